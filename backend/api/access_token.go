@@ -0,0 +1,49 @@
+package api
+
+import (
+	"emviwiki/backend/client"
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"emviwiki/shared/rest"
+	"net/http"
+)
+
+func ReadAccessTokensHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	rest.WriteResponse(w, client.ReadAccessTokens(ctx.Organization, ctx.UserId))
+	return nil
+}
+
+func SaveAccessTokenHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := new(client.SaveAccessTokenData)
+
+	if err := rest.DecodeJSON(r, req); err != nil {
+		return []error{err}
+	}
+
+	accessToken, token, err := client.SaveAccessToken(ctx.Organization, ctx.UserId, req)
+
+	if err != nil {
+		return err
+	}
+
+	resp := struct {
+		*model.AccessToken
+		Token string `json:"token"`
+	}{accessToken, token}
+	rest.WriteResponse(w, &resp)
+	return nil
+}
+
+func DeleteAccessTokenHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := client.DeleteAccessToken(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"emviwiki/backend/article"
 	"emviwiki/backend/article/history"
 	"emviwiki/backend/article/util"
+	"emviwiki/backend/content"
 	"emviwiki/backend/context"
 	"emviwiki/shared/model"
 	"emviwiki/shared/rest"
@@ -22,6 +23,12 @@ func SaveArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.
 	}
 
 	req.Organization = ctx.Organization
+
+	// only trusted clients (collab) are allowed to save articles on behalf of other users
+	if !ctx.TrustedClient {
+		req.UserId = ctx.UserId
+	}
+
 	id, err := article.SaveArticle(req)
 
 	if err != nil {
@@ -67,12 +74,19 @@ func ReadArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.
 
 	rawContent := rest.GetBoolParam(r, "raw_content")
 	format := rest.GetParam(r, "format")
-	result, err := article.ReadArticle(ctx, articleId, langId, version, !rawContent, format)
+	changes := rest.GetBoolParam(r, "changes")
+	result, err := article.ReadArticle(ctx, articleId, langId, version, !rawContent, format, changes)
 
 	if err != nil {
 		return []error{err}
 	}
 
+	result.SignContentURLs(ctx.Organization.ID, ctx.UserId)
+
+	if searchQuery := rest.GetParam(r, "search_query"); searchQuery != "" {
+		article.TrackSearchQuery(ctx, articleId, searchQuery)
+	}
+
 	for i := range result.Article.Access {
 		if result.Article.Access[i].User != nil && result.Article.Access[i].User.Picture.Valid {
 			result.Article.Access[i].User.Picture.SetValid(getResourceURL(result.Article.Access[i].User.Picture.String))
@@ -106,6 +120,7 @@ func ReadArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.
 			Observed        bool                          `json:"observed"`
 			Bookmarked      bool                          `json:"bookmarked"`
 			Recommendations []model.ArticleRecommendation `json:"recommendations"`
+			Changes         *article.ArticleChanges       `json:"changes,omitempty"`
 		}{
 			result.Article,
 			result.Content,
@@ -114,6 +129,7 @@ func ReadArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.
 			result.IsObserved,
 			result.IsBookmarked,
 			result.Recommendations,
+			result.Changes,
 		})
 	}
 	return nil
@@ -165,6 +181,35 @@ func DeleteArticleHistoryEntryHandler(ctx context.EmviContext, w http.ResponseWr
 	return nil
 }
 
+func ReadArticleStatisticsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articleId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	langId, err := rest.GetIdParam(r, "lang") // default will be used if not set
+
+	if err != nil {
+		return []error{err}
+	}
+
+	days, err := rest.GetIntParam(r, "days")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	statistics, err := article.ReadArticleStatistics(ctx, articleId, langId, days)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, statistics)
+	return nil
+}
+
 func ReadArticlePreviewHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
 	articleId, err := rest.IdParam(r, "id")
 
@@ -178,15 +223,16 @@ func ReadArticlePreviewHandler(ctx context.EmviContext, w http.ResponseWriter, r
 		return []error{err}
 	}
 
-	content, err := article.GetArticlePreview(ctx, articleId, langId, rest.GetBoolParam(r, "preview_paragraph"))
+	preview, err := article.GetArticlePreview(ctx, articleId, langId, rest.GetBoolParam(r, "preview_paragraph"))
 
 	if err != nil {
 		return []error{err}
 	}
 
+	// attachments can only be downloaded using signed URLs
 	rest.WriteResponse(w, struct {
 		Content string `json:"content"`
-	}{content})
+	}{content.SignContentURLs(preview, ctx.Organization.ID, ctx.UserId)})
 	return nil
 }
 
@@ -324,6 +370,31 @@ func ResetArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http
 	return nil
 }
 
+func PatchArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articleId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	var req article.PatchArticleData
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	version, err := article.PatchArticle(ctx, articleId, req)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		Version int `json:"version"`
+	}{version})
+	return nil
+}
+
 func CopyArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
 	articleId, err := rest.IdParam(r, "id")
 
@@ -4,6 +4,7 @@ import (
 	"emviwiki/backend/article"
 	"emviwiki/backend/content"
 	"emviwiki/backend/context"
+	"emviwiki/shared/model"
 	"emviwiki/shared/rest"
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
@@ -28,7 +29,8 @@ func UploadArticleAttachmentHandler(ctx context.EmviContext, w http.ResponseWrit
 
 	rest.WriteResponse(w, struct {
 		UniqueName string `json:"unique_name"`
-	}{uniqueName})
+		URL        string `json:"url"`
+	}{uniqueName, getSignedResourceURL(ctx, uniqueName)})
 	took := time.Now().Sub(startTime)
 	logbuch.Debug("Finished attachment upload", logbuch.Fields{"unique_name": uniqueName, "took_ms": took.Milliseconds()})
 	return nil
@@ -50,3 +52,118 @@ func getArticleAttachmentMetaData(r *http.Request) (hide.ID, hide.ID, string, er
 	roomId := rest.GetParam(r, "room")
 	return articleId, langId, roomId, nil
 }
+
+func CreateAttachmentUploadHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := struct {
+		ArticleId hide.ID `json:"article_id"`
+		LangId    hide.ID `json:"language_id"`
+		RoomId    string  `json:"room_id"`
+		Filename  string  `json:"filename"`
+		MimeType  string  `json:"mime_type"`
+		Size      int64   `json:"size"`
+		Checksum  string  `json:"checksum"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	session, err := article.CreateAttachmentUploadSession(&content.UploadSessionData{Organization: ctx.Organization,
+		UserId:    ctx.UserId,
+		ArticleId: req.ArticleId,
+		LangId:    req.LangId,
+		RoomId:    req.RoomId,
+		Filename:  req.Filename,
+		MimeType:  req.MimeType,
+		Size:      req.Size,
+		Checksum:  req.Checksum})
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		Id        hide.ID `json:"id"`
+		ChunkSize int64   `json:"chunk_size"`
+		Chunks    int     `json:"chunks"`
+	}{session.ID, session.ChunkSize, session.Chunks()})
+	return nil
+}
+
+func ReadAttachmentUploadHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	sessionId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	session, chunks, err := content.ReadUploadSession(ctx.Organization, ctx.UserId, sessionId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		Session *model.UploadSession `json:"session"`
+		Chunks  []model.UploadChunk  `json:"chunks"`
+	}{session, chunks})
+	return nil
+}
+
+// UploadAttachmentChunkHandler receives a chunk of a resumable upload.
+// The chunk number is passed as parameter and the SHA-256 of the chunk (hex) in the X-Checksum-SHA256 header.
+func UploadAttachmentChunkHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	sessionId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	number, err := rest.GetIntParam(r, "chunk")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, content.UploadChunkSize)
+
+	if err := content.UploadChunk(ctx.Organization, ctx.UserId, sessionId, number, r.Header.Get("X-Checksum-SHA256"), r.Body); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func CompleteAttachmentUploadHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	sessionId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	uniqueName, err := content.CompleteUploadSession(ctx.Organization, ctx.UserId, sessionId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		UniqueName string `json:"unique_name"`
+		URL        string `json:"url"`
+	}{uniqueName, getSignedResourceURL(ctx, uniqueName)})
+	return nil
+}
+
+func CancelAttachmentUploadHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	sessionId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := content.CancelUploadSession(ctx.Organization, ctx.UserId, sessionId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
@@ -19,23 +19,44 @@ type AuthHandler func(context.EmviContext, http.ResponseWriter, *http.Request) [
 
 func AuthMiddleware(next AuthHandler, getOrga, requireExpert, requireWritePermissions bool, scopes ...string) http.Handler {
 	scopeList := make([]client.Scope, 0, len(scopes))
+	requireWriteScope := false
 
 	for _, scope := range scopes {
-		scopeList = append(scopeList, client.ScopeFromString(scope))
+		s := client.ScopeFromString(scope)
+		scopeList = append(scopeList, s)
+		requireWriteScope = requireWriteScope || s.Write
 	}
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		tokenResp, orga := AuthenticateUser(w, r, getOrga)
+		var ctx context.EmviContext
 
-		if tokenResp == nil {
-			return
+		if token := auth.GetAccessToken(r); client.IsAccessToken(token) {
+			accessTokenCtx := authenticateAccessToken(w, r, token, getOrga)
+
+			if accessTokenCtx == nil {
+				return
+			}
+
+			ctx = *accessTokenCtx
+		} else {
+			tokenResp, orga := AuthenticateUser(w, r, getOrga)
+
+			if tokenResp == nil {
+				return
+			}
+
+			ctx = context.NewEmviContext(orga, tokenResp.UserId, tokenResp.Scopes, tokenResp.Trusted)
+
+			if tokenResp.IsClient() && !tokenResp.Trusted && requireWriteScope {
+				ctx = getClientBotContext(ctx, tokenResp)
+			}
 		}
 
-		ctx := context.NewEmviContext(orga, tokenResp.UserId, tokenResp.Scopes, tokenResp.Trusted)
+		orga := ctx.Organization
 
 		if !ctx.HasScopes(scopeList...) ||
 			(requireExpert && !orga.Expert) ||
-			(requireWritePermissions && !memberHasWritePermissions(orga.ID, tokenResp.UserId)) {
+			(requireWritePermissions && !memberHasWritePermissions(orga.ID, ctx.UserId)) {
 			rest.WriteErrorResponse(w, http.StatusForbidden)
 			return
 		}
@@ -69,6 +90,43 @@ func AuthenticateUser(w http.ResponseWriter, r *http.Request, getOrga bool) (*au
 	return tokenResp, org
 }
 
+// authenticateAccessToken validates the personal access token and returns a context for the user it belongs to.
+// Personal access tokens can only be used for endpoints of the organization they belong to.
+func authenticateAccessToken(w http.ResponseWriter, r *http.Request, token string, getOrga bool) *context.EmviContext {
+	if !getOrga {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil
+	}
+
+	accessToken, orga, scopes, err := client.ValidateAccessToken(token)
+
+	if err != nil {
+		logbuch.Debug("Error validating access token", logbuch.Fields{"err": err, "method": r.Method, "url": r.URL})
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil
+	}
+
+	if name := r.Header.Get(headerOrg); name != "" && name != orga.NameNormalized {
+		w.WriteHeader(http.StatusUnauthorized)
+		return nil
+	}
+
+	ctx := context.NewEmviScopedContext(orga, accessToken.UserId, scopes)
+	return &ctx
+}
+
+// getClientBotContext returns a context for the bot of the client, so that writes are attributed to the bot and its permissions apply.
+// The bot is restricted to the scopes of the client. If the client has no bot, the context is returned as is.
+func getClientBotContext(ctx context.EmviContext, tokenResp *auth.TokenResponse) context.EmviContext {
+	c := model.GetClientByOrganizationIdAndClientId(ctx.Organization.ID, tokenResp.ClientId)
+
+	if c == nil || c.UserId == 0 {
+		return ctx
+	}
+
+	return context.NewEmviScopedContext(ctx.Organization, c.UserId, tokenResp.Scopes)
+}
+
 func getOrganization(r *http.Request, tokenResp *auth.TokenResponse) *model.Organization {
 	name := r.Header.Get(headerOrg)
 
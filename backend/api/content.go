@@ -2,6 +2,7 @@ package api
 
 import (
 	"emviwiki/backend/content"
+	"emviwiki/backend/errs"
 	sharedcontent "emviwiki/shared/content"
 	"github.com/emvi/logbuch"
 	"github.com/gorilla/mux"
@@ -11,11 +12,30 @@ import (
 	"syscall"
 )
 
-// TODO signed URL
+// GetContentHandler returns the file content for given filename.
+// Images can be requested in a smaller size by setting the size parameter (medium or thumbnail).
+// Attachments require a signed URL (see content.SignFileURL), pictures are public.
+// Range and conditional requests are supported if the store allows seeking, otherwise only conditional requests are.
 func GetContentHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	filename := strings.TrimSpace(vars["filename"])
-	file, reader, err := content.ReadFile(filename)
+	derivative := r.URL.Query().Get("size")
+	file, err := content.CheckFileAccess(filename, r.URL.Query())
+
+	if err == errs.PermissionDenied {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	} else if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if presignedURL := content.GetPresignedFileURL(file, derivative); presignedURL != "" {
+		http.Redirect(w, r, presignedURL, http.StatusFound)
+		return
+	}
+
+	reader, mimeType, err := content.ReadFileContent(file, derivative)
 
 	if err != nil {
 		w.WriteHeader(http.StatusNotFound)
@@ -28,7 +48,27 @@ func GetContentHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
-	sharedcontent.SetContentDownloadHeader(w, file.OriginalName, file.MimeType, file.MD5)
+	etag := file.MD5
+
+	if sharedcontent.IsImageDerivative(derivative) {
+		etag += "_" + derivative
+	}
+
+	sharedcontent.SetContentDownloadHeader(w, file.OriginalName, mimeType, etag)
+
+	if file.ArticleId != 0 || file.RoomId.Valid {
+		w.Header().Set("Cache-Control", "private, max-age=1200")
+	}
+
+	if seeker, ok := reader.(io.ReadSeeker); ok {
+		http.ServeContent(w, r, "", file.DefTime, seeker)
+		return
+	}
+
+	if etagMatches(r.Header.Get("If-None-Match"), `"`+etag+`"`) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
 
 	// EPIPE ignore broken pipe errors
 	if _, err := io.Copy(w, reader); err != nil && err != syscall.EPIPE {
@@ -36,3 +76,19 @@ func GetContentHandler(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusInternalServerError)
 	}
 }
+
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	for _, match := range strings.Split(ifNoneMatch, ",") {
+		match = strings.TrimPrefix(strings.TrimSpace(match), "W/")
+
+		if match == "*" || match == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/customfield"
+	"emviwiki/shared/rest"
+	"github.com/emvi/hide"
+	"net/http"
+)
+
+func ReadCustomFieldsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	rest.WriteResponse(w, customfield.ReadCustomFields(ctx.Organization))
+	return nil
+}
+
+func SaveCustomFieldHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := new(customfield.SaveCustomFieldData)
+
+	if err := rest.DecodeJSON(r, req); err != nil {
+		return []error{err}
+	}
+
+	id, err := customfield.SaveCustomField(ctx.Organization, ctx.UserId, req)
+
+	if err != nil {
+		return err
+	}
+
+	rest.WriteResponse(w, struct {
+		Id hide.ID `json:"id"`
+	}{id})
+	return nil
+}
+
+func DeleteCustomFieldHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := customfield.DeleteCustomField(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
@@ -45,6 +45,15 @@ func GetFilteredFeedHandler(ctx context.EmviContext, w http.ResponseWriter, r *h
 	return nil
 }
 
+func GetChangedArticlesHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articles, count := feed.GetChangedArticles(ctx.Organization, ctx.UserId)
+	rest.WriteResponse(w, struct {
+		Articles []model.ArticleVisitChange `json:"articles"`
+		Count    int                        `json:"count"`
+	}{articles, count})
+	return nil
+}
+
 func ToggleNotificationReadHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
 	req := struct {
 		Id hide.ID `json:"id"`
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/inbound"
+	"emviwiki/shared/constants"
+	"emviwiki/shared/rest"
+	"github.com/emvi/hide"
+	"net/http"
+	"strings"
+)
+
+const (
+	maxInboundMailSize = 1024 * 1024 * 30 // 30 MB
+)
+
+func ReadInboundMailAddressesHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	addresses, err := inbound.ReadAddresses(ctx.Organization, ctx.UserId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, addresses)
+	return nil
+}
+
+func CreateInboundMailAddressHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := struct {
+		ArticleListId hide.ID `json:"article_list_id"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	address, err := inbound.CreateAddress(ctx.Organization, ctx.UserId, req.ArticleListId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, address)
+	return nil
+}
+
+func DeleteInboundMailAddressHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := inbound.DeleteAddress(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+// InboundMailHandler receives raw MIME mails from the mail server. Requests are authenticated by the configured secret.
+func InboundMailHandler(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get(constants.AuthHeader), constants.AuthTokenType+" ")
+
+	if !inbound.CheckSecret(token) {
+		rest.WriteErrorResponse(w, http.StatusUnauthorized, errs.PermissionDenied)
+		return
+	}
+
+	id, err := inbound.ReceiveMail(http.MaxBytesReader(w, r.Body, maxInboundMailSize))
+
+	if err == errs.PermissionDenied || err == errs.ArticlePermissionDenied {
+		rest.WriteErrorResponse(w, http.StatusForbidden, err)
+		return
+	} else if err == errs.InboundMailAddressNotFound || err == errs.ArticleNotFound {
+		rest.WriteErrorResponse(w, http.StatusNotFound, err)
+		return
+	} else if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	rest.WriteResponse(w, struct {
+		Id hide.ID `json:"id"`
+	}{id})
+}
@@ -0,0 +1,164 @@
+package api
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/integration"
+	"emviwiki/shared/rest"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"io/ioutil"
+	"net/http"
+)
+
+const (
+	maxSlackEventSize = 1024 * 1024 // 1 MB
+)
+
+func ReadIntegrationsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	integrations, err := integration.ReadIntegrations(ctx.Organization, ctx.UserId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, integrations)
+	return nil
+}
+
+func SaveIntegrationHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := new(integration.SaveIntegrationData)
+
+	if err := rest.DecodeJSON(r, req); err != nil {
+		return []error{err}
+	}
+
+	id, err := integration.SaveIntegration(ctx.Organization, ctx.UserId, req)
+
+	if err != nil {
+		return err
+	}
+
+	rest.WriteResponse(w, struct {
+		Id hide.ID `json:"id"`
+	}{id})
+	return nil
+}
+
+func DeleteIntegrationHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := integration.DeleteIntegration(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func ReadChatAccountsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	accounts, err := integration.ReadAccounts(ctx.Organization, ctx.UserId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, accounts)
+	return nil
+}
+
+func LinkChatAccountHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	req := struct {
+		ExternalId string `json:"external_id"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	if err := integration.LinkAccount(ctx.Organization, ctx.UserId, id, req.ExternalId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func ConfirmChatAccountHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	req := struct {
+		Code string `json:"code"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	if err := integration.ConfirmAccount(ctx.Organization, ctx.UserId, id, req.Code); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func UnlinkChatAccountHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := integration.UnlinkAccount(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+// SlackEventsHandler receives events from the Slack events API. Requests are authenticated by their signature.
+func SlackEventsHandler(w http.ResponseWriter, r *http.Request) {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	// the body is read before the signature is checked, so its size must be limited
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxSlackEventSize))
+
+	if err != nil {
+		logbuch.Error("Error reading body from Slack event", logbuch.Fields{"err": err})
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	challenge, err := integration.SlackEvent(id, body, r.Header.Get("X-Slack-Request-Timestamp"), r.Header.Get("X-Slack-Signature"))
+
+	if err == errs.PermissionDenied {
+		rest.WriteErrorResponse(w, http.StatusUnauthorized, err)
+		return
+	} else if err != nil {
+		rest.WriteErrorResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if challenge != "" {
+		rest.WriteResponse(w, struct {
+			Challenge string `json:"challenge"`
+		}{challenge})
+	}
+}
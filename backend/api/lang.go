@@ -62,3 +62,62 @@ func SwitchDefaultLangHandler(ctx context.EmviContext, w http.ResponseWriter, r
 
 	return nil
 }
+
+func ReadTranslatorsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	langId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	translators, err := lang.ReadTranslators(ctx.Organization, langId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, translators)
+	return nil
+}
+
+func AddTranslatorHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	langId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	req := struct {
+		UserId hide.ID `json:"user_id"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	if err := lang.AddTranslator(ctx.Organization, ctx.UserId, langId, req.UserId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func RemoveTranslatorHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	langId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	userId, err := rest.GetIdParam(r, "user_id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := lang.RemoveTranslator(ctx.Organization, ctx.UserId, langId, userId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
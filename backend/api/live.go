@@ -0,0 +1,92 @@
+package api
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/live"
+	"encoding/json"
+	"fmt"
+	"github.com/emvi/logbuch"
+	"net/http"
+	"time"
+)
+
+const (
+	liveKeepAliveInterval = time.Second * 15
+
+	// time in milliseconds the client waits before reconnecting after the stream was interrupted
+	liveRetryMs = 1000
+)
+
+// LiveEventsHandler streams feed, notification and article events to the user as server-sent events.
+// The server write timeout must be disabled for this handler, the stream is kept open until the client disconnects.
+func LiveEventsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	if ctx.IsClient() {
+		return []error{errs.PermissionDenied}
+	}
+
+	flusher, ok := w.(http.Flusher)
+
+	if !ok {
+		return []error{errs.StreamingNotSupported}
+	}
+
+	sub := live.Subscribe(ctx)
+	defer live.Unsubscribe(sub)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", liveRetryMs); err != nil {
+		return nil
+	}
+
+	flusher.Flush()
+	keepAlive := time.NewTicker(liveKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return nil
+			}
+
+			if err := writeLiveMessage(w, msg); err != nil {
+				return nil
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case <-r.Context().Done():
+			return nil
+		}
+
+		flusher.Flush()
+	}
+}
+
+func writeLiveMessage(w http.ResponseWriter, msg live.Message) error {
+	// the message is shared between all subscriptions of the user, so the feed is copied before modifying it
+	if feedMsg, ok := msg.Data.(live.FeedMessage); ok && feedMsg.Feed.TriggeredByUser.Picture.Valid {
+		feed := *feedMsg.Feed
+		user := *feed.TriggeredByUser
+		user.Picture.SetValid(getResourceURL(user.Picture.String))
+		feed.TriggeredByUser = &user
+		feedMsg.Feed = &feed
+		msg.Data = feedMsg
+	}
+
+	data, err := json.Marshal(msg.Data)
+
+	if err != nil {
+		logbuch.Error("Error marshalling live message", logbuch.Fields{"err": err, "event": msg.Event})
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Event, data)
+	return err
+}
@@ -165,7 +165,13 @@ func UpdateOrganizationPermissionsHandler(ctx context.EmviContext, w http.Respon
 }
 
 func GetOrganizationStatisticsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
-	statistics, err := organization.GetOrganizationStatistics(ctx.Organization, ctx.UserId)
+	days, err := rest.GetIntParam(r, "days")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	statistics, err := organization.GetOrganizationStatistics(ctx.Organization, ctx.UserId, days)
 
 	if err != nil {
 		return []error{err}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"emviwiki/backend/article"
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"emviwiki/shared/rest"
+	"github.com/emvi/hide"
+	"net/http"
+	"time"
+)
+
+func CreateReadingCampaignHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articleId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	req := struct {
+		LangId  hide.ID   `json:"language_id"`
+		User    []hide.ID `json:"user"`
+		Groups  []hide.ID `json:"groups"`
+		DueDate time.Time `json:"due_date"`
+	}{}
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	id, err := article.CreateReadingCampaign(ctx.Organization, ctx.UserId, articleId, req.LangId, req.User, req.Groups, req.DueDate)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		Id hide.ID `json:"id"`
+	}{id})
+	return nil
+}
+
+func ReadReadingCampaignsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articleId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	campaigns, err := article.ReadReadingCampaigns(ctx.Organization, ctx.UserId, articleId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, campaigns)
+	return nil
+}
+
+func ReadUnconfirmedReadingCampaignsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	rest.WriteResponse(w, article.ReadUnconfirmedReadingCampaigns(ctx.Organization, ctx.UserId))
+	return nil
+}
+
+func ReadReadingCampaignReportHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	campaignId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	report, err := article.ReadReadingCampaignReport(ctx.Organization, ctx.UserId, campaignId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	for i := range report.Members {
+		if report.Members[i].User != nil && report.Members[i].User.Picture.Valid {
+			report.Members[i].User.Picture.SetValid(getResourceURL(report.Members[i].User.Picture.String))
+		}
+	}
+
+	rest.WriteResponse(w, struct {
+		Campaign *model.ReadingCampaign        `json:"campaign"`
+		Members  []model.ReadingCampaignMember `json:"members"`
+	}{report.Campaign, report.Members})
+	return nil
+}
+
+func ConfirmReadingCampaignHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	campaignId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := article.ConfirmReadingCampaign(ctx.Organization, ctx.UserId, campaignId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func DeleteReadingCampaignHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	campaignId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := article.DeleteReadingCampaign(ctx.Organization, ctx.UserId, campaignId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
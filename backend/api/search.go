@@ -2,7 +2,9 @@ package api
 
 import (
 	"emviwiki/backend/context"
+	"github.com/emvi/hide"
 	"net/http"
+	"strings"
 	"time"
 
 	"emviwiki/backend/search"
@@ -157,27 +159,46 @@ func SearchArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *htt
 		publishedEnd = time.Time{}
 	}
 
+	customFields, err := getCustomFieldFilter(r)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	sortCustomFieldId, sortCustomField := hide.ID(0), ""
+
+	if param := rest.GetParam(r, "sort_custom_field"); param != "" {
+		sortCustomFieldId, sortCustomField, err = splitCustomFieldParam("sort_custom_field", param)
+
+		if err != nil {
+			return []error{err}
+		}
+	}
+
 	filter := &model.SearchArticleFilter{
-		BaseSearch:       baseFilter,
-		LanguageId:       langId,
-		Archived:         rest.GetBoolParam(r, "archived"),
-		WIP:              rest.GetBoolParam(r, "wip"),
-		ClientAccess:     rest.GetBoolParam(r, "client_access"),
-		Preview:          rest.GetBoolParam(r, "preview"),
-		PreviewParagraph: rest.GetBoolParam(r, "preview_paragraph"),
-		PreviewImage:     rest.GetBoolParam(r, "preview_image"),
-		Title:            rest.GetParam(r, "title"),
-		Content:          rest.GetParam(r, "content"),
-		Tags:             rest.GetParam(r, "tags"),
-		TagIds:           tagIds,
-		AuthorUserIds:    authorUserIds,
-		UserGroupIds:     userGroupIds,
-		Commits:          rest.GetParam(r, "commits"),
-		PublishedStart:   publishedStart,
-		PublishedEnd:     publishedEnd,
-		SortTitle:        rest.GetParam(r, "sort_title"),
-		SortPublished:    rest.GetParam(r, "sort_published"),
-		SortRelevance:    rest.GetParam(r, "sort_relevance"),
+		BaseSearch:        baseFilter,
+		LanguageId:        langId,
+		Archived:          rest.GetBoolParam(r, "archived"),
+		WIP:               rest.GetBoolParam(r, "wip"),
+		ClientAccess:      rest.GetBoolParam(r, "client_access"),
+		Preview:           rest.GetBoolParam(r, "preview"),
+		PreviewParagraph:  rest.GetBoolParam(r, "preview_paragraph"),
+		PreviewImage:      rest.GetBoolParam(r, "preview_image"),
+		Title:             rest.GetParam(r, "title"),
+		Content:           rest.GetParam(r, "content"),
+		Tags:              rest.GetParam(r, "tags"),
+		TagIds:            tagIds,
+		AuthorUserIds:     authorUserIds,
+		UserGroupIds:      userGroupIds,
+		Commits:           rest.GetParam(r, "commits"),
+		PublishedStart:    publishedStart,
+		PublishedEnd:      publishedEnd,
+		SortTitle:         rest.GetParam(r, "sort_title"),
+		SortPublished:     rest.GetParam(r, "sort_published"),
+		SortRelevance:     rest.GetParam(r, "sort_relevance"),
+		CustomFields:      customFields,
+		SortCustomFieldId: sortCustomFieldId,
+		SortCustomField:   sortCustomField,
 	}
 
 	articles, count := search.SearchArticle(ctx, query, filter)
@@ -343,3 +364,56 @@ func getBaseFilter(w http.ResponseWriter, r *http.Request) (model.BaseSearch, er
 		Offset:       offset,
 		Limit:        limit}, nil
 }
+
+// Returns the custom field filter for the parameters "custom_field", "custom_field_min" and "custom_field_max".
+// Each parameter can be passed multiple times as <custom field ID>:<value>. Example: ?custom_field=id:value&custom_field_min=id:1
+func getCustomFieldFilter(r *http.Request) ([]model.CustomFieldFilter, error) {
+	filter := make([]model.CustomFieldFilter, 0)
+	index := make(map[hide.ID]int)
+	query := r.URL.Query()
+
+	for _, name := range []string{"custom_field", "custom_field_min", "custom_field_max"} {
+		for _, param := range query[name] {
+			id, value, err := splitCustomFieldParam(name, param)
+
+			if err != nil {
+				return nil, err
+			}
+
+			i, ok := index[id]
+
+			if !ok {
+				filter = append(filter, model.CustomFieldFilter{CustomFieldId: id})
+				i = len(filter) - 1
+				index[id] = i
+			}
+
+			switch name {
+			case "custom_field":
+				filter[i].Value = value
+			case "custom_field_min":
+				filter[i].Min = value
+			case "custom_field_max":
+				filter[i].Max = value
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+func splitCustomFieldParam(name, param string) (hide.ID, string, error) {
+	parts := strings.SplitN(param, ":", 2)
+
+	if len(parts) != 2 {
+		return 0, "", rest.NewApiError("Invalid format", name)
+	}
+
+	id, err := hide.FromString(strings.TrimSpace(parts[0]))
+
+	if err != nil {
+		return 0, "", rest.NewApiError("Invalid format", name)
+	}
+
+	return id, strings.TrimSpace(parts[1]), nil
+}
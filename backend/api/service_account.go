@@ -0,0 +1,104 @@
+package api
+
+import (
+	"emviwiki/backend/client"
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"emviwiki/shared/rest"
+	"github.com/emvi/hide"
+	"net/http"
+)
+
+func ReadServiceAccountsHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	accounts, err := client.ReadServiceAccounts(ctx.Organization, ctx.UserId)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	for i := range accounts {
+		if accounts[i].Picture.Valid {
+			accounts[i].Picture.SetValid(getResourceURL(accounts[i].Picture.String))
+		}
+	}
+
+	rest.WriteResponse(w, accounts)
+	return nil
+}
+
+func SaveServiceAccountHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	req := new(client.SaveServiceAccountData)
+
+	if err := rest.DecodeJSON(r, req); err != nil {
+		return []error{err}
+	}
+
+	id, err := client.SaveServiceAccount(ctx.Organization, ctx.UserId, req)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, struct {
+		Id hide.ID `json:"id"`
+	}{id})
+	return nil
+}
+
+func DeleteServiceAccountHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := client.DeleteServiceAccount(ctx.Organization, ctx.UserId, id); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
+
+func ReadServiceAccountAccessTokensHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	tokens, err := client.ReadServiceAccountAccessTokens(ctx.Organization, ctx.UserId, id)
+
+	if err != nil {
+		return []error{err}
+	}
+
+	rest.WriteResponse(w, tokens)
+	return nil
+}
+
+func SaveServiceAccountAccessTokenHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	id, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	req := new(client.SaveAccessTokenData)
+
+	if err := rest.DecodeJSON(r, req); err != nil {
+		return []error{err}
+	}
+
+	accessToken, token, saveErr := client.SaveServiceAccountAccessToken(ctx.Organization, ctx.UserId, id, req)
+
+	if saveErr != nil {
+		return saveErr
+	}
+
+	resp := struct {
+		*model.AccessToken
+		Token string `json:"token"`
+	}{accessToken, token}
+	rest.WriteResponse(w, &resp)
+	return nil
+}
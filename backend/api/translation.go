@@ -0,0 +1,54 @@
+package api
+
+import (
+	"emviwiki/backend/article"
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"emviwiki/shared/rest"
+	"net/http"
+)
+
+func ReadTranslationStatusHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	langId, err := rest.GetIdParam(r, "lang") // all languages if not set
+
+	if err != nil {
+		return []error{err}
+	}
+
+	offset, err := rest.GetIntParam(r, "offset")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	status, count := article.ReadTranslationStatus(ctx.Organization, ctx.UserId, article.ReadTranslationStatusData{LanguageId: langId,
+		Pending: rest.GetBoolParam(r, "pending"),
+		Offset:  offset})
+	rest.WriteResponse(w, struct {
+		Translations []model.TranslationStatus `json:"translations"`
+		Count        int                       `json:"count"`
+	}{status, count})
+	return nil
+}
+
+func TranslateArticleHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	articleId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	var req article.TranslateArticleData
+
+	if err := rest.DecodeJSON(r, &req); err != nil {
+		return []error{err}
+	}
+
+	if err := article.TranslateArticle(ctx, articleId, req); err != nil {
+		return []error{err}
+	}
+
+	// the translation runs in background, the user is notified when it's done
+	w.WriteHeader(http.StatusAccepted)
+	return nil
+}
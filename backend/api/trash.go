@@ -0,0 +1,35 @@
+package api
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/trash"
+	"emviwiki/shared/rest"
+	"net/http"
+)
+
+func ReadTrashHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	objects := trash.ReadTrash(ctx.Organization, ctx.UserId)
+
+	for i := range objects {
+		if objects[i].User != nil && objects[i].User.Picture.Valid {
+			objects[i].User.Picture.SetValid(getResourceURL(objects[i].User.Picture.String))
+		}
+	}
+
+	rest.WriteResponse(w, objects)
+	return nil
+}
+
+func RestoreTrashHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
+	trashId, err := rest.IdParam(r, "id")
+
+	if err != nil {
+		return []error{err}
+	}
+
+	if err := trash.RestoreTrash(ctx.Organization, ctx.UserId, trashId); err != nil {
+		return []error{err}
+	}
+
+	return nil
+}
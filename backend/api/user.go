@@ -1,16 +1,13 @@
 package api
 
 import (
+	"emviwiki/backend/content"
 	"emviwiki/backend/context"
 	"emviwiki/backend/user"
 	"emviwiki/shared/rest"
 	"net/http"
 )
 
-const (
-	maxUserPictureMem = 2097152 // 2 MBi
-)
-
 // Does nothing. The authentication is handled by middleware.
 // This function is just a dummy to have an endpoint.
 func AuthenticateUserHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
@@ -75,7 +72,7 @@ func GetProfileByNameHandler(ctx context.EmviContext, w http.ResponseWriter, r *
 }
 
 func UploadUserPictureHandler(ctx context.EmviContext, w http.ResponseWriter, r *http.Request) []error {
-	r.Body = http.MaxBytesReader(w, r.Body, maxUserPictureMem)
+	r.Body = http.MaxBytesReader(w, r.Body, content.MaxUserPictureSize)
 
 	if err := user.UploadUserPicture(r, ctx.UserId); err != nil {
 		return []error{err}
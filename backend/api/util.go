@@ -1,6 +1,8 @@
 package api
 
 import (
+	"emviwiki/backend/content"
+	"emviwiki/backend/context"
 	"fmt"
 	"strings"
 )
@@ -16,3 +18,8 @@ func getResourceURL(filename string) string {
 
 	return fmt.Sprintf("%s%s/%s", contentHost, contentEndpoint, filename)
 }
+
+// getSignedResourceURL returns the content URL for given filename signed for the user (or client) in context.
+func getSignedResourceURL(ctx context.EmviContext, filename string) string {
+	return fmt.Sprintf("%s?%s", getResourceURL(filename), content.SignFileURL(ctx.Organization.ID, ctx.UserId, filename))
+}
@@ -0,0 +1,111 @@
+// Package apidoc generates the API reference for the endpoints of the wiki.
+package apidoc
+
+import (
+	"emviwiki/backend/client"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	apiPathPrefix = "/api/v1/"
+)
+
+// Route is an endpoint of the wiki.
+type Route struct {
+	Path                    string
+	Method                  string
+	Handler                 string
+	RequireExpert           bool
+	RequireWritePermissions bool
+	Scopes                  []string
+}
+
+// Reference collects routes to generate the API reference.
+type Reference struct {
+	routes []Route
+}
+
+// Add adds a route to the reference.
+func (ref *Reference) Add(route Route) {
+	ref.routes = append(ref.routes, route)
+}
+
+// Markdown writes the API reference as Markdown.
+// The endpoints are grouped by the first element of their path in the order they were added.
+// Endpoints without scopes can only be accessed by users.
+func (ref *Reference) Markdown(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("# API reference\n\n")
+	sb.WriteString("All endpoints require the `Organization` header to be set to the name of the organization. ")
+	sb.WriteString("Clients and personal access tokens can only access endpoints listing scopes and need all of them. ")
+	sb.WriteString("Read and write access (`rw`) includes read access (`r`).\n\n")
+	writeScopes(&sb)
+	group := ""
+
+	for _, route := range ref.routes {
+		if g := getGroup(route.Path); g != group {
+			group = g
+			sb.WriteString(fmt.Sprintf("\n## %s\n\n", group))
+			sb.WriteString("| Method | Path | Handler | Scopes | Expert | Write permissions |\n")
+			sb.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("| %s | `%s` | %s | %s | %s | %s |\n",
+			route.Method,
+			route.Path,
+			route.Handler,
+			formatScopes(route.Scopes),
+			formatBool(route.RequireExpert),
+			formatBool(route.RequireWritePermissions)))
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeScopes(sb *strings.Builder) {
+	names := make([]string, 0, len(client.Scopes))
+
+	for name := range client.Scopes {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	sb.WriteString("## Scopes\n\n")
+	sb.WriteString("| Scope | Read | Write |\n")
+	sb.WriteString("| --- | --- | --- |\n")
+
+	for _, name := range names {
+		scope := client.Scopes[name]
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", name, formatBool(scope.Read), formatBool(scope.Write)))
+	}
+}
+
+func getGroup(path string) string {
+	path = strings.TrimPrefix(path, apiPathPrefix)
+
+	if i := strings.Index(path, "/"); i != -1 {
+		return path[:i]
+	}
+
+	return path
+}
+
+func formatScopes(scopes []string) string {
+	if len(scopes) == 0 {
+		return "-"
+	}
+
+	return "`" + strings.Join(scopes, "`, `") + "`"
+}
+
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+
+	return "no"
+}
@@ -0,0 +1,46 @@
+package apidoc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReferenceMarkdown(t *testing.T) {
+	ref := Reference{}
+	ref.Add(Route{Path: "/api/v1/article", Method: "POST", Handler: "SaveArticleHandler", RequireWritePermissions: true, Scopes: []string{"articles:rw"}})
+	ref.Add(Route{Path: "/api/v1/article/{id}", Method: "GET", Handler: "ReadArticleHandler", Scopes: []string{"articles:r"}})
+	ref.Add(Route{Path: "/api/v1/tag", Method: "POST", Handler: "AddTagHandler", RequireExpert: true})
+	var sb strings.Builder
+
+	if err := ref.Markdown(&sb); err != nil {
+		t.Fatal(err)
+	}
+
+	out := sb.String()
+
+	if strings.Count(out, "\n## article\n") != 1 || strings.Count(out, "\n## tag\n") != 1 {
+		t.Fatalf("Routes must be grouped, but was: %v", out)
+	}
+
+	if !strings.Contains(out, "| POST | `/api/v1/article` | SaveArticleHandler | `articles:rw` | no | yes |") {
+		t.Fatalf("Route must be written, but was: %v", out)
+	}
+
+	if !strings.Contains(out, "| POST | `/api/v1/tag` | AddTagHandler | - | yes | no |") {
+		t.Fatalf("Route without scopes must be written, but was: %v", out)
+	}
+
+	if !strings.Contains(out, "| articles | yes | yes |") {
+		t.Fatalf("Scopes must be written, but was: %v", out)
+	}
+}
+
+func TestGetGroup(t *testing.T) {
+	if group := getGroup("/api/v1/article/{id}/history"); group != "article" {
+		t.Fatalf("Group must be first path element, but was: %v", group)
+	}
+
+	if group := getGroup("/api/v1/tag"); group != "tag" {
+		t.Fatalf("Group must be first path element, but was: %v", group)
+	}
+}
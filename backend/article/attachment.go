@@ -9,7 +9,6 @@ import (
 	"github.com/emvi/logbuch"
 	"github.com/jmoiron/sqlx"
 	"net/http"
-	"path/filepath"
 	"strings"
 	"time"
 )
@@ -52,6 +51,12 @@ func UploadAttachment(r *http.Request, orga *model.Organization, userId, article
 	return uniqueName, nil
 }
 
+// CreateAttachmentUploadSession starts a resumable upload of a file for given article or room.
+func CreateAttachmentUploadSession(data *content.UploadSessionData) (*model.UploadSession, error) {
+	data.Path = attachmentPath
+	return content.CreateUploadSession(data)
+}
+
 // Updates the article ID of all files belonging to an article.
 func updateAttachments(tx *sqlx.Tx, orgaId, articleId, langId hide.ID, roomId string) error {
 	if roomId != "" {
@@ -85,8 +90,9 @@ func cleanupAttachments(orgaId, userId, articleId hide.ID, lastContentDefTime ti
 
 	for _, file := range attachments {
 		if !attachmentExistsInContent(file.UniqueName, files) {
-			if len(model.FindFileByOrganizationIdAndUniqueNameAndNotId(orgaId, file.UniqueName, file.ID)) == 0 {
-				path := filepath.Join(file.Path, file.UniqueName)
+			// deduplicated content is removed by garbage collection
+			if !file.Blob.Valid && len(model.FindFileByOrganizationIdAndUniqueNameAndNotId(orgaId, file.UniqueName, file.ID)) == 0 {
+				path := file.StorePath()
 				logbuch.Debug("Deleting unused attachment in store", logbuch.Fields{"article_id": articleId, "path": path})
 				content.DeleteFileInStore(orgaId, userId, path)
 			}
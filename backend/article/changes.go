@@ -0,0 +1,91 @@
+package article
+
+import (
+	"emviwiki/backend/article/schema"
+	"emviwiki/backend/context"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"github.com/emvi/logbuch"
+)
+
+type ArticleChanges struct {
+	LastVisitedVersion int                  `json:"last_visited_version"`
+	Version            int                  `json:"version"`
+	Blocks             []ArticleChangeBlock `json:"blocks"`
+}
+
+// ArticleChangeBlock is a rendered top level block of the article marked as equal, inserted or deleted.
+type ArticleChangeBlock struct {
+	Op      string `json:"op"`
+	Content string `json:"content"`
+}
+
+// Returns the changes between the version the user has read last and given (unrendered) content.
+// Returns nil if the user has not read the article before or has already read the given version.
+func getArticleChanges(ctx context.EmviContext, content *model.ArticleContent, lastVisitedVersion int, format string) (*ArticleChanges, error) {
+	version := getVisitedVersion(content)
+
+	if lastVisitedVersion == 0 || lastVisitedVersion >= version {
+		return nil, nil
+	}
+
+	oldContent := model.GetArticleContentByArticleIdAndLanguageIdAndMaxVersion(content.ArticleId, content.LanguageId, lastVisitedVersion)
+
+	if oldContent == nil {
+		return nil, nil
+	}
+
+	if err := schema.Migrate(oldContent); err != nil {
+		logbuch.Error("Error migrating last visited article content", logbuch.Fields{"err": err, "article_content_id": oldContent.ID})
+		return nil, err
+	}
+
+	oldDoc, err := parseContentDoc(oldContent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newDoc, err := parseContentDoc(content)
+
+	if err != nil {
+		return nil, err
+	}
+
+	renderSchema := schema.HTMLSchema
+
+	if format == formatMarkdown {
+		renderSchema = schema.GetMarkdownSchema(ctx.Organization)
+	}
+
+	diff := prosemirror.DiffDocs(oldDoc, newDoc)
+	blocks := make([]ArticleChangeBlock, 0, len(diff))
+
+	for _, d := range diff {
+		doc := &prosemirror.Node{Type: "doc", Content: []prosemirror.Node{d.Node}}
+		out, err := RenderDocument(ctx, ctx.Organization.ID, ctx.UserId, content.LanguageId, doc, renderSchema)
+
+		if err != nil {
+			return nil, err
+		}
+
+		blocks = append(blocks, ArticleChangeBlock{d.Op, out})
+	}
+
+	return &ArticleChanges{lastVisitedVersion, version, blocks}, nil
+}
+
+func parseContentDoc(content *model.ArticleContent) (*prosemirror.Node, error) {
+	if content.Content == "" {
+		return nil, nil
+	}
+
+	doc, err := prosemirror.ParseDoc(content.Content)
+
+	if err != nil {
+		logbuch.Warn("Error parsing article content to prosemirror document", logbuch.Fields{"err": err, "article_content_id": content.ID})
+		return nil, err
+	}
+
+	return doc, nil
+}
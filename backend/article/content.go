@@ -1,7 +1,11 @@
 package article
 
 import (
+	"emviwiki/backend/article/schema"
+	"emviwiki/backend/errs"
 	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/rest"
+	"encoding/json"
 	"math"
 	"strings"
 )
@@ -28,3 +32,35 @@ func calculateReadingTimeSeconds(content string) int {
 	readingTimeSeconds := (readingTime - readingTimeMinutes) * 0.6
 	return int(readingTimeMinutes*60 + readingTimeSeconds)
 }
+
+// validateContent validates the document against the schema and returns it including default attributes.
+// Schema violations are returned as errors for the content field, followed by the path within the document.
+func validateContent(content string) (string, []error) {
+	doc, err := prosemirror.ParseDoc(content)
+
+	if err != nil {
+		return "", []error{errs.ContentInvalid}
+	}
+
+	if validationErr := prosemirror.ValidateDoc(schema.HTMLSchema, doc); len(validationErr) != 0 {
+		err := make([]error, 0, len(validationErr))
+
+		for _, e := range validationErr {
+			err = append(err, contentValidationError(e))
+		}
+
+		return "", err
+	}
+
+	out, err := json.Marshal(doc)
+
+	if err != nil {
+		return "", []error{errs.ContentInvalid}
+	}
+
+	return string(out), nil
+}
+
+func contentValidationError(err prosemirror.ValidationError) error {
+	return rest.NewApiError(err.Message, "content"+err.Path)
+}
@@ -1,6 +1,7 @@
 package article
 
 import (
+	"emviwiki/backend/customfield"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/feed"
 	"emviwiki/backend/perm"
@@ -56,6 +57,10 @@ func CopyArticle(orga *model.Organization, userId, articleId, langId hide.ID) (h
 		return 0, err
 	}
 
+	if err := customfield.SaveValues(tx, newArticle.ID, model.FindArticleCustomFieldByArticleIdTx(tx, articleId)); err != nil {
+		return 0, err
+	}
+
 	if err := createCopiedArticleFeed(tx, orga, userId, article, latestContent); err != nil {
 		return 0, err
 	}
@@ -1,7 +1,6 @@
 package article
 
 import (
-	"emviwiki/backend/content"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/feed"
 	"emviwiki/backend/perm"
@@ -28,8 +27,6 @@ func DeleteArticle(orga *model.Organization, userId, articleId hide.ID) error {
 		return errs.TxBegin
 	}
 
-	content.DeleteFileForArticle(tx, orga, userId, articleId, nil)
-
 	// create feed if public before deleting article
 	if article.ReadEveryone || article.WriteEveryone {
 		if err := createDeletedArticleFeed(tx, orga, userId, article); err != nil {
@@ -43,6 +40,12 @@ func DeleteArticle(orga *model.Organization, userId, articleId hide.ID) error {
 		return errs.Saving
 	}
 
+	// files are kept in store until the article is purged from trash
+	if err := saveArticleTrash(tx, orga, userId, articleId); err != nil {
+		logbuch.Error("Error moving article to trash", logbuch.Fields{"err": err, "article_id": article.ID, "user_id": userId})
+		return errs.Saving
+	}
+
 	if err := model.DeleteArticleById(tx, articleId); err != nil {
 		logbuch.Error("Error deleting article", logbuch.Fields{"err": err, "article_id": article.ID, "user_id": userId})
 		return errs.Saving
@@ -57,6 +60,21 @@ func DeleteArticle(orga *model.Organization, userId, articleId hide.ID) error {
 	return nil
 }
 
+func saveArticleTrash(tx *sqlx.Tx, orga *model.Organization, userId, articleId hide.ID) error {
+	langId := util.DetermineLang(tx, orga.ID, userId, 0).ID
+	latestContent := model.GetArticleContentLatestByOrganizationIdAndArticleIdAndLanguageIdTx(tx, orga.ID, articleId, langId, false)
+	trash := &model.Trash{OrganizationId: orga.ID,
+		UserId:   userId,
+		Type:     model.TrashTypeArticle,
+		ObjectId: articleId}
+
+	if latestContent != nil {
+		trash.Name = latestContent.Title
+	}
+
+	return model.SaveTrashSnapshot(tx, trash)
+}
+
 func createDeletedArticleFeed(tx *sqlx.Tx, orga *model.Organization, userId hide.ID, article *model.Article) error {
 	langId := util.DetermineLang(tx, orga.ID, userId, 0).ID
 	latestContent := model.GetArticleContentLatestByOrganizationIdAndArticleIdAndLanguageIdTx(tx, orga.ID, article.ID, langId, false)
@@ -6,6 +6,7 @@ import (
 	"emviwiki/backend/article/schema"
 	filecontent "emviwiki/backend/content"
 	"emviwiki/backend/context"
+	"emviwiki/backend/customfield"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/prosemirror"
 	"emviwiki/shared/feed"
@@ -63,6 +64,12 @@ var exportHTMLI18n = i18n.Translation{
 	},
 }
 
+// exportCustomField is a custom field value formatted for the export.
+type exportCustomField struct {
+	Name  string
+	Value string
+}
+
 // ExportArticle exports an article to given format inside a zip archive.
 // The format can be either html or markdown. If includeFiles is set to true, all attachments will be included.
 func ExportArticle(ctx context.EmviContext, articleId, langId hide.ID, format string, includeFiles bool) (io.Reader, error) {
@@ -159,7 +166,8 @@ func findAndUpdateFilePaths(doc *prosemirror.Node, node, attr string) map[hide.I
 		filename := getNodeAttrAsString(node, attr)
 
 		if filename != "" {
-			file := model.GetFileByUniqueName(filepath.Base(filename))
+			// content URLs might contain query parameters, like the image size
+			file := model.GetFileByUniqueName(filepath.Base(strings.SplitN(filename, "?", 2)[0]))
 
 			if file != nil {
 				files[file.ID] = *file
@@ -210,15 +218,16 @@ func renderArticleTemplate(ctx context.EmviContext, article *model.Article, cont
 
 	var buffer bytes.Buffer
 	data := struct {
-		Vars      map[string]template.HTML
-		LangCode  string
-		Title     string
-		Content   template.HTML
-		RTL       bool
-		Authors   []model.User
-		Tags      []model.Tag
-		Published time.Time
-		Updated   time.Time
+		Vars         map[string]template.HTML
+		LangCode     string
+		Title        string
+		Content      template.HTML
+		RTL          bool
+		Authors      []model.User
+		Tags         []model.Tag
+		CustomFields []exportCustomField
+		Published    time.Time
+		Updated      time.Time
 	}{
 		i18n.GetVars(lang.Code, exportHTMLI18n),
 		lang.Code,
@@ -227,6 +236,7 @@ func renderArticleTemplate(ctx context.EmviContext, article *model.Article, cont
 		content.RTL,
 		getAuthors(ctx, article.ID),
 		model.FindTagByOrganizationIdAndUserIdAndArticleId(ctx.Organization.ID, ctx.UserId, article.ID),
+		getExportCustomFields(ctx.Organization.ID, article.ID),
 		article.Published.Time,
 		content.DefTime,
 	}
@@ -271,12 +281,29 @@ func addMarkdownMetaData(ctx context.EmviContext, article *model.Article, conten
 	sb.WriteString(fmt.Sprintf("%s: %s\n", vars["tags"], strings.Join(tagList, ", ")))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", vars["authors"], strings.Join(authorsList, ", ")))
 	sb.WriteString(fmt.Sprintf("%s: %s\n", vars["published"], article.Published.Time.Format("2006-01-02")))
-	sb.WriteString(fmt.Sprintf("%s: %s\n\n", vars["changed"], content.DefTime.Format("2006-01-02")))
+	sb.WriteString(fmt.Sprintf("%s: %s\n", vars["changed"], content.DefTime.Format("2006-01-02")))
+
+	for _, field := range getExportCustomFields(ctx.Organization.ID, article.ID) {
+		sb.WriteString(fmt.Sprintf("%s: %s\n", field.Name, field.Value))
+	}
+
+	sb.WriteString("\n")
 	sb.WriteString(content.Content)
 	content.Content = sb.String()
 	return nil
 }
 
+func getExportCustomFields(orgaId, articleId hide.ID) []exportCustomField {
+	values := model.FindArticleCustomFieldByArticleId(articleId)
+	fields := make([]exportCustomField, 0, len(values))
+
+	for i := range values {
+		fields = append(fields, exportCustomField{values[i].Name, customfield.FormatValue(orgaId, &values[i])})
+	}
+
+	return fields
+}
+
 func createExportZip(writer *io.PipeWriter, content *model.ArticleContent, ext string, files []model.File) {
 	zipFile := zip.NewWriter(writer)
 
@@ -302,7 +329,7 @@ func createExportZip(writer *io.PipeWriter, content *model.ArticleContent, ext s
 			break
 		}
 
-		_, reader, err := filecontent.ReadFile(file.UniqueName)
+		_, reader, err := filecontent.ReadFile(file.UniqueName, "")
 
 		if err != nil {
 			logbuch.Error("Error reading attachment file to write it to export zip", logbuch.Fields{"err": err, "article_id": content.ArticleId, "file_id": file.ID})
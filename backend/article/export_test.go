@@ -3,6 +3,7 @@ package article
 import (
 	"emviwiki/backend/content"
 	"emviwiki/backend/context"
+	"emviwiki/backend/customfield"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/prosemirror"
 	"emviwiki/shared/config"
@@ -160,6 +161,13 @@ func TestFindFilesForFilesAndImages(t *testing.T) {
 	if len(images) != 1 || images[0].Attrs["src"] != "files/original_name_hoADCzmBrtfmzM3LlzDJ.jpg" {
 		t.Fatalf("Image src not as expected: %v", images)
 	}
+
+	// signed URLs contain query parameters
+	for _, node := range prosemirror.FindNodes(doc, -1, "file") {
+		if node.Attrs["file"] != "files/original_name_j06rqfiflKwSRgmtw5li.txt" {
+			t.Fatalf("File path not as expected: %v", node.Attrs)
+		}
+	}
 }
 
 func TestRenderArticleTemplate(t *testing.T) {
@@ -184,3 +192,32 @@ func TestRenderArticleTemplate(t *testing.T) {
 		t.Fatalf("Content not as expected: %v", c.Content)
 	}
 }
+
+func TestAddMarkdownMetaDataCustomFields(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	fieldId, err := customfield.SaveCustomField(orga, user.ID, &customfield.SaveCustomFieldData{Name: "Due", Type: model.CustomFieldDate})
+
+	if len(err) != 0 {
+		t.Fatal(err)
+	}
+
+	values, _ := customfield.ParseValues(orga.ID, []customfield.SaveValueData{{fieldId, "2020-01-31"}})
+
+	if err := customfield.SaveValues(nil, article.ID, values); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &model.ArticleContent{Title: "title", Content: "content", LanguageId: lang.ID, BaseEntity: db.BaseEntity{DefTime: time.Now()}}
+	ctx := context.NewEmviUserContext(orga, user.ID)
+
+	if err := addMarkdownMetaData(ctx, article, c); err != nil {
+		t.Fatalf("Meta data must have been added, but was: %v", err)
+	}
+
+	if !strings.Contains(c.Content, "Due: 2020-01-31\n") || !strings.HasSuffix(c.Content, "\ncontent") {
+		t.Fatalf("Content not as expected: %v", c.Content)
+	}
+}
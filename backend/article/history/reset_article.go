@@ -5,6 +5,7 @@ import (
 	"emviwiki/backend/context"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/feed"
+	"emviwiki/backend/live"
 	"emviwiki/backend/perm"
 	"emviwiki/shared/db"
 	"emviwiki/shared/model"
@@ -47,6 +48,10 @@ func ResetArticle(orga *model.Organization, userId, articleId, langId hide.ID, v
 		return err
 	}
 
+	if err := live.PublishArticleUpdate(tx, orga.ID, userId, content); err != nil {
+		return errs.Saving
+	}
+
 	if err := tx.Commit(); err != nil {
 		logbuch.Error("Error committing transaction when resetting article to content version", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId, "article_id": articleId})
 		return errs.TxCommit
@@ -121,6 +126,7 @@ func resetContentToVersion(tx *sqlx.Tx, lastContent, content *model.ArticleConte
 	latestContent.WIP = false
 	latestContent.UserId = userId
 	latestContent.ReadingTime = content.ReadingTime
+	latestContent.SourceVersion = content.SourceVersion
 
 	if err := model.SaveArticleContent(tx, latestContent); err != nil {
 		return errs.Saving
@@ -0,0 +1,209 @@
+package article
+
+import (
+	"emviwiki/backend/article/schema"
+	articleutil "emviwiki/backend/article/util"
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/feed"
+	"emviwiki/backend/perm"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/backend/translate"
+	"emviwiki/shared/db"
+	"emviwiki/shared/model"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+// TranslateArticleData is the target language to translate an article into.
+// The source language is optional and defaults to the default language of the organization.
+type TranslateArticleData struct {
+	SourceLanguageId hide.ID `json:"source_language_id"`
+	LanguageId       hide.ID `json:"language_id"`
+}
+
+// TranslateArticle creates a draft translation of the last version of an article using the configured translation provider.
+// The translation is saved as a new WIP version in the target language, so that it can be reviewed before it gets published.
+// Translating takes a while for long articles, so it runs in background and the user is notified through the feed when it's done.
+func TranslateArticle(ctx context.EmviContext, articleId hide.ID, data TranslateArticleData) error {
+	if !translate.Enabled() {
+		return errs.TranslationDisabled
+	}
+
+	article, err := articleutil.GetArticleWithAccess(nil, ctx, articleId, false)
+
+	if err != nil {
+		return err
+	}
+
+	if !article.WriteEveryone && !perm.CheckUserWriteAccess(articleId, ctx.UserId) {
+		return errs.PermissionDenied
+	}
+
+	source, target, err := getTranslationLanguages(ctx.Organization.ID, data)
+
+	if err != nil {
+		return err
+	}
+
+	sourceContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, source.ID, false)
+
+	if sourceContent == nil {
+		return errs.FindingLatestArticleContent
+	}
+
+	go func() {
+		_, err := translateArticle(ctx, article, sourceContent, source, target)
+		createTranslateArticleFeed(ctx, article, sourceContent, target, err)
+	}()
+
+	return nil
+}
+
+// Translates and saves the source content as a new WIP version in the target language and returns it.
+func translateArticle(ctx context.EmviContext, article *model.Article, sourceContent *model.ArticleContent, source, target *model.Language) (*model.ArticleContent, error) {
+	saveData, err := translateContent(ctx, sourceContent, source, target)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if errList := saveData.validate(); len(errList) != 0 {
+		return nil, errList[0]
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error beginning transaction when saving machine translation", logbuch.Fields{"err": err, "article_id": article.ID})
+		return nil, errs.TxBegin
+	}
+
+	// read the article again, as it might have been changed while translating
+	article = model.GetArticleByOrganizationIdAndIdForUpdateTx(tx, ctx.Organization.ID, article.ID)
+
+	if article == nil {
+		db.Rollback(tx)
+		return nil, errs.ArticleNotFound
+	}
+
+	lastCommit := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIPTx(tx, article.ID, target.ID, true)
+
+	// set WIP version the same way it's done when saving the article as WIP
+	if article.WIP == -1 {
+		article.WIP = 1
+
+		if lastCommit != nil {
+			article.WIP = lastCommit.Version
+		}
+
+		if err := model.SaveArticle(tx, article); err != nil {
+			logbuch.Error("Error saving article when saving machine translation", logbuch.Fields{"err": err, "article_id": article.ID})
+			return nil, errs.Saving
+		}
+	}
+
+	content, err := saveContent(tx, article.ID, saveData, lastCommit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction when saving machine translation", logbuch.Fields{"err": err, "article_id": article.ID})
+		return nil, errs.TxCommit
+	}
+
+	return content, nil
+}
+
+// Notifies the user who started the translation whether it succeeded.
+func createTranslateArticleFeed(ctx context.EmviContext, article *model.Article, sourceContent *model.ArticleContent, target *model.Language, translateErr error) {
+	reason := "article_translated"
+
+	if translateErr != nil {
+		reason = "article_translation_failed"
+	}
+
+	refs := make([]interface{}, 3)
+	refs[0] = article
+	refs[1] = sourceContent
+	refs[2] = feed.KeyValue{"language", target.Name}
+	feedData := &feed.CreateFeedData{Organization: ctx.Organization,
+		UserId:        ctx.UserId,
+		Reason:        reason,
+		Public:        false,
+		Access:        []hide.ID{},
+		Notify:        []hide.ID{ctx.UserId},
+		NotifyCreator: true,
+		Refs:          refs}
+
+	if err := feed.CreateFeed(feedData); err != nil {
+		logbuch.Error("Error creating feed when translating article", logbuch.Fields{"err": err, "article_id": article.ID})
+	}
+}
+
+func getTranslationLanguages(orgaId hide.ID, data TranslateArticleData) (*model.Language, *model.Language, error) {
+	var source *model.Language
+
+	if data.SourceLanguageId == 0 {
+		source = model.GetDefaultLanguageByOrganizationId(orgaId)
+	} else {
+		source = model.GetLanguageByOrganizationIdAndId(orgaId, data.SourceLanguageId)
+	}
+
+	target := model.GetLanguageByOrganizationIdAndId(orgaId, data.LanguageId)
+
+	if source == nil || target == nil {
+		return nil, nil, errs.LanguageNotFound
+	}
+
+	if source.ID == target.ID {
+		return nil, nil, errs.TranslationLanguageInvalid
+	}
+
+	return source, target, nil
+}
+
+func translateContent(ctx context.EmviContext, content *model.ArticleContent, source, target *model.Language) (*SaveArticleData, error) {
+	if err := schema.Migrate(content); err != nil {
+		return nil, errs.Saving
+	}
+
+	doc, err := prosemirror.ParseDoc(content.Content)
+
+	if err != nil {
+		logbuch.Error("Error parsing content when translating article", logbuch.Fields{"err": err, "article_content_id": content.ID})
+		return nil, errs.Saving
+	}
+
+	title, err := translate.TranslateContent(content.Title, doc, source.Code, target.Code)
+
+	if err != nil {
+		logbuch.Error("Error translating article", logbuch.Fields{"err": err, "article_content_id": content.ID, "source": source.Code, "target": target.Code})
+		return nil, errs.Translating
+	}
+
+	out, err := json.Marshal(doc)
+
+	if err != nil {
+		return nil, errs.Saving
+	}
+
+	// the translation might be longer than the original
+	if runes := []rune(title); len(runes) > maxTitleLen {
+		title = string(runes[:maxTitleLen])
+	}
+
+	// the draft is based on the translated version if translated from the default language
+	return &SaveArticleData{Organization: ctx.Organization,
+		UserId:       ctx.UserId,
+		LanguageId:   target.ID,
+		Authors:      []hide.ID{ctx.UserId},
+		Wip:          true,
+		Title:        title,
+		Content:      string(out),
+		RTL:          content.RTL,
+		SourceSynced: source.Default}, nil
+}
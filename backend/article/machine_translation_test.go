@@ -0,0 +1,120 @@
+package article
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/translate"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"strings"
+	"testing"
+)
+
+// testTranslationProvider "translates" by turning "content" to upper case.
+type testTranslationProvider struct{}
+
+func (p testTranslationProvider) Translate(texts []string, source, target string) ([]string, error) {
+	result := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		result = append(result, strings.ReplaceAll(text, "content", "CONTENT"))
+	}
+
+	return result, nil
+}
+
+func TestTranslateArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	ja := model.GetLanguageByOrganizationIdAndCode(orga.ID, "ja")
+	ctx := context.NewEmviUserContext(orga, user.ID)
+	id, err := SaveArticle(SaveArticleData{Organization: orga,
+		UserId:       user.ID,
+		LanguageId:   lang.ID,
+		ReadEveryone: true,
+		Title:        "title content",
+		Content:      simpleSampleDoc})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := TranslateArticleData{LanguageId: ja.ID}
+
+	if err := TranslateArticle(ctx, id, data); err != errs.TranslationDisabled {
+		t.Fatalf("Machine translation must be disabled, but was: %v", err)
+	}
+
+	translate.SetProvider(testTranslationProvider{})
+	defer translate.SetProvider(nil)
+
+	if err := TranslateArticle(ctx, id, TranslateArticleData{LanguageId: lang.ID}); err != errs.TranslationLanguageInvalid {
+		t.Fatalf("Source and target language must differ, but was: %v", err)
+	}
+
+	// translate synchronously, TranslateArticle runs this in background
+	article := model.GetArticleByOrganizationIdAndId(orga.ID, id)
+	sourceContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(id, lang.ID, false)
+	content, translateErr := translateArticle(ctx, article, sourceContent, lang, ja)
+
+	if translateErr != nil {
+		t.Fatalf("Article must have been translated, but was: %v", translateErr)
+	}
+
+	if content.Version != 1 {
+		t.Fatalf("Translation must be version 1, but was: %v", content.Version)
+	}
+
+	content = model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(id, ja.ID, true)
+
+	if content == nil || !content.WIP || content.Title != "title CONTENT" || !strings.Contains(content.Content, `"text":"CONTENT"`) {
+		t.Fatalf("Translation must have been saved as WIP, but was: %v", content)
+	}
+
+	if model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(id, ja.ID, false) != nil {
+		t.Fatal("Translation must not have been published")
+	}
+
+	if article := model.GetArticleByOrganizationIdAndId(orga.ID, id); article.WIP != 1 {
+		t.Fatalf("Article must have WIP version, but was: %v", article.WIP)
+	}
+
+	createTranslateArticleFeed(ctx, article, sourceContent, ja, nil)
+
+	if len(model.FindFeedByOrganizationIdAndReason(orga.ID, "article_translated")) != 1 {
+		t.Fatal("Feed must have been created for translation")
+	}
+
+	if model.CountFeedAccessByOrganizationIdAndUserIdAndNotificationAndRead(orga.ID, user.ID, true, false) != 1 {
+		t.Fatal("User must have been notified about the translation")
+	}
+}
+
+func TestTranslateArticleAccess(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	ja := model.GetLanguageByOrganizationIdAndCode(orga.ID, "ja")
+	reader := testutil.CreateUser(t, orga, 321, "reader@user.com")
+	translate.SetProvider(testTranslationProvider{})
+	defer translate.SetProvider(nil)
+	id, err := SaveArticle(SaveArticleData{Organization: orga,
+		UserId:       user.ID,
+		LanguageId:   lang.ID,
+		ReadEveryone: true,
+		Title:        "title",
+		Content:      simpleSampleDoc})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := TranslateArticle(context.NewEmviUserContext(orga, reader.ID), id, TranslateArticleData{LanguageId: ja.ID}); err != errs.PermissionDenied {
+		t.Fatalf("Reader must not be allowed to translate article, but was: %v", err)
+	}
+
+	if err := TranslateArticle(context.NewEmviUserContext(orga, user.ID), id, TranslateArticleData{SourceLanguageId: ja.ID, LanguageId: lang.ID}); err != errs.FindingLatestArticleContent {
+		t.Fatalf("Source content must not have been found, but was: %v", err)
+	}
+}
@@ -0,0 +1,316 @@
+package article
+
+import (
+	"emviwiki/backend/article/schema"
+	articleutil "emviwiki/backend/article/util"
+	filecontent "emviwiki/backend/content"
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/feed"
+	"emviwiki/backend/live"
+	"emviwiki/backend/perm"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/constants"
+	"emviwiki/shared/db"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+const (
+	schemaHeadlineType = "headline"
+)
+
+// PatchArticleData is a partial update of the latest article content.
+// Either a list of Prosemirror steps or a block to append to a section must be passed.
+type PatchArticleData struct {
+	LanguageId hide.ID `json:"language_id"`
+
+	// Version is the version the steps are based on. It is optional,
+	// but should be set when passing steps, as positions become invalid when the article was changed in between.
+	Version   int                `json:"version"`
+	Steps     []prosemirror.Step `json:"steps"`
+	Section   string             `json:"section"`
+	Block     *prosemirror.Node  `json:"block"`
+	CommitMsg string             `json:"message"`
+
+	// RequireConfirmation marks the change as significant,
+	// so that members of reading campaigns for the article must confirm the new version again.
+	RequireConfirmation bool `json:"require_confirmation"`
+}
+
+func (data *PatchArticleData) validate() error {
+	data.Section = strings.TrimSpace(data.Section)
+	data.CommitMsg = strings.TrimSpace(data.CommitMsg)
+
+	if len(data.Steps) == 0 && data.Block == nil {
+		return errs.ArticlePatchEmpty
+	}
+
+	if data.Block != nil && data.Section == "" {
+		return errs.SectionNotFound
+	}
+
+	return articleutil.CheckCommitMsg(data.CommitMsg)
+}
+
+// PatchArticle applies the steps or appends the block to the latest version of the article and saves it as a new version.
+// It returns the new version.
+func PatchArticle(ctx context.EmviContext, articleId hide.ID, data PatchArticleData) (int, error) {
+	if err := data.validate(); err != nil {
+		return 0, err
+	}
+
+	article, err := articleutil.GetArticleWithAccess(nil, ctx, articleId, false)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if !article.WriteEveryone && !perm.CheckUserWriteAccess(articleId, ctx.UserId) {
+		return 0, errs.PermissionDenied
+	}
+
+	langId := util.DetermineLang(nil, ctx.Organization.ID, ctx.UserId, data.LanguageId).ID
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error beginning transaction when patching article", logbuch.Fields{"err": err, "article_id": articleId})
+		return 0, errs.TxBegin
+	}
+
+	// lock the article, so that concurrent patches are based on the latest version
+	lockedArticle := model.GetArticleByOrganizationIdAndIdForUpdateTx(tx, ctx.Organization.ID, articleId)
+
+	if lockedArticle == nil {
+		db.Rollback(tx)
+		return 0, errs.ArticleNotFound
+	}
+
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIPTx(tx, articleId, langId, false)
+
+	if lastContent == nil {
+		db.Rollback(tx)
+		return 0, errs.FindingLatestArticleContent
+	}
+
+	if data.Version != 0 && data.Version != lastContent.Version {
+		db.Rollback(tx)
+		return 0, errs.ArticleContentVersionOutdated
+	}
+
+	if err := schema.Migrate(lastContent); err != nil {
+		db.Rollback(tx)
+		return 0, errs.Saving
+	}
+
+	doc, err := patchContent(lastContent, &data)
+
+	if err != nil {
+		db.Rollback(tx)
+		return 0, err
+	}
+
+	content, err := savePatchedContent(tx, lastContent, doc, ctx.UserId, data.CommitMsg)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// updates the modification time of the article
+	if err := model.SaveArticle(tx, lockedArticle); err != nil {
+		logbuch.Error("Error saving article when patching article", logbuch.Fields{"err": err, "article_id": articleId})
+		return 0, errs.Saving
+	}
+
+	if err := createPatchArticleFeed(tx, ctx.Organization, ctx.UserId, article, content); err != nil {
+		return 0, err
+	}
+
+	if err := live.PublishArticleUpdate(tx, ctx.Organization.ID, ctx.UserId, content); err != nil {
+		return 0, errs.Saving
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction when patching article", logbuch.Fields{"err": err, "article_id": articleId})
+		return 0, errs.TxCommit
+	}
+
+	// tags, access and authors are not changed by a patch, so only the actions depending on the content are executed
+	notifyTranslators(ctx.Organization, ctx.UserId, article, content, lastContent.Version)
+
+	if data.RequireConfirmation {
+		requireReadingCampaignReconfirmation(ctx.Organization, ctx.UserId, content)
+	}
+
+	cleanupAttachmentsAndNotifyMentions(ctx.Organization, ctx.UserId, article, content, lastContent.DefTime)
+	return content.Version, nil
+}
+
+func patchContent(content *model.ArticleContent, data *PatchArticleData) (*prosemirror.Node, error) {
+	doc, err := prosemirror.ParseDoc(content.Content)
+
+	if err != nil {
+		logbuch.Error("Error parsing content while patching article", logbuch.Fields{"err": err, "article_content_id": content.ID})
+		return nil, errs.Saving
+	}
+
+	steps := data.Steps
+
+	if data.Block != nil {
+		step, err := appendToSectionStep(doc, data.Section, data.Block)
+
+		if err != nil {
+			return nil, err
+		}
+
+		steps = append(steps, *step)
+	}
+
+	doc, err = prosemirror.ApplySteps(schema.HTMLSchema, doc, steps)
+
+	if err != nil {
+		logbuch.Debug("Error applying steps while patching article", logbuch.Fields{"err": err, "article_content_id": content.ID})
+
+		if validationErr, ok := err.(prosemirror.ValidationError); ok {
+			return nil, contentValidationError(validationErr)
+		}
+
+		if data.Block != nil {
+			return nil, errs.ArticleBlockInvalid
+		}
+
+		return nil, errs.ArticleStepsInvalid
+	}
+
+	return doc, nil
+}
+
+// Returns a step inserting the block at the end of the section starting with the given headline.
+// The section ends before the next headline of the same or a higher level.
+func appendToSectionStep(doc *prosemirror.Node, section string, block *prosemirror.Node) (*prosemirror.Step, error) {
+	pos, level := 0, -1
+
+	for i := range doc.Content {
+		node := &doc.Content[i]
+
+		if node.Type == schemaHeadlineType {
+			nodeLevel := getHeadlineLevel(node)
+
+			if level != -1 && nodeLevel <= level {
+				break
+			} else if level == -1 && strings.EqualFold(strings.TrimSpace(extractTextFromContent(node)), section) {
+				level = nodeLevel
+			}
+		}
+
+		size, err := prosemirror.NodeSize(schema.HTMLSchema, node)
+
+		if err != nil {
+			return nil, errs.ArticleBlockInvalid
+		}
+
+		pos += size
+	}
+
+	if level == -1 {
+		return nil, errs.SectionNotFound
+	}
+
+	return &prosemirror.Step{StepType: prosemirror.StepReplace,
+		From:  pos,
+		To:    pos,
+		Slice: &prosemirror.Slice{Content: []prosemirror.Node{*block}}}, nil
+}
+
+func getHeadlineLevel(node *prosemirror.Node) int {
+	// numbers are decoded as float64 from JSON
+	if level, ok := node.Attrs["level"].(float64); ok {
+		return int(level)
+	}
+
+	return 0
+}
+
+func savePatchedContent(tx *sqlx.Tx, lastContent *model.ArticleContent, doc *prosemirror.Node, userId hide.ID, commit string) (*model.ArticleContent, error) {
+	out, err := json.Marshal(doc)
+
+	if err != nil {
+		db.Rollback(tx)
+		logbuch.Error("Error marshalling content when patching article", logbuch.Fields{"err": err, "article_id": lastContent.ArticleId})
+		return nil, errs.Saving
+	}
+
+	textContent := extractTextFromContent(doc)
+	content := &model.ArticleContent{ArticleId: lastContent.ArticleId,
+		LanguageId:      lastContent.LanguageId,
+		UserId:          userId,
+		Title:           lastContent.Title,
+		Content:         filecontent.UnsignContentURLs(string(out)),
+		Version:         lastContent.Version + 1,
+		Commit:          null.NewString(commit, commit != ""),
+		TitleTsvector:   lastContent.Title,
+		ContentTsvector: textContent,
+		ReadingTime:     calculateReadingTimeSeconds(textContent),
+		SchemaVersion:   constants.LatestSchemaVersion,
+		RTL:             lastContent.RTL,
+		SourceVersion:   lastContent.SourceVersion}
+
+	if err := model.SaveArticleContent(tx, content); err != nil {
+		logbuch.Error("Error saving article content when patching article", logbuch.Fields{"err": err, "article_id": lastContent.ArticleId})
+		return nil, errs.Saving
+	}
+
+	if err := saveAuthors(tx, content.ID, []hide.ID{userId}); err != nil {
+		return nil, err
+	}
+
+	latestContent := model.GetArticleContentLatestByArticleIdAndLanguageIdTx(tx, content.ArticleId, content.LanguageId, false)
+
+	if latestContent == nil {
+		db.Rollback(tx)
+		return nil, errs.FindingLatestArticleContent
+	}
+
+	latestContent.Content = content.Content
+	latestContent.Commit = content.Commit
+	latestContent.WIP = false
+	latestContent.UserId = userId
+	latestContent.ContentTsvector = content.ContentTsvector
+	latestContent.ReadingTime = content.ReadingTime
+	latestContent.SchemaVersion = content.SchemaVersion
+	latestContent.SourceVersion = content.SourceVersion
+
+	if err := model.SaveArticleContent(tx, latestContent); err != nil {
+		logbuch.Error("Error saving latest article content when patching article", logbuch.Fields{"err": err, "article_id": lastContent.ArticleId})
+		return nil, errs.Saving
+	}
+
+	return content, nil
+}
+
+func createPatchArticleFeed(tx *sqlx.Tx, orga *model.Organization, userId hide.ID, article *model.Article, content *model.ArticleContent) error {
+	refs := make([]interface{}, 2)
+	refs[0] = article
+	refs[1] = content
+	feedData := &feed.CreateFeedData{Tx: tx,
+		Organization: orga,
+		UserId:       userId,
+		Reason:       "update_article",
+		Public:       article.ReadEveryone || article.WriteEveryone,
+		Access:       perm.GetUserIdsFromAccess(tx, article.Access),
+		Notify:       model.FindObservedObjectUserIdByArticleIdOrArticleListIdTx(tx, article.ID, 0),
+		Refs:         refs}
+
+	if err := feed.CreateFeed(feedData); err != nil {
+		logbuch.Error("Error creating feed when patching article", logbuch.Fields{"err": err})
+		return errs.Saving
+	}
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package article
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/hide"
+	"testing"
+)
+
+const (
+	samplePatchArticleContent = `{"type":"doc","content":[{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"Changelog"}]},{"type":"headline","attrs":{"level":3},"content":[{"type":"text","text":"1.0"}]},{"type":"paragraph","content":[{"type":"text","text":"First release"}]},{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"License"}]}]}`
+)
+
+func TestPatchArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, false, false)
+	userNoAccess := testutil.CreateUser(t, orga, 321, "noaccess@user.com")
+	setPatchArticleContent(t, article.ID, lang.ID)
+	modTime := model.GetArticleByOrganizationIdAndId(orga.ID, article.ID).ModTime
+	ctx := context.NewEmviUserContext(orga, user.ID)
+	block := &prosemirror.Node{Type: "paragraph", Content: []prosemirror.Node{{Type: "text", Text: "Second release"}}}
+	input := []struct {
+		ctx       context.EmviContext
+		articleId hide.ID
+		data      PatchArticleData
+	}{
+		{ctx, article.ID, PatchArticleData{}},
+		{ctx, 0, PatchArticleData{Block: block, Section: "Changelog"}},
+		{context.NewEmviUserContext(orga, userNoAccess.ID), article.ID, PatchArticleData{Block: block, Section: "Changelog"}},
+		{ctx, article.ID, PatchArticleData{Block: block}},
+		{ctx, article.ID, PatchArticleData{Block: block, Section: "Unknown"}},
+		{ctx, article.ID, PatchArticleData{Block: &prosemirror.Node{Type: "unknown"}, Section: "Changelog"}},
+		{ctx, article.ID, PatchArticleData{Block: block, Section: "Changelog", Version: 1}},
+		{ctx, article.ID, PatchArticleData{Steps: []prosemirror.Step{{StepType: prosemirror.StepReplace, From: 0, To: 3}}}},
+		{ctx, article.ID, PatchArticleData{Block: block, Section: " changelog ", Version: 2, CommitMsg: "Release 2.0"}},
+	}
+	expected := []error{
+		errs.ArticlePatchEmpty,
+		errs.ArticleNotFound,
+		errs.PermissionDenied,
+		errs.SectionNotFound,
+		errs.SectionNotFound,
+		errs.ArticleBlockInvalid,
+		errs.ArticleContentVersionOutdated,
+		errs.ArticleStepsInvalid,
+		nil,
+	}
+
+	for i, in := range input {
+		if _, err := PatchArticle(in.ctx, in.articleId, in.data); err != expected[i] {
+			t.Fatalf("Expected '%v' for input %d, but was: %v", expected[i], i, err)
+		}
+	}
+
+	expectedContent := `{"type":"doc","content":[{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"Changelog"}]},{"type":"headline","attrs":{"level":3},"content":[{"type":"text","text":"1.0"}]},{"type":"paragraph","content":[{"type":"text","text":"First release"}]},{"type":"paragraph","content":[{"type":"text","text":"Second release"}]},{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"License"}]}]}`
+	content := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(article.ID, lang.ID, false)
+
+	if content == nil || content.Version != 3 || content.Commit.String != "Release 2.0" || content.UserId != user.ID {
+		t.Fatalf("New version must have been created, but was: %v", content)
+	}
+
+	testutil.AssertJSONEquals(t, content.Content, expectedContent)
+	latestContent := model.GetArticleContentLatestByArticleIdAndLanguageId(article.ID, lang.ID, false)
+
+	if latestContent == nil || latestContent.Title != "title 2" {
+		t.Fatalf("Latest content must have been updated, but was: %v", latestContent)
+	}
+
+	testutil.AssertJSONEquals(t, latestContent.Content, expectedContent)
+	patchedArticle := model.GetArticleByOrganizationIdAndId(orga.ID, article.ID)
+
+	if patchedArticle == nil || !patchedArticle.ModTime.After(modTime) {
+		t.Fatalf("Article modification time must have been updated, but was: %v", patchedArticle)
+	}
+}
+
+func TestPatchArticleSteps(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, false, false)
+	setPatchArticleContent(t, article.ID, lang.ID)
+	steps := []prosemirror.Step{
+		{StepType: prosemirror.StepReplace, From: 17, To: 22, Slice: &prosemirror.Slice{Content: []prosemirror.Node{{Type: "text", Text: "Initial"}}}},
+		{StepType: prosemirror.StepAddMark, From: 17, To: 24, Mark: &prosemirror.Mark{Type: "bold"}},
+	}
+	version, err := PatchArticle(context.NewEmviUserContext(orga, user.ID), article.ID, PatchArticleData{Steps: steps})
+
+	if err != nil || version != 3 {
+		t.Fatalf("Steps must have been applied, but was: %v %v", version, err)
+	}
+
+	content := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(article.ID, lang.ID, false)
+	testutil.AssertJSONEquals(t, content.Content, `{"type":"doc","content":[{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"Changelog"}]},{"type":"headline","attrs":{"level":3},"content":[{"type":"text","text":"1.0"}]},{"type":"paragraph","content":[{"type":"text","text":"Initial","marks":[{"type":"bold"}]},{"type":"text","text":" release"}]},{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"License"}]}]}`)
+}
+
+func setPatchArticleContent(t *testing.T, articleId, langId hide.ID) {
+	for _, content := range []*model.ArticleContent{
+		model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, langId, false),
+		model.GetArticleContentLatestByArticleIdAndLanguageId(articleId, langId, false),
+	} {
+		content.Content = samplePatchArticleContent
+
+		if err := model.SaveArticleContent(nil, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	articleutil "emviwiki/backend/article/util"
 	"emviwiki/backend/bookmark"
 	"emviwiki/backend/client"
+	filecontent "emviwiki/backend/content"
 	"emviwiki/backend/context"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/observe"
@@ -17,7 +18,6 @@ import (
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
 	"strings"
-	"time"
 )
 
 const (
@@ -40,12 +40,26 @@ type ArticleResult struct {
 	IsObserved      bool
 	IsBookmarked    bool
 	Recommendations []model.ArticleRecommendation
+	Changes         *ArticleChanges
+}
+
+// SignContentURLs signs the attachment URLs in the content and changes for given organization and user,
+// as attachments can only be downloaded using signed URLs.
+func (result *ArticleResult) SignContentURLs(orgaId, userId hide.ID) {
+	result.Content.Content = filecontent.SignContentURLs(result.Content.Content, orgaId, userId)
+
+	if result.Changes != nil {
+		for i := range result.Changes.Blocks {
+			result.Changes.Blocks[i].Content = filecontent.SignContentURLs(result.Changes.Blocks[i].Content, orgaId, userId)
+		}
+	}
 }
 
 // ReadArticle reads an article and renders its content if so desired.
 // In addition to the article, all relevant meta data is returned.
 // The format can be either HTML or Markdown.
-func ReadArticle(ctx context.EmviContext, articleId, langId hide.ID, version int, renderContent bool, format string) (ArticleResult, error) {
+// If changes is set and the user has read an older version of the article before, the changes since are returned too.
+func ReadArticle(ctx context.EmviContext, articleId, langId hide.ID, version int, renderContent bool, format string, changes bool) (ArticleResult, error) {
 	article, err := articleutil.GetArticleWithAccess(nil, ctx, articleId, true)
 
 	if err != nil {
@@ -70,11 +84,23 @@ func ReadArticle(ctx context.EmviContext, articleId, langId hide.ID, version int
 		article.Tags = model.FindTagByOrganizationIdAndUserIdAndArticleId(ctx.Organization.ID, ctx.UserId, articleId)
 	}
 
+	article.CustomFields = model.FindArticleCustomFieldByArticleId(articleId)
+
 	isObserved := false
 	isBookmarked := false
 	writeAccess := false
+	var articleChanges *ArticleChanges
 
 	if ctx.IsUser() {
+		if changes && version == 0 {
+			lastVisitedVersion := model.GetArticleVisitLastVersionByArticleIdAndUserIdAndLanguageId(articleId, ctx.UserId, content.LanguageId)
+			articleChanges, err = getArticleChanges(ctx, content, lastVisitedVersion, strings.ToLower(format))
+
+			if err != nil {
+				return ArticleResult{}, err
+			}
+		}
+
 		article.Access = model.FindArticleAccessByOrganizationIdAndArticleId(ctx.Organization.ID, article.ID)
 		isObserved = observe.IsObserved(ctx.UserId, article.ID, 0, 0)
 		isBookmarked = bookmark.IsBookmarked(ctx.UserId, articleId, 0)
@@ -97,7 +123,7 @@ func ReadArticle(ctx context.EmviContext, articleId, langId hide.ID, version int
 	}
 
 	if ctx.IsUser() {
-		updateArticleViews(article, ctx.UserId)
+		updateArticleViews(article, content, ctx.UserId)
 	}
 
 	return ArticleResult{
@@ -108,6 +134,7 @@ func ReadArticle(ctx context.EmviContext, articleId, langId hide.ID, version int
 		isObserved,
 		isBookmarked,
 		getRecommendations(articleId, ctx.UserId),
+		articleChanges,
 	}, nil
 }
 
@@ -191,20 +218,34 @@ func hasWriteAccess(article *model.Article, userId hide.ID) bool {
 	return perm.CheckUserWriteAccess(article.ID, userId)
 }
 
-func updateArticleViews(article *model.Article, userId hide.ID) {
-	if model.GetArticleVisitByArticleIdAndUserIdAndDefTimeAfter(article.ID, userId, time.Now().Add(-time.Hour*24)) == nil {
-		visit := &model.ArticleVisit{ArticleId: article.ID, UserId: userId}
+// Buffers a view of the article by the user in an append-only table.
+// Views are merged into the article visits and views periodically by a batch process,
+// so that reading an article does not need to update the article itself.
+func updateArticleViews(article *model.Article, content *model.ArticleContent, userId hide.ID) {
+	view := &model.ArticleView{ArticleId: article.ID,
+		UserId:     userId,
+		LanguageId: content.LanguageId,
+		Version:    getVisitedVersion(content)}
+
+	if err := model.SaveArticleView(nil, view); err != nil {
+		logbuch.Error("Error saving article view", logbuch.Fields{"err": err, "article_id": article.ID, "user_id": userId})
+	}
+}
 
-		if err := model.SaveArticleVisit(nil, visit); err != nil {
-			logbuch.Error("Error saving article visit", logbuch.Fields{"err": err, "article_id": article.ID, "user_id": userId})
-		}
+// Returns the version of given content the user has read.
+// The latest content is always marked as version 0, so the last published version is looked up instead.
+func getVisitedVersion(content *model.ArticleContent) int {
+	if content.Version != 0 || content.ID == 0 {
+		return content.Version
+	}
 
-		article.Views++
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(content.ArticleId, content.LanguageId, false)
 
-		if err := model.SaveArticle(nil, article); err != nil {
-			logbuch.Error("Error saving article when updating views", logbuch.Fields{"err": err, "article_id": article.ID, "user_id": userId})
-		}
+	if lastContent == nil {
+		return 0
 	}
+
+	return lastContent.Version
 }
 
 func renderArticleContent(ctx context.EmviContext, orgaId, userId hide.ID, content *model.ArticleContent, schema *prosemirror.Schema) (string, error) {
@@ -20,7 +20,7 @@ func TestReadArticleNotFound(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
 
-	if _, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), 123, 321, 0, false, formatHTML); err != errs.ArticleNotFound {
+	if _, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), 123, 321, 0, false, formatHTML, false); err != errs.ArticleNotFound {
 		t.Fatal("Article must not be found")
 	}
 }
@@ -35,7 +35,7 @@ func TestReadArticleNoAccess(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	if _, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 0, false, formatHTML); err != errs.PermissionDenied {
+	if _, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 0, false, formatHTML, false); err != errs.PermissionDenied {
 		t.Fatal("Permission must be denied")
 	}
 }
@@ -47,11 +47,11 @@ func TestReadArticleContentDoesNotExist(t *testing.T) {
 	langRu := testutil.CreateLang(t, orga, "ru", "Russian", false)
 	article := testutil.CreateArticle(t, orga, user, langRu, true, true)
 
-	if result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, langEn.ID, 0, false, formatHTML); err != nil || result.Content == nil {
+	if result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, langEn.ID, 0, false, formatHTML, false); err != nil || result.Content == nil {
 		t.Fatal("Empty content must be returned if it does not exist")
 	}
 
-	if result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, langEn.ID, 0, false, formatHTML); err != nil || result.Content == nil {
+	if result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, langEn.ID, 0, false, formatHTML, false); err != nil || result.Content == nil {
 		t.Fatal("Empty content must be returned if it does not exist")
 	}
 }
@@ -64,7 +64,7 @@ func TestReadArticleGroupAccess(t *testing.T) {
 	article := testutil.CreateArticleWithoutContent(t, orga, user, lang, false, false)
 	user2 := testutil.CreateUser(t, orga, hide.ID(567), "group@access.com")
 
-	if _, err := ReadArticle(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0, false, formatHTML); err != errs.PermissionDenied {
+	if _, err := ReadArticle(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0, false, formatHTML, false); err != errs.PermissionDenied {
 		t.Fatal("Permission must be denied for user without access via group")
 	}
 
@@ -72,7 +72,7 @@ func TestReadArticleGroupAccess(t *testing.T) {
 	testutil.CreateUserGroupMember(t, group, user2, false)
 	testutil.CreateArticleAccess(t, article, nil, group, true)
 
-	if _, err := ReadArticle(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0, false, formatHTML); err != nil {
+	if _, err := ReadArticle(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0, false, formatHTML, false); err != nil {
 		t.Fatal("User must have access via group")
 	}
 }
@@ -93,13 +93,14 @@ func TestReadArticle(t *testing.T) {
 	testutil.CreateArticleContentAuthor(t, user, content)
 	testutil.CreateObservedObject(t, user, article, nil, nil)
 	testutil.CreateBookmark(t, orga, user, article, nil)
-	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML)
+	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML, false)
 
 	if err != nil || result.Article == nil || result.Content == nil {
 		t.Fatal("Article and content must be returned")
 	}
 
-	if result.Article.Views != 55 || len(result.Article.Tags) != 4 {
+	// views are merged asynchronously
+	if result.Article.Views != 54 || len(result.Article.Tags) != 4 || model.CountArticleView() != 1 {
 		t.Fatalf("Article not as expected, was: %v", result.Article)
 	}
 
@@ -144,7 +145,7 @@ func TestReadArticleMarkdown(t *testing.T) {
 	}
 
 	ctx := context.NewEmviUserContext(orga, user.ID)
-	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, true, formatMarkdown)
+	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, true, formatMarkdown, false)
 
 	if err != nil {
 		t.Fatalf("Article with markdown content must have been returned, but was: %v", err)
@@ -198,7 +199,7 @@ func TestReadArticleClient(t *testing.T) {
 		}
 
 		ctx := context.NewEmviContext(orga, 0, in.scopes, false)
-		result, err := ReadArticle(ctx, article.ID, 0, 0, false, formatHTML)
+		result, err := ReadArticle(ctx, article.ID, 0, 0, false, formatHTML, false)
 
 		if err != expected[i].err {
 			t.Fatalf("Expected '%v', but was: %v", expected[i].err, err)
@@ -258,7 +259,7 @@ func TestReadArticleUserTagUsageCount(t *testing.T) {
 	testutil.CreateArticleTag(t, article, tag)
 	testutil.CreateArticleTag(t, article2, tag)
 	ctx := context.NewEmviUserContext(orga, user.ID)
-	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -266,7 +267,7 @@ func TestReadArticleUserTagUsageCount(t *testing.T) {
 
 	testUsageCountTagFound(t, result, 1)
 	testutil.CreateArticleAccess(t, article2, user, nil, false)
-	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -289,7 +290,7 @@ func TestReadArticleUserTagUsageCountThroughGroup(t *testing.T) {
 	group := testutil.CreateUserGroup(t, orga, "name")
 	testutil.CreateUserGroupMember(t, group, user, false)
 	ctx := context.NewEmviUserContext(orga, user.ID)
-	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -297,7 +298,7 @@ func TestReadArticleUserTagUsageCountThroughGroup(t *testing.T) {
 
 	testUsageCountTagFound(t, result, 1)
 	testutil.CreateArticleAccess(t, article2, nil, group, false)
-	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -324,7 +325,7 @@ func TestReadArticleClientTagUsageCount(t *testing.T) {
 	}
 
 	ctx := context.NewEmviContext(orga, 0, []string{client.Scopes["tags"].String()}, false)
-	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -332,7 +333,7 @@ func TestReadArticleClientTagUsageCount(t *testing.T) {
 
 	testUsageCountTagFound(t, result, 1)
 	testutil.SetArticleClientAccess(t, article2)
-	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML)
+	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -345,13 +346,13 @@ func TestReadArticleContentVersion(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user, article, lang := setupArticleHistory(t, -1)
 
-	result, _ := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 0, false, formatHTML)
+	result, _ := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 0, false, formatHTML, false)
 
 	if result.Content == nil || !strings.Contains(result.Content.Content, "content 4") {
 		t.Fatalf("Latest article content must be returned, but was: %v", result.Content)
 	}
 
-	result, _ = ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 2, false, formatHTML)
+	result, _ = ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 2, false, formatHTML, false)
 
 	if result.Content == nil || !strings.Contains(result.Content.Content, "content 2") {
 		t.Fatalf("Article content with version 2 must be returned, but was: %v", result.Content)
@@ -362,7 +363,7 @@ func TestReadArticleContentVersionWIP(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user, article, lang := setupArticleHistory(t, 2)
 
-	result, _ := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 99999999, false, formatHTML)
+	result, _ := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 99999999, false, formatHTML, false)
 
 	if result.Content == nil || !strings.Contains(result.Content.Content, "content 4") {
 		t.Fatalf("WIP article content must be returned, but was: %v", result.Content)
@@ -378,7 +379,7 @@ func TestReadArticleNonExpert(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 1, false, formatHTML)
+	_, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 1, false, formatHTML, false)
 
 	if err != errs.RequiresExpertVersion {
 		t.Fatalf("Article version must not be returned, but was: %v", err)
@@ -391,7 +392,7 @@ func TestReadArticleWithoutLanguage(t *testing.T) {
 	lang := testutil.CreateLang(t, orga, "ru", "Russian", true)
 	article := testutil.CreateArticleWithoutContent(t, orga, user, lang, true, true)
 	testutil.CreateArticleContent(t, user, article, lang, 0)
-	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML)
+	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML, false)
 
 	if err != nil || result.Content == nil {
 		t.Fatal("Content must be returned")
@@ -408,7 +409,7 @@ func TestReadArticleWriteAccessReadEveryone(t *testing.T) {
 	user2 := testutil.CreateUser(t, orga, 321, "test321@user.com")
 	lang := testutil.CreateLang(t, orga, "ru", "Russian", true)
 	article := testutil.CreateArticleWithoutContent(t, orga, user2, lang, true, false)
-	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML)
+	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), article.ID, 0, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatal(err)
@@ -419,6 +420,108 @@ func TestReadArticleWriteAccessReadEveryone(t *testing.T) {
 	}
 }
 
+func TestReadArticleChanges(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "test321@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	oldContent := model.GetArticleContentByArticleIdAndLanguageIdAndMaxVersion(article.ID, lang.ID, 1)
+	oldContent.Content = `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"unchanged"}]},{"type":"paragraph","content":[{"type":"text","text":"old"}]}]}`
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(article.ID, lang.ID, false)
+	content.Content = `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"unchanged"}]},{"type":"paragraph","content":[{"type":"text","text":"new"}]}]}`
+
+	if err := model.SaveArticleContent(nil, oldContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := model.SaveArticleContent(nil, content); err != nil {
+		t.Fatal(err)
+	}
+
+	view := &model.ArticleView{ArticleId: article.ID, UserId: user2.ID, LanguageId: lang.ID, Version: 1}
+
+	if err := model.SaveArticleView(nil, view); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.NewEmviUserContext(orga, user2.ID)
+	result, err := ReadArticle(ctx, article.ID, lang.ID, 0, true, formatHTML, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Changes == nil || result.Changes.LastVisitedVersion != 1 || result.Changes.Version != 2 {
+		t.Fatalf("Changes since last visit must be returned, but was: %v", result.Changes)
+	}
+
+	if len(result.Changes.Blocks) != 3 ||
+		result.Changes.Blocks[0].Op != prosemirror.DiffEqual ||
+		result.Changes.Blocks[1].Op != prosemirror.DiffDelete ||
+		result.Changes.Blocks[2].Op != prosemirror.DiffInsert ||
+		result.Changes.Blocks[2].Content != "<p>new</p>" {
+		t.Fatalf("Changed blocks not as expected: %v", result.Changes.Blocks)
+	}
+
+	result, err = ReadArticle(ctx, article.ID, lang.ID, 0, true, formatHTML, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Changes != nil {
+		t.Fatalf("No changes must be returned after reading the latest version, but was: %v", result.Changes)
+	}
+}
+
+func TestReadArticleChangesSignedURLs(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "test321@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	oldContent := model.GetArticleContentByArticleIdAndLanguageIdAndMaxVersion(article.ID, lang.ID, 1)
+	oldContent.Content = `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"old"}]}]}`
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(article.ID, lang.ID, false)
+	content.Content = `{"type":"doc","content":[{"type":"image","attrs":{"src":"http://localhost:4003/api/v1/content/KmCMFEvoFi.png"},"content":[{"type":"paragraph"}]}]}`
+
+	if err := model.SaveArticleContent(nil, oldContent); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := model.SaveArticleContent(nil, content); err != nil {
+		t.Fatal(err)
+	}
+
+	view := &model.ArticleView{ArticleId: article.ID, UserId: user2.ID, LanguageId: lang.ID, Version: 1}
+
+	if err := model.SaveArticleView(nil, view); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := ReadArticle(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0, true, formatHTML, true)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result.SignContentURLs(orga.ID, user2.ID)
+
+	if !strings.Contains(result.Content.Content, "/api/v1/content/KmCMFEvoFi.png?") {
+		t.Fatalf("Content URLs must have been signed, but was: %v", result.Content.Content)
+	}
+
+	if result.Changes == nil || len(result.Changes.Blocks) != 2 || result.Changes.Blocks[1].Op != prosemirror.DiffInsert {
+		t.Fatalf("Changes must have been returned, but was: %v", result.Changes)
+	}
+
+	if !strings.Contains(result.Changes.Blocks[1].Content, "/api/v1/content/KmCMFEvoFi.png?") ||
+		!strings.Contains(result.Changes.Blocks[1].Content, "signature=") {
+		t.Fatalf("Content URLs in changes must have been signed, but was: %v", result.Changes.Blocks[1].Content)
+	}
+}
+
 func TestRenderArticleContent(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
@@ -548,10 +651,21 @@ func TestUpdateArticleViews(t *testing.T) {
 	orga, user := testutil.CreateOrgaAndUser(t)
 	lang := testutil.CreateLang(t, orga, "en", "English", true)
 	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(article.ID, lang.ID, false)
 	views := article.Views
 
-	updateArticleViews(article, user.ID)
-	updateArticleViews(article, user.ID)
+	updateArticleViews(article, content, user.ID)
+	updateArticleViews(article, content, user.ID)
+	article = model.GetArticleByOrganizationIdAndId(orga.ID, article.ID)
+
+	if article.Views != views || model.CountArticleView() != 2 {
+		t.Fatalf("Article views must have been buffered, but was: %v -> %v", views, article.Views)
+	}
+
+	if err := model.MergeArticleViewTx(nil); err != nil {
+		t.Fatal(err)
+	}
+
 	article = model.GetArticleByOrganizationIdAndId(orga.ID, article.ID)
 
 	if article.Views != views+1 {
@@ -563,6 +677,10 @@ func TestUpdateArticleViews(t *testing.T) {
 	if visit == nil {
 		t.Fatal("Visit must exist")
 	}
+
+	if visit.Views != 2 || visit.LanguageId != lang.ID || visit.Version != 2 {
+		t.Fatalf("Visit not as expected: %v %v %v", visit.Views, visit.LanguageId, visit.Version)
+	}
 }
 
 func TestUpdateArticleViewsClient(t *testing.T) {
@@ -585,7 +703,7 @@ func TestUpdateArticleViewsClient(t *testing.T) {
 
 	views := article.Views
 	ctx := context.NewEmviContext(orga, 0, []string{client.Scopes["articles"].String()}, false)
-	_, err := ReadArticle(ctx, article.ID, 0, 0, false, formatHTML)
+	_, err := ReadArticle(ctx, article.ID, 0, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatalf("Article must be returned, but was: %v", err)
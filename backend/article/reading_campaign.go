@@ -0,0 +1,254 @@
+package article
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/feed"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"time"
+)
+
+const (
+	readingCampaignFeed          = "reading_campaign"
+	readingCampaignReconfirmFeed = "reading_campaign_reconfirm"
+	readingCampaignDueDateFormat = "2006-01-02"
+)
+
+type ReadingCampaignReport struct {
+	Campaign *model.ReadingCampaign        `json:"campaign"`
+	Members  []model.ReadingCampaignMember `json:"members"`
+}
+
+// CreateReadingCampaign assigns the article in given language to users and group members,
+// who must confirm they have read the latest version until the due date.
+// Only administrators and moderators can create reading campaigns.
+func CreateReadingCampaign(orga *model.Organization, userId, articleId, langId hide.ID, userIds, groupIds []hide.ID, dueDate time.Time) (hide.ID, error) {
+	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
+		return 0, err
+	}
+
+	if dueDate.Before(time.Now()) {
+		return 0, errs.DueDateInvalid
+	}
+
+	article := model.GetArticleByOrganizationIdAndId(orga.ID, articleId)
+
+	if article == nil {
+		return 0, errs.ArticleNotFound
+	}
+
+	if model.GetLanguageByOrganizationIdAndId(orga.ID, langId) == nil {
+		return 0, errs.LanguageNotFound
+	}
+
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, langId, false)
+
+	if lastContent == nil {
+		return 0, errs.ArticleContentVersionNotFound
+	}
+
+	members, err := checkAllUserExist(orga.ID, userIds)
+
+	if err != nil {
+		return 0, err
+	}
+
+	members = appendGroupMembers(orga.ID, members, groupIds)
+
+	if len(members) == 0 {
+		return 0, errs.ReadingCampaignNoMembers
+	}
+
+	for _, member := range members {
+		if !article.ReadEveryone && !article.WriteEveryone && !perm.CheckUserReadOrWriteAccess(articleId, member.ID) {
+			return 0, errs.ArticlePermissionDenied
+		}
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to create reading campaign", logbuch.Fields{"err": err, "orga_id": orga.ID, "article_id": articleId})
+		return 0, errs.TxBegin
+	}
+
+	campaign := &model.ReadingCampaign{OrganizationId: orga.ID,
+		ArticleId:  articleId,
+		LanguageId: langId,
+		UserId:     userId,
+		DueDate:    dueDate,
+		Version:    lastContent.Version}
+
+	if err := model.SaveReadingCampaign(tx, campaign); err != nil {
+		return 0, errs.Saving
+	}
+
+	notify := make([]hide.ID, 0, len(members))
+
+	for _, member := range members {
+		campaignMember := &model.ReadingCampaignMember{ReadingCampaignId: campaign.ID, UserId: member.ID}
+
+		if err := model.SaveReadingCampaignMember(tx, campaignMember); err != nil {
+			return 0, errs.Saving
+		}
+
+		notify = append(notify, member.ID)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction to create reading campaign", logbuch.Fields{"err": err, "orga_id": orga.ID, "article_id": articleId})
+		return 0, errs.TxCommit
+	}
+
+	createReadingCampaignFeed(orga, userId, campaign, notify, readingCampaignFeed)
+	return campaign.ID, nil
+}
+
+// ConfirmReadingCampaign confirms the user has read the last published version of the article assigned by the campaign.
+func ConfirmReadingCampaign(orga *model.Organization, userId, campaignId hide.ID) error {
+	campaign := model.GetReadingCampaignByOrganizationIdAndId(orga.ID, campaignId)
+
+	if campaign == nil {
+		return errs.ReadingCampaignNotFound
+	}
+
+	member := model.GetReadingCampaignMemberByReadingCampaignIdAndUserId(campaign.ID, userId)
+
+	if member == nil {
+		return errs.ReadingCampaignMemberNotFound
+	}
+
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(campaign.ArticleId, campaign.LanguageId, false)
+
+	if lastContent == nil {
+		return errs.ArticleContentVersionNotFound
+	}
+
+	member.ConfirmedVersion = lastContent.Version
+	member.Confirmed = null.NewTime(time.Now(), true)
+
+	if err := model.SaveReadingCampaignMember(nil, member); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// ReadReadingCampaigns returns all reading campaigns for given article.
+// Only administrators and moderators can read reading campaigns.
+func ReadReadingCampaigns(orga *model.Organization, userId, articleId hide.ID) ([]model.ReadingCampaign, error) {
+	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
+		return nil, err
+	}
+
+	return model.FindReadingCampaignByOrganizationIdAndArticleId(orga.ID, articleId), nil
+}
+
+// ReadUnconfirmedReadingCampaigns returns all reading campaigns the user still needs to confirm.
+func ReadUnconfirmedReadingCampaigns(orga *model.Organization, userId hide.ID) []model.ReadingCampaign {
+	return model.FindReadingCampaignByOrganizationIdAndUserIdAndUnconfirmed(orga.ID, userId)
+}
+
+// ReadReadingCampaignReport returns the campaign and which version each member has confirmed.
+// Only administrators and moderators can read the report.
+func ReadReadingCampaignReport(orga *model.Organization, userId, campaignId hide.ID) (*ReadingCampaignReport, error) {
+	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
+		return nil, err
+	}
+
+	campaign := model.GetReadingCampaignByOrganizationIdAndId(orga.ID, campaignId)
+
+	if campaign == nil {
+		return nil, errs.ReadingCampaignNotFound
+	}
+
+	members := model.FindReadingCampaignMemberByReadingCampaignIdWithUser(campaign.ID)
+	campaign.Members = len(members)
+
+	for _, member := range members {
+		if member.ConfirmedVersion >= campaign.Version {
+			campaign.ConfirmedMembers++
+		}
+	}
+
+	return &ReadingCampaignReport{campaign, members}, nil
+}
+
+// DeleteReadingCampaign deletes a reading campaign and all of its members.
+// Only administrators and moderators can delete reading campaigns.
+func DeleteReadingCampaign(orga *model.Organization, userId, campaignId hide.ID) error {
+	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
+		return err
+	}
+
+	campaign := model.GetReadingCampaignByOrganizationIdAndId(orga.ID, campaignId)
+
+	if campaign == nil {
+		return errs.ReadingCampaignNotFound
+	}
+
+	if err := model.DeleteReadingCampaignById(nil, campaign.ID); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// Requires all members of reading campaigns for the article and language to confirm the new version again.
+// This is called when an article was changed significantly.
+func requireReadingCampaignReconfirmation(orga *model.Organization, userId hide.ID, content *model.ArticleContent) {
+	if err := model.UpdateReadingCampaignVersionByArticleIdAndLanguageId(nil, content.ArticleId, content.LanguageId, content.Version); err != nil {
+		logbuch.Error("Error updating reading campaign version", logbuch.Fields{"err": err, "article_id": content.ArticleId, "lang_id": content.LanguageId, "version": content.Version})
+		return
+	}
+
+	for _, campaign := range model.FindReadingCampaignByOrganizationIdAndArticleId(orga.ID, content.ArticleId) {
+		if campaign.LanguageId != content.LanguageId {
+			continue
+		}
+
+		members := model.FindReadingCampaignMemberByReadingCampaignIdWithUser(campaign.ID)
+		notify := make([]hide.ID, 0, len(members))
+
+		for _, member := range members {
+			if member.ConfirmedVersion < campaign.Version && member.UserId != userId {
+				notify = append(notify, member.UserId)
+			}
+		}
+
+		createReadingCampaignFeed(orga, userId, &campaign, notify, readingCampaignReconfirmFeed)
+	}
+}
+
+func createReadingCampaignFeed(orga *model.Organization, userId hide.ID, campaign *model.ReadingCampaign, notify []hide.ID, reason string) {
+	if len(notify) == 0 {
+		return
+	}
+
+	article := model.GetArticleByOrganizationIdAndId(orga.ID, campaign.ArticleId)
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(campaign.ArticleId, campaign.LanguageId, false)
+
+	if article == nil || content == nil {
+		logbuch.Error("Article or content for reading campaign not found", logbuch.Fields{"campaign_id": campaign.ID, "article_id": campaign.ArticleId, "lang_id": campaign.LanguageId})
+		return
+	}
+
+	refs := make([]interface{}, 3)
+	refs[0] = article
+	refs[1] = content
+	refs[2] = feed.KeyValue{"due_date", campaign.DueDate.Format(readingCampaignDueDateFormat)}
+	feedData := &feed.CreateFeedData{Organization: orga,
+		UserId: userId,
+		Reason: reason,
+		Public: false,
+		Access: []hide.ID{},
+		Notify: notify,
+		Refs:   refs}
+
+	if err := feed.CreateFeed(feedData); err != nil {
+		logbuch.Error("Error creating feed for reading campaign", logbuch.Fields{"err": err, "campaign_id": campaign.ID})
+	}
+}
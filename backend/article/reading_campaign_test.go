@@ -0,0 +1,111 @@
+package article
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/hide"
+	"testing"
+	"time"
+)
+
+func TestCreateReadingCampaign(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	user3 := testutil.CreateUser(t, orga, 322, "user3@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	group := testutil.CreateUserGroup(t, orga, "employees")
+	testutil.CreateUserGroupMember(t, group, user2, false)
+	testutil.CreateUserGroupMember(t, group, user3, false)
+	dueDate := time.Now().Add(time.Hour * 24 * 7)
+
+	if _, err := CreateReadingCampaign(orga, user2.ID, article.ID, lang.ID, []hide.ID{user3.ID}, nil, dueDate); err != errs.PermissionDenied {
+		t.Fatalf("Only administrators and moderators must be allowed to create campaigns, but was: %v", err)
+	}
+
+	if _, err := CreateReadingCampaign(orga, user.ID, article.ID, lang.ID, []hide.ID{user3.ID}, nil, time.Now().Add(-time.Hour)); err != errs.DueDateInvalid {
+		t.Fatalf("Due date must be invalid, but was: %v", err)
+	}
+
+	if _, err := CreateReadingCampaign(orga, user.ID, article.ID, lang.ID, nil, nil, dueDate); err != errs.ReadingCampaignNoMembers {
+		t.Fatalf("Campaign must have members, but was: %v", err)
+	}
+
+	id, err := CreateReadingCampaign(orga, user.ID, article.ID, lang.ID, []hide.ID{user2.ID}, []hide.ID{group.ID}, dueDate)
+
+	if err != nil {
+		t.Fatalf("Campaign must have been created, but was: %v", err)
+	}
+
+	campaign := model.GetReadingCampaignByOrganizationIdAndId(orga.ID, id)
+
+	if campaign == nil || campaign.Version != 2 || campaign.LanguageId != lang.ID {
+		t.Fatalf("Campaign not as expected: %v", campaign)
+	}
+
+	if members := model.FindReadingCampaignMemberByReadingCampaignIdWithUser(id); len(members) != 2 {
+		t.Fatalf("Campaign must have two members, but was: %v", len(members))
+	}
+
+	if campaigns := ReadUnconfirmedReadingCampaigns(orga, user2.ID); len(campaigns) != 1 || campaigns[0].Title != "title 2" {
+		t.Fatalf("Unconfirmed campaign must be returned, but was: %v", campaigns)
+	}
+}
+
+func TestConfirmReadingCampaign(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	id, err := CreateReadingCampaign(orga, user.ID, article.ID, lang.ID, []hide.ID{user2.ID}, nil, time.Now().Add(time.Hour*24))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ConfirmReadingCampaign(orga, user.ID, id); err != errs.ReadingCampaignMemberNotFound {
+		t.Fatalf("User who is not a member must not be able to confirm, but was: %v", err)
+	}
+
+	if err := ConfirmReadingCampaign(orga, user2.ID, id); err != nil {
+		t.Fatalf("Campaign must have been confirmed, but was: %v", err)
+	}
+
+	report, err := ReadReadingCampaignReport(orga, user.ID, id)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if report.Campaign.Members != 1 || report.Campaign.ConfirmedMembers != 1 ||
+		report.Members[0].ConfirmedVersion != 2 || !report.Members[0].Confirmed.Valid {
+		t.Fatalf("Report not as expected: %v %v", report.Campaign, report.Members)
+	}
+
+	if _, err := ReadReadingCampaignReport(orga, user2.ID, id); err != errs.PermissionDenied {
+		t.Fatalf("Only administrators and moderators must be allowed to read the report, but was: %v", err)
+	}
+
+	if campaigns := ReadUnconfirmedReadingCampaigns(orga, user2.ID); len(campaigns) != 0 {
+		t.Fatalf("Confirmed campaign must not be returned, but was: %v", campaigns)
+	}
+
+	// significant change requires reconfirmation
+	content := testutil.CreateArticleContent(t, user, article, lang, 3)
+	requireReadingCampaignReconfirmation(orga, user.ID, content)
+
+	if campaigns := ReadUnconfirmedReadingCampaigns(orga, user2.ID); len(campaigns) != 1 || campaigns[0].Version != 3 {
+		t.Fatalf("Campaign must require reconfirmation, but was: %v", campaigns)
+	}
+
+	if err := DeleteReadingCampaign(orga, user.ID, id); err != nil {
+		t.Fatal(err)
+	}
+
+	if model.GetReadingCampaignByOrganizationIdAndId(orga.ID, id) != nil {
+		t.Fatal("Campaign must have been deleted")
+	}
+}
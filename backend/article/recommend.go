@@ -6,6 +6,7 @@ import (
 	"emviwiki/backend/feed"
 	"emviwiki/backend/mailtpl"
 	"emviwiki/backend/perm"
+	rendering "emviwiki/shared/feed"
 	"emviwiki/shared/i18n"
 	"emviwiki/shared/mail"
 	"emviwiki/shared/model"
@@ -225,7 +226,13 @@ func sendRecommendInviteArticleMail(orga *model.Organization, article *model.Art
 	e := make([]error, 0)
 
 	for _, notifyUser := range notifyUsers {
-		if notifyUser.OrganizationMember.RecommendationMail {
+		if notifyUser.Bot {
+			continue
+		}
+
+		prefs := model.FindNotificationPreferenceByOrganizationMemberId(notifyUser.OrganizationMember.ID)
+
+		if rendering.NotificationDelivery(notifyUser.OrganizationMember, prefs, rendering.CategoryRecommendations) == model.NotificationDeliveryInstant {
 			userLang := util.DetermineSystemSupportedLangCode(orga.ID, notifyUser.ID)
 			tpl := mailtpl.Cache.Get()
 			var buffer bytes.Buffer
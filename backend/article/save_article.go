@@ -2,8 +2,11 @@ package article
 
 import (
 	articleutil "emviwiki/backend/article/util"
+	filecontent "emviwiki/backend/content"
+	"emviwiki/backend/customfield"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/feed"
+	"emviwiki/backend/live"
 	"emviwiki/backend/observe"
 	"emviwiki/backend/perm"
 	"emviwiki/backend/pinned"
@@ -45,6 +48,17 @@ type SaveArticleData struct {
 	Content       string                   `json:"content"`
 	RTL           bool                     `json:"rtl"`
 	Tags          []string                 `json:"tags"`
+
+	// CustomFields replaces the custom field values of the article. The values are kept if nil.
+	CustomFields []customfield.SaveValueData `json:"custom_fields"`
+
+	// SourceSynced marks a translation as up to date with the last version in the default language.
+	// The version the translation is based on is kept otherwise.
+	SourceSynced bool `json:"source_synced"`
+
+	// RequireConfirmation marks the change as significant,
+	// so that members of reading campaigns for the article must confirm the new version again.
+	RequireConfirmation bool `json:"require_confirmation"`
 }
 
 func (data *SaveArticleData) validate() []error {
@@ -64,6 +78,13 @@ func (data *SaveArticleData) validate() []error {
 		err = append(err, e)
 	}
 
+	if content, e := validateContent(data.Content); len(e) != 0 {
+		err = append(err, e...)
+	} else {
+		// the editor inserts signed URLs for attachments, which must be signed again when read
+		data.Content = filecontent.UnsignContentURLs(content)
+	}
+
 	if len(err) != 0 {
 		return err
 	}
@@ -104,6 +125,12 @@ func SaveArticle(data SaveArticleData) (hide.ID, []error) {
 		return 0, err
 	}
 
+	customFields, err := getCustomFields(&data, article)
+
+	if err != nil {
+		return 0, []error{err}
+	}
+
 	tx, err := model.GetConnection().Beginx()
 
 	if err != nil {
@@ -134,6 +161,12 @@ func SaveArticle(data SaveArticleData) (hide.ID, []error) {
 		return 0, []error{err}
 	}
 
+	if data.CustomFields != nil {
+		if err := customfield.SaveValues(tx, article.ID, customFields); err != nil {
+			return 0, []error{err}
+		}
+	}
+
 	if err := updateAttachments(tx, data.Organization.ID, article.ID, content.LanguageId, data.RoomId); err != nil {
 		return 0, []error{err}
 	}
@@ -158,6 +191,18 @@ func subsequentActions(data *SaveArticleData, article *model.Article, content, l
 
 	if !data.Wip {
 		createSaveArticleFeed(data, article, content)
+
+		if lastCommit != nil {
+			notifyTranslators(data.Organization, data.UserId, article, content, lastCommit.Version)
+		}
+
+		if data.Id != 0 {
+			publishSaveArticle(data, content)
+		}
+
+		if data.RequireConfirmation && data.Id != 0 {
+			requireReadingCampaignReconfirmation(data.Organization, data.UserId, content)
+		}
 	}
 
 	// observe if this is a new article
@@ -172,15 +217,20 @@ func subsequentActions(data *SaveArticleData, article *model.Article, content, l
 		cleanupDefTime = lastCommit.DefTime
 	}
 
+	cleanupAttachmentsAndNotifyMentions(data.Organization, data.UserId, article, content, cleanupDefTime)
+}
+
+// Removes attachments no longer used in the new content and notifies mentioned users in background.
+// The definition time of the last commit is used to find attachments and mentions added since then.
+func cleanupAttachmentsAndNotifyMentions(orga *model.Organization, userId hide.ID, article *model.Article, content *model.ArticleContent, lastCommitDefTime time.Time) {
 	go func() {
-		if err := cleanupAttachments(data.Organization.ID, data.UserId, article.ID, cleanupDefTime, content); err != nil {
+		if err := cleanupAttachments(orga.ID, userId, article.ID, lastCommitDefTime, content); err != nil {
 			logbuch.Error("Error cleaning up attachments when saving article", logbuch.Fields{"err": err, "article_id": article.ID, "content_id": content.ID})
 		}
 	}()
 
-	// notify mentioned users
 	go func() {
-		if err := notifyMentionedUsers(data.Organization, data.UserId, cleanupDefTime, article, content); err != nil {
+		if err := notifyMentionedUsers(orga, userId, lastCommitDefTime, article, content); err != nil {
 			logbuch.Error("Error notifying mentioned users when saving article", logbuch.Fields{"err": err, "article_id": article.ID, "content_id": content.ID})
 		}
 	}()
@@ -229,6 +279,40 @@ func validateAuthors(data *SaveArticleData) {
 	data.Authors = authors
 }
 
+// Returns the custom field values the article will have after saving.
+// Fields required by the tags of the article must be set when it is published and the values are passed.
+// Saves without values are not blocked, so that articles tagged before a field became required can still be edited.
+func getCustomFields(data *SaveArticleData, article *model.Article) ([]model.ArticleCustomField, error) {
+	var values []model.ArticleCustomField
+
+	if data.CustomFields != nil {
+		var err error
+		values, err = customfield.ParseValues(data.Organization.ID, data.CustomFields)
+
+		if err != nil {
+			return nil, err
+		}
+	} else if article.ID != 0 {
+		values = model.FindArticleCustomFieldByArticleId(article.ID)
+	}
+
+	if !data.Wip && data.CustomFields != nil {
+		tags := append([]string{}, data.Tags...)
+
+		if article.ID != 0 {
+			for _, t := range model.FindTagByOrganizationIdAndUserIdAndArticleId(data.Organization.ID, data.UserId, article.ID) {
+				tags = append(tags, t.Name)
+			}
+		}
+
+		if err := customfield.CheckRequired(data.Organization.ID, tags, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
 func saveArticle(tx *sqlx.Tx, orga *model.Organization, article *model.Article, data *SaveArticleData, lastCommit *model.ArticleContent) error {
 	wipVersion := article.WIP
 
@@ -280,6 +364,7 @@ func saveContent(tx *sqlx.Tx, articleId hide.ID, data *SaveArticleData, lastComm
 	}
 
 	textContent := extractTextFromContent(doc)
+	sourceVersion := getSourceVersion(tx, data.Organization.ID, articleId, data.LanguageId, data.SourceSynced, lastCommit)
 	newContent := &model.ArticleContent{ArticleId: articleId,
 		LanguageId:      data.LanguageId,
 		UserId:          data.UserId,
@@ -292,7 +377,8 @@ func saveContent(tx *sqlx.Tx, articleId hide.ID, data *SaveArticleData, lastComm
 		ContentTsvector: textContent,
 		ReadingTime:     calculateReadingTimeSeconds(textContent),
 		SchemaVersion:   constants.LatestSchemaVersion,
-		RTL:             data.RTL}
+		RTL:             data.RTL,
+		SourceVersion:   sourceVersion}
 
 	if err := model.SaveArticleContent(tx, newContent); err != nil {
 		logbuch.Error("Error saving new article content when saving article", logbuch.Fields{"err": err, "article_id": articleId})
@@ -328,6 +414,7 @@ func saveContent(tx *sqlx.Tx, articleId hide.ID, data *SaveArticleData, lastComm
 		latestContent.ContentTsvector = textContent
 		latestContent.ReadingTime = calculateReadingTimeSeconds(textContent)
 		latestContent.RTL = data.RTL
+		latestContent.SourceVersion = sourceVersion
 	} else {
 		latestContent = &model.ArticleContent{ArticleId: articleId,
 			LanguageId:      data.LanguageId,
@@ -340,7 +427,8 @@ func saveContent(tx *sqlx.Tx, articleId hide.ID, data *SaveArticleData, lastComm
 			ContentTsvector: textContent,
 			ReadingTime:     calculateReadingTimeSeconds(textContent),
 			SchemaVersion:   constants.LatestSchemaVersion,
-			RTL:             data.RTL}
+			RTL:             data.RTL,
+			SourceVersion:   sourceVersion}
 	}
 
 	if err := model.SaveArticleContent(tx, latestContent); err != nil {
@@ -456,6 +544,12 @@ func createSaveArticleFeed(data *SaveArticleData, article *model.Article, conten
 	}
 }
 
+func publishSaveArticle(data *SaveArticleData, content *model.ArticleContent) {
+	if err := live.PublishArticleUpdate(nil, data.Organization.ID, data.UserId, content); err != nil {
+		logbuch.Error("Error publishing article update when updating article", logbuch.Fields{"err": err, "article_id": content.ArticleId})
+	}
+}
+
 func observeArticle(articleId hide.ID, authors []hide.ID) {
 	for _, author := range authors {
 		if !observe.IsObserved(author, articleId, 0, 0) {
@@ -2,10 +2,12 @@ package article
 
 import (
 	"emviwiki/backend/context"
+	"emviwiki/backend/customfield"
 	"emviwiki/backend/errs"
 	"emviwiki/backend/perm"
 	"emviwiki/shared/constants"
 	"emviwiki/shared/model"
+	"emviwiki/shared/rest"
 	"emviwiki/shared/testutil"
 	"github.com/emvi/hide"
 	"testing"
@@ -58,6 +60,36 @@ func TestSaveArticleInvalidInput(t *testing.T) {
 	}
 }
 
+func TestSaveArticleInvalidContent(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "de", "Deutsch", true)
+	data := SaveArticleData{Organization: orga,
+		UserId:     user.ID,
+		LanguageId: lang.ID,
+		Title:      "title",
+		Content:    "invalid"}
+
+	if _, err := SaveArticle(data); len(err) != 1 || err[0] != errs.ContentInvalid {
+		t.Fatalf("Content must be invalid, but was: %v", err)
+	}
+
+	data.Content = `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"paragraph"}]},{"type":"image","content":[{"type":"paragraph"}]}]}`
+	_, err := SaveArticle(data)
+
+	if len(err) != 2 {
+		t.Fatalf("Content must not match schema, but was: %v", err)
+	}
+
+	if e, ok := err[0].(*rest.ApiError); !ok || e.Field != "content/content/0/content/0" {
+		t.Fatalf("Path of invalid node must be returned, but was: %v", err[0])
+	}
+
+	if e, ok := err[1].(*rest.ApiError); !ok || e.Field != "content/content/1/attrs/src" {
+		t.Fatalf("Path of missing attribute must be returned, but was: %v", err[1])
+	}
+}
+
 func TestSaveArticleNewSuccess(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
@@ -361,7 +393,7 @@ func TestSaveArticleWIPFirstCommit(t *testing.T) {
 		t.Fatalf("Article must have been saved, but was: %v", errors)
 	}
 
-	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), id, lang.ID, 0, false, formatHTML)
+	result, err := ReadArticle(context.NewEmviUserContext(orga, user.ID), id, lang.ID, 0, false, formatHTML, false)
 
 	if err != nil {
 		t.Fatalf("Article must have been found after creation, but was: %v", err)
@@ -870,3 +902,67 @@ func TestSaveArticleDeleteWIPFeedRefs(t *testing.T) {
 		t.Fatalf("Article must have been saved, but was: %v", errors)
 	}
 }
+
+func TestSaveArticleCustomFields(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	tag := testutil.CreateTag(t, orga, "contract")
+	fieldId, err := customfield.SaveCustomField(orga, user.ID, &customfield.SaveCustomFieldData{Name: "Amount",
+		Type:           model.CustomFieldNumber,
+		RequiredTagIds: []hide.ID{tag.ID}})
+
+	if len(err) != 0 {
+		t.Fatal(err)
+	}
+
+	data := SaveArticleData{Organization: orga,
+		UserId:       user.ID,
+		LanguageId:   lang.ID,
+		Title:        "Title",
+		Content:      simpleSampleDoc,
+		Tags:         []string{"contract"},
+		CustomFields: []customfield.SaveValueData{}}
+
+	if _, err := SaveArticle(data); len(err) != 1 || err[0] != errs.CustomFieldRequired {
+		t.Fatalf("Expected custom field to be required, but was: %v", err)
+	}
+
+	data.CustomFields = []customfield.SaveValueData{{fieldId, "abc"}}
+
+	if _, err := SaveArticle(data); len(err) != 1 || err[0] != errs.CustomFieldValueInvalid {
+		t.Fatalf("Expected custom field value to be invalid, but was: %v", err)
+	}
+
+	data.CustomFields = []customfield.SaveValueData{{fieldId, "12.5"}}
+	id, err := SaveArticle(data)
+
+	if len(err) != 0 {
+		t.Fatalf("Article must have been saved, but was: %v", err)
+	}
+
+	values := model.FindArticleCustomFieldByArticleId(id)
+
+	if len(values) != 1 || values[0].Number.Float64 != 12.5 {
+		t.Fatalf("Custom field value not as expected: %v", values)
+	}
+
+	// values are kept if not passed
+	data.Id = id
+	data.CustomFields = nil
+
+	if _, err := SaveArticle(data); len(err) != 0 {
+		t.Fatalf("Article must have been saved, but was: %v", err)
+	}
+
+	if len(model.FindArticleCustomFieldByArticleId(id)) != 1 {
+		t.Fatal("Custom field value must have been kept")
+	}
+
+	// required values are not enforced if not passed
+	data.Id = 0
+
+	if _, err := SaveArticle(data); len(err) != 0 {
+		t.Fatalf("Article without custom field values must have been saved, but was: %v", err)
+	}
+}
@@ -20,15 +20,10 @@ type migration func(*model.ArticleContent) error
 // Migrate migrates the given article content to newest version if required.
 func Migrate(content *model.ArticleContent) error {
 	if content != nil && content.Content != "" && content.SchemaVersion < constants.LatestSchemaVersion {
-		for i := content.SchemaVersion; i < len(migrationSteps); i++ {
-			if err := migrationSteps[i](content); err != nil {
-				logbuch.Error("Error migrating article content to new schema version", logbuch.Fields{"err": err, "schema_version": content.SchemaVersion, "latest_schema_version": constants.LatestSchemaVersion, "migration_step": i})
-				return err
-			}
+		if err := migrateContent(content); err != nil {
+			return err
 		}
 
-		content.SchemaVersion = constants.LatestSchemaVersion
-
 		if err := model.SaveArticleContent(nil, content); err != nil {
 			logbuch.Error("Error saving article content after migrating to newest schema version", logbuch.Fields{"err": err, "schema_version": content.SchemaVersion, "latest_schema_version": constants.LatestSchemaVersion})
 			return err
@@ -38,6 +33,19 @@ func Migrate(content *model.ArticleContent) error {
 	return nil
 }
 
+// migrateContent migrates the given article content to newest version without saving it.
+func migrateContent(content *model.ArticleContent) error {
+	for i := content.SchemaVersion; i < len(migrationSteps); i++ {
+		if err := migrationSteps[i](content); err != nil {
+			logbuch.Error("Error migrating article content to new schema version", logbuch.Fields{"err": err, "schema_version": content.SchemaVersion, "latest_schema_version": constants.LatestSchemaVersion, "migration_step": i})
+			return err
+		}
+	}
+
+	content.SchemaVersion = constants.LatestSchemaVersion
+	return nil
+}
+
 /* Add caption paragraph to images.
  * Before:
  *
@@ -0,0 +1,82 @@
+package schema
+
+import (
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"io"
+)
+
+const (
+	reportPageSize = 100
+)
+
+// InvalidContent is a stored article content version which does not validate against the schema.
+type InvalidContent struct {
+	ArticleContentId hide.ID                       `json:"article_content_id"`
+	ArticleId        hide.ID                       `json:"article_id"`
+	LanguageId       hide.ID                       `json:"language_id"`
+	Version          int                           `json:"version"`
+	Errors           []prosemirror.ValidationError `json:"errors"`
+}
+
+// WriteValidationReport validates all stored article content versions against the schema
+// and writes the invalid ones to given writer, one JSON object per line.
+// Content of older schema versions is migrated before, but not saved.
+func WriteValidationReport(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	var lastId hide.ID
+
+	for {
+		contents := model.FindArticleContentByIdGreaterThanLimit(lastId, reportPageSize)
+
+		for i := range contents {
+			if report := validateStoredContent(&contents[i]); report != nil {
+				if err := enc.Encode(report); err != nil {
+					return err
+				}
+			}
+		}
+
+		if len(contents) < reportPageSize {
+			break
+		}
+
+		lastId = contents[len(contents)-1].ID
+	}
+
+	return nil
+}
+
+func validateStoredContent(content *model.ArticleContent) *InvalidContent {
+	// WIP content of new articles is stored empty
+	if content.Content == "" {
+		return nil
+	}
+
+	report := &InvalidContent{ArticleContentId: content.ID,
+		ArticleId:  content.ArticleId,
+		LanguageId: content.LanguageId,
+		Version:    content.Version}
+
+	if err := migrateContent(content); err != nil {
+		report.Errors = []prosemirror.ValidationError{{Path: "", Message: "migration failed: " + err.Error()}}
+		return report
+	}
+
+	doc, err := prosemirror.ParseDoc(content.Content)
+
+	if err != nil {
+		report.Errors = []prosemirror.ValidationError{{Path: "", Message: "invalid JSON: " + err.Error()}}
+		return report
+	}
+
+	report.Errors = prosemirror.ValidateDoc(HTMLSchema, doc)
+
+	if len(report.Errors) == 0 {
+		return nil
+	}
+
+	return report
+}
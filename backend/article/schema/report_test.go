@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"bytes"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteValidationReport(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	contents := model.FindArticleContentByArticleId(article.ID)
+
+	if len(contents) != 3 {
+		t.Fatalf("Three article contents expected, but was: %v", len(contents))
+	}
+
+	// old schema version, valid after migration
+	contents[0].Content = testContentVersion1
+	contents[0].SchemaVersion = 1
+	contents[1].Content = `{"type":"doc","content":[{"type":"paragraph"},{"type":"unknown"}]}`
+	contents[2].Content = testContentVersion2
+
+	for i := range contents {
+		if err := model.SaveArticleContent(nil, &contents[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+
+	if err := WriteValidationReport(&out); err != nil {
+		t.Fatalf("Report must have been written, but was: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+
+	if len(lines) != 1 {
+		t.Fatalf("One invalid version expected, but was: %v", lines)
+	}
+
+	var report InvalidContent
+
+	if err := json.Unmarshal([]byte(lines[0]), &report); err != nil {
+		t.Fatal(err)
+	}
+
+	if report.ArticleContentId != contents[1].ID ||
+		report.ArticleId != article.ID ||
+		len(report.Errors) != 1 ||
+		report.Errors[0].Path != "/content/1" {
+		t.Fatalf("Unexpected report: %v", report)
+	}
+
+	if content := model.GetArticleContentById(contents[0].ID); content.SchemaVersion != 1 {
+		t.Fatal("Migrated content must not have been saved")
+	}
+}
@@ -278,6 +278,7 @@ func buildHTMLSchema() {
 			},
 		},
 	}
+	addSpecs(nodes, marks)
 	s, err := prosemirror.NewSchema(nodes, marks)
 
 	if err != nil {
@@ -325,6 +325,7 @@ func GetMarkdownSchema(orga *model.Organization) *prosemirror.Schema {
 			},
 		},
 	}
+	addSpecs(nodes, marks)
 	s, err := prosemirror.NewSchema(nodes, marks)
 
 	if err != nil {
@@ -0,0 +1,80 @@
+package schema
+
+import (
+	"emviwiki/backend/prosemirror"
+)
+
+// The node and mark specifications must match the editor schema (frontend/public/src/editor/schema.js),
+// including the nodes added by prosemirror-schema-list and prosemirror-tables.
+// Numbers are float64, as they are decoded from JSON.
+var (
+	nodeSpecs = map[string]prosemirror.NodeType{
+		"doc":             {Content: "(block|table)+"},
+		"text":            {Group: "inline", Inline: true},
+		"hard_break":      {Group: "inline", Inline: true},
+		"paragraph":       {Content: "inline*", Group: "block", Marks: "_"},
+		"blockquote":      {Content: "(block|table)+", Group: "block"},
+		"code_block":      {Content: "text*", Group: "block", Attrs: map[string]prosemirror.AttrSpec{"language": {Default: "text/plain", Type: prosemirror.AttrString}}},
+		"infobox":         {Content: "(block|table)+", Group: "block", Attrs: map[string]prosemirror.AttrSpec{"color": {Default: "blue", Type: prosemirror.AttrString, Values: infoboxColors}}},
+		"headline":        {Content: "inline*", Group: "block", Marks: "_", Attrs: map[string]prosemirror.AttrSpec{"level": {Default: float64(2), Type: prosemirror.AttrNumber, Values: headlineLevels}}},
+		"horizontal_rule": {Group: "block"},
+		"image":           {Content: "paragraph", Group: "block", Attrs: map[string]prosemirror.AttrSpec{"src": {Required: true, Type: prosemirror.AttrString}}},
+		"file": {Group: "inline", Inline: true, Attrs: map[string]prosemirror.AttrSpec{
+			"file": {Required: true, Type: prosemirror.AttrString},
+			"name": {Required: true, Type: prosemirror.AttrString},
+			"size": {Required: true, Type: prosemirror.AttrString},
+		}},
+		"mention": {Group: "inline", Inline: true, Attrs: map[string]prosemirror.AttrSpec{
+			"id":    {Required: true, Type: prosemirror.AttrString},
+			"type":  {Required: true, Type: prosemirror.AttrString, Values: mentionTypes},
+			"title": {Required: true, Type: prosemirror.AttrString},
+			"time":  {Required: true, Type: prosemirror.AttrString},
+		}},
+		"ordered_list":    {Content: "list_item+", Group: "block", Attrs: map[string]prosemirror.AttrSpec{"order": {Default: float64(1), Type: prosemirror.AttrNumber}}},
+		"bullet_list":     {Content: "list_item+", Group: "block"},
+		"list_item":       {Content: "paragraph block*"},
+		"check_list":      {Content: "check_list_item+", Group: "block"},
+		"check_list_item": {Content: "paragraph block*", Attrs: map[string]prosemirror.AttrSpec{"checked": {Default: false, Type: prosemirror.AttrBool}}},
+		"table":           {Content: "table_row+", Group: "table"},
+		"table_row":       {Content: "(table_cell | table_header)*"},
+		"table_cell":      {Content: "block+", Attrs: tableCellAttrs},
+		"table_header":    {Content: "block+", Attrs: tableCellAttrs},
+		"youtube":         {Group: "block", Attrs: map[string]prosemirror.AttrSpec{"src": {Required: true, Type: prosemirror.AttrString}}},
+		"vimeo":           {Group: "block", Attrs: map[string]prosemirror.AttrSpec{"src": {Required: true, Type: prosemirror.AttrString}}},
+		"spotify":         {Group: "block", Attrs: map[string]prosemirror.AttrSpec{"src": {Required: true, Type: prosemirror.AttrString}}},
+		"pdf":             {Group: "block", Attrs: map[string]prosemirror.AttrSpec{"src": {Required: true, Type: prosemirror.AttrString}}},
+		"link_preview": {Group: "block", Attrs: map[string]prosemirror.AttrSpec{
+			"href":        {Required: true, Type: prosemirror.AttrString},
+			"title":       {Default: ""},
+			"description": {Default: ""},
+			"image":       {Default: ""},
+		}},
+	}
+	markSpecs = map[string]prosemirror.MarkType{
+		"link": {Attrs: map[string]prosemirror.AttrSpec{"href": {Required: true, Type: prosemirror.AttrString}}},
+	}
+	tableCellAttrs = map[string]prosemirror.AttrSpec{
+		"colspan":    {Default: float64(1), Type: prosemirror.AttrNumber},
+		"rowspan":    {Default: float64(1), Type: prosemirror.AttrNumber},
+		"colwidth":   {Default: nil},
+		"background": {Default: "none"},
+	}
+	headlineLevels = []interface{}{float64(2), float64(3), float64(4)}
+	infoboxColors  = []interface{}{"blue", "green", "orange", "red"}
+	mentionTypes   = []interface{}{"article", "list", "user", "tag", "group"}
+)
+
+// addSpecs sets the specification of each node and mark type, so that only the ToDOM functions must be defined per schema.
+func addSpecs(nodes map[string]prosemirror.NodeType, marks map[string]prosemirror.MarkType) {
+	for name, node := range nodes {
+		spec := nodeSpecs[name]
+		spec.ToDOM = node.ToDOM
+		nodes[name] = spec
+	}
+
+	for name, mark := range marks {
+		spec := markSpecs[name]
+		spec.ToDOM = mark.ToDOM
+		marks[name] = spec
+	}
+}
@@ -0,0 +1,89 @@
+package article
+
+import (
+	articleutil "emviwiki/backend/article/util"
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	maxSearchQueries  = 10
+	maxSearchQueryLen = 200
+)
+
+type ArticleStatistics struct {
+	Views                uint                                   `json:"views"`
+	Readers              int                                    `json:"readers"`
+	LatestVersion        int                                    `json:"latest_version"`
+	LatestVersionReaders int                                    `json:"latest_version_readers"`
+	ViewsPerDay          []model.ArticleVisitStatistic          `json:"views_per_day"`
+	ReadersByGroup       []model.ArticleVisitUserGroupStatistic `json:"readers_by_group"`
+	SearchQueries        []model.ArticleSearchQueryStatistic    `json:"search_queries"`
+}
+
+// ReadArticleStatistics returns the reading analytics for given article and language over the past number of days.
+// Only users with write access to the article or administrators and moderators can read the statistics.
+func ReadArticleStatistics(ctx context.EmviContext, articleId, langId hide.ID, days int) (*ArticleStatistics, error) {
+	article, err := articleutil.GetArticleWithAccess(nil, ctx, articleId, true)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasWriteAccess(article, ctx.UserId) {
+		if _, err := perm.CheckUserIsAdminOrMod(ctx.Organization.ID, ctx.UserId); err != nil {
+			return nil, errs.PermissionDenied
+		}
+	}
+
+	langId = util.DetermineLang(nil, ctx.Organization.ID, ctx.UserId, langId).ID
+	defTime := util.GetStatisticsStartTime(days)
+	latestVersion := 0
+	latestVersionReaders := 0
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, langId, false)
+
+	if lastContent != nil {
+		latestVersion = lastContent.Version
+		latestVersionReaders = model.CountArticleVisitReaderByArticleIdAndLanguageIdAndMinVersion(articleId, langId, latestVersion)
+	}
+
+	return &ArticleStatistics{
+		article.Views,
+		model.CountArticleVisitReaderByArticleId(articleId),
+		latestVersion,
+		latestVersionReaders,
+		model.FindArticleVisitStatisticByArticleIdAndDefTimeAfter(articleId, defTime),
+		model.FindArticleVisitUserGroupStatisticByOrganizationIdAndArticleIdAndDefTimeAfter(ctx.Organization.ID, articleId, defTime),
+		model.FindArticleSearchQueryStatisticByArticleIdAndDefTimeAfterLimit(articleId, defTime, maxSearchQueries),
+	}, nil
+}
+
+// TrackSearchQuery saves the search query that led the user to the article.
+// Errors are logged but not returned, since this is not essential to read an article.
+func TrackSearchQuery(ctx context.EmviContext, articleId hide.ID, query string) {
+	query = strings.TrimSpace(query)
+
+	if !ctx.IsUser() || query == "" {
+		return
+	}
+
+	if utf8.RuneCountInString(query) > maxSearchQueryLen {
+		query = string([]rune(query)[:maxSearchQueryLen])
+	}
+
+	searchQuery := &model.ArticleSearchQuery{OrganizationId: ctx.Organization.ID,
+		ArticleId: articleId,
+		UserId:    ctx.UserId,
+		Query:     query}
+
+	if err := model.SaveArticleSearchQuery(nil, searchQuery); err != nil {
+		logbuch.Error("Error saving article search query", logbuch.Fields{"err": err, "article_id": articleId, "user_id": ctx.UserId})
+	}
+}
@@ -0,0 +1,90 @@
+package article
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReadArticleStatistics(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "reader@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	group := testutil.CreateUserGroup(t, orga, "readers")
+	testutil.CreateUserGroupMember(t, group, user2, false)
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(article.ID, lang.ID, false)
+	updateArticleViews(article, content, user.ID)
+	updateArticleViews(article, content, user2.ID)
+	updateArticleViews(article, content, user2.ID)
+
+	if err := model.MergeArticleViewTx(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	TrackSearchQuery(context.NewEmviUserContext(orga, user2.ID), article.ID, " Foo ")
+	TrackSearchQuery(context.NewEmviUserContext(orga, user2.ID), article.ID, "foo")
+	TrackSearchQuery(context.NewEmviUserContext(orga, user2.ID), article.ID, "")
+
+	if _, err := ReadArticleStatistics(context.NewEmviUserContext(orga, user2.ID), article.ID, lang.ID, 0); err != errs.PermissionDenied {
+		t.Fatalf("Permission must be denied for readers, but was: %v", err)
+	}
+
+	statistics, err := ReadArticleStatistics(context.NewEmviUserContext(orga, user.ID), article.ID, lang.ID, 7)
+
+	if err != nil {
+		t.Fatalf("Statistics must be returned, but was: %v", err)
+	}
+
+	if statistics.Readers != 2 || statistics.LatestVersion != 2 || statistics.LatestVersionReaders != 2 {
+		t.Fatalf("Statistics not as expected: %v", statistics)
+	}
+
+	if len(statistics.ViewsPerDay) != 7 {
+		t.Fatalf("Expected views for 7 days, but was: %v", len(statistics.ViewsPerDay))
+	}
+
+	today := statistics.ViewsPerDay[len(statistics.ViewsPerDay)-1]
+
+	if today.Views != 3 || today.Readers != 2 {
+		t.Fatalf("Views for today not as expected: %v", today)
+	}
+
+	if len(statistics.ReadersByGroup) == 0 {
+		t.Fatal("Readers by group must be returned")
+	}
+
+	found := false
+
+	for _, g := range statistics.ReadersByGroup {
+		if g.UserGroupId == group.ID && g.Readers == 1 {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatalf("Group readers not as expected: %v", statistics.ReadersByGroup)
+	}
+
+	if len(statistics.SearchQueries) != 1 || statistics.SearchQueries[0].Query != "foo" || statistics.SearchQueries[0].Count != 2 {
+		t.Fatalf("Search queries not as expected: %v", statistics.SearchQueries)
+	}
+}
+
+func TestTrackSearchQueryMaxLen(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	TrackSearchQuery(context.NewEmviUserContext(orga, user.ID), article.ID, strings.Repeat("a", maxSearchQueryLen+1))
+	queries := model.FindArticleSearchQueryStatisticByArticleIdAndDefTimeAfterLimit(article.ID, time.Now().Add(-time.Minute), 10)
+
+	if len(queries) != 1 || len(queries[0].Query) != maxSearchQueryLen {
+		t.Fatalf("Search query must have been cut, but was: %v", queries)
+	}
+}
@@ -490,7 +490,7 @@ const exportSampleDoc = `{
             {
                "type":"file",
                "attrs":{
-                  "file":"http://localhost:4003/api/v1/content/j06rqfiflKwSRgmtw5li.txt",
+                  "file":"http://localhost:4003/api/v1/content/j06rqfiflKwSRgmtw5li.txt?organization=a&expires=1&signature=b",
                   "name":"test.txt",
                   "size":"20.94 kB"
                }
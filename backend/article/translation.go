@@ -0,0 +1,130 @@
+package article
+
+import (
+	"emviwiki/backend/feed"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	TranslationUpToDate = "up_to_date"
+	TranslationOutdated = "outdated"
+	TranslationMissing  = "missing"
+
+	maxTranslationStatus = 50
+)
+
+// ReadTranslationStatusData filters the translation dashboard.
+// All languages except the default language are returned if the language ID is not set.
+type ReadTranslationStatusData struct {
+	LanguageId hide.ID
+	Pending    bool
+	Offset     int
+}
+
+// ReadTranslationStatus returns the status of all translations of articles the user has access to,
+// compared to the last version in the default language, and the total number of results.
+func ReadTranslationStatus(orga *model.Organization, userId hide.ID, data ReadTranslationStatusData) ([]model.TranslationStatus, int) {
+	filter := &model.SearchTranslationStatusFilter{LanguageId: data.LanguageId,
+		Pending: data.Pending,
+		Offset:  data.Offset,
+		Limit:   maxTranslationStatus}
+	status := model.FindTranslationStatusByOrganizationIdAndUserIdAndFilterLimit(orga.ID, userId, filter)
+
+	for i := range status {
+		status[i].Status = getTranslationStatus(status[i].Version, status[i].TranslationVersion, status[i].SourceVersion)
+	}
+
+	return status, model.CountTranslationStatusByOrganizationIdAndUserIdAndFilter(orga.ID, userId, filter)
+}
+
+// Translations without source version were created before it was tracked or the default language was switched.
+// They are considered outdated, as they cannot be compared.
+func getTranslationStatus(version int, translationVersion, sourceVersion null.Int64) string {
+	if !translationVersion.Valid {
+		return TranslationMissing
+	}
+
+	if !sourceVersion.Valid || sourceVersion.Int64 < int64(version) {
+		return TranslationOutdated
+	}
+
+	return TranslationUpToDate
+}
+
+// Returns the last version in the default language the content in given language is based on.
+// The source version of the last commit is kept, unless the translation is new or has been marked as synced by the editor.
+// Content in the default language has no source version.
+func getSourceVersion(tx *sqlx.Tx, orgaId, articleId, langId hide.ID, synced bool, lastCommit *model.ArticleContent) null.Int64 {
+	defaultLang := model.GetDefaultLanguageByOrganizationIdTx(tx, orgaId)
+
+	if defaultLang == nil || defaultLang.ID == langId {
+		return null.Int64{}
+	}
+
+	if !synced && lastCommit != nil {
+		return lastCommit.SourceVersion
+	}
+
+	source := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIPTx(tx, articleId, defaultLang.ID, false)
+
+	if source == nil {
+		return null.Int64{}
+	}
+
+	return null.NewInt64(int64(source.Version), true)
+}
+
+// Notifies the translators of all languages which translation was up to date with the previous version in the default language,
+// but became outdated by the new content. Nothing happens if the content is not in the default language.
+func notifyTranslators(orga *model.Organization, userId hide.ID, article *model.Article, content *model.ArticleContent, previousVersion int) {
+	defaultLang := model.GetDefaultLanguageByOrganizationId(orga.ID)
+
+	if defaultLang == nil || defaultLang.ID != content.LanguageId || previousVersion == 0 {
+		return
+	}
+
+	for _, lang := range model.FindLanguagesByOrganizationId(orga.ID) {
+		if lang.ID == defaultLang.ID {
+			continue
+		}
+
+		translation := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(article.ID, lang.ID, false)
+
+		if translation == nil || !translation.SourceVersion.Valid || translation.SourceVersion.Int64 < int64(previousVersion) {
+			continue
+		}
+
+		notify := make([]hide.ID, 0)
+
+		for _, translatorId := range model.FindLanguageTranslatorUserIdByOrganizationIdAndLanguageId(orga.ID, lang.ID) {
+			if translatorId != userId && (article.ReadEveryone || article.WriteEveryone || perm.CheckUserReadOrWriteAccess(article.ID, translatorId)) {
+				notify = append(notify, translatorId)
+			}
+		}
+
+		if len(notify) == 0 {
+			continue
+		}
+
+		refs := make([]interface{}, 3)
+		refs[0] = article
+		refs[1] = content
+		refs[2] = feed.KeyValue{"language", lang.Name}
+		feedData := &feed.CreateFeedData{Organization: orga,
+			UserId: userId,
+			Reason: "translation_outdated",
+			Public: false,
+			Access: []hide.ID{},
+			Notify: notify,
+			Refs:   refs}
+
+		if err := feed.CreateFeed(feedData); err != nil {
+			logbuch.Error("Error creating feed when translation became outdated", logbuch.Fields{"err": err, "article_id": article.ID, "lang_id": lang.ID})
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package article
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/null"
+	"testing"
+)
+
+func TestGetTranslationStatus(t *testing.T) {
+	input := []struct {
+		version            int
+		translationVersion null.Int64
+		sourceVersion      null.Int64
+	}{
+		{2, null.Int64{}, null.Int64{}},
+		{2, null.NewInt64(1, true), null.Int64{}},
+		{2, null.NewInt64(1, true), null.NewInt64(1, true)},
+		{2, null.NewInt64(3, true), null.NewInt64(2, true)},
+	}
+	expected := []string{TranslationMissing, TranslationOutdated, TranslationOutdated, TranslationUpToDate}
+
+	for i, in := range input {
+		if status := getTranslationStatus(in.version, in.translationVersion, in.sourceVersion); status != expected[i] {
+			t.Fatalf("Expected status %v for input %d, but was: %v", expected[i], i, status)
+		}
+	}
+}
+
+func TestTranslationStatus(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	ja := model.GetLanguageByOrganizationIdAndCode(orga.ID, "ja")
+	translator := testutil.CreateUser(t, orga, 321, "translator@user.com")
+	saveTestTranslator(t, ja, translator)
+	data := SaveArticleData{Organization: orga,
+		UserId:       user.ID,
+		LanguageId:   lang.ID,
+		ReadEveryone: true,
+		Title:        "title",
+		Content:      simpleSampleDoc}
+	id, err := SaveArticle(data)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertTranslationStatus(t, orga, user, ja, TranslationMissing)
+	data.Id = id
+	data.LanguageId = ja.ID
+	data.Title = "タイトル"
+
+	if _, err := SaveArticle(data); err != nil {
+		t.Fatal(err)
+	}
+
+	content := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(id, ja.ID, false)
+
+	if content == nil || !content.SourceVersion.Valid || content.SourceVersion.Int64 != 1 {
+		t.Fatalf("Translation must be based on version 1, but was: %v", content)
+	}
+
+	assertTranslationStatus(t, orga, user, ja, TranslationUpToDate)
+
+	if len(model.FindFeedByOrganizationIdAndReason(orga.ID, "translation_outdated")) != 0 {
+		t.Fatal("Translators must not have been notified")
+	}
+
+	data.LanguageId = lang.ID
+	data.Content = simpleSampleDoc2
+
+	if _, err := SaveArticle(data); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTranslationStatus(t, orga, user, ja, TranslationOutdated)
+	assertTranslatorNotifications(t, orga, 1)
+
+	// the translation is outdated already, so translators are not notified again
+	ctx := context.NewEmviUserContext(orga, user.ID)
+	step := prosemirror.Step{StepType: prosemirror.StepReplace,
+		From:  0,
+		To:    0,
+		Slice: &prosemirror.Slice{Content: []prosemirror.Node{{Type: "paragraph", Content: []prosemirror.Node{{Type: "text", Text: "patch"}}}}}}
+
+	if _, err := PatchArticle(ctx, id, PatchArticleData{LanguageId: lang.ID, Steps: []prosemirror.Step{step}}); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTranslatorNotifications(t, orga, 1)
+	status, count := ReadTranslationStatus(orga, user.ID, ReadTranslationStatusData{LanguageId: ja.ID, Pending: true})
+
+	if len(status) != 1 || count != 1 || status[0].Version != 3 {
+		t.Fatalf("Outdated translation must be returned, but was: %v %v", count, status)
+	}
+
+	// the translation stays outdated until it is marked as synced
+	data.LanguageId = ja.ID
+	data.Content = simpleSampleDoc
+
+	if _, err := SaveArticle(data); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTranslationStatus(t, orga, user, ja, TranslationOutdated)
+	data.SourceSynced = true
+
+	if _, err := SaveArticle(data); err != nil {
+		t.Fatal(err)
+	}
+
+	assertTranslationStatus(t, orga, user, ja, TranslationUpToDate)
+
+	status, count = ReadTranslationStatus(orga, user.ID, ReadTranslationStatusData{LanguageId: lang.ID})
+
+	if len(status) != 0 || count != 0 {
+		t.Fatalf("Default language must not be returned, but was: %v %v", count, status)
+	}
+}
+
+func TestTranslationStatusAccess(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	testutil.CreateArticle(t, orga, user, lang, false, false)
+	userNoAccess := testutil.CreateUser(t, orga, 321, "noaccess@user.com")
+
+	if status, count := ReadTranslationStatus(orga, user.ID, ReadTranslationStatusData{}); len(status) == 0 || count != len(status) {
+		t.Fatalf("Translation status must be returned, but was: %v %v", count, status)
+	}
+
+	if status, count := ReadTranslationStatus(orga, userNoAccess.ID, ReadTranslationStatusData{}); len(status) != 0 || count != 0 {
+		t.Fatalf("Translation status must not be returned without access, but was: %v %v", count, status)
+	}
+}
+
+func saveTestTranslator(t *testing.T, lang *model.Language, user *model.User) {
+	if err := model.SaveLanguageTranslator(nil, &model.LanguageTranslator{LanguageId: lang.ID, UserId: user.ID}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func assertTranslationStatus(t *testing.T, orga *model.Organization, user *model.User, lang *model.Language, expected string) {
+	status, count := ReadTranslationStatus(orga, user.ID, ReadTranslationStatusData{LanguageId: lang.ID})
+
+	if len(status) != 1 || count != 1 || status[0].Status != expected {
+		t.Fatalf("Expected translation status %v, but was: %v", expected, status)
+	}
+}
+
+func assertTranslatorNotifications(t *testing.T, orga *model.Organization, n int) {
+	feed := model.FindFeedByOrganizationIdAndReason(orga.ID, "translation_outdated")
+
+	if len(feed) != n {
+		t.Fatalf("Expected %d translator notifications, but was: %v", n, len(feed))
+	}
+}
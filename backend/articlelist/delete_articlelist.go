@@ -38,6 +38,11 @@ func DeleteArticleList(organization *model.Organization, userId, listId hide.ID)
 		return errs.Saving
 	}
 
+	if err := saveListTrash(tx, organization, userId, listId); err != nil {
+		logbuch.Error("Error moving article list to trash", logbuch.Fields{"err": err, "list_id": listId, "user_id": userId})
+		return errs.Saving
+	}
+
 	if err := model.DeleteArticleListById(tx, listId); err != nil {
 		return errs.Saving
 	}
@@ -60,6 +65,21 @@ func checkListExists(organization *model.Organization, listId hide.ID) (*model.A
 	return list, nil
 }
 
+func saveListTrash(tx *sqlx.Tx, orga *model.Organization, userId, listId hide.ID) error {
+	langId := util.DetermineLang(tx, orga.ID, userId, 0).ID
+	name := model.GetArticleListNameByOrganizationIdAndArticleListIdAndLangIdTx(tx, orga.ID, listId, langId)
+	trash := &model.Trash{OrganizationId: orga.ID,
+		UserId:   userId,
+		Type:     model.TrashTypeArticleList,
+		ObjectId: listId}
+
+	if name != nil {
+		trash.Name = name.Name
+	}
+
+	return model.SaveTrashSnapshot(tx, trash)
+}
+
 func createDeletedListFeed(tx *sqlx.Tx, orga *model.Organization, userId, listId hide.ID) error {
 	list := model.GetArticleListByOrganizationIdAndIdTx(tx, orga.ID, listId)
 
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/db"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	botUsernamePrefix = "bot-"
+	botUsernameLen    = 8
+)
+
+// createBot creates a new bot user and adds it to the organization. The transaction is rolled back on error.
+// Clients with write access act as their bot, so that changes are attributed to it and its permissions apply.
+func createBot(tx *sqlx.Tx, orgaId hide.ID, name string) (hide.ID, error) {
+	lang := model.GetDefaultLanguageByOrganizationIdTx(tx, orgaId)
+
+	if lang == nil {
+		db.Rollback(tx)
+		return 0, errs.LanguageNotFound
+	}
+
+	user := &model.User{Firstname: name}
+	user.Language.SetValid(lang.Code)
+
+	if err := model.SaveBotUser(tx, user); err != nil {
+		logbuch.Error("Error saving bot user", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return 0, errs.Saving
+	}
+
+	member := &model.OrganizationMember{OrganizationId: orgaId,
+		UserId:     user.ID,
+		LanguageId: lang.ID,
+		Username:   newBotUsername(orgaId),
+		Active:     true,
+		Timezone:   model.DefaultTimezone}
+
+	if err := model.SaveOrganizationMember(tx, member); err != nil {
+		logbuch.Error("Error saving bot organization member", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return 0, errs.Saving
+	}
+
+	return user.ID, nil
+}
+
+// deactivateBot removes the bot from the organization.
+// The user is kept, as it is still referenced by the changes made by the bot.
+func deactivateBot(tx *sqlx.Tx, orgaId, userId hide.ID) error {
+	member := model.GetOrganizationMemberByOrganizationIdAndUserIdTx(tx, orgaId, userId)
+
+	if member == nil {
+		return nil
+	}
+
+	member.Active = false
+
+	if err := model.SaveOrganizationMember(tx, member); err != nil {
+		logbuch.Error("Error deactivating bot organization member", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return errs.Saving
+	}
+
+	return nil
+}
+
+func newBotUsername(orgaId hide.ID) string {
+	for {
+		username := botUsernamePrefix + util.GenRandomString(botUsernameLen)
+
+		if model.GetOrganizationMemberByOrganizationIdAndUsername(orgaId, username) == nil {
+			return username
+		}
+	}
+}
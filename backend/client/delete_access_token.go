@@ -0,0 +1,31 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+// DeleteAccessToken revokes a personal access token. Administrators can revoke the tokens of all members.
+func DeleteAccessToken(orga *model.Organization, userId, id hide.ID) error {
+	token := model.GetAccessTokenByOrganizationIdAndId(orga.ID, id)
+
+	if token == nil {
+		return errs.AccessTokenNotFound
+	}
+
+	if token.UserId != userId {
+		if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+			return err
+		}
+	}
+
+	if err := model.DeleteAccessTokenById(nil, id); err != nil {
+		logbuch.Error("Error deleting access token", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId, "id": id})
+		return errs.Saving
+	}
+
+	return nil
+}
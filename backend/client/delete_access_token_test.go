@@ -0,0 +1,43 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+	"time"
+)
+
+func TestDeleteAccessToken(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	other := testutil.CreateUser(t, orga, 322, "other@test.com")
+	data := &SaveAccessTokenData{Name: "token",
+		Scopes:  []Scope{{"articles", true, false}},
+		Expires: time.Now().AddDate(0, 0, 30)}
+	accessToken, _, _ := SaveAccessToken(orga, user.ID, data)
+
+	if err := DeleteAccessToken(orga, user.ID, 0); err != errs.AccessTokenNotFound {
+		t.Fatalf("Access token must not be found, but was: %v", err)
+	}
+
+	if err := DeleteAccessToken(orga, other.ID, accessToken.ID); err != errs.PermissionDenied {
+		t.Fatalf("Other member must not be allowed to delete access token, but was: %v", err)
+	}
+
+	if err := DeleteAccessToken(orga, user.ID, accessToken.ID); err != nil {
+		t.Fatalf("Access token must be deleted by owner, but was: %v", err)
+	}
+
+	if model.GetAccessTokenByOrganizationIdAndId(orga.ID, accessToken.ID) != nil {
+		t.Fatal("Access token must not exist anymore")
+	}
+
+	data.Name = "other"
+	accessToken, _, _ = SaveAccessToken(orga, user.ID, data)
+
+	if err := DeleteAccessToken(orga, admin.ID, accessToken.ID); err != nil {
+		t.Fatalf("Access token must be deleted by administrator, but was: %v", err)
+	}
+}
@@ -25,6 +25,12 @@ func DeleteClient(orga *model.Organization, userId, id hide.ID, auth auth.AuthCl
 		return errs.Saving
 	}
 
+	if client.UserId != 0 {
+		if err := deactivateBot(nil, orga.ID, client.UserId); err != nil {
+			return err
+		}
+	}
+
 	if err := model.DeleteClientById(nil, id); err != nil {
 		return errs.Saving
 	}
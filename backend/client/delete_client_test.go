@@ -37,3 +37,29 @@ func TestDeleteClient(t *testing.T) {
 		t.Fatalf("Delete client must have been called once")
 	}
 }
+
+func TestDeleteClientBot(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	authProvider := auth.NewMockAuthClient()
+	authProvider.NewClientMockResponse = &auth.NewClientResponse{ClientId: "id", ClientSecret: "secret"}
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	data := &SaveClientData{Name: "client", Scopes: []Scope{{"tags", true, true}}}
+
+	if err := SaveClient(orga, admin.ID, data, authProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	client := model.GetClientByOrganizationIdAndName(orga.ID, "client")
+
+	if err := DeleteClient(orga, admin.ID, client.ID, authProvider); err != nil {
+		t.Fatalf("Client must be deleted, but was: %v", err)
+	}
+
+	if model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, client.UserId) != nil {
+		t.Fatal("Bot must have been removed from organization")
+	}
+
+	if model.GetUserById(client.UserId) == nil {
+		t.Fatal("Bot user must be kept")
+	}
+}
@@ -0,0 +1,17 @@
+package client
+
+import (
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+// ReadAccessTokens returns the personal access tokens of the organization member.
+func ReadAccessTokens(orga *model.Organization, userId hide.ID) []model.AccessToken {
+	tokens := model.FindAccessTokenByOrganizationIdAndUserId(orga.ID, userId)
+
+	for i := range tokens {
+		tokens[i].Scopes = model.FindAccessTokenScopeByAccessTokenId(tokens[i].ID)
+	}
+
+	return tokens
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"crypto/rand"
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"encoding/base64"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// AccessTokenPrefix is the prefix of personal access tokens to tell them apart from tokens issued by auth.
+	AccessTokenPrefix = "emvi_pat_"
+
+	accessTokenBytes   = 32
+	maxAccessTokenDays = 365
+)
+
+type SaveAccessTokenData struct {
+	Name    string    `json:"name"`
+	Scopes  []Scope   `json:"scopes"`
+	Expires time.Time `json:"expires"`
+}
+
+func (data *SaveAccessTokenData) validate(orgaId, userId hide.ID) []error {
+	data.Name = strings.TrimSpace(data.Name)
+	err := make([]error, 0)
+
+	if len(data.Name) == 0 {
+		err = append(err, errs.NameEmpty)
+	} else if utf8.RuneCountInString(data.Name) > nameMaxLen {
+		err = append(err, errs.NameLen)
+	} else if model.GetAccessTokenByOrganizationIdAndUserIdAndName(orgaId, userId, data.Name) != nil {
+		err = append(err, errs.AccessTokenExistsAlready)
+	}
+
+	scopes, scopeErr := validateScopes(data.Scopes)
+
+	if scopeErr != nil {
+		err = append(err, scopeErr)
+	} else if len(scopes) == 0 {
+		err = append(err, errs.ScopeInvalid)
+	}
+
+	data.Scopes = scopes
+
+	if now := time.Now(); data.Expires.Before(now) || data.Expires.After(now.AddDate(0, 0, maxAccessTokenDays)) {
+		err = append(err, errs.ExpiresInvalid)
+	}
+
+	if len(err) == 0 {
+		return nil
+	}
+
+	return err
+}
+
+// SaveAccessToken creates a new personal access token for the organization member and returns it.
+// The token cannot be read afterwards, as only its hash is stored.
+func SaveAccessToken(orga *model.Organization, userId hide.ID, data *SaveAccessTokenData) (*model.AccessToken, string, []error) {
+	if model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId) == nil {
+		return nil, "", []error{errs.PermissionDenied}
+	}
+
+	if err := data.validate(orga.ID, userId); err != nil {
+		return nil, "", err
+	}
+
+	token, err := generateAccessToken()
+
+	if err != nil {
+		logbuch.Error("Error generating access token", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId})
+		return nil, "", []error{errs.Saving}
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to save access token", logbuch.Fields{"err": err})
+		return nil, "", []error{errs.TxBegin}
+	}
+
+	accessToken := &model.AccessToken{OrganizationId: orga.ID,
+		UserId:    userId,
+		Name:      data.Name,
+		TokenHash: util.Sha256Base64(token),
+		Expires:   data.Expires}
+
+	if err := model.SaveAccessToken(tx, accessToken); err != nil {
+		logbuch.Error("Error saving access token", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId})
+		return nil, "", []error{errs.Saving}
+	}
+
+	for _, s := range data.Scopes {
+		scope := &model.AccessTokenScope{AccessTokenId: accessToken.ID,
+			Name:  s.Name,
+			Read:  s.Read,
+			Write: s.Write}
+
+		if err := model.SaveAccessTokenScope(tx, scope); err != nil {
+			logbuch.Error("Error saving access token scope", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId})
+			return nil, "", []error{errs.Saving}
+		}
+
+		accessToken.Scopes = append(accessToken.Scopes, *scope)
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction while saving access token", logbuch.Fields{"err": err})
+		return nil, "", []error{errs.TxCommit}
+	}
+
+	return accessToken, token, nil
+}
+
+func generateAccessToken() (string, error) {
+	token := make([]byte, accessTokenBytes)
+
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+
+	return AccessTokenPrefix + base64.RawURLEncoding.EncodeToString(token), nil
+}
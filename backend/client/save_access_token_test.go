@@ -0,0 +1,72 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"emviwiki/shared/util"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveAccessTokenDataValidate(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	data := &SaveAccessTokenData{Name: "", Expires: time.Now().Add(time.Hour)}
+
+	if err := data.validate(orga.ID, user.ID); len(err) != 2 || err[0] != errs.NameEmpty || err[1] != errs.ScopeInvalid {
+		t.Fatalf("Expected name and scopes to be invalid, but was: %v", err)
+	}
+
+	data.Name = "token"
+	data.Scopes = []Scope{{"articles", true, false}}
+	data.Expires = time.Now().Add(-time.Hour)
+
+	if err := data.validate(orga.ID, user.ID); len(err) != 1 || err[0] != errs.ExpiresInvalid {
+		t.Fatalf("Expected expiry to be invalid, but was: %v", err)
+	}
+
+	data.Expires = time.Now().AddDate(0, 0, maxAccessTokenDays+1)
+
+	if err := data.validate(orga.ID, user.ID); len(err) != 1 || err[0] != errs.ExpiresInvalid {
+		t.Fatalf("Expected expiry to be invalid, but was: %v", err)
+	}
+
+	data.Expires = time.Now().AddDate(0, 0, 30)
+
+	if err := data.validate(orga.ID, user.ID); err != nil {
+		t.Fatalf("Expected data to be valid, but was: %v", err)
+	}
+}
+
+func TestSaveAccessToken(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	data := &SaveAccessTokenData{Name: "token",
+		Scopes:  []Scope{{"articles", false, true}, {"tags", true, false}},
+		Expires: time.Now().AddDate(0, 0, 30)}
+	accessToken, token, err := SaveAccessToken(orga, user.ID, data)
+
+	if err != nil {
+		t.Fatalf("Access token must have been saved, but was: %v", err)
+	}
+
+	if !strings.HasPrefix(token, AccessTokenPrefix) || !IsAccessToken(token) {
+		t.Fatalf("Access token must be prefixed, but was: %v", token)
+	}
+
+	if accessToken.TokenHash == token || accessToken.TokenHash != util.Sha256Base64(token) {
+		t.Fatal("Only the hash of the access token must be stored")
+	}
+
+	scopes := model.FindAccessTokenScopeByAccessTokenId(accessToken.ID)
+
+	if len(scopes) != 2 || !scopes[0].Read || !scopes[0].Write {
+		t.Fatalf("Access token scopes must have been saved, but was: %v", scopes)
+	}
+
+	if _, _, err := SaveAccessToken(orga, user.ID, data); len(err) != 1 || err[0] != errs.AccessTokenExistsAlready {
+		t.Fatalf("Access token name must be unique, but was: %v", err)
+	}
+}
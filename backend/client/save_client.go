@@ -34,44 +34,13 @@ func (data *SaveClientData) validate(orgaId hide.ID) []error {
 		err = append(err, errs.ClientExistsAlready)
 	}
 
-	newScopes := make([]Scope, 0, len(data.Scopes))
+	scopes, scopeErr := validateScopes(data.Scopes)
 
-	for i := range data.Scopes {
-		if !data.Scopes[i].Read && !data.Scopes[i].Write {
-			continue
-		}
-
-		data.Scopes[i].Name = strings.ToLower(data.Scopes[i].Name)
-		found := false
-
-		for _, s := range newScopes {
-			if s.Name == data.Scopes[i].Name {
-				found = true
-			}
-
-			data.Scopes[i].Read = data.Scopes[i].Read || data.Scopes[i].Write
-		}
-
-		if found {
-			continue
-		}
-
-		scope, ok := Scopes[data.Scopes[i].Name]
-
-		if !ok {
-			err = append(err, errs.ScopeInvalid)
-			break
-		}
-
-		if (data.Scopes[i].Read && !scope.Read) || (data.Scopes[i].Write && !scope.Write) {
-			err = append(err, errs.ScopeInvalid)
-			break
-		}
-
-		newScopes = append(newScopes, data.Scopes[i])
+	if scopeErr != nil {
+		err = append(err, scopeErr)
 	}
 
-	data.Scopes = newScopes
+	data.Scopes = scopes
 
 	if len(err) == 0 {
 		return nil
@@ -140,6 +109,14 @@ func newClient(orgaId hide.ID, data *SaveClientData, auth auth.AuthClient) error
 		ClientId:     clientId,
 		ClientSecret: clientSecret}
 
+	if hasWriteScope(data.Scopes) {
+		client.UserId, err = createBot(tx, orgaId, data.Name)
+
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := model.SaveClient(tx, client); err != nil {
 		logbuch.Error("Error saving client while creating new client", logbuch.Fields{"err": err, "orga_id": orgaId})
 		return errs.Saving
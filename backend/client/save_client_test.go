@@ -50,7 +50,7 @@ func TestSaveClientDataValidateScopes(t *testing.T) {
 	}
 
 	data.Scopes = []Scope{
-		{"articles", true, true},
+		{"organization", true, true},
 	}
 
 	if err := data.validate(orga.ID); len(err) != 1 && err[0] != errs.ScopeInvalid {
@@ -156,6 +156,40 @@ func TestSaveClientNewClient(t *testing.T) {
 	if authProvider.NewClientCalls != 1 {
 		t.Fatalf("New client must have been called once, but was: %v", authProvider.NewClientCalls)
 	}
+
+	if client.UserId != 0 {
+		t.Fatal("Client without write access must not have a bot")
+	}
+}
+
+func TestSaveClientNewClientWrite(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	authProvider := auth.NewMockAuthClient()
+	authProvider.NewClientMockResponse = &auth.NewClientResponse{ClientId: "01234567890123456789",
+		ClientSecret: "0123456789012345678901234567890123456789012345678901234567891234"}
+	orga, user := testutil.CreateOrgaAndUser(t)
+	data := &SaveClientData{Name: "name", Scopes: []Scope{{"articles", true, true}}}
+
+	if err := SaveClient(orga, user.ID, data, authProvider); err != nil {
+		t.Fatalf("New client must have been created, but was: %v", err)
+	}
+
+	client := model.GetClientByOrganizationIdAndName(orga.ID, "name")
+	bot := model.GetUserById(client.UserId)
+
+	if bot == nil || !bot.Bot || bot.Firstname != "name" {
+		t.Fatalf("Bot must have been created for client, but was: %v", bot)
+	}
+
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, bot.ID)
+
+	if member == nil || member.IsAdmin || member.ReadOnly || member.SendNotificationsInterval != 0 || member.RecommendationMail {
+		t.Fatalf("Bot must have been added to organization, but was: %v", member)
+	}
+
+	if model.CountOrganizationMemberByOrganizationIdAndActiveAndNotReadOnly(orga.ID) != 1 {
+		t.Fatal("Bot must not be billed")
+	}
 }
 
 func TestSaveClientNameExistsDifferentOrga(t *testing.T) {
@@ -1,18 +1,21 @@
 package client
 
-import "strings"
+import (
+	"emviwiki/backend/errs"
+	"strings"
+)
 
 var (
 	// list of valid scopes and if read/write is supported
 	Scopes = map[string]Scope{
 		"organization":          {"organization", true, false},
 		"language":              {"language", true, false},
-		"articles":              {"articles", true, false},
+		"articles":              {"articles", true, true},
 		"article_authors":       {"article_authors", true, false},
 		"article_authors_mails": {"article_authors_mails", true, false},
 		"article_history":       {"article_history", true, false},
-		"lists":                 {"lists", true, false},
-		"tags":                  {"tags", true, false},
+		"lists":                 {"lists", true, true},
+		"tags":                  {"tags", true, true},
 		"pinned":                {"pinned_articles", true, false},
 		"search_articles":       {"search_articles", true, false},
 		"search_lists":          {"search_lists", true, false},
@@ -63,3 +66,54 @@ func ScopeFromString(scopeStr string) Scope {
 
 	return scope
 }
+
+// hasWriteScope returns true if one of the scopes grants write access.
+func hasWriteScope(scopes []Scope) bool {
+	for _, scope := range scopes {
+		if scope.Write {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validateScopes removes scopes without read or write access and duplicates.
+// An error is returned if a scope doesn't exist or doesn't support the requested access.
+func validateScopes(scopes []Scope) ([]Scope, error) {
+	newScopes := make([]Scope, 0, len(scopes))
+
+	for i := range scopes {
+		if !scopes[i].Read && !scopes[i].Write {
+			continue
+		}
+
+		scopes[i].Name = strings.ToLower(scopes[i].Name)
+		scopes[i].Read = scopes[i].Read || scopes[i].Write
+		found := false
+
+		for _, s := range newScopes {
+			if s.Name == scopes[i].Name {
+				found = true
+			}
+		}
+
+		if found {
+			continue
+		}
+
+		scope, ok := Scopes[scopes[i].Name]
+
+		if !ok {
+			return newScopes, errs.ScopeInvalid
+		}
+
+		if (scopes[i].Read && !scope.Read) || (scopes[i].Write && !scope.Write) {
+			return newScopes, errs.ScopeInvalid
+		}
+
+		newScopes = append(newScopes, scopes[i])
+	}
+
+	return newScopes, nil
+}
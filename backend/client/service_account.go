@@ -0,0 +1,139 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"strings"
+	"unicode/utf8"
+)
+
+type SaveServiceAccountData struct {
+	Name string `json:"name"`
+}
+
+func (data *SaveServiceAccountData) validate() error {
+	data.Name = strings.TrimSpace(data.Name)
+
+	if len(data.Name) == 0 {
+		return errs.NameEmpty
+	} else if utf8.RuneCountInString(data.Name) > nameMaxLen {
+		return errs.NameLen
+	}
+
+	return nil
+}
+
+// SaveServiceAccount creates a new service account for the organization and returns its user ID.
+// Service accounts are bots, which can be added to groups and access lists like other members,
+// but authenticate using personal access tokens only.
+func SaveServiceAccount(orga *model.Organization, userId hide.ID, data *SaveServiceAccountData) (hide.ID, error) {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return 0, err
+	}
+
+	if err := data.validate(); err != nil {
+		return 0, err
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to save service account", logbuch.Fields{"err": err})
+		return 0, errs.TxBegin
+	}
+
+	id, err := createBot(tx, orga.ID, data.Name)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction while saving service account", logbuch.Fields{"err": err})
+		return 0, errs.TxCommit
+	}
+
+	return id, nil
+}
+
+// ReadServiceAccounts returns the service accounts of the organization.
+func ReadServiceAccounts(orga *model.Organization, userId hide.ID) ([]model.User, error) {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return nil, err
+	}
+
+	return model.FindUserByOrganizationIdAndBotAndNotClient(orga.ID), nil
+}
+
+// DeleteServiceAccount removes the service account from the organization and revokes its access tokens.
+func DeleteServiceAccount(orga *model.Organization, userId, id hide.ID) error {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	if getServiceAccount(orga.ID, id) == nil {
+		return errs.ServiceAccountNotFound
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to delete service account", logbuch.Fields{"err": err})
+		return errs.TxBegin
+	}
+
+	if err := model.DeleteAccessTokenByOrganizationIdAndUserId(tx, orga.ID, id); err != nil {
+		logbuch.Error("Error deleting access tokens of service account", logbuch.Fields{"err": err, "orga_id": orga.ID, "id": id})
+		return errs.Saving
+	}
+
+	if err := deactivateBot(tx, orga.ID, id); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction while deleting service account", logbuch.Fields{"err": err})
+		return errs.TxCommit
+	}
+
+	return nil
+}
+
+// ReadServiceAccountAccessTokens returns the access tokens of the service account.
+func ReadServiceAccountAccessTokens(orga *model.Organization, userId, id hide.ID) ([]model.AccessToken, error) {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return nil, err
+	}
+
+	if getServiceAccount(orga.ID, id) == nil {
+		return nil, errs.ServiceAccountNotFound
+	}
+
+	return ReadAccessTokens(orga, id), nil
+}
+
+// SaveServiceAccountAccessToken creates a new access token for the service account and returns it.
+func SaveServiceAccountAccessToken(orga *model.Organization, userId, id hide.ID, data *SaveAccessTokenData) (*model.AccessToken, string, []error) {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return nil, "", []error{err}
+	}
+
+	if getServiceAccount(orga.ID, id) == nil {
+		return nil, "", []error{errs.ServiceAccountNotFound}
+	}
+
+	return SaveAccessToken(orga, id, data)
+}
+
+func getServiceAccount(orgaId, id hide.ID) *model.User {
+	user := model.GetUserWithOrganizationMemberByOrganizationIdAndId(orgaId, id)
+
+	if user == nil || !user.Bot || model.GetClientByOrganizationIdAndUserId(orgaId, id) != nil {
+		return nil
+	}
+
+	return user
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/auth"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+	"time"
+)
+
+func TestSaveServiceAccount(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	data := &SaveServiceAccountData{Name: " "}
+
+	if _, err := SaveServiceAccount(orga, user.ID, data); err != errs.PermissionDenied {
+		t.Fatalf("Member must not be allowed to create service account, but was: %v", err)
+	}
+
+	if _, err := SaveServiceAccount(orga, admin.ID, data); err != errs.NameEmpty {
+		t.Fatalf("Name must be validated, but was: %v", err)
+	}
+
+	data.Name = "Automation"
+	id, err := SaveServiceAccount(orga, admin.ID, data)
+
+	if err != nil {
+		t.Fatalf("Service account must have been created, but was: %v", err)
+	}
+
+	accounts, err := ReadServiceAccounts(orga, admin.ID)
+
+	if err != nil || len(accounts) != 1 || accounts[0].ID != id || !accounts[0].Bot || accounts[0].Firstname != "Automation" {
+		t.Fatalf("Service account must be returned, but was: %v %v", accounts, err)
+	}
+
+	if accounts[0].OrganizationMember == nil || accounts[0].OrganizationMember.Username == "" {
+		t.Fatalf("Service account must be member of organization, but was: %v", accounts[0].OrganizationMember)
+	}
+}
+
+func TestReadServiceAccountsIgnoreClients(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	authProvider := auth.NewMockAuthClient()
+	authProvider.NewClientMockResponse = &auth.NewClientResponse{ClientId: "id", ClientSecret: "secret"}
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	data := &SaveClientData{Name: "client", Scopes: []Scope{{"articles", true, true}}}
+
+	if err := SaveClient(orga, admin.ID, data, authProvider); err != nil {
+		t.Fatal(err)
+	}
+
+	client := model.GetClientByOrganizationIdAndName(orga.ID, "client")
+
+	if accounts, _ := ReadServiceAccounts(orga, admin.ID); len(accounts) != 0 {
+		t.Fatalf("Bots of clients must not be returned, but was: %v", accounts)
+	}
+
+	if err := DeleteServiceAccount(orga, admin.ID, client.UserId); err != errs.ServiceAccountNotFound {
+		t.Fatalf("Bot of client must not be deleted as service account, but was: %v", err)
+	}
+
+	if err := DeleteServiceAccount(orga, admin.ID, admin.ID); err != errs.ServiceAccountNotFound {
+		t.Fatalf("User must not be deleted as service account, but was: %v", err)
+	}
+}
+
+func TestServiceAccountAccessToken(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	id, _ := SaveServiceAccount(orga, admin.ID, &SaveServiceAccountData{Name: "bot"})
+	data := &SaveAccessTokenData{Name: "token",
+		Scopes:  []Scope{{"articles", true, true}},
+		Expires: time.Now().AddDate(0, 0, 30)}
+
+	if _, _, err := SaveServiceAccountAccessToken(orga, admin.ID, admin.ID, data); len(err) != 1 || err[0] != errs.ServiceAccountNotFound {
+		t.Fatalf("Access token must not be created for user, but was: %v", err)
+	}
+
+	accessToken, token, err := SaveServiceAccountAccessToken(orga, admin.ID, id, data)
+
+	if err != nil || accessToken.UserId != id {
+		t.Fatalf("Access token must have been created for service account, but was: %v", err)
+	}
+
+	if result, _, _, err := ValidateAccessToken(token); err != nil || result.UserId != id {
+		t.Fatalf("Service account must authenticate using access token, but was: %v", err)
+	}
+
+	if tokens, err := ReadServiceAccountAccessTokens(orga, admin.ID, id); err != nil || len(tokens) != 1 {
+		t.Fatalf("Access tokens of service account must be returned, but was: %v %v", tokens, err)
+	}
+
+	if err := DeleteServiceAccount(orga, admin.ID, id); err != nil {
+		t.Fatalf("Service account must have been deleted, but was: %v", err)
+	}
+
+	if _, _, _, err := ValidateAccessToken(token); err != errs.AccessTokenNotFound {
+		t.Fatalf("Access token of deleted service account must be revoked, but was: %v", err)
+	}
+
+	if accounts, _ := ReadServiceAccounts(orga, admin.ID); len(accounts) != 0 {
+		t.Fatalf("Deleted service account must not be returned, but was: %v", accounts)
+	}
+}
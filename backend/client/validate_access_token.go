@@ -0,0 +1,56 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/logbuch"
+	"strings"
+	"time"
+)
+
+const (
+	accessTokenLastUsedInterval = time.Minute * 10
+)
+
+// IsAccessToken returns true if given token is a personal access token.
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// ValidateAccessToken returns the personal access token and the organization it belongs to.
+// The token must not be expired and the user must still be an active member of the organization.
+// The scopes of the token are read as strings in the format "name:rw".
+func ValidateAccessToken(token string) (*model.AccessToken, *model.Organization, []string, error) {
+	accessToken := model.GetAccessTokenByTokenHash(util.Sha256Base64(token))
+
+	if accessToken == nil || accessToken.Expires.Before(time.Now()) {
+		return nil, nil, nil, errs.AccessTokenNotFound
+	}
+
+	if model.GetOrganizationMemberByOrganizationIdAndUserId(accessToken.OrganizationId, accessToken.UserId) == nil {
+		return nil, nil, nil, errs.PermissionDenied
+	}
+
+	orga := model.GetOrganizationById(accessToken.OrganizationId)
+
+	if orga == nil {
+		return nil, nil, nil, errs.OrganizationNotFound
+	}
+
+	scopes := model.FindAccessTokenScopeByAccessTokenId(accessToken.ID)
+	scopeStrs := make([]string, 0, len(scopes))
+
+	for _, scope := range scopes {
+		scopeStrs = append(scopeStrs, Scope{scope.Name, scope.Read, scope.Write}.String())
+	}
+
+	// the last usage is updated periodically only, to avoid writing on every request
+	if !accessToken.LastUsed.Valid || accessToken.LastUsed.Time.Before(time.Now().Add(-accessTokenLastUsedInterval)) {
+		if err := model.UpdateAccessTokenLastUsedById(accessToken.ID); err != nil {
+			logbuch.Warn("Error updating access token last used", logbuch.Fields{"err": err, "id": accessToken.ID})
+		}
+	}
+
+	return accessToken, orga, scopeStrs, nil
+}
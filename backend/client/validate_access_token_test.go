@@ -0,0 +1,66 @@
+package client
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+	"time"
+)
+
+func TestValidateAccessToken(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	data := &SaveAccessTokenData{Name: "token",
+		Scopes:  []Scope{{"articles", false, true}},
+		Expires: time.Now().AddDate(0, 0, 30)}
+	accessToken, token, _ := SaveAccessToken(orga, user.ID, data)
+
+	if _, _, _, err := ValidateAccessToken(AccessTokenPrefix + "unknown"); err != errs.AccessTokenNotFound {
+		t.Fatalf("Unknown access token must not be valid, but was: %v", err)
+	}
+
+	result, resultOrga, scopes, err := ValidateAccessToken(token)
+
+	if err != nil || result.ID != accessToken.ID || resultOrga.ID != orga.ID {
+		t.Fatalf("Access token must be valid, but was: %v", err)
+	}
+
+	if len(scopes) != 1 || scopes[0] != "articles:rw" {
+		t.Fatalf("Access token scopes must be returned, but was: %v", scopes)
+	}
+
+	if !model.GetAccessTokenByOrganizationIdAndId(orga.ID, accessToken.ID).LastUsed.Valid {
+		t.Fatal("Access token last used must have been set")
+	}
+
+	accessToken.Expires = time.Now().Add(-time.Minute)
+
+	if err := model.SaveAccessToken(nil, accessToken); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := ValidateAccessToken(token); err != errs.AccessTokenNotFound {
+		t.Fatalf("Expired access token must not be valid, but was: %v", err)
+	}
+}
+
+func TestValidateAccessTokenInactiveMember(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, _ := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	data := &SaveAccessTokenData{Name: "token",
+		Scopes:  []Scope{{"articles", true, false}},
+		Expires: time.Now().AddDate(0, 0, 30)}
+	_, token, _ := SaveAccessToken(orga, user.ID, data)
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, user.ID)
+	member.Active = false
+
+	if err := model.SaveOrganizationMember(nil, member); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, _, err := ValidateAccessToken(token); err != errs.PermissionDenied {
+		t.Fatalf("Access token of inactive member must not be valid, but was: %v", err)
+	}
+}
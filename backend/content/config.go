@@ -1,30 +1,45 @@
 package content
 
 import (
+	"crypto/rand"
 	"emviwiki/shared/config"
 	"emviwiki/shared/content"
 	"github.com/emvi/logbuch"
+	"time"
+)
+
+const (
+	defaultURLExpiry = 3600 // seconds
+	urlSecretLength  = 32
 )
 
 var (
-	store content.ContentStore
+	store     content.ContentStore
+	scanner   content.Scanner
+	urlSecret []byte
+	urlExpiry time.Duration
+	presign   bool
 )
 
 func LoadConfig() {
-	storeType := config.Get().Storage.Type
-
-	if storeType == "file" {
-		logbuch.Info("Using file store for content")
-		store = content.NewFileStore()
-	} else if storeType == "gcs" {
-		logbuch.Info("Using Google Cloud Store for content")
-		store = content.NewGoogleCloudStore()
-	} else if storeType == "minio" {
-		logbuch.Info("Using MinIO Store for content")
-		store = content.NewMinioStore()
-	} else {
-		logbuch.Info("Using dummy store for content")
-		store = content.NewDummyStore()
+	store = content.SelectStore()
+	scanner = content.SelectScanner()
+	c := config.Get().Storage.URLs
+	urlSecret = []byte(c.Secret)
+	urlExpiry = time.Second * time.Duration(c.Expiry)
+	presign = c.Presign
+
+	if len(urlSecret) == 0 {
+		logbuch.Warn("No secret configured to sign content URLs, using a random secret which is not shared between instances")
+		urlSecret = make([]byte, urlSecretLength)
+
+		if _, err := rand.Read(urlSecret); err != nil {
+			logbuch.Fatal("Error generating secret to sign content URLs", logbuch.Fields{"err": err})
+		}
+	}
+
+	if urlExpiry <= 0 {
+		urlExpiry = time.Second * defaultURLExpiry
 	}
 }
 
@@ -2,11 +2,11 @@ package content
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/shared/content"
 	"emviwiki/shared/model"
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
 	"github.com/jmoiron/sqlx"
-	"path/filepath"
 )
 
 // DeleteFile checks user permissions to delete a file and deletes it if allowed.
@@ -28,7 +28,10 @@ func DeleteFile(orga *model.Organization, userId hide.ID, uniqueName string) err
 		logbuch.Error("Error deleting file", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "path": file.Path})
 	}
 
-	go DeleteFileInStore(orgaId, userId, filepath.Join(file.Path, file.UniqueName))
+	// deduplicated content is removed by garbage collection
+	if !file.Blob.Valid {
+		go DeleteFileInStore(orgaId, userId, file.StorePath())
+	}
 
 	return nil
 }
@@ -42,17 +45,17 @@ func DeleteFileForArticle(tx *sqlx.Tx, orga *model.Organization, userId, article
 
 	go func() {
 		for _, file := range files {
-			DeleteFileInStore(orga.ID, userId, filepath.Join(file.Path, file.UniqueName))
+			DeleteFileInStore(orga.ID, userId, file.StorePath())
 		}
 
 		done <- true
 	}()
 }
 
-// DeleteFileInStore deletes given file in store by path.
-// This is just a fancy wrapper for store.Delete and logs the error should it occur.
+// DeleteFileInStore deletes given file and its image derivatives in store by path.
+// This is just a fancy wrapper for content.DeleteWithDerivatives and logs the error should it occur.
 func DeleteFileInStore(orgaId, userId hide.ID, path string) {
-	if err := store.Delete(path); err != nil {
+	if err := content.DeleteWithDerivatives(store, path); err != nil {
 		logbuch.Error("Error deleting file in store", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "path": path})
 	}
 }
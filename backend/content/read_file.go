@@ -4,33 +4,174 @@ import (
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
 	"io"
-	"path/filepath"
+	"net/url"
 
 	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/content"
 	"emviwiki/shared/model"
 )
 
-// TODO protect files with signed URLs
-func ReadFile(uniqueName string) (*model.File, io.ReadCloser, error) {
+// CheckFileAccess returns the file for given unique name if the content URL is allowed to access it.
+// Organization and user pictures are public. All other files require a valid signature (see SignFileURL)
+// and the user it was signed for must have read access to the article the file belongs to at the time of the request.
+// Deduplicated and copied attachments share the unique name, so access is granted if any of them can be read.
+// Quarantined files are not returned until they have been scanned by the scan_files batch.
+func CheckFileAccess(uniqueName string, query url.Values) (*model.File, error) {
 	file := model.GetFileByUniqueName(uniqueName)
 
 	if file == nil {
 		// only log in debug, because this might happen very frequently
 		logbuch.Debug("File not found in database by unique name", logbuch.Fields{"unique_name": uniqueName})
-		return nil, nil, errs.FileNotFound
+		return nil, errs.FileNotFound
 	}
 
-	reader, err := store.Read(filepath.Join(file.Path, file.UniqueName))
+	if file.ArticleId != 0 || file.RoomId.Valid {
+		orgaId, userId, err := checkFileURLSignature(uniqueName, query)
+
+		if err != nil {
+			return nil, err
+		}
+
+		file, err = findReadableFile(model.FindFileByOrganizationIdAndUniqueName(orgaId, uniqueName), orgaId, userId)
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if file.Quarantined {
+		return nil, errs.FileQuarantined
+	}
+
+	return file, nil
+}
+
+// Returns the first file the user has read access to or the error for the last file checked.
+func findReadableFile(files []model.File, orgaId, userId hide.ID) (*model.File, error) {
+	var err error = errs.FileNotFound
+
+	for i := range files {
+		if err = checkFileReadAccess(&files[i], orgaId, userId); err == nil {
+			return &files[i], nil
+		}
+	}
+
+	return nil, err
+}
+
+// GetPresignedFileURL returns an URL to download the file from the store directly.
+// An empty string is returned if presigned URLs are disabled or not supported by the store.
+func GetPresignedFileURL(file *model.File, derivative string) string {
+	presignStore, ok := store.(content.PresignedURLStore)
+
+	if !presign || !ok {
+		return ""
+	}
+
+	path := file.StorePath()
+	mimeType := file.MimeType
+
+	if content.IsImageDerivative(derivative) && content.IsProcessableImage(file.MimeType) {
+		if _, err := store.Info(content.ImageDerivativePath(path, derivative, file.MimeType)); err == nil {
+			path = content.ImageDerivativePath(path, derivative, file.MimeType)
+			mimeType = content.ImageDerivativeMimeType(file.MimeType)
+		}
+	}
+
+	presignedURL, err := presignStore.PresignedURL(path, file.OriginalName, mimeType, urlExpiry)
 
 	if err != nil {
+		logbuch.Warn("Error creating presigned URL for file", logbuch.Fields{"err": err, "id": file.ID, "path": path})
+		return ""
+	}
+
+	return presignedURL
+}
+
+// ReadFile returns the file and a reader for its content.
+// The derivative is optional and selects a resized version of an image, if available.
+// Quarantined files are not returned until they have been scanned by the scan_files batch.
+// This function does not check permissions, use CheckFileAccess for requests by users.
+func ReadFile(uniqueName, derivative string) (*model.File, io.ReadCloser, error) {
+	file := model.GetFileByUniqueName(uniqueName)
+
+	if file == nil {
 		// only log in debug, because this might happen very frequently
-		logbuch.Debug("Error reading file from store", logbuch.Fields{"err": err})
+		logbuch.Debug("File not found in database by unique name", logbuch.Fields{"unique_name": uniqueName})
 		return nil, nil, errs.FileNotFound
 	}
 
+	reader, _, err := ReadFileContent(file, derivative)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
 	return file, reader, nil
 }
 
+// ReadFileContent returns a reader for the content of given file, like it was returned by CheckFileAccess, and its mime type.
+// The derivative is optional and selects a resized version of an image, if available.
+// The mime type of a derivative might differ from the mime type of the file.
+func ReadFileContent(file *model.File, derivative string) (io.ReadCloser, string, error) {
+	if file.Quarantined {
+		return nil, "", errs.FileQuarantined
+	}
+
+	if content.IsImageDerivative(derivative) && content.IsProcessableImage(file.MimeType) {
+		if reader, err := store.Read(content.ImageDerivativePath(file.StorePath(), derivative, file.MimeType)); err == nil {
+			return reader, content.ImageDerivativeMimeType(file.MimeType), nil
+		}
+	}
+
+	reader, err := store.Read(file.StorePath())
+
+	if err != nil {
+		// only log in debug, because this might happen very frequently
+		logbuch.Debug("Error reading file from store", logbuch.Fields{"err": err})
+		return nil, "", errs.FileNotFound
+	}
+
+	return reader, file.MimeType, nil
+}
+
+// checkFileReadAccess checks the user has read access to the article or room the file belongs to.
+// Clients (user 0) can only read attachments of articles they have access to.
+func checkFileReadAccess(file *model.File, orgaId, userId hide.ID) error {
+	if file.OrganizationId != orgaId {
+		return errs.PermissionDenied
+	}
+
+	if userId != 0 && model.GetOrganizationMemberByOrganizationIdAndUserId(orgaId, userId) == nil {
+		return errs.PermissionDenied
+	}
+
+	if file.ArticleId == 0 {
+		if userId == 0 {
+			return errs.PermissionDenied
+		}
+
+		return nil
+	}
+
+	article := model.GetArticleByOrganizationIdAndIdIgnoreArchived(orgaId, file.ArticleId)
+
+	if article == nil {
+		return errs.FileNotFound
+	}
+
+	if userId == 0 {
+		if !article.ClientAccess || !article.ReadEveryone {
+			return errs.PermissionDenied
+		}
+	} else if !article.ReadEveryone && !perm.CheckUserReadOrWriteAccess(article.ID, userId) {
+		return errs.PermissionDenied
+	}
+
+	return nil
+}
+
 func checkArticleAccess(orgaId, userId, articleId hide.ID, write bool) error {
 	article := model.GetArticleByOrganizationIdAndIdIgnoreArchived(orgaId, articleId)
 
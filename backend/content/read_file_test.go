@@ -32,7 +32,7 @@ func TestReadFile(t *testing.T) {
 
 	for i, in := range input {
 		var err error
-		result, reader, err = ReadFile(in.UniqueName)
+		result, reader, err = ReadFile(in.UniqueName, "")
 
 		if err != expected[i] {
 			t.Fatalf("Expected %v when reading file, but was: %v", expected[i], err)
@@ -0,0 +1,64 @@
+package content
+
+import (
+	"emviwiki/backend/feed"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+const (
+	fileInfectedFeed = "file_infected"
+)
+
+// scanFileInStore scans the file content for given path in store for malware.
+func scanFileInStore(path string) (content.ScanResult, error) {
+	reader, err := store.Read(path)
+
+	if err != nil {
+		return content.ScanResult{}, err
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logbuch.Error("Error closing reader on file scan", logbuch.Fields{"err": err, "path": path})
+		}
+	}()
+
+	return scanner.Scan(reader)
+}
+
+// createFileInfectedFeed notifies the administrators of the organization about a refused upload.
+// Errors are logged only, as the upload is refused anyway.
+func createFileInfectedFeed(orgaId, userId hide.ID, filename, signature string) {
+	if orgaId == 0 {
+		return
+	}
+
+	orga := model.GetOrganizationById(orgaId)
+
+	if orga == nil {
+		return
+	}
+
+	admins := model.FindOrganizationMemberByOrganizationIdAndIsAdmin(orgaId)
+	notify := make([]hide.ID, 0, len(admins))
+
+	for _, admin := range admins {
+		notify = append(notify, admin.UserId)
+	}
+
+	refs := make([]interface{}, 2)
+	refs[0] = feed.KeyValue{"filename", filename}
+	refs[1] = feed.KeyValue{"signature", signature}
+	feedData := &feed.CreateFeedData{Organization: orga,
+		UserId: userId,
+		Reason: fileInfectedFeed,
+		Notify: notify,
+		Refs:   refs}
+
+	if err := feed.CreateFeed(feedData); err != nil {
+		logbuch.Error("Error creating feed for infected file", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+	}
+}
@@ -0,0 +1,73 @@
+package content
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testScanner struct {
+	result content.ScanResult
+	err    error
+}
+
+func (scanner testScanner) Scan(reader io.Reader) (content.ScanResult, error) {
+	return scanner.result, scanner.err
+}
+
+func TestScanUploadedFile(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	defer func() {
+		scanner = content.NoopScanner{}
+	}()
+	input := []content.Scanner{
+		testScanner{},
+		testScanner{err: errors.New("unavailable")},
+		testScanner{result: content.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}},
+	}
+	expectedQuarantined := []bool{false, true, false}
+	expected := []error{nil, nil, errs.FileInfected}
+
+	for i, in := range input {
+		scanner = in
+		file := &File{Organization: orga, UserId: user.ID, Filename: "file.txt"}
+		quarantined, err := scanUploadedFile(file, "dir", "unique.txt")
+
+		if err != expected[i] || quarantined != expectedQuarantined[i] {
+			t.Fatalf("Expected %v and quarantined %v, but was: %v %v", expected[i], expectedQuarantined[i], err, quarantined)
+		}
+	}
+
+	if len(model.FindFeedByOrganizationIdAndReason(orga.ID, fileInfectedFeed)) != 1 {
+		t.Fatal("Feed must have been created for infected file")
+	}
+}
+
+func TestReadFileQuarantined(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	file := testutil.CreateFile(t, orga, user, nil, "")
+	file.Quarantined = true
+
+	if err := model.SaveFile(nil, file); err != nil {
+		t.Fatal(err)
+	}
+
+	// quarantined files are not scanned on read, even if the scanner is available
+	if _, _, err := ReadFile(file.UniqueName, ""); err != errs.FileQuarantined {
+		t.Fatalf("Quarantined file must not be returned, but was: %v", err)
+	}
+
+	if _, err := CheckFileAccess(file.UniqueName, nil); err != errs.FileQuarantined {
+		t.Fatalf("Quarantined file must not be accessible, but was: %v", err)
+	}
+
+	if !model.GetFileByUniqueName(file.UniqueName).Quarantined {
+		t.Fatal("Quarantine must not have been lifted")
+	}
+}
@@ -0,0 +1,130 @@
+package content
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"emviwiki/backend/errs"
+	"encoding/hex"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	urlOrganizationParam = "organization"
+	urlUserParam         = "user"
+	urlExpiresParam      = "expires"
+	urlSignatureParam    = "signature"
+)
+
+// matches content URLs in article content, including an existing (possibly outdated) signature
+var contentURLRegex = regexp.MustCompile(`(/api/v1/content/[^"'\s<>\\?/]+)(\?[^"'\s<>\\]*)?`)
+
+// SignFileURL returns the query parameters to access the file for given organization and user.
+// The user is 0 for clients, which can access attachments of articles they have been granted access to.
+// The expiry time is rounded, so that the URL doesn't change on every request and can be cached by browsers.
+// It stays valid for at least the configured expiry duration.
+func SignFileURL(orgaId, userId hide.ID, uniqueName string) string {
+	expiry := int64(urlExpiry.Seconds())
+	expires := (time.Now().Unix()/expiry + 2) * expiry
+	orga, user := idToString(orgaId), idToString(userId)
+	query := url.Values{}
+	query.Set(urlOrganizationParam, orga)
+	query.Set(urlUserParam, user)
+	query.Set(urlExpiresParam, strconv.FormatInt(expires, 10))
+	query.Set(urlSignatureParam, signFileURL(uniqueName, orga, user, expires))
+	return query.Encode()
+}
+
+// SignContentURLs signs all content URLs found in the article content for given organization and user.
+// Signatures which have been saved with the content before are replaced, the image size is kept.
+func SignContentURLs(content string, orgaId, userId hide.ID) string {
+	return contentURLRegex.ReplaceAllStringFunc(content, func(match string) string {
+		groups := contentURLRegex.FindStringSubmatch(match)
+		path := groups[1]
+		uniqueName := path[strings.LastIndex(path, "/")+1:]
+		signed := path + "?" + SignFileURL(orgaId, userId, uniqueName)
+
+		if query, err := url.ParseQuery(strings.ReplaceAll(strings.TrimPrefix(groups[2], "?"), "&amp;", "&")); err == nil && query.Get("size") != "" {
+			signed += "&size=" + url.QueryEscape(query.Get("size"))
+		}
+
+		return signed
+	})
+}
+
+// UnsignContentURLs removes the signature from all content URLs found in the article content, keeping the image size.
+// Content is saved without signatures, as they expire and are bound to the user who saved it. See SignContentURLs.
+func UnsignContentURLs(content string) string {
+	return contentURLRegex.ReplaceAllStringFunc(content, func(match string) string {
+		groups := contentURLRegex.FindStringSubmatch(match)
+		unsigned := groups[1]
+
+		if query, err := url.ParseQuery(strings.ReplaceAll(strings.TrimPrefix(groups[2], "?"), "&amp;", "&")); err == nil && query.Get("size") != "" {
+			unsigned += "?size=" + url.QueryEscape(query.Get("size"))
+		}
+
+		return unsigned
+	})
+}
+
+// checkFileURLSignature checks the signature of the content URL query and returns the organization and user it was signed for.
+func checkFileURLSignature(uniqueName string, query url.Values) (hide.ID, hide.ID, error) {
+	expires, err := strconv.ParseInt(query.Get(urlExpiresParam), 10, 64)
+
+	if err != nil || time.Now().Unix() > expires {
+		logbuch.Debug("Content URL expired", logbuch.Fields{"unique_name": uniqueName, "expires": query.Get(urlExpiresParam)})
+		return 0, 0, errs.PermissionDenied
+	}
+
+	orga, user := query.Get(urlOrganizationParam), query.Get(urlUserParam)
+	expected := signFileURL(uniqueName, orga, user, expires)
+
+	if !hmac.Equal([]byte(expected), []byte(query.Get(urlSignatureParam))) {
+		logbuch.Debug("Content URL signature invalid", logbuch.Fields{"unique_name": uniqueName})
+		return 0, 0, errs.PermissionDenied
+	}
+
+	orgaId, err := hide.FromString(orga)
+
+	if err != nil {
+		return 0, 0, errs.PermissionDenied
+	}
+
+	var userId hide.ID
+
+	if user != "" {
+		userId, err = hide.FromString(user)
+
+		if err != nil {
+			return 0, 0, errs.PermissionDenied
+		}
+	}
+
+	return orgaId, userId, nil
+}
+
+func signFileURL(uniqueName, orga, user string, expires int64) string {
+	mac := hmac.New(sha256.New, urlSecret)
+	mac.Write([]byte(uniqueName + "\n" + orga + "\n" + user + "\n" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func idToString(id hide.ID) string {
+	if id == 0 {
+		return ""
+	}
+
+	str, err := hide.ToString(id)
+
+	if err != nil {
+		logbuch.Error("Error converting ID to string to sign content URL", logbuch.Fields{"err": err, "id": id})
+		return ""
+	}
+
+	return str
+}
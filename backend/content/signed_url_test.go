@@ -0,0 +1,189 @@
+package content
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/hide"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignFileURL(t *testing.T) {
+	query, err := url.ParseQuery(SignFileURL(42, 21, "file.png"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orgaId, userId, err := checkFileURLSignature("file.png", query)
+
+	if err != nil || orgaId != 42 || userId != 21 {
+		t.Fatalf("Signature must be valid, but was: %v %v %v", orgaId, userId, err)
+	}
+
+	expires, _ := strconv.ParseInt(query.Get(urlExpiresParam), 10, 64)
+
+	if time.Unix(expires, 0).Before(time.Now().Add(urlExpiry)) {
+		t.Fatalf("URL must be valid for at least %v, but expires at: %v", urlExpiry, time.Unix(expires, 0))
+	}
+
+	if _, _, err := checkFileURLSignature("other.png", query); err != errs.PermissionDenied {
+		t.Fatalf("Signature must be invalid for other file, but was: %v", err)
+	}
+
+	tampered, _ := url.ParseQuery(query.Encode())
+	tampered.Set(urlUserParam, idToString(22))
+
+	if _, _, err := checkFileURLSignature("file.png", tampered); err != errs.PermissionDenied {
+		t.Fatalf("Signature must be invalid for other user, but was: %v", err)
+	}
+
+	expired, _ := url.ParseQuery(query.Encode())
+	expired.Set(urlExpiresParam, strconv.FormatInt(time.Now().Add(-time.Minute).Unix(), 10))
+	expired.Set(urlSignatureParam, signFileURL("file.png", expired.Get(urlOrganizationParam), expired.Get(urlUserParam), time.Now().Add(-time.Minute).Unix()))
+
+	if _, _, err := checkFileURLSignature("file.png", expired); err != errs.PermissionDenied {
+		t.Fatalf("Signature must be expired, but was: %v", err)
+	}
+
+	if _, _, err := checkFileURLSignature("file.png", url.Values{}); err != errs.PermissionDenied {
+		t.Fatalf("Signature must be required, but was: %v", err)
+	}
+}
+
+func TestSignFileURLClient(t *testing.T) {
+	query, _ := url.ParseQuery(SignFileURL(42, 0, "file.png"))
+	orgaId, userId, err := checkFileURLSignature("file.png", query)
+
+	if err != nil || orgaId != 42 || userId != 0 {
+		t.Fatalf("Signature must be valid for client, but was: %v %v %v", orgaId, userId, err)
+	}
+}
+
+func TestSignContentURLs(t *testing.T) {
+	content := `{"type":"image","attrs":{"src":"http://localhost:4003/api/v1/content/DoB9mwd3ZV.png"}},` +
+		`{"type":"file","attrs":{"file":"http://localhost:4003/api/v1/content/j06rqfiflKwSRgmtw5li.txt?expires=1&signature=old"}}`
+	signed := SignContentURLs(content, 42, 21)
+
+	if strings.Contains(signed, "signature=old") {
+		t.Fatal("Old signature must have been replaced")
+	}
+
+	for _, uniqueName := range []string{"DoB9mwd3ZV.png", "j06rqfiflKwSRgmtw5li.txt"} {
+		expected := "/api/v1/content/" + uniqueName + "?" + SignFileURL(42, 21, uniqueName) + `"`
+
+		if !strings.Contains(signed, expected) {
+			t.Fatalf("Expected signed URL %v in content, but was: %v", expected, signed)
+		}
+	}
+}
+
+func TestUnsignContentURLs(t *testing.T) {
+	content := `{"type":"image","attrs":{"src":"http://localhost:4003/api/v1/content/DoB9mwd3ZV.png?organization=a&user=b&expires=1&signature=old&size=50"}},` +
+		`{"type":"file","attrs":{"file":"http://localhost:4003/api/v1/content/j06rqfiflKwSRgmtw5li.txt?expires=1&signature=old"}}`
+	expected := `{"type":"image","attrs":{"src":"http://localhost:4003/api/v1/content/DoB9mwd3ZV.png?size=50"}},` +
+		`{"type":"file","attrs":{"file":"http://localhost:4003/api/v1/content/j06rqfiflKwSRgmtw5li.txt"}}`
+
+	if unsigned := UnsignContentURLs(content); unsigned != expected {
+		t.Fatalf("Signatures must have been removed, but was: %v", unsigned)
+	}
+}
+
+func TestCheckFileAccess(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	otherUser := testutil.CreateUser(t, orga, 321, "other@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, false, false)
+	picture := testutil.CreateFile(t, orga, user, nil, "")
+	attachment := testutil.CreateFile(t, orga, user, article, "")
+	roomAttachment := testutil.CreateFile(t, orga, user, nil, "room")
+
+	input := []struct {
+		UniqueName string
+		UserId     hide.ID
+		Signed     bool
+	}{
+		{"not_found", user.ID, true},
+		{picture.UniqueName, 0, false},
+		{attachment.UniqueName, user.ID, false},
+		{attachment.UniqueName, user.ID, true},
+		{attachment.UniqueName, otherUser.ID, true},
+		{attachment.UniqueName, 0, true},
+		{roomAttachment.UniqueName, otherUser.ID, true},
+		{roomAttachment.UniqueName, 0, true},
+	}
+	expected := []error{
+		errs.FileNotFound,
+		nil,
+		errs.PermissionDenied,
+		nil,
+		errs.PermissionDenied,
+		errs.PermissionDenied,
+		nil,
+		errs.PermissionDenied,
+	}
+
+	for i, in := range input {
+		query := url.Values{}
+
+		if in.Signed {
+			query, _ = url.ParseQuery(SignFileURL(orga.ID, in.UserId, in.UniqueName))
+		}
+
+		if _, err := CheckFileAccess(in.UniqueName, query); err != expected[i] {
+			t.Fatalf("Expected %v for input %v, but was: %v", expected[i], i, err)
+		}
+	}
+
+	article.ReadEveryone = true
+	article.ClientAccess = true
+
+	if err := model.SaveArticle(nil, article); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, userId := range []hide.ID{otherUser.ID, 0} {
+		query, _ := url.ParseQuery(SignFileURL(orga.ID, userId, attachment.UniqueName))
+
+		if _, err := CheckFileAccess(attachment.UniqueName, query); err != nil {
+			t.Fatalf("User %v must have access to attachment of public article, but was: %v", userId, err)
+		}
+	}
+}
+
+func TestCheckFileAccessDeduplicated(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	otherUser := testutil.CreateUser(t, orga, 321, "other@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	privateArticle := testutil.CreateArticle(t, orga, user, lang, false, false)
+	publicArticle := testutil.CreateArticle(t, orga, user, lang, true, false)
+	attachment := testutil.CreateFile(t, orga, user, privateArticle, "")
+	copied := *attachment
+	copied.ID = 0
+	copied.ArticleId = publicArticle.ID
+
+	if err := model.SaveFile(nil, &copied); err != nil {
+		t.Fatal(err)
+	}
+
+	query, _ := url.ParseQuery(SignFileURL(orga.ID, otherUser.ID, attachment.UniqueName))
+	file, err := CheckFileAccess(attachment.UniqueName, query)
+
+	if err != nil || file.ArticleId != publicArticle.ID {
+		t.Fatalf("User must have access to the file through the public article, but was: %v %v", err, file)
+	}
+
+	if err := model.DeleteFileById(nil, copied.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CheckFileAccess(attachment.UniqueName, query); err != errs.PermissionDenied {
+		t.Fatalf("User must not have access to the file of the private article, but was: %v", err)
+	}
+}
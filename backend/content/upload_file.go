@@ -21,10 +21,10 @@ import (
 
 const (
 	DefaultMaxFileSize = 52428800 // 50 MB
+	MaxUserPictureSize = 2097152  // 2 MiB
 	filenameLength     = 20
 	maxExtLen          = 10
 	timeDirFormat      = "20060102"
-	gbToBytes          = int64(1073741824)
 	defaultMimeType    = "application/octet-stream"
 )
 
@@ -42,11 +42,19 @@ type File struct {
 	RequiresImage     bool
 }
 
+// UploadFile saves the file in store and database and returns its unique name.
+// The data is read from the multipart request if set, otherwise Data, Filename and ContentTypeHeader must be set.
 func UploadFile(file *File) (string, error) {
-	cancelUpload, err := readMultipart(file)
+	var cancelUpload <-chan bool
 
-	if err != nil {
-		return "", err
+	if file.Request != nil {
+		cancel, err := readMultipart(file)
+
+		if err != nil {
+			return "", err
+		}
+
+		cancelUpload = cancel
 	}
 
 	if file.RequiresImage && !content.IsImage(getMimeType(file.ContentTypeHeader)) {
@@ -64,8 +72,8 @@ func UploadFile(file *File) (string, error) {
 		}
 	}
 
-	// organization and user pictures don't count into storage usage
-	if file.ArticleId != 0 || file.RoomId != "" {
+	// all files of an organization count into storage usage, user pictures don't belong to an organization and are limited in size instead
+	if file.Organization.ID != 0 {
 		if err := checkUploadLimitReached(file.Organization); err != nil {
 			logbuch.Debug("Upload limit reached", logbuch.Fields{"orga_id": file.Organization.ID, "user_id": file.UserId, "article_id": file.ArticleId, "room_id": file.RoomId, "filename": file.Filename})
 			return "", err
@@ -75,16 +83,49 @@ func UploadFile(file *File) (string, error) {
 	dir := getUploadDir(file.Organization, file.Path)
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	uniqueName := generateUniqueFilename() + shortenExt(ext)
-	md5Hash, size, err := saveFileInStore(file, dir, uniqueName, cancelUpload)
 
-	if err != nil {
+	if err := saveFileInStore(file, dir, uniqueName, cancelUpload); err != nil {
 		logbuch.Error("Error saving file in store", logbuch.Fields{"err": err})
 		go cleanupAttachment(dir, uniqueName)
 		return "", err
 	}
 
+	return completeUpload(file, dir, uniqueName)
+}
+
+// completeUpload processes and scans a file which has been saved in store and saves it in the database.
+// The file is removed from store in case of an error.
+func completeUpload(file *File, dir, uniqueName string) (string, error) {
+	md5Hash, size, err := processFileInStore(file, dir, uniqueName)
+
+	if err != nil {
+		logbuch.Error("Error processing file in store", logbuch.Fields{"err": err})
+		go cleanupAttachment(dir, uniqueName)
+		return "", err
+	}
+
+	// the size is not known before the file has been saved
+	if file.Organization.ID != 0 {
+		if err := checkUploadSizeAllowed(file.Organization, 0, size); err != nil {
+			logbuch.Debug("Upload exceeds storage limit", logbuch.Fields{"orga_id": file.Organization.ID, "user_id": file.UserId, "size": size, "filename": file.Filename})
+			go cleanupAttachment(dir, uniqueName)
+			return "", err
+		}
+	} else if size > MaxUserPictureSize {
+		logbuch.Debug("User picture exceeds size limit", logbuch.Fields{"user_id": file.UserId, "size": size, "filename": file.Filename})
+		go cleanupAttachment(dir, uniqueName)
+		return "", errs.FileSizeInvalid
+	}
+
+	quarantined, err := scanUploadedFile(file, dir, uniqueName)
+
+	if err != nil {
+		go cleanupAttachment(dir, uniqueName)
+		return "", err
+	}
+
 	fileUniqueName := uniqueName
-	uniqueName, err = saveFileInDatabase(file, dir, uniqueName, md5Hash, size)
+	uniqueName, err = saveFileInDatabase(file, dir, uniqueName, md5Hash, size, quarantined)
 
 	if err != nil {
 		logbuch.Error("Error saving file in database", logbuch.Fields{"err": err})
@@ -153,14 +194,14 @@ func readMultipart(file *File) (<-chan bool, error) {
 	return cancelUpload, nil
 }
 
-func saveFileInStore(file *File, dir, uniqueName string, cancelUpload <-chan bool) (string, int64, error) {
+func saveFileInStore(file *File, dir, uniqueName string, cancelUpload <-chan bool) error {
 	logbuch.Debug("Saving file to upload in store...", logbuch.Fields{"orga_id": file.Organization.ID, "user_id": file.UserId, "article_id": file.ArticleId, "room_id": file.RoomId, "filename": file.Filename})
 	storeStartTime := time.Now()
 	doneChan := make(chan bool)
 	errChan := make(chan error, 2)
 
 	go func() {
-		if err := store.Save(dir, uniqueName, file.Data); err != nil {
+		if err := content.ForOrganization(store, file.Organization.ID).Save(dir, uniqueName, file.Data); err != nil {
 			logbuch.Error("Error saving file on upload", logbuch.Fields{"orga_id": file.Organization.ID, "user_id": file.UserId, "article_id": file.ArticleId, "room_id": file.RoomId, "filename": file.Filename})
 			errChan <- err
 			return
@@ -172,9 +213,21 @@ func saveFileInStore(file *File, dir, uniqueName string, cancelUpload <-chan boo
 	select {
 	case <-doneChan: // we're good
 	case err := <-errChan:
-		return "", 0, err
+		return err
 	case <-cancelUpload:
-		return "", 0, errs.IO
+		return errs.IO
+	}
+
+	took := time.Now().Sub(storeStartTime)
+	logbuch.Debug("Saved file in store", logbuch.Fields{"took_ms": took.Milliseconds()})
+	return nil
+}
+
+// processFileInStore processes images and returns the MD5 and size of the file in store.
+func processFileInStore(file *File, dir, uniqueName string) (string, int64, error) {
+	// strip metadata and create resized versions of images before the MD5 is calculated
+	if err := content.ProcessImage(content.ForOrganization(store, file.Organization.ID), dir, uniqueName, getMimeType(file.ContentTypeHeader)); err != nil {
+		logbuch.Warn("Error processing uploaded image", logbuch.Fields{"err": err, "orga_id": file.Organization.ID, "user_id": file.UserId, "filename": file.Filename})
 	}
 
 	path := filepath.Join(dir, uniqueName)
@@ -185,12 +238,30 @@ func saveFileInStore(file *File, dir, uniqueName string, cancelUpload <-chan boo
 		return "", 0, err
 	}
 
-	took := time.Now().Sub(storeStartTime)
-	logbuch.Debug("Saved file in store", logbuch.Fields{"size": info.Size, "took_ms": took.Milliseconds()})
 	return info.MD5, info.Size, nil
 }
 
-func saveFileInDatabase(file *File, dir, uniqueName, md5Hash string, size int64) (string, error) {
+// scanUploadedFile scans the uploaded file for malware and returns an error if it is infected.
+// If the file cannot be scanned right now, it is quarantined until it has been scanned successfully.
+func scanUploadedFile(file *File, dir, uniqueName string) (bool, error) {
+	path := filepath.Join(dir, uniqueName)
+	result, err := scanFileInStore(path)
+
+	if err != nil {
+		logbuch.Warn("Error scanning uploaded file, quarantining it", logbuch.Fields{"err": err, "orga_id": file.Organization.ID, "user_id": file.UserId, "path": path})
+		return true, nil
+	}
+
+	if result.Infected {
+		logbuch.Warn("Uploaded file is infected", logbuch.Fields{"orga_id": file.Organization.ID, "user_id": file.UserId, "filename": file.Filename, "signature": result.Signature})
+		createFileInfectedFeed(file.Organization.ID, file.UserId, getFilename(file.Filename), result.Signature)
+		return false, errs.FileInfected
+	}
+
+	return false, nil
+}
+
+func saveFileInDatabase(file *File, dir, uniqueName, md5Hash string, size int64, quarantined bool) (string, error) {
 	tx, err := model.GetConnection().Beginx()
 
 	if err != nil {
@@ -198,7 +269,7 @@ func saveFileInDatabase(file *File, dir, uniqueName, md5Hash string, size int64)
 		return "", errs.TxBegin
 	}
 
-	entity, err := createOrUpdateFile(tx, file.Organization, file.UserId, file.ArticleId, file.LangId, file.RoomId, dir, file.ContentTypeHeader, file.Filename, uniqueName, md5Hash, size)
+	entity, err := createOrUpdateFile(tx, file.Organization, file.UserId, file.ArticleId, file.LangId, file.RoomId, dir, file.ContentTypeHeader, file.Filename, uniqueName, md5Hash, size, quarantined)
 
 	if err != nil {
 		return "", err
@@ -212,7 +283,7 @@ func saveFileInDatabase(file *File, dir, uniqueName, md5Hash string, size int64)
 	return entity.UniqueName, nil
 }
 
-func createOrUpdateFile(tx *sqlx.Tx, organization *model.Organization, userId, articleId, langId hide.ID, roomId, dir, contentTypeHeader, filename, uniqueName, md5Hash string, size int64) (*model.File, error) {
+func createOrUpdateFile(tx *sqlx.Tx, organization *model.Organization, userId, articleId, langId hide.ID, roomId, dir, contentTypeHeader, filename, uniqueName, md5Hash string, size int64, quarantined bool) (*model.File, error) {
 	entity := findExistingFile(tx, organization, articleId, roomId, md5Hash)
 
 	if entity == nil {
@@ -228,7 +299,8 @@ func createOrUpdateFile(tx *sqlx.Tx, organization *model.Organization, userId, a
 			Type:         strings.ToLower(filepath.Ext(filename)),
 			MimeType:     getMimeType(contentTypeHeader),
 			Size:         size,
-			MD5:          md5Hash}
+			MD5:          md5Hash,
+			Quarantined:  quarantined}
 	} else {
 		// create a copy of the existing file and set references
 		entity.ID = 0
@@ -236,6 +308,12 @@ func createOrUpdateFile(tx *sqlx.Tx, organization *model.Organization, userId, a
 		entity.ArticleId = articleId
 		entity.RoomId = null.NewString(roomId, roomId != "")
 		entity.LanguageId = langId
+		entity.Unreferenced = null.Time{}
+
+		// the content has just been scanned successfully
+		if !quarantined {
+			entity.Quarantined = false
+		}
 
 		// delete the file we just uploaded
 		go cleanupAttachment(dir, uniqueName)
@@ -253,19 +331,34 @@ func cleanupAttachment(dir, uniqueName string) {
 	path := filepath.Join(dir, uniqueName)
 	logbuch.Debug("Cleaning up file that failed to be saved in database correctly", logbuch.Fields{"path": path})
 
-	if err := store.Delete(path); err != nil {
+	if err := content.DeleteWithDerivatives(store, path); err != nil {
 		logbuch.Error("Error deleting uploaded file after failed transaction commit", logbuch.Fields{"err": err, "path": path})
 	}
 }
 
 func checkUploadLimitReached(orga *model.Organization) error {
-	if model.GetFileStorageUsageByOrganizationId(orga.ID) > orga.MaxStorageGB*gbToBytes {
+	if getStorageUsage(orga, 0) > util.GetStorageLimit(orga) {
 		return errs.MaxStorageReached
 	}
 
 	return nil
 }
 
+// checkUploadSizeAllowed checks if a file of given size can be added without exceeding the storage limit of the organization.
+// The upload session ID is optional and excludes the size reserved by the session itself.
+func checkUploadSizeAllowed(orga *model.Organization, sessionId hide.ID, size int64) error {
+	if getStorageUsage(orga, sessionId)+size > util.GetStorageLimit(orga) {
+		return errs.MaxStorageReached
+	}
+
+	return nil
+}
+
+// getStorageUsage returns the storage used by files and reserved by open upload sessions, except for given session.
+func getStorageUsage(orga *model.Organization, sessionId hide.ID) int64 {
+	return model.GetFileStorageUsageByOrganizationId(orga.ID) + model.GetUploadSessionSizeByOrganizationIdAndNotId(orga.ID, sessionId)
+}
+
 func findExistingFile(tx *sqlx.Tx, orga *model.Organization, articleId hide.ID, roomId, md5Hash string) *model.File {
 	var entity *model.File
 
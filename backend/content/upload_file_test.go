@@ -1,6 +1,7 @@
 package content
 
 import (
+	"bytes"
 	"emviwiki/backend/errs"
 	"emviwiki/shared/model"
 	"emviwiki/shared/testutil"
@@ -62,6 +63,26 @@ func TestCheckUploadLimitReached(t *testing.T) {
 	}
 }
 
+func TestUploadFileUserPictureSize(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	_, user := testutil.CreateOrgaAndUser(t)
+	file := &File{UserId: user.ID,
+		Data:              bytes.NewReader(make([]byte, MaxUserPictureSize+1)),
+		ContentTypeHeader: "text/plain",
+		Filename:          "picture.txt",
+		Path:              "user"}
+
+	if _, err := UploadFile(file); err != errs.FileSizeInvalid {
+		t.Fatalf("User picture exceeding the size limit must be rejected, but was: %v", err)
+	}
+
+	file.Data = bytes.NewReader(make([]byte, MaxUserPictureSize))
+
+	if _, err := UploadFile(file); err != nil {
+		t.Fatalf("User picture within the size limit must be accepted, but was: %v", err)
+	}
+}
+
 func TestFindExistingFile(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
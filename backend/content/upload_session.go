@@ -0,0 +1,316 @@
+package content
+
+import (
+	"crypto/sha256"
+	"emviwiki/backend/errs"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"encoding/hex"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"hash"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	UploadChunkSize    = 5242880     // 5 MB
+	MaxChunkedFileSize = 10737418240 // 10 GB
+)
+
+var checksumRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// UploadSessionData is the data to start a resumable upload for an article or room.
+// The checksum is the optional SHA-256 (hex) of the whole file.
+type UploadSessionData struct {
+	Organization *model.Organization
+	UserId       hide.ID
+	ArticleId    hide.ID
+	LangId       hide.ID
+	RoomId       string
+	Path         string
+	Filename     string
+	MimeType     string
+	Size         int64
+	Checksum     string
+}
+
+// CreateUploadSession starts a resumable upload. The file is uploaded in chunks of UploadChunkSize
+// (the last chunk might be smaller) and assembled in store when the upload is completed.
+// The declared size is reserved from the storage limit until the session is completed, cancelled or abandoned.
+func CreateUploadSession(data *UploadSessionData) (*model.UploadSession, error) {
+	if data.ArticleId == 0 && data.RoomId == "" {
+		return nil, errs.ArticleNotFound
+	}
+
+	if data.ArticleId != 0 {
+		if err := checkArticleAccess(data.Organization.ID, data.UserId, data.ArticleId, true); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Size <= 0 || data.Size > MaxChunkedFileSize {
+		return nil, errs.FileSizeInvalid
+	}
+
+	data.Checksum = strings.ToLower(strings.TrimSpace(data.Checksum))
+
+	if data.Checksum != "" && !checksumRegex.MatchString(data.Checksum) {
+		return nil, errs.ChecksumInvalid
+	}
+
+	if err := checkUploadSizeAllowed(data.Organization, 0, data.Size); err != nil {
+		return nil, err
+	}
+
+	session := &model.UploadSession{OrganizationId: data.Organization.ID,
+		UserId:     data.UserId,
+		ArticleId:  data.ArticleId,
+		LanguageId: data.LangId,
+		RoomId:     null.NewString(data.RoomId, data.RoomId != ""),
+		Path:       data.Path,
+		Filename:   getFilename(data.Filename),
+		MimeType:   getMimeType(data.MimeType),
+		Size:       data.Size,
+		ChunkSize:  UploadChunkSize,
+		Checksum:   null.NewString(data.Checksum, data.Checksum != "")}
+
+	if err := model.SaveUploadSession(nil, session); err != nil {
+		logbuch.Error("Error saving upload session", logbuch.Fields{"err": err, "orga_id": data.Organization.ID, "user_id": data.UserId})
+		return nil, errs.Saving
+	}
+
+	return session, nil
+}
+
+// ReadUploadSession returns the upload session and all chunks received so far, so that an upload can be resumed.
+func ReadUploadSession(orga *model.Organization, userId, sessionId hide.ID) (*model.UploadSession, []model.UploadChunk, error) {
+	session := model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, userId, sessionId)
+
+	if session == nil {
+		return nil, nil, errs.UploadSessionNotFound
+	}
+
+	return session, model.FindUploadChunkByUploadSessionId(session.ID), nil
+}
+
+// UploadChunk saves a chunk of an upload session in store after verifying its size and checksum (SHA-256, hex).
+// Chunks can be uploaded in any order and be uploaded again, for example if the connection was interrupted.
+func UploadChunk(orga *model.Organization, userId, sessionId hide.ID, number int, checksum string, data io.Reader) error {
+	session := model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, userId, sessionId)
+
+	if session == nil {
+		return errs.UploadSessionNotFound
+	}
+
+	if number < 0 || number >= session.Chunks() {
+		return errs.UploadChunkInvalid
+	}
+
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+
+	if !checksumRegex.MatchString(checksum) {
+		return errs.ChecksumInvalid
+	}
+
+	chunk := model.GetUploadChunkByUploadSessionIdAndNumber(session.ID, number)
+
+	if chunk != nil && chunk.Checksum == checksum {
+		return nil
+	}
+
+	expectedSize := session.ChunkSize
+
+	if number == session.Chunks()-1 {
+		expectedSize = session.Size - int64(number)*session.ChunkSize
+	}
+
+	hasher := sha256.New()
+	counter := &countWriter{hash: hasher}
+	reader := io.TeeReader(io.LimitReader(data, expectedSize+1), counter)
+
+	if err := content.ForOrganization(store, orga.ID).Save(session.ChunkDir(), strconv.Itoa(number), reader); err != nil {
+		logbuch.Error("Error saving upload chunk in store", logbuch.Fields{"err": err, "session_id": session.ID, "number": number})
+		return errs.IO
+	}
+
+	if counter.size != expectedSize {
+		logbuch.Debug("Upload chunk size invalid", logbuch.Fields{"session_id": session.ID, "number": number, "size": counter.size, "expected": expectedSize})
+		deleteUploadChunk(session, number)
+		return errs.UploadChunkInvalid
+	}
+
+	if hex.EncodeToString(hasher.Sum(nil)) != checksum {
+		logbuch.Debug("Upload chunk checksum mismatch", logbuch.Fields{"session_id": session.ID, "number": number})
+		deleteUploadChunk(session, number)
+		return errs.ChecksumMismatch
+	}
+
+	if chunk == nil {
+		chunk = &model.UploadChunk{UploadSessionId: session.ID, Number: number}
+	}
+
+	chunk.Size = counter.size
+	chunk.Checksum = checksum
+
+	if err := model.SaveUploadChunk(nil, chunk); err != nil {
+		logbuch.Error("Error saving upload chunk", logbuch.Fields{"err": err, "session_id": session.ID, "number": number})
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// CompleteUploadSession assembles all chunks into the final file and saves it like a regular upload.
+// The upload limit is checked again without the size reserved by the session, as the organization might have been downgraded in the meantime.
+// The session is deleted afterwards, unless the file couldn't be assembled, so that the client can retry.
+func CompleteUploadSession(orga *model.Organization, userId, sessionId hide.ID) (string, error) {
+	session, chunks, err := ReadUploadSession(orga, userId, sessionId)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(chunks) != session.Chunks() {
+		return "", errs.UploadIncomplete
+	}
+
+	if session.ArticleId != 0 {
+		if err := checkArticleAccess(orga.ID, userId, session.ArticleId, true); err != nil {
+			return "", err
+		}
+	}
+
+	if err := checkUploadSizeAllowed(orga, session.ID, session.Size); err != nil {
+		return "", err
+	}
+
+	file := &File{Organization: orga,
+		UserId:            userId,
+		ArticleId:         session.ArticleId,
+		LangId:            session.LanguageId,
+		RoomId:            session.RoomId.String,
+		ContentTypeHeader: session.MimeType,
+		Filename:          session.Filename,
+		Path:              session.Path}
+	dir := getUploadDir(orga, session.Path)
+	uniqueName := generateUniqueFilename() + shortenExt(strings.ToLower(filepath.Ext(session.Filename)))
+	hasher := sha256.New()
+
+	if err := content.ForOrganization(store, orga.ID).Save(dir, uniqueName, io.TeeReader(&chunkReader{session: session, chunks: chunks}, hasher)); err != nil {
+		logbuch.Error("Error assembling upload chunks in store", logbuch.Fields{"err": err, "session_id": session.ID})
+		go cleanupAttachment(dir, uniqueName)
+		return "", errs.IO
+	}
+
+	if session.Checksum.Valid && hex.EncodeToString(hasher.Sum(nil)) != session.Checksum.String {
+		logbuch.Debug("Upload checksum mismatch", logbuch.Fields{"session_id": session.ID})
+		go cleanupAttachment(dir, uniqueName)
+		return "", errs.ChecksumMismatch
+	}
+
+	uniqueName, err = completeUpload(file, dir, uniqueName)
+	deleteUploadSession(session, chunks)
+	return uniqueName, err
+}
+
+// CancelUploadSession deletes the upload session and all chunks uploaded so far.
+func CancelUploadSession(orga *model.Organization, userId, sessionId hide.ID) error {
+	session, chunks, err := ReadUploadSession(orga, userId, sessionId)
+
+	if err != nil {
+		return err
+	}
+
+	deleteUploadSession(session, chunks)
+	return nil
+}
+
+// DeleteUploadSessionChunks deletes the chunks of given upload sessions in store.
+// This is used when an organization gets deleted, which deletes all upload sessions.
+func DeleteUploadSessionChunks(sessions []model.UploadSession) {
+	for _, session := range sessions {
+		for number := 0; number < session.Chunks(); number++ {
+			if _, err := store.Info(session.ChunkPath(number)); err == nil {
+				deleteUploadChunk(&session, number)
+			}
+		}
+	}
+}
+
+func deleteUploadSession(session *model.UploadSession, chunks []model.UploadChunk) {
+	if err := model.DeleteUploadSessionById(nil, session.ID); err != nil {
+		logbuch.Error("Error deleting upload session", logbuch.Fields{"err": err, "session_id": session.ID})
+	}
+
+	go func() {
+		for _, chunk := range chunks {
+			deleteUploadChunk(session, chunk.Number)
+		}
+	}()
+}
+
+func deleteUploadChunk(session *model.UploadSession, number int) {
+	if err := store.Delete(session.ChunkPath(number)); err != nil {
+		logbuch.Error("Error deleting upload chunk in store", logbuch.Fields{"err": err, "session_id": session.ID, "number": number})
+	}
+}
+
+// countWriter counts the bytes written to the hash.
+type countWriter struct {
+	hash hash.Hash
+	size int64
+}
+
+func (w *countWriter) Write(p []byte) (int, error) {
+	w.size += int64(len(p))
+	return w.hash.Write(p)
+}
+
+// chunkReader reads the chunks of an upload session from store one after another.
+type chunkReader struct {
+	session *model.UploadSession
+	chunks  []model.UploadChunk
+	current io.ReadCloser
+}
+
+func (reader *chunkReader) Read(p []byte) (int, error) {
+	for {
+		if reader.current == nil {
+			if len(reader.chunks) == 0 {
+				return 0, io.EOF
+			}
+
+			current, err := store.Read(reader.session.ChunkPath(reader.chunks[0].Number))
+
+			if err != nil {
+				return 0, err
+			}
+
+			reader.current = current
+			reader.chunks = reader.chunks[1:]
+		}
+
+		n, err := reader.current.Read(p)
+
+		if err == io.EOF {
+			if err := reader.current.Close(); err != nil {
+				logbuch.Error("Error closing upload chunk reader", logbuch.Fields{"err": err, "session_id": reader.session.ID})
+			}
+
+			reader.current = nil
+
+			if n == 0 {
+				continue
+			}
+
+			return n, nil
+		}
+
+		return n, err
+	}
+}
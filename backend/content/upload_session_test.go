@@ -0,0 +1,145 @@
+package content
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"emviwiki/backend/errs"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"emviwiki/shared/util"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUploadSession(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	dir, err := ioutil.TempDir("", "upload")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	defaultStore := store
+	store = &content.FileStore{BasePath: dir}
+	defer func() {
+		store = defaultStore
+	}()
+
+	data := bytes.Repeat([]byte("0123456789"), UploadChunkSize/10+10)
+	chunks := [][]byte{data[:UploadChunkSize], data[UploadChunkSize:]}
+	session, err := CreateUploadSession(&UploadSessionData{Organization: orga,
+		UserId:    user.ID,
+		ArticleId: article.ID,
+		LangId:    lang.ID,
+		Path:      "attachments",
+		Filename:  "log.txt",
+		MimeType:  "text/plain",
+		Size:      int64(len(data)),
+		Checksum:  sha256Hex(data)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if session.Chunks() != 2 {
+		t.Fatalf("Session must require 2 chunks, but was: %v", session.Chunks())
+	}
+
+	if err := UploadChunk(orga, user.ID, session.ID, 0, sha256Hex(chunks[1]), bytes.NewReader(chunks[0])); err != errs.ChecksumMismatch {
+		t.Fatalf("Chunk with wrong checksum must be refused, but was: %v", err)
+	}
+
+	if err := UploadChunk(orga, user.ID, session.ID, 0, sha256Hex(chunks[0][:10]), bytes.NewReader(chunks[0][:10])); err != errs.UploadChunkInvalid {
+		t.Fatalf("Chunk with wrong size must be refused, but was: %v", err)
+	}
+
+	if err := UploadChunk(orga, user.ID, session.ID, 2, sha256Hex(chunks[1]), bytes.NewReader(chunks[1])); err != errs.UploadChunkInvalid {
+		t.Fatalf("Chunk with invalid number must be refused, but was: %v", err)
+	}
+
+	if err := UploadChunk(orga, user.ID, session.ID, 1, sha256Hex(chunks[1]), bytes.NewReader(chunks[1])); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := CompleteUploadSession(orga, user.ID, session.ID); err != errs.UploadIncomplete {
+		t.Fatalf("Incomplete upload must not be completed, but was: %v", err)
+	}
+
+	_, received, err := ReadUploadSession(orga, user.ID, session.ID)
+
+	if err != nil || len(received) != 1 || received[0].Number != 1 {
+		t.Fatalf("Second chunk must have been received, but was: %v %v", received, err)
+	}
+
+	if err := UploadChunk(orga, user.ID, session.ID, 0, sha256Hex(chunks[0]), bytes.NewReader(chunks[0])); err != nil {
+		t.Fatal(err)
+	}
+
+	uniqueName, err := CompleteUploadSession(orga, user.ID, session.ID)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file := model.GetFileByUniqueName(uniqueName)
+
+	if file == nil || file.ArticleId != article.ID || file.Size != int64(len(data)) || file.OriginalName != "log.txt" {
+		t.Fatalf("File must have been saved, but was: %v", file)
+	}
+
+	content, err := ioutil.ReadFile(dir + "/" + file.StorePath())
+
+	if err != nil || !bytes.Equal(content, data) {
+		t.Fatalf("Chunks must have been assembled, but was: %v", err)
+	}
+
+	if _, err := os.Stat(dir + "/" + session.ChunkPath(0)); !os.IsNotExist(err) {
+		t.Fatalf("Chunks must have been deleted, but was: %v", err)
+	}
+
+	if model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, user.ID, session.ID) != nil {
+		t.Fatal("Upload session must have been deleted")
+	}
+}
+
+func TestCreateUploadSessionLimit(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+
+	input := []UploadSessionData{
+		{Organization: orga, UserId: user.ID, Size: 1},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: 0},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: MaxChunkedFileSize + 1},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: 1, Checksum: "invalid"},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: util.GetStorageLimit(orga) + 1},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: util.GetStorageLimit(orga)},
+		{Organization: orga, UserId: user.ID, RoomId: "room", Size: 1}, // storage reserved by open session
+	}
+	expected := []error{
+		errs.ArticleNotFound,
+		errs.FileSizeInvalid,
+		errs.FileSizeInvalid,
+		errs.ChecksumInvalid,
+		errs.MaxStorageReached,
+		nil,
+		errs.MaxStorageReached,
+	}
+
+	for i, in := range input {
+		if _, err := CreateUploadSession(&in); err != expected[i] {
+			t.Fatalf("Expected %v for input %v, but was: %v", expected[i], i, err)
+		}
+	}
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
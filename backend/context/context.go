@@ -13,13 +13,21 @@ type EmviContext struct {
 	UserId        hide.ID
 	Scopes        map[string]client.Scope
 	TrustedClient bool
+	Scoped        bool // set if the user is restricted to scopes, like for personal access tokens and bots of clients
 }
 
 // NewEmviContext returns a new context for given parameters.
 // The scopes are converted to a valid client.Scope map with their name as index
 // and must be in the format "name:rw". Invalid scopes are ignored.
 func NewEmviContext(orga *model.Organization, userId hide.ID, scopes []string, trustedClient bool) EmviContext {
-	return EmviContext{orga, userId, toScopeMap(scopes), trustedClient}
+	return EmviContext{orga, userId, toScopeMap(scopes), trustedClient, false}
+}
+
+// NewEmviScopedContext returns a new context for a user restricted to given scopes,
+// like a user authenticated using a personal access token or the bot of a client.
+// The user can access endpoints requiring the scopes only. Invalid scopes are ignored.
+func NewEmviScopedContext(orga *model.Organization, userId hide.ID, scopes []string) EmviContext {
+	return EmviContext{orga, userId, toScopeMap(scopes), false, true}
 }
 
 func NewEmviUserContext(orga *model.Organization, userId hide.ID) EmviContext {
@@ -37,11 +45,11 @@ func (ctx *EmviContext) IsClient() bool {
 }
 
 // HasScopes checks if the client of this context has given scopes.
-// This checks for exact matches, so read and write permissions on scopes must match.
+// Write access includes read access, so a scope granting read and write access matches a scope requiring read access only.
 // If this context was created by a user request or the client is trusted true is returned.
-// If this context was created by a client and no scopes are passed or it's an entry organization false is returned.
+// If this context was created by a client or is scoped and no scopes are passed or it's an entry organization false is returned.
 func (ctx *EmviContext) HasScopes(scopes ...client.Scope) bool {
-	if ctx.UserId != 0 || ctx.TrustedClient {
+	if (ctx.UserId != 0 && !ctx.Scoped) || ctx.TrustedClient {
 		return true
 	} else if !ctx.Organization.Expert {
 		return false
@@ -52,7 +60,7 @@ func (ctx *EmviContext) HasScopes(scopes ...client.Scope) bool {
 	for _, scope := range scopes {
 		result, ok := ctx.Scopes[scope.Name]
 
-		if ok && result.Read == scope.Read && result.Write == scope.Write {
+		if ok && (result.Read || !scope.Read) && (result.Write || !scope.Write) {
 			found++
 
 			if found == len(scopes) {
@@ -44,3 +44,26 @@ func TestEmviContext_HasScopes(t *testing.T) {
 		t.Fatalf("Client must be trusted")
 	}
 }
+
+func TestEmviContext_HasScopesScoped(t *testing.T) {
+	orga := &model.Organization{Expert: true}
+	ctx := NewEmviScopedContext(orga, 123, []string{"articles:r"})
+
+	if !ctx.HasScopes(client.Scope{"articles", true, false}) {
+		t.Fatalf("Scoped user must have scope")
+	}
+
+	if ctx.HasScopes(client.Scope{"articles", true, true}) {
+		t.Fatalf("Scoped user must not have write scope")
+	}
+
+	if !ctx.IsUser() {
+		t.Fatalf("Scoped user must act as user")
+	}
+
+	ctx = NewEmviScopedContext(orga, 123, []string{"articles:rw"})
+
+	if !ctx.HasScopes(client.Scope{"articles", true, false}) {
+		t.Fatalf("Write scope must include read scope")
+	}
+}
@@ -0,0 +1,25 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+// DeleteCustomField deletes a custom field and its values for all articles.
+func DeleteCustomField(orga *model.Organization, userId, id hide.ID) error {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	if model.GetCustomFieldByOrganizationIdAndId(orga.ID, id) == nil {
+		return errs.CustomFieldNotFound
+	}
+
+	if err := model.DeleteCustomFieldById(nil, id); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestDeleteCustomField(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	member := testutil.CreateUser(t, orga, 321, "member@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	id, err := SaveCustomField(orga, user.ID, &SaveCustomFieldData{Name: "Name", Type: model.CustomFieldText})
+
+	if len(err) != 0 {
+		t.Fatal(err)
+	}
+
+	values, _ := ParseValues(orga.ID, []SaveValueData{{id, "value"}})
+
+	if err := SaveValues(nil, article.ID, values); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := DeleteCustomField(orga, member.ID, id); err != errs.PermissionDenied {
+		t.Fatalf("Expected permission to be denied, but was: %v", err)
+	}
+
+	if err := DeleteCustomField(orga, user.ID, 0); err != errs.CustomFieldNotFound {
+		t.Fatalf("Expected custom field not to be found, but was: %v", err)
+	}
+
+	if err := DeleteCustomField(orga, user.ID, id); err != nil {
+		t.Fatalf("Expected custom field to be deleted, but was: %v", err)
+	}
+
+	if model.GetCustomFieldByOrganizationIdAndId(orga.ID, id) != nil {
+		t.Fatal("Custom field must have been deleted")
+	}
+
+	if len(model.FindArticleCustomFieldByArticleId(article.ID)) != 0 {
+		t.Fatal("Values must have been deleted")
+	}
+}
@@ -0,0 +1,26 @@
+package customfield
+
+import (
+	"emviwiki/shared/model"
+)
+
+// ReadCustomFields returns all custom fields of the organization including their options and required tags.
+func ReadCustomFields(orga *model.Organization) []model.CustomField {
+	fields := model.FindCustomFieldByOrganizationId(orga.ID)
+
+	for i := range fields {
+		loadOptionsAndTags(&fields[i])
+	}
+
+	return fields
+}
+
+func loadOptionsAndTags(field *model.CustomField) {
+	field.Options = make([]string, 0)
+
+	for _, option := range model.FindCustomFieldOptionByCustomFieldId(field.ID) {
+		field.Options = append(field.Options, option.Name)
+	}
+
+	field.RequiredTags = model.FindTagByCustomFieldId(field.ID)
+}
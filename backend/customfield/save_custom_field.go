@@ -0,0 +1,201 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	nameMaxLen      = 40
+	optionMaxLen    = 100
+	maxOptions      = 50
+	maxCustomFields = 50
+)
+
+var types = map[string]bool{
+	model.CustomFieldText:   true,
+	model.CustomFieldNumber: true,
+	model.CustomFieldDate:   true,
+	model.CustomFieldEnum:   true,
+	model.CustomFieldUser:   true,
+}
+
+// SaveCustomFieldData is used to create or update a custom field.
+// Options are required for enum fields and ignored otherwise.
+// The field is required for articles having one of the required tags when they are published.
+type SaveCustomFieldData struct {
+	Id             hide.ID   `json:"id"`
+	Name           string    `json:"name"`
+	Type           string    `json:"type"`
+	Options        []string  `json:"options"`
+	RequiredTagIds []hide.ID `json:"required_tag_ids"`
+}
+
+func (data *SaveCustomFieldData) validate(orgaId hide.ID) []error {
+	data.Name = strings.TrimSpace(data.Name)
+	err := make([]error, 0)
+
+	if len(data.Name) == 0 {
+		err = append(err, errs.NameEmpty)
+	} else if utf8.RuneCountInString(data.Name) > nameMaxLen {
+		err = append(err, errs.NameLen)
+	} else if existing := model.GetCustomFieldByOrganizationIdAndName(orgaId, data.Name); existing != nil && existing.ID != data.Id {
+		err = append(err, errs.CustomFieldExists)
+	}
+
+	if !types[data.Type] {
+		err = append(err, errs.CustomFieldTypeInvalid)
+	}
+
+	if data.Type == model.CustomFieldEnum {
+		data.Options = cleanOptions(data.Options)
+
+		if len(data.Options) == 0 {
+			err = append(err, errs.CustomFieldOptionsEmpty)
+		}
+
+		for _, option := range data.Options {
+			if utf8.RuneCountInString(option) > optionMaxLen {
+				err = append(err, errs.CustomFieldOptionLen)
+				break
+			}
+		}
+	} else {
+		data.Options = nil
+	}
+
+	for _, tagId := range data.RequiredTagIds {
+		if model.GetTagByOrganizationIdAndId(orgaId, tagId) == nil {
+			err = append(err, errs.TagNotFound)
+			break
+		}
+	}
+
+	if len(err) == 0 {
+		return nil
+	}
+
+	return err
+}
+
+// SaveCustomField creates or updates a custom field. Only administrators can manage custom fields.
+// The type cannot be changed once the field was created, as existing values would become invalid.
+func SaveCustomField(orga *model.Organization, userId hide.ID, data *SaveCustomFieldData) (hide.ID, []error) {
+	if !orga.Expert {
+		return 0, []error{errs.RequiresExpertVersion}
+	}
+
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return 0, []error{err}
+	}
+
+	field := new(model.CustomField)
+
+	if data.Id != 0 {
+		field = model.GetCustomFieldByOrganizationIdAndId(orga.ID, data.Id)
+
+		if field == nil {
+			return 0, []error{errs.CustomFieldNotFound}
+		}
+
+		data.Type = field.Type
+	} else if model.CountCustomFieldByOrganizationId(orga.ID) >= maxCustomFields {
+		return 0, []error{errs.MaxCustomFieldsReached}
+	}
+
+	if err := data.validate(orga.ID); err != nil {
+		return 0, err
+	}
+
+	field.OrganizationId = orga.ID
+	field.Name = data.Name
+	field.Type = data.Type
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to save custom field", logbuch.Fields{"err": err})
+		return 0, []error{errs.TxBegin}
+	}
+
+	if err := model.SaveCustomField(tx, field); err != nil {
+		return 0, []error{errs.Saving}
+	}
+
+	if err := saveOptions(tx, field.ID, data.Options); err != nil {
+		return 0, []error{err}
+	}
+
+	if err := saveRequiredTags(tx, field.ID, data.RequiredTagIds); err != nil {
+		return 0, []error{err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction to save custom field", logbuch.Fields{"err": err})
+		return 0, []error{errs.TxCommit}
+	}
+
+	return field.ID, nil
+}
+
+// Trims the options and removes empty and duplicate options, keeping the order.
+func cleanOptions(options []string) []string {
+	clean := make([]string, 0, len(options))
+	added := make(map[string]bool)
+
+	for _, option := range options {
+		option = strings.TrimSpace(option)
+
+		if option != "" && !added[option] {
+			clean = append(clean, option)
+			added[option] = true
+		}
+	}
+
+	if len(clean) > maxOptions {
+		clean = clean[:maxOptions]
+	}
+
+	return clean
+}
+
+func saveOptions(tx *sqlx.Tx, fieldId hide.ID, options []string) error {
+	if err := model.DeleteCustomFieldOptionByCustomFieldId(tx, fieldId); err != nil {
+		return errs.Saving
+	}
+
+	for _, option := range options {
+		if err := model.SaveCustomFieldOption(tx, &model.CustomFieldOption{CustomFieldId: fieldId, Name: option}); err != nil {
+			return errs.Saving
+		}
+	}
+
+	return nil
+}
+
+func saveRequiredTags(tx *sqlx.Tx, fieldId hide.ID, tagIds []hide.ID) error {
+	if err := model.DeleteCustomFieldTagByCustomFieldId(tx, fieldId); err != nil {
+		return errs.Saving
+	}
+
+	saved := make(map[hide.ID]bool)
+
+	for _, tagId := range tagIds {
+		if saved[tagId] {
+			continue
+		}
+
+		if err := model.SaveCustomFieldTag(tx, &model.CustomFieldTag{CustomFieldId: fieldId, TagId: tagId}); err != nil {
+			return errs.Saving
+		}
+
+		saved[tagId] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/hide"
+	"testing"
+)
+
+func TestSaveCustomField(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	member := testutil.CreateUser(t, orga, 321, "member@user.com")
+	tag := testutil.CreateTag(t, orga, "contract")
+	data := &SaveCustomFieldData{Name: " Status ",
+		Type:           model.CustomFieldEnum,
+		Options:        []string{"Open", " ", "Closed", "Open"},
+		RequiredTagIds: []hide.ID{tag.ID}}
+
+	if _, err := SaveCustomField(orga, member.ID, data); len(err) != 1 || err[0] != errs.PermissionDenied {
+		t.Fatalf("Expected permission to be denied, but was: %v", err)
+	}
+
+	id, err := SaveCustomField(orga, user.ID, data)
+
+	if len(err) != 0 {
+		t.Fatalf("Expected custom field to be saved, but was: %v", err)
+	}
+
+	fields := ReadCustomFields(orga)
+
+	if len(fields) != 1 || fields[0].ID != id || fields[0].Name != "Status" || fields[0].Type != model.CustomFieldEnum {
+		t.Fatalf("Custom field not as expected: %v", fields)
+	}
+
+	if len(fields[0].Options) != 2 || fields[0].Options[0] != "Open" || fields[0].Options[1] != "Closed" {
+		t.Fatalf("Options not as expected: %v", fields[0].Options)
+	}
+
+	if len(fields[0].RequiredTags) != 1 || fields[0].RequiredTags[0].ID != tag.ID {
+		t.Fatalf("Required tags not as expected: %v", fields[0].RequiredTags)
+	}
+
+	if _, err := SaveCustomField(orga, user.ID, &SaveCustomFieldData{Name: "status", Type: model.CustomFieldText}); len(err) != 1 || err[0] != errs.CustomFieldExists {
+		t.Fatalf("Expected custom field to exist, but was: %v", err)
+	}
+
+	// the type must not change on update
+	data = &SaveCustomFieldData{Id: id, Name: "State", Type: model.CustomFieldText, Options: []string{"Done"}}
+
+	if _, err := SaveCustomField(orga, user.ID, data); len(err) != 0 {
+		t.Fatalf("Expected custom field to be updated, but was: %v", err)
+	}
+
+	field := model.GetCustomFieldByOrganizationIdAndId(orga.ID, id)
+	options := model.FindCustomFieldOptionByCustomFieldId(id)
+
+	if field.Name != "State" || field.Type != model.CustomFieldEnum || len(options) != 1 || options[0].Name != "Done" {
+		t.Fatalf("Custom field not as expected: %v %v", field, options)
+	}
+
+	if len(model.FindTagByCustomFieldId(id)) != 0 {
+		t.Fatal("Required tags must have been removed")
+	}
+}
+
+func TestSaveCustomFieldInvalid(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	input := []struct {
+		data *SaveCustomFieldData
+		err  error
+	}{
+		{&SaveCustomFieldData{Id: 1, Name: "Name", Type: model.CustomFieldText}, errs.CustomFieldNotFound},
+		{&SaveCustomFieldData{Name: " ", Type: model.CustomFieldText}, errs.NameEmpty},
+		{&SaveCustomFieldData{Name: "This name is way too long for a custom field", Type: model.CustomFieldText}, errs.NameLen},
+		{&SaveCustomFieldData{Name: "Name", Type: "unknown"}, errs.CustomFieldTypeInvalid},
+		{&SaveCustomFieldData{Name: "Name", Type: model.CustomFieldEnum, Options: []string{" "}}, errs.CustomFieldOptionsEmpty},
+		{&SaveCustomFieldData{Name: "Name", Type: model.CustomFieldText, RequiredTagIds: []hide.ID{1}}, errs.TagNotFound},
+	}
+
+	for _, in := range input {
+		if _, err := SaveCustomField(orga, user.ID, in.data); len(err) != 1 || err[0] != in.err {
+			t.Fatalf("Expected error %v, but was: %v", in.err, err)
+		}
+	}
+
+	orga.Expert = false
+
+	if _, err := SaveCustomField(orga, user.ID, &SaveCustomFieldData{Name: "Name", Type: model.CustomFieldText}); len(err) != 1 || err[0] != errs.RequiresExpertVersion {
+		t.Fatalf("Expected expert version to be required, but was: %v", err)
+	}
+}
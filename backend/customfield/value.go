@@ -0,0 +1,190 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/jmoiron/sqlx"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat      = "2006-01-02"
+	textValueMaxLen = 1000
+)
+
+// SaveValueData is the value of a custom field for an article.
+// Numbers are passed as decimals, dates as 2006-01-02 and users by ID. Empty values are removed.
+type SaveValueData struct {
+	CustomFieldId hide.ID `json:"custom_field_id"`
+	Value         string  `json:"value"`
+}
+
+// ParseValues validates the values for the custom fields of the organization and converts them to article custom fields.
+// The article ID is not set.
+func ParseValues(orgaId hide.ID, values []SaveValueData) ([]model.ArticleCustomField, error) {
+	result := make([]model.ArticleCustomField, 0, len(values))
+	added := make(map[hide.ID]bool)
+
+	for _, value := range values {
+		value.Value = strings.TrimSpace(value.Value)
+
+		if value.Value == "" || added[value.CustomFieldId] {
+			continue
+		}
+
+		field := model.GetCustomFieldByOrganizationIdAndId(orgaId, value.CustomFieldId)
+
+		if field == nil {
+			return nil, errs.CustomFieldNotFound
+		}
+
+		articleField, err := parseValue(orgaId, field, value.Value)
+
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, *articleField)
+		added[field.ID] = true
+	}
+
+	return result, nil
+}
+
+func parseValue(orgaId hide.ID, field *model.CustomField, value string) (*model.ArticleCustomField, error) {
+	articleField := &model.ArticleCustomField{CustomFieldId: field.ID, Name: field.Name, Type: field.Type}
+
+	switch field.Type {
+	case model.CustomFieldText:
+		if utf8.RuneCountInString(value) > textValueMaxLen {
+			return nil, errs.CustomFieldValueInvalid
+		}
+
+		articleField.Text.SetValid(value)
+	case model.CustomFieldNumber:
+		number, err := strconv.ParseFloat(value, 64)
+
+		if err != nil {
+			return nil, errs.CustomFieldValueInvalid
+		}
+
+		articleField.Number.SetValid(number)
+	case model.CustomFieldDate:
+		date, err := time.Parse(dateFormat, value)
+
+		if err != nil {
+			return nil, errs.CustomFieldValueInvalid
+		}
+
+		articleField.Date.SetValid(date)
+	case model.CustomFieldEnum:
+		if !isOption(field.ID, value) {
+			return nil, errs.CustomFieldValueInvalid
+		}
+
+		articleField.Text.SetValid(value)
+	case model.CustomFieldUser:
+		userId, err := hide.FromString(value)
+
+		if err != nil || model.GetOrganizationMemberByOrganizationIdAndUserId(orgaId, userId) == nil {
+			return nil, errs.CustomFieldValueInvalid
+		}
+
+		articleField.UserId = userId
+	default:
+		return nil, errs.CustomFieldTypeInvalid
+	}
+
+	return articleField, nil
+}
+
+func isOption(fieldId hide.ID, value string) bool {
+	for _, option := range model.FindCustomFieldOptionByCustomFieldId(fieldId) {
+		if option.Name == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CheckRequired returns an error if a custom field required by one of the tags has no value.
+func CheckRequired(orgaId hide.ID, tags []string, values []model.ArticleCustomField) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	tagNames := make(map[string]bool)
+
+	for _, tag := range tags {
+		tagNames[strings.ToLower(tag)] = true
+	}
+
+	set := make(map[hide.ID]bool)
+
+	for _, value := range values {
+		set[value.CustomFieldId] = true
+	}
+
+	for _, field := range model.FindCustomFieldByOrganizationId(orgaId) {
+		if set[field.ID] {
+			continue
+		}
+
+		for _, tag := range model.FindTagByCustomFieldId(field.ID) {
+			if tagNames[strings.ToLower(tag.Name)] {
+				return errs.CustomFieldRequired
+			}
+		}
+	}
+
+	return nil
+}
+
+// SaveValues replaces the custom field values of the article.
+func SaveValues(tx *sqlx.Tx, articleId hide.ID, values []model.ArticleCustomField) error {
+	if err := model.DeleteArticleCustomFieldByArticleId(tx, articleId); err != nil {
+		return errs.Saving
+	}
+
+	for _, value := range values {
+		value.ID = 0
+		value.ArticleId = articleId
+
+		if err := model.SaveArticleCustomField(tx, &value); err != nil {
+			return errs.Saving
+		}
+	}
+
+	return nil
+}
+
+// FormatValue returns the value of a custom field as text.
+// Users are formatted by name, or username if the name is not set.
+func FormatValue(orgaId hide.ID, value *model.ArticleCustomField) string {
+	switch value.Type {
+	case model.CustomFieldNumber:
+		return strconv.FormatFloat(value.Number.Float64, 'f', -1, 64)
+	case model.CustomFieldDate:
+		return value.Date.Time.Format(dateFormat)
+	case model.CustomFieldUser:
+		user := model.GetUserWithOrganizationMemberByOrganizationIdAndId(orgaId, value.UserId)
+
+		if user == nil {
+			return ""
+		}
+
+		if user.Firstname != "" {
+			return fmt.Sprintf("%s %s", user.Firstname, user.Lastname)
+		}
+
+		return user.OrganizationMember.Username
+	}
+
+	return value.Text.String
+}
@@ -0,0 +1,136 @@
+package customfield
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/hide"
+	"testing"
+)
+
+func TestParseValues(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	text := createCustomField(t, orga, user, "Text", model.CustomFieldText, nil)
+	number := createCustomField(t, orga, user, "Number", model.CustomFieldNumber, nil)
+	date := createCustomField(t, orga, user, "Date", model.CustomFieldDate, nil)
+	enum := createCustomField(t, orga, user, "Enum", model.CustomFieldEnum, []string{"a", "b"})
+	userField := createCustomField(t, orga, user, "User", model.CustomFieldUser, nil)
+	userId, _ := hide.ToString(user.ID)
+	values, err := ParseValues(orga.ID, []SaveValueData{
+		{text, " text "},
+		{number, "4.2"},
+		{date, "2020-02-29"},
+		{enum, "b"},
+		{userField, userId},
+		{text, "duplicate"},
+		{number, " "},
+	})
+
+	if err != nil {
+		t.Fatalf("Expected values to be valid, but was: %v", err)
+	}
+
+	if len(values) != 4 ||
+		values[0].Text.String != "text" ||
+		values[1].Number.Float64 != 4.2 ||
+		values[2].Date.Time.Format(dateFormat) != "2020-02-29" ||
+		values[3].Text.String != "b" {
+		t.Fatalf("Values not as expected: %v", values)
+	}
+
+	if values, err = ParseValues(orga.ID, []SaveValueData{{userField, userId}}); err != nil || len(values) != 1 || values[0].UserId != user.ID {
+		t.Fatalf("User value not as expected: %v %v", err, values)
+	}
+
+	input := []struct {
+		value SaveValueData
+		err   error
+	}{
+		{SaveValueData{0, "value"}, errs.CustomFieldNotFound},
+		{SaveValueData{number, "abc"}, errs.CustomFieldValueInvalid},
+		{SaveValueData{date, "29.02.2020"}, errs.CustomFieldValueInvalid},
+		{SaveValueData{enum, "c"}, errs.CustomFieldValueInvalid},
+		{SaveValueData{userField, "invalid"}, errs.CustomFieldValueInvalid},
+	}
+
+	for _, in := range input {
+		if _, err := ParseValues(orga.ID, []SaveValueData{in.value}); err != in.err {
+			t.Fatalf("Expected error %v for %v, but was: %v", in.err, in.value, err)
+		}
+	}
+}
+
+func TestCheckRequired(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	tag := testutil.CreateTag(t, orga, "Contract")
+	id, err := SaveCustomField(orga, user.ID, &SaveCustomFieldData{Name: "Due", Type: model.CustomFieldDate, RequiredTagIds: []hide.ID{tag.ID}})
+
+	if len(err) != 0 {
+		t.Fatal(err)
+	}
+
+	if err := CheckRequired(orga.ID, []string{"other"}, nil); err != nil {
+		t.Fatalf("Custom field must not be required, but was: %v", err)
+	}
+
+	if err := CheckRequired(orga.ID, []string{"other", "contract"}, nil); err != errs.CustomFieldRequired {
+		t.Fatalf("Custom field must be required, but was: %v", err)
+	}
+
+	values, _ := ParseValues(orga.ID, []SaveValueData{{id, "2020-01-01"}})
+
+	if err := CheckRequired(orga.ID, []string{"contract"}, values); err != nil {
+		t.Fatalf("Custom field must be set, but was: %v", err)
+	}
+}
+
+func TestSaveValues(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	text := createCustomField(t, orga, user, "Text", model.CustomFieldText, nil)
+	number := createCustomField(t, orga, user, "Number", model.CustomFieldNumber, nil)
+	values, _ := ParseValues(orga.ID, []SaveValueData{{text, "text"}, {number, "42"}})
+
+	if err := SaveValues(nil, article.ID, values); err != nil {
+		t.Fatal(err)
+	}
+
+	saved := model.FindArticleCustomFieldByArticleId(article.ID)
+
+	if len(saved) != 2 {
+		t.Fatalf("Values must have been saved, but was: %v", saved)
+	}
+
+	for _, value := range saved {
+		if value.Name == "Number" && FormatValue(orga.ID, &value) != "42" ||
+			value.Name == "Text" && FormatValue(orga.ID, &value) != "text" {
+			t.Fatalf("Value not as expected: %v", value)
+		}
+	}
+
+	values, _ = ParseValues(orga.ID, []SaveValueData{{number, "1.5"}})
+
+	if err := SaveValues(nil, article.ID, values); err != nil {
+		t.Fatal(err)
+	}
+
+	saved = model.FindArticleCustomFieldByArticleId(article.ID)
+
+	if len(saved) != 1 || saved[0].Number.Float64 != 1.5 {
+		t.Fatalf("Values must have been replaced, but was: %v", saved)
+	}
+}
+
+func createCustomField(t *testing.T, orga *model.Organization, user *model.User, name, fieldType string, options []string) hide.ID {
+	id, err := SaveCustomField(orga, user.ID, &SaveCustomFieldData{Name: name, Type: fieldType, Options: options})
+
+	if len(err) != 0 {
+		t.Fatal(err)
+	}
+
+	return id
+}
@@ -35,6 +35,7 @@ var (
 	ReasonNotFound                 = rest.NewApiError("Reason not found", "")
 	RefObjectUnknown               = rest.NewApiError("The referenced object is of unknown type", "")
 	FeedAccessNotFound             = rest.NewApiError("Feed access not found", "")
+	StreamingNotSupported          = rest.NewApiError("Streaming not supported", "")
 	ChangeAdminYourself            = rest.NewApiError("You cannot change admin privileges on yourself", "")
 	EmailInvalid                   = rest.NewApiError("Email invalid", "email")
 	InvitationNotFound             = rest.NewApiError("Invitation not found", "")
@@ -46,6 +47,9 @@ var (
 	PhoneLen                       = rest.NewApiError("Phone too long", "phone")
 	MobileLen                      = rest.NewApiError("Mobile too long", "mobile")
 	NotificationIntervalInvalid    = rest.NewApiError("Notification interval invalid", "")
+	NotificationCategoryInvalid    = rest.NewApiError("Notification category invalid", "notification_preferences")
+	NotificationDeliveryInvalid    = rest.NewApiError("Notification delivery invalid", "notification_preferences")
+	TimezoneInvalid                = rest.NewApiError("Time zone invalid", "timezone")
 	NoObjectToObserve              = rest.NewApiError("No object to observe was set", "")
 	DomainInUse                    = rest.NewApiError("Domain in use already", "domain")
 	DomainNumberFirstChar          = rest.NewApiError("Domain must start with letter", "domain")
@@ -97,6 +101,10 @@ var (
 	NameEmpty                      = rest.NewApiError("Name empty", "name")
 	NameLen                        = rest.NewApiError("Name too long", "name")
 	ScopeInvalid                   = rest.NewApiError("Scope invalid", "")
+	AccessTokenNotFound            = rest.NewApiError("Access token not found", "")
+	AccessTokenExistsAlready       = rest.NewApiError("Access token exists already", "name")
+	ServiceAccountNotFound         = rest.NewApiError("Service account not found", "")
+	ExpiresInvalid                 = rest.NewApiError("Expiry invalid", "expires")
 	ColorModeInvalid               = rest.NewApiError("Color mode invalid", "")
 	NewsletterNotFound             = rest.NewApiError("Newsletter not found", "")
 	SubjectTooLong                 = rest.NewApiError("Subject too long", "subject")
@@ -104,6 +112,54 @@ var (
 	RecommendationsNotFound        = rest.NewApiError("Recommendations not found", "")
 	UnknownArticleFormat           = rest.NewApiError("Unknown article format", "format")
 	UnpublishedArticle             = rest.NewApiError("Unpublished article", "")
+	ReadingCampaignNotFound        = rest.NewApiError("Reading campaign not found", "")
+	ReadingCampaignMemberNotFound  = rest.NewApiError("Reading campaign member not found", "")
+	ReadingCampaignNoMembers       = rest.NewApiError("Reading campaign without members", "")
+	DueDateInvalid                 = rest.NewApiError("Due date invalid", "due_date")
+	TrashNotFound                  = rest.NewApiError("Trash not found", "")
+	FileInfected                   = rest.NewApiError("File infected", "")
+	FileQuarantined                = rest.NewApiError("File quarantined", "")
+	UploadSessionNotFound          = rest.NewApiError("Upload session not found", "")
+	UploadChunkInvalid             = rest.NewApiError("Upload chunk invalid", "")
+	UploadIncomplete               = rest.NewApiError("Upload incomplete", "")
+	FileSizeInvalid                = rest.NewApiError("File size invalid", "size")
+	ChecksumInvalid                = rest.NewApiError("Checksum invalid", "checksum")
+	ChecksumMismatch               = rest.NewApiError("Checksum mismatch", "checksum")
+	ArticleContentVersionOutdated  = rest.NewApiError("Article content version outdated", "version")
+	ArticlePatchEmpty              = rest.NewApiError("Article patch empty", "")
+	ArticleStepsInvalid            = rest.NewApiError("Article steps invalid", "steps")
+	ArticleBlockInvalid            = rest.NewApiError("Article block invalid", "block")
+	SectionNotFound                = rest.NewApiError("Section not found", "section")
+	ContentInvalid                 = rest.NewApiError("Content invalid", "content")
+	ChatIntegrationNotFound        = rest.NewApiError("Chat integration not found", "")
+	ChatIntegrationTypeInvalid     = rest.NewApiError("Chat integration type invalid", "type")
+	ChatIntegrationNoDirectMessage = rest.NewApiError("Chat integration cannot send direct messages", "")
+	WebhookURLInvalid              = rest.NewApiError("Webhook URL invalid", "webhook_url")
+	APIURLInvalid                  = rest.NewApiError("API URL invalid", "api_url")
+	AccessTokenEmpty               = rest.NewApiError("Access token empty", "access_token")
+	RoomIdEmpty                    = rest.NewApiError("Room ID empty", "room_id")
+	ChatAccountNotFound            = rest.NewApiError("Chat account not found", "")
+	ExternalIdEmpty                = rest.NewApiError("Chat account ID empty", "external_id")
+	ChatAccountCodeInvalid         = rest.NewApiError("Chat account confirmation code invalid", "code")
+	SendingChatMessage             = rest.NewApiError("Error sending chat message", "")
+	InboundMailDisabled            = rest.NewApiError("Inbound mail disabled", "")
+	InboundMailAddressNotFound     = rest.NewApiError("Inbound mail address not found", "")
+	InboundMailInvalid             = rest.NewApiError("Inbound mail invalid", "")
+	InboundMailEmpty               = rest.NewApiError("Inbound mail empty", "")
+	TranslatorExists               = rest.NewApiError("Member is a translator already", "user_id")
+	TranslatorNotFound             = rest.NewApiError("Translator not found", "")
+	TranslatorReadOnly             = rest.NewApiError("Read only members cannot be translators", "user_id")
+	TranslationDisabled            = rest.NewApiError("Machine translation disabled", "")
+	TranslationLanguageInvalid     = rest.NewApiError("Source and target language must differ", "language_id")
+	Translating                    = rest.NewApiError("Error translating article", "")
+	CustomFieldNotFound            = rest.NewApiError("Custom field not found", "")
+	CustomFieldExists              = rest.NewApiError("Custom field exists already", "name")
+	CustomFieldTypeInvalid         = rest.NewApiError("Custom field type invalid", "type")
+	CustomFieldOptionsEmpty        = rest.NewApiError("Enum fields require options", "options")
+	CustomFieldOptionLen           = rest.NewApiError("Option too long", "options")
+	MaxCustomFieldsReached         = rest.NewApiError("Maximum number of custom fields reached", "")
+	CustomFieldValueInvalid        = rest.NewApiError("Custom field value invalid", "custom_fields")
+	CustomFieldRequired            = rest.NewApiError("Custom field required", "custom_fields")
 
 	// billing errors
 	BillingIntervalInvalid   = rest.NewApiError("Billing interval invalid", "")
@@ -0,0 +1,13 @@
+package feed
+
+import (
+	"emviwiki/shared/mail"
+)
+
+var (
+	mailProvider mail.Sender
+)
+
+func LoadConfig() {
+	mailProvider = mail.SelectMailSender()
+}
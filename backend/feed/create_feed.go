@@ -2,6 +2,8 @@ package feed
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/backend/integration"
+	"emviwiki/backend/live"
 	"emviwiki/shared/db"
 	"emviwiki/shared/feed"
 	"emviwiki/shared/model"
@@ -10,6 +12,12 @@ import (
 	"github.com/emvi/null"
 	"github.com/jmoiron/sqlx"
 	"strings"
+	"time"
+)
+
+const (
+	feedCommitRetries  = 50
+	feedCommitInterval = time.Millisecond * 100
 )
 
 // RoomID for new articles. This can be used to reference a newly created article without an ID yet.
@@ -35,6 +43,9 @@ type CreateFeedData struct {
 	Access       []hide.ID
 	Notify       []hide.ID
 
+	// NotifyCreator notifies the creating user too, which is used to report the result of background tasks.
+	NotifyCreator bool
+
 	// List of referenced objects or a key value pair, like an article for example.
 	Refs []interface{}
 }
@@ -94,7 +105,17 @@ func CreateFeed(data *CreateFeedData) error {
 		}
 	}
 
-	if err := createAccessAndNotifications(tx, newFeed, data); err != nil {
+	access, err := createAccess(tx, newFeed, data)
+
+	if err != nil {
+		return err
+	}
+
+	if err := live.Publish(tx, live.Event{Type: live.EventFeed,
+		OrganizationId: data.Organization.ID,
+		UserId:         data.UserId,
+		FeedId:         newFeed.ID,
+		Public:         newFeed.Public}); err != nil {
 		return err
 	}
 
@@ -104,11 +125,39 @@ func CreateFeed(data *CreateFeedData) error {
 			logbuch.Error("Error committing transaction to create feed", logbuch.Fields{"err": err})
 			return err
 		}
+
+		sendNotifications(data, newFeed, access)
+	} else {
+		go func() {
+			if waitForFeedCommit(newFeed.ID) {
+				sendNotifications(data, newFeed, access)
+			}
+		}()
 	}
 
 	return nil
 }
 
+// Waits until the feed has been committed by the caller owning the transaction.
+// Returns false if the feed did not become visible in time, which means the transaction was rolled back or is still open.
+func waitForFeedCommit(id hide.ID) bool {
+	for i := 0; i < feedCommitRetries; i++ {
+		if model.CountFeedById(id) != 0 {
+			return true
+		}
+
+		time.Sleep(feedCommitInterval)
+	}
+
+	logbuch.Debug("Feed not committed, skipping notifications", logbuch.Fields{"id": id})
+	return false
+}
+
+func sendNotifications(data *CreateFeedData, feed *model.Feed, access map[hide.ID]model.FeedAccess) {
+	sendNotificationMails(data.Organization, feed, access)
+	integration.PostFeed(data.Organization, feed, data.Notify)
+}
+
 func setRefs(feed *model.Feed, refs []interface{}) error {
 	for _, ref := range refs {
 		switch t := ref.(type) {
@@ -161,9 +210,15 @@ func setRefs(feed *model.Feed, refs []interface{}) error {
 	return nil
 }
 
-func createAccessAndNotifications(tx *sqlx.Tx, feed *model.Feed, data *CreateFeedData) error {
+func createAccess(tx *sqlx.Tx, feed *model.Feed, data *CreateFeedData) (map[hide.ID]model.FeedAccess, error) {
 	access := make(map[hide.ID]model.FeedAccess)
-	access = appendAccess(feed, data.UserId, data.Notify, access, true)
+	notifyExcludedUserId := data.UserId
+
+	if data.NotifyCreator {
+		notifyExcludedUserId = 0
+	}
+
+	access = appendAccess(feed, notifyExcludedUserId, data.Notify, access, true)
 
 	if !data.Public {
 		// grant access if this is not pure notification
@@ -177,11 +232,11 @@ func createAccessAndNotifications(tx *sqlx.Tx, feed *model.Feed, data *CreateFee
 	// TODO optimize (bulk insert)
 	for _, a := range access {
 		if err := model.SaveFeedAccess(tx, &a); err != nil {
-			return err
+			return nil, err
 		}
 	}
 
-	return nil
+	return access, nil
 }
 
 func appendAccess(feed *model.Feed, creatingUserId hide.ID, add []hide.ID, list map[hide.ID]model.FeedAccess, notify bool) map[hide.ID]model.FeedAccess {
@@ -0,0 +1,23 @@
+package feed
+
+import (
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+const (
+	changedArticlesLimit = 10
+)
+
+// GetChangedArticles returns the articles the user has read and which have been changed by someone else since,
+// so that they can be grouped into a single "N articles you read have changed" feed entry.
+// The total number of changed articles is returned in addition to the limited list.
+func GetChangedArticles(organization *model.Organization, userId hide.ID) ([]model.ArticleVisitChange, int) {
+	count := model.CountArticleVisitChangeByOrganizationIdAndUserId(organization.ID, userId)
+
+	if count == 0 {
+		return nil, 0
+	}
+
+	return model.FindArticleVisitChangeByOrganizationIdAndUserIdLimit(organization.ID, userId, changedArticlesLimit), count
+}
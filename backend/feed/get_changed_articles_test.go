@@ -0,0 +1,46 @@
+package feed
+
+import (
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestGetChangedArticles(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "member@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	testutil.CreateArticle(t, orga, user, lang, true, false)
+	createArticleVisit(t, article, user, lang, 1)
+	createArticleVisit(t, article, user2, lang, 1)
+
+	if changes, count := GetChangedArticles(orga, user.ID); len(changes) != 0 || count != 0 {
+		t.Fatalf("Changes made by the user must not be returned, but was: %v %v", len(changes), count)
+	}
+
+	changes, count := GetChangedArticles(orga, user2.ID)
+
+	if len(changes) != 1 || count != 1 {
+		t.Fatalf("One changed article must be returned, but was: %v %v", len(changes), count)
+	}
+
+	if changes[0].ArticleId != article.ID || changes[0].LastVisitedVersion != 1 || changes[0].Version != 2 || changes[0].Title != "title 2" {
+		t.Fatalf("Changed article not as expected: %v", changes[0])
+	}
+
+	createArticleVisit(t, article, user2, lang, 2)
+
+	if changes, count := GetChangedArticles(orga, user2.ID); len(changes) != 0 || count != 0 {
+		t.Fatalf("Article must not be returned after reading the latest version, but was: %v %v", len(changes), count)
+	}
+}
+
+func createArticleVisit(t *testing.T, article *model.Article, user *model.User, lang *model.Language, version int) {
+	visit := &model.ArticleVisit{ArticleId: article.ID, UserId: user.ID, LanguageId: lang.ID, Version: version, Views: 1}
+
+	if err := model.SaveArticleVisit(nil, visit); err != nil {
+		t.Fatal(err)
+	}
+}
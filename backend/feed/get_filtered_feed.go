@@ -40,20 +40,7 @@ func GetFilteredFeed(organization *model.Organization, userId hide.ID, filter *m
 	feed = model.FindFeedByOrganizationIdAndUserIdAndLanguageIdAndFilterLimit(organization.ID, userId, language.ID, filter)
 	feedLangCode := util.DetermineSystemSupportedLangCode(organization.ID, userId)
 
-	for i := range feed {
-		reason, ok := rendering.Reasons[feedLangCode][feed[i].Reason]
-
-		if ok {
-			feed[i].Feed = rendering.RenderFeed(organization, reason.Feed, rendering.FeedText, feedLangCode, &feed[i])
-
-			if feed[i].Notification == "" {
-				feed[i].Notification = feed[i].Feed
-			} else {
-				feed[i].Notification = rendering.RenderFeed(organization, reason.Notification, rendering.NotificationText, feedLangCode, &feed[i])
-			}
-		}
-	}
-
+	rendering.RenderFeedEntries(organization, feedLangCode, feed)
 	return feed, notificationCount
 }
 
@@ -0,0 +1,131 @@
+package feed
+
+import (
+	"bytes"
+	"emviwiki/backend/mailtpl"
+	"emviwiki/shared/feed"
+	"emviwiki/shared/i18n"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"html/template"
+)
+
+const (
+	notificationMailSubject = "mail_notification"
+)
+
+var notificationMailI18n = i18n.Translation{
+	"en": {
+		"title":    "New notification on Emvi",
+		"text-1":   "You have a new notification:",
+		"text-2":   "You can always change the notification settings in the preferences.",
+		"greeting": "You have a new notification!",
+		"goodbye":  "Cheers, Emvi Team",
+	},
+	"de": {
+		"title":    "Neue Benachrichtigung auf Emvi",
+		"text-1":   "Du hast eine neue Benachrichtigung:",
+		"text-2":   "Du kannst die Benachrichtigungseinstellungen jederzeit in den Einstellungen ändern.",
+		"greeting": "Du hast eine neue Benachrichtigung!",
+		"goodbye":  "Dein Emvi Team",
+	},
+}
+
+// reasons sending a dedicated mail, which must not be sent twice
+var dedicatedMailReasons = map[string]bool{
+	"recommend_article":  true,
+	"invite_article":     true,
+	"invite_new_article": true,
+}
+
+// Sends a mail to all notified users who want to receive notifications of the feed category instantly.
+// This must be called after the transaction creating the feed has been committed.
+func sendNotificationMails(orga *model.Organization, newFeed *model.Feed, access map[hide.ID]model.FeedAccess) {
+	if mailProvider == nil || dedicatedMailReasons[newFeed.Reason] {
+		return
+	}
+
+	var userIds []hide.ID
+
+	for _, a := range access {
+		if a.Notification {
+			userIds = append(userIds, a.UserId)
+		}
+	}
+
+	if len(userIds) == 0 {
+		return
+	}
+
+	go func() {
+		mailFeed := *newFeed
+		mailFeed.TriggeredByUser = model.GetUserById(newFeed.TriggeredByUserId)
+
+		if mailFeed.TriggeredByUser == nil {
+			logbuch.Error("User triggering notification not found", logbuch.Fields{"user_id": newFeed.TriggeredByUserId, "feed_id": newFeed.ID})
+			return
+		}
+
+		category := feed.ReasonCategory(newFeed.Reason)
+
+		for _, userId := range userIds {
+			user := model.GetUserById(userId)
+
+			if user == nil || user.Bot {
+				continue
+			}
+
+			member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId)
+
+			if member == nil {
+				continue
+			}
+
+			prefs := model.FindNotificationPreferenceByOrganizationMemberId(member.ID)
+
+			if feed.NotificationDelivery(member, prefs, category) == model.NotificationDeliveryInstant {
+				sendNotificationMail(orga, &mailFeed, user)
+			}
+		}
+	}()
+}
+
+func sendNotificationMail(orga *model.Organization, mailFeed *model.Feed, user *model.User) {
+	langCode := util.DetermineSystemSupportedLangCode(orga.ID, user.ID)
+	reason, ok := feed.Reasons[langCode][mailFeed.Reason]
+
+	if !ok {
+		return
+	}
+
+	if reason.Notification == "" {
+		reason.Notification = reason.Feed
+	}
+
+	tpl := mailtpl.Cache.Get()
+	var buffer bytes.Buffer
+	data := struct {
+		Feed    *model.Feed
+		Text    template.HTML
+		EndVars map[string]template.HTML
+		Vars    map[string]template.HTML
+	}{
+		mailFeed,
+		template.HTML(feed.RenderFeed(orga, reason.Notification, feed.NotificationText, langCode, mailFeed)),
+		i18n.GetMailEndI18n(langCode),
+		i18n.GetVars(langCode, notificationMailI18n),
+	}
+
+	if err := tpl.ExecuteTemplate(&buffer, mailtpl.NotificationMailTemplate, &data); err != nil {
+		logbuch.Error("Error executing notification mail template", logbuch.Fields{"err": err, "feed_id": mailFeed.ID, "user_id": user.ID})
+		return
+	}
+
+	subject := i18n.GetMailTitle(langCode)[notificationMailSubject]
+
+	if err := mailProvider(subject, buffer.String(), user.Email); err != nil {
+		logbuch.Error("Error sending notification mail", logbuch.Fields{"err": err, "feed_id": mailFeed.ID, "user_id": user.ID})
+	}
+}
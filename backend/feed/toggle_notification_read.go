@@ -2,6 +2,7 @@ package feed
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/backend/live"
 	"emviwiki/shared/model"
 	"github.com/emvi/hide"
 	"github.com/jmoiron/sqlx"
@@ -23,11 +24,25 @@ func ToggleNotificationRead(tx *sqlx.Tx, organization *model.Organization, userI
 		if err := model.SaveFeedAccess(tx, access); err != nil {
 			return errs.Saving
 		}
-	} else {
-		// mark all notifications as read
-		if err := model.UpdateFeedAccessNotificationByOrganizationIdAndUserId(tx, organization.ID, userId, true); err != nil {
-			return errs.Saving
-		}
+
+		return publishRead(tx, organization, userId, id, access.Read)
+	}
+
+	// mark all notifications as read
+	if err := model.UpdateFeedAccessNotificationByOrganizationIdAndUserId(tx, organization.ID, userId, true); err != nil {
+		return errs.Saving
+	}
+
+	return publishRead(tx, organization, userId, 0, true)
+}
+
+func publishRead(tx *sqlx.Tx, organization *model.Organization, userId, id hide.ID, read bool) error {
+	if err := live.Publish(tx, live.Event{Type: live.EventRead,
+		OrganizationId: organization.ID,
+		UserId:         userId,
+		FeedId:         id,
+		Read:           read}); err != nil {
+		return errs.Saving
 	}
 
 	return nil
@@ -0,0 +1,122 @@
+package inbound
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"fmt"
+	"github.com/emvi/hide"
+	"strings"
+)
+
+const (
+	tokenLen       = 16
+	tokenSeparator = "."
+)
+
+// CreateAddress creates a new address to send mails to. Mails sent to it create articles in the organization.
+// If a list ID is passed, the articles are added to the article list.
+func CreateAddress(orga *model.Organization, userId, listId hide.ID) (*model.InboundMailAddress, error) {
+	if domain == "" {
+		return nil, errs.InboundMailDisabled
+	}
+
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return nil, err
+	}
+
+	if listId != 0 && model.GetArticleListByOrganizationIdAndId(orga.ID, listId) == nil {
+		return nil, errs.ArticleListNotFound
+	}
+
+	address := &model.InboundMailAddress{OrganizationId: orga.ID,
+		ArticleListId: listId,
+		Token:         generateToken(model.CountInboundMailAddressByToken)}
+
+	if err := model.SaveInboundMailAddress(nil, address); err != nil {
+		return nil, errs.Saving
+	}
+
+	member, err := getMember(orga.ID, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	address.Address = getAddress(address.Token, member.Token)
+	return address, nil
+}
+
+// ReadAddresses returns all inbound mail addresses of the organization.
+// The addresses contain the personal token of the member and only accept mails sent from the address of the member.
+func ReadAddresses(orga *model.Organization, userId hide.ID) ([]model.InboundMailAddress, error) {
+	if model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId) == nil {
+		return nil, errs.MemberNotFound
+	}
+
+	member, err := getMember(orga.ID, userId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := model.FindInboundMailAddressByOrganizationId(orga.ID)
+
+	for i := range addresses {
+		addresses[i].Address = getAddress(addresses[i].Token, member.Token)
+	}
+
+	return addresses, nil
+}
+
+// DeleteAddress deletes the inbound mail address. Mails sent to it afterwards are rejected.
+func DeleteAddress(orga *model.Organization, userId, id hide.ID) error {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	if model.GetInboundMailAddressByOrganizationIdAndId(orga.ID, id) == nil {
+		return errs.InboundMailAddressNotFound
+	}
+
+	if err := model.DeleteInboundMailAddressById(nil, id); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// Returns the personal token of the member, which is created on first use.
+func getMember(orgaId, userId hide.ID) (*model.InboundMailMember, error) {
+	member := model.GetInboundMailMemberByOrganizationIdAndUserId(orgaId, userId)
+
+	if member != nil {
+		return member, nil
+	}
+
+	member = &model.InboundMailMember{OrganizationId: orgaId,
+		UserId: userId,
+		Token:  generateToken(model.CountInboundMailMemberByToken)}
+
+	if err := model.SaveInboundMailMember(nil, member); err != nil {
+		return nil, errs.Saving
+	}
+
+	return member, nil
+}
+
+func generateToken(count func(string) int) string {
+	// the local part of mail addresses is case insensitive for most mail servers
+	token := strings.ToLower(util.GenRandomString(tokenLen))
+
+	for count(token) != 0 {
+		token = strings.ToLower(util.GenRandomString(tokenLen))
+	}
+
+	return token
+}
+
+func getAddress(token, memberToken string) string {
+	return fmt.Sprintf("%s%s%s@%s", token, tokenSeparator, memberToken, domain)
+}
@@ -0,0 +1,69 @@
+package inbound
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"strings"
+	"testing"
+)
+
+func TestCreateReadDeleteAddress(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	domain = "in.emvi.com"
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	list, _ := testutil.CreateArticleList(t, orga, admin, lang, true)
+
+	if _, err := CreateAddress(orga, user.ID, 0); err != errs.PermissionDenied {
+		t.Fatalf("Member must not be allowed to create address, but was: %v", err)
+	}
+
+	if _, err := CreateAddress(orga, admin.ID, 999); err != errs.ArticleListNotFound {
+		t.Fatalf("Article list must be validated, but was: %v", err)
+	}
+
+	address, err := CreateAddress(orga, admin.ID, list.ID)
+
+	if err != nil {
+		t.Fatalf("Address must have been created, but was: %v", err)
+	}
+
+	adminMember := model.GetInboundMailMemberByOrganizationIdAndUserId(orga.ID, admin.ID)
+
+	if len(address.Token) != tokenLen || address.Token != strings.ToLower(address.Token) || adminMember == nil ||
+		address.Address != address.Token+"."+adminMember.Token+"@in.emvi.com" {
+		t.Fatalf("Address not as expected: %v", address)
+	}
+
+	addresses, err := ReadAddresses(orga, user.ID)
+	userMember := model.GetInboundMailMemberByOrganizationIdAndUserId(orga.ID, user.ID)
+
+	if err != nil || len(addresses) != 1 || addresses[0].ArticleListId != list.ID || userMember == nil ||
+		addresses[0].Address != address.Token+"."+userMember.Token+"@in.emvi.com" {
+		t.Fatalf("Personal address must have been returned, but was: %v %v", addresses, err)
+	}
+
+	if addresses, _ := ReadAddresses(orga, user.ID); len(addresses) != 1 || addresses[0].Address != address.Token+"."+userMember.Token+"@in.emvi.com" {
+		t.Fatalf("Member token must have been reused, but was: %v", addresses)
+	}
+
+	if err := DeleteAddress(orga, user.ID, address.ID); err != errs.PermissionDenied {
+		t.Fatalf("Member must not be allowed to delete address, but was: %v", err)
+	}
+
+	if err := DeleteAddress(orga, admin.ID, address.ID); err != nil {
+		t.Fatalf("Address must have been deleted, but was: %v", err)
+	}
+
+	if model.GetInboundMailAddressByToken(address.Token) != nil {
+		t.Fatal("Address must not exist anymore")
+	}
+
+	domain = ""
+
+	if _, err := CreateAddress(orga, admin.ID, 0); err != errs.InboundMailDisabled {
+		t.Fatalf("Address must not be created if inbound mails are disabled, but was: %v", err)
+	}
+}
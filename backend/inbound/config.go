@@ -0,0 +1,25 @@
+package inbound
+
+import (
+	"crypto/subtle"
+	"emviwiki/shared/config"
+	"strings"
+)
+
+var (
+	domain      string
+	secret      string
+	backendHost string
+)
+
+func LoadConfig() {
+	c := config.Get()
+	domain = strings.ToLower(c.Mail.Inbound.Domain)
+	secret = c.Mail.Inbound.Secret
+	backendHost = strings.TrimSuffix(c.Hosts.Backend, "/")
+}
+
+// CheckSecret returns true if inbound mails are enabled and the token matches the configured secret.
+func CheckSecret(token string) bool {
+	return domain != "" && secret != "" && subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1
+}
@@ -0,0 +1,412 @@
+package inbound
+
+import (
+	"emviwiki/backend/prosemirror"
+	"fmt"
+	"golang.org/x/net/html"
+	"reflect"
+	"strings"
+)
+
+var (
+	blockElements = map[string]bool{
+		"address":    true,
+		"article":    true,
+		"center":     true,
+		"dd":         true,
+		"div":        true,
+		"dl":         true,
+		"dt":         true,
+		"figure":     true,
+		"figcaption": true,
+		"footer":     true,
+		"form":       true,
+		"header":     true,
+		"main":       true,
+		"p":          true,
+		"section":    true,
+		"table":      true,
+		"tbody":      true,
+		"td":         true,
+		"tfoot":      true,
+		"th":         true,
+		"thead":      true,
+		"tr":         true,
+	}
+	headlineElements = map[string]int{"h1": 2, "h2": 3, "h3": 4, "h4": 4, "h5": 4, "h6": 4} // the editor supports levels 2 to 4 only
+	ignoredElements  = map[string]bool{"head": true, "script": true, "style": true, "title": true}
+	markElements     = map[string]string{
+		"b":      "bold",
+		"strong": "bold",
+		"i":      "italic",
+		"em":     "italic",
+		"u":      "underlined",
+		"s":      "strikethrough",
+		"strike": "strikethrough",
+		"del":    "strikethrough",
+		"code":   "code",
+		"tt":     "code",
+	}
+	whitespaceReplacer = strings.NewReplacer("\r\n", " ", "\n", " ", "\r", " ", "\t", " ")
+)
+
+// converter converts the text of mails to Prosemirror nodes.
+// Inline images are resolved using the URLs of the uploaded attachments by content ID.
+// External images are dropped, as they are mostly used for tracking.
+type converter struct {
+	images map[string]string // content ID -> URL
+	used   map[string]bool   // content IDs of images referenced in the text
+	blocks []prosemirror.Node
+	inline []prosemirror.Node
+}
+
+func newConverter(images map[string]string) *converter {
+	return &converter{images: images, used: make(map[string]bool)}
+}
+
+// convertHTML returns the blocks for given HTML.
+func (c *converter) convertHTML(src string) ([]prosemirror.Node, error) {
+	doc, err := html.Parse(strings.NewReader(src))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.convertChildren(doc), nil
+}
+
+// convertText returns paragraphs for given plain text. Paragraphs are separated by empty lines.
+func (c *converter) convertText(text string) []prosemirror.Node {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	paragraphs := make([]prosemirror.Node, 0)
+
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.Trim(paragraph, "\n")
+
+		if strings.TrimSpace(paragraph) == "" {
+			continue
+		}
+
+		content := make([]prosemirror.Node, 0)
+
+		for i, line := range strings.Split(paragraph, "\n") {
+			if i > 0 {
+				content = append(content, prosemirror.Node{Type: "hard_break"})
+			}
+
+			if line != "" {
+				content = append(content, prosemirror.Node{Type: "text", Text: line})
+			}
+		}
+
+		paragraphs = append(paragraphs, prosemirror.Node{Type: "paragraph", Content: content})
+	}
+
+	return paragraphs
+}
+
+// Converts the children of the node to blocks, using a new converter so that the state of the parent is kept.
+func (c *converter) convertChildren(node *html.Node) []prosemirror.Node {
+	child := &converter{images: c.images, used: c.used}
+
+	for n := node.FirstChild; n != nil; n = n.NextSibling {
+		child.convertNode(n, nil)
+	}
+
+	child.flushInline()
+	return child.blocks
+}
+
+func (c *converter) convertNode(node *html.Node, marks []prosemirror.Mark) {
+	if node.Type == html.TextNode {
+		text := whitespaceReplacer.Replace(node.Data)
+
+		if text != "" {
+			c.inline = append(c.inline, prosemirror.Node{Type: "text", Text: text, Marks: marks})
+		}
+
+		return
+	}
+
+	if node.Type != html.ElementNode {
+		for n := node.FirstChild; n != nil; n = n.NextSibling {
+			c.convertNode(n, marks)
+		}
+
+		return
+	}
+
+	name := strings.ToLower(node.Data)
+
+	if ignoredElements[name] {
+		return
+	}
+
+	if level, ok := headlineElements[name]; ok {
+		c.addTextBlock("headline", map[string]interface{}{"level": float64(level)}, node)
+	} else if name == "br" {
+		c.inline = append(c.inline, prosemirror.Node{Type: "hard_break"})
+	} else if name == "hr" {
+		c.addBlock(prosemirror.Node{Type: "horizontal_rule"})
+	} else if name == "img" {
+		c.addImage(node)
+	} else if name == "pre" {
+		c.addCodeBlock(node)
+	} else if name == "blockquote" {
+		if content := c.convertChildren(node); len(content) != 0 {
+			c.addBlock(prosemirror.Node{Type: "blockquote", Content: content})
+		}
+	} else if name == "ul" || name == "ol" {
+		c.addList(name, node)
+	} else if blockElements[name] || name == "li" {
+		c.flushInline()
+
+		for n := node.FirstChild; n != nil; n = n.NextSibling {
+			c.convertNode(n, marks)
+		}
+
+		c.flushInline()
+	} else {
+		if mark, ok := markElements[name]; ok {
+			marks = addMark(marks, prosemirror.Mark{Type: mark})
+		} else if href := getAttr(node, "href"); name == "a" && isLink(href) {
+			marks = addMark(marks, prosemirror.Mark{Type: "link", Attrs: map[string]interface{}{"href": href}})
+		}
+
+		for n := node.FirstChild; n != nil; n = n.NextSibling {
+			c.convertNode(n, marks)
+		}
+	}
+}
+
+func (c *converter) addBlock(node prosemirror.Node) {
+	c.flushInline()
+	c.blocks = append(c.blocks, node)
+}
+
+// Adds a headline or paragraph containing the inline content of the node.
+func (c *converter) addTextBlock(nodeType string, attrs map[string]interface{}, node *html.Node) {
+	c.flushInline()
+
+	for n := node.FirstChild; n != nil; n = n.NextSibling {
+		c.convertNode(n, nil)
+	}
+
+	content := trimInline(c.inline)
+	c.inline = nil
+
+	if len(content) != 0 {
+		c.blocks = append(c.blocks, prosemirror.Node{Type: nodeType, Attrs: attrs, Content: content})
+	}
+}
+
+func (c *converter) addImage(node *html.Node) {
+	src := getAttr(node, "src")
+
+	if !strings.HasPrefix(strings.ToLower(src), "cid:") {
+		return
+	}
+
+	contentId := src[len("cid:"):]
+
+	if url, ok := c.images[contentId]; ok {
+		c.addBlock(newImageNode(url))
+		c.used[contentId] = true
+	}
+}
+
+func (c *converter) addCodeBlock(node *html.Node) {
+	var text strings.Builder
+	extractText(node, &text)
+	code := strings.Trim(text.String(), "\r\n")
+	content := make([]prosemirror.Node, 0, 1)
+
+	if code != "" {
+		content = append(content, prosemirror.Node{Type: "text", Text: code})
+	}
+
+	c.addBlock(prosemirror.Node{Type: "code_block", Content: content})
+}
+
+func (c *converter) addList(name string, node *html.Node) {
+	items := make([]prosemirror.Node, 0)
+
+	for n := node.FirstChild; n != nil; n = n.NextSibling {
+		if n.Type != html.ElementNode || strings.ToLower(n.Data) != "li" {
+			continue
+		}
+
+		content := c.convertChildren(n)
+
+		// list items must start with a paragraph
+		if len(content) == 0 || content[0].Type != "paragraph" {
+			content = append([]prosemirror.Node{{Type: "paragraph"}}, content...)
+		}
+
+		items = append(items, prosemirror.Node{Type: "list_item", Content: content})
+	}
+
+	if len(items) == 0 {
+		return
+	}
+
+	if name == "ol" {
+		c.addBlock(prosemirror.Node{Type: "ordered_list", Content: items})
+	} else {
+		c.addBlock(prosemirror.Node{Type: "bullet_list", Content: items})
+	}
+}
+
+// Adds the collected inline content as a paragraph, unless it's empty.
+func (c *converter) flushInline() {
+	content := trimInline(c.inline)
+	c.inline = nil
+
+	if len(content) != 0 {
+		c.blocks = append(c.blocks, prosemirror.Node{Type: "paragraph", Content: content})
+	}
+}
+
+// Collapses whitespace, merges text nodes and removes leading and trailing whitespace and line breaks.
+// Returns nil if there is no text left.
+func trimInline(nodes []prosemirror.Node) []prosemirror.Node {
+	result := make([]prosemirror.Node, 0, len(nodes))
+	lastSpace := true
+	hasText := false
+
+	for _, node := range nodes {
+		if node.Type == "text" {
+			var text strings.Builder
+
+			for _, r := range node.Text {
+				if r == ' ' || r == ' ' && lastSpace {
+					if !lastSpace {
+						text.WriteRune(' ')
+					}
+
+					lastSpace = true
+				} else {
+					text.WriteRune(r)
+					lastSpace = false
+				}
+			}
+
+			node.Text = text.String()
+
+			if node.Text == "" {
+				continue
+			}
+
+			hasText = true
+
+			// merge adjacent text having the same marks
+			if len(result) != 0 && result[len(result)-1].Type == "text" && reflect.DeepEqual(result[len(result)-1].Marks, node.Marks) {
+				result[len(result)-1].Text += node.Text
+				continue
+			}
+		} else {
+			lastSpace = true
+		}
+
+		result = append(result, node)
+	}
+
+	if !hasText {
+		return nil
+	}
+
+	// remove trailing whitespace and line breaks
+	for len(result) > 0 {
+		last := &result[len(result)-1]
+
+		if last.Type == "hard_break" {
+			result = result[:len(result)-1]
+		} else if last.Type == "text" {
+			last.Text = strings.TrimRight(last.Text, " ")
+
+			if last.Text != "" {
+				break
+			}
+
+			result = result[:len(result)-1]
+		} else {
+			break
+		}
+	}
+
+	// remove leading line breaks
+	for len(result) > 0 && result[0].Type == "hard_break" {
+		result = result[1:]
+	}
+
+	return result
+}
+
+func addMark(marks []prosemirror.Mark, mark prosemirror.Mark) []prosemirror.Mark {
+	for _, m := range marks {
+		if m.Type == mark.Type {
+			return marks
+		}
+	}
+
+	// copy, so that siblings are not affected
+	result := make([]prosemirror.Mark, len(marks), len(marks)+1)
+	copy(result, marks)
+	return append(result, mark)
+}
+
+func extractText(node *html.Node, text *strings.Builder) {
+	if node.Type == html.TextNode {
+		text.WriteString(node.Data)
+	} else if node.Type == html.ElementNode && strings.ToLower(node.Data) == "br" {
+		text.WriteString("\n")
+	}
+
+	for n := node.FirstChild; n != nil; n = n.NextSibling {
+		extractText(n, text)
+	}
+}
+
+func getAttr(node *html.Node, key string) string {
+	for _, attr := range node.Attr {
+		if strings.EqualFold(attr.Key, key) {
+			return strings.TrimSpace(attr.Val)
+		}
+	}
+
+	return ""
+}
+
+func isLink(href string) bool {
+	href = strings.ToLower(href)
+	return strings.HasPrefix(href, "http://") || strings.HasPrefix(href, "https://") || strings.HasPrefix(href, "mailto:")
+}
+
+func newImageNode(url string) prosemirror.Node {
+	return prosemirror.Node{Type: "image",
+		Attrs:   map[string]interface{}{"src": url},
+		Content: []prosemirror.Node{{Type: "paragraph"}}}
+}
+
+func newFileNode(url, name string, size int) prosemirror.Node {
+	return prosemirror.Node{Type: "file",
+		Attrs: map[string]interface{}{"file": url, "name": name, "size": formatSize(size)}}
+}
+
+// Formats the file size the same way the editor does.
+func formatSize(size int) string {
+	if size <= 1000 {
+		return fmt.Sprintf("%d bytes", size)
+	}
+
+	units := []string{"bytes", "kB", "MB", "GB"}
+	value := float64(size)
+	i := 0
+
+	for value > 1000 && i < len(units)-1 {
+		value /= 1000
+		i++
+	}
+
+	return fmt.Sprintf("%.2f %s", value, units[i])
+}
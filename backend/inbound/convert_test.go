@@ -0,0 +1,79 @@
+package inbound
+
+import (
+	"emviwiki/backend/article/schema"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/testutil"
+	"encoding/json"
+	"testing"
+)
+
+func TestConvertHTML(t *testing.T) {
+	input := []string{
+		"<html><head><title>Title</title><style>p {}</style></head><body>  Hello\n  <b>World</b>!  </body></html>",
+		"<div>First<br>line</div><div><br></div><p>Second <a href=\"https://emvi.com\"><i>link</i></a> <a href=\"javascript:alert()\">no link</a></p>",
+		"<h1>Headline</h1><ul><li>one</li><li><p>two</p><ol><li>three</li></ol></li></ul>",
+		"<blockquote><p>quote</p></blockquote><pre>code\n  block</pre><hr>",
+		"<p>image <img src=\"cid:image\"> <img src=\"https://tracking.com/pixel.gif\"></p>",
+		"<table><tr><td>cell 1</td><td>cell 2</td></tr></table>",
+	}
+	expected := []string{
+		`[{"type":"paragraph","content":[{"type":"text","text":"Hello "},{"type":"text","marks":[{"type":"bold"}],"text":"World"},{"type":"text","text":"!"}]}]`,
+		`[{"type":"paragraph","content":[{"type":"text","text":"First"},{"type":"hard_break"},{"type":"text","text":"line"}]},{"type":"paragraph","content":[{"type":"text","text":"Second "},{"type":"text","marks":[{"type":"link","attrs":{"href":"https://emvi.com"}},{"type":"italic"}],"text":"link"},{"type":"text","text":" no link"}]}]`,
+		`[{"type":"headline","attrs":{"level":2},"content":[{"type":"text","text":"Headline"}]},{"type":"bullet_list","content":[{"type":"list_item","content":[{"type":"paragraph","content":[{"type":"text","text":"one"}]}]},{"type":"list_item","content":[{"type":"paragraph","content":[{"type":"text","text":"two"}]},{"type":"ordered_list","content":[{"type":"list_item","content":[{"type":"paragraph","content":[{"type":"text","text":"three"}]}]}]}]}]}]`,
+		`[{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"quote"}]}]},{"type":"code_block","content":[{"type":"text","text":"code\n  block"}]},{"type":"horizontal_rule"}]`,
+		`[{"type":"paragraph","content":[{"type":"text","text":"image"}]},{"type":"image","attrs":{"src":"https://api.emvi.com/api/v1/content/image.png"},"content":[{"type":"paragraph"}]}]`,
+		`[{"type":"paragraph","content":[{"type":"text","text":"cell 1"}]},{"type":"paragraph","content":[{"type":"text","text":"cell 2"}]}]`,
+	}
+
+	for i, in := range input {
+		c := newConverter(map[string]string{"image": "https://api.emvi.com/api/v1/content/image.png"})
+		blocks, err := c.convertHTML(in)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, _ := json.Marshal(blocks)
+		testutil.AssertJSONEquals(t, string(out), expected[i])
+		assertValidDoc(t, blocks)
+	}
+}
+
+func TestConvertHTMLUsedImages(t *testing.T) {
+	c := newConverter(map[string]string{"used": "url", "unused": "url"})
+
+	if _, err := c.convertHTML(`<div><p><img src="cid:used"></p></div>`); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.used["used"] || c.used["unused"] {
+		t.Fatalf("Used images not as expected: %v", c.used)
+	}
+}
+
+func TestConvertText(t *testing.T) {
+	blocks := newConverter(nil).convertText("Hello\r\nWorld\r\n\r\n\r\n\r\nSecond paragraph\n")
+	out, _ := json.Marshal(blocks)
+	testutil.AssertJSONEquals(t, string(out), `[{"type":"paragraph","content":[{"type":"text","text":"Hello"},{"type":"hard_break"},{"type":"text","text":"World"}]},{"type":"paragraph","content":[{"type":"text","text":"Second paragraph"}]}]`)
+	assertValidDoc(t, blocks)
+}
+
+func TestFormatSize(t *testing.T) {
+	input := []int{12, 1000, 1500, 49280000}
+	expected := []string{"12 bytes", "1000 bytes", "1.50 kB", "49.28 MB"}
+
+	for i, in := range input {
+		if out := formatSize(in); out != expected[i] {
+			t.Fatalf("Expected '%s', but was: %s", expected[i], out)
+		}
+	}
+}
+
+func assertValidDoc(t *testing.T, blocks []prosemirror.Node) {
+	doc := &prosemirror.Node{Type: "doc", Content: blocks}
+
+	if err := prosemirror.ValidateDoc(schema.HTMLSchema, doc); len(err) != 0 {
+		t.Fatalf("Document must be valid, but was: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+package inbound
+
+import (
+	"emviwiki/backend/content"
+	"emviwiki/shared/config"
+	"emviwiki/shared/testutil"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	testutil.SetTestLogger()
+	os.RemoveAll("bucket")
+	config.Load()
+	LoadConfig()
+	content.LoadConfig()
+	conn := testutil.ConnectBackend(true)
+	defer conn.Disconnect()
+	code := m.Run()
+	testutil.CheckOpenConnectionsNull(conn)
+	os.Exit(code)
+}
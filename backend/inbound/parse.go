@@ -0,0 +1,188 @@
+package inbound
+
+import (
+	"encoding/base64"
+	"errors"
+	"golang.org/x/net/html/charset"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+)
+
+const (
+	maxPartDepth = 10
+)
+
+var (
+	// the original recipient is set by most mail servers, in case the mail was sent as CC, BCC or forwarded
+	recipientHeaders = []string{"Delivered-To", "X-Original-To", "To", "Cc"}
+
+	wordDecoder = &mime.WordDecoder{CharsetReader: charset.NewReaderLabel}
+)
+
+// inboundMail is a parsed mail. The first plain text and HTML parts are used as text,
+// all other parts having a filename or content ID are attachments.
+type inboundMail struct {
+	From        string
+	Recipients  []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []attachment
+}
+
+type attachment struct {
+	Filename    string
+	ContentType string
+	ContentId   string // set for inline images, which are referenced by cid: URLs in HTML
+	Data        []byte
+}
+
+func parseMail(r io.Reader) (*inboundMail, error) {
+	msg, err := mail.ReadMessage(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := msg.Header.AddressList("From")
+
+	if err != nil || len(from) == 0 {
+		return nil, errors.New("sender missing")
+	}
+
+	subject, err := wordDecoder.DecodeHeader(msg.Header.Get("Subject"))
+
+	if err != nil {
+		subject = msg.Header.Get("Subject")
+	}
+
+	result := &inboundMail{From: strings.ToLower(from[0].Address),
+		Recipients: parseRecipients(msg.Header),
+		Subject:    strings.TrimSpace(subject)}
+
+	if err := result.parsePart(textproto.MIMEHeader(msg.Header), msg.Body, 0); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func parseRecipients(header mail.Header) []string {
+	recipients := make([]string, 0)
+
+	for _, key := range recipientHeaders {
+		// invalid headers are ignored, as long as one of them contains a known address
+		list, _ := header.AddressList(key)
+
+		for _, address := range list {
+			recipients = append(recipients, strings.ToLower(address.Address))
+		}
+	}
+
+	return recipients
+}
+
+func (m *inboundMail) parsePart(header textproto.MIMEHeader, body io.Reader, depth int) error {
+	if depth > maxPartDepth {
+		return errors.New("mail parts nested too deep")
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+
+	if err != nil {
+		mediaType = "text/plain"
+		params = map[string]string{}
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+
+		for {
+			part, err := reader.NextPart()
+
+			if err == io.EOF {
+				return nil
+			} else if err != nil {
+				return err
+			}
+
+			if err := m.parsePart(part.Header, part, depth+1); err != nil {
+				return err
+			}
+		}
+	}
+
+	data, err := ioutil.ReadAll(decodeTransferEncoding(header.Get("Content-Transfer-Encoding"), body))
+
+	if err != nil {
+		return err
+	}
+
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	filename := dispositionParams["filename"]
+
+	if filename == "" {
+		filename = params["name"]
+	}
+
+	if decoded, err := wordDecoder.DecodeHeader(filename); err == nil {
+		filename = decoded
+	}
+
+	contentId := strings.Trim(header.Get("Content-ID"), "<> ")
+
+	if disposition != "attachment" && filename == "" && (mediaType == "text/plain" || mediaType == "text/html") {
+		text, err := decodeCharset(params["charset"], data)
+
+		if err != nil {
+			return err
+		}
+
+		if mediaType == "text/plain" && m.Text == "" {
+			m.Text = text
+		} else if mediaType == "text/html" && m.HTML == "" {
+			m.HTML = text
+		}
+	} else if filename != "" || contentId != "" {
+		m.Attachments = append(m.Attachments, attachment{filename, mediaType, contentId, data})
+	}
+
+	return nil
+}
+
+func decodeTransferEncoding(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	default:
+		return body
+	}
+}
+
+func decodeCharset(label string, data []byte) (string, error) {
+	if label == "" || strings.EqualFold(label, "utf-8") || strings.EqualFold(label, "us-ascii") {
+		return string(data), nil
+	}
+
+	reader, err := charset.NewReaderLabel(label, strings.NewReader(string(data)))
+
+	if err != nil {
+		return "", err
+	}
+
+	out, err := ioutil.ReadAll(reader)
+
+	if err != nil {
+		return "", err
+	}
+
+	return string(out), nil
+}
@@ -0,0 +1,92 @@
+package inbound
+
+import (
+	"strings"
+	"testing"
+)
+
+const testMail = "From: =?UTF-8?Q?J=C3=BCrgen?= <Juergen@Test.com>\r\n" +
+	"To: Support <support@test.com>\r\n" +
+	"Cc: Wiki <token@in.emvi.com>\r\n" +
+	"Subject: =?UTF-8?Q?Fwd:_Customer_=C3=A4nswer?=\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/mixed; boundary=\"mixed\"\r\n" +
+	"\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: multipart/related; boundary=\"related\"\r\n" +
+	"\r\n" +
+	"--related\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"alternative\"\r\n" +
+	"\r\n" +
+	"--alternative\r\n" +
+	"Content-Type: text/plain; charset=\"iso-8859-1\"\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"Gr=FC=DFe\r\n" +
+	"--alternative\r\n" +
+	"Content-Type: text/html; charset=\"utf-8\"\r\n" +
+	"\r\n" +
+	"<p>Hello <b>World</b></p><img src=\"cid:logo@test\">\r\n" +
+	"--alternative--\r\n" +
+	"--related\r\n" +
+	"Content-Type: image/png\r\n" +
+	"Content-ID: <logo@test>\r\n" +
+	"Content-Transfer-Encoding: base64\r\n" +
+	"\r\n" +
+	"aW1h\r\n" +
+	"Z2U=\r\n" +
+	"--related--\r\n" +
+	"--mixed\r\n" +
+	"Content-Type: text/plain; name=\"notes.txt\"\r\n" +
+	"Content-Disposition: attachment; filename=\"notes.txt\"\r\n" +
+	"\r\n" +
+	"some notes\r\n" +
+	"--mixed--\r\n"
+
+func TestParseMail(t *testing.T) {
+	mail, err := parseMail(strings.NewReader(testMail))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mail.From != "juergen@test.com" || mail.Subject != "Fwd: Customer änswer" {
+		t.Fatalf("Sender and subject not as expected: %v %v", mail.From, mail.Subject)
+	}
+
+	if len(mail.Recipients) != 2 || mail.Recipients[0] != "support@test.com" || mail.Recipients[1] != "token@in.emvi.com" {
+		t.Fatalf("Recipients not as expected: %v", mail.Recipients)
+	}
+
+	if mail.Text != "Grüße" || mail.HTML != `<p>Hello <b>World</b></p><img src="cid:logo@test">` {
+		t.Fatalf("Text not as expected: %v %v", mail.Text, mail.HTML)
+	}
+
+	if len(mail.Attachments) != 2 {
+		t.Fatalf("Attachments not as expected: %v", mail.Attachments)
+	}
+
+	if mail.Attachments[0].ContentId != "logo@test" || mail.Attachments[0].ContentType != "image/png" || string(mail.Attachments[0].Data) != "image" {
+		t.Fatalf("Inline image not as expected: %v", mail.Attachments[0])
+	}
+
+	if mail.Attachments[1].Filename != "notes.txt" || string(mail.Attachments[1].Data) != "some notes" {
+		t.Fatalf("Attachment not as expected: %v", mail.Attachments[1])
+	}
+}
+
+func TestParseMailPlainText(t *testing.T) {
+	mail, err := parseMail(strings.NewReader("From: user@test.com\r\nDelivered-To: token@in.emvi.com\r\nSubject: Test\r\n\r\nLine 1\r\nLine 2\r\n"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mail.Text != "Line 1\r\nLine 2\r\n" || mail.HTML != "" || len(mail.Attachments) != 0 || len(mail.Recipients) != 1 {
+		t.Fatalf("Mail not as expected: %v", mail)
+	}
+
+	if _, err := parseMail(strings.NewReader("Subject: Test\r\n\r\nNo sender")); err == nil {
+		t.Fatal("Mail without sender must be rejected")
+	}
+}
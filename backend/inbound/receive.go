@@ -0,0 +1,321 @@
+package inbound
+
+import (
+	"bytes"
+	"emviwiki/backend/article"
+	"emviwiki/backend/article/schema"
+	"emviwiki/backend/articlelist"
+	"emviwiki/backend/content"
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"encoding/json"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	attachmentPath   = "organization/attachments"
+	contentEndpoint  = "/api/v1/content/"
+	roomIdLen        = 20
+	maxTitleLen      = 100
+	maxCommitMsgLen  = 100
+	articleSeparator = "+"
+)
+
+var subjectPrefix = regexp.MustCompile(`(?i)^\s*((re|aw|fwd?|wg)\s*:\s*)+`)
+
+// ReceiveMail creates or appends to an article from a raw MIME mail.
+// Mails sent to <token>.<member token>@<domain> create a new WIP article, which is added to the article list of the address if set.
+// Mails sent to <token>.<member token>+<article ID>@<domain> are appended to the article as a new version.
+// The sender must be the member the token belongs to, with write access.
+// As the sender address can be forged, the member token must be kept secret.
+// Mail servers should additionally reject mails failing sender verification (SPF, DKIM, DMARC) before they are passed on.
+func ReceiveMail(body io.Reader) (hide.ID, error) {
+	if domain == "" {
+		return 0, errs.InboundMailDisabled
+	}
+
+	mail, err := parseMail(body)
+
+	if err != nil {
+		logbuch.Debug("Error parsing inbound mail", logbuch.Fields{"err": err})
+		return 0, errs.InboundMailInvalid
+	}
+
+	address, member, articleId := findAddress(mail.Recipients)
+
+	if address == nil {
+		return 0, errs.InboundMailAddressNotFound
+	}
+
+	orga := model.GetOrganizationById(address.OrganizationId)
+
+	if orga == nil {
+		return 0, errs.OrganizationNotFound
+	}
+
+	user := model.GetUserWithOrganizationMemberByOrganizationIdAndEmail(orga.ID, mail.From)
+
+	if user == nil || user.ID != member.UserId || user.OrganizationMember.ReadOnly {
+		logbuch.Debug("Inbound mail sender is not allowed to create articles", logbuch.Fields{"orga_id": orga.ID, "from": mail.From})
+		return 0, errs.PermissionDenied
+	}
+
+	ctx := context.NewEmviUserContext(orga, user.ID)
+
+	if articleId != 0 {
+		return articleId, appendToArticle(ctx, articleId, mail)
+	}
+
+	return createArticle(ctx, address, mail)
+}
+
+// Returns the address, member and article ID for the first recipient matching an inbound mail address.
+// The member token must belong to the organization of the address.
+func findAddress(recipients []string) (*model.InboundMailAddress, *model.InboundMailMember, hide.ID) {
+	for _, recipient := range recipients {
+		at := strings.LastIndex(recipient, "@")
+
+		if at == -1 || !strings.EqualFold(recipient[at+1:], domain) {
+			continue
+		}
+
+		token, articleId := recipient[:at], hide.ID(0)
+
+		if i := strings.Index(token, articleSeparator); i != -1 {
+			id, err := hide.FromString(token[i+1:])
+
+			if err != nil {
+				continue
+			}
+
+			token, articleId = token[:i], id
+		}
+
+		i := strings.Index(token, tokenSeparator)
+
+		if i == -1 {
+			continue
+		}
+
+		address := model.GetInboundMailAddressByToken(token[:i])
+		member := model.GetInboundMailMemberByToken(token[i+1:])
+
+		if address != nil && member != nil && address.OrganizationId == member.OrganizationId {
+			return address, member, articleId
+		}
+	}
+
+	return nil, nil, 0
+}
+
+func createArticle(ctx context.EmviContext, address *model.InboundMailAddress, mail *inboundMail) (hide.ID, error) {
+	roomId := util.GenRandomString(roomIdLen)
+	blocks, err := convertMail(ctx, 0, 0, roomId, mail)
+
+	if err != nil {
+		return 0, err
+	}
+
+	doc, err := json.Marshal(prosemirror.Node{Type: "doc", Content: blocks})
+
+	if err != nil {
+		return 0, errs.InboundMailInvalid
+	}
+
+	id, errList := article.SaveArticle(article.SaveArticleData{Organization: ctx.Organization,
+		UserId:  ctx.UserId,
+		RoomId:  roomId,
+		Wip:     true,
+		Title:   getTitle(mail.Subject),
+		Content: string(doc)})
+
+	if len(errList) != 0 {
+		logbuch.Debug("Error creating article from inbound mail", logbuch.Fields{"err": errList, "orga_id": ctx.Organization.ID, "user_id": ctx.UserId})
+		return 0, errList[0]
+	}
+
+	if address.ArticleListId != 0 {
+		if _, err := articlelist.AddArticleListEntry(ctx.Organization, ctx.UserId, address.ArticleListId, []hide.ID{id}); err != nil {
+			logbuch.Warn("Error adding article created from inbound mail to list", logbuch.Fields{"err": err, "orga_id": ctx.Organization.ID, "user_id": ctx.UserId, "list_id": address.ArticleListId})
+		}
+	}
+
+	return id, nil
+}
+
+func appendToArticle(ctx context.EmviContext, articleId hide.ID, mail *inboundMail) error {
+	langId := util.DetermineLang(nil, ctx.Organization.ID, ctx.UserId, 0).ID
+	lastContent := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, langId, false)
+
+	if lastContent == nil || model.GetArticleByOrganizationIdAndId(ctx.Organization.ID, articleId) == nil {
+		return errs.ArticleNotFound
+	}
+
+	blocks, err := convertMail(ctx, articleId, langId, "", mail)
+
+	if err != nil {
+		return err
+	}
+
+	pos, err := getDocEndPos(lastContent)
+
+	if err != nil {
+		return err
+	}
+
+	// the version makes sure the position is still valid when the steps are applied
+	_, err = article.PatchArticle(ctx, articleId, article.PatchArticleData{LanguageId: langId,
+		Version: lastContent.Version,
+		Steps: []prosemirror.Step{{StepType: prosemirror.StepReplace,
+			From:  pos,
+			To:    pos,
+			Slice: &prosemirror.Slice{Content: blocks}}},
+		CommitMsg: truncate(mail.Subject, maxCommitMsgLen)})
+	return err
+}
+
+func getDocEndPos(content *model.ArticleContent) (int, error) {
+	if err := schema.Migrate(content); err != nil {
+		return 0, errs.Saving
+	}
+
+	doc, err := prosemirror.ParseDoc(content.Content)
+
+	if err != nil {
+		return 0, errs.Saving
+	}
+
+	pos := 0
+
+	for i := range doc.Content {
+		size, err := prosemirror.NodeSize(schema.HTMLSchema, &doc.Content[i])
+
+		if err != nil {
+			return 0, errs.Saving
+		}
+
+		pos += size
+	}
+
+	return pos, nil
+}
+
+// Uploads the attachments and converts the text to blocks. Attachments not referenced in the text are added at the end.
+func convertMail(ctx context.EmviContext, articleId, langId hide.ID, roomId string, mail *inboundMail) ([]prosemirror.Node, error) {
+	urls, err := uploadAttachments(ctx, articleId, langId, roomId, mail.Attachments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	images := make(map[string]string)
+
+	for i, a := range mail.Attachments {
+		if a.ContentId != "" {
+			images[a.ContentId] = urls[i]
+		}
+	}
+
+	c := newConverter(images)
+	var blocks []prosemirror.Node
+
+	if strings.TrimSpace(mail.HTML) != "" {
+		blocks, err = c.convertHTML(mail.HTML)
+
+		if err != nil {
+			logbuch.Debug("Error converting HTML of inbound mail", logbuch.Fields{"err": err})
+			return nil, errs.InboundMailInvalid
+		}
+	} else {
+		blocks = c.convertText(mail.Text)
+	}
+
+	files := make([]prosemirror.Node, 0)
+
+	for i, a := range mail.Attachments {
+		if a.ContentId != "" && c.used[a.ContentId] {
+			continue
+		}
+
+		if strings.HasPrefix(a.ContentType, "image/") {
+			blocks = append(blocks, newImageNode(urls[i]))
+		} else {
+			files = append(files, newFileNode(urls[i], getFilename(a), len(a.Data)))
+		}
+	}
+
+	if len(files) != 0 {
+		blocks = append(blocks, prosemirror.Node{Type: "paragraph", Content: files})
+	}
+
+	if len(blocks) == 0 {
+		return nil, errs.InboundMailEmpty
+	}
+
+	return blocks, nil
+}
+
+func uploadAttachments(ctx context.EmviContext, articleId, langId hide.ID, roomId string, attachments []attachment) ([]string, error) {
+	urls := make([]string, 0, len(attachments))
+
+	for _, a := range attachments {
+		uniqueName, err := content.UploadFile(&content.File{Organization: ctx.Organization,
+			UserId:            ctx.UserId,
+			ArticleId:         articleId,
+			LangId:            langId,
+			RoomId:            roomId,
+			Data:              bytes.NewReader(a.Data),
+			ContentTypeHeader: a.ContentType,
+			Filename:          getFilename(a),
+			Path:              attachmentPath})
+
+		if err == errs.PermissionDenied || err == errs.ArticleNotFound || err == errs.MaxStorageReached || err == errs.FileInfected {
+			return nil, err
+		} else if err != nil {
+			logbuch.Error("Error uploading attachment of inbound mail", logbuch.Fields{"err": err, "orga_id": ctx.Organization.ID, "user_id": ctx.UserId, "article_id": articleId})
+			return nil, errs.UploadingFile
+		}
+
+		urls = append(urls, backendHost+contentEndpoint+uniqueName)
+	}
+
+	return urls, nil
+}
+
+func getFilename(a attachment) string {
+	if a.Filename != "" {
+		return a.Filename
+	}
+
+	// inline images might not have a name
+	return fmt.Sprintf("%s.%s", a.ContentId, strings.TrimPrefix(a.ContentType, "image/"))
+}
+
+func getTitle(subject string) string {
+	title := strings.TrimSpace(subjectPrefix.ReplaceAllString(subject, ""))
+
+	if title == "" {
+		title = subject
+	}
+
+	return truncate(title, maxTitleLen)
+}
+
+// Truncates the string to max bytes without splitting runes.
+func truncate(str string, max int) string {
+	for len(str) > max {
+		_, size := utf8.DecodeLastRuneInString(str)
+		str = str[:len(str)-size]
+	}
+
+	return str
+}
@@ -0,0 +1,131 @@
+package inbound
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"fmt"
+	"github.com/emvi/hide"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	sampleArticleContent = `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Existing"}]}]}`
+)
+
+func TestReceiveMailCreateArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	domain = "in.emvi.com"
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	list, _ := testutil.CreateArticleList(t, orga, admin, lang, true)
+	address, _ := CreateAddress(orga, admin.ID, list.ID)
+	mail := strings.Replace(testMail, "token@in.emvi.com", address.Address, 1)
+	mail = strings.Replace(mail, "Juergen@Test.com", admin.Email, 1)
+
+	if _, err := ReceiveMail(strings.NewReader(strings.Replace(mail, address.Address, "unknown@in.emvi.com", 1))); err != errs.InboundMailAddressNotFound {
+		t.Fatalf("Mail to unknown address must be rejected, but was: %v", err)
+	}
+
+	if _, err := ReceiveMail(strings.NewReader(strings.Replace(mail, address.Address, address.Token+"@in.emvi.com", 1))); err != errs.InboundMailAddressNotFound {
+		t.Fatalf("Mail to address without member token must be rejected, but was: %v", err)
+	}
+
+	if _, err := ReceiveMail(strings.NewReader(strings.Replace(mail, admin.Email, "unknown@test.com", 1))); err != errs.PermissionDenied {
+		t.Fatalf("Mail from unknown sender must be rejected, but was: %v", err)
+	}
+
+	// the sender address can be forged, so it must match the member the address belongs to
+	if _, err := ReceiveMail(strings.NewReader(strings.Replace(mail, admin.Email, user.Email, 1))); err != errs.PermissionDenied {
+		t.Fatalf("Mail from other member must be rejected, but was: %v", err)
+	}
+
+	id, err := ReceiveMail(strings.NewReader(mail))
+
+	if err != nil {
+		t.Fatalf("Article must have been created, but was: %v", err)
+	}
+
+	article := model.GetArticleByOrganizationIdAndId(orga.ID, id)
+
+	if article == nil || article.WIP == -1 {
+		t.Fatalf("WIP article must have been created, but was: %v", article)
+	}
+
+	content := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(id, lang.ID, true)
+
+	if content == nil || content.Title != "Customer änswer" {
+		t.Fatalf("Content must have been created, but was: %v", content)
+	}
+
+	doc, _ := prosemirror.ParseDoc(content.Content)
+
+	if images := prosemirror.FindNodes(doc, -1, "image"); len(images) != 1 {
+		t.Fatalf("Inline image must have been added, but was: %v", images)
+	}
+
+	if files := prosemirror.FindNodes(doc, -1, "file"); len(files) != 1 || files[0].Attrs["name"] != "notes.txt" {
+		t.Fatalf("Attachment must have been added, but was: %v", files)
+	}
+
+	if files := model.FindFileByOrganizationIdAndArticleIdAndLanguageIdAndDefTimeAfter(orga.ID, id, lang.ID, time.Time{}); len(files) != 2 {
+		t.Fatalf("Attachments must belong to article, but was: %v", len(files))
+	}
+
+	if model.GetArticleListEntryByArticleListIdAndArticleId(list.ID, id) == nil {
+		t.Fatal("Article must have been added to list")
+	}
+}
+
+func TestReceiveMailAppendToArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	domain = "in.emvi.com"
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	article := testutil.CreateArticle(t, orga, admin, lang, true, false)
+	setArticleContent(t, article.ID, lang.ID)
+	address, _ := CreateAddress(orga, admin.ID, 0)
+	userAddresses, _ := ReadAddresses(orga, user.ID)
+	articleId, _ := hide.ToString(article.ID)
+	mail := "From: %s\r\nTo: %s+%s@in.emvi.com\r\nSubject: Answer\r\n\r\nCustomer answer\r\n"
+
+	if _, err := ReceiveMail(strings.NewReader(fmt.Sprintf(mail, user.Email, getLocalPart(userAddresses[0].Address), articleId))); err != errs.PermissionDenied {
+		t.Fatalf("Mail from member without write access must be rejected, but was: %v", err)
+	}
+
+	id, err := ReceiveMail(strings.NewReader(fmt.Sprintf(mail, admin.Email, getLocalPart(address.Address), articleId)))
+
+	if err != nil || id != article.ID {
+		t.Fatalf("Mail must have been appended to article, but was: %v %v", id, err)
+	}
+
+	content := model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(article.ID, lang.ID, false)
+
+	if content.Version != 3 || content.Commit.String != "Answer" {
+		t.Fatalf("New version must have been created, but was: %v %v", content.Version, content.Commit.String)
+	}
+
+	testutil.AssertJSONEquals(t, content.Content, `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"Existing"}]},{"type":"paragraph","content":[{"type":"text","text":"Customer answer"}]}]}`)
+}
+
+func getLocalPart(address string) string {
+	return address[:strings.Index(address, "@")]
+}
+
+func setArticleContent(t *testing.T, articleId, langId hide.ID) {
+	for _, content := range []*model.ArticleContent{
+		model.GetArticleContentLastByArticleIdAndLanguageIdAndWIP(articleId, langId, false),
+		model.GetArticleContentLatestByArticleIdAndLanguageId(articleId, langId, false),
+	} {
+		content.Content = sampleArticleContent
+
+		if err := model.SaveArticleContent(nil, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/i18n"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"html"
+	"strings"
+)
+
+const (
+	confirmationCodeLen = 8
+)
+
+var linkAccountI18n = i18n.Translation{
+	"en": {
+		"text": "Enter this code in Emvi to link your chat account:",
+	},
+	"de": {
+		"text": "Gib diesen Code in Emvi ein, um deinen Chat-Account zu verknüpfen:",
+	},
+}
+
+// LinkAccount links the Slack member ID or Matrix user ID to the member for given integration.
+// A confirmation code is sent to the chat account, which must be confirmed using ConfirmAccount.
+// Linking an account again replaces the previous one.
+func LinkAccount(orga *model.Organization, userId, integrationId hide.ID, externalId string) error {
+	externalId = strings.TrimSpace(externalId)
+
+	if externalId == "" {
+		return errs.ExternalIdEmpty
+	}
+
+	member, integration, err := getMemberAndIntegration(orga, userId, integrationId)
+
+	if err != nil {
+		return err
+	}
+
+	client := clients[integration.Type]
+
+	if client == nil || !client.canSendDirect(integration) {
+		return errs.ChatIntegrationNoDirectMessage
+	}
+
+	account := model.GetChatAccountByChatIntegrationIdAndOrganizationMemberId(integration.ID, member.ID)
+
+	if account == nil {
+		account = &model.ChatAccount{ChatIntegrationId: integration.ID, OrganizationMemberId: member.ID}
+	}
+
+	if account.ExternalId != externalId {
+		account.RoomId.SetNil()
+	}
+
+	account.ExternalId = externalId
+	account.Code = util.GenRandomString(confirmationCodeLen)
+	account.Confirmed = false
+	langCode := util.DetermineSystemSupportedLangCode(orga.ID, userId)
+	text := i18n.GetVars(langCode, linkAccountI18n)["text"]
+	msg := message{fmt.Sprintf("%s %s", text, account.Code),
+		fmt.Sprintf("%s <b>%s</b>", text, html.EscapeString(account.Code)),
+		fmt.Sprintf("%s *%s*", text, account.Code)}
+
+	if err := client.sendDirect(integration, account, msg); err != nil {
+		logbuch.Warn("Error sending confirmation code to chat account", logbuch.Fields{"err": err, "chat_integration_id": integration.ID, "member_id": member.ID})
+		return errs.SendingChatMessage
+	}
+
+	if err := model.SaveChatAccount(nil, account); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// ConfirmAccount confirms the chat account linked to the integration using the code sent to it.
+func ConfirmAccount(orga *model.Organization, userId, integrationId hide.ID, code string) error {
+	account, err := getAccount(orga, userId, integrationId)
+
+	if err != nil {
+		return err
+	}
+
+	if account.Confirmed {
+		return nil
+	}
+
+	if strings.TrimSpace(code) != account.Code {
+		return errs.ChatAccountCodeInvalid
+	}
+
+	account.Confirmed = true
+
+	if err := model.SaveChatAccount(nil, account); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// UnlinkAccount removes the chat account linked to the integration.
+func UnlinkAccount(orga *model.Organization, userId, integrationId hide.ID) error {
+	account, err := getAccount(orga, userId, integrationId)
+
+	if err != nil {
+		return err
+	}
+
+	if err := model.DeleteChatAccountById(nil, account.ID); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// ReadAccounts returns the chat accounts linked by the member.
+func ReadAccounts(orga *model.Organization, userId hide.ID) ([]model.ChatAccount, error) {
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId)
+
+	if member == nil {
+		return nil, errs.MemberNotFound
+	}
+
+	return model.FindChatAccountByOrganizationMemberId(member.ID), nil
+}
+
+func getAccount(orga *model.Organization, userId, integrationId hide.ID) (*model.ChatAccount, error) {
+	member, integration, err := getMemberAndIntegration(orga, userId, integrationId)
+
+	if err != nil {
+		return nil, err
+	}
+
+	account := model.GetChatAccountByChatIntegrationIdAndOrganizationMemberId(integration.ID, member.ID)
+
+	if account == nil {
+		return nil, errs.ChatAccountNotFound
+	}
+
+	return account, nil
+}
+
+func getMemberAndIntegration(orga *model.Organization, userId, integrationId hide.ID) (*model.OrganizationMember, *model.ChatIntegration, error) {
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId)
+
+	if member == nil {
+		return nil, nil, errs.MemberNotFound
+	}
+
+	integration := model.GetChatIntegrationByOrganizationIdAndId(orga.ID, integrationId)
+
+	if integration == nil {
+		return nil, nil, errs.ChatIntegrationNotFound
+	}
+
+	return member, integration, nil
+}
@@ -0,0 +1,122 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLinkAccount(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	messages := make([]matrixMessage, 0)
+	server := newMatrixStandIn(t, &messages)
+	defer server.Close()
+	slackId, _ := SaveIntegration(orga, admin.ID, &SaveIntegrationData{Type: model.ChatIntegrationSlack,
+		Name:       "Slack",
+		WebhookURL: "https://hooks.slack.com/services/T/B/X"})
+	id, _ := SaveIntegration(orga, admin.ID, &SaveIntegrationData{Type: model.ChatIntegrationMatrix,
+		Name:        "Matrix",
+		APIURL:      server.URL,
+		AccessToken: "token",
+		RoomId:      "!room:matrix.org"})
+
+	if err := LinkAccount(orga, user.ID, id, " "); err != errs.ExternalIdEmpty {
+		t.Fatalf("External ID must be validated, but was: %v", err)
+	}
+
+	if err := LinkAccount(orga, user.ID, slackId, "U123"); err != errs.ChatIntegrationNoDirectMessage {
+		t.Fatalf("Slack integration without access token must not allow linking accounts, but was: %v", err)
+	}
+
+	if err := LinkAccount(orga, user.ID, id, "@user:matrix.org"); err != nil {
+		t.Fatalf("Account must have been linked, but was: %v", err)
+	}
+
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, user.ID)
+	account := model.GetChatAccountByChatIntegrationIdAndOrganizationMemberId(id, member.ID)
+
+	if account == nil || account.Confirmed || account.RoomId.String != "!direct:matrix.org" || len(account.Code) != confirmationCodeLen {
+		t.Fatalf("Account not as expected: %v", account)
+	}
+
+	if len(messages) != 1 || !strings.Contains(messages[0].Body, account.Code) {
+		t.Fatalf("Code must have been sent to account, but was: %v", messages)
+	}
+
+	if err := ConfirmAccount(orga, admin.ID, id, account.Code); err != errs.ChatAccountNotFound {
+		t.Fatalf("Account of other member must not be confirmed, but was: %v", err)
+	}
+
+	if err := ConfirmAccount(orga, user.ID, id, "invalid"); err != errs.ChatAccountCodeInvalid {
+		t.Fatalf("Code must be validated, but was: %v", err)
+	}
+
+	if err := ConfirmAccount(orga, user.ID, id, account.Code); err != nil {
+		t.Fatalf("Account must have been confirmed, but was: %v", err)
+	}
+
+	if accounts := model.FindChatAccountByOrganizationIdAndUserIdAndConfirmed(orga.ID, user.ID); len(accounts) != 1 {
+		t.Fatalf("Confirmed account must be found, but was: %v", accounts)
+	}
+
+	accounts, err := ReadAccounts(orga, user.ID)
+
+	if err != nil || len(accounts) != 1 || !accounts[0].Confirmed || accounts[0].ExternalId != "@user:matrix.org" {
+		t.Fatalf("Account must have been returned, but was: %v %v", accounts, err)
+	}
+
+	if err := UnlinkAccount(orga, user.ID, id); err != nil {
+		t.Fatalf("Account must have been unlinked, but was: %v", err)
+	}
+
+	if accounts, _ := ReadAccounts(orga, user.ID); len(accounts) != 0 {
+		t.Fatalf("Account must have been deleted, but was: %v", accounts)
+	}
+}
+
+func TestParseArticleURL(t *testing.T) {
+	frontendHost = "https://emvi.com"
+	orga := &model.Organization{NameNormalized: "test"}
+	id, _ := hide.ToString(42)
+	input := []string{
+		"https://test.emvi.com/read/some-article-title-" + id,
+		"https://TEST.emvi.com/read/" + id + "/",
+		"https://other.emvi.com/read/some-article-title-" + id,
+		"https://test.emvi.com/edit/some-article-title-" + id,
+		"https://test.emvi.com/read/some-article-title-invalid",
+		"://invalid",
+	}
+	expected := []bool{true, true, false, false, false, false}
+
+	for i, in := range input {
+		if articleId := parseArticleURL(orga, in); (articleId == 42) != expected[i] {
+			t.Fatalf("Expected article ID to be found for '%s': %v, but was: %v", in, expected[i], articleId)
+		}
+	}
+}
+
+func newMatrixStandIn(t *testing.T, messages *[]matrixMessage) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/_matrix/client/r0/createRoom" {
+			w.Write([]byte(`{"room_id":"!direct:matrix.org"}`))
+			return
+		}
+
+		var msg matrixMessage
+
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Fatal(err)
+		}
+
+		*messages = append(*messages, msg)
+		w.Write([]byte(`{}`))
+	}))
+}
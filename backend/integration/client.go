@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"bytes"
+	"emviwiki/shared/model"
+	"encoding/json"
+	"fmt"
+	"github.com/emvi/logbuch"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+)
+
+const (
+	httpTimeout = time.Second * 10
+)
+
+var (
+	httpClient = &http.Client{Timeout: httpTimeout}
+
+	// clients by integration type
+	clients = map[string]chatClient{
+		model.ChatIntegrationSlack:  slackClient{},
+		model.ChatIntegrationMatrix: matrixClient{},
+	}
+)
+
+// chatClient posts messages to the chat of an integration.
+type chatClient interface {
+	// post posts the message to the channel or room of the integration.
+	post(integration *model.ChatIntegration, msg message) error
+
+	// sendDirect sends the message to the linked account directly.
+	// The account might be modified and should be saved afterwards.
+	sendDirect(integration *model.ChatIntegration, account *model.ChatAccount, msg message) error
+
+	// canSendDirect returns whether the integration is able to send direct messages.
+	canSendDirect(integration *model.ChatIntegration) bool
+}
+
+// Sends given body as JSON to the URL and decodes the response into resp if not nil.
+// The access token is send as bearer token if set.
+func doJSON(method, url, accessToken string, body, resp interface{}) error {
+	var reader io.Reader
+
+	if body != nil {
+		data, err := json.Marshal(body)
+
+		if err != nil {
+			return err
+		}
+
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, url, reader)
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	r, err := httpClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logbuch.Error("Error closing chat integration response body", logbuch.Fields{"err": err})
+		}
+	}()
+
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		respBody, _ := ioutil.ReadAll(r.Body)
+		return fmt.Errorf("chat integration responded with status %d: %s", r.StatusCode, string(respBody))
+	}
+
+	if resp != nil {
+		return json.NewDecoder(r.Body).Decode(resp)
+	}
+
+	return nil
+}
@@ -0,0 +1,13 @@
+package integration
+
+import (
+	"emviwiki/shared/config"
+)
+
+var (
+	frontendHost string
+)
+
+func LoadConfig() {
+	frontendHost = config.Get().Hosts.Frontend
+}
@@ -0,0 +1,25 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+// DeleteIntegration deletes a chat integration and all accounts linked to it.
+func DeleteIntegration(orga *model.Organization, userId, id hide.ID) error {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	if model.GetChatIntegrationByOrganizationIdAndId(orga.ID, id) == nil {
+		return errs.ChatIntegrationNotFound
+	}
+
+	if err := model.DeleteChatIntegrationById(nil, id); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package integration
+
+import (
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	matrixClientAPI        = "/_matrix/client/r0"
+	matrixMessageType      = "m.text"
+	matrixMessageFormat    = "org.matrix.custom.html"
+	matrixTransactionIdLen = 20
+)
+
+type matrixClient struct{}
+
+type matrixMessage struct {
+	MsgType       string `json:"msgtype"`
+	Body          string `json:"body"`
+	Format        string `json:"format"`
+	FormattedBody string `json:"formatted_body"`
+}
+
+type matrixCreateRoom struct {
+	IsDirect bool     `json:"is_direct"`
+	Invite   []string `json:"invite"`
+	Preset   string   `json:"preset"`
+}
+
+type matrixRoom struct {
+	RoomId string `json:"room_id"`
+}
+
+func (client matrixClient) post(integration *model.ChatIntegration, msg message) error {
+	return sendMatrixMessage(integration, integration.RoomId.String, msg)
+}
+
+func (client matrixClient) sendDirect(integration *model.ChatIntegration, account *model.ChatAccount, msg message) error {
+	if !account.RoomId.Valid {
+		roomId, err := createMatrixDirectRoom(integration, account.ExternalId)
+
+		if err != nil {
+			return err
+		}
+
+		account.RoomId.SetValid(roomId)
+	}
+
+	return sendMatrixMessage(integration, account.RoomId.String, msg)
+}
+
+func (client matrixClient) canSendDirect(integration *model.ChatIntegration) bool {
+	return true
+}
+
+func sendMatrixMessage(integration *model.ChatIntegration, roomId string, msg message) error {
+	// the transaction ID makes sure the message is send once only if the request is retried
+	path := fmt.Sprintf("/rooms/%s/send/m.room.message/%s", url.PathEscape(roomId), util.GenRandomString(matrixTransactionIdLen))
+	body := matrixMessage{matrixMessageType, msg.Text, matrixMessageFormat, msg.HTML}
+	return doJSON(http.MethodPut, matrixURL(integration, path), integration.AccessToken.String, body, nil)
+}
+
+func createMatrixDirectRoom(integration *model.ChatIntegration, userId string) (string, error) {
+	body := matrixCreateRoom{true, []string{userId}, "trusted_private_chat"}
+	var room matrixRoom
+
+	if err := doJSON(http.MethodPost, matrixURL(integration, "/createRoom"), integration.AccessToken.String, body, &room); err != nil {
+		return "", err
+	}
+
+	return room.RoomId, nil
+}
+
+func matrixURL(integration *model.ChatIntegration, path string) string {
+	return strings.TrimSuffix(integration.APIURL, "/") + matrixClientAPI + path
+}
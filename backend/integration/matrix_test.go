@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"emviwiki/shared/model"
+	"encoding/json"
+	"github.com/emvi/null"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMatrixClient(t *testing.T) {
+	rooms := make([]string, 0)
+	messages := make([]matrixMessage, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == http.MethodPost && r.URL.Path == "/_matrix/client/r0/createRoom" {
+			var req matrixCreateRoom
+			json.NewDecoder(r.Body).Decode(&req)
+
+			if !req.IsDirect || len(req.Invite) != 1 || req.Invite[0] != "@user:matrix.org" {
+				t.Fatalf("Create room request not as expected: %v", req)
+			}
+
+			w.Write([]byte(`{"room_id":"!direct:matrix.org"}`))
+		} else if r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_matrix/client/r0/rooms/") {
+			var msg matrixMessage
+			json.NewDecoder(r.Body).Decode(&msg)
+			rooms = append(rooms, strings.Split(r.URL.Path, "/")[5])
+			messages = append(messages, msg)
+			w.Write([]byte(`{"event_id":"$event"}`))
+		} else {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	integration := &model.ChatIntegration{Type: model.ChatIntegrationMatrix,
+		APIURL:      server.URL,
+		AccessToken: null.NewString("token", true),
+		RoomId:      null.NewString("!room:matrix.org", true)}
+	account := &model.ChatAccount{ExternalId: "@user:matrix.org"}
+	client := matrixClient{}
+
+	if err := client.post(integration, message{"text", "<b>text</b>", ""}); err != nil {
+		t.Fatalf("Message must have been posted, but was: %v", err)
+	}
+
+	if err := client.sendDirect(integration, account, message{"direct", "direct", ""}); err != nil {
+		t.Fatalf("Direct message must have been sent, but was: %v", err)
+	}
+
+	if account.RoomId.String != "!direct:matrix.org" {
+		t.Fatalf("Direct room must have been set, but was: %v", account.RoomId.String)
+	}
+
+	if len(rooms) != 2 || rooms[0] != "!room:matrix.org" || rooms[1] != "!direct:matrix.org" {
+		t.Fatalf("Messages must have been sent to rooms, but was: %v", rooms)
+	}
+
+	if messages[0].Body != "text" || messages[0].FormattedBody != "<b>text</b>" || messages[0].Format != matrixMessageFormat {
+		t.Fatalf("Message not as expected: %v", messages[0])
+	}
+
+	integration.AccessToken.SetValid("invalid")
+
+	if err := client.post(integration, message{}); err == nil {
+		t.Fatal("Error must be returned for invalid access token")
+	}
+}
@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"emviwiki/shared/model"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"html"
+	"strings"
+)
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// message is a text in the formats supported by the chats.
+type message struct {
+	Text  string // plain text
+	HTML  string
+	Slack string // Slack mrkdwn
+}
+
+// Creates a new message for a feed text as rendered for the frontend, prefixed by the name of the user triggering it.
+func newFeedMessage(user *model.User, feedHTML string) message {
+	name := fmt.Sprintf("%s %s", user.Firstname, user.Lastname)
+	msg := newMessage(feedHTML)
+	msg.Text = name + " " + msg.Text
+	msg.HTML = "<b>" + html.EscapeString(name) + "</b> " + msg.HTML
+	msg.Slack = "*" + slackEscaper.Replace(name) + "* " + msg.Slack
+	return msg
+}
+
+// Creates a new message from HTML. Only links and line breaks are kept for plain text and Slack.
+func newMessage(htmlText string) message {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(htmlText))
+
+	if err != nil {
+		return message{htmlText, html.EscapeString(htmlText), slackEscaper.Replace(htmlText)}
+	}
+
+	var text, slack strings.Builder
+	convertNodes(doc.Find("body").Contents(), &text, &slack)
+	return message{strings.TrimSpace(text.String()), htmlText, strings.TrimSpace(slack.String())}
+}
+
+func convertNodes(nodes *goquery.Selection, text, slack *strings.Builder) {
+	nodes.Each(func(_ int, node *goquery.Selection) {
+		switch goquery.NodeName(node) {
+		case "#text":
+			text.WriteString(node.Text())
+			slack.WriteString(slackEscaper.Replace(node.Text()))
+		case "a":
+			href, _ := node.Attr("href")
+			text.WriteString(fmt.Sprintf("%s (%s)", node.Text(), href))
+			slack.WriteString(fmt.Sprintf("<%s|%s>", href, slackEscaper.Replace(node.Text())))
+		case "br":
+			text.WriteString("\n")
+			slack.WriteString("\n")
+		case "div", "p":
+			text.WriteString("\n")
+			slack.WriteString("\n")
+			convertNodes(node.Contents(), text, slack)
+		default:
+			convertNodes(node.Contents(), text, slack)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"emviwiki/shared/model"
+	"testing"
+)
+
+func TestNewMessage(t *testing.T) {
+	input := []string{
+		"plain text",
+		`updated <a href="https://emvi.com/read/article-1">Article &amp; more</a>`,
+		"<p>first</p><p>second<br>line</p>",
+		"a < b",
+	}
+	expectedText := []string{
+		"plain text",
+		"updated Article & more (https://emvi.com/read/article-1)",
+		"first\nsecond\nline",
+		"a < b",
+	}
+	expectedSlack := []string{
+		"plain text",
+		"updated <https://emvi.com/read/article-1|Article &amp; more>",
+		"first\nsecond\nline",
+		"a &lt; b",
+	}
+
+	for i, in := range input {
+		msg := newMessage(in)
+
+		if msg.Text != expectedText[i] {
+			t.Fatalf("Expected text '%s', but was: '%s'", expectedText[i], msg.Text)
+		}
+
+		if msg.Slack != expectedSlack[i] {
+			t.Fatalf("Expected Slack text '%s', but was: '%s'", expectedSlack[i], msg.Slack)
+		}
+
+		if msg.HTML != in {
+			t.Fatalf("HTML must be kept, but was: %s", msg.HTML)
+		}
+	}
+}
+
+func TestNewFeedMessage(t *testing.T) {
+	user := &model.User{Firstname: "Max", Lastname: "<Mustermann>"}
+	msg := newFeedMessage(user, "created a tag")
+
+	if msg.Text != "Max <Mustermann> created a tag" ||
+		msg.HTML != "<b>Max &lt;Mustermann&gt;</b> created a tag" ||
+		msg.Slack != "*Max &lt;Mustermann&gt;* created a tag" {
+		t.Fatalf("Message not as expected: %v", msg)
+	}
+}
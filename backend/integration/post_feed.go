@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"emviwiki/shared/feed"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+var (
+	// notification categories sent to linked chat accounts as direct messages
+	directMessageCategories = map[string]bool{
+		feed.CategoryMentions:        true,
+		feed.CategoryRecommendations: true,
+	}
+)
+
+// PostFeed posts a new feed entry to the chat integrations of the organization selecting its reason
+// and sends personal notifications to the linked chat accounts of notified users.
+// Only public feed entries are posted to integrations, as the channel members might not have access.
+// The feed must contain its references and is expected not to be modified afterwards.
+func PostFeed(orga *model.Organization, newFeed *model.Feed, notify []hide.ID) {
+	if !newFeed.Public && (len(notify) == 0 || !directMessageCategories[feed.ReasonCategory(newFeed.Reason)]) {
+		return
+	}
+
+	go func() {
+		user := model.GetUserById(newFeed.TriggeredByUserId)
+
+		if user == nil {
+			logbuch.Error("User triggering feed not found", logbuch.Fields{"user_id": newFeed.TriggeredByUserId, "feed_id": newFeed.ID})
+			return
+		}
+
+		if newFeed.Public {
+			postFeed(orga, user, newFeed)
+		}
+
+		if directMessageCategories[feed.ReasonCategory(newFeed.Reason)] {
+			for _, userId := range notify {
+				sendDirectMessages(orga, user, newFeed, userId)
+			}
+		}
+	}()
+}
+
+func postFeed(orga *model.Organization, user *model.User, newFeed *model.Feed) {
+	integrations := model.FindChatIntegrationByOrganizationIdAndReason(orga.ID, newFeed.Reason)
+
+	if len(integrations) == 0 {
+		return
+	}
+
+	msg, ok := renderFeedMessage(orga, user, newFeed, util.DefaultSupportedLang, false)
+
+	if !ok {
+		return
+	}
+
+	for i := range integrations {
+		if err := clients[integrations[i].Type].post(&integrations[i], msg); err != nil {
+			logbuch.Warn("Error posting feed to chat integration", logbuch.Fields{"err": err, "chat_integration_id": integrations[i].ID, "feed_id": newFeed.ID})
+		}
+	}
+}
+
+func sendDirectMessages(orga *model.Organization, user *model.User, newFeed *model.Feed, userId hide.ID) {
+	if userId == newFeed.TriggeredByUserId {
+		return
+	}
+
+	accounts := model.FindChatAccountByOrganizationIdAndUserIdAndConfirmed(orga.ID, userId)
+
+	if len(accounts) == 0 {
+		return
+	}
+
+	msg, ok := renderFeedMessage(orga, user, newFeed, util.DetermineSystemSupportedLangCode(orga.ID, userId), true)
+
+	if !ok {
+		return
+	}
+
+	for i := range accounts {
+		integration := model.GetChatIntegrationById(accounts[i].ChatIntegrationId)
+
+		if integration == nil {
+			continue
+		}
+
+		roomId := accounts[i].RoomId
+
+		if err := clients[integration.Type].sendDirect(integration, &accounts[i], msg); err != nil {
+			logbuch.Warn("Error sending direct message to chat account", logbuch.Fields{"err": err, "chat_account_id": accounts[i].ID, "feed_id": newFeed.ID})
+			continue
+		}
+
+		if roomId != accounts[i].RoomId {
+			if err := model.SaveChatAccount(nil, &accounts[i]); err != nil {
+				logbuch.Error("Error saving chat account room", logbuch.Fields{"err": err, "chat_account_id": accounts[i].ID})
+			}
+		}
+	}
+}
+
+func renderFeedMessage(orga *model.Organization, user *model.User, newFeed *model.Feed, langCode string, notification bool) (message, bool) {
+	reason, ok := feed.Reasons[langCode][newFeed.Reason]
+
+	if !ok {
+		return message{}, false
+	}
+
+	text, textType := reason.Feed, feed.FeedText
+
+	if notification && reason.Notification != "" {
+		text, textType = reason.Notification, feed.NotificationText
+	}
+
+	rendered := feed.RenderFeed(orga, text, textType, langCode, newFeed)
+
+	if rendered == "" {
+		return message{}, false
+	}
+
+	return newFeedMessage(user, rendered), true
+}
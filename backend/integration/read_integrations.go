@@ -0,0 +1,34 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+// ReadIntegrations returns all chat integrations of the organization including the reasons they post.
+// Members need to read them to link their chat accounts, so secrets are never returned.
+func ReadIntegrations(orga *model.Organization, userId hide.ID) ([]model.ChatIntegration, error) {
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, userId)
+
+	if member == nil {
+		return nil, errs.MemberNotFound
+	}
+
+	integrations := model.FindChatIntegrationByOrganizationId(orga.ID)
+
+	for i := range integrations {
+		integrations[i].Reasons = make([]string, 0)
+
+		for _, reason := range model.FindChatIntegrationReasonByChatIntegrationId(integrations[i].ID) {
+			integrations[i].Reasons = append(integrations[i].Reasons, reason.Reason)
+		}
+
+		// the webhook URL allows to post to the channel
+		if !member.IsAdmin {
+			integrations[i].WebhookURL.SetNil()
+		}
+	}
+
+	return integrations, nil
+}
@@ -0,0 +1,175 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/feed"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	nameMaxLen = 40
+)
+
+// SaveIntegrationData is used to create or update a chat integration.
+// The access token and signing secret are kept when updating the integration and they are left empty.
+type SaveIntegrationData struct {
+	Id            hide.ID  `json:"id"`
+	Type          string   `json:"type"`
+	Name          string   `json:"name"`
+	WebhookURL    string   `json:"webhook_url"`
+	APIURL        string   `json:"api_url"`
+	AccessToken   string   `json:"access_token"`
+	SigningSecret string   `json:"signing_secret"`
+	RoomId        string   `json:"room_id"`
+	Reasons       []string `json:"reasons"`
+}
+
+func (data *SaveIntegrationData) validate(integration *model.ChatIntegration) []error {
+	data.Name = strings.TrimSpace(data.Name)
+	data.WebhookURL = strings.TrimSpace(data.WebhookURL)
+	data.APIURL = strings.TrimSpace(data.APIURL)
+	data.AccessToken = strings.TrimSpace(data.AccessToken)
+	data.SigningSecret = strings.TrimSpace(data.SigningSecret)
+	data.RoomId = strings.TrimSpace(data.RoomId)
+	err := make([]error, 0)
+
+	if len(data.Name) == 0 {
+		err = append(err, errs.NameEmpty)
+	} else if utf8.RuneCountInString(data.Name) > nameMaxLen {
+		err = append(err, errs.NameLen)
+	}
+
+	switch data.Type {
+	case model.ChatIntegrationSlack:
+		if data.APIURL == "" {
+			data.APIURL = slackDefaultAPIURL
+		}
+
+		if !checkURL(data.WebhookURL) {
+			err = append(err, errs.WebhookURLInvalid)
+		}
+	case model.ChatIntegrationMatrix:
+		if data.AccessToken == "" && (integration == nil || !integration.AccessToken.Valid) {
+			err = append(err, errs.AccessTokenEmpty)
+		}
+
+		if data.RoomId == "" {
+			err = append(err, errs.RoomIdEmpty)
+		}
+	default:
+		err = append(err, errs.ChatIntegrationTypeInvalid)
+	}
+
+	if !checkURL(data.APIURL) {
+		err = append(err, errs.APIURLInvalid)
+	}
+
+	for _, reason := range data.Reasons {
+		if !feed.CheckReasonExists(reason) {
+			err = append(err, errs.ReasonNotFound)
+			break
+		}
+	}
+
+	if len(err) == 0 {
+		return nil
+	}
+
+	return err
+}
+
+// SaveIntegration creates or updates a chat integration. Only administrators can manage integrations.
+func SaveIntegration(orga *model.Organization, userId hide.ID, data *SaveIntegrationData) (hide.ID, []error) {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return 0, []error{err}
+	}
+
+	integration := new(model.ChatIntegration)
+
+	if data.Id != 0 {
+		integration = model.GetChatIntegrationByOrganizationIdAndId(orga.ID, data.Id)
+
+		if integration == nil {
+			return 0, []error{errs.ChatIntegrationNotFound}
+		}
+
+		// changing the type would break linked accounts
+		data.Type = integration.Type
+	}
+
+	if err := data.validate(integration); err != nil {
+		return 0, err
+	}
+
+	integration.OrganizationId = orga.ID
+	integration.Type = data.Type
+	integration.Name = data.Name
+	integration.WebhookURL = null.NewString(data.WebhookURL, data.WebhookURL != "")
+	integration.APIURL = data.APIURL
+	integration.RoomId = null.NewString(data.RoomId, data.RoomId != "")
+
+	if data.AccessToken != "" {
+		integration.AccessToken.SetValid(data.AccessToken)
+	}
+
+	if data.SigningSecret != "" {
+		integration.SigningSecret.SetValid(data.SigningSecret)
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to save chat integration", logbuch.Fields{"err": err})
+		return 0, []error{errs.TxBegin}
+	}
+
+	if err := model.SaveChatIntegration(tx, integration); err != nil {
+		return 0, []error{errs.Saving}
+	}
+
+	if err := saveReasons(tx, integration.ID, data.Reasons); err != nil {
+		return 0, []error{err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction to save chat integration", logbuch.Fields{"err": err})
+		return 0, []error{errs.TxCommit}
+	}
+
+	return integration.ID, nil
+}
+
+func saveReasons(tx *sqlx.Tx, integrationId hide.ID, reasons []string) error {
+	if err := model.DeleteChatIntegrationReasonByChatIntegrationId(tx, integrationId); err != nil {
+		return errs.Saving
+	}
+
+	saved := make(map[string]bool)
+
+	for _, reason := range reasons {
+		if saved[reason] {
+			continue
+		}
+
+		if err := model.SaveChatIntegrationReason(tx, &model.ChatIntegrationReason{ChatIntegrationId: integrationId, Reason: reason}); err != nil {
+			return errs.Saving
+		}
+
+		saved[reason] = true
+	}
+
+	return nil
+}
+
+func checkURL(str string) bool {
+	u, err := url.Parse(str)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
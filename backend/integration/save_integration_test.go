@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestSaveIntegration(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	data := &SaveIntegrationData{Type: model.ChatIntegrationSlack, Name: "Slack", WebhookURL: "https://hooks.slack.com/services/T/B/X"}
+
+	if _, err := SaveIntegration(orga, user.ID, data); len(err) != 1 || err[0] != errs.PermissionDenied {
+		t.Fatalf("Member must not be allowed to create integration, but was: %v", err)
+	}
+
+	input := []SaveIntegrationData{
+		{Type: "irc", Name: "IRC", APIURL: "https://irc.com"},
+		{Type: model.ChatIntegrationSlack, Name: " ", WebhookURL: "https://hooks.slack.com"},
+		{Type: model.ChatIntegrationSlack, Name: "Slack", WebhookURL: "invalid"},
+		{Type: model.ChatIntegrationSlack, Name: "Slack", WebhookURL: "https://hooks.slack.com", Reasons: []string{"unknown"}},
+		{Type: model.ChatIntegrationMatrix, Name: "Matrix", APIURL: "ftp://matrix.org", AccessToken: "token", RoomId: "!room:matrix.org"},
+		{Type: model.ChatIntegrationMatrix, Name: "Matrix", APIURL: "https://matrix.org", RoomId: "!room:matrix.org"},
+		{Type: model.ChatIntegrationMatrix, Name: "Matrix", APIURL: "https://matrix.org", AccessToken: "token"},
+	}
+	expected := []error{
+		errs.ChatIntegrationTypeInvalid,
+		errs.NameEmpty,
+		errs.WebhookURLInvalid,
+		errs.ReasonNotFound,
+		errs.APIURLInvalid,
+		errs.AccessTokenEmpty,
+		errs.RoomIdEmpty,
+	}
+
+	for i, in := range input {
+		if _, err := SaveIntegration(orga, admin.ID, &in); len(err) != 1 || err[0] != expected[i] {
+			t.Fatalf("Expected error '%v', but was: %v", expected[i], err)
+		}
+	}
+
+	data.Reasons = []string{"create_article", "update_article", "create_article"}
+	id, err := SaveIntegration(orga, admin.ID, data)
+
+	if err != nil {
+		t.Fatalf("Integration must have been created, but was: %v", err)
+	}
+
+	integration := model.GetChatIntegrationById(id)
+
+	if integration.APIURL != slackDefaultAPIURL || integration.WebhookURL.String != data.WebhookURL {
+		t.Fatalf("Integration not as expected: %v", integration)
+	}
+
+	if reasons := model.FindChatIntegrationReasonByChatIntegrationId(id); len(reasons) != 2 {
+		t.Fatalf("Reasons must have been saved once, but was: %v", len(reasons))
+	}
+
+	data.Id = id
+	data.Type = model.ChatIntegrationMatrix
+	data.AccessToken = "token"
+	data.SigningSecret = "secret"
+	data.Reasons = nil
+
+	if _, err := SaveIntegration(orga, admin.ID, data); err != nil {
+		t.Fatalf("Integration must have been updated, but was: %v", err)
+	}
+
+	data.AccessToken = ""
+	data.SigningSecret = ""
+
+	if _, err := SaveIntegration(orga, admin.ID, data); err != nil {
+		t.Fatalf("Integration must have been updated, but was: %v", err)
+	}
+
+	integration = model.GetChatIntegrationById(id)
+
+	if integration.Type != model.ChatIntegrationSlack || integration.AccessToken.String != "token" || integration.SigningSecret.String != "secret" {
+		t.Fatalf("Type, access token and signing secret must have been kept, but was: %v", integration)
+	}
+
+	if reasons := model.FindChatIntegrationReasonByChatIntegrationId(id); len(reasons) != 0 {
+		t.Fatalf("Reasons must have been removed, but was: %v", len(reasons))
+	}
+}
+
+func TestReadAndDeleteIntegration(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, admin := testutil.CreateOrgaAndUser(t)
+	user := testutil.CreateUser(t, orga, 321, "user@test.com")
+	id, _ := SaveIntegration(orga, admin.ID, &SaveIntegrationData{Type: model.ChatIntegrationSlack,
+		Name:       "Slack",
+		WebhookURL: "https://hooks.slack.com/services/T/B/X",
+		Reasons:    []string{"create_article"}})
+	integrations, err := ReadIntegrations(orga, user.ID)
+
+	if err != nil || len(integrations) != 1 || integrations[0].ID != id {
+		t.Fatalf("Integration must have been returned, but was: %v %v", integrations, err)
+	}
+
+	if integrations[0].WebhookURL.Valid || len(integrations[0].Reasons) != 1 || integrations[0].Reasons[0] != "create_article" {
+		t.Fatalf("Webhook URL must be hidden from members and reasons must be returned, but was: %v", integrations[0])
+	}
+
+	integrations, _ = ReadIntegrations(orga, admin.ID)
+
+	if !integrations[0].WebhookURL.Valid {
+		t.Fatal("Webhook URL must be returned to administrators")
+	}
+
+	if err := DeleteIntegration(orga, user.ID, id); err != errs.PermissionDenied {
+		t.Fatalf("Member must not be allowed to delete integration, but was: %v", err)
+	}
+
+	if err := DeleteIntegration(orga, admin.ID, id); err != nil {
+		t.Fatalf("Integration must have been deleted, but was: %v", err)
+	}
+
+	if model.GetChatIntegrationById(id) != nil {
+		t.Fatal("Integration must not exist anymore")
+	}
+}
@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"emviwiki/shared/model"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	slackDefaultAPIURL      = "https://slack.com/api"
+	slackSignatureVersion   = "v0"
+	slackMaxTimestampOffset = 60 * 5 // seconds
+)
+
+type slackClient struct{}
+
+type slackMessage struct {
+	Channel string `json:"channel,omitempty"`
+	Text    string `json:"text"`
+}
+
+type slackUnfurl struct {
+	Title     string `json:"title"`
+	TitleLink string `json:"title_link"`
+	Text      string `json:"text"`
+	Footer    string `json:"footer,omitempty"`
+}
+
+type slackUnfurlRequest struct {
+	Channel string                 `json:"channel"`
+	TS      string                 `json:"ts"`
+	Unfurls map[string]slackUnfurl `json:"unfurls"`
+}
+
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (client slackClient) post(integration *model.ChatIntegration, msg message) error {
+	// incoming webhooks respond with plain text
+	return doJSON(http.MethodPost, integration.WebhookURL.String, "", slackMessage{Text: msg.Slack}, nil)
+}
+
+func (client slackClient) sendDirect(integration *model.ChatIntegration, account *model.ChatAccount, msg message) error {
+	// posting to the user ID opens the direct message channel of the app
+	return slackAPI(integration, "chat.postMessage", slackMessage{Channel: account.ExternalId, Text: msg.Slack})
+}
+
+func (client slackClient) canSendDirect(integration *model.ChatIntegration) bool {
+	return integration.AccessToken.Valid && integration.AccessToken.String != ""
+}
+
+func slackUnfurlLinks(integration *model.ChatIntegration, channel, ts string, unfurls map[string]slackUnfurl) error {
+	return slackAPI(integration, "chat.unfurl", slackUnfurlRequest{channel, ts, unfurls})
+}
+
+// Calls a Slack Web API method, which always responds with status 200 and reports errors in the body.
+func slackAPI(integration *model.ChatIntegration, method string, body interface{}) error {
+	url := fmt.Sprintf("%s/%s", strings.TrimSuffix(integration.APIURL, "/"), method)
+	var resp slackResponse
+
+	if err := doJSON(http.MethodPost, url, integration.AccessToken.String, body, &resp); err != nil {
+		return err
+	}
+
+	if !resp.OK {
+		return errors.New("Slack returned an error: " + resp.Error)
+	}
+
+	return nil
+}
+
+// Checks the signature Slack sends with each request to the events API.
+// The timestamp must not be older than five minutes to prevent replay attacks.
+func checkSlackSignature(secret, timestamp, signature string, body []byte, now time.Time) bool {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil || math.Abs(float64(now.Unix()-ts)) > slackMaxTimestampOffset {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("%s:%s:%s", slackSignatureVersion, timestamp, body)))
+	expected := slackSignatureVersion + "=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,190 @@
+package integration
+
+import (
+	"emviwiki/backend/article/util"
+	"emviwiki/backend/context"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/model"
+	sharedutil "emviwiki/shared/util"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	slackEventURLVerification = "url_verification"
+	slackEventCallback        = "event_callback"
+	slackEventLinkShared      = "link_shared"
+	articlePath               = "/read/"
+	unfurlTextMaxLen          = 300
+)
+
+type slackEventRequest struct {
+	Type      string     `json:"type"`
+	Challenge string     `json:"challenge"`
+	Event     slackEvent `json:"event"`
+}
+
+type slackEvent struct {
+	Type      string      `json:"type"`
+	Channel   string      `json:"channel"`
+	User      string      `json:"user"`
+	MessageTS string      `json:"message_ts"`
+	Links     []slackLink `json:"links"`
+}
+
+type slackLink struct {
+	Domain string `json:"domain"`
+	URL    string `json:"url"`
+}
+
+// SlackEvent handles a request to the Slack events API for given integration.
+// The challenge is returned when Slack verifies the URL and must be send back as response.
+// Links to articles are unfurled in the background, if the article can be read by the linked account of the sharing user.
+func SlackEvent(integrationId hide.ID, body []byte, timestamp, signature string) (string, error) {
+	integration := model.GetChatIntegrationById(integrationId)
+
+	if integration == nil || integration.Type != model.ChatIntegrationSlack || !integration.SigningSecret.Valid {
+		return "", errs.ChatIntegrationNotFound
+	}
+
+	if !checkSlackSignature(integration.SigningSecret.String, timestamp, signature, body, time.Now()) {
+		return "", errs.PermissionDenied
+	}
+
+	var req slackEventRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		logbuch.Debug("Error decoding Slack event", logbuch.Fields{"err": err, "chat_integration_id": integrationId})
+		return "", errs.UserDataInvalid
+	}
+
+	if req.Type == slackEventURLVerification {
+		return req.Challenge, nil
+	}
+
+	// Slack expects a response within three seconds
+	if req.Type == slackEventCallback && req.Event.Type == slackEventLinkShared {
+		go unfurlLinks(integration, req.Event)
+	}
+
+	return "", nil
+}
+
+func unfurlLinks(integration *model.ChatIntegration, event slackEvent) {
+	account := model.GetChatAccountByChatIntegrationIdAndExternalIdAndConfirmed(integration.ID, event.User)
+
+	if account == nil {
+		return
+	}
+
+	orga := model.GetOrganizationById(integration.OrganizationId)
+	member := model.GetOrganizationMemberByOrganizationIdAndId(integration.OrganizationId, account.OrganizationMemberId)
+
+	if orga == nil || member == nil {
+		return
+	}
+
+	ctx := context.NewEmviUserContext(orga, member.UserId)
+	unfurls := make(map[string]slackUnfurl)
+
+	for _, link := range event.Links {
+		if unfurl, ok := unfurlArticle(ctx, link.URL); ok {
+			unfurls[link.URL] = unfurl
+		}
+	}
+
+	if len(unfurls) == 0 {
+		return
+	}
+
+	if err := slackUnfurlLinks(integration, event.Channel, event.MessageTS, unfurls); err != nil {
+		logbuch.Warn("Error unfurling Slack links", logbuch.Fields{"err": err, "chat_integration_id": integration.ID})
+	}
+}
+
+// Returns the unfurl for an article link of the organization.
+// The article must be readable by the user sharing it and by all members of the organization,
+// because the channel might contain members without access.
+func unfurlArticle(ctx context.EmviContext, link string) (slackUnfurl, bool) {
+	articleId := parseArticleURL(ctx.Organization, link)
+
+	if articleId == 0 {
+		return slackUnfurl{}, false
+	}
+
+	article, err := util.GetArticleWithAccess(nil, ctx, articleId, false)
+
+	if err != nil || !article.ReadEveryone || article.Private {
+		return slackUnfurl{}, false
+	}
+
+	lang := sharedutil.DetermineLang(nil, ctx.Organization.ID, ctx.UserId, 0)
+	content := model.GetArticleContentLatestByOrganizationIdAndArticleIdAndLanguageId(ctx.Organization.ID, articleId, lang.ID, true)
+
+	if content == nil {
+		return slackUnfurl{}, false
+	}
+
+	return slackUnfurl{Title: content.Title,
+		TitleLink: link,
+		Text:      extractPreviewText(content.Content),
+		Footer:    ctx.Organization.Name}, true
+}
+
+// Returns the article ID for links like https://orga.emvi.com/read/title-slug-ID or 0 if it's not an article of the organization.
+func parseArticleURL(orga *model.Organization, link string) hide.ID {
+	u, err := url.Parse(link)
+
+	if err != nil {
+		return 0
+	}
+
+	orgaURL, err := url.Parse(sharedutil.InjectSubdomain(frontendHost, orga.NameNormalized))
+
+	if err != nil || !strings.EqualFold(u.Host, orgaURL.Host) || !strings.HasPrefix(u.Path, articlePath) {
+		return 0
+	}
+
+	slug := strings.Trim(strings.TrimPrefix(u.Path, articlePath), "/")
+	id, err := hide.FromString(slug[strings.LastIndex(slug, "-")+1:])
+
+	if err != nil {
+		return 0
+	}
+
+	return id
+}
+
+func extractPreviewText(content string) string {
+	doc, err := prosemirror.ParseDoc(content)
+
+	if err != nil {
+		return ""
+	}
+
+	paragraphs := prosemirror.FindNodes(doc, 1, "paragraph")
+
+	if len(paragraphs) == 0 {
+		return ""
+	}
+
+	var text strings.Builder
+
+	for _, node := range prosemirror.FindNodes(&paragraphs[0], -1, "text") {
+		text.WriteString(node.Text)
+	}
+
+	preview := strings.TrimSpace(text.String())
+
+	if utf8.RuneCountInString(preview) > unfurlTextMaxLen {
+		preview = string([]rune(preview)[:unfurlTextMaxLen]) + "…"
+	}
+
+	return preview
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"emviwiki/shared/model"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/emvi/null"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCheckSlackSignature(t *testing.T) {
+	now := time.Now()
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	body := []byte(`{"type":"url_verification"}`)
+	signature := signSlackRequest("secret", timestamp, body)
+
+	if !checkSlackSignature("secret", timestamp, signature, body, now) {
+		t.Fatal("Signature must be valid")
+	}
+
+	if checkSlackSignature("other", timestamp, signature, body, now) {
+		t.Fatal("Signature must be invalid for other secret")
+	}
+
+	if checkSlackSignature("secret", timestamp, signature, []byte("{}"), now) {
+		t.Fatal("Signature must be invalid for other body")
+	}
+
+	if checkSlackSignature("secret", timestamp, signature, body, now.Add(time.Minute*6)) {
+		t.Fatal("Signature must be invalid for old timestamp")
+	}
+
+	if checkSlackSignature("secret", "invalid", signature, body, now) {
+		t.Fatal("Signature must be invalid for invalid timestamp")
+	}
+}
+
+func TestSlackClient(t *testing.T) {
+	requests := make(map[string]map[string]interface{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		var req map[string]interface{}
+
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatal(err)
+		}
+
+		req["authorization"] = r.Header.Get("Authorization")
+		requests[r.URL.Path] = req
+
+		if r.URL.Path == "/api/chat.postMessage" {
+			w.Write([]byte(`{"ok":true}`))
+		} else if r.URL.Path == "/api/chat.unfurl" {
+			w.Write([]byte(`{"ok":false,"error":"cannot_unfurl_url"}`))
+		} else {
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+	integration := &model.ChatIntegration{Type: model.ChatIntegrationSlack,
+		WebhookURL:  null.NewString(server.URL+"/webhook", true),
+		APIURL:      server.URL + "/api",
+		AccessToken: null.NewString("token", true)}
+	account := &model.ChatAccount{ExternalId: "U123"}
+	client := slackClient{}
+
+	if err := client.post(integration, message{Slack: "*posted*"}); err != nil {
+		t.Fatalf("Message must have been posted, but was: %v", err)
+	}
+
+	if requests["/webhook"]["text"] != "*posted*" || requests["/webhook"]["authorization"] != "" {
+		t.Fatalf("Webhook request not as expected: %v", requests["/webhook"])
+	}
+
+	if err := client.sendDirect(integration, account, message{Slack: "direct"}); err != nil {
+		t.Fatalf("Direct message must have been sent, but was: %v", err)
+	}
+
+	req := requests["/api/chat.postMessage"]
+
+	if req["channel"] != "U123" || req["text"] != "direct" || req["authorization"] != "Bearer token" {
+		t.Fatalf("Direct message request not as expected: %v", req)
+	}
+
+	if err := slackUnfurlLinks(integration, "C1", "123.456", map[string]slackUnfurl{}); err == nil {
+		t.Fatal("Error must be returned if Slack is not ok")
+	}
+}
+
+func signSlackRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":" + string(body)))
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -50,6 +50,10 @@ func SwitchDefaultLanguage(orga *model.Organization, userId, langId hide.ID) err
 		return errs.Saving
 	}
 
+	if err := model.ResetArticleContentSourceVersionByOrganizationIdTx(tx, orga.ID); err != nil {
+		return errs.Saving
+	}
+
 	if err := tx.Commit(); err != nil {
 		logbuch.Error("Error committing transaction when switching default language", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId, "lang_id": langId})
 		return errs.TxCommit
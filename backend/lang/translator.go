@@ -0,0 +1,86 @@
+package lang
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+// ReadTranslators returns the members translating articles into given language.
+func ReadTranslators(orga *model.Organization, langId hide.ID) ([]model.User, error) {
+	if model.GetLanguageByOrganizationIdAndId(orga.ID, langId) == nil {
+		return nil, errs.LanguageNotFound
+	}
+
+	return model.FindLanguageTranslatorUserByOrganizationIdAndLanguageId(orga.ID, langId), nil
+}
+
+// AddTranslator adds a member as translator for given language.
+// Translators are notified when a translation into the language becomes outdated.
+func AddTranslator(orga *model.Organization, userId, langId, translatorUserId hide.ID) error {
+	if !orga.Expert {
+		return errs.RequiresExpertVersion
+	}
+
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	lang := model.GetLanguageByOrganizationIdAndId(orga.ID, langId)
+
+	if lang == nil {
+		return errs.LanguageNotFound
+	}
+
+	if lang.Default {
+		return errs.LanguageInvalid
+	}
+
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, translatorUserId)
+
+	if member == nil {
+		return errs.MemberNotFound
+	}
+
+	if member.ReadOnly {
+		return errs.TranslatorReadOnly
+	}
+
+	if model.GetLanguageTranslatorByLanguageIdAndUserId(langId, translatorUserId) != nil {
+		return errs.TranslatorExists
+	}
+
+	translator := &model.LanguageTranslator{LanguageId: langId, UserId: translatorUserId}
+
+	if err := model.SaveLanguageTranslator(nil, translator); err != nil {
+		logbuch.Error("Error saving language translator", logbuch.Fields{"err": err, "orga_id": orga.ID, "lang_id": langId, "user_id": translatorUserId})
+		return errs.Saving
+	}
+
+	return nil
+}
+
+// RemoveTranslator removes a member as translator for given language.
+func RemoveTranslator(orga *model.Organization, userId, langId, translatorUserId hide.ID) error {
+	if _, err := perm.CheckUserIsAdmin(orga.ID, userId); err != nil {
+		return err
+	}
+
+	if model.GetLanguageByOrganizationIdAndId(orga.ID, langId) == nil {
+		return errs.LanguageNotFound
+	}
+
+	translator := model.GetLanguageTranslatorByLanguageIdAndUserId(langId, translatorUserId)
+
+	if translator == nil {
+		return errs.TranslatorNotFound
+	}
+
+	if err := model.DeleteLanguageTranslatorById(nil, translator.ID); err != nil {
+		return errs.Saving
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package lang
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestAddTranslator(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	ja := model.GetLanguageByOrganizationIdAndCode(orga.ID, "ja")
+	translator := testutil.CreateUser(t, orga, 321, "translator@user.com")
+	readOnly := testutil.CreateUser(t, orga, 322, "readonly@user.com")
+	readOnly.OrganizationMember.ReadOnly = true
+
+	if err := model.SaveOrganizationMember(nil, readOnly.OrganizationMember); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AddTranslator(orga, translator.ID, ja.ID, translator.ID); err != errs.PermissionDenied {
+		t.Fatalf("Expected permission to be denied, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, 0, translator.ID); err != errs.LanguageNotFound {
+		t.Fatalf("Expected language not to be found, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, lang.ID, translator.ID); err != errs.LanguageInvalid {
+		t.Fatalf("Expected default language to be invalid, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, ja.ID, 0); err != errs.MemberNotFound {
+		t.Fatalf("Expected member not to be found, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, ja.ID, readOnly.ID); err != errs.TranslatorReadOnly {
+		t.Fatalf("Expected read only member to be refused, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, ja.ID, translator.ID); err != nil {
+		t.Fatalf("Expected translator to be added, but was: %v", err)
+	}
+
+	if err := AddTranslator(orga, user.ID, ja.ID, translator.ID); err != errs.TranslatorExists {
+		t.Fatalf("Expected translator to exist, but was: %v", err)
+	}
+
+	translators, err := ReadTranslators(orga, ja.ID)
+
+	if err != nil || len(translators) != 1 || translators[0].ID != translator.ID {
+		t.Fatalf("Translator must be returned, but was: %v %v", err, translators)
+	}
+}
+
+func TestRemoveTranslator(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	ja := model.GetLanguageByOrganizationIdAndCode(orga.ID, "ja")
+	translator := testutil.CreateUser(t, orga, 321, "translator@user.com")
+
+	if err := AddTranslator(orga, user.ID, ja.ID, translator.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RemoveTranslator(orga, translator.ID, ja.ID, translator.ID); err != errs.PermissionDenied {
+		t.Fatalf("Expected permission to be denied, but was: %v", err)
+	}
+
+	if err := RemoveTranslator(orga, user.ID, ja.ID, user.ID); err != errs.TranslatorNotFound {
+		t.Fatalf("Expected translator not to be found, but was: %v", err)
+	}
+
+	if err := RemoveTranslator(orga, user.ID, ja.ID, translator.ID); err != nil {
+		t.Fatalf("Expected translator to be removed, but was: %v", err)
+	}
+
+	if translators, _ := ReadTranslators(orga, ja.ID); len(translators) != 0 {
+		t.Fatalf("Translator must have been removed, but was: %v", translators)
+	}
+}
@@ -0,0 +1,137 @@
+package live
+
+import (
+	articleutil "emviwiki/backend/article/util"
+	rendering "emviwiki/shared/feed"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+// FeedMessage is sent for a new feed entry the subscriber has access to.
+// Count is the number of unread notifications.
+type FeedMessage struct {
+	Feed  *model.Feed `json:"feed"`
+	Count int         `json:"count"`
+}
+
+// ReadMessage is sent when the subscriber marked a notification as read or unread.
+// The feed ID is not set if all notifications have been marked as read.
+type ReadMessage struct {
+	FeedId hide.ID `json:"feed_id"`
+	Read   bool    `json:"read"`
+	Count  int     `json:"count"`
+}
+
+// ArticleMessage is sent when an article observed by the subscriber was updated.
+type ArticleMessage struct {
+	ArticleId  hide.ID `json:"article_id"`
+	LanguageId hide.ID `json:"language_id"`
+	Version    int     `json:"version"`
+	UserId     hide.ID `json:"user_id"`
+}
+
+// Dispatches the event to all subscribers of this instance allowed to receive it.
+// Messages are built once per user, as a user might be subscribed multiple times (like for multiple open tabs).
+func dispatch(event Event) {
+	subs := subscriptions.get(event.OrganizationId)
+
+	if len(subs) == 0 {
+		return
+	}
+
+	var build func(*Subscription) *Message
+
+	switch event.Type {
+	case EventFeed:
+		build = feedMessageBuilder(event)
+	case EventRead:
+		build = readMessageBuilder(event)
+	case EventArticle:
+		build = articleMessageBuilder(event)
+	case EventSync:
+		build = func(*Subscription) *Message {
+			return &Message{Event: EventSync, Data: struct{}{}}
+		}
+	default:
+		logbuch.Warn("Unknown live event type", logbuch.Fields{"type": event.Type})
+		return
+	}
+
+	messages := make(map[hide.ID]*Message)
+
+	for _, sub := range subs {
+		msg, ok := messages[sub.ctx.UserId]
+
+		if !ok {
+			msg = build(sub)
+			messages[sub.ctx.UserId] = msg
+		}
+
+		if msg != nil {
+			subscriptions.send(sub, *msg)
+		}
+	}
+}
+
+func feedMessageBuilder(event Event) func(*Subscription) *Message {
+	access := make(map[hide.ID]bool)
+
+	if !event.Public {
+		for _, a := range model.FindFeedAccessByFeedId(event.FeedId) {
+			access[a.UserId] = true
+		}
+	}
+
+	return func(sub *Subscription) *Message {
+		orga, userId := sub.ctx.Organization, sub.ctx.UserId
+
+		if !event.Public && !access[userId] {
+			return nil
+		}
+
+		lang := util.DetermineLang(nil, orga.ID, userId, 0)
+		feed := model.GetFeedByOrganizationIdAndUserIdAndLanguageIdAndId(orga.ID, userId, lang.ID, event.FeedId)
+
+		if feed == nil {
+			return nil
+		}
+
+		entries := []model.Feed{*feed}
+		rendering.RenderFeedEntries(orga, util.DetermineSystemSupportedLangCode(orga.ID, userId), entries)
+		count := model.CountFeedAccessByOrganizationIdAndUserIdAndNotificationAndRead(orga.ID, userId, true, false)
+		return &Message{Event: EventFeed, Data: FeedMessage{&entries[0], count}}
+	}
+}
+
+func readMessageBuilder(event Event) func(*Subscription) *Message {
+	return func(sub *Subscription) *Message {
+		if sub.ctx.UserId != event.UserId {
+			return nil
+		}
+
+		count := model.CountFeedAccessByOrganizationIdAndUserIdAndNotificationAndRead(event.OrganizationId, event.UserId, true, false)
+		return &Message{Event: EventRead, Data: ReadMessage{event.FeedId, event.Read, count}}
+	}
+}
+
+func articleMessageBuilder(event Event) func(*Subscription) *Message {
+	observers := make(map[hide.ID]bool)
+
+	for _, userId := range model.FindObservedObjectUserIdByArticleIdOrArticleListId(event.ArticleId, 0) {
+		observers[userId] = true
+	}
+
+	return func(sub *Subscription) *Message {
+		if !observers[sub.ctx.UserId] {
+			return nil
+		}
+
+		if _, err := articleutil.GetArticleWithAccess(nil, sub.ctx, event.ArticleId, false); err != nil {
+			return nil
+		}
+
+		return &Message{Event: EventArticle, Data: ArticleMessage{event.ArticleId, event.LanguageId, event.Version, event.UserId}}
+	}
+}
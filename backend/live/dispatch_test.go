@@ -0,0 +1,142 @@
+package live
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestSubscribeUnsubscribe(t *testing.T) {
+	orga := &model.Organization{}
+	orga.ID = 1
+	otherOrga := &model.Organization{}
+	otherOrga.ID = 2
+	sub := Subscribe(context.NewEmviUserContext(orga, 1))
+	otherSub := Subscribe(context.NewEmviUserContext(otherOrga, 2))
+
+	if len(subscriptions.get(orga.ID)) != 1 || len(subscriptions.get(0)) != 2 {
+		t.Fatal("Subscriptions must have been added")
+	}
+
+	Unsubscribe(sub)
+	Unsubscribe(otherSub)
+
+	if len(subscriptions.get(0)) != 0 {
+		t.Fatal("Subscriptions must have been removed")
+	}
+}
+
+func TestDispatchSync(t *testing.T) {
+	orga := &model.Organization{}
+	orga.ID = 1
+	sub := Subscribe(context.NewEmviUserContext(orga, 1))
+	defer Unsubscribe(sub)
+	dispatch(Event{Type: EventSync})
+
+	if msg := receive(sub); msg == nil || msg.Event != EventSync {
+		t.Fatalf("Sync message must have been received, but was: %v", msg)
+	}
+}
+
+func TestDispatchFeed(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	otherUser := testutil.CreateUser(t, orga, 321, "member@user.com")
+	feed := testutil.CreateFeed(t, orga, user, lang, true)
+	sub := Subscribe(context.NewEmviUserContext(orga, user.ID))
+	defer Unsubscribe(sub)
+	otherSub := Subscribe(context.NewEmviUserContext(orga, otherUser.ID))
+	defer Unsubscribe(otherSub)
+	dispatch(Event{Type: EventFeed, OrganizationId: orga.ID, UserId: user.ID, FeedId: feed.ID})
+	msg := receive(sub)
+
+	if msg == nil || msg.Event != EventFeed {
+		t.Fatalf("Feed message must have been received, but was: %v", msg)
+	}
+
+	data := msg.Data.(FeedMessage)
+
+	if data.Feed.ID != feed.ID || data.Feed.Feed == "" || data.Count != 1 {
+		t.Fatalf("Feed message not as expected: %v", data)
+	}
+
+	if msg := receive(otherSub); msg != nil {
+		t.Fatalf("Feed message must not have been received without access, but was: %v", msg)
+	}
+}
+
+func TestDispatchRead(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	otherUser := testutil.CreateUser(t, orga, 321, "member@user.com")
+	feed := testutil.CreateFeed(t, orga, user, lang, true)
+	sub := Subscribe(context.NewEmviUserContext(orga, user.ID))
+	defer Unsubscribe(sub)
+	otherSub := Subscribe(context.NewEmviUserContext(orga, otherUser.ID))
+	defer Unsubscribe(otherSub)
+	dispatch(Event{Type: EventRead, OrganizationId: orga.ID, UserId: user.ID, FeedId: feed.ID, Read: true})
+	msg := receive(sub)
+
+	if msg == nil || msg.Event != EventRead {
+		t.Fatalf("Read message must have been received, but was: %v", msg)
+	}
+
+	data := msg.Data.(ReadMessage)
+
+	if data.FeedId != feed.ID || !data.Read || data.Count != 1 {
+		t.Fatalf("Read message not as expected: %v", data)
+	}
+
+	if msg := receive(otherSub); msg != nil {
+		t.Fatalf("Read message must only be received by the user, but was: %v", msg)
+	}
+}
+
+func TestDispatchArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	otherUser := testutil.CreateUser(t, orga, 321, "member@user.com")
+	observer := testutil.CreateUser(t, orga, 432, "observer@user.com")
+	article := testutil.CreateArticle(t, orga, user, lang, false, false)
+	testutil.CreateObservedObject(t, user, article, nil, nil)
+	testutil.CreateObservedObject(t, observer, article, nil, nil)
+	sub := Subscribe(context.NewEmviUserContext(orga, user.ID))
+	defer Unsubscribe(sub)
+	otherSub := Subscribe(context.NewEmviUserContext(orga, otherUser.ID))
+	defer Unsubscribe(otherSub)
+	observerSub := Subscribe(context.NewEmviUserContext(orga, observer.ID))
+	defer Unsubscribe(observerSub)
+	dispatch(Event{Type: EventArticle, OrganizationId: orga.ID, UserId: user.ID, ArticleId: article.ID, LanguageId: lang.ID, Version: 3})
+	msg := receive(sub)
+
+	if msg == nil || msg.Event != EventArticle {
+		t.Fatalf("Article message must have been received, but was: %v", msg)
+	}
+
+	data := msg.Data.(ArticleMessage)
+
+	if data.ArticleId != article.ID || data.LanguageId != lang.ID || data.Version != 3 || data.UserId != user.ID {
+		t.Fatalf("Article message not as expected: %v", data)
+	}
+
+	if msg := receive(otherSub); msg != nil {
+		t.Fatalf("Article message must only be received by observers, but was: %v", msg)
+	}
+
+	if msg := receive(observerSub); msg != nil {
+		t.Fatalf("Article message must only be received with access, but was: %v", msg)
+	}
+}
+
+func receive(sub *Subscription) *Message {
+	select {
+	case msg := <-sub.Messages():
+		return &msg
+	default:
+		return nil
+	}
+}
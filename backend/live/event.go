@@ -0,0 +1,64 @@
+package live
+
+import (
+	"emviwiki/shared/db"
+	"emviwiki/shared/model"
+	"encoding/json"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// Postgres notification channel all backend instances listen on
+	channel = "live_event"
+
+	EventFeed    = "feed"
+	EventRead    = "read"
+	EventArticle = "article"
+
+	// sent to all subscribers after the listener reconnected, as events might have been missed in between
+	EventSync = "sync"
+)
+
+// Event is published to all backend instances and dispatched to the subscribers of the organization.
+// Events contain IDs only, as the payload of Postgres notifications is limited
+// and the messages sent to the subscribers depend on their permissions and language.
+type Event struct {
+	Type           string  `json:"type"`
+	OrganizationId hide.ID `json:"organization_id"`
+	UserId         hide.ID `json:"user_id"`
+	FeedId         hide.ID `json:"feed_id"`
+	Public         bool    `json:"public"`
+	Read           bool    `json:"read"`
+	ArticleId      hide.ID `json:"article_id"`
+	LanguageId     hide.ID `json:"language_id"`
+	Version        int     `json:"version"`
+}
+
+// Publish sends the event to all backend instances.
+// When called within a transaction, the event is delivered once the transaction has been committed.
+func Publish(tx *sqlx.Tx, event Event) error {
+	payload, err := json.Marshal(event)
+
+	if err != nil {
+		if tx != nil {
+			db.Rollback(tx)
+		}
+
+		logbuch.Error("Error marshalling live event", logbuch.Fields{"err": err, "type": event.Type})
+		return err
+	}
+
+	return model.Notify(tx, channel, string(payload))
+}
+
+// PublishArticleUpdate publishes the new version of an article to the subscribers observing it.
+func PublishArticleUpdate(tx *sqlx.Tx, orgaId, userId hide.ID, content *model.ArticleContent) error {
+	return Publish(tx, Event{Type: EventArticle,
+		OrganizationId: orgaId,
+		UserId:         userId,
+		ArticleId:      content.ArticleId,
+		LanguageId:     content.LanguageId,
+		Version:        content.Version})
+}
@@ -0,0 +1,142 @@
+package live
+
+import (
+	"emviwiki/backend/context"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"sync"
+)
+
+const (
+	// number of messages buffered per subscription before messages are dropped
+	subscriptionBufferSize = 32
+)
+
+var (
+	subscriptions = newHub()
+)
+
+// Message is sent to a subscriber.
+// Data is one of FeedMessage, ReadMessage, ArticleMessage or empty for sync messages.
+type Message struct {
+	Event string
+	Data  interface{}
+}
+
+// Subscription receives the messages for a user of an organization.
+// The message channel is closed when the subscription is closed on shutdown.
+type Subscription struct {
+	ctx      context.EmviContext
+	messages chan Message
+}
+
+// Messages returns the channel the messages for this subscription are sent to.
+func (sub *Subscription) Messages() <-chan Message {
+	return sub.messages
+}
+
+type hub struct {
+	subscriptions map[hide.ID]map[*Subscription]struct{}
+	closed        bool
+	m             sync.RWMutex
+}
+
+func newHub() *hub {
+	return &hub{subscriptions: make(map[hide.ID]map[*Subscription]struct{})}
+}
+
+// Subscribe creates a new subscription for the user in given context.
+// The subscription must be removed by calling Unsubscribe once the subscriber disconnects.
+func Subscribe(ctx context.EmviContext) *Subscription {
+	return subscriptions.subscribe(ctx)
+}
+
+// Unsubscribe removes the subscription.
+func Unsubscribe(sub *Subscription) {
+	subscriptions.unsubscribe(sub)
+}
+
+func (h *hub) subscribe(ctx context.EmviContext) *Subscription {
+	sub := &Subscription{ctx: ctx, messages: make(chan Message, subscriptionBufferSize)}
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.closed {
+		close(sub.messages)
+		return sub
+	}
+
+	orgaSubs, ok := h.subscriptions[ctx.Organization.ID]
+
+	if !ok {
+		orgaSubs = make(map[*Subscription]struct{})
+		h.subscriptions[ctx.Organization.ID] = orgaSubs
+	}
+
+	orgaSubs[sub] = struct{}{}
+	return sub
+}
+
+func (h *hub) unsubscribe(sub *Subscription) {
+	h.m.Lock()
+	defer h.m.Unlock()
+	orgaSubs := h.subscriptions[sub.ctx.Organization.ID]
+	delete(orgaSubs, sub)
+
+	if len(orgaSubs) == 0 {
+		delete(h.subscriptions, sub.ctx.Organization.ID)
+	}
+}
+
+// Returns all subscriptions for the organization or all subscriptions if the organization ID is 0.
+func (h *hub) get(orgaId hide.ID) []*Subscription {
+	h.m.RLock()
+	defer h.m.RUnlock()
+	subs := make([]*Subscription, 0)
+
+	for id, orgaSubs := range h.subscriptions {
+		if orgaId == 0 || orgaId == id {
+			for sub := range orgaSubs {
+				subs = append(subs, sub)
+			}
+		}
+	}
+
+	return subs
+}
+
+// Sends the message without blocking the dispatcher.
+// Messages are dropped for subscribers not reading fast enough.
+func (h *hub) send(sub *Subscription, msg Message) {
+	h.m.RLock()
+	defer h.m.RUnlock()
+
+	if h.closed {
+		return
+	}
+
+	select {
+	case sub.messages <- msg:
+	default:
+		logbuch.Debug("Live message dropped", logbuch.Fields{"event": msg.Event, "organization_id": sub.ctx.Organization.ID, "user_id": sub.ctx.UserId})
+	}
+}
+
+// Closes all subscriptions, so that open streams are ended.
+func (h *hub) close() {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	for _, orgaSubs := range h.subscriptions {
+		for sub := range orgaSubs {
+			close(sub.messages)
+		}
+	}
+
+	h.subscriptions = make(map[hide.ID]map[*Subscription]struct{})
+	h.closed = true
+}
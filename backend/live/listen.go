@@ -0,0 +1,111 @@
+package live
+
+import (
+	"emviwiki/shared/db"
+	"encoding/json"
+	"github.com/emvi/logbuch"
+	"github.com/lib/pq"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// the connection is checked periodically, as lost connections are not always noticed otherwise
+	listenerPingInterval = time.Second * 90
+
+	// number of events waiting to be dispatched before new events are dropped
+	dispatchQueueSize = 1000
+)
+
+var (
+	listener *pq.Listener
+
+	// set if events have been dropped because the queue was full
+	dropped int32
+)
+
+// Listen starts listening for events published by all backend instances
+// and dispatches them to the subscribers of this instance.
+func Listen(data db.ConnectionData) {
+	listener = db.NewListener(data, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			logbuch.Error("Error in live event listener connection", logbuch.Fields{"err": err, "event": event})
+		}
+	})
+
+	if err := listener.Listen(channel); err != nil {
+		logbuch.Fatal("Error listening for live events", logbuch.Fields{"err": err})
+	}
+
+	queue := make(chan Event, dispatchQueueSize)
+	go dispatchQueue(queue)
+	go listen(listener, queue)
+}
+
+// Close stops listening for events and closes all subscriptions.
+func Close() {
+	if listener != nil {
+		if err := listener.Close(); err != nil {
+			logbuch.Error("Error closing live event listener", logbuch.Fields{"err": err})
+		}
+	}
+
+	subscriptions.close()
+}
+
+// Receives the events and passes them on to the queue, so that notifications are not blocked
+// by building the messages for the subscribers, which requires reading from the database.
+func listen(listener *pq.Listener, queue chan<- Event) {
+	defer close(queue)
+
+	for {
+		select {
+		case n, ok := <-listener.Notify:
+			if !ok {
+				return
+			}
+
+			// a nil notification is sent after the connection has been re-established
+			if n == nil {
+				enqueue(queue, Event{Type: EventSync})
+				continue
+			}
+
+			var event Event
+
+			if err := json.Unmarshal([]byte(n.Extra), &event); err != nil {
+				logbuch.Error("Error unmarshalling live event", logbuch.Fields{"err": err})
+				continue
+			}
+
+			enqueue(queue, event)
+		case <-time.After(listenerPingInterval):
+			go func() {
+				if err := listener.Ping(); err != nil {
+					logbuch.Debug("Error pinging live event listener connection", logbuch.Fields{"err": err})
+				}
+			}()
+		}
+	}
+}
+
+func enqueue(queue chan<- Event, event Event) {
+	select {
+	case queue <- event:
+	default:
+		logbuch.Warn("Live event queue full, dropping event", logbuch.Fields{"type": event.Type})
+		atomic.StoreInt32(&dropped, 1)
+	}
+}
+
+// Dispatches the events in order of arrival.
+// A sync event is dispatched after the queue has been drained if events have been dropped.
+func dispatchQueue(queue <-chan Event) {
+	for event := range queue {
+		dispatch(event)
+
+		if len(queue) == 0 && atomic.CompareAndSwapInt32(&dropped, 1, 0) {
+			dispatch(Event{Type: EventSync})
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package live
+
+import (
+	"emviwiki/backend/context"
+	"emviwiki/shared/model"
+	"testing"
+)
+
+func TestDispatchQueueDropped(t *testing.T) {
+	orga := &model.Organization{}
+	orga.ID = 1
+	sub := Subscribe(context.NewEmviUserContext(orga, 1))
+	defer Unsubscribe(sub)
+	queue := make(chan Event, 1)
+	enqueue(queue, Event{Type: EventSync})
+	enqueue(queue, Event{Type: EventSync})
+	close(queue)
+	dispatchQueue(queue)
+
+	// the second event was dropped, so another sync event is sent after the queue was drained
+	for i := 0; i < 2; i++ {
+		if msg := receive(sub); msg == nil || msg.Event != EventSync {
+			t.Fatalf("Sync message must have been received, but was: %v", msg)
+		}
+	}
+
+	if msg := receive(sub); msg != nil {
+		t.Fatalf("No more messages must have been received, but was: %v", msg)
+	}
+}
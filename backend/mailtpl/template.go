@@ -19,6 +19,7 @@ const (
 	CancelSubscriptionMailTemplate              = "mail_cancel_subscription.html"
 	DowngradeMailTemplate                       = "mail_expert_downgrade.html"
 	PaymentActionRequiredMailTemplate           = "mail_payment_action_required.html"
+	NotificationMailTemplate                    = "mail_notification.html"
 )
 
 var (
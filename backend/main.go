@@ -2,14 +2,21 @@ package main
 
 import (
 	"emviwiki/backend/api"
+	"emviwiki/backend/apidoc"
 	"emviwiki/backend/article"
+	"emviwiki/backend/article/schema"
 	"emviwiki/backend/billing"
 	"emviwiki/backend/content"
+	backendfeed "emviwiki/backend/feed"
+	"emviwiki/backend/inbound"
+	"emviwiki/backend/integration"
+	"emviwiki/backend/live"
 	"emviwiki/backend/mailtpl"
 	"emviwiki/backend/member"
 	"emviwiki/backend/newsletter"
 	"emviwiki/backend/organization"
 	"emviwiki/backend/support"
+	"emviwiki/backend/translate"
 	"emviwiki/shared/auth"
 	"emviwiki/shared/config"
 	"emviwiki/shared/db"
@@ -19,8 +26,28 @@ import (
 	"emviwiki/shared/model"
 	"emviwiki/shared/rest"
 	"emviwiki/shared/server"
+	"github.com/emvi/logbuch"
 	"github.com/gorilla/mux"
 	"net/http"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+const (
+	// run the backend with this argument to write the API reference to stdout instead of starting the server
+	apiDocArg = "apidoc"
+
+	// run the backend with this argument to write all stored article versions not matching the schema to stdout
+	schemaReportArg = "schemareport"
+
+	// the server write timeout doesn't apply to the live event stream
+	liveEventsPath = "/api/v1/feed/live"
+)
+
+var (
+	reference apidoc.Reference
 )
 
 func setupRouter() *mux.Router {
@@ -47,6 +74,8 @@ func setupRouter() *mux.Router {
 	router.Handle("/api/v1/newsletter", rest.ErrorMiddleware(api.UnsubscribeNewsletterHandler)).Methods(http.MethodDelete)
 	router.HandleFunc("/api/v1/content/{filename}", api.GetContentHandler).Methods(http.MethodGet)
 	router.HandleFunc("/api/v1/billing/webhook", api.StripeWebhookHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/integration/{id}/slack", api.SlackEventsHandler).Methods(http.MethodPost)
+	router.HandleFunc("/api/v1/inbound", api.InboundMailHandler).Methods(http.MethodPost)
 
 	// endpoints with context (organization) check -> wiki
 	addRoute(router, "/api/v1/organization", http.MethodGet, api.GetOrganizationHandler, false, false, "organization:r")
@@ -76,8 +105,13 @@ func setupRouter() *mux.Router {
 	addRoute(router, "/api/v1/member/{id}/admin", http.MethodPut, api.ToggleMemberAdminHandler, true, true)
 	addRoute(router, "/api/v1/member/{id}/readonly", http.MethodPut, api.ToggleReadOnlyHandler, false, true)
 	addRoute(router, "/api/v1/auth", http.MethodGet, api.AuthenticateUserHandler, false, false)
-	addRoute(router, "/api/v1/article", http.MethodPost, api.SaveArticleHandler, false, true)
+	addRoute(router, "/api/v1/article", http.MethodPost, api.SaveArticleHandler, false, true, "articles:rw")
 	addRoute(router, "/api/v1/article/content", http.MethodPost, api.UploadArticleAttachmentHandler, false, true)
+	addRoute(router, "/api/v1/article/content/upload", http.MethodPost, api.CreateAttachmentUploadHandler, false, true)
+	addRoute(router, "/api/v1/article/content/upload/{id}", http.MethodGet, api.ReadAttachmentUploadHandler, false, true)
+	addRoute(router, "/api/v1/article/content/upload/{id}", http.MethodPut, api.UploadAttachmentChunkHandler, false, true)
+	addRoute(router, "/api/v1/article/content/upload/{id}", http.MethodPost, api.CompleteAttachmentUploadHandler, false, true)
+	addRoute(router, "/api/v1/article/content/upload/{id}", http.MethodDelete, api.CancelAttachmentUploadHandler, false, true)
 	addRoute(router, "/api/v1/article/private", http.MethodGet, api.ReadPrivateArticlesHandler, false, false)
 	addRoute(router, "/api/v1/article/draft", http.MethodGet, api.ReadDraftsHandler, false, false)
 	addRoute(router, "/api/v1/article/invite", http.MethodPut, api.InviteEditArticleHandler, false, true)
@@ -86,18 +120,27 @@ func setupRouter() *mux.Router {
 	addRoute(router, "/api/v1/article/{id}", http.MethodDelete, api.DeleteArticleHandler, false, true)
 	addRoute(router, "/api/v1/article/{id}/preview", http.MethodGet, api.ReadArticlePreviewHandler, false, false, "articles:r")
 	addRoute(router, "/api/v1/article/{id}/history", http.MethodGet, api.ReadArticleHistoryHandler, false, false, "articles:r", "article_history:r")
+	addRoute(router, "/api/v1/article/{id}/statistics", http.MethodGet, api.ReadArticleStatisticsHandler, false, false)
 	addRoute(router, "/api/v1/article/{id}/recommendation", http.MethodPost, api.RecommendArticleHandler, false, false)
 	addRoute(router, "/api/v1/article/{id}/recommendation", http.MethodPut, api.ConfirmRecommendationHandler, false, false)
+	addRoute(router, "/api/v1/article/{id}/campaign", http.MethodPost, api.CreateReadingCampaignHandler, false, false)
+	addRoute(router, "/api/v1/article/{id}/campaign", http.MethodGet, api.ReadReadingCampaignsHandler, false, false)
 	addRoute(router, "/api/v1/article/{id}/invite", http.MethodPut, api.InviteEditArticleHandler, false, true)
 	addRoute(router, "/api/v1/article/{id}/archive", http.MethodPut, api.ArchiveArticleHandler, false, true)
 	addRoute(router, "/api/v1/article/{id}/reset", http.MethodPut, api.ResetArticleHandler, false, true)
+	addRoute(router, "/api/v1/article/{id}/patch", http.MethodPut, api.PatchArticleHandler, false, true, "articles:rw")
 	addRoute(router, "/api/v1/article/{id}/copy", http.MethodPut, api.CopyArticleHandler, false, true)
+	addRoute(router, "/api/v1/article/{id}/translate", http.MethodPut, api.TranslateArticleHandler, true, true)
 	addRoute(router, "/api/v1/article/{id}/list", http.MethodPost, api.AddArticleToListHandler, false, true)
 	addRoute(router, "/api/v1/article/{id}/export", http.MethodGet, api.ExportArticleHandler, false, false)
 	addRoute(router, "/api/v1/lang", http.MethodGet, api.GetLangsHandler, false, false, "language:r")
 	addRoute(router, "/api/v1/lang", http.MethodPost, api.AddLangHandler, true, true)
 	addRoute(router, "/api/v1/lang", http.MethodPut, api.SwitchDefaultLangHandler, false, true)
 	addRoute(router, "/api/v1/lang/{id}", http.MethodGet, api.GetLangHandler, false, false, "language:r")
+	addRoute(router, "/api/v1/lang/{id}/translator", http.MethodGet, api.ReadTranslatorsHandler, true, false)
+	addRoute(router, "/api/v1/lang/{id}/translator", http.MethodPost, api.AddTranslatorHandler, true, true)
+	addRoute(router, "/api/v1/lang/{id}/translator", http.MethodDelete, api.RemoveTranslatorHandler, true, true)
+	addRoute(router, "/api/v1/translation", http.MethodGet, api.ReadTranslationStatusHandler, true, false)
 	addRoute(router, "/api/v1/usergroup", http.MethodPost, api.SaveUserGroupHandler, true, true)
 	addRoute(router, "/api/v1/usergroup/{id}", http.MethodDelete, api.DeleteUserGroupHandler, true, true)
 	addRoute(router, "/api/v1/usergroup/{id}", http.MethodGet, api.GetUserGroupHandler, false, false)
@@ -114,6 +157,14 @@ func setupRouter() *mux.Router {
 	addRoute(router, "/api/v1/search/list", http.MethodGet, api.SearchArticleListHandler, false, false, "lists:r", "search_lists:r")
 	addRoute(router, "/api/v1/feed", http.MethodGet, api.GetFilteredFeedHandler, false, false)
 	addRoute(router, "/api/v1/feed", http.MethodPut, api.ToggleNotificationReadHandler, false, false)
+	addRoute(router, "/api/v1/feed/changes", http.MethodGet, api.GetChangedArticlesHandler, false, false)
+	addRoute(router, liveEventsPath, http.MethodGet, api.LiveEventsHandler, false, false)
+	addRoute(router, "/api/v1/campaign", http.MethodGet, api.ReadUnconfirmedReadingCampaignsHandler, false, false)
+	addRoute(router, "/api/v1/campaign/{id}", http.MethodGet, api.ReadReadingCampaignReportHandler, false, false)
+	addRoute(router, "/api/v1/campaign/{id}", http.MethodPut, api.ConfirmReadingCampaignHandler, false, false)
+	addRoute(router, "/api/v1/campaign/{id}", http.MethodDelete, api.DeleteReadingCampaignHandler, false, false)
+	addRoute(router, "/api/v1/trash", http.MethodGet, api.ReadTrashHandler, false, false)
+	addRoute(router, "/api/v1/trash/{id}", http.MethodPut, api.RestoreTrashHandler, false, true)
 	addRoute(router, "/api/v1/observe", http.MethodPost, api.ObserveObjectHandler, false, false)
 	addRoute(router, "/api/v1/observe", http.MethodGet, api.ReadObservedHandler, false, false)
 	addRoute(router, "/api/v1/bookmark", http.MethodPost, api.BookmarkHandler, false, false)
@@ -129,12 +180,12 @@ func setupRouter() *mux.Router {
 	addRoute(router, "/api/v1/articlelist/{id}/member", http.MethodDelete, api.RemoveArticleListMemberHandler, false, true)
 	addRoute(router, "/api/v1/articlelist/{id}/member", http.MethodPut, api.ToggleArticleListModeratorHandler, false, true)
 	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodGet, api.GetArticleListEntriesHandler, false, false, "lists:r", "articles:r")
-	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodPost, api.AddArticleListEntryHandler, false, true)
-	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodDelete, api.RemoveArticleListEntryHandler, false, true)
-	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodPut, api.SortArticleListEntryHandler, false, true)
-	addRoute(router, "/api/v1/tag", http.MethodPost, api.AddTagHandler, false, true)
-	addRoute(router, "/api/v1/tag", http.MethodPut, api.RenameTagHandler, false, true)
-	addRoute(router, "/api/v1/tag", http.MethodDelete, api.RemoveTagHandler, false, true)
+	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodPost, api.AddArticleListEntryHandler, false, true, "lists:rw")
+	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodDelete, api.RemoveArticleListEntryHandler, false, true, "lists:rw")
+	addRoute(router, "/api/v1/articlelist/{id}/entry", http.MethodPut, api.SortArticleListEntryHandler, false, true, "lists:rw")
+	addRoute(router, "/api/v1/tag", http.MethodPost, api.AddTagHandler, false, true, "tags:rw")
+	addRoute(router, "/api/v1/tag", http.MethodPut, api.RenameTagHandler, false, true, "tags:rw")
+	addRoute(router, "/api/v1/tag", http.MethodDelete, api.RemoveTagHandler, false, true, "tags:rw")
 	addRoute(router, "/api/v1/tag", http.MethodGet, api.ValidateTagHandler, false, true)
 	addRoute(router, "/api/v1/tag/{id}", http.MethodDelete, api.DeleteTagHandler, false, true)
 	addRoute(router, "/api/v1/tag/{name}", http.MethodGet, api.GetTagByNameHandler, false, false, "tags:r")
@@ -148,6 +199,27 @@ func setupRouter() *mux.Router {
 	addRoute(router, "/api/v1/client/{id}", http.MethodGet, api.ReadClientHandler, false, false)
 	addRoute(router, "/api/v1/client/{id}", http.MethodPost, api.SaveClientHandler, true, false)
 	addRoute(router, "/api/v1/client/{id}", http.MethodDelete, api.DeleteClientHandler, true, false)
+	addRoute(router, "/api/v1/accesstoken", http.MethodGet, api.ReadAccessTokensHandler, true, false)
+	addRoute(router, "/api/v1/accesstoken", http.MethodPost, api.SaveAccessTokenHandler, true, false)
+	addRoute(router, "/api/v1/accesstoken/{id}", http.MethodDelete, api.DeleteAccessTokenHandler, true, false)
+	addRoute(router, "/api/v1/serviceaccount", http.MethodGet, api.ReadServiceAccountsHandler, true, false)
+	addRoute(router, "/api/v1/serviceaccount", http.MethodPost, api.SaveServiceAccountHandler, true, false)
+	addRoute(router, "/api/v1/serviceaccount/{id}", http.MethodDelete, api.DeleteServiceAccountHandler, true, false)
+	addRoute(router, "/api/v1/serviceaccount/{id}/accesstoken", http.MethodGet, api.ReadServiceAccountAccessTokensHandler, true, false)
+	addRoute(router, "/api/v1/serviceaccount/{id}/accesstoken", http.MethodPost, api.SaveServiceAccountAccessTokenHandler, true, false)
+	addRoute(router, "/api/v1/customfield", http.MethodGet, api.ReadCustomFieldsHandler, false, false)
+	addRoute(router, "/api/v1/customfield", http.MethodPost, api.SaveCustomFieldHandler, true, false)
+	addRoute(router, "/api/v1/customfield/{id}", http.MethodDelete, api.DeleteCustomFieldHandler, true, false)
+	addRoute(router, "/api/v1/integration", http.MethodGet, api.ReadIntegrationsHandler, false, false)
+	addRoute(router, "/api/v1/integration", http.MethodPost, api.SaveIntegrationHandler, true, false)
+	addRoute(router, "/api/v1/integration/account", http.MethodGet, api.ReadChatAccountsHandler, false, false)
+	addRoute(router, "/api/v1/integration/{id}", http.MethodDelete, api.DeleteIntegrationHandler, true, false)
+	addRoute(router, "/api/v1/integration/{id}/account", http.MethodPost, api.LinkChatAccountHandler, false, false)
+	addRoute(router, "/api/v1/integration/{id}/account", http.MethodPut, api.ConfirmChatAccountHandler, false, false)
+	addRoute(router, "/api/v1/integration/{id}/account", http.MethodDelete, api.UnlinkChatAccountHandler, false, false)
+	addRoute(router, "/api/v1/inbound/address", http.MethodGet, api.ReadInboundMailAddressesHandler, false, false)
+	addRoute(router, "/api/v1/inbound/address", http.MethodPost, api.CreateInboundMailAddressHandler, false, false)
+	addRoute(router, "/api/v1/inbound/address/{id}", http.MethodDelete, api.DeleteInboundMailAddressHandler, false, false)
 	addRoute(router, "/api/v1/urlmeta", http.MethodGet, api.GetLinkMetaDataHandler, false, false)
 
 	return router
@@ -155,11 +227,40 @@ func setupRouter() *mux.Router {
 
 func addRoute(router *mux.Router, path, method string, handler api.AuthHandler, requireExpert, requireWritePermissions bool, scopes ...string) {
 	router.Handle(path, api.AuthMiddleware(handler, true, requireExpert, requireWritePermissions, scopes...)).Methods(method)
+	reference.Add(apidoc.Route{Path: path,
+		Method:                  method,
+		Handler:                 getHandlerName(handler),
+		RequireExpert:           requireExpert,
+		RequireWritePermissions: requireWritePermissions,
+		Scopes:                  scopes})
+}
+
+func getHandlerName(handler api.AuthHandler) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+func writeAPIReference() {
+	setupRouter()
+
+	if err := reference.Markdown(os.Stdout); err != nil {
+		panic(err)
+	}
+}
+
+func writeSchemaReport() {
+	if err := schema.WriteValidationReport(os.Stdout); err != nil {
+		logbuch.Fatal("Error writing schema validation report", logbuch.Fields{"err": err})
+	}
 }
 
 func connectDB() *db.Connection {
+	return db.NewConnection(getConnectionData())
+}
+
+func getConnectionData() db.ConnectionData {
 	backend := config.Get().BackendDB
-	return db.NewConnection(db.ConnectionData{
+	return db.ConnectionData{
 		Host:               backend.Host,
 		Port:               backend.Port,
 		User:               backend.User,
@@ -170,16 +271,22 @@ func connectDB() *db.Connection {
 		SSLKey:             backend.SSLKey,
 		SSLRootCert:        backend.SSLRootCert,
 		MaxOpenConnections: backend.MaxOpenConnections,
-	})
+	}
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == apiDocArg {
+		writeAPIReference()
+		return
+	}
+
 	config.Load()
 	stdout, stderr := server.ConfigureLogging()
 	defer server.CloseLogger(stdout, stderr)
 	db.Migrate()
 	auth.LoadConfig()
 	feed.LoadConfig()
+	backendfeed.LoadConfig()
 	mail.LoadConfig()
 	i18n.LoadConfig()
 	api.LoadConfig()
@@ -190,12 +297,22 @@ func main() {
 	organization.LoadConfig()
 	support.LoadConfig()
 	billing.LoadConfig()
+	integration.LoadConfig()
+	inbound.LoadConfig()
+	translate.LoadConfig()
 	article.InitTemplates()
 	mailtpl.InitTemplates()
 	connection := connectDB()
 	model.SetConnection(connection)
 	defer connection.Disconnect()
+
+	if len(os.Args) > 1 && os.Args[1] == schemaReportArg {
+		writeSchemaReport()
+		return
+	}
+
+	live.Listen(getConnectionData())
 	router := setupRouter()
 	cors := server.ConfigureCors(router)
-	server.Start(cors, nil)
+	server.Start(cors, live.Close, liveEventsPath)
 }
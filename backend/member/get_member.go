@@ -2,10 +2,13 @@ package member
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/shared/feed"
 	"emviwiki/shared/model"
 	"github.com/emvi/hide"
 )
 
+// GetMember returns the organization member for given user.
+// The notification preferences contain the effective delivery for all categories, including defaults.
 func GetMember(organization *model.Organization, userId hide.ID) (*model.OrganizationMember, error) {
 	member := model.GetOrganizationMemberByOrganizationIdAndUserId(organization.ID, userId)
 
@@ -13,5 +16,16 @@ func GetMember(organization *model.Organization, userId hide.ID) (*model.Organiz
 		return nil, errs.MemberNotFound
 	}
 
+	prefs := model.FindNotificationPreferenceByOrganizationMemberId(member.ID)
+	member.NotificationPreferences = make([]model.NotificationPreference, 0, len(feed.Categories))
+
+	for _, category := range feed.Categories {
+		member.NotificationPreferences = append(member.NotificationPreferences, model.NotificationPreference{
+			OrganizationMemberId: member.ID,
+			Category:             category,
+			Delivery:             feed.NotificationDelivery(member, prefs, category),
+		})
+	}
+
 	return member, nil
 }
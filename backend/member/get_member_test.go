@@ -2,6 +2,7 @@ package member
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/shared/feed"
 	"emviwiki/shared/testutil"
 	"testing"
 )
@@ -14,7 +15,13 @@ func TestGetMember(t *testing.T) {
 		t.Fatalf("Member must not be found, but was: %v", err)
 	}
 
-	if member, err := GetMember(orga, user.ID); err != nil || member == nil {
+	member, err := GetMember(orga, user.ID)
+
+	if err != nil || member == nil {
 		t.Fatalf("Member must not found, but was: %v", err)
 	}
+
+	if len(member.NotificationPreferences) != len(feed.Categories) {
+		t.Fatalf("Notification preferences must contain all categories, but was: %v", member.NotificationPreferences)
+	}
 }
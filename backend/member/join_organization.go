@@ -230,7 +230,8 @@ func createMember(tx *sqlx.Tx, orgaId, userId hide.ID, username string, readOnly
 			Active:                    true,
 			ShowCreateButton:          true,
 			ShowActionButtons:         true,
-			ShowNavigation:            true}
+			ShowNavigation:            true,
+			Timezone:                  model.DefaultTimezone}
 	} else {
 		member.Username = username
 		member.Active = true
@@ -40,6 +40,11 @@ func LeaveOrganization(orga *model.Organization, userId hide.ID, orgaName string
 		return errs.Saving
 	}
 
+	if err := model.DeleteAccessTokenByOrganizationIdAndUserId(tx, orga.ID, userId); err != nil {
+		logbuch.Error("Error deleting access tokens when leaving organization", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId})
+		return errs.Saving
+	}
+
 	if err := updateObjectPermissionsForInactiveUser(tx, orga, userId, false); err != nil {
 		return err
 	}
@@ -34,6 +34,11 @@ func RemoveMember(orga *model.Organization, userId, memberUserId hide.ID, remove
 		return errs.Saving
 	}
 
+	if err := model.DeleteAccessTokenByOrganizationIdAndUserId(tx, orga.ID, memberUserId); err != nil {
+		logbuch.Error("Error deleting access tokens when removing member", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": memberUserId})
+		return errs.Saving
+	}
+
 	if err := updateObjectPermissionsForInactiveUser(tx, orga, memberUserId, removePermissions); err != nil {
 		return err
 	}
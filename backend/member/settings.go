@@ -2,9 +2,12 @@ package member
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/shared/feed"
 	"emviwiki/shared/model"
 	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
 	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -29,6 +32,10 @@ type Settings struct {
 	ShowCreateButton          *bool   `json:"show_create_button"`
 	ShowNavigation            *bool   `json:"show_navigation"`
 	ShowActionButtons         *bool   `json:"show_action_buttons"`
+	Timezone                  *string `json:"timezone"`
+
+	// NotificationPreferences maps notification categories to their delivery.
+	NotificationPreferences map[string]string `json:"notification_preferences"`
 }
 
 func (data *Settings) validate() []error {
@@ -61,6 +68,22 @@ func (data *Settings) validate() []error {
 		}
 	}
 
+	if data.Timezone != nil && !feed.CheckTimezone(*data.Timezone) {
+		err = append(err, errs.TimezoneInvalid)
+	}
+
+	for category, delivery := range data.NotificationPreferences {
+		if !containsString(feed.Categories, category) {
+			err = append(err, errs.NotificationCategoryInvalid)
+			break
+		}
+
+		if !containsString(feed.NotificationDeliveries, delivery) {
+			err = append(err, errs.NotificationDeliveryInvalid)
+			break
+		}
+	}
+
 	if len(err) == 0 {
 		return nil
 	}
@@ -73,6 +96,7 @@ func SaveSettings(organization *model.Organization, userId hide.ID, data Setting
 	data.Phone = trimString(data.Phone)
 	data.Mobile = trimString(data.Mobile)
 	data.Info = trimString(data.Info)
+	data.Timezone = trimString(data.Timezone)
 
 	if err := data.validate(); err != nil {
 		return err
@@ -98,7 +122,15 @@ func SaveSettings(organization *model.Organization, userId hide.ID, data Setting
 
 	if data.SendNotificationsInterval != nil {
 		member.SendNotificationsInterval = *data.SendNotificationsInterval
-		member.NextNotificationMail = time.Now().Add(time.Hour * 24 * time.Duration(*data.SendNotificationsInterval))
+	}
+
+	if data.Timezone != nil {
+		member.Timezone = *data.Timezone
+	}
+
+	// reschedule the digest, as the notifications it contains or the time it is sent at might have changed
+	if data.SendNotificationsInterval != nil || data.Timezone != nil || len(data.NotificationPreferences) != 0 {
+		member.NextNotificationMail = feed.NextDigest(time.Now(), member.Timezone)
 	}
 
 	if data.RecommendationMail != nil {
@@ -117,10 +149,44 @@ func SaveSettings(organization *model.Organization, userId hide.ID, data Setting
 		member.ShowActionButtons = *data.ShowActionButtons
 	}
 
-	if err := model.SaveOrganizationMember(nil, member); err != nil {
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		logbuch.Error("Error starting transaction to save member settings", logbuch.Fields{"err": err})
+		return []error{errs.TxBegin}
+	}
+
+	if err := model.SaveOrganizationMember(tx, member); err != nil {
 		return []error{errs.Saving}
 	}
 
+	if err := saveNotificationPreferences(tx, member, data.NotificationPreferences); err != nil {
+		return []error{err}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logbuch.Error("Error committing transaction to save member settings", logbuch.Fields{"err": err})
+		return []error{errs.TxCommit}
+	}
+
+	return nil
+}
+
+func saveNotificationPreferences(tx *sqlx.Tx, member *model.OrganizationMember, prefs map[string]string) error {
+	for category, delivery := range prefs {
+		pref := model.GetNotificationPreferenceByOrganizationMemberIdAndCategoryTx(tx, member.ID, category)
+
+		if pref == nil {
+			pref = &model.NotificationPreference{OrganizationMemberId: member.ID, Category: category}
+		}
+
+		pref.Delivery = delivery
+
+		if err := model.SaveNotificationPreference(tx, pref); err != nil {
+			return errs.Saving
+		}
+	}
+
 	return nil
 }
 
@@ -2,9 +2,11 @@ package member
 
 import (
 	"emviwiki/backend/errs"
+	"emviwiki/shared/feed"
 	"emviwiki/shared/model"
 	"emviwiki/shared/testutil"
 	"testing"
+	"time"
 )
 
 func TestSaveSettings(t *testing.T) {
@@ -14,17 +16,24 @@ func TestSaveSettings(t *testing.T) {
 	mobile := "0123456789012345678901234567891"
 	info := "01234567890123456789012345678901234567890123456789012345678901234567890123456789012345678901234567891"
 	interval := uint(2)
+	timezone := "Mars/Olympus_Mons"
 	input := []Settings{
 		Settings{Phone: &phone},
 		Settings{Mobile: &mobile},
 		Settings{Info: &info},
 		Settings{SendNotificationsInterval: &interval},
+		Settings{Timezone: &timezone},
+		Settings{NotificationPreferences: map[string]string{"unknown": model.NotificationDeliveryDaily}},
+		Settings{NotificationPreferences: map[string]string{feed.CategoryMentions: "hourly"}},
 	}
 	expected := []error{
 		errs.PhoneLen,
 		errs.MobileLen,
 		errs.InfoTooLong,
 		errs.NotificationIntervalInvalid,
+		errs.TimezoneInvalid,
+		errs.NotificationCategoryInvalid,
+		errs.NotificationDeliveryInvalid,
 	}
 
 	for i, in := range input {
@@ -79,6 +88,50 @@ func TestSaveSettingsSuccess(t *testing.T) {
 	}
 }
 
+func TestSaveSettingsNotificationPreferences(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	timezone := "Europe/Berlin"
+	data := Settings{
+		Timezone: &timezone,
+		NotificationPreferences: map[string]string{
+			feed.CategoryMentions: model.NotificationDeliveryInstant,
+			feed.CategoryGroups:   model.NotificationDeliveryApp,
+		},
+	}
+
+	if err := SaveSettings(orga, user.ID, data); err != nil {
+		t.Fatalf("Member must have been saved, but was: %v", err)
+	}
+
+	data.NotificationPreferences = map[string]string{feed.CategoryMentions: model.NotificationDeliveryWeekly}
+
+	if err := SaveSettings(orga, user.ID, data); err != nil {
+		t.Fatalf("Member must have been saved, but was: %v", err)
+	}
+
+	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, user.ID)
+
+	if member.Timezone != "Europe/Berlin" {
+		t.Fatalf("Time zone must have been updated, but was: %v", member.Timezone)
+	}
+
+	if !member.NextNotificationMail.After(time.Now()) || member.NextNotificationMail.After(time.Now().Add(time.Hour*24)) {
+		t.Fatalf("Next notification mail must be scheduled within the next day, but was: %v", member.NextNotificationMail)
+	}
+
+	prefs := model.FindNotificationPreferenceByOrganizationMemberId(member.ID)
+
+	if len(prefs) != 2 {
+		t.Fatalf("Two notification preferences must have been saved, but was: %v", len(prefs))
+	}
+
+	if feed.NotificationDelivery(member, prefs, feed.CategoryMentions) != model.NotificationDeliveryWeekly ||
+		feed.NotificationDelivery(member, prefs, feed.CategoryGroups) != model.NotificationDeliveryApp {
+		t.Fatalf("Notification preferences not as expected: %v", prefs)
+	}
+}
+
 func TestSaveSettingsUpdateNil(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
@@ -203,7 +203,8 @@ func CreateOrganization(userId hide.ID, data CreateOrganizationData) []error {
 		ShowActionButtons:         true,
 		ShowNavigation:            true,
 		ShowCreateButton:          true,
-		Active:                    true}
+		Active:                    true,
+		Timezone:                  model.DefaultTimezone}
 
 	if err := model.SaveOrganizationMember(tx, member); err != nil {
 		return []error{errs.Saving}
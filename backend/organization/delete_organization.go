@@ -7,7 +7,6 @@ import (
 	"emviwiki/shared/auth"
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
-	"path/filepath"
 
 	"emviwiki/backend/errs"
 	"emviwiki/shared/model"
@@ -36,6 +35,7 @@ func DeleteOrganization(orgaId, userId hide.ID, name string, auth auth.AuthClien
 
 	// read files before deleting organization to enable rollback
 	files := model.FindFileByOrganizationId(orgaId)
+	uploadSessions := model.FindUploadSessionByOrganizationId(orgaId)
 
 	if err := deleteClients(organization, userId, auth); err != nil {
 		logbuch.Error("Error deleting organization clients while deleting organization", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
@@ -48,6 +48,7 @@ func DeleteOrganization(orgaId, userId hide.ID, name string, auth auth.AuthClien
 	}
 
 	deleteOrganizationFiles(organization, userId, files)
+	go content.DeleteUploadSessionChunks(uploadSessions)
 	return nil
 }
 
@@ -65,8 +66,16 @@ func deleteClients(orga *model.Organization, userId hide.ID, auth auth.AuthClien
 
 func deleteOrganizationFiles(orga *model.Organization, userId hide.ID, files []model.File) {
 	go func() {
+		deleted := make(map[string]bool)
+
 		for _, file := range files {
-			content.DeleteFileInStore(orga.ID, userId, filepath.Join(file.Path, file.UniqueName))
+			// deduplicated files share the same path
+			path := file.StorePath()
+
+			if !deleted[path] {
+				content.DeleteFileInStore(orga.ID, userId, path)
+				deleted[path] = true
+			}
 		}
 	}()
 
@@ -22,10 +22,6 @@ func UploadOrganizationPicture(r *http.Request, orgaId, userId hide.ID) error {
 		return errs.PermissionDenied
 	}
 
-	if err := deleteOrganizationPicture(organization, userId); err != nil {
-		logbuch.Debug("Error deleting old organization picture", logbuch.Fields{"err": err})
-	}
-
 	uniqueName, err := content.UploadFile(&content.File{
 		Request:       r,
 		Organization:  organization,
@@ -34,10 +30,17 @@ func UploadOrganizationPicture(r *http.Request, orgaId, userId hide.ID) error {
 		RequiresImage: true,
 	})
 
-	if err != nil {
+	if err == errs.MaxStorageReached {
+		return err
+	} else if err != nil {
 		return errs.UploadingFile
 	}
 
+	// the old picture is deleted after the new one has been uploaded, so that it is kept if the upload fails
+	if err := deleteOrganizationPicture(organization, userId); err != nil {
+		logbuch.Debug("Error deleting old organization picture", logbuch.Fields{"err": err})
+	}
+
 	organization.Picture = null.NewString(uniqueName, true)
 
 	if err := model.SaveOrganization(nil, organization); err != nil {
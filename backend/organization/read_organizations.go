@@ -5,9 +5,16 @@ import (
 	"emviwiki/backend/errs"
 	"emviwiki/backend/perm"
 	"emviwiki/shared/model"
+	"emviwiki/shared/util"
 	"github.com/emvi/hide"
 )
 
+const (
+	maxMostViewedArticles = 10
+	maxSearchQueries      = 10
+	maxStorageUsage       = 10
+)
+
 type Statistics struct {
 	ArticleCount        int   `json:"article_count"`
 	ListCount           int   `json:"list_count"`
@@ -17,6 +24,17 @@ type Statistics struct {
 	TagCount            int   `json:"tag_count"`
 	StorageUsage        int64 `json:"storage_usage"`
 	MaxStorage          int64 `json:"max_storage"`
+
+	ReaderCount        int                                  `json:"reader_count"`
+	ViewsPerDay        []model.ArticleVisitStatistic        `json:"views_per_day"`
+	MostViewedArticles []model.ArticleVisitArticleStatistic `json:"most_viewed_articles"`
+	SearchQueries      []model.ArticleSearchQueryStatistic  `json:"search_queries"`
+
+	StorageSoftLimitReached bool                        `json:"storage_soft_limit_reached"`
+	StoragePerDay           []model.StorageUsage        `json:"storage_per_day"`
+	StorageByArticle        []model.StorageUsageArticle `json:"storage_by_article"`
+	StorageByUser           []model.StorageUsageUser    `json:"storage_by_user"`
+	LargestFiles            []model.StorageUsageFile    `json:"largest_files"`
 }
 
 func ReadOrganizations(userId hide.ID) []model.Organization {
@@ -39,11 +57,15 @@ func ReadOrganization(ctx context.EmviContext) (*model.Organization, error) {
 	return organization, nil
 }
 
-func GetOrganizationStatistics(orga *model.Organization, userId hide.ID) (*Statistics, error) {
+// GetOrganizationStatistics returns the organization statistics, reading analytics and storage growth over the past number of days.
+func GetOrganizationStatistics(orga *model.Organization, userId hide.ID, days int) (*Statistics, error) {
 	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
 		return nil, err
 	}
 
+	langId := util.DetermineLang(nil, orga.ID, userId, 0).ID
+	defTime := util.GetStatisticsStartTime(days)
+	storageUsage := model.GetFileStorageUsageByOrganizationId(orga.ID)
 	return &Statistics{
 		model.CountArticleByOrganizationId(orga.ID),
 		model.CountArticleListByOrganizationId(orga.ID),
@@ -51,7 +73,16 @@ func GetOrganizationStatistics(orga *model.Organization, userId hide.ID) (*Stati
 		model.CountOrganizationMemberByOrganizationIdAndActiveAndNotReadOnly(orga.ID),
 		model.CountUserGroupByOrganizationId(orga.ID),
 		model.CountTagByOrganizationId(orga.ID),
-		model.GetFileStorageUsageByOrganizationId(orga.ID),
+		storageUsage,
 		orga.MaxStorageGB,
+		model.CountArticleVisitReaderByOrganizationIdAndDefTimeAfter(orga.ID, defTime),
+		model.FindArticleVisitStatisticByOrganizationIdAndDefTimeAfter(orga.ID, defTime),
+		model.FindArticleVisitArticleStatisticByOrganizationIdAndLanguageIdAndDefTimeAfterLimit(orga.ID, langId, defTime, maxMostViewedArticles),
+		model.FindArticleSearchQueryStatisticByOrganizationIdAndDefTimeAfterLimit(orga.ID, defTime, maxSearchQueries),
+		util.IsStorageSoftLimitReached(orga, storageUsage),
+		model.FindStorageUsageByOrganizationIdAndDateAfter(orga.ID, defTime.AddDate(0, 0, -1)),
+		model.FindFileStorageUsageArticleByOrganizationIdAndLanguageIdLimit(orga.ID, langId, maxStorageUsage),
+		model.FindFileStorageUsageUserByOrganizationIdLimit(orga.ID, maxStorageUsage),
+		model.FindFileStorageUsageFileByOrganizationIdAndLanguageIdLimit(orga.ID, langId, maxStorageUsage),
 	}, nil
 }
@@ -6,7 +6,9 @@ import (
 	"emviwiki/shared/constants"
 	"emviwiki/shared/model"
 	"emviwiki/shared/testutil"
+	"emviwiki/shared/util"
 	"testing"
+	"time"
 )
 
 func TestReadOrganizations(t *testing.T) {
@@ -52,18 +54,20 @@ func TestGetOrganizationStatistics(t *testing.T) {
 	}
 
 	lang := testutil.CreateLang(t, orga, "en", "English", true)
-	testutil.CreateArticle(t, orga, user, lang, true, true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	testutil.CreateArticleVisit(t, article, user)
+	testutil.CreateArticleVisit(t, article, user2)
 	testutil.CreateArticleList(t, orga, user, lang, true)
 	testutil.CreateUserGroup(t, orga, "group")
 	testutil.CreateTag(t, orga, "tag")
 
-	_, err := GetOrganizationStatistics(orga, user2.ID)
+	_, err := GetOrganizationStatistics(orga, user2.ID, 0)
 
 	if err != errs.PermissionDenied {
 		t.Fatalf("Expected permission denied, but was: %v", err)
 	}
 
-	statistics, err := GetOrganizationStatistics(orga, user.ID)
+	statistics, err := GetOrganizationStatistics(orga, user.ID, 0)
 
 	if err != nil {
 		t.Fatalf("Statistics must be returned, but was: %v", err)
@@ -79,4 +83,56 @@ func TestGetOrganizationStatistics(t *testing.T) {
 		int(statistics.StorageUsage) != 0 {
 		t.Fatalf("Statistics not as expected: %v", statistics)
 	}
+
+	if statistics.ReaderCount != 2 ||
+		len(statistics.ViewsPerDay) != util.DefaultStatisticsDays ||
+		statistics.ViewsPerDay[util.DefaultStatisticsDays-1].Views != 2 ||
+		len(statistics.MostViewedArticles) != 1 ||
+		statistics.MostViewedArticles[0].ArticleId != article.ID {
+		t.Fatalf("Reading statistics not as expected: %v", statistics)
+	}
+}
+
+func TestGetOrganizationStatisticsStorage(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	testutil.CreateFile(t, orga, user, article, "")
+	testutil.CreateFile(t, orga, user, article, "")
+	testutil.CreateFile(t, orga, user, nil, "room")
+	testutil.CreateFile(t, nil, user, nil, "")
+	usage := &model.StorageUsage{OrganizationId: orga.ID, Date: time.Now(), Size: 126, Files: 3}
+
+	if err := model.SaveStorageUsage(nil, usage); err != nil {
+		t.Fatal(err)
+	}
+
+	statistics, err := GetOrganizationStatistics(orga, user.ID, 0)
+
+	if err != nil {
+		t.Fatalf("Statistics must be returned, but was: %v", err)
+	}
+
+	if statistics.StorageUsage != 126 || statistics.StorageSoftLimitReached || len(statistics.StoragePerDay) != 1 {
+		t.Fatalf("Storage usage not as expected: %v", statistics)
+	}
+
+	if len(statistics.StorageByArticle) != 1 ||
+		statistics.StorageByArticle[0].ArticleId != article.ID ||
+		statistics.StorageByArticle[0].Size != 84 ||
+		statistics.StorageByArticle[0].Files != 2 {
+		t.Fatalf("Storage usage by article not as expected: %v", statistics.StorageByArticle)
+	}
+
+	if len(statistics.StorageByUser) != 1 ||
+		statistics.StorageByUser[0].UserId != user.ID ||
+		statistics.StorageByUser[0].Size != 126 ||
+		statistics.StorageByUser[0].Files != 3 {
+		t.Fatalf("Storage usage by user not as expected: %v", statistics.StorageByUser)
+	}
+
+	if len(statistics.LargestFiles) != 3 {
+		t.Fatalf("Largest files not as expected: %v", statistics.LargestFiles)
+	}
 }
@@ -0,0 +1,348 @@
+package prosemirror
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// contentExpr is a compiled content expression, like "paragraph block*" or "(table_cell | table_header)*".
+type contentExpr struct {
+	types    map[string]bool // node types matched by a name, nil for sequences and choices
+	seq      []*contentExpr
+	choice   []*contentExpr
+	repeat   *contentExpr
+	min, max int // max is -1 for unlimited repetitions
+}
+
+// contentParser parses content expressions, resolving names to node types and groups.
+type contentParser struct {
+	tokens []string
+	pos    int
+	nodes  map[string]NodeType
+}
+
+func parseContentExpr(expr string, nodes map[string]NodeType) (*contentExpr, error) {
+	p := &contentParser{tokens: tokenizeContentExpr(expr), nodes: nodes}
+
+	if len(p.tokens) == 0 {
+		return nil, nil
+	}
+
+	e, err := p.parseChoice()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token '%v' in content expression '%v'", p.tokens[p.pos], expr)
+	}
+
+	return e, nil
+}
+
+func tokenizeContentExpr(expr string) []string {
+	tokens := make([]string, 0)
+	var name strings.Builder
+
+	for _, r := range expr {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == ',' {
+			name.WriteRune(r)
+			continue
+		}
+
+		if name.Len() != 0 {
+			tokens = append(tokens, name.String())
+			name.Reset()
+		}
+
+		if !unicode.IsSpace(r) {
+			tokens = append(tokens, string(r))
+		}
+	}
+
+	if name.Len() != 0 {
+		tokens = append(tokens, name.String())
+	}
+
+	return tokens
+}
+
+func (p *contentParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+
+	return ""
+}
+
+func (p *contentParser) eat(token string) bool {
+	if p.next() == token {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *contentParser) parseChoice() (*contentExpr, error) {
+	choice := make([]*contentExpr, 0, 1)
+
+	for {
+		e, err := p.parseSeq()
+
+		if err != nil {
+			return nil, err
+		}
+
+		choice = append(choice, e)
+
+		if !p.eat("|") {
+			break
+		}
+	}
+
+	if len(choice) == 1 {
+		return choice[0], nil
+	}
+
+	return &contentExpr{choice: choice}, nil
+}
+
+func (p *contentParser) parseSeq() (*contentExpr, error) {
+	seq := make([]*contentExpr, 0, 1)
+
+	for p.next() != "" && p.next() != ")" && p.next() != "|" {
+		e, err := p.parseSubscript()
+
+		if err != nil {
+			return nil, err
+		}
+
+		seq = append(seq, e)
+	}
+
+	if len(seq) == 0 {
+		return nil, errors.New("empty sequence in content expression")
+	}
+
+	if len(seq) == 1 {
+		return seq[0], nil
+	}
+
+	return &contentExpr{seq: seq}, nil
+}
+
+func (p *contentParser) parseSubscript() (*contentExpr, error) {
+	e, err := p.parseAtom()
+
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if p.eat("*") {
+			e = &contentExpr{repeat: e, min: 0, max: -1}
+		} else if p.eat("+") {
+			e = &contentExpr{repeat: e, min: 1, max: -1}
+		} else if p.eat("?") {
+			e = &contentExpr{repeat: e, min: 0, max: 1}
+		} else if p.eat("{") {
+			min, max, err := p.parseRange()
+
+			if err != nil {
+				return nil, err
+			}
+
+			e = &contentExpr{repeat: e, min: min, max: max}
+		} else {
+			return e, nil
+		}
+	}
+}
+
+// parseRange parses "n}", "n,}" and "n,m}".
+func (p *contentParser) parseRange() (int, int, error) {
+	parts := strings.Split(p.next(), ",")
+	p.pos++
+
+	if len(parts) > 2 || !p.eat("}") {
+		return 0, 0, errors.New("invalid range in content expression")
+	}
+
+	min, err := strconv.Atoi(parts[0])
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if len(parts) == 1 {
+		return min, min, nil
+	}
+
+	if parts[1] == "" {
+		return min, -1, nil
+	}
+
+	max, err := strconv.Atoi(parts[1])
+
+	if err != nil || max < min {
+		return 0, 0, errors.New("invalid range in content expression")
+	}
+
+	return min, max, nil
+}
+
+func (p *contentParser) parseAtom() (*contentExpr, error) {
+	if p.eat("(") {
+		e, err := p.parseChoice()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.eat(")") {
+			return nil, errors.New("missing closing parenthesis in content expression")
+		}
+
+		return e, nil
+	}
+
+	name := p.next()
+	p.pos++
+	types := make(map[string]bool)
+
+	if _, ok := p.nodes[name]; ok {
+		types[name] = true
+	} else {
+		for typeName, t := range p.nodes {
+			if t.inGroup(name) {
+				types[typeName] = true
+			}
+		}
+	}
+
+	if len(types) == 0 {
+		return nil, fmt.Errorf("unknown node type or group '%v' in content expression", name)
+	}
+
+	return &contentExpr{types: types}, nil
+}
+
+// allows returns true if the node type can appear anywhere in the content.
+func (e *contentExpr) allows(typeName string) bool {
+	if e == nil {
+		return false
+	}
+
+	if e.types != nil {
+		return e.types[typeName]
+	}
+
+	if e.repeat != nil {
+		return e.repeat.allows(typeName)
+	}
+
+	for _, sub := range append(e.seq, e.choice...) {
+		if sub.allows(typeName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matches returns true if the expression matches the node types of the content.
+func (e *contentExpr) matches(content []Node) bool {
+	if e == nil {
+		return len(content) == 0
+	}
+
+	for _, pos := range e.match(content, []int{0}) {
+		if pos == len(content) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// match returns all positions the expression can end at when starting at one of the given positions.
+func (e *contentExpr) match(content []Node, positions []int) []int {
+	if len(positions) == 0 {
+		return nil
+	}
+
+	if e.types != nil {
+		result := make([]int, 0, len(positions))
+
+		for _, pos := range positions {
+			if pos < len(content) && e.types[content[pos].Type] {
+				result = append(result, pos+1)
+			}
+		}
+
+		return result
+	}
+
+	if e.repeat != nil {
+		return e.matchRepeat(content, positions)
+	}
+
+	if e.seq != nil {
+		for _, sub := range e.seq {
+			positions = sub.match(content, positions)
+		}
+
+		return positions
+	}
+
+	result := make([]int, 0)
+
+	for _, sub := range e.choice {
+		result = addPositions(result, sub.match(content, positions)...)
+	}
+
+	return result
+}
+
+func (e *contentExpr) matchRepeat(content []Node, positions []int) []int {
+	result := make([]int, 0)
+
+	if e.min == 0 {
+		result = addPositions(result, positions...)
+	}
+
+	// each repetition consumes a node, except for empty matches, so repetitions are limited by the remaining content
+	for count := 1; (e.max == -1 || count <= e.max) && count <= e.min+len(content)+1 && len(positions) != 0; count++ {
+		positions = e.repeat.match(content, positions)
+
+		if count >= e.min {
+			result = addPositions(result, positions...)
+		}
+	}
+
+	return result
+}
+
+func addPositions(positions []int, add ...int) []int {
+	for _, pos := range add {
+		found := false
+
+		for _, p := range positions {
+			if p == pos {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			positions = append(positions, pos)
+		}
+	}
+
+	return positions
+}
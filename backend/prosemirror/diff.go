@@ -0,0 +1,83 @@
+package prosemirror
+
+import (
+	"reflect"
+)
+
+const (
+	// DiffEqual marks a block that exists in both documents.
+	DiffEqual = "equal"
+
+	// DiffInsert marks a block that was added to the new document.
+	DiffInsert = "insert"
+
+	// DiffDelete marks a block that was removed from the old document.
+	DiffDelete = "delete"
+)
+
+// NodeDiff is a single top level block of a document diff.
+type NodeDiff struct {
+	Op   string
+	Node Node
+}
+
+// DiffDocs compares the top level blocks (paragraphs, headlines, lists, ...) of two documents.
+// The result contains all blocks of both documents in order, each marked as equal, inserted or deleted.
+// A modified block is reported as the deletion of the old block followed by the insertion of the new one.
+func DiffDocs(oldDoc, newDoc *Node) []NodeDiff {
+	var a, b []Node
+
+	if oldDoc != nil {
+		a = oldDoc.Content
+	}
+
+	if newDoc != nil {
+		b = newDoc.Content
+	}
+
+	// longest common subsequence of blocks
+	lcs := make([][]int, len(a)+1)
+
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if reflect.DeepEqual(a[i], b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	diff := make([]NodeDiff, 0, len(a)+len(b))
+	i, j := 0, 0
+
+	for i < len(a) && j < len(b) {
+		if reflect.DeepEqual(a[i], b[j]) {
+			diff = append(diff, NodeDiff{DiffEqual, b[j]})
+			i++
+			j++
+		} else if lcs[i+1][j] >= lcs[i][j+1] {
+			diff = append(diff, NodeDiff{DiffDelete, a[i]})
+			i++
+		} else {
+			diff = append(diff, NodeDiff{DiffInsert, b[j]})
+			j++
+		}
+	}
+
+	for ; i < len(a); i++ {
+		diff = append(diff, NodeDiff{DiffDelete, a[i]})
+	}
+
+	for ; j < len(b); j++ {
+		diff = append(diff, NodeDiff{DiffInsert, b[j]})
+	}
+
+	return diff
+}
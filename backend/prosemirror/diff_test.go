@@ -0,0 +1,57 @@
+package prosemirror
+
+import (
+	"testing"
+)
+
+func TestDiffDocs(t *testing.T) {
+	oldDoc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]},{"type":"paragraph","content":[{"type":"text","text":"b"}]},{"type":"paragraph","content":[{"type":"text","text":"c"}]}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newDoc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]},{"type":"paragraph","content":[{"type":"text","text":"B"}]},{"type":"paragraph","content":[{"type":"text","text":"c"}]},{"type":"paragraph","content":[{"type":"text","text":"d"}]}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diff := DiffDocs(oldDoc, newDoc)
+	expected := []struct {
+		op   string
+		text string
+	}{
+		{DiffEqual, "a"},
+		{DiffDelete, "b"},
+		{DiffInsert, "B"},
+		{DiffEqual, "c"},
+		{DiffInsert, "d"},
+	}
+
+	if len(diff) != len(expected) {
+		t.Fatalf("Expected %v blocks, but was: %v", len(expected), len(diff))
+	}
+
+	for i, e := range expected {
+		if diff[i].Op != e.op || diff[i].Node.Content[0].Text != e.text {
+			t.Fatalf("Expected block %v to be %v '%v', but was: %v '%v'", i, e.op, e.text, diff[i].Op, diff[i].Node.Content[0].Text)
+		}
+	}
+}
+
+func TestDiffDocsEmpty(t *testing.T) {
+	doc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := DiffDocs(nil, doc); len(diff) != 1 || diff[0].Op != DiffInsert {
+		t.Fatalf("Block must have been inserted, but was: %v", diff)
+	}
+
+	if diff := DiffDocs(doc, nil); len(diff) != 1 || diff[0].Op != DiffDelete {
+		t.Fatalf("Block must have been deleted, but was: %v", diff)
+	}
+}
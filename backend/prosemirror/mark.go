@@ -9,5 +9,6 @@ type Mark struct {
 // MarkType is a Prosemirror mark specification.
 // The ToDOM function is used to render the corresponding mark.
 type MarkType struct {
+	Attrs map[string]AttrSpec
 	ToDOM func(mark *Mark, content string) string
 }
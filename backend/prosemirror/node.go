@@ -1,5 +1,9 @@
 package prosemirror
 
+import (
+	"strings"
+)
+
 // Node is a Prosemirror document node.
 type Node struct {
 	Type    string                 `json:"type,omitempty"`
@@ -10,7 +14,90 @@ type Node struct {
 }
 
 // NodeType is a Prosemirror node specification.
+// Content is the content expression (like "paragraph block*"), referring to node types and groups.
+// Nodes without content expression are leaf nodes and take up a single position in the document.
+// Group is a space separated list of groups the node belongs to.
+// Marks is a space separated list of marks allowed on the content, "_" allows all marks.
+// Inline nodes can be marked.
 // The ToDOM function is used to render the corresponding node.
 type NodeType struct {
-	ToDOM func(node *Node, content string) string
+	Content string
+	Group   string
+	Marks   string
+	Inline  bool
+	Attrs   map[string]AttrSpec
+	ToDOM   func(node *Node, content string) string
+}
+
+// AttrType is the JSON type of an attribute value.
+type AttrType string
+
+const (
+	AttrString AttrType = "string"
+	AttrNumber AttrType = "number"
+	AttrBool   AttrType = "boolean"
+)
+
+// AttrSpec is a node or mark attribute specification.
+// Required attributes must be set, others are set to the default value if missing.
+// The value must be of the given type and one of the values, if set. Numbers must be passed as float64.
+type AttrSpec struct {
+	Default  interface{}
+	Required bool
+	Type     AttrType
+	Values   []interface{}
+}
+
+// allows returns whether the value matches the type and is one of the allowed values.
+func (spec *AttrSpec) allows(value interface{}) bool {
+	switch spec.Type {
+	case AttrString:
+		if _, ok := value.(string); !ok {
+			return false
+		}
+	case AttrNumber:
+		if _, ok := value.(float64); !ok {
+			return false
+		}
+	case AttrBool:
+		if _, ok := value.(bool); !ok {
+			return false
+		}
+	}
+
+	if len(spec.Values) == 0 {
+		return true
+	}
+
+	for _, v := range spec.Values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *NodeType) isLeaf() bool {
+	return t.Content == ""
+}
+
+func (t *NodeType) inGroup(group string) bool {
+	for _, g := range strings.Fields(t.Group) {
+		if g == group {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t *NodeType) allowsMark(markType string) bool {
+	for _, m := range strings.Fields(t.Marks) {
+		if m == "_" || m == markType {
+			return true
+		}
+	}
+
+	return false
 }
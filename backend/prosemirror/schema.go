@@ -2,23 +2,40 @@ package prosemirror
 
 import (
 	"errors"
+	"fmt"
 )
 
 const (
-	docType = "doc"
+	docType  = "doc"
+	textType = "text"
 )
 
 // Schema is a Prosemirror schema containing all node and mark specifications.
 type Schema struct {
 	Nodes map[string]NodeType
 	Marks map[string]MarkType
+
+	content map[string]*contentExpr
 }
 
 // NewSchema returns a new Prosemirror schema for the given nodes and marks.
+// Returns an error if a content expression is invalid.
 func NewSchema(nodes map[string]NodeType, marks map[string]MarkType) (*Schema, error) {
 	if _, ok := nodes[docType]; !ok {
 		return nil, errors.New("schema must have root node")
 	}
 
-	return &Schema{nodes, marks}, nil
+	content := make(map[string]*contentExpr)
+
+	for name, t := range nodes {
+		expr, err := parseContentExpr(t.Content, nodes)
+
+		if err != nil {
+			return nil, fmt.Errorf("node type '%v': %v", name, err)
+		}
+
+		content[name] = expr
+	}
+
+	return &Schema{nodes, marks, content}, nil
 }
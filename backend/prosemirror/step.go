@@ -0,0 +1,389 @@
+package prosemirror
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"unicode/utf16"
+)
+
+const (
+	// StepReplace replaces a range of the document with a slice.
+	StepReplace = "replace"
+
+	// StepReplaceAround replaces a range of the document with a slice, but keeps the gap inside the range.
+	StepReplaceAround = "replaceAround"
+
+	// StepAddMark adds a mark to all inline content within a range.
+	StepAddMark = "addMark"
+
+	// StepRemoveMark removes a mark from all inline content within a range.
+	StepRemoveMark = "removeMark"
+)
+
+// Slice is a piece of a document used by replace steps.
+// OpenStart and OpenEnd are the depth of the nodes which are open at the start and end of the slice.
+type Slice struct {
+	Content   []Node `json:"content,omitempty"`
+	OpenStart int    `json:"openStart,omitempty"`
+	OpenEnd   int    `json:"openEnd,omitempty"`
+}
+
+// Step is a Prosemirror transform step in the JSON representation used by the editor.
+// Positions are counted like in Prosemirror: each node boundary, leaf node and UTF-16 code unit of text counts as one.
+type Step struct {
+	StepType  string `json:"stepType"`
+	From      int    `json:"from"`
+	To        int    `json:"to"`
+	GapFrom   int    `json:"gapFrom,omitempty"`
+	GapTo     int    `json:"gapTo,omitempty"`
+	Insert    int    `json:"insert,omitempty"`
+	Slice     *Slice `json:"slice,omitempty"`
+	Mark      *Mark  `json:"mark,omitempty"`
+	Structure bool   `json:"structure,omitempty"`
+}
+
+type tokenKind int
+
+const (
+	tokenOpen tokenKind = iota
+	tokenClose
+	tokenLeaf
+	tokenText
+)
+
+// token is a single position of a flattened document.
+type token struct {
+	kind  tokenKind
+	node  *Node
+	unit  uint16
+	marks []Mark
+}
+
+// ApplySteps applies the given steps to the document in order and returns the resulting document.
+// The document passed in is not modified. The result is validated against the schema using ValidateDoc,
+// the first violation found is returned as error.
+func ApplySteps(schema *Schema, doc *Node, steps []Step) (*Node, error) {
+	if doc == nil || doc.Type != docType {
+		return nil, errors.New("document must have a root node")
+	}
+
+	tokens, err := flatten(schema, doc.Content, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for i, step := range steps {
+		tokens, err = applyStep(schema, tokens, &step)
+
+		if err != nil {
+			return nil, fmt.Errorf("step %d: %v", i, err)
+		}
+	}
+
+	content, err := build(tokens)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Node{Type: doc.Type, Attrs: doc.Attrs, Content: content}
+
+	if err := ValidateDoc(schema, result); len(err) != 0 {
+		return nil, err[0]
+	}
+
+	return result, nil
+}
+
+// NodeSize returns the number of positions the given node takes up within a document.
+func NodeSize(schema *Schema, node *Node) (int, error) {
+	tokens, err := flatten(schema, []Node{*node}, nil)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(tokens), nil
+}
+
+func applyStep(schema *Schema, tokens []token, step *Step) ([]token, error) {
+	if step.From < 0 || step.From > step.To || step.To > len(tokens) {
+		return nil, fmt.Errorf("position %d to %d out of range", step.From, step.To)
+	}
+
+	switch step.StepType {
+	case StepReplace:
+		return replace(schema, tokens, step)
+	case StepReplaceAround:
+		return replaceAround(schema, tokens, step)
+	case StepAddMark:
+		return updateMarks(schema, tokens, step, addMark)
+	case StepRemoveMark:
+		return updateMarks(schema, tokens, step, removeMark)
+	default:
+		return nil, fmt.Errorf("unknown step type '%v'", step.StepType)
+	}
+}
+
+func replace(schema *Schema, tokens []token, step *Step) ([]token, error) {
+	if step.Structure && contentBetween(tokens, step.From, step.To) {
+		return nil, errors.New("structure replace would overwrite content")
+	}
+
+	slice, err := flattenSlice(schema, step.Slice)
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]token, 0, len(tokens)-(step.To-step.From)+len(slice))
+	result = append(result, tokens[:step.From]...)
+	result = append(result, slice...)
+	return append(result, tokens[step.To:]...), nil
+}
+
+func replaceAround(schema *Schema, tokens []token, step *Step) ([]token, error) {
+	if step.GapFrom < step.From || step.GapFrom > step.GapTo || step.GapTo > step.To {
+		return nil, fmt.Errorf("gap %d to %d out of range", step.GapFrom, step.GapTo)
+	}
+
+	if step.Structure && (contentBetween(tokens, step.From, step.GapFrom) || contentBetween(tokens, step.GapTo, step.To)) {
+		return nil, errors.New("structure gap-replace would overwrite content")
+	}
+
+	slice, err := flattenSlice(schema, step.Slice)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if step.Insert < 0 || step.Insert > len(slice) {
+		return nil, fmt.Errorf("insert position %d out of range", step.Insert)
+	}
+
+	result := make([]token, 0, len(tokens)-(step.To-step.From)+(step.GapTo-step.GapFrom)+len(slice))
+	result = append(result, tokens[:step.From]...)
+	result = append(result, slice[:step.Insert]...)
+	result = append(result, tokens[step.GapFrom:step.GapTo]...)
+	result = append(result, slice[step.Insert:]...)
+	return append(result, tokens[step.To:]...), nil
+}
+
+// contentBetween returns true if the range contains more than closing and opening node boundaries.
+func contentBetween(tokens []token, from, to int) bool {
+	for from < to && tokens[from].kind == tokenClose {
+		from++
+	}
+
+	for from < to && tokens[from].kind == tokenOpen {
+		from++
+	}
+
+	return from < to
+}
+
+func updateMarks(schema *Schema, tokens []token, step *Step, update func([]Mark, *Mark) []Mark) ([]token, error) {
+	if step.Mark == nil {
+		return nil, errors.New("mark missing")
+	}
+
+	if _, ok := schema.Marks[step.Mark.Type]; !ok {
+		return nil, fmt.Errorf("unknown mark type '%v'", step.Mark.Type)
+	}
+
+	result := make([]token, len(tokens))
+	copy(result, tokens)
+
+	for i := step.From; i < step.To; i++ {
+		t := &result[i]
+
+		if t.kind == tokenText {
+			t.marks = update(t.marks, step.Mark)
+		} else if t.kind == tokenLeaf && schema.Nodes[t.node.Type].Inline {
+			node := *t.node
+			node.Marks = update(node.Marks, step.Mark)
+			t.node = &node
+		}
+	}
+
+	return result, nil
+}
+
+// addMark adds the mark to the set, replacing a mark of the same type.
+func addMark(marks []Mark, mark *Mark) []Mark {
+	result := make([]Mark, 0, len(marks)+1)
+
+	for _, m := range marks {
+		if m.Type != mark.Type {
+			result = append(result, m)
+		}
+	}
+
+	return append(result, *mark)
+}
+
+func removeMark(marks []Mark, mark *Mark) []Mark {
+	result := make([]Mark, 0, len(marks))
+
+	for _, m := range marks {
+		if !markEquals(&m, mark) {
+			result = append(result, m)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	return result
+}
+
+func markEquals(a, b *Mark) bool {
+	return a.Type == b.Type && (len(a.Attrs) == 0 && len(b.Attrs) == 0 || reflect.DeepEqual(a.Attrs, b.Attrs))
+}
+
+func marksEqual(a, b []Mark) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !markEquals(&a[i], &b[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func flattenSlice(schema *Schema, slice *Slice) ([]token, error) {
+	if slice == nil {
+		return nil, nil
+	}
+
+	tokens, err := flatten(schema, slice.Content, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if slice.OpenStart < 0 || slice.OpenEnd < 0 || slice.OpenStart+slice.OpenEnd > len(tokens) {
+		return nil, errors.New("slice open depth out of range")
+	}
+
+	for i := 0; i < slice.OpenStart; i++ {
+		if tokens[i].kind != tokenOpen {
+			return nil, errors.New("slice open start exceeds content depth")
+		}
+	}
+
+	for i := len(tokens) - slice.OpenEnd; i < len(tokens); i++ {
+		if tokens[i].kind != tokenClose {
+			return nil, errors.New("slice open end exceeds content depth")
+		}
+	}
+
+	return tokens[slice.OpenStart : len(tokens)-slice.OpenEnd], nil
+}
+
+// flatten appends the positions of given nodes to the tokens.
+func flatten(schema *Schema, nodes []Node, tokens []token) ([]token, error) {
+	for i := range nodes {
+		node := &nodes[i]
+		t, ok := schema.Nodes[node.Type]
+
+		if !ok {
+			return nil, fmt.Errorf("unknown node type '%v'", node.Type)
+		}
+
+		if node.Type == textType {
+			if node.Text == "" {
+				return nil, errors.New("empty text node")
+			}
+
+			for _, unit := range utf16.Encode([]rune(node.Text)) {
+				tokens = append(tokens, token{kind: tokenText, unit: unit, marks: node.Marks})
+			}
+		} else if t.isLeaf() {
+			tokens = append(tokens, token{kind: tokenLeaf, node: node})
+		} else {
+			var err error
+			tokens = append(tokens, token{kind: tokenOpen, node: node})
+			tokens, err = flatten(schema, node.Content, tokens)
+
+			if err != nil {
+				return nil, err
+			}
+
+			tokens = append(tokens, token{kind: tokenClose})
+		}
+	}
+
+	return tokens, nil
+}
+
+// frame is a node being build from tokens, collecting text of the same marks.
+type frame struct {
+	node  Node
+	text  []uint16
+	marks []Mark
+}
+
+func (f *frame) appendNode(node Node) {
+	f.flushText()
+	f.node.Content = append(f.node.Content, node)
+}
+
+func (f *frame) appendText(t *token) {
+	if len(f.text) != 0 && !marksEqual(f.marks, t.marks) {
+		f.flushText()
+	}
+
+	f.marks = t.marks
+	f.text = append(f.text, t.unit)
+}
+
+func (f *frame) flushText() {
+	if len(f.text) != 0 {
+		f.node.Content = append(f.node.Content, Node{Type: textType, Text: string(utf16.Decode(f.text)), Marks: f.marks})
+		f.text = nil
+		f.marks = nil
+	}
+}
+
+// build turns tokens back into nodes and returns an error if node boundaries don't match.
+func build(tokens []token) ([]Node, error) {
+	stack := []*frame{new(frame)}
+
+	for i := range tokens {
+		t := &tokens[i]
+		top := stack[len(stack)-1]
+
+		switch t.kind {
+		case tokenOpen:
+			top.flushText()
+			stack = append(stack, &frame{node: Node{Type: t.node.Type, Attrs: t.node.Attrs, Marks: t.node.Marks}})
+		case tokenClose:
+			if len(stack) == 1 {
+				return nil, fmt.Errorf("unexpected closing node boundary at position %d", i)
+			}
+
+			top.flushText()
+			stack = stack[:len(stack)-1]
+			stack[len(stack)-1].appendNode(top.node)
+		case tokenLeaf:
+			top.appendNode(*t.node)
+		case tokenText:
+			top.appendText(t)
+		}
+	}
+
+	if len(stack) != 1 {
+		return nil, errors.New("unclosed node boundaries")
+	}
+
+	stack[0].flushText()
+	return stack[0].node.Content, nil
+}
@@ -0,0 +1,161 @@
+package prosemirror
+
+import (
+	"emviwiki/shared/testutil"
+	"encoding/json"
+	"testing"
+)
+
+func TestApplySteps(t *testing.T) {
+	input := []struct {
+		doc      string
+		steps    string
+		expected string
+	}{
+		// insert text
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}`,
+			`[{"stepType":"replace","from":2,"to":2,"slice":{"content":[{"type":"text","text":"X"}]}}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"aXb"}]}]}`,
+		},
+		// split paragraph
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}`,
+			`[{"stepType":"replace","from":2,"to":2,"slice":{"content":[{"type":"paragraph"},{"type":"paragraph"}],"openStart":1,"openEnd":1}}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"}]},{"type":"paragraph","content":[{"type":"text","text":"b"}]}]}`,
+		},
+		// delete across and join paragraphs
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]},{"type":"paragraph","content":[{"type":"text","text":"cd"}]}]}`,
+			`[{"stepType":"replace","from":2,"to":6}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ad"}]}]}`,
+		},
+		// text outside the basic multilingual plane counts as two positions
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"😀b"}]}]}`,
+			`[{"stepType":"replace","from":3,"to":3,"slice":{"content":[{"type":"text","text":"X"}]}}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"😀Xb"}]}]}`,
+		},
+		// add and remove marks, leaf nodes take one position
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"abc"},{"type":"hard_break"},{"type":"text","text":"d"}]}]}`,
+			`[{"stepType":"addMark","from":2,"to":6,"mark":{"type":"italic"}},{"stepType":"removeMark","from":5,"to":6,"mark":{"type":"italic"}}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a"},{"type":"text","text":"bc","marks":[{"type":"italic"}]},{"type":"hard_break","marks":[{"type":"italic"}]},{"type":"text","text":"d"}]}]}`,
+		},
+		// marks are replaced by marks of the same type
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"link","attrs":{"href":"a"}}]}]}]}`,
+			`[{"stepType":"addMark","from":1,"to":2,"mark":{"type":"link","attrs":{"href":"b"}}}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"link","attrs":{"href":"b"}}]}]}]}`,
+		},
+		// wrap paragraph in blockquote
+		{
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}`,
+			`[{"stepType":"replaceAround","from":0,"to":4,"gapFrom":0,"gapTo":4,"insert":1,"slice":{"content":[{"type":"blockquote"}]},"structure":true}]`,
+			`{"type":"doc","content":[{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}]}`,
+		},
+		// lift paragraph out of blockquote
+		{
+			`{"type":"doc","content":[{"type":"blockquote","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}]}`,
+			`[{"stepType":"replaceAround","from":0,"to":6,"gapFrom":1,"gapTo":5,"insert":0,"structure":true}]`,
+			`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}`,
+		},
+	}
+
+	for i, in := range input {
+		doc, err := ParseDoc(in.doc)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var steps []Step
+
+		if err := json.Unmarshal([]byte(in.steps), &steps); err != nil {
+			t.Fatal(err)
+		}
+
+		result, err := ApplySteps(testStepSchema(), doc, steps)
+
+		if err != nil {
+			t.Fatalf("Steps must be applied for input %d, but was: %v", i, err)
+		}
+
+		out, err := json.Marshal(result)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		testutil.AssertJSONEquals(t, string(out), in.expected)
+	}
+}
+
+func TestApplyStepsInvalid(t *testing.T) {
+	input := []string{
+		`[{"stepType":"unknown","from":0,"to":0}]`,
+		`[{"stepType":"replace","from":3,"to":2}]`,
+		`[{"stepType":"replace","from":0,"to":5}]`,
+		`[{"stepType":"replace","from":2,"to":2,"slice":{"content":[{"type":"unknown"}]}}]`,
+		`[{"stepType":"replace","from":2,"to":2,"slice":{"content":[{"type":"text","text":"a"}],"openStart":1}}]`,
+		`[{"stepType":"replace","from":2,"to":2,"slice":{"content":[{"type":"paragraph"}],"openEnd":1}}]`,
+		`[{"stepType":"replace","from":0,"to":2}]`,
+		`[{"stepType":"replace","from":2,"to":3,"structure":true}]`,
+		`[{"stepType":"replaceAround","from":0,"to":4,"gapFrom":3,"gapTo":2}]`,
+		`[{"stepType":"addMark","from":1,"to":2}]`,
+		`[{"stepType":"addMark","from":1,"to":2,"mark":{"type":"unknown"}}]`,
+	}
+	doc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"}]}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, in := range input {
+		var steps []Step
+
+		if err := json.Unmarshal([]byte(in), &steps); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := ApplySteps(testStepSchema(), doc, steps); err == nil {
+			t.Fatalf("Steps must not be applied for input %d", i)
+		}
+	}
+
+	if len(doc.Content) != 1 || doc.Content[0].Content[0].Text != "ab" {
+		t.Fatalf("Document must not be modified, but was: %v", doc)
+	}
+}
+
+func TestNodeSize(t *testing.T) {
+	doc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"ab"},{"type":"hard_break"}]}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size, err := NodeSize(testStepSchema(), doc); err != nil || size != 7 {
+		t.Fatalf("Node size must be 7, but was: %v %v", size, err)
+	}
+}
+
+func testStepSchema() *Schema {
+	toDOM := func(node *Node, content string) string {
+		return content
+	}
+	markToDOM := func(mark *Mark, content string) string {
+		return content
+	}
+	schema, _ := NewSchema(map[string]NodeType{
+		"doc":        {Content: "block+", ToDOM: toDOM},
+		"text":       {Group: "inline", Inline: true, ToDOM: toDOM},
+		"hard_break": {Group: "inline", Inline: true, ToDOM: toDOM},
+		"paragraph":  {Content: "inline*", Group: "block", Marks: "_", ToDOM: toDOM},
+		"blockquote": {Content: "block+", Group: "block", ToDOM: toDOM},
+	}, map[string]MarkType{
+		"italic": {ToDOM: markToDOM},
+		"link":   {ToDOM: markToDOM},
+	})
+	return schema
+}
@@ -0,0 +1,137 @@
+package prosemirror
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// ValidationError is a violation of the schema within a document.
+// The path is a JSON pointer to the invalid node, mark or attribute, like "/content/1/attrs/level".
+type ValidationError struct {
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+// Error returns the path and message.
+func (err ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", err.Path, err.Message)
+}
+
+// ValidateDoc validates the document against the schema and returns all violations found.
+// Node types must be known, the content of each node must match its content expression,
+// marks must be allowed by the parent node, required attributes must be set and attribute values must match their type and allowed values.
+// Missing optional attributes are set to their default value, like Prosemirror does when parsing a document.
+func ValidateDoc(schema *Schema, doc *Node) []ValidationError {
+	if doc.Type != docType {
+		return []ValidationError{{"", "document must have a root node"}}
+	}
+
+	return validateNode(schema, doc, nil, "", nil)
+}
+
+func validateNode(schema *Schema, node *Node, parent *NodeType, path string, err []ValidationError) []ValidationError {
+	t, ok := schema.Nodes[node.Type]
+
+	if !ok {
+		return append(err, ValidationError{path, fmt.Sprintf("unknown node type '%v'", node.Type)})
+	}
+
+	if node.Type == textType {
+		if node.Text == "" {
+			err = append(err, ValidationError{path + "/text", "text nodes must not be empty"})
+		}
+	} else if node.Text != "" {
+		err = append(err, ValidationError{path + "/text", fmt.Sprintf("node type '%v' must not have text", node.Type)})
+	}
+
+	node.Attrs, err = validateAttrs(t.Attrs, node.Attrs, path, err)
+	node.Marks, err = validateMarks(schema, node.Marks, parent, path, err)
+	expr := schema.content[node.Type]
+	contentValid := true
+
+	for i := range node.Content {
+		childPath := path + "/content/" + strconv.Itoa(i)
+
+		if !expr.allows(node.Content[i].Type) {
+			err = append(err, ValidationError{childPath, fmt.Sprintf("node type '%v' not allowed in '%v'", node.Content[i].Type, node.Type)})
+			contentValid = false
+		} else {
+			err = validateNode(schema, &node.Content[i], &t, childPath, err)
+		}
+	}
+
+	if contentValid && !expr.matches(node.Content) {
+		err = append(err, ValidationError{path, fmt.Sprintf("invalid content for node type '%v'", node.Type)})
+	}
+
+	return err
+}
+
+// validateMarks returns a copy of the marks including default attributes, as they might be shared with other nodes.
+func validateMarks(schema *Schema, marks []Mark, parent *NodeType, path string, err []ValidationError) ([]Mark, []ValidationError) {
+	if len(marks) == 0 {
+		return marks, err
+	}
+
+	marks = append([]Mark(nil), marks...)
+	types := make(map[string]bool)
+
+	for i := range marks {
+		markPath := path + "/marks/" + strconv.Itoa(i)
+		t, ok := schema.Marks[marks[i].Type]
+
+		if !ok {
+			err = append(err, ValidationError{markPath, fmt.Sprintf("unknown mark type '%v'", marks[i].Type)})
+			continue
+		}
+
+		if parent == nil || !parent.allowsMark(marks[i].Type) {
+			err = append(err, ValidationError{markPath, fmt.Sprintf("mark type '%v' not allowed", marks[i].Type)})
+		}
+
+		if types[marks[i].Type] {
+			err = append(err, ValidationError{markPath, fmt.Sprintf("duplicate mark type '%v'", marks[i].Type)})
+		}
+
+		types[marks[i].Type] = true
+		marks[i].Attrs, err = validateAttrs(t.Attrs, marks[i].Attrs, markPath, err)
+	}
+
+	return marks, err
+}
+
+// validateAttrs checks required attributes are set and have a valid value and returns the attributes including default values.
+// The attributes passed in are copied before defaults are added, as they might be shared with other nodes.
+func validateAttrs(spec map[string]AttrSpec, attrs map[string]interface{}, path string, err []ValidationError) (map[string]interface{}, []ValidationError) {
+	copied := false
+
+	for name, attr := range spec {
+		if value, ok := attrs[name]; ok {
+			if !attr.allows(value) {
+				err = append(err, ValidationError{path + "/attrs/" + name, fmt.Sprintf("invalid value '%v' for attribute '%v'", value, name)})
+			}
+
+			continue
+		}
+
+		if attr.Required {
+			err = append(err, ValidationError{path + "/attrs/" + name, fmt.Sprintf("attribute '%v' missing", name)})
+			continue
+		}
+
+		if !copied {
+			withDefaults := make(map[string]interface{}, len(spec))
+
+			for k, v := range attrs {
+				withDefaults[k] = v
+			}
+
+			attrs = withDefaults
+			copied = true
+		}
+
+		attrs[name] = attr.Default
+	}
+
+	return attrs, err
+}
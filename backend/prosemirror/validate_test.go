@@ -0,0 +1,150 @@
+package prosemirror
+
+import (
+	"testing"
+)
+
+func TestValidateDoc(t *testing.T) {
+	input := []struct {
+		doc  string
+		path string
+	}{
+		{`{"type":"paragraph"}`, ""},
+		{`{"type":"doc"}`, ""},
+		{`{"type":"doc","content":[{"type":"paragraph"},{"type":"unknown"}]}`, "/content/1"},
+		{`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"paragraph"}]}]}`, "/content/0/content/0"},
+		{`{"type":"doc","content":[{"type":"list"}]}`, "/content/0"},
+		{`{"type":"doc","content":[{"type":"list","content":[{"type":"list_item","content":[{"type":"list"}]}]}]}`, "/content/0/content/0/content/0"},
+		{`{"type":"doc","content":[{"type":"image"}]}`, "/content/0/attrs/src"},
+		{`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":""}]}]}`, "/content/0/content/0/text"},
+		{`{"type":"doc","content":[{"type":"paragraph","text":"a"}]}`, "/content/0/text"},
+		{`{"type":"doc","content":[{"type":"code","content":[{"type":"text","text":"a","marks":[{"type":"italic"}]}]}]}`, "/content/0/content/0/marks/0"},
+		{`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"unknown"}]}]}]}`, "/content/0/content/0/marks/0"},
+		{`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"italic"},{"type":"italic"}]}]}]}`, "/content/0/content/0/marks/1"},
+		{`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"link"}]}]}]}`, "/content/0/content/0/marks/0/attrs/href"},
+		{`{"type":"doc","content":[{"type":"paragraph","marks":[{"type":"italic"}]}]}`, "/content/0/marks/0"},
+		{`{"type":"doc","content":[{"type":"image","attrs":{"src":1}}]}`, "/content/0/attrs/src"},
+		{`{"type":"doc","content":[{"type":"headline","attrs":{"level":"2"}}]}`, "/content/0/attrs/level"},
+		{`{"type":"doc","content":[{"type":"headline","attrs":{"level":5}}]}`, "/content/0/attrs/level"},
+	}
+
+	for i, in := range input {
+		doc, err := ParseDoc(in.doc)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		validationErr := ValidateDoc(testValidationSchema(t), doc)
+
+		if len(validationErr) != 1 || validationErr[0].Path != in.path {
+			t.Fatalf("Expected one error at path '%v' for input %d, but was: %v", in.path, i, validationErr)
+		}
+	}
+}
+
+func TestValidateDocValid(t *testing.T) {
+	doc, err := ParseDoc(`{"type":"doc","content":[{"type":"paragraph","content":[{"type":"text","text":"a","marks":[{"type":"italic"}]}]},{"type":"list","content":[{"type":"list_item","content":[{"type":"paragraph"},{"type":"image","attrs":{"src":"src"}}]}]},{"type":"image","attrs":{"src":"src"}},{"type":"headline","attrs":{"level":3}}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateDoc(testValidationSchema(t), doc); len(err) != 0 {
+		t.Fatalf("Document must be valid, but was: %v", err)
+	}
+}
+
+func TestValidateDocDefaultAttrs(t *testing.T) {
+	doc, err := ParseDoc(`{"type":"doc","content":[{"type":"code","attrs":{"language":"go"}},{"type":"code"}]}`)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateDoc(testValidationSchema(t), doc); len(err) != 0 {
+		t.Fatalf("Document must be valid, but was: %v", err)
+	}
+
+	if doc.Content[0].Attrs["language"] != "go" || doc.Content[1].Attrs["language"] != "text" {
+		t.Fatalf("Default attributes must have been set, but was: %v %v", doc.Content[0].Attrs, doc.Content[1].Attrs)
+	}
+}
+
+func TestParseContentExpr(t *testing.T) {
+	nodes := testValidationSchema(t).Nodes
+	input := []struct {
+		expr    string
+		content []string
+		valid   bool
+	}{
+		{"paragraph block*", []string{"paragraph"}, true},
+		{"paragraph block*", []string{"paragraph", "image", "paragraph"}, true},
+		{"paragraph block*", []string{"image"}, false},
+		{"(paragraph | image){2,3}", []string{"image"}, false},
+		{"(paragraph | image){2,3}", []string{"image", "paragraph", "image"}, true},
+		{"(paragraph | image){2,3}", []string{"image", "paragraph", "image", "image"}, false},
+		{"paragraph{2,}", []string{"paragraph", "paragraph", "paragraph"}, true},
+		{"paragraph{2}", []string{"paragraph", "paragraph", "paragraph"}, false},
+		{"image? paragraph+", []string{"paragraph", "paragraph"}, true},
+		{"(paragraph*)*", []string{"paragraph", "image"}, false},
+		{"", []string{}, true},
+		{"", []string{"paragraph"}, false},
+	}
+
+	for i, in := range input {
+		expr, err := parseContentExpr(in.expr, nodes)
+
+		if err != nil {
+			t.Fatalf("Expression must be parsed for input %d, but was: %v", i, err)
+		}
+
+		content := make([]Node, 0, len(in.content))
+
+		for _, typeName := range in.content {
+			content = append(content, Node{Type: typeName})
+		}
+
+		if expr.matches(content) != in.valid {
+			t.Fatalf("Expected content to match %v for input %d", in.valid, i)
+		}
+	}
+
+	for _, expr := range []string{"unknown", "(paragraph", "paragraph)", "paragraph{3,1}", "paragraph{a}", "paragraph |"} {
+		if _, err := parseContentExpr(expr, nodes); err == nil {
+			t.Fatalf("Expression '%v' must be invalid", expr)
+		}
+	}
+}
+
+func testValidationSchema(t *testing.T) *Schema {
+	toDOM := func(node *Node, content string) string {
+		return content
+	}
+	markToDOM := func(mark *Mark, content string) string {
+		return content
+	}
+	schema, err := NewSchema(map[string]NodeType{
+		"doc":       {Content: "block+", ToDOM: toDOM},
+		"text":      {Group: "inline", Inline: true, ToDOM: toDOM},
+		"paragraph": {Content: "inline*", Group: "block", Marks: "_", ToDOM: toDOM},
+		"code": {Content: "text*", Group: "block", ToDOM: toDOM,
+			Attrs: map[string]AttrSpec{"language": {Default: "text"}}},
+		"image": {Group: "block", ToDOM: toDOM,
+			Attrs: map[string]AttrSpec{"src": {Required: true, Type: AttrString}}},
+		"headline": {Content: "text*", Group: "block", ToDOM: toDOM,
+			Attrs: map[string]AttrSpec{"level": {Default: float64(2), Type: AttrNumber, Values: []interface{}{float64(2), float64(3)}}}},
+		"list":      {Content: "list_item+", Group: "block", ToDOM: toDOM},
+		"list_item": {Content: "paragraph (paragraph | image)*", ToDOM: toDOM},
+	}, map[string]MarkType{
+		"italic": {ToDOM: markToDOM},
+		"link": {ToDOM: markToDOM,
+			Attrs: map[string]AttrSpec{"href": {Required: true}}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return schema
+}
@@ -0,0 +1,46 @@
+BEGIN;
+
+ALTER TABLE "article_visit" ADD COLUMN "language_id" bigint;
+ALTER TABLE "article_visit" ADD COLUMN "version" integer NOT NULL DEFAULT 0;
+ALTER TABLE "article_visit" ADD COLUMN "views" integer NOT NULL DEFAULT 1;
+ALTER TABLE "article_visit" ADD CONSTRAINT article_visit_language_fk FOREIGN KEY (language_id) REFERENCES "language"(id);
+
+CREATE INDEX article_visit_language_fk_index ON article_visit(language_id);
+CREATE INDEX article_visit_def_time_index ON article_visit(def_time);
+
+CREATE TABLE article_search_query (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    article_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    query character varying(200) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE article_search_query_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE article_search_query_id_seq OWNED BY article_search_query.id;
+
+ALTER TABLE ONLY article_search_query ALTER COLUMN id SET DEFAULT nextval('article_search_query_id_seq'::regclass);
+
+ALTER TABLE ONLY article_search_query
+    ADD CONSTRAINT article_search_query_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT article_search_query_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT article_search_query_article_fk FOREIGN KEY (article_id) REFERENCES article(id),
+    ADD CONSTRAINT article_search_query_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id);
+
+CREATE INDEX article_search_query_organization_fk_index ON article_search_query(organization_id);
+CREATE INDEX article_search_query_article_fk_index ON article_search_query(article_id);
+CREATE INDEX article_search_query_user_fk_index ON article_search_query(user_id);
+
+CREATE TRIGGER update_article_search_query_mod_time BEFORE UPDATE
+    ON "article_search_query" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+CREATE TABLE article_view (
+    id bigint NOT NULL UNIQUE,
+    article_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    language_id bigint,
+    version integer NOT NULL DEFAULT 0,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE article_view_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE article_view_id_seq OWNED BY article_view.id;
+
+ALTER TABLE ONLY article_view ALTER COLUMN id SET DEFAULT nextval('article_view_id_seq'::regclass);
+
+ALTER TABLE ONLY article_view
+    ADD CONSTRAINT article_view_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT article_view_article_fk FOREIGN KEY (article_id) REFERENCES article(id),
+    ADD CONSTRAINT article_view_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id),
+    ADD CONSTRAINT article_view_language_fk FOREIGN KEY (language_id) REFERENCES "language"(id);
+
+CREATE INDEX article_view_article_fk_index ON article_view(article_id);
+CREATE INDEX article_view_user_fk_index ON article_view(user_id);
+CREATE INDEX article_view_language_fk_index ON article_view(language_id);
+
+COMMIT;
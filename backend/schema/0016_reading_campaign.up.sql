@@ -0,0 +1,77 @@
+BEGIN;
+
+CREATE TABLE reading_campaign (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    article_id bigint NOT NULL,
+    language_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    due_date timestamp with time zone NOT NULL,
+    version integer NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE reading_campaign_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE reading_campaign_id_seq OWNED BY reading_campaign.id;
+
+ALTER TABLE ONLY reading_campaign ALTER COLUMN id SET DEFAULT nextval('reading_campaign_id_seq'::regclass);
+
+ALTER TABLE ONLY reading_campaign
+    ADD CONSTRAINT reading_campaign_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT reading_campaign_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT reading_campaign_article_fk FOREIGN KEY (article_id) REFERENCES article(id),
+    ADD CONSTRAINT reading_campaign_language_fk FOREIGN KEY (language_id) REFERENCES "language"(id),
+    ADD CONSTRAINT reading_campaign_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id);
+
+CREATE INDEX reading_campaign_organization_fk_index ON reading_campaign(organization_id);
+CREATE INDEX reading_campaign_article_fk_index ON reading_campaign(article_id);
+CREATE INDEX reading_campaign_language_fk_index ON reading_campaign(language_id);
+CREATE INDEX reading_campaign_user_fk_index ON reading_campaign(user_id);
+
+CREATE TRIGGER update_reading_campaign_mod_time BEFORE UPDATE
+    ON "reading_campaign" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+CREATE TABLE reading_campaign_member (
+    id bigint NOT NULL UNIQUE,
+    reading_campaign_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    confirmed_version integer NOT NULL DEFAULT 0,
+    confirmed timestamp with time zone,
+    reminded timestamp with time zone,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE reading_campaign_member_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE reading_campaign_member_id_seq OWNED BY reading_campaign_member.id;
+
+ALTER TABLE ONLY reading_campaign_member ALTER COLUMN id SET DEFAULT nextval('reading_campaign_member_id_seq'::regclass);
+
+ALTER TABLE ONLY reading_campaign_member
+    ADD CONSTRAINT reading_campaign_member_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT reading_campaign_member_reading_campaign_fk FOREIGN KEY (reading_campaign_id) REFERENCES reading_campaign(id),
+    ADD CONSTRAINT reading_campaign_member_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id),
+    ADD CONSTRAINT reading_campaign_member_unique UNIQUE (reading_campaign_id, user_id);
+
+CREATE INDEX reading_campaign_member_reading_campaign_fk_index ON reading_campaign_member(reading_campaign_id);
+CREATE INDEX reading_campaign_member_user_fk_index ON reading_campaign_member(user_id);
+
+CREATE TRIGGER update_reading_campaign_member_mod_time BEFORE UPDATE
+    ON "reading_campaign_member" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+CREATE TABLE trash (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    type character varying(20) NOT NULL,
+    object_id bigint NOT NULL,
+    name character varying(100) NOT NULL,
+    data jsonb NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE trash_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE trash_id_seq OWNED BY trash.id;
+
+ALTER TABLE ONLY trash ALTER COLUMN id SET DEFAULT nextval('trash_id_seq'::regclass);
+
+ALTER TABLE ONLY trash
+    ADD CONSTRAINT trash_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT trash_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT trash_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id);
+
+CREATE INDEX trash_organization_fk_index ON trash(organization_id);
+CREATE INDEX trash_user_fk_index ON trash(user_id);
+CREATE INDEX trash_def_time_index ON trash(def_time);
+
+CREATE TRIGGER update_trash_mod_time BEFORE UPDATE
+    ON "trash" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
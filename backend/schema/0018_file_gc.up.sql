@@ -0,0 +1,9 @@
+BEGIN;
+
+ALTER TABLE "file" ADD COLUMN "blob" character varying(4096);
+ALTER TABLE "file" ADD COLUMN "unreferenced" timestamp with time zone;
+
+CREATE INDEX file_organization_md5_index ON "file"(organization_id, md5);
+CREATE INDEX file_unreferenced_index ON "file"(unreferenced);
+
+COMMIT;
@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE "file" ADD COLUMN "quarantined" boolean NOT NULL DEFAULT FALSE;
+
+COMMIT;
@@ -0,0 +1,82 @@
+BEGIN;
+
+-- chunked uploads allow files larger than 2 GB
+ALTER TABLE "file" ALTER COLUMN "size" TYPE bigint;
+
+CREATE TABLE upload_session (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    article_id bigint,
+    language_id bigint,
+    room_id character varying(255),
+    "path" character varying(4096) NOT NULL,
+    filename character varying(255) NOT NULL,
+    mime_type character varying(100) NOT NULL,
+    size bigint NOT NULL,
+    chunk_size bigint NOT NULL,
+    checksum character varying(64),
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE upload_session_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE upload_session_id_seq OWNED BY upload_session.id;
+
+ALTER TABLE ONLY upload_session ALTER COLUMN id SET DEFAULT nextval('upload_session_id_seq'::regclass);
+
+ALTER TABLE ONLY upload_session
+    ADD CONSTRAINT upload_session_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT upload_session_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT upload_session_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id),
+    ADD CONSTRAINT upload_session_article_fk FOREIGN KEY (article_id) REFERENCES article(id) ON DELETE CASCADE,
+    ADD CONSTRAINT upload_session_language_fk FOREIGN KEY (language_id) REFERENCES "language"(id) ON DELETE CASCADE;
+
+CREATE INDEX upload_session_organization_fk_index ON upload_session(organization_id);
+CREATE INDEX upload_session_user_fk_index ON upload_session(user_id);
+CREATE INDEX upload_session_article_fk_index ON upload_session(article_id);
+CREATE INDEX upload_session_mod_time_index ON upload_session(mod_time);
+
+CREATE TRIGGER update_upload_session_mod_time BEFORE UPDATE
+    ON "upload_session" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+CREATE TABLE upload_chunk (
+    id bigint NOT NULL UNIQUE,
+    upload_session_id bigint NOT NULL,
+    number integer NOT NULL,
+    size bigint NOT NULL,
+    checksum character varying(64) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE upload_chunk_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE upload_chunk_id_seq OWNED BY upload_chunk.id;
+
+ALTER TABLE ONLY upload_chunk ALTER COLUMN id SET DEFAULT nextval('upload_chunk_id_seq'::regclass);
+
+ALTER TABLE ONLY upload_chunk
+    ADD CONSTRAINT upload_chunk_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT upload_chunk_upload_session_fk FOREIGN KEY (upload_session_id) REFERENCES upload_session(id) ON DELETE CASCADE,
+    ADD CONSTRAINT upload_chunk_number_unique UNIQUE (upload_session_id, number);
+
+CREATE INDEX upload_chunk_upload_session_fk_index ON upload_chunk(upload_session_id);
+
+CREATE TRIGGER update_upload_chunk_mod_time BEFORE UPDATE
+    ON "upload_chunk" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
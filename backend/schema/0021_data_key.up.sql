@@ -0,0 +1,37 @@
+BEGIN;
+
+CREATE TABLE data_key (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint,
+    master_key_id character varying(100) NOT NULL,
+    wrapped_key bytea NOT NULL,
+    retired timestamp with time zone,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE data_key_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE data_key_id_seq OWNED BY data_key.id;
+
+ALTER TABLE ONLY data_key ALTER COLUMN id SET DEFAULT nextval('data_key_id_seq'::regclass);
+
+ALTER TABLE ONLY data_key
+    ADD CONSTRAINT data_key_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT data_key_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id);
+
+CREATE INDEX data_key_organization_fk_index ON data_key(organization_id);
+
+-- there is at most one active data key per organization (and one without organization)
+CREATE UNIQUE INDEX data_key_active_index ON data_key(COALESCE(organization_id, 0)) WHERE retired IS NULL;
+
+CREATE TRIGGER update_data_key_mod_time BEFORE UPDATE
+    ON "data_key" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
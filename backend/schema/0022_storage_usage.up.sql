@@ -0,0 +1,35 @@
+BEGIN;
+
+CREATE TABLE storage_usage (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    "date" date NOT NULL,
+    size bigint NOT NULL,
+    files integer NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE storage_usage_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE storage_usage_id_seq OWNED BY storage_usage.id;
+
+ALTER TABLE ONLY storage_usage ALTER COLUMN id SET DEFAULT nextval('storage_usage_id_seq'::regclass);
+
+ALTER TABLE ONLY storage_usage
+    ADD CONSTRAINT storage_usage_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT storage_usage_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT storage_usage_organization_date_unique UNIQUE (organization_id, "date");
+
+CREATE INDEX storage_usage_organization_fk_index ON storage_usage(organization_id);
+
+CREATE TRIGGER update_storage_usage_mod_time BEFORE UPDATE
+    ON "storage_usage" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,70 @@
+BEGIN;
+
+CREATE TABLE access_token (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    name character varying(40) NOT NULL,
+    token_hash character varying(64) NOT NULL,
+    expires timestamp with time zone NOT NULL,
+    last_used timestamp with time zone,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE access_token_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE access_token_id_seq OWNED BY access_token.id;
+
+ALTER TABLE ONLY access_token ALTER COLUMN id SET DEFAULT nextval('access_token_id_seq'::regclass);
+
+ALTER TABLE ONLY access_token
+    ADD CONSTRAINT access_token_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT access_token_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id),
+    ADD CONSTRAINT access_token_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id),
+    ADD CONSTRAINT access_token_token_hash_unique UNIQUE (token_hash);
+
+CREATE INDEX access_token_organization_fk_index ON access_token(organization_id);
+CREATE INDEX access_token_user_fk_index ON access_token(user_id);
+
+CREATE TRIGGER update_access_token_mod_time BEFORE UPDATE
+    ON "access_token" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+CREATE TABLE access_token_scope (
+    id bigint NOT NULL UNIQUE,
+    access_token_id bigint NOT NULL,
+    name character varying(40) NOT NULL,
+    read boolean NOT NULL,
+    write boolean NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE access_token_scope_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE access_token_scope_id_seq OWNED BY access_token_scope.id;
+
+ALTER TABLE ONLY access_token_scope ALTER COLUMN id SET DEFAULT nextval('access_token_scope_id_seq'::regclass);
+
+ALTER TABLE ONLY access_token_scope
+    ADD CONSTRAINT access_token_scope_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT access_token_scope_access_token_fk FOREIGN KEY (access_token_id) REFERENCES access_token(id);
+
+CREATE INDEX access_token_scope_access_token_fk_index ON access_token_scope(access_token_id);
+
+CREATE TRIGGER update_access_token_scope_mod_time BEFORE UPDATE
+    ON "access_token_scope" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+ALTER TABLE "user" ADD COLUMN bot boolean NOT NULL DEFAULT FALSE;
+
+-- users are created on auth and copied to the backend using the same ID,
+-- bots only exist in the backend, so they use IDs which won't be reached by auth
+CREATE SEQUENCE bot_user_id_seq
+    START WITH 1000000000000
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER TABLE "client" ADD COLUMN user_id bigint;
+
+ALTER TABLE ONLY "client"
+    ADD CONSTRAINT client_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id);
+
+CREATE INDEX client_user_fk_index ON "client"(user_id);
+
+COMMIT;
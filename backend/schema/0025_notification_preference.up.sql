@@ -0,0 +1,36 @@
+BEGIN;
+
+ALTER TABLE "organization_member" ADD COLUMN timezone character varying(60) NOT NULL DEFAULT 'UTC';
+
+CREATE TABLE notification_preference (
+    id bigint NOT NULL UNIQUE,
+    organization_member_id bigint NOT NULL,
+    category character varying(20) NOT NULL,
+    delivery character varying(20) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE notification_preference_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE notification_preference_id_seq OWNED BY notification_preference.id;
+
+ALTER TABLE ONLY notification_preference ALTER COLUMN id SET DEFAULT nextval('notification_preference_id_seq'::regclass);
+
+ALTER TABLE ONLY notification_preference
+    ADD CONSTRAINT notification_preference_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT notification_preference_organization_member_fk FOREIGN KEY (organization_member_id) REFERENCES organization_member(id) ON DELETE CASCADE,
+    ADD CONSTRAINT notification_preference_category_unique UNIQUE (organization_member_id, category);
+
+CREATE INDEX notification_preference_organization_member_fk_index ON notification_preference(organization_member_id);
+
+CREATE TRIGGER update_notification_preference_mod_time BEFORE UPDATE
+    ON "notification_preference" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
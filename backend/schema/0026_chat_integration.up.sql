@@ -0,0 +1,107 @@
+BEGIN;
+
+-- Slack incoming webhooks or Matrix rooms selected feed events are posted to
+CREATE TABLE chat_integration (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    type character varying(20) NOT NULL,
+    name character varying(40) NOT NULL,
+    webhook_url character varying(2000),
+    api_url character varying(2000) NOT NULL,
+    access_token character varying(500),
+    signing_secret character varying(200),
+    room_id character varying(255),
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE chat_integration_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE chat_integration_id_seq OWNED BY chat_integration.id;
+
+ALTER TABLE ONLY chat_integration ALTER COLUMN id SET DEFAULT nextval('chat_integration_id_seq'::regclass);
+
+ALTER TABLE ONLY chat_integration
+    ADD CONSTRAINT chat_integration_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT chat_integration_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id) ON DELETE CASCADE;
+
+CREATE INDEX chat_integration_organization_fk_index ON chat_integration(organization_id);
+
+CREATE TRIGGER update_chat_integration_mod_time BEFORE UPDATE
+    ON "chat_integration" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+-- feed reasons posted by an integration
+CREATE TABLE chat_integration_reason (
+    id bigint NOT NULL UNIQUE,
+    chat_integration_id bigint NOT NULL,
+    reason character varying(100) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE chat_integration_reason_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE chat_integration_reason_id_seq OWNED BY chat_integration_reason.id;
+
+ALTER TABLE ONLY chat_integration_reason ALTER COLUMN id SET DEFAULT nextval('chat_integration_reason_id_seq'::regclass);
+
+ALTER TABLE ONLY chat_integration_reason
+    ADD CONSTRAINT chat_integration_reason_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT chat_integration_reason_chat_integration_fk FOREIGN KEY (chat_integration_id) REFERENCES chat_integration(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chat_integration_reason_unique UNIQUE (chat_integration_id, reason);
+
+CREATE INDEX chat_integration_reason_chat_integration_fk_index ON chat_integration_reason(chat_integration_id);
+
+CREATE TRIGGER update_chat_integration_reason_mod_time BEFORE UPDATE
+    ON "chat_integration_reason" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+-- chat accounts linked by members to receive personal notifications and unfurl links
+CREATE TABLE chat_account (
+    id bigint NOT NULL UNIQUE,
+    chat_integration_id bigint NOT NULL,
+    organization_member_id bigint NOT NULL,
+    external_id character varying(255) NOT NULL,
+    room_id character varying(255),
+    code character varying(20) NOT NULL,
+    confirmed boolean NOT NULL DEFAULT FALSE,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE chat_account_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE chat_account_id_seq OWNED BY chat_account.id;
+
+ALTER TABLE ONLY chat_account ALTER COLUMN id SET DEFAULT nextval('chat_account_id_seq'::regclass);
+
+ALTER TABLE ONLY chat_account
+    ADD CONSTRAINT chat_account_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT chat_account_chat_integration_fk FOREIGN KEY (chat_integration_id) REFERENCES chat_integration(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chat_account_organization_member_fk FOREIGN KEY (organization_member_id) REFERENCES organization_member(id) ON DELETE CASCADE,
+    ADD CONSTRAINT chat_account_member_unique UNIQUE (chat_integration_id, organization_member_id);
+
+CREATE INDEX chat_account_chat_integration_fk_index ON chat_account(chat_integration_id);
+CREATE INDEX chat_account_organization_member_fk_index ON chat_account(organization_member_id);
+
+CREATE TRIGGER update_chat_account_mod_time BEFORE UPDATE
+    ON "chat_account" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- addresses mails can be sent to in order to create articles, optionally added to an article list
+CREATE TABLE inbound_mail_address (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    article_list_id bigint,
+    token character varying(20) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE inbound_mail_address_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE inbound_mail_address_id_seq OWNED BY inbound_mail_address.id;
+
+ALTER TABLE ONLY inbound_mail_address ALTER COLUMN id SET DEFAULT nextval('inbound_mail_address_id_seq'::regclass);
+
+ALTER TABLE ONLY inbound_mail_address
+    ADD CONSTRAINT inbound_mail_address_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT inbound_mail_address_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id) ON DELETE CASCADE,
+    ADD CONSTRAINT inbound_mail_address_article_list_fk FOREIGN KEY (article_list_id) REFERENCES article_list(id) ON DELETE CASCADE,
+    ADD CONSTRAINT inbound_mail_address_token_unique UNIQUE (token);
+
+CREATE INDEX inbound_mail_address_organization_fk_index ON inbound_mail_address(organization_id);
+CREATE INDEX inbound_mail_address_article_list_fk_index ON inbound_mail_address(article_list_id);
+
+CREATE TRIGGER update_inbound_mail_address_mod_time BEFORE UPDATE
+    ON "inbound_mail_address" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+-- version of the default language a translation is based on, NULL for content in the default language
+ALTER TABLE article_content ADD COLUMN source_version integer;
+
+-- members responsible for translating articles into a language, who get notified when translations become outdated
+CREATE TABLE language_translator (
+    id bigint NOT NULL UNIQUE,
+    language_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE language_translator_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE language_translator_id_seq OWNED BY language_translator.id;
+
+ALTER TABLE ONLY language_translator ALTER COLUMN id SET DEFAULT nextval('language_translator_id_seq'::regclass);
+
+ALTER TABLE ONLY language_translator
+    ADD CONSTRAINT language_translator_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT language_translator_language_fk FOREIGN KEY (language_id) REFERENCES language(id) ON DELETE CASCADE,
+    ADD CONSTRAINT language_translator_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE,
+    ADD CONSTRAINT language_translator_language_user_unique UNIQUE (language_id, user_id);
+
+CREATE INDEX language_translator_language_fk_index ON language_translator(language_id);
+CREATE INDEX language_translator_user_fk_index ON language_translator(user_id);
+
+CREATE TRIGGER update_language_translator_mod_time BEFORE UPDATE
+    ON "language_translator" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,135 @@
+BEGIN;
+
+-- structured attributes of articles defined by the organization
+CREATE TABLE custom_field (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    name character varying(40) NOT NULL,
+    type character varying(20) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE custom_field_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE custom_field_id_seq OWNED BY custom_field.id;
+
+ALTER TABLE ONLY custom_field ALTER COLUMN id SET DEFAULT nextval('custom_field_id_seq'::regclass);
+
+ALTER TABLE ONLY custom_field
+    ADD CONSTRAINT custom_field_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT custom_field_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id) ON DELETE CASCADE;
+
+CREATE INDEX custom_field_organization_fk_index ON custom_field(organization_id);
+
+CREATE TRIGGER update_custom_field_mod_time BEFORE UPDATE
+    ON "custom_field" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+-- options to choose from for enum fields
+CREATE TABLE custom_field_option (
+    id bigint NOT NULL UNIQUE,
+    custom_field_id bigint NOT NULL,
+    name character varying(100) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE custom_field_option_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE custom_field_option_id_seq OWNED BY custom_field_option.id;
+
+ALTER TABLE ONLY custom_field_option ALTER COLUMN id SET DEFAULT nextval('custom_field_option_id_seq'::regclass);
+
+ALTER TABLE ONLY custom_field_option
+    ADD CONSTRAINT custom_field_option_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT custom_field_option_custom_field_fk FOREIGN KEY (custom_field_id) REFERENCES custom_field(id) ON DELETE CASCADE;
+
+CREATE INDEX custom_field_option_custom_field_fk_index ON custom_field_option(custom_field_id);
+
+CREATE TRIGGER update_custom_field_option_mod_time BEFORE UPDATE
+    ON "custom_field_option" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+-- the field is required for articles having one of these tags
+CREATE TABLE custom_field_tag (
+    id bigint NOT NULL UNIQUE,
+    custom_field_id bigint NOT NULL,
+    tag_id bigint NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE custom_field_tag_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE custom_field_tag_id_seq OWNED BY custom_field_tag.id;
+
+ALTER TABLE ONLY custom_field_tag ALTER COLUMN id SET DEFAULT nextval('custom_field_tag_id_seq'::regclass);
+
+ALTER TABLE ONLY custom_field_tag
+    ADD CONSTRAINT custom_field_tag_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT custom_field_tag_custom_field_fk FOREIGN KEY (custom_field_id) REFERENCES custom_field(id) ON DELETE CASCADE,
+    ADD CONSTRAINT custom_field_tag_tag_fk FOREIGN KEY (tag_id) REFERENCES tag(id) ON DELETE CASCADE,
+    ADD CONSTRAINT custom_field_tag_custom_field_tag_unique UNIQUE (custom_field_id, tag_id);
+
+CREATE INDEX custom_field_tag_custom_field_fk_index ON custom_field_tag(custom_field_id);
+CREATE INDEX custom_field_tag_tag_fk_index ON custom_field_tag(tag_id);
+
+CREATE TRIGGER update_custom_field_tag_mod_time BEFORE UPDATE
+    ON "custom_field_tag" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+-- values of custom fields per article, only the column matching the type of the field is set
+CREATE TABLE article_custom_field (
+    id bigint NOT NULL UNIQUE,
+    article_id bigint NOT NULL,
+    custom_field_id bigint NOT NULL,
+    value_text text,
+    value_number double precision,
+    value_date date,
+    value_user_id bigint,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE article_custom_field_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE article_custom_field_id_seq OWNED BY article_custom_field.id;
+
+ALTER TABLE ONLY article_custom_field ALTER COLUMN id SET DEFAULT nextval('article_custom_field_id_seq'::regclass);
+
+ALTER TABLE ONLY article_custom_field
+    ADD CONSTRAINT article_custom_field_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT article_custom_field_article_fk FOREIGN KEY (article_id) REFERENCES article(id) ON DELETE CASCADE,
+    ADD CONSTRAINT article_custom_field_custom_field_fk FOREIGN KEY (custom_field_id) REFERENCES custom_field(id) ON DELETE CASCADE,
+    ADD CONSTRAINT article_custom_field_value_user_fk FOREIGN KEY (value_user_id) REFERENCES "user"(id) ON DELETE CASCADE,
+    ADD CONSTRAINT article_custom_field_article_custom_field_unique UNIQUE (article_id, custom_field_id);
+
+CREATE INDEX article_custom_field_article_fk_index ON article_custom_field(article_id);
+CREATE INDEX article_custom_field_custom_field_fk_index ON article_custom_field(custom_field_id);
+
+CREATE TRIGGER update_article_custom_field_mod_time BEFORE UPDATE
+    ON "article_custom_field" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,37 @@
+BEGIN;
+
+-- personal tokens of members, which are part of inbound mail addresses, so that mails can only be sent on behalf of the owner
+CREATE TABLE inbound_mail_member (
+    id bigint NOT NULL UNIQUE,
+    organization_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    token character varying(20) NOT NULL,
+    def_time timestamp with time zone DEFAULT now(),
+    mod_time timestamp with time zone DEFAULT now()
+);
+
+CREATE SEQUENCE inbound_mail_member_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+
+ALTER SEQUENCE inbound_mail_member_id_seq OWNED BY inbound_mail_member.id;
+
+ALTER TABLE ONLY inbound_mail_member ALTER COLUMN id SET DEFAULT nextval('inbound_mail_member_id_seq'::regclass);
+
+ALTER TABLE ONLY inbound_mail_member
+    ADD CONSTRAINT inbound_mail_member_pkey PRIMARY KEY (id),
+    ADD CONSTRAINT inbound_mail_member_organization_fk FOREIGN KEY (organization_id) REFERENCES organization(id) ON DELETE CASCADE,
+    ADD CONSTRAINT inbound_mail_member_user_fk FOREIGN KEY (user_id) REFERENCES "user"(id) ON DELETE CASCADE,
+    ADD CONSTRAINT inbound_mail_member_token_unique UNIQUE (token),
+    ADD CONSTRAINT inbound_mail_member_organization_user_unique UNIQUE (organization_id, user_id);
+
+CREATE INDEX inbound_mail_member_user_fk_index ON inbound_mail_member(user_id);
+
+CREATE TRIGGER update_inbound_mail_member_mod_time BEFORE UPDATE
+    ON "inbound_mail_member" FOR EACH ROW EXECUTE PROCEDURE
+    update_mod_time_column();
+
+COMMIT;
@@ -0,0 +1,21 @@
+BEGIN;
+
+-- versions saved twice by concurrent saves are moved behind the latest version of the article, ordered by creation
+WITH "duplicate" AS (
+    SELECT id, article_id, language_id, ROW_NUMBER() OVER (PARTITION BY article_id, language_id, version ORDER BY id) AS n
+    FROM article_content
+    WHERE wip IS FALSE
+), "renumbered" AS (
+    SELECT d.id, (SELECT MAX(version) FROM article_content WHERE article_id = d.article_id AND language_id = d.language_id) +
+        ROW_NUMBER() OVER (PARTITION BY d.article_id, d.language_id ORDER BY d.id) AS version
+    FROM "duplicate" d
+    WHERE d.n > 1
+)
+UPDATE article_content SET version = r.version
+FROM "renumbered" r
+WHERE article_content.id = r.id;
+
+-- prevents concurrent saves from creating the same version twice, WIP versions are removed when the article is published
+CREATE UNIQUE INDEX article_content_version_unique_index ON article_content(article_id, language_id, version) WHERE wip IS FALSE;
+
+COMMIT;
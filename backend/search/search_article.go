@@ -10,14 +10,18 @@ import (
 	"emviwiki/shared/util"
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 )
 
 const (
 	schemaParagraphType = "paragraph"
 	schemaImageType     = "image"
 	schemaImageAttrSrc  = "src"
+
+	customFieldDateFormat = "2006-01-02"
 )
 
 // Performs a fuzzy search for articles.
@@ -31,6 +35,7 @@ func SearchArticle(ctx context.EmviContext, query string, filter *model.SearchAr
 	}
 
 	filter.ClientAccess = ctx.IsClient()
+	resolveCustomFieldFilter(ctx.Organization.ID, filter)
 	langId := util.DetermineLang(nil, ctx.Organization.ID, ctx.UserId, filter.LanguageId).ID
 	var wg sync.WaitGroup
 	wg.Add(2)
@@ -59,6 +64,52 @@ func SearchArticle(ctx context.EmviContext, query string, filter *model.SearchAr
 	return results, resultCount
 }
 
+// Sets the type of the custom fields to filter by. Unknown fields and values invalid for the type are removed.
+func resolveCustomFieldFilter(orgaId hide.ID, filter *model.SearchArticleFilter) {
+	customFields := make([]model.CustomFieldFilter, 0, len(filter.CustomFields))
+
+	for _, f := range filter.CustomFields {
+		field := model.GetCustomFieldByOrganizationIdAndId(orgaId, f.CustomFieldId)
+
+		if field == nil {
+			continue
+		}
+
+		f.Type = field.Type
+		f.Value = validCustomFieldValue(field.Type, f.Value)
+		f.Min = validCustomFieldValue(field.Type, f.Min)
+		f.Max = validCustomFieldValue(field.Type, f.Max)
+		customFields = append(customFields, f)
+	}
+
+	filter.CustomFields = customFields
+
+	if filter.SortCustomFieldId != 0 && model.GetCustomFieldByOrganizationIdAndId(orgaId, filter.SortCustomFieldId) == nil {
+		filter.SortCustomFieldId = 0
+		filter.SortCustomField = ""
+	}
+}
+
+func validCustomFieldValue(fieldType, value string) string {
+	value = strings.TrimSpace(value)
+	var err error
+
+	switch fieldType {
+	case model.CustomFieldNumber:
+		_, err = strconv.ParseFloat(value, 64)
+	case model.CustomFieldDate:
+		_, err = time.Parse(customFieldDateFormat, value)
+	case model.CustomFieldUser:
+		_, err = hide.FromString(value)
+	}
+
+	if value == "" || err != nil {
+		return ""
+	}
+
+	return value
+}
+
 func articlePreview(ctx context.EmviContext, article *model.Article, langId hide.ID, extractParagraph, extractImage bool) {
 	content := articles.GetArticleContent(ctx.Organization.ID, ctx.UserId, article.ID, langId, 0)
 
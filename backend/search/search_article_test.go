@@ -24,51 +24,51 @@ func TestSearchArticleFiltered(t *testing.T) {
 		N      int
 	}{
 		// all filters enabled
-		{"", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"notfound", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"First commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"first commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"testuser", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
+		{"", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"notfound", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"First commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"first commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"testuser", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
 
 		// no title
-		{"title", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "non existent", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"title", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "non existent", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// no content
-		{"content", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "non existent", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"content", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "non existent", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// no tags
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "non existent", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "non existent", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// no authors
-		{"testuser", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{user.ID + 1}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"testuser", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{user.ID + 1}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// no commits
-		{"commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "non existent", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"commit", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "non existent", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// date filtered by created start
-		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedStart: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedStart: time.Now().AddDate(0, 0, -1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedStart: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedStart: time.Now().AddDate(0, 0, -1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
 
 		// date filtered by created end
-		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedEnd: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedEnd: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedEnd: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{CreatedEnd: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// date filtered by updated start
-		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedStart: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedStart: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedStart: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedStart: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
 
 		// date filtered by updated end
-		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedEnd: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 1},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedEnd: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedEnd: time.Now().AddDate(0, 0, 1)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{UpdatedEnd: time.Now().AddDate(0, 0, -2)}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}, 0},
 
 		// date filtered by published start
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Now().AddDate(0, 0, 1), time.Time{}, "", "", ""}, 0},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Now().AddDate(0, 0, -1), time.Time{}, "", "", ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Now().AddDate(0, 0, 1), time.Time{}, "", "", "", nil, 0, ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Now().AddDate(0, 0, -1), time.Time{}, "", "", "", nil, 0, ""}, 1},
 
 		// date filtered by published end
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Now().AddDate(0, 0, 1), "", "", ""}, 1},
-		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Now().AddDate(0, 0, -1), "", "", ""}, 0},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Now().AddDate(0, 0, 1), "", "", "", nil, 0, ""}, 1},
+		{"article", &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Now().AddDate(0, 0, -1), "", "", "", nil, 0, ""}, 0},
 	}
 
 	for i, io := range inout {
@@ -85,7 +85,7 @@ func TestSearchArticleNoAccess(t *testing.T) {
 	lang := testutil.CreateLang(t, orga, "en", "English", true)
 	testutil.CreateArticle(t, orga, user, lang, false, false)
 	ctx := context.NewEmviUserContext(orga, userNoAccess.ID)
-	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}
+	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}
 
 	if articles, _ := SearchArticle(ctx, "article", filter); len(articles) != 0 {
 		t.Fatal("Article must not be found")
@@ -100,7 +100,7 @@ func TestSearchArticleUserAccess(t *testing.T) {
 	article := testutil.CreateArticle(t, orga, user, lang, false, false)
 	testutil.CreateArticleAccess(t, article, userWithAccess, nil, false)
 	ctx := context.NewEmviUserContext(orga, userWithAccess.ID)
-	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}
+	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}
 
 	if articles, _ := SearchArticle(ctx, "article", filter); len(articles) != 1 {
 		t.Fatal("Article must be found")
@@ -117,7 +117,7 @@ func TestSearchArticleGroupAccess(t *testing.T) {
 	article := testutil.CreateArticle(t, orga, user, lang, false, false)
 	testutil.CreateArticleAccess(t, article, nil, group, false)
 	ctx := context.NewEmviUserContext(orga, userWithGroupAccess.ID)
-	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}
+	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}
 	articles, _ := SearchArticle(ctx, "article", filter)
 
 	if len(articles) != 1 {
@@ -135,7 +135,7 @@ func TestSearchArticleOrderByInSelect(t *testing.T) {
 	lang := testutil.CreateLang(t, orga, "en", "English", true)
 	testutil.CreateArticle(t, orga, user, lang, true, true)
 	ctx := context.NewEmviUserContext(orga, user.ID)
-	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "asc", ""}
+	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, false, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "asc", "", nil, 0, ""}
 
 	if articles, count := SearchArticle(ctx, "article", filter); len(articles) != 1 || count != 1 {
 		t.Fatal("One article must be found")
@@ -155,7 +155,7 @@ func TestSearchArticleArchived(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, true, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", ""}
+	filter := &model.SearchArticleFilter{model.BaseSearch{}, 0, true, false, false, false, false, false, "", "", "", []hide.ID{}, []hide.ID{}, nil, "", time.Time{}, time.Time{}, "", "", "", nil, 0, ""}
 
 	if articles, count := SearchArticle(ctx, "article", filter); len(articles) != 1 || count != 1 {
 		t.Fatal("One archived article must be found")
@@ -488,6 +488,58 @@ func TestSearchArticleSortByPublished(t *testing.T) {
 	}
 }
 
+func TestSearchArticleCustomField(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article1 := testutil.CreateArticle(t, orga, user, lang, true, true)
+	article2 := testutil.CreateArticle(t, orga, user, lang, true, true)
+	article3 := testutil.CreateArticle(t, orga, user, lang, true, true)
+	field := &model.CustomField{OrganizationId: orga.ID, Name: "Amount", Type: model.CustomFieldNumber}
+
+	if err := model.SaveCustomField(nil, field); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, article := range []*model.Article{article1, article2} {
+		value := &model.ArticleCustomField{ArticleId: article.ID,
+			CustomFieldId: field.ID,
+			Number:        null.NewFloat64(float64(20-i*10), true)}
+
+		if err := model.SaveArticleCustomField(nil, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx := context.NewEmviUserContext(orga, user.ID)
+	filter := &model.SearchArticleFilter{CustomFields: []model.CustomFieldFilter{{CustomFieldId: field.ID, Min: "15"}}}
+	articles, count := SearchArticle(ctx, "", filter)
+
+	if len(articles) != 1 || count != 1 || articles[0].ID != article1.ID {
+		t.Fatalf("One article must have been found, but was: %v %v", len(articles), count)
+	}
+
+	filter = &model.SearchArticleFilter{CustomFields: []model.CustomFieldFilter{{CustomFieldId: field.ID, Value: "invalid"}}}
+	articles, count = SearchArticle(ctx, "", filter)
+
+	if len(articles) != 2 || count != 2 {
+		t.Fatalf("Invalid value must be ignored, but was: %v %v", len(articles), count)
+	}
+
+	articles, count = SearchArticle(ctx, "", &model.SearchArticleFilter{SortCustomFieldId: field.ID, SortCustomField: "asc"})
+
+	if len(articles) != 3 || count != 3 {
+		t.Fatalf("Three articles must have been found, but was: %v %v", len(articles), count)
+	}
+
+	// articles without value come last
+	if articles[0].ID != article2.ID ||
+		articles[1].ID != article1.ID ||
+		articles[2].ID != article3.ID {
+		t.Fatal("Articles in wrong order")
+	}
+}
+
 func createTestArticle(t *testing.T, orga *model.Organization, user *model.User, lang1, lang2 *model.Language, title1, title2, contentText1, contentText2 string) *model.Article {
 	if contentText1 == "" {
 		contentText1 = "content 1"
@@ -32,6 +32,17 @@ func DeleteTag(orga *model.Organization, userId, tagId hide.ID) error {
 		return errs.TxBegin
 	}
 
+	trash := &model.Trash{OrganizationId: orga.ID,
+		UserId:   userId,
+		Type:     model.TrashTypeTag,
+		ObjectId: tagId,
+		Name:     tag.Name}
+
+	if err := model.SaveTrashSnapshot(tx, trash); err != nil {
+		logbuch.Error("Error moving tag to trash", logbuch.Fields{"err": err, "tag_id": tagId, "user_id": userId})
+		return errs.Saving
+	}
+
 	if err := model.DeleteArticleTagByOrganizationIdAndTagId(tx, orga.ID, tagId); err != nil {
 		return errs.Saving
 	}
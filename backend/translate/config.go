@@ -0,0 +1,23 @@
+package translate
+
+import (
+	"emviwiki/shared/config"
+)
+
+var (
+	provider Provider
+)
+
+func LoadConfig() {
+	SetProvider(SelectProvider(config.Get().Translation))
+}
+
+// SetProvider sets the provider used to translate content. Passing nil disables machine translation.
+func SetProvider(p Provider) {
+	provider = p
+}
+
+// Enabled returns true if a translation provider is configured.
+func Enabled() bool {
+	return provider != nil
+}
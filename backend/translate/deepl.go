@@ -0,0 +1,90 @@
+package translate
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	// DeepL requires the variant for some target languages
+	deepLTargetLanguages = map[string]string{
+		"en": "EN-US",
+		"pt": "PT-PT",
+	}
+)
+
+// DeepLProvider translates using the DeepL API.
+// The URL is either https://api.deepl.com or https://api-free.deepl.com, depending on the plan.
+// Tags are handled as XML, so that they are kept in place.
+type DeepLProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type deepLResponse struct {
+	Translations []struct {
+		Text string `json:"text"`
+	} `json:"translations"`
+}
+
+type deepLError struct {
+	Message string `json:"message"`
+}
+
+func (e *deepLError) String() string {
+	return e.Message
+}
+
+// NewDeepLProvider creates a new provider for the DeepL API at given URL.
+func NewDeepLProvider(url, apiKey string, timeout time.Duration) *DeepLProvider {
+	return &DeepLProvider{strings.TrimSuffix(url, "/"), apiKey, &http.Client{Timeout: timeout}}
+}
+
+func (provider *DeepLProvider) Translate(texts []string, source, target string) ([]string, error) {
+	form := url.Values{}
+	form.Set("source_lang", strings.ToUpper(source))
+	form.Set("target_lang", getDeepLTargetLanguage(target))
+	form.Set("tag_handling", "xml")
+
+	for _, text := range texts {
+		form.Add("text", text)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, provider.url+"/v2/translate", strings.NewReader(form.Encode()))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Authorization", "DeepL-Auth-Key "+provider.apiKey)
+	var resp deepLResponse
+
+	if err := doRequest(provider.client, req, &resp, new(deepLError)); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Translations) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, but got %d", len(texts), len(resp.Translations))
+	}
+
+	translations := make([]string, 0, len(resp.Translations))
+
+	for _, t := range resp.Translations {
+		translations = append(translations, t.Text)
+	}
+
+	return translations, nil
+}
+
+func getDeepLTargetLanguage(code string) string {
+	if lang, ok := deepLTargetLanguages[code]; ok {
+		return lang
+	}
+
+	return strings.ToUpper(code)
+}
@@ -0,0 +1,222 @@
+package translate
+
+import (
+	"emviwiki/backend/prosemirror"
+	"errors"
+	"fmt"
+	"golang.org/x/net/html"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// text nodes are wrapped in a text tag, all other inline nodes are replaced by a placeholder tag
+	textTag        = "t"
+	placeholderTag = "x"
+	idAttr         = "id"
+
+	maxTextsPerRequest = 50
+)
+
+var (
+	// ErrDisabled is returned if no translation provider is configured.
+	ErrDisabled = errors.New("machine translation is disabled")
+
+	// content of these nodes is kept as it is
+	untranslatedNodes = map[string]bool{"code_block": true}
+)
+
+// TranslateContent translates the title and the text of the document from the source into the target language.
+// The document is modified in place, keeping the node structure and marks.
+// Mentions, files, line breaks, inline code and code blocks are not translated.
+func TranslateContent(title string, doc *prosemirror.Node, source, target string) (string, error) {
+	if provider == nil {
+		return "", ErrDisabled
+	}
+
+	blocks := make([]*prosemirror.Node, 0)
+	collectTextBlocks(doc, &blocks)
+	texts := make([]string, 0, len(blocks)+1)
+	texts = append(texts, html.EscapeString(title))
+
+	for _, block := range blocks {
+		texts = append(texts, encodeInline(block.Content))
+	}
+
+	translated, err := translateTexts(texts, source, target)
+
+	if err != nil {
+		return "", err
+	}
+
+	for i, block := range blocks {
+		content, err := decodeInline(translated[i+1], block.Content)
+
+		if err != nil {
+			return "", err
+		}
+
+		block.Content = content
+	}
+
+	return strings.TrimSpace(html.UnescapeString(translated[0])), nil
+}
+
+// Sends the texts to the provider in batches.
+func translateTexts(texts []string, source, target string) ([]string, error) {
+	result := make([]string, 0, len(texts))
+
+	for len(texts) > 0 {
+		n := len(texts)
+
+		if n > maxTextsPerRequest {
+			n = maxTextsPerRequest
+		}
+
+		translated, err := provider.Translate(texts[:n], source, target)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if len(translated) != n {
+			return nil, fmt.Errorf("expected %d translations, but got %d", n, len(translated))
+		}
+
+		result = append(result, translated...)
+		texts = texts[n:]
+	}
+
+	return result, nil
+}
+
+// Collects all blocks containing text which needs to be translated.
+func collectTextBlocks(node *prosemirror.Node, blocks *[]*prosemirror.Node) {
+	if untranslatedNodes[node.Type] {
+		return
+	}
+
+	for i := range node.Content {
+		if isTranslatableText(&node.Content[i]) {
+			*blocks = append(*blocks, node)
+			return
+		}
+	}
+
+	for i := range node.Content {
+		collectTextBlocks(&node.Content[i], blocks)
+	}
+}
+
+// Encodes the inline content of a block to HTML, so that the provider keeps the position of marks and inline nodes.
+// Each node is referenced by its index.
+func encodeInline(nodes []prosemirror.Node) string {
+	var sb strings.Builder
+
+	for i := range nodes {
+		if isTranslatableText(&nodes[i]) {
+			sb.WriteString(fmt.Sprintf(`<%s %s="%d">%s</%s>`, textTag, idAttr, i, html.EscapeString(nodes[i].Text), textTag))
+		} else {
+			sb.WriteString(fmt.Sprintf(`<%s %s="%d"/>`, placeholderTag, idAttr, i))
+		}
+	}
+
+	return sb.String()
+}
+
+// Decodes the translated HTML back to inline nodes. Text takes the marks of the node it was wrapped in,
+// placeholders are replaced by the original nodes. Placeholders dropped by the provider are added at the end.
+func decodeInline(translated string, nodes []prosemirror.Node) ([]prosemirror.Node, error) {
+	tokenizer := html.NewTokenizer(strings.NewReader(translated))
+	content := make([]prosemirror.Node, 0, len(nodes))
+	used := make(map[int]bool)
+	var marks []prosemirror.Mark
+
+	for {
+		tokenType := tokenizer.Next()
+
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				return nil, tokenizer.Err()
+			}
+
+			for i := range nodes {
+				if !isTranslatableText(&nodes[i]) && !used[i] {
+					content = append(content, nodes[i])
+				}
+			}
+
+			return mergeText(content), nil
+		case html.TextToken:
+			if text := string(tokenizer.Text()); text != "" {
+				content = append(content, prosemirror.Node{Type: "text", Text: text, Marks: marks})
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, _ := tokenizer.TagName()
+			i, ok := getNodeIndex(tokenizer, len(nodes))
+
+			if !ok {
+				continue
+			}
+
+			if string(name) == textTag && tokenType == html.StartTagToken {
+				marks = nodes[i].Marks
+			} else if string(name) == placeholderTag && !isTranslatableText(&nodes[i]) && !used[i] {
+				content = append(content, nodes[i])
+				used[i] = true
+			}
+		case html.EndTagToken:
+			marks = nil
+		}
+	}
+}
+
+// Returns the index of the node referenced by the current tag, or false if it's missing or invalid.
+func getNodeIndex(tokenizer *html.Tokenizer, n int) (int, bool) {
+	for {
+		key, val, more := tokenizer.TagAttr()
+
+		if string(key) == idAttr {
+			i, err := strconv.Atoi(string(val))
+			return i, err == nil && i >= 0 && i < n
+		}
+
+		if !more {
+			return 0, false
+		}
+	}
+}
+
+// Merges adjacent text nodes having the same marks.
+func mergeText(nodes []prosemirror.Node) []prosemirror.Node {
+	result := make([]prosemirror.Node, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.Type == "text" && len(result) != 0 && result[len(result)-1].Type == "text" && reflect.DeepEqual(result[len(result)-1].Marks, node.Marks) {
+			result[len(result)-1].Text += node.Text
+			continue
+		}
+
+		result = append(result, node)
+	}
+
+	return result
+}
+
+// Text is translated unless it's formatted as code.
+func isTranslatableText(node *prosemirror.Node) bool {
+	if node.Type != "text" {
+		return false
+	}
+
+	for _, mark := range node.Marks {
+		if mark.Type == "code" {
+			return false
+		}
+	}
+
+	return true
+}
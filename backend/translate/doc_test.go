@@ -0,0 +1,201 @@
+package translate
+
+import (
+	"emviwiki/backend/prosemirror"
+	"emviwiki/shared/testutil"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// upperProvider "translates" by turning all text outside of tags to upper case.
+type upperProvider struct {
+	requests int
+}
+
+func (p *upperProvider) Translate(texts []string, source, target string) ([]string, error) {
+	p.requests++
+	result := make([]string, 0, len(texts))
+
+	for _, text := range texts {
+		var sb strings.Builder
+		inTag := false
+
+		for _, r := range text {
+			if r == '<' {
+				inTag = true
+			} else if r == '>' {
+				inTag = false
+			}
+
+			if inTag {
+				sb.WriteRune(r)
+			} else {
+				sb.WriteRune(unicode.ToUpper(r))
+			}
+		}
+
+		result = append(result, sb.String())
+	}
+
+	return result, nil
+}
+
+// replaceProvider returns a fixed translation for all texts.
+type replaceProvider struct {
+	translation string
+}
+
+func (p *replaceProvider) Translate(texts []string, source, target string) ([]string, error) {
+	result := make([]string, len(texts))
+
+	for i := range result {
+		result[i] = p.translation
+	}
+
+	return result, nil
+}
+
+func TestTranslateContent(t *testing.T) {
+	p := &upperProvider{}
+	provider = p
+	defer func() {
+		provider = nil
+	}()
+	in := `{"type":"doc","content":[
+		{"type":"headline","attrs":{"level":1},"content":[{"type":"text","text":"headline"}]},
+		{"type":"paragraph","content":[
+			{"type":"text","text":"hello "},
+			{"type":"mention","attrs":{"id":"id","title":"user","type":"user"}},
+			{"type":"text","marks":[{"type":"bold"}],"text":" <world> & "},
+			{"type":"text","marks":[{"type":"code"}],"text":"code"},
+			{"type":"hard_break"},
+			{"type":"text","marks":[{"type":"link","attrs":{"href":"https://emvi.com"}}],"text":"link"}
+		]},
+		{"type":"code_block","content":[{"type":"text","text":"code block"}]},
+		{"type":"bullet_list","content":[{"type":"list_item","content":[{"type":"paragraph","content":[{"type":"text","text":"item"}]}]}]},
+		{"type":"paragraph"}
+	]}`
+	expected := `{"type":"doc","content":[
+		{"type":"headline","attrs":{"level":1},"content":[{"type":"text","text":"HEADLINE"}]},
+		{"type":"paragraph","content":[
+			{"type":"text","text":"HELLO "},
+			{"type":"mention","attrs":{"id":"id","title":"user","type":"user"}},
+			{"type":"text","marks":[{"type":"bold"}],"text":" <WORLD> & "},
+			{"type":"text","marks":[{"type":"code"}],"text":"code"},
+			{"type":"hard_break"},
+			{"type":"text","marks":[{"type":"link","attrs":{"href":"https://emvi.com"}}],"text":"LINK"}
+		]},
+		{"type":"code_block","content":[{"type":"text","text":"code block"}]},
+		{"type":"bullet_list","content":[{"type":"list_item","content":[{"type":"paragraph","content":[{"type":"text","text":"ITEM"}]}]}]},
+		{"type":"paragraph"}
+	]}`
+	doc, err := prosemirror.ParseDoc(in)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	title, err := TranslateContent("title & <more>", doc, "en", "de")
+
+	if err != nil {
+		t.Fatalf("Content must have been translated, but was: %v", err)
+	}
+
+	if title != "TITLE & <MORE>" {
+		t.Fatalf("Title must have been translated, but was: %v", title)
+	}
+
+	out, _ := json.Marshal(doc)
+	testutil.AssertJSONEquals(t, string(out), expected)
+
+	if p.requests != 1 {
+		t.Fatalf("Texts must have been translated in one request, but was: %v", p.requests)
+	}
+}
+
+func TestTranslateContentBatches(t *testing.T) {
+	p := &upperProvider{}
+	provider = p
+	defer func() {
+		provider = nil
+	}()
+	doc := &prosemirror.Node{Type: "doc"}
+
+	for i := 0; i < maxTextsPerRequest*2; i++ {
+		doc.Content = append(doc.Content, prosemirror.Node{Type: "paragraph", Content: []prosemirror.Node{{Type: "text", Text: "text"}}})
+	}
+
+	if _, err := TranslateContent("title", doc, "en", "de"); err != nil {
+		t.Fatal(err)
+	}
+
+	if p.requests != 3 {
+		t.Fatalf("Texts must have been translated in three requests, but was: %v", p.requests)
+	}
+
+	for _, block := range doc.Content {
+		if block.Content[0].Text != "TEXT" {
+			t.Fatalf("Text must have been translated, but was: %v", block.Content[0].Text)
+		}
+	}
+}
+
+func TestTranslateContentDisabled(t *testing.T) {
+	provider = nil
+
+	if _, err := TranslateContent("title", &prosemirror.Node{Type: "doc"}, "en", "de"); err != ErrDisabled {
+		t.Fatalf("Translation must be disabled, but was: %v", err)
+	}
+}
+
+func TestDecodeInline(t *testing.T) {
+	nodes := []prosemirror.Node{
+		{Type: "text", Text: "one "},
+		{Type: "text", Marks: []prosemirror.Mark{{Type: "bold"}}, Text: "two"},
+		{Type: "mention", Attrs: map[string]interface{}{"id": "id"}},
+		{Type: "hard_break"},
+	}
+	input := []string{
+		// reordered by the provider
+		`<t id="1">zwei</t> <t id="0">eins</t><x id="2"></x><x id="3"/>`,
+		// placeholders dropped, unknown tags and indices ignored
+		`<t id="0">eins</t><b>fett</b><t id="9">neun</t>`,
+		// text outside of tags, duplicated placeholder
+		`eins <x id="2"/><x id="2"/><t id="1">zwei</t>`,
+	}
+	expected := []string{
+		`[{"type":"text","marks":[{"type":"bold"}],"text":"zwei"},{"type":"text","text":" eins"},{"type":"mention","attrs":{"id":"id"}},{"type":"hard_break"}]`,
+		`[{"type":"text","text":"einsfettneun"},{"type":"mention","attrs":{"id":"id"}},{"type":"hard_break"}]`,
+		`[{"type":"text","text":"eins "},{"type":"mention","attrs":{"id":"id"}},{"type":"text","marks":[{"type":"bold"}],"text":"zwei"},{"type":"hard_break"}]`,
+	}
+
+	for i, in := range input {
+		content, err := decodeInline(in, nodes)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		out, _ := json.Marshal(content)
+		testutil.AssertJSONEquals(t, string(out), expected[i])
+	}
+}
+
+func TestTranslateContentMissingText(t *testing.T) {
+	provider = &replaceProvider{""}
+	defer func() {
+		provider = nil
+	}()
+	doc := &prosemirror.Node{Type: "doc", Content: []prosemirror.Node{
+		{Type: "paragraph", Content: []prosemirror.Node{{Type: "text", Text: "text"}, {Type: "hard_break"}}},
+	}}
+
+	if _, err := TranslateContent("title", doc, "en", "de"); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := json.Marshal(doc)
+	testutil.AssertJSONEquals(t, string(out), `{"type":"doc","content":[{"type":"paragraph","content":[{"type":"hard_break"}]}]}`)
+}
@@ -0,0 +1,70 @@
+package translate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LibreTranslateProvider translates using the LibreTranslate API, which can be self hosted.
+// The API key is optional for instances not requiring one.
+type LibreTranslateProvider struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+type libreTranslateRequest struct {
+	Q      []string `json:"q"`
+	Source string   `json:"source"`
+	Target string   `json:"target"`
+	Format string   `json:"format"`
+	APIKey string   `json:"api_key,omitempty"`
+}
+
+type libreTranslateResponse struct {
+	TranslatedText []string `json:"translatedText"`
+}
+
+type libreTranslateError struct {
+	Error string `json:"error"`
+}
+
+func (e *libreTranslateError) String() string {
+	return e.Error
+}
+
+// NewLibreTranslateProvider creates a new provider for the LibreTranslate instance at given URL.
+func NewLibreTranslateProvider(url, apiKey string, timeout time.Duration) *LibreTranslateProvider {
+	return &LibreTranslateProvider{strings.TrimSuffix(url, "/"), apiKey, &http.Client{Timeout: timeout}}
+}
+
+func (provider *LibreTranslateProvider) Translate(texts []string, source, target string) ([]string, error) {
+	body, err := json.Marshal(libreTranslateRequest{texts, source, target, "html", provider.apiKey})
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, provider.url+"/translate", bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	var resp libreTranslateResponse
+
+	if err := doRequest(provider.client, req, &resp, new(libreTranslateError)); err != nil {
+		return nil, err
+	}
+
+	if len(resp.TranslatedText) != len(texts) {
+		return nil, fmt.Errorf("expected %d translations, but got %d", len(texts), len(resp.TranslatedText))
+	}
+
+	return resp.TranslatedText, nil
+}
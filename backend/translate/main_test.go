@@ -0,0 +1,12 @@
+package translate
+
+import (
+	"emviwiki/shared/testutil"
+	"os"
+	"testing"
+)
+
+func TestMain(m *testing.M) {
+	testutil.SetTestLogger()
+	os.Exit(m.Run())
+}
@@ -0,0 +1,72 @@
+package translate
+
+import (
+	"emviwiki/shared/config"
+	"encoding/json"
+	"fmt"
+	"github.com/emvi/logbuch"
+	"net/http"
+	"time"
+)
+
+const (
+	ProviderLibreTranslate = "libretranslate"
+	ProviderDeepL          = "deepl"
+
+	defaultTimeout = 60 // seconds
+)
+
+// Provider translates texts from the source into the target language.
+// Languages are ISO 639-1 codes. The texts are HTML fragments and the tags must be kept as they are.
+// The translations are returned in the same order as the texts.
+type Provider interface {
+	Translate(texts []string, source, target string) ([]string, error)
+}
+
+// SelectProvider selects the translation provider by configured provider name.
+// If no name or an unknown name is configured, machine translation is disabled and nil is returned.
+func SelectProvider(c config.Translation) Provider {
+	timeout := time.Second * time.Duration(c.Timeout)
+
+	if timeout <= 0 {
+		timeout = time.Second * defaultTimeout
+	}
+
+	switch c.Provider {
+	case ProviderLibreTranslate:
+		logbuch.Info("Using LibreTranslate for machine translation", logbuch.Fields{"url": c.URL})
+		return NewLibreTranslateProvider(c.URL, c.APIKey, timeout)
+	case ProviderDeepL:
+		logbuch.Info("Using DeepL for machine translation", logbuch.Fields{"url": c.URL})
+		return NewDeepLProvider(c.URL, c.APIKey, timeout)
+	}
+
+	logbuch.Info("Machine translation is disabled")
+	return nil
+}
+
+// Sends the request and decodes the JSON response into resp.
+// The error message is read from the response into errResp for status codes other than 200.
+func doRequest(client *http.Client, req *http.Request, resp interface{}, errResp fmt.Stringer) error {
+	r, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			logbuch.Error("Error closing translation response body", logbuch.Fields{"err": err})
+		}
+	}()
+
+	if r.StatusCode != http.StatusOK {
+		if err := json.NewDecoder(r.Body).Decode(errResp); err != nil {
+			return fmt.Errorf("translation request failed with status %d", r.StatusCode)
+		}
+
+		return fmt.Errorf("translation request failed with status %d: %s", r.StatusCode, errResp.String())
+	}
+
+	return json.NewDecoder(r.Body).Decode(resp)
+}
@@ -0,0 +1,107 @@
+package translate
+
+import (
+	"emviwiki/shared/config"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestSelectProvider(t *testing.T) {
+	if SelectProvider(config.Translation{}) != nil {
+		t.Fatal("Translation must be disabled")
+	}
+
+	if _, ok := SelectProvider(config.Translation{Provider: ProviderLibreTranslate}).(*LibreTranslateProvider); !ok {
+		t.Fatal("LibreTranslate must have been selected")
+	}
+
+	if _, ok := SelectProvider(config.Translation{Provider: ProviderDeepL}).(*DeepLProvider); !ok {
+		t.Fatal("DeepL must have been selected")
+	}
+}
+
+func TestLibreTranslateProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req libreTranslateRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		if r.Method != http.MethodPost || r.URL.Path != "/translate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if req.APIKey != "key" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"error":"Invalid API key"}`))
+			return
+		}
+
+		if req.Source != "en" || req.Target != "de" || req.Format != "html" || len(req.Q) != 2 {
+			t.Fatalf("Request not as expected: %v", req)
+		}
+
+		w.Write([]byte(`{"translatedText":["<t id=\"0\">Hallo</t>","Welt"]}`))
+	}))
+	defer server.Close()
+	provider := NewLibreTranslateProvider(server.URL+"/", "key", time.Second)
+	translations, err := provider.Translate([]string{`<t id="0">Hello</t>`, "World"}, "en", "de")
+
+	if err != nil {
+		t.Fatalf("Texts must have been translated, but was: %v", err)
+	}
+
+	if len(translations) != 2 || translations[0] != `<t id="0">Hallo</t>` || translations[1] != "Welt" {
+		t.Fatalf("Translations not as expected: %v", translations)
+	}
+
+	provider = NewLibreTranslateProvider(server.URL, "invalid", time.Second)
+
+	if _, err := provider.Translate([]string{"Hello"}, "en", "de"); err == nil || err.Error() != "translation request failed with status 403: Invalid API key" {
+		t.Fatalf("Error must have been returned, but was: %v", err)
+	}
+}
+
+func TestDeepLProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/translate" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "DeepL-Auth-Key key" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"Wrong endpoint"}`))
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+
+		if r.Form.Get("source_lang") != "DE" || r.Form.Get("target_lang") != "EN-US" || r.Form.Get("tag_handling") != "xml" || len(r.Form["text"]) != 2 {
+			t.Fatalf("Request not as expected: %v", r.Form)
+		}
+
+		w.Write([]byte(`{"translations":[{"detected_source_language":"DE","text":"<t id=\"0\">Hello</t>"},{"detected_source_language":"DE","text":"World"}]}`))
+	}))
+	defer server.Close()
+	provider := NewDeepLProvider(server.URL, "key", time.Second)
+	translations, err := provider.Translate([]string{`<t id="0">Hallo</t>`, "Welt"}, "de", "en")
+
+	if err != nil {
+		t.Fatalf("Texts must have been translated, but was: %v", err)
+	}
+
+	if len(translations) != 2 || translations[0] != `<t id="0">Hello</t>` || translations[1] != "World" {
+		t.Fatalf("Translations not as expected: %v", translations)
+	}
+
+	provider = NewDeepLProvider(server.URL, "invalid", time.Second)
+
+	if _, err := provider.Translate([]string{"Hallo"}, "de", "en"); err == nil || err.Error() != "translation request failed with status 403: Wrong endpoint" {
+		t.Fatalf("Error must have been returned, but was: %v", err)
+	}
+}
@@ -0,0 +1,17 @@
+package trash
+
+import (
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+)
+
+// ReadTrash returns the deleted objects of the organization.
+// Administrators and moderators see all objects, other users only the ones they have deleted themselves.
+func ReadTrash(orga *model.Organization, userId hide.ID) []model.Trash {
+	if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err == nil {
+		return model.FindTrashByOrganizationIdAndUserIdWithUser(orga.ID, 0)
+	}
+
+	return model.FindTrashByOrganizationIdAndUserIdWithUser(orga.ID, userId)
+}
@@ -0,0 +1,27 @@
+package trash
+
+import (
+	"emviwiki/backend/articlelist"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestReadTrash(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	list, _ := testutil.CreateArticleList(t, orga, user, lang, true)
+
+	if err := articlelist.DeleteArticleList(orga, user.ID, list.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if trash := ReadTrash(orga, user.ID); len(trash) != 1 || trash[0].User == nil || trash[0].Data != "{}" {
+		t.Fatalf("Trash must be returned without data, but was: %v", trash)
+	}
+
+	if trash := ReadTrash(orga, user2.ID); len(trash) != 0 {
+		t.Fatalf("User must only see objects deleted by themselves, but was: %v", trash)
+	}
+}
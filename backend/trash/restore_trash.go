@@ -0,0 +1,33 @@
+package trash
+
+import (
+	"emviwiki/backend/errs"
+	"emviwiki/backend/perm"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+)
+
+// RestoreTrash restores a deleted object including the data belonging to it.
+// References to objects which have been deleted in the meantime are skipped.
+// Only administrators, moderators and the user who deleted the object can restore it.
+func RestoreTrash(orga *model.Organization, userId, trashId hide.ID) error {
+	trash := model.GetTrashByOrganizationIdAndId(orga.ID, trashId)
+
+	if trash == nil {
+		return errs.TrashNotFound
+	}
+
+	if trash.UserId != userId {
+		if _, err := perm.CheckUserIsAdminOrMod(orga.ID, userId); err != nil {
+			return err
+		}
+	}
+
+	if err := model.RestoreTrash(nil, trash); err != nil {
+		logbuch.Error("Error restoring trash", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": userId, "trash_id": trashId})
+		return errs.Saving
+	}
+
+	return nil
+}
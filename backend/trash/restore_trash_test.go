@@ -0,0 +1,215 @@
+package trash
+
+import (
+	"emviwiki/backend/article"
+	"emviwiki/backend/errs"
+	"emviwiki/backend/tag"
+	"emviwiki/backend/usergroup"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+)
+
+func TestRestoreTrashArticle(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	a := testutil.CreateArticle(t, orga, user, lang, true, true)
+	list, _ := testutil.CreateArticleList(t, orga, user, lang, true)
+	testutil.CreateArticleListEntry(t, list, a, 1)
+	testutil.CreateFile(t, orga, user, a, "")
+	field := createCustomField(t, orga, "Owner", model.CustomFieldUser)
+	articleTag := model.FindTagByOrganizationIdAndUserIdAndArticleId(orga.ID, user.ID, a.ID)[0]
+	createCustomFieldTag(t, field, &articleTag)
+	value := &model.ArticleCustomField{ArticleId: a.ID, CustomFieldId: field.ID, UserId: user2.ID}
+
+	if err := model.SaveArticleCustomField(nil, value); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := article.DeleteArticle(orga, user.ID, a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	trash := ReadTrash(orga, user.ID)
+
+	if len(trash) != 1 || trash[0].Type != model.TrashTypeArticle || trash[0].ObjectId != a.ID || trash[0].Name != "title 2" {
+		t.Fatalf("Article must have been moved to trash, but was: %v", trash)
+	}
+
+	if err := RestoreTrash(orga, user2.ID, trash[0].ID); err != errs.PermissionDenied {
+		t.Fatalf("Only admins, mods and the user who deleted the object must be allowed to restore, but was: %v", err)
+	}
+
+	if err := RestoreTrash(orga, user.ID, trash[0].ID); err != nil {
+		t.Fatalf("Article must have been restored, but was: %v", err)
+	}
+
+	if model.GetArticleByOrganizationIdAndId(orga.ID, a.ID) == nil {
+		t.Fatal("Article must exist")
+	}
+
+	if content := model.FindArticleContentByArticleId(a.ID); len(content) != 3 {
+		t.Fatalf("Article content must have been restored, but was: %v", len(content))
+	}
+
+	if tags := model.FindTagByOrganizationIdAndUserIdAndArticleId(orga.ID, user.ID, a.ID); len(tags) != 4 {
+		t.Fatalf("Tags must have been restored, but was: %v", len(tags))
+	}
+
+	if model.CountArticleListEntryByArticleListId(list.ID) != 1 {
+		t.Fatal("List entry must have been restored")
+	}
+
+	if len(model.FindFileByOrganizationIdAndArticleIdAndUniqueInOrganization(nil, orga.ID, a.ID)) != 1 {
+		t.Fatal("File must have been restored")
+	}
+
+	if values := model.FindArticleCustomFieldByArticleId(a.ID); len(values) != 1 || values[0].UserId != user2.ID {
+		t.Fatalf("Custom field value must have been restored, but was: %v", values)
+	}
+
+	if tags := model.FindTagByCustomFieldId(field.ID); len(tags) != 1 || tags[0].ID != articleTag.ID {
+		t.Fatalf("Required tag of custom field must have been restored, but was: %v", tags)
+	}
+
+	if len(ReadTrash(orga, user.ID)) != 0 {
+		t.Fatal("Trash must be empty")
+	}
+
+	if err := RestoreTrash(orga, user.ID, trash[0].ID); err != errs.TrashNotFound {
+		t.Fatalf("Trash must not be found, but was: %v", err)
+	}
+}
+
+func TestRestoreTrashColumnDefaults(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	a := testutil.CreateArticle(t, orga, user, lang, true, true)
+	testutil.CreateFile(t, orga, user, a, "")
+
+	if err := article.DeleteArticle(orga, user.ID, a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// snapshots taken before a column was added don't contain it
+	trash := ReadTrash(orga, user.ID)
+
+	if _, err := model.GetConnection().Exec(nil, `UPDATE "trash"
+		SET data = jsonb_set(data, '{file}', (SELECT jsonb_agg(f - 'quarantined') FROM jsonb_array_elements(data->'file') f))
+		WHERE id = $1`, trash[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := RestoreTrash(orga, user.ID, trash[0].ID); err != nil {
+		t.Fatalf("Article must have been restored, but was: %v", err)
+	}
+
+	files := model.FindFileByOrganizationIdAndArticleIdAndUniqueInOrganization(nil, orga.ID, a.ID)
+
+	if len(files) != 1 || files[0].Quarantined {
+		t.Fatalf("File must have been restored using the column default, but was: %v", files)
+	}
+}
+
+func TestRestoreTrashUserGroup(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	a := testutil.CreateArticle(t, orga, user, lang, false, false)
+	group := testutil.CreateUserGroup(t, orga, "group")
+	testutil.CreateUserGroupMember(t, group, user, true)
+	testutil.CreateUserGroupMember(t, group, user2, false)
+	testutil.CreateArticleAccess(t, a, nil, group, false)
+
+	if err := usergroup.DeleteUserGroup(orga, user.ID, group.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	trash := ReadTrash(orga, user.ID)
+
+	if len(trash) != 1 || trash[0].Name != "group" {
+		t.Fatalf("Group must have been moved to trash, but was: %v", trash)
+	}
+
+	if err := RestoreTrash(orga, user.ID, trash[0].ID); err != nil {
+		t.Fatalf("Group must have been restored, but was: %v", err)
+	}
+
+	if model.GetUserGroupByOrganizationIdAndId(orga.ID, group.ID) == nil {
+		t.Fatal("Group must exist")
+	}
+
+	if members := model.FindUserGroupMemberUserIdByUserGroupId(group.ID); len(members) != 2 {
+		t.Fatalf("Group members must have been restored, but was: %v", len(members))
+	}
+
+	if access := model.FindArticleAccessByArticleIdAndUserId(a.ID, user2.ID); len(access) != 1 {
+		t.Fatalf("Article access must have been restored, but was: %v", len(access))
+	}
+}
+
+func TestRestoreTrashTagRecreated(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	a := testutil.CreateArticle(t, orga, user, lang, true, true)
+	tags := model.FindTagByOrganizationIdAndUserIdAndArticleId(orga.ID, user.ID, a.ID)
+	field := createCustomField(t, orga, "Status", model.CustomFieldText)
+	createCustomFieldTag(t, field, &tags[0])
+
+	if err := tag.DeleteTag(orga, user.ID, tags[0].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// a tag with the same name is created while the old one is in trash
+	recreated := testutil.CreateTag(t, orga, tags[0].Name)
+	trash := ReadTrash(orga, user.ID)
+
+	if len(trash) != 1 || trash[0].Type != model.TrashTypeTag {
+		t.Fatalf("Tag must have been moved to trash, but was: %v", trash)
+	}
+
+	if err := RestoreTrash(orga, user.ID, trash[0].ID); err != nil {
+		t.Fatalf("Tag must have been restored, but was: %v", err)
+	}
+
+	if model.GetTagByOrganizationIdAndId(orga.ID, tags[0].ID) != nil {
+		t.Fatal("Tag must not have been restored, since it was created again")
+	}
+
+	found := false
+
+	for _, tag := range model.FindTagByOrganizationIdAndUserIdAndArticleId(orga.ID, user.ID, a.ID) {
+		if tag.ID == recreated.ID {
+			found = true
+		}
+	}
+
+	if !found {
+		t.Fatal("Article must have been tagged with the recreated tag")
+	}
+
+	if required := model.FindTagByCustomFieldId(field.ID); len(required) != 1 || required[0].ID != recreated.ID {
+		t.Fatalf("Recreated tag must have been required by the custom field, but was: %v", required)
+	}
+}
+
+func createCustomField(t *testing.T, orga *model.Organization, name, fieldType string) *model.CustomField {
+	field := &model.CustomField{OrganizationId: orga.ID, Name: name, Type: fieldType}
+
+	if err := model.SaveCustomField(nil, field); err != nil {
+		t.Fatal(err)
+	}
+
+	return field
+}
+
+func createCustomFieldTag(t *testing.T, field *model.CustomField, tag *model.Tag) {
+	if err := model.SaveCustomFieldTag(nil, &model.CustomFieldTag{CustomFieldId: field.ID, TagId: tag.ID}); err != nil {
+		t.Fatal(err)
+	}
+}
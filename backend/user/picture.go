@@ -22,10 +22,6 @@ func UploadUserPicture(r *http.Request, userId hide.ID) error {
 		return errs.PermissionDenied
 	}
 
-	if err := deleteUserPicture(user); err != nil {
-		logbuch.Debug("Error deleting old user picture", logbuch.Fields{"err": err})
-	}
-
 	uniqueName, err := content.UploadFile(&content.File{
 		Request:       r,
 		UserId:        userId,
@@ -37,6 +33,11 @@ func UploadUserPicture(r *http.Request, userId hide.ID) error {
 		return errs.UploadingFile
 	}
 
+	// the old picture is deleted after the new one has been uploaded, so that it is kept if the upload fails
+	if err := deleteUserPicture(user); err != nil {
+		logbuch.Debug("Error deleting old user picture", logbuch.Fields{"err": err})
+	}
+
 	user.Picture = null.NewString(uniqueName, true)
 
 	if err := model.SaveUser(nil, user, false); err != nil {
@@ -11,7 +11,9 @@ import (
 )
 
 func DeleteUserGroup(organization *model.Organization, userId, groupId hide.ID) error {
-	if _, err := checkGroupExists(organization, groupId); err != nil {
+	group, err := checkGroupExists(organization, groupId)
+
+	if err != nil {
 		return err
 	}
 
@@ -37,6 +39,17 @@ func DeleteUserGroup(organization *model.Organization, userId, groupId hide.ID)
 		return errs.Saving
 	}
 
+	trash := &model.Trash{OrganizationId: organization.ID,
+		UserId:   userId,
+		Type:     model.TrashTypeUserGroup,
+		ObjectId: groupId,
+		Name:     group.Name}
+
+	if err := model.SaveTrashSnapshot(tx, trash); err != nil {
+		logbuch.Error("Error moving user group to trash", logbuch.Fields{"err": err, "group_id": groupId, "user_id": userId})
+		return errs.Saving
+	}
+
 	if err := model.DeleteUserGroupById(tx, groupId); err != nil {
 		return errs.Saving
 	}
@@ -41,6 +41,7 @@ func TestUpdateBalance(t *testing.T) {
 	inactive := testutil.CreateUser(t, expert, 333, "user@test.com")
 	ro := testutil.CreateUser(t, expert, 444, "ro@test.com")
 	disabled := testutil.CreateUser(t, expert, 555, "disabled@test.com")
+	bot := testutil.CreateBot(t, expert, "bot")
 	active1.OrganizationMember.LastSeen = time.Date(2020, 6, 14, 0, 0, 0, 0, time.Local)
 	active2.OrganizationMember.LastSeen = time.Date(2020, 6, 9, 0, 0, 0, 0, time.Local)
 	inactive.OrganizationMember.LastSeen = time.Date(2020, 5, 24, 0, 0, 0, 0, time.Local)
@@ -48,6 +49,7 @@ func TestUpdateBalance(t *testing.T) {
 	ro.OrganizationMember.LastSeen = time.Date(2020, 5, 26, 0, 0, 0, 0, time.Local)
 	disabled.OrganizationMember.Active = false
 	disabled.OrganizationMember.LastSeen = time.Date(2020, 5, 26, 0, 0, 0, 0, time.Local)
+	bot.OrganizationMember.LastSeen = time.Date(2020, 5, 24, 0, 0, 0, 0, time.Local)
 
 	if err := model.SaveOrganizationMember(nil, active1.OrganizationMember); err != nil {
 		t.Fatal(err)
@@ -69,6 +71,11 @@ func TestUpdateBalance(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	// bots are not billed
+	if err := model.SaveOrganizationMember(nil, bot.OrganizationMember); err != nil {
+		t.Fatal(err)
+	}
+
 	UpdateBalance()
 
 	if len(mock.AddBalanceParams) != 1 {
@@ -0,0 +1,120 @@
+package campaign
+
+import (
+	"bytes"
+	"emviwiki/batch/errs"
+	"emviwiki/shared/i18n"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"html/template"
+	"time"
+)
+
+const (
+	reminderMailTemplate = "mail_reading_campaign.html"
+	reminderSubject      = "reading_campaign"
+	reminderDueDateDays  = 3  // members are reminded the last days before the due date and afterwards
+	reminderIntervalHrs  = 24 // members are reminded once per interval
+	dueDateFormat        = "2006-01-02"
+)
+
+var reminderMailI18n = i18n.Translation{
+	"en": {
+		"title":    "Please read and confirm",
+		"text-1":   "You have been asked to read and confirm the article until",
+		"text-2":   "but haven't confirmed the latest version yet.",
+		"action":   "Open Article",
+		"link":     "Or paste this link into your browser",
+		"greeting": "Please confirm you have read an article!",
+		"goodbye":  "Cheers, Emvi Team",
+	},
+	"de": {
+		"title":    "Bitte lesen und bestätigen",
+		"text-1":   "Du wurdest gebeten den Artikel bis zum",
+		"text-2":   "zu lesen und zu bestätigen, hast die aktuelle Version aber noch nicht bestätigt.",
+		"action":   "Artikel öffnen",
+		"link":     "Oder kopiere diesen Link in deinen Browser",
+		"greeting": "Bitte bestätige, dass du einen Artikel gelesen hast!",
+		"goodbye":  "Dein Emvi Team",
+	},
+}
+
+type reminderMailData struct {
+	Title     string
+	DueDate   string
+	ArticleId string
+	LangId    string
+	OrgaURL   string
+	EndVars   map[string]template.HTML
+	Vars      map[string]template.HTML
+}
+
+// SendReadingCampaignReminders reminds members of reading campaigns who haven't confirmed the article yet,
+// starting a few days before the due date.
+func SendReadingCampaignReminders() {
+	now := time.Now()
+	members := model.FindReadingCampaignMemberByDueDateBeforeAndRemindedBeforeAndUnconfirmed(now.Add(time.Hour*24*reminderDueDateDays),
+		now.Add(-time.Hour*reminderIntervalHrs))
+	logbuch.Info("Sending reading campaign reminders", logbuch.Fields{"count": len(members)})
+	orgas := make(map[hide.ID]*model.Organization)
+
+	for i := range members {
+		orga, ok := orgas[members[i].ReadingCampaign.OrganizationId]
+
+		if !ok {
+			orga = model.GetOrganizationById(members[i].ReadingCampaign.OrganizationId)
+			orgas[members[i].ReadingCampaign.OrganizationId] = orga
+		}
+
+		if orga == nil {
+			logbuch.Error("Organization for reading campaign not found", logbuch.Fields{"campaign_id": members[i].ReadingCampaignId})
+			continue
+		}
+
+		if err := sendReminder(orga, &members[i]); err != nil {
+			logbuch.Error("Error sending reading campaign reminder", logbuch.Fields{"err": err, "campaign_id": members[i].ReadingCampaignId, "user_id": members[i].UserId})
+		}
+	}
+}
+
+func sendReminder(orga *model.Organization, member *model.ReadingCampaignMember) error {
+	campaign := member.ReadingCampaign
+	content := model.GetArticleContentLatestByArticleIdAndLanguageId(campaign.ArticleId, campaign.LanguageId, false)
+
+	if content == nil {
+		return errs.ArticleContentNotFound
+	}
+
+	// update first so that the member won't receive the reminder more than once in case sending fails
+	member.Reminded = null.NewTime(time.Now(), true)
+
+	if err := model.SaveReadingCampaignMember(nil, member); err != nil {
+		return errs.Saving
+	}
+
+	articleId, _ := hide.ToString(campaign.ArticleId)
+	langId, _ := hide.ToString(campaign.LanguageId)
+	langCode := util.DetermineSystemSupportedLangCode(orga.ID, member.UserId)
+	data := reminderMailData{
+		content.Title,
+		campaign.DueDate.Format(dueDateFormat),
+		articleId,
+		langId,
+		util.InjectSubdomain(frontendHost, orga.NameNormalized),
+		i18n.GetMailEndI18n(langCode),
+		i18n.GetVars(langCode, reminderMailI18n),
+	}
+	tpl := tplCache.Get()
+	var buffer bytes.Buffer
+
+	if err := tpl.ExecuteTemplate(&buffer, reminderMailTemplate, &data); err != nil {
+		logbuch.Error("Error executing reading campaign reminder mail template", logbuch.Fields{"err": err})
+		return err
+	}
+
+	subject := i18n.GetMailTitle(langCode)[reminderSubject]
+	return mailProvider(subject, buffer.String(), member.User.Email)
+}
@@ -0,0 +1,78 @@
+package campaign
+
+import (
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testMailSend struct {
+	subject string
+	body    string
+	to      string
+}
+
+func TestSendReadingCampaignReminders(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "user2@test.com")
+	user3 := testutil.CreateUser(t, orga, 322, "user3@test.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, false)
+	campaign := createReadingCampaign(t, orga, user, article, lang, time.Now().Add(time.Hour*24))
+	createReadingCampaignMember(t, campaign, user2, 0)
+	createReadingCampaignMember(t, campaign, user3, 2)
+	bot := testutil.CreateBot(t, orga, "bot")
+	createReadingCampaignMember(t, campaign, bot, 0)
+	laterCampaign := createReadingCampaign(t, orga, user, article, lang, time.Now().Add(time.Hour*24*30))
+	createReadingCampaignMember(t, laterCampaign, user2, 0)
+
+	var mailsSend []testMailSend
+	mailProvider = func(subject, msgHTML, from string, to ...string) error {
+		// from is the receiver in this case
+		mailsSend = append(mailsSend, testMailSend{subject, msgHTML, from})
+		return nil
+	}
+
+	SendReadingCampaignReminders()
+
+	if len(mailsSend) != 1 || mailsSend[0].to != user2.Email {
+		t.Fatalf("One reminder must have been send to user2 and none to the bot, but was: %v", mailsSend)
+	}
+
+	if mailsSend[0].subject != "Please confirm you have read an article on Emvi" || !strings.Contains(mailsSend[0].body, "title 2") {
+		t.Fatalf("Reminder mail not as expected: %v", mailsSend[0])
+	}
+
+	// must not be send again within the interval
+	SendReadingCampaignReminders()
+
+	if len(mailsSend) != 1 {
+		t.Fatalf("Reminder must not have been send again, but was: %v", len(mailsSend))
+	}
+}
+
+func createReadingCampaign(t *testing.T, orga *model.Organization, user *model.User, article *model.Article, lang *model.Language, dueDate time.Time) *model.ReadingCampaign {
+	campaign := &model.ReadingCampaign{OrganizationId: orga.ID,
+		ArticleId:  article.ID,
+		LanguageId: lang.ID,
+		UserId:     user.ID,
+		DueDate:    dueDate,
+		Version:    2}
+
+	if err := model.SaveReadingCampaign(nil, campaign); err != nil {
+		t.Fatal(err)
+	}
+
+	return campaign
+}
+
+func createReadingCampaignMember(t *testing.T, campaign *model.ReadingCampaign, user *model.User, confirmedVersion int) {
+	member := &model.ReadingCampaignMember{ReadingCampaignId: campaign.ID, UserId: user.ID, ConfirmedVersion: confirmedVersion}
+
+	if err := model.SaveReadingCampaignMember(nil, member); err != nil {
+		t.Fatal(err)
+	}
+}
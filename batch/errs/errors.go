@@ -8,4 +8,6 @@ var (
 	TxBegin  = errors.New("error starting transaction")
 	TxCommit = errors.New("error committing transaction")
 	Saving   = errors.New("error on save")
+
+	ArticleContentNotFound = errors.New("article content not found")
 )
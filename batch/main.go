@@ -3,10 +3,14 @@ package main
 import (
 	auth "emviwiki/auth/model"
 	"emviwiki/batch/balance"
+	"emviwiki/batch/campaign"
 	"emviwiki/batch/invitation"
 	"emviwiki/batch/newsletter"
 	"emviwiki/batch/notification"
 	"emviwiki/batch/registration"
+	"emviwiki/batch/storage"
+	"emviwiki/batch/trash"
+	"emviwiki/batch/views"
 	dashboard "emviwiki/dashboard/model"
 	"emviwiki/shared/config"
 	"emviwiki/shared/db"
@@ -28,6 +32,15 @@ var (
 		"cleanup_registrations": {nil, registration.CleanupRegistrations},
 		"cleanup_invitations":   {nil, invitation.CleanupInvitations},
 		"update_balance":        {balance.LoadConfig, balance.UpdateBalance},
+		"merge_article_views":   {nil, views.MergeArticleViews},
+		"campaign_reminders":    {campaign.LoadConfig, campaign.SendReadingCampaignReminders},
+		"purge_trash":           {trash.LoadConfig, trash.PurgeTrash},
+		"collect_files":         {storage.LoadConfig, storage.CollectGarbage},
+		"migrate_files":         {storage.LoadMigrationConfig, storage.MigrateStore},
+		"rotate_keys":           {storage.LoadEncryptionConfig, storage.RotateKeys},
+		"reencrypt_files":       {storage.LoadEncryptionConfig, storage.ReencryptFiles},
+		"storage_usage":         {storage.LoadUsageConfig, storage.RecordStorageUsage},
+		"scan_files":            {storage.LoadScanConfig, storage.ScanQuarantinedFiles},
 	}
 )
 
@@ -22,20 +22,22 @@ const (
 
 var notificationMailI18n = i18n.Translation{
 	"en": {
-		"title":    "Your unread notifications on Emvi",
-		"text-1":   "Here are your unread notifications of the last",
-		"text-2":   "days:",
-		"text-3":   "Visit Emvi to view all notifications and mark them as read. You can always change the notification settings in the preferences.",
-		"greeting": "Your unread notifications.",
-		"goodbye":  "Cheers, Emvi Team",
+		"title":      "Your unread notifications on Emvi",
+		"text-1":     "Here are your unread notifications of the last",
+		"text-2":     "days:",
+		"text-daily": "Here are your unread notifications of the last day:",
+		"text-3":     "Visit Emvi to view all notifications and mark them as read. You can always change the notification settings in the preferences.",
+		"greeting":   "Your unread notifications.",
+		"goodbye":    "Cheers, Emvi Team",
 	},
 	"de": {
-		"title":    "Deine ungelesenen Benachrichtigungen auf Emvi",
-		"text-1":   "Hier sind deine Benachrichtigungen der letzten",
-		"text-2":   "Tage:",
-		"text-3":   "Besuche Emvi alle Benachrichtigungen einzusehen und um sie als gelesen zu markieren. Du kannst die Benachrichtigungseinstellungen jederzeit in den Einstellungen ändern.",
-		"greeting": "Deine ungelesenen Benachrichtigungen.",
-		"goodbye":  "Dein Emvi Team",
+		"title":      "Deine ungelesenen Benachrichtigungen auf Emvi",
+		"text-1":     "Hier sind deine Benachrichtigungen der letzten",
+		"text-2":     "Tage:",
+		"text-daily": "Hier sind deine Benachrichtigungen des letzten Tages:",
+		"text-3":     "Besuche Emvi alle Benachrichtigungen einzusehen und um sie als gelesen zu markieren. Du kannst die Benachrichtigungseinstellungen jederzeit in den Einstellungen ändern.",
+		"greeting":   "Deine ungelesenen Benachrichtigungen.",
+		"goodbye":    "Dein Emvi Team",
 	},
 }
 
@@ -45,6 +47,7 @@ type sendNotificationMailData struct {
 	Organization  *model.Organization
 	Notifications []sendNotificationData
 	OrgaURL       string
+	Days          int
 	EndVars       map[string]template.HTML
 	Vars          map[string]template.HTML
 }
@@ -107,12 +110,15 @@ func sendNotificationsConsumer(sendChan <-chan *model.OrganizationMember, wg *sy
 }
 
 func sendNotificationForMember(member *model.OrganizationMember) error {
+	// the digest is scheduled for the next notification mail time, which might have passed a while ago
+	weekly := feed.IsWeeklyDigest(member.NextNotificationMail, member.Timezone)
+
 	if err := updateNextNotification(member); err != nil {
 		return err
 	}
 
 	lang := util.DetermineLang(nil, member.OrganizationId, member.UserId, 0)
-	mailData := getMailData(member, lang)
+	mailData := getMailData(member, lang, weekly)
 
 	if mailData == nil {
 		return nil
@@ -130,22 +136,41 @@ func sendNotificationForMember(member *model.OrganizationMember) error {
 	return nil
 }
 
-func getMailData(member *model.OrganizationMember, lang *model.Language) *sendNotificationMailData {
+// Returns the unread notifications of the last day for categories delivered daily
+// and of the last week for categories delivered weekly, if this is a weekly digest.
+func getMailData(member *model.OrganizationMember, lang *model.Language, weekly bool) *sendNotificationMailData {
 	orga := model.GetOrganizationById(member.OrganizationId)
-	minDefTime := time.Now().Add(time.Hour * 24 * time.Duration(-member.SendNotificationsInterval))
-	notifications := model.FindNotificationByOrganizationIdAndUserIdAndLanguageIdAndAfterDefTimeUnread(orga.ID, member.UserId, lang.ID, minDefTime)
+	prefs := model.FindNotificationPreferenceByOrganizationMemberId(member.ID)
+	now := time.Now()
+	minDailyDefTime := now.Add(-time.Hour * 24)
+	minDefTime := minDailyDefTime
 
-	if len(notifications) == 0 {
-		return nil
+	if weekly {
+		minDefTime = now.Add(-time.Hour * 24 * 7)
 	}
 
+	notifications := model.FindNotificationByOrganizationIdAndUserIdAndLanguageIdAndAfterDefTimeUnread(orga.ID, member.UserId, lang.ID, minDefTime)
 	var notificationData []sendNotificationData
+	days := 1
 
 	for i := range notifications {
-		notificationData = append(notificationData, sendNotificationData{
-			Feed: &notifications[i],
-			When: notifications[i].DefTime,
-		})
+		delivery := feed.NotificationDelivery(member, prefs, feed.ReasonCategory(notifications[i].Reason))
+
+		if delivery == model.NotificationDeliveryDaily && notifications[i].DefTime.After(minDailyDefTime) ||
+			delivery == model.NotificationDeliveryWeekly && weekly {
+			if delivery == model.NotificationDeliveryWeekly {
+				days = 7
+			}
+
+			notificationData = append(notificationData, sendNotificationData{
+				Feed: &notifications[i],
+				When: notifications[i].DefTime,
+			})
+		}
+	}
+
+	if len(notificationData) == 0 {
+		return nil
 	}
 
 	langCode := util.DetermineSystemSupportedLangCode(member.OrganizationId, member.UserId)
@@ -155,6 +180,7 @@ func getMailData(member *model.OrganizationMember, lang *model.Language) *sendNo
 		orga,
 		notificationData,
 		util.InjectSubdomain(frontendHost, orga.NameNormalized),
+		days,
 		i18n.GetMailEndI18n(langCode),
 		i18n.GetVars(langCode, notificationMailI18n),
 	}
@@ -193,7 +219,7 @@ func renderAndSendMail(member *model.OrganizationMember, lang string, data *send
 }
 
 func updateNextNotification(member *model.OrganizationMember) error {
-	member.NextNotificationMail = time.Now().Add(time.Hour * 24 * time.Duration(member.SendNotificationsInterval))
+	member.NextNotificationMail = feed.NextDigest(time.Now(), member.Timezone)
 
 	if err := model.SaveOrganizationMember(nil, member); err != nil {
 		return errs.Saving
@@ -1,6 +1,7 @@
 package notification
 
 import (
+	"emviwiki/shared/feed"
 	"emviwiki/shared/model"
 	"emviwiki/shared/testutil"
 	"github.com/emvi/hide"
@@ -51,10 +52,7 @@ func TestSendNotificationForMember(t *testing.T) {
 	}
 
 	member := model.GetOrganizationMemberByOrganizationIdAndUserId(orga.ID, user.ID)
-
-	if member.NextNotificationMail.Before(time.Now().Add(time.Hour*24*3 - time.Minute)) {
-		t.Fatalf("Next notification must have been set, but was: %v", member.NextNotificationMail)
-	}
+	assertNextNotificationMail(t, member)
 }
 
 func TestSendNotificationMails(t *testing.T) {
@@ -69,7 +67,7 @@ func TestSendNotificationMails(t *testing.T) {
 
 	setNotificationInterval(t, user1.OrganizationMember, 3)
 	setNotificationInterval(t, member2, 4)
-	setNotificationInterval(t, user2.OrganizationMember, 2)
+	setNotificationInterval(t, user2.OrganizationMember, 1)
 
 	// these two in one mail
 	createTestFeed(t, orga1, user1, lang, true, 1, "create_article_list")
@@ -79,13 +77,13 @@ func TestSendNotificationMails(t *testing.T) {
 	createTestFeed(t, orga2, user1, lang, true, 2, "create_article_list")
 
 	// irrelevant notifications for user1
-	createTestFeed(t, orga1, user1, lang, true, 4, "create_article_list")
+	createTestFeed(t, orga1, user1, lang, true, 8, "create_article_list")
 	createTestFeed(t, orga1, user1, lang, false, 2, "create_article_list")
-	createTestFeed(t, orga2, user1, lang, true, 3, "create_article_list")
+	createTestFeed(t, orga2, user1, lang, true, 9, "create_article_list")
 	createTestFeed(t, orga2, user1, lang, false, 1, "create_article_list")
 
-	// mail for user2
-	createTestFeed(t, orga1, user2, lang, true, 1, "create_article_list") // send this one only
+	// daily mail for user2
+	createTestFeed(t, orga1, user2, lang, true, 0, "create_article_list") // send this one only
 	createTestFeed(t, orga1, user2, lang, true, 2, "create_article_list")
 	createTestFeed(t, orga1, user2, lang, true, 3, "create_article_list")
 	createTestFeed(t, orga1, user2, lang, false, 1, "create_article_list")
@@ -106,23 +104,9 @@ func TestSendNotificationMails(t *testing.T) {
 		t.Fatalf("Must have send 3 mails, but was: %v", len(mailsSend))
 	}
 
-	member1 := model.GetOrganizationMemberByOrganizationIdAndUserId(orga1.ID, user1.ID)
-
-	if member1.NextNotificationMail.Before(time.Now().Add(time.Hour*24*3 - time.Minute)) {
-		t.Fatalf("Next notification must have been set, but was: %v", member1.NextNotificationMail)
-	}
-
-	member2 = model.GetOrganizationMemberByOrganizationIdAndUserId(orga1.ID, user2.ID)
-
-	if member1.NextNotificationMail.Before(time.Now().Add(time.Hour*24*2 - time.Minute)) {
-		t.Fatalf("Next notification must have been set, but was: %v", member1.NextNotificationMail)
-	}
-
-	member1Orga2 := model.GetOrganizationMemberByOrganizationIdAndUserId(orga2.ID, user1.ID)
-
-	if member1Orga2.NextNotificationMail.Before(time.Now().Add(time.Hour*24*4 - time.Minute)) {
-		t.Fatalf("Next notification must have been set, but was: %v", member1.NextNotificationMail)
-	}
+	assertNextNotificationMail(t, model.GetOrganizationMemberByOrganizationIdAndUserId(orga1.ID, user1.ID))
+	assertNextNotificationMail(t, model.GetOrganizationMemberByOrganizationIdAndUserId(orga1.ID, user2.ID))
+	assertNextNotificationMail(t, model.GetOrganizationMemberByOrganizationIdAndUserId(orga2.ID, user1.ID))
 }
 
 func TestSendNotificationForMemberTwoNotifications(t *testing.T) {
@@ -200,6 +184,51 @@ func TestSendNotificationMailsIgnoreTooOld(t *testing.T) {
 	}
 }
 
+func TestSendNotificationForMemberPreferences(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	setNotificationInterval(t, user.OrganizationMember, 1)
+	pref := &model.NotificationPreference{OrganizationMemberId: user.OrganizationMember.ID,
+		Category: feed.CategoryLists,
+		Delivery: model.NotificationDeliveryWeekly}
+
+	if err := model.SaveNotificationPreference(nil, pref); err != nil {
+		t.Fatal(err)
+	}
+
+	// not a weekly digest, so the list notification must not be send
+	user.OrganizationMember.NextNotificationMail = time.Date(2020, 6, 2, 8, 0, 0, 0, time.UTC)
+	createTestFeed(t, orga, user, lang, true, 0, "create_article_list")
+	createTestFeed(t, orga, user, lang, true, 0, "joined_organization")
+
+	var mailsSend []testMailSend
+	var m sync.Mutex
+	mailProvider = func(subject, msgHTML, from string, to ...string) error {
+		m.Lock()
+		defer m.Unlock()
+		// from is the receiver in this case
+		mailsSend = append(mailsSend, testMailSend{subject, msgHTML, from})
+		return nil
+	}
+
+	if err := sendNotificationForMember(user.OrganizationMember); err != nil {
+		t.Fatalf("Must send notification mail for user, but was: %v", err)
+	}
+
+	if len(mailsSend) != 1 {
+		t.Fatalf("Must have send 1 mail, but was: %v", len(mailsSend))
+	}
+
+	body := mailsSend[0].body
+
+	if !strings.Contains(body, string(notificationMailI18n["en"]["text-daily"])) ||
+		!strings.Contains(body, "joined the organization") ||
+		strings.Contains(body, "created a new list") {
+		t.Fatalf("Body not as expected: %v", body)
+	}
+}
+
 func TestRenderAndSendMail(t *testing.T) {
 	testutil.CleanBackendDb(t)
 	orga, user := testutil.CreateOrgaAndUser(t)
@@ -240,21 +269,31 @@ func TestRenderAndSendMail(t *testing.T) {
 		!strings.Contains(body, string(notificationMailI18n["en"]["text-3"])) ||
 		!strings.Contains(body, string(notificationMailI18n["en"]["greeting"])) ||
 		!strings.Contains(body, string(notificationMailI18n["en"]["goodbye"])) ||
-		!strings.Contains(body, "3 days") ||
+		!strings.Contains(body, "7 days") ||
 		!strings.Contains(body, "created a new list") ||
 		!strings.Contains(body, "joined the organization") {
 		t.Fatalf("Body not as expected: %v", body)
 	}
 }
 
+// Sets the notification interval and schedules a weekly digest in the past.
 func setNotificationInterval(t *testing.T, member *model.OrganizationMember, days uint) {
 	member.SendNotificationsInterval = days
+	member.NextNotificationMail = time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)
 
 	if err := model.SaveOrganizationMember(nil, member); err != nil {
 		t.Fatal(err)
 	}
 }
 
+func assertNextNotificationMail(t *testing.T, member *model.OrganizationMember) {
+	now := time.Now()
+
+	if !member.NextNotificationMail.After(now) || member.NextNotificationMail.After(now.Add(time.Hour*24)) {
+		t.Fatalf("Next notification must have been set to the next digest, but was: %v", member.NextNotificationMail)
+	}
+}
+
 func createTestFeed(t *testing.T, orga *model.Organization, user *model.User, lang *model.Language, notification bool, ageDays int, reason string) hide.ID {
 	feed := testutil.CreateFeed(t, orga, user, lang, notification)
 	defTime := time.Now().Add(time.Hour * 24 * time.Duration(-ageDays))
@@ -0,0 +1,148 @@
+package storage
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	blobDir              = "blob"
+	uploadSessionTimeout = time.Hour * 24
+)
+
+// CollectGarbage marks files which are not referenced anymore, reports and deletes them after the grace period
+// and moves duplicated attachments of an organization into content-addressed storage.
+// Abandoned resumable uploads are deleted as well.
+func CollectGarbage() {
+	now := time.Now()
+
+	if err := model.UpdateFileUnreferenced(nil, now); err != nil {
+		logbuch.Fatal("Error marking unreferenced files", logbuch.Fields{"err": err})
+	}
+
+	reportUnreferencedFiles()
+	deleteUnreferencedFiles(now.Add(-time.Hour * 24 * time.Duration(graceDays)))
+	deduplicateFiles()
+	deleteAbandonedUploadSessions(now.Add(-uploadSessionTimeout))
+}
+
+func reportUnreferencedFiles() {
+	for _, report := range model.FindFileUnreferencedReport() {
+		logbuch.Info("Unreferenced files", logbuch.Fields{"orga_id": report.OrganizationId, "files": report.Files, "size": report.Size})
+	}
+}
+
+func deleteUnreferencedFiles(unreferenced time.Time) {
+	files := model.FindFileByUnreferencedBefore(unreferenced)
+	logbuch.Info("Deleting unreferenced files", logbuch.Fields{"count": len(files), "grace_days": graceDays})
+
+	for _, file := range files {
+		if err := model.DeleteFileById(nil, file.ID); err != nil {
+			logbuch.Error("Error deleting unreferenced file", logbuch.Fields{"err": err, "id": file.ID})
+			continue
+		}
+
+		deleteFileInStoreIfUnused(file.StorePath())
+	}
+}
+
+func deleteAbandonedUploadSessions(lastActivity time.Time) {
+	sessions := model.FindUploadSessionByLastActivityBefore(lastActivity)
+	logbuch.Info("Deleting abandoned upload sessions", logbuch.Fields{"count": len(sessions)})
+
+	for _, session := range sessions {
+		for _, chunk := range model.FindUploadChunkByUploadSessionId(session.ID) {
+			if err := store.Delete(session.ChunkPath(chunk.Number)); err != nil {
+				logbuch.Error("Error deleting upload chunk in store", logbuch.Fields{"err": err, "session_id": session.ID, "number": chunk.Number})
+			}
+		}
+
+		if err := model.DeleteUploadSessionById(nil, session.ID); err != nil {
+			logbuch.Error("Error deleting abandoned upload session", logbuch.Fields{"err": err, "session_id": session.ID})
+		}
+	}
+}
+
+func deduplicateFiles() {
+	duplicates := model.FindFileDuplicate()
+	logbuch.Info("Deduplicating files", logbuch.Fields{"count": len(duplicates)})
+
+	for _, duplicate := range duplicates {
+		if err := deduplicateFile(duplicate); err != nil {
+			logbuch.Error("Error deduplicating file", logbuch.Fields{"err": err, "orga_id": duplicate.OrganizationId, "md5": duplicate.MD5})
+		}
+	}
+}
+
+func deduplicateFile(duplicate model.FileDuplicate) error {
+	files := model.FindFileByOrganizationIdAndMD5(duplicate.OrganizationId, duplicate.MD5)
+
+	if len(files) == 0 {
+		return nil
+	}
+
+	blob := filepath.Join(blobDir, strconv.FormatInt(int64(duplicate.OrganizationId), 10), duplicate.MD5)
+
+	if _, err := store.Info(blob); err != nil {
+		if err := copyFileInStore(duplicate.OrganizationId, files[0].StorePath(), blob); err != nil {
+			return err
+		}
+
+		for _, derivative := range content.ImageDerivatives {
+			path := content.ImageDerivativePath(files[0].StorePath(), derivative.Name, files[0].MimeType)
+
+			if _, err := store.Info(path); err == nil {
+				if err := copyFileInStore(duplicate.OrganizationId, path, content.ImageDerivativePath(blob, derivative.Name, files[0].MimeType)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := model.UpdateFileBlobByOrganizationIdAndMD5(nil, duplicate.OrganizationId, duplicate.MD5, blob); err != nil {
+		return err
+	}
+
+	deleted := make(map[string]bool)
+
+	for _, file := range files {
+		if path := file.StorePath(); path != blob && !deleted[path] {
+			deleteFileInStoreIfUnused(path)
+			deleted[path] = true
+		}
+	}
+
+	return nil
+}
+
+func copyFileInStore(orgaId hide.ID, from, to string) error {
+	reader, err := store.Read(from)
+
+	if err != nil {
+		return err
+	}
+
+	dir, name := filepath.Split(to)
+	err = content.ForOrganization(store, orgaId).Save(dir, name, reader)
+
+	if closeErr := reader.Close(); closeErr != nil {
+		logbuch.Warn("Error closing file reader", logbuch.Fields{"err": closeErr, "path": from})
+	}
+
+	return err
+}
+
+func deleteFileInStoreIfUnused(path string) {
+	if model.CountFileByStorePath(path) != 0 {
+		return
+	}
+
+	if err := content.DeleteWithDerivatives(store, path); err != nil {
+		logbuch.Error("Error deleting file in store", logbuch.Fields{"err": err, "path": path})
+	}
+}
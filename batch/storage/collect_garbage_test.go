@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"github.com/emvi/null"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dummyStore := content.NewDummyStore()
+	store = dummyStore
+	graceDays = 7
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	file1 := testutil.CreateFileWithMd5(t, orga, user, article, "", "md5")
+	file2 := testutil.CreateFileWithMd5(t, orga, user, article, "", "md5")
+	picture := testutil.CreateFileWithMd5(t, orga, user, nil, "", "picture")
+	user.Picture = null.NewString(picture.UniqueName, true)
+
+	if err := model.SaveUser(nil, user, false); err != nil {
+		t.Fatal(err)
+	}
+
+	CollectGarbage()
+	blob := filepath.Join(blobDir, strconv.FormatInt(int64(orga.ID), 10), "md5")
+	file1 = model.GetFileByUniqueName(file1.UniqueName)
+	file2 = model.GetFileByUniqueName(file2.UniqueName)
+	picture = model.GetFileByUniqueName(picture.UniqueName)
+
+	if !file1.Unreferenced.Valid || !file2.Unreferenced.Valid || picture.Unreferenced.Valid {
+		t.Fatal("Attachments must have been marked unreferenced, but not the profile picture")
+	}
+
+	if file1.Blob.String != blob || file2.Blob.String != blob {
+		t.Fatalf("Files must have been moved to content-addressed storage, but was: %v %v", file1.Blob, file2.Blob)
+	}
+
+	// the dummy store reports image derivatives to exist for all files
+	if len(dummyStore.Deletes) != 2*(len(content.ImageDerivatives)+1) {
+		t.Fatalf("Duplicated files must have been deleted in store, but was: %v", dummyStore.Deletes)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `UPDATE "file" SET unreferenced = $1 WHERE id = $2`, time.Now().Add(-time.Hour*24*8), file1.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	dummyStore.Deletes = nil
+	CollectGarbage()
+
+	if model.GetFileByUniqueName(file1.UniqueName) != nil {
+		t.Fatal("File unreferenced longer than the grace period must have been deleted")
+	}
+
+	if model.GetFileByUniqueName(file2.UniqueName) == nil {
+		t.Fatal("File unreferenced within the grace period must not have been deleted")
+	}
+
+	if len(dummyStore.Deletes) != 0 {
+		t.Fatalf("Blob still in use must not have been deleted, but was: %v", dummyStore.Deletes)
+	}
+}
+
+func TestDeleteAbandonedUploadSessions(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dummyStore := content.NewDummyStore()
+	store = dummyStore
+	orga, user := testutil.CreateOrgaAndUser(t)
+	abandoned := createUploadSession(t, orga, user)
+	abandoned = model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, user.ID, abandoned.ID)
+	active := createUploadSession(t, orga, user)
+	time.Sleep(time.Millisecond * 10)
+
+	// mod_time is set by a trigger, so the time of last activity is taken from the abandoned session
+	lastActivity := abandoned.ModTime.Add(time.Millisecond * 5)
+	chunk := &model.UploadChunk{UploadSessionId: active.ID, Number: 0, Size: 1, Checksum: "checksum"}
+
+	if err := model.SaveUploadChunk(nil, chunk); err != nil {
+		t.Fatal(err)
+	}
+
+	deleteAbandonedUploadSessions(lastActivity)
+
+	if model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, user.ID, abandoned.ID) != nil {
+		t.Fatal("Abandoned upload session must have been deleted")
+	}
+
+	if model.GetUploadSessionByOrganizationIdAndUserIdAndId(orga.ID, user.ID, active.ID) == nil {
+		t.Fatal("Upload session which received a chunk recently must not have been deleted")
+	}
+}
+
+func createUploadSession(t *testing.T, orga *model.Organization, user *model.User) *model.UploadSession {
+	session := &model.UploadSession{OrganizationId: orga.ID,
+		UserId:    user.ID,
+		RoomId:    null.NewString("room", true),
+		Path:      "attachments",
+		Filename:  "file.txt",
+		MimeType:  "text/plain",
+		Size:      1,
+		ChunkSize: 1}
+
+	if err := model.SaveUploadSession(nil, session); err != nil {
+		t.Fatal(err)
+	}
+
+	return session
+}
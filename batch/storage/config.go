@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"emviwiki/shared/config"
+	"emviwiki/shared/content"
+	"emviwiki/shared/mail"
+	"emviwiki/shared/tpl"
+	"github.com/emvi/logbuch"
+)
+
+const (
+	defaultGraceDays = 7
+)
+
+var (
+	store          content.ContentStore
+	scanner        content.Scanner
+	targetStore    content.ContentStore
+	encryptedStore *content.EncryptedStore
+	graceDays      int
+	rotationDays   int
+	mailProvider   mail.Sender
+	frontendHost   string
+	tplCache       *tpl.Cache
+)
+
+func LoadConfig() {
+	store = content.SelectStore()
+	graceDays = config.Get().Storage.GCGrace
+
+	if graceDays <= 0 {
+		graceDays = defaultGraceDays
+	}
+}
+
+func LoadMigrationConfig() {
+	LoadConfig()
+	targetStore = content.SelectMigrationStore()
+
+	if targetStore == nil {
+		logbuch.Fatal("Migration store type must be set to file, gcs or minio")
+	}
+}
+
+func LoadEncryptionConfig() {
+	LoadConfig()
+	encrypted, ok := store.(*content.EncryptedStore)
+
+	if !ok {
+		logbuch.Fatal("Encryption must be configured to rotate keys or re-encrypt files")
+	}
+
+	encryptedStore = encrypted
+	rotationDays = config.Get().Storage.Encryption.RotationDays
+}
+
+func LoadScanConfig() {
+	LoadConfig()
+	scanner = content.SelectScanner()
+
+	// the no-op scanner reports all files as clean and would lift the quarantine without scanning
+	if _, ok := scanner.(content.NoopScanner); ok {
+		logbuch.Fatal("Scanner must be configured to scan quarantined files")
+	}
+}
+
+func LoadUsageConfig() {
+	mailProvider = mail.SelectMailSender()
+	frontendHost = config.Get().Hosts.Frontend
+	tplCache = tpl.NewCache(config.Get().Template.MailTemplateDir, false)
+}
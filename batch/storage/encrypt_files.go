@@ -0,0 +1,232 @@
+package storage
+
+import (
+	"crypto/md5"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"encoding/hex"
+	"errors"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"io"
+	"path/filepath"
+	"time"
+)
+
+const (
+	reencryptSuffix = ".reencrypt"
+
+	// retired data keys are kept for a while, so that files which were being saved while the key was retired can be re-encrypted
+	retiredKeyGrace = time.Hour * 24
+)
+
+// RotateKeys wraps all data keys which have been wrapped by an old master key using the current master key
+// and retires data keys older than the configured rotation period.
+// Files encrypted by retired data keys are re-encrypted by ReencryptFiles.
+func RotateKeys() {
+	masterKeyId := encryptedStore.MasterKeyId()
+	dataKeys := model.FindDataKeyByMasterKeyIdNot(masterKeyId)
+	logbuch.Info("Wrapping data keys using current master key", logbuch.Fields{"count": len(dataKeys), "master_key_id": masterKeyId})
+
+	for _, dataKey := range dataKeys {
+		if err := encryptedStore.RewrapDataKey(&dataKey); err != nil {
+			logbuch.Error("Error wrapping data key", logbuch.Fields{"err": err, "id": dataKey.ID})
+		}
+	}
+
+	if rotationDays <= 0 {
+		return
+	}
+
+	dataKeys = model.FindDataKeyByActiveAndDefTimeBefore(time.Now().Add(-time.Hour * 24 * time.Duration(rotationDays)))
+	logbuch.Info("Retiring data keys", logbuch.Fields{"count": len(dataKeys), "rotation_days": rotationDays})
+
+	for _, dataKey := range dataKeys {
+		dataKey.Retired = null.NewTime(time.Now(), true)
+
+		if err := model.SaveDataKey(nil, &dataKey); err != nil {
+			logbuch.Error("Error retiring data key", logbuch.Fields{"err": err, "id": dataKey.ID})
+		}
+	}
+}
+
+// ReencryptFiles encrypts all files which are not encrypted yet or have been encrypted using a retired data key
+// with the active data key of the organization. Retired data keys are deleted afterwards, if all files have been re-encrypted.
+func ReencryptFiles() {
+	start := time.Now()
+	paths := findStorePaths()
+	logbuch.Info("Re-encrypting files", logbuch.Fields{"count": len(paths)})
+	reencrypted, failed := 0, 0
+
+	for _, path := range paths {
+		ok, err := reencryptFile(path.OrganizationId, path.Path)
+
+		if err != nil {
+			logbuch.Error("Error re-encrypting file", logbuch.Fields{"err": err, "orga_id": path.OrganizationId, "path": path.Path})
+			failed++
+		} else if ok {
+			reencrypted++
+		}
+	}
+
+	logbuch.Info("Re-encrypted files", logbuch.Fields{"reencrypted": reencrypted, "failed": failed})
+
+	if failed != 0 {
+		logbuch.Warn("Retired data keys are not deleted, because some files could not be re-encrypted")
+		return
+	}
+
+	for _, dataKey := range model.FindDataKeyByRetiredBefore(start.Add(-retiredKeyGrace)) {
+		if err := model.DeleteDataKeyById(nil, dataKey.ID); err != nil {
+			logbuch.Error("Error deleting retired data key", logbuch.Fields{"err": err, "id": dataKey.ID})
+		}
+	}
+}
+
+// reencryptFile encrypts the file using the active data key of the organization, if it isn't already.
+// The file is encrypted into a temporary file next to it first, which replaces the file once it has been verified.
+// Files which don't exist are skipped, all other errors are returned, so that retired data keys are not deleted.
+func reencryptFile(orgaId hide.ID, path string) (bool, error) {
+	if resumed, err := resumeReplaceFile(path); err != nil || resumed {
+		return resumed, err
+	}
+
+	dataKeyId, err := encryptedStore.DataKeyId(path)
+
+	if content.IsNotExist(err) {
+		// the file might have been deleted in the meantime
+		logbuch.Debug("File to re-encrypt not found", logbuch.Fields{"err": err, "path": path})
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	if active := model.GetDataKeyByOrganizationIdAndActive(orgaId); dataKeyId != 0 && active != nil && active.ID == dataKeyId {
+		return false, nil
+	}
+
+	sum, err := encryptFile(orgaId, path, path+reencryptSuffix)
+
+	if err != nil {
+		return false, err
+	}
+
+	info, err := encryptedStore.Info(path + reencryptSuffix)
+
+	if err != nil {
+		return false, err
+	}
+
+	if info.MD5 != sum {
+		return false, errors.New("re-encrypted file doesn't match original")
+	}
+
+	if err := replaceFile(path, sum); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// resumeReplaceFile replaces the file by the temporary file left over by a previous run, which was interrupted while replacing it.
+// The temporary file is complete if it can be decrypted, because the last segment of an encrypted file is authenticated.
+// Incomplete temporary files are left over from an interrupted encryption and are overwritten when the file is re-encrypted,
+// unless the file is already encrypted using the active data key, in which case it might have been partially overwritten.
+func resumeReplaceFile(path string) (bool, error) {
+	tmp := path + reencryptSuffix
+
+	if _, err := encryptedStore.Store().Info(tmp); content.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	info, err := encryptedStore.Info(tmp)
+
+	if err != nil {
+		dataKeyId, keyErr := encryptedStore.DataKeyId(path)
+
+		if keyErr != nil && !content.IsNotExist(keyErr) {
+			return false, keyErr
+		}
+
+		if active := model.GetDataKeyById(dataKeyId); active != nil && !active.Retired.Valid {
+			return false, err
+		}
+
+		logbuch.Debug("Overwriting incomplete re-encrypted file", logbuch.Fields{"err": err, "path": tmp})
+		return false, nil
+	}
+
+	logbuch.Info("Resuming to replace re-encrypted file", logbuch.Fields{"path": path})
+
+	if err := replaceFile(path, info.MD5); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// replaceFile overwrites the file by the temporary re-encrypted file and deletes the temporary file afterwards.
+// The temporary file is kept until the file has been verified, so that replacing it can be resumed if it fails.
+func replaceFile(path, sum string) error {
+	tmp := path + reencryptSuffix
+	raw := encryptedStore.Store()
+	reader, err := raw.Read(tmp)
+
+	if err != nil {
+		return err
+	}
+
+	dir, name := filepath.Split(path)
+	err = raw.Save(dir, name, reader)
+
+	if closeErr := reader.Close(); closeErr != nil {
+		logbuch.Warn("Error closing file reader", logbuch.Fields{"err": closeErr, "path": tmp})
+	}
+
+	if err != nil {
+		return err
+	}
+
+	info, err := encryptedStore.Info(path)
+
+	if err != nil {
+		return err
+	}
+
+	if info.MD5 != sum {
+		return errors.New("replaced file doesn't match re-encrypted file")
+	}
+
+	if err := raw.Delete(tmp); err != nil {
+		logbuch.Warn("Error deleting temporary re-encrypted file", logbuch.Fields{"err": err, "path": tmp})
+	}
+
+	return nil
+}
+
+// encryptFile decrypts the file and encrypts it into another file for the organization. It returns the MD5 of the content.
+func encryptFile(orgaId hide.ID, from, to string) (string, error) {
+	reader, err := encryptedStore.Read(from)
+
+	if err != nil {
+		return "", err
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logbuch.Warn("Error closing file reader", logbuch.Fields{"err": err, "path": from})
+		}
+	}()
+
+	hash := md5.New()
+	dir, name := filepath.Split(to)
+
+	if err := encryptedStore.Organization(orgaId).Save(dir, name, io.TeeReader(reader, hash)); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,188 @@
+package storage
+
+import (
+	"bytes"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"encoding/base64"
+	"github.com/emvi/null"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestReencryptFiles(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+	keys, err := content.NewMasterKeys("old", map[string]string{"old": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encryptedStore = content.NewEncryptedStore(&content.FileStore{BasePath: dir}, keys)
+	store = encryptedStore
+	orga, user := testutil.CreateOrgaAndUser(t)
+	file := testutil.CreateFile(t, orga, user, nil, "")
+	writeFileInStore(t, dir, file.StorePath(), "data")
+	ReencryptFiles()
+	dataKey := model.GetDataKeyByOrganizationIdAndActive(orga.ID)
+
+	if dataKey == nil {
+		t.Fatal("Data key must have been created")
+	}
+
+	if id, _ := encryptedStore.DataKeyId(file.StorePath()); id != dataKey.ID {
+		t.Fatalf("Unencrypted file must have been encrypted, but was: %v", id)
+	}
+
+	dataKey.Retired = null.NewTime(time.Now().Add(-retiredKeyGrace*2), true)
+
+	if err := model.SaveDataKey(nil, dataKey); err != nil {
+		t.Fatal(err)
+	}
+
+	ReencryptFiles()
+
+	if id, _ := encryptedStore.DataKeyId(file.StorePath()); id == dataKey.ID || id == 0 {
+		t.Fatalf("File must have been re-encrypted using a new data key, but was: %v", id)
+	}
+
+	if model.GetDataKeyById(dataKey.ID) != nil {
+		t.Fatal("Retired data key must have been deleted")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, file.StorePath()+reencryptSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("Temporary file must have been deleted, but was: %v", err)
+	}
+
+	reader, err := store.Read(file.StorePath())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil || string(data) != "data" {
+		t.Fatalf("Re-encrypted file must be readable, but was: %v %v", string(data), err)
+	}
+}
+
+func TestReencryptFilesReadError(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+	keys, _ := content.NewMasterKeys("old", map[string]string{"old": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))})
+	encryptedStore = content.NewEncryptedStore(&content.FileStore{BasePath: dir}, keys)
+	store = encryptedStore
+	orga, user := testutil.CreateOrgaAndUser(t)
+	dataKey := &model.DataKey{OrganizationId: orga.ID,
+		MasterKeyId: "old",
+		WrappedKey:  []byte("key"),
+		Retired:     null.NewTime(time.Now().Add(-retiredKeyGrace*2), true)}
+
+	if err := model.SaveDataKey(nil, dataKey); err != nil {
+		t.Fatal(err)
+	}
+
+	// reading a directory fails with an error other than the file not existing
+	file := testutil.CreateFile(t, orga, user, nil, "")
+
+	if err := os.MkdirAll(filepath.Join(dir, file.StorePath()), 0776); err != nil {
+		t.Fatal(err)
+	}
+
+	ReencryptFiles()
+
+	if model.GetDataKeyById(dataKey.ID) == nil {
+		t.Fatal("Retired data key must not have been deleted")
+	}
+}
+
+func TestReencryptFilesResumeReplace(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+	keys, _ := content.NewMasterKeys("old", map[string]string{"old": base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))})
+	encryptedStore = content.NewEncryptedStore(&content.FileStore{BasePath: dir}, keys)
+	store = encryptedStore
+	orga, user := testutil.CreateOrgaAndUser(t)
+	file := testutil.CreateFile(t, orga, user, nil, "")
+	writeFileInStore(t, dir, file.StorePath(), "data")
+
+	if _, err := encryptFile(orga.ID, file.StorePath(), file.StorePath()+reencryptSuffix); err != nil {
+		t.Fatal(err)
+	}
+
+	// simulate an interrupted replace, leaving a partially written file encrypted using the active key
+	tmp, err := ioutil.ReadFile(filepath.Join(dir, file.StorePath()+reencryptSuffix))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, file.StorePath()), tmp[:len(tmp)-1], 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	ReencryptFiles()
+
+	if _, err := os.Stat(filepath.Join(dir, file.StorePath()+reencryptSuffix)); !os.IsNotExist(err) {
+		t.Fatalf("Temporary file must have been deleted, but was: %v", err)
+	}
+
+	reader, err := store.Read(file.StorePath())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil || string(data) != "data" {
+		t.Fatalf("Replaced file must be readable, but was: %v %v", string(data), err)
+	}
+}
+
+func TestRotateKeys(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dir := createTempDir(t)
+	defer os.RemoveAll(dir)
+	oldKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, 32))
+	newKey := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{2}, 32))
+	keys, _ := content.NewMasterKeys("old", map[string]string{"old": oldKey})
+	orga, _ := testutil.CreateOrgaAndUser(t)
+
+	if err := content.NewEncryptedStore(&content.FileStore{BasePath: dir}, keys).Organization(orga.ID).Save("", "file", bytes.NewReader([]byte("data"))); err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ = content.NewMasterKeys("new", map[string]string{"old": oldKey, "new": newKey})
+	encryptedStore = content.NewEncryptedStore(&content.FileStore{BasePath: dir}, keys)
+	rotationDays = 0
+	RotateKeys()
+	dataKey := model.GetDataKeyByOrganizationIdAndActive(orga.ID)
+
+	if dataKey == nil || dataKey.MasterKeyId != "new" {
+		t.Fatalf("Data key must have been wrapped using the new master key, but was: %v", dataKey)
+	}
+
+	rotationDays = 1
+
+	if _, err := model.GetConnection().Exec(nil, `UPDATE "data_key" SET def_time = $1`, time.Now().Add(-time.Hour*48)); err != nil {
+		t.Fatal(err)
+	}
+
+	RotateKeys()
+
+	if model.GetDataKeyByOrganizationIdAndActive(orga.ID) != nil {
+		t.Fatal("Data key older than the rotation period must have been retired")
+	}
+}
@@ -0,0 +1,173 @@
+package storage
+
+import (
+	"crypto/md5"
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"encoding/hex"
+	"errors"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"io"
+	"path/filepath"
+)
+
+const (
+	objectCopied = iota
+	objectSkipped
+	objectMissing
+	objectCorrupt
+)
+
+// MigrationReport is the result of a store migration.
+type MigrationReport struct {
+	Copied  int
+	Skipped int
+	Missing []string
+	Corrupt []string
+}
+
+// MigrateStore copies all files, image derivatives and upload chunks from the configured store to the migration store.
+// Objects which already exist in the target store with the same size and MD5 are skipped,
+// so the migration can be resumed after an interruption and run again after switching to the target store,
+// to copy objects which have been uploaded in the meantime.
+func MigrateStore() {
+	report := migrateStore()
+
+	for _, path := range report.Missing {
+		logbuch.Warn("Object missing in source store", logbuch.Fields{"path": path})
+	}
+
+	for _, path := range report.Corrupt {
+		logbuch.Error("Object corrupt", logbuch.Fields{"path": path})
+	}
+
+	logbuch.Info("Store migrated", logbuch.Fields{"copied": report.Copied,
+		"skipped": report.Skipped,
+		"missing": len(report.Missing),
+		"corrupt": len(report.Corrupt)})
+}
+
+func migrateStore() *MigrationReport {
+	report := new(MigrationReport)
+	paths := findStorePaths()
+	logbuch.Info("Migrating objects", logbuch.Fields{"count": len(paths)})
+
+	for _, path := range paths {
+		migrateObjectAndReport(report, path.OrganizationId, path.Path)
+	}
+
+	return report
+}
+
+// findStorePaths returns the paths of all files, their image derivatives and upload chunks in store.
+func findStorePaths() []model.FileStorePath {
+	files := model.FindFileStorePath()
+	paths := make([]model.FileStorePath, 0, len(files))
+
+	for _, file := range files {
+		paths = append(paths, file)
+
+		// derivatives only exist for processable images
+		for _, derivative := range content.ImageDerivatives {
+			path := content.ImageDerivativePath(file.Path, derivative.Name, file.MimeType)
+
+			if existsInStore(path) {
+				paths = append(paths, model.FileStorePath{OrganizationId: file.OrganizationId, Path: path})
+			}
+		}
+	}
+
+	return append(paths, model.FindUploadChunkStorePath()...)
+}
+
+// existsInStore checks if the object exists without decrypting it.
+func existsInStore(path string) bool {
+	s := store
+
+	if encrypted, ok := store.(*content.EncryptedStore); ok {
+		s = encrypted.Store()
+	}
+
+	_, err := s.Info(path)
+	return err == nil
+}
+
+func migrateObjectAndReport(report *MigrationReport, orgaId hide.ID, path string) {
+	result, err := migrateObject(orgaId, path)
+
+	if err != nil {
+		logbuch.Error("Error migrating object", logbuch.Fields{"err": err, "path": path})
+	}
+
+	switch result {
+	case objectCopied:
+		report.Copied++
+	case objectSkipped:
+		report.Skipped++
+	case objectMissing:
+		report.Missing = append(report.Missing, path)
+	case objectCorrupt:
+		report.Corrupt = append(report.Corrupt, path)
+	}
+}
+
+// migrateObject copies the object for given path to the target store and verifies the copy through Info.
+// An object is corrupt if its content doesn't match the size and MD5 reported by the source store
+// or if the copy doesn't match afterwards. Corrupt copies are copied again when the migration is resumed.
+// If encryption is enabled, the object is encrypted for the organization in the target store.
+func migrateObject(orgaId hide.ID, path string) (int, error) {
+	sourceInfo, err := store.Info(path)
+
+	if err != nil {
+		return objectMissing, nil
+	}
+
+	if targetInfo, err := targetStore.Info(path); err == nil && targetInfo == sourceInfo {
+		return objectSkipped, nil
+	}
+
+	reader, err := store.Read(path)
+
+	if err != nil {
+		return objectMissing, err
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logbuch.Warn("Error closing object reader", logbuch.Fields{"err": err, "path": path})
+		}
+	}()
+
+	hash := md5.New()
+	counter := &countReader{reader: io.TeeReader(reader, hash)}
+	dir, name := filepath.Split(path)
+
+	if err := content.ForOrganization(targetStore, orgaId).Save(dir, name, counter); err != nil {
+		return objectCorrupt, err
+	}
+
+	if counter.size != sourceInfo.Size || hex.EncodeToString(hash.Sum(nil)) != sourceInfo.MD5 {
+		return objectCorrupt, errors.New("object content doesn't match source info")
+	}
+
+	targetInfo, err := targetStore.Info(path)
+
+	if err != nil || targetInfo != sourceInfo {
+		return objectCorrupt, errors.New("object copy doesn't match source info")
+	}
+
+	return objectCopied, nil
+}
+
+// countReader counts the bytes read.
+type countReader struct {
+	reader io.Reader
+	size   int64
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.size += int64(n)
+	return n, err
+}
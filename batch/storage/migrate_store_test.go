@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"emviwiki/shared/config"
+	"emviwiki/shared/content"
+	"emviwiki/shared/testutil"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateStore(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	sourceDir, targetDir := createTempDir(t), createTempDir(t)
+	defer os.RemoveAll(sourceDir)
+	defer os.RemoveAll(targetDir)
+	store = &content.FileStore{BasePath: sourceDir}
+	targetStore = &content.FileStore{BasePath: targetDir}
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	copied := testutil.CreateFile(t, orga, user, article, "")
+	migrated := testutil.CreateFile(t, orga, user, article, "")
+	outdated := testutil.CreateFile(t, orga, user, article, "")
+	missing := testutil.CreateFile(t, orga, user, article, "")
+	derivative := content.ImageDerivativePath(copied.StorePath(), content.ImageDerivatives[0].Name, copied.MimeType)
+	writeFileInStore(t, sourceDir, copied.StorePath(), "copied")
+	writeFileInStore(t, sourceDir, derivative, "derivative")
+	writeFileInStore(t, sourceDir, migrated.StorePath(), "migrated")
+	writeFileInStore(t, targetDir, migrated.StorePath(), "migrated")
+	writeFileInStore(t, sourceDir, outdated.StorePath(), "outdated")
+	writeFileInStore(t, targetDir, outdated.StorePath(), "interrupted")
+	report := migrateStore()
+
+	if report.Copied != 3 || report.Skipped != 1 || len(report.Corrupt) != 0 {
+		t.Fatalf("Files must have been copied, but was: %v", report)
+	}
+
+	if len(report.Missing) != 1 || report.Missing[0] != missing.StorePath() {
+		t.Fatalf("Missing file must have been reported, but was: %v", report.Missing)
+	}
+
+	for path, expected := range map[string]string{copied.StorePath(): "copied", derivative: "derivative", outdated.StorePath(): "outdated"} {
+		data, err := ioutil.ReadFile(filepath.Join(targetDir, path))
+
+		if err != nil || string(data) != expected {
+			t.Fatalf("File %v must have been copied, but was: %v %v", path, string(data), err)
+		}
+	}
+
+	report = migrateStore()
+
+	if report.Copied != 0 || report.Skipped != 4 {
+		t.Fatalf("Files already copied must have been skipped, but was: %v", report)
+	}
+}
+
+func TestMigrateStoreMinio(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	sourceDir := createTempDir(t)
+	defer os.RemoveAll(sourceDir)
+	server := testutil.NewS3Server()
+	defer server.Close()
+	config.Get().Storage.Minio = config.Minio{Endpoint: server.Endpoint(), ID: "id", Secret: "secret", Bucket: "bucket"}
+	store = &content.FileStore{BasePath: sourceDir}
+	targetStore = content.NewMinioStore()
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	file := testutil.CreateFile(t, orga, user, article, "")
+	writeFileInStore(t, sourceDir, file.StorePath(), "content")
+	report := migrateStore()
+
+	if report.Copied != 1 || len(report.Corrupt) != 0 {
+		t.Fatalf("File must have been copied, but was: %v", report)
+	}
+
+	reader, err := targetStore.Read(file.StorePath())
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil || string(data) != "content" {
+		t.Fatalf("File must have been copied, but was: %v %v", string(data), err)
+	}
+
+	report = migrateStore()
+
+	if report.Copied != 0 || report.Skipped != 1 || len(report.Corrupt) != 0 {
+		t.Fatalf("File already copied must have been skipped, but was: %v", report)
+	}
+}
+
+func createTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "store")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func writeFileInStore(t *testing.T, base, path, data string) {
+	dir, name := filepath.Split(path)
+
+	if err := (&content.FileStore{BasePath: base}).Save(dir, name, strings.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bytes"
+	"emviwiki/shared/i18n"
+	"emviwiki/shared/model"
+	"emviwiki/shared/util"
+	"fmt"
+	"github.com/emvi/logbuch"
+	"html/template"
+	"time"
+)
+
+const (
+	storageLimitMailTemplate = "mail_storage_limit.html"
+	storageLimitSubject      = "storage_limit"
+	gbToBytes                = float64(1073741824)
+)
+
+var storageLimitMailI18n = i18n.Translation{
+	"en": {
+		"title":    "Your organization is running out of storage",
+		"text-1":   "Your organization uses",
+		"text-2":   "of",
+		"text-3":   "storage.",
+		"text-4":   "Once the storage is used up, no more files can be uploaded. Delete files you don't need anymore or upgrade your subscription to get more storage.",
+		"action":   "Manage Subscription",
+		"link":     "Or paste this link into your browser",
+		"greeting": "Your organization is running out of storage!",
+		"goodbye":  "Cheers, Emvi Team",
+	},
+	"de": {
+		"title":    "Deiner Organisation geht der Speicherplatz aus",
+		"text-1":   "Deine Organisation nutzt",
+		"text-2":   "von",
+		"text-3":   "Speicherplatz.",
+		"text-4":   "Sobald der Speicherplatz aufgebraucht ist, können keine Dateien mehr hochgeladen werden. Lösche Dateien, die du nicht mehr benötigst, oder erweitere dein Abonnement, um mehr Speicherplatz zu erhalten.",
+		"action":   "Abonnement verwalten",
+		"link":     "Oder kopiere diesen Link in deinen Browser",
+		"greeting": "Deiner Organisation geht der Speicherplatz aus!",
+		"goodbye":  "Dein Emvi Team",
+	},
+}
+
+type storageLimitMailData struct {
+	Usage   string
+	Limit   string
+	OrgaURL string
+	EndVars map[string]template.HTML
+	Vars    map[string]template.HTML
+}
+
+// RecordStorageUsage saves a snapshot of the storage used by each organization for the current day,
+// so that the growth can be shown over time. Running it multiple times a day updates the snapshot.
+// Administrators are warned once the usage exceeds the soft limit, compared to the last snapshot.
+func RecordStorageUsage() {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	usages := model.FindFileStorageUsage()
+	logbuch.Info("Recording storage usage", logbuch.Fields{"count": len(usages)})
+
+	for _, usage := range usages {
+		recordStorageUsage(today, &usage)
+	}
+}
+
+func recordStorageUsage(today time.Time, usage *model.StorageUsage) {
+	snapshot := model.GetStorageUsageByOrganizationIdAndDate(usage.OrganizationId, today)
+	last := snapshot
+
+	if snapshot == nil {
+		snapshot = &model.StorageUsage{OrganizationId: usage.OrganizationId, Date: today}
+		last = model.GetStorageUsageByOrganizationIdAndDateBefore(usage.OrganizationId, today)
+	}
+
+	var lastSize int64
+
+	if last != nil {
+		lastSize = last.Size
+	}
+
+	snapshot.Size = usage.Size
+	snapshot.Files = usage.Files
+
+	if err := model.SaveStorageUsage(nil, snapshot); err != nil {
+		logbuch.Error("Error saving storage usage", logbuch.Fields{"err": err, "orga_id": usage.OrganizationId})
+		return
+	}
+
+	orga := model.GetOrganizationById(usage.OrganizationId)
+
+	if orga == nil {
+		logbuch.Error("Organization for storage usage not found", logbuch.Fields{"orga_id": usage.OrganizationId})
+		return
+	}
+
+	if util.IsStorageSoftLimitReached(orga, usage.Size) && !util.IsStorageSoftLimitReached(orga, lastSize) {
+		sendStorageLimitMails(orga, usage.Size)
+	}
+}
+
+func sendStorageLimitMails(orga *model.Organization, size int64) {
+	admins := model.FindOrganizationMemberByOrganizationIdAndIsAdmin(orga.ID)
+	logbuch.Info("Storage soft limit reached, warning administrators", logbuch.Fields{"orga_id": orga.ID, "size": size, "admins": len(admins)})
+
+	for _, admin := range admins {
+		user := model.GetUserById(admin.UserId)
+
+		if user == nil {
+			logbuch.Error("User for administrator not found", logbuch.Fields{"orga_id": orga.ID, "user_id": admin.UserId})
+			continue
+		}
+
+		if err := sendStorageLimitMail(orga, user, size); err != nil {
+			logbuch.Error("Error sending storage limit mail", logbuch.Fields{"err": err, "orga_id": orga.ID, "user_id": user.ID})
+		}
+	}
+}
+
+func sendStorageLimitMail(orga *model.Organization, user *model.User, size int64) error {
+	langCode := util.DetermineSystemSupportedLangCode(orga.ID, user.ID)
+	data := storageLimitMailData{
+		formatGB(size),
+		formatGB(util.GetStorageLimit(orga)),
+		util.InjectSubdomain(frontendHost, orga.NameNormalized),
+		i18n.GetMailEndI18n(langCode),
+		i18n.GetVars(langCode, storageLimitMailI18n),
+	}
+	tpl := tplCache.Get()
+	var buffer bytes.Buffer
+
+	if err := tpl.ExecuteTemplate(&buffer, storageLimitMailTemplate, &data); err != nil {
+		logbuch.Error("Error executing storage limit mail template", logbuch.Fields{"err": err})
+		return err
+	}
+
+	subject := i18n.GetMailTitle(langCode)[storageLimitSubject]
+	return mailProvider(subject, buffer.String(), user.Email)
+}
+
+func formatGB(size int64) string {
+	return fmt.Sprintf("%.1f GB", float64(size)/gbToBytes)
+}
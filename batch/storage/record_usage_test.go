@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testMailSend struct {
+	subject string
+	body    string
+	to      string
+}
+
+func TestRecordStorageUsage(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	orga.MaxStorageGB = 1
+
+	if err := model.SaveOrganization(nil, orga); err != nil {
+		t.Fatal(err)
+	}
+
+	testutil.CreateUser(t, orga, 321, "user2@test.com")
+	file := testutil.CreateFile(t, orga, user, nil, "room")
+	var mailsSend []testMailSend
+	mailProvider = func(subject, msgHTML, from string, to ...string) error {
+		// from is the receiver in this case
+		mailsSend = append(mailsSend, testMailSend{subject, msgHTML, from})
+		return nil
+	}
+
+	RecordStorageUsage()
+	snapshots := model.FindStorageUsageByOrganizationIdAndDateAfter(orga.ID, time.Now().Add(-time.Hour*48))
+
+	if len(snapshots) != 1 || snapshots[0].Size != 42 || snapshots[0].Files != 1 {
+		t.Fatalf("Storage usage must have been recorded, but was: %v", snapshots)
+	}
+
+	if len(mailsSend) != 0 {
+		t.Fatalf("No mail must have been send below the soft limit, but was: %v", len(mailsSend))
+	}
+
+	file.Size = 900000000
+
+	if err := model.SaveFile(nil, file); err != nil {
+		t.Fatal(err)
+	}
+
+	RecordStorageUsage()
+	snapshots = model.FindStorageUsageByOrganizationIdAndDateAfter(orga.ID, time.Now().Add(-time.Hour*48))
+
+	if len(snapshots) != 1 || snapshots[0].Size != 900000000 {
+		t.Fatalf("Storage usage must have been updated, but was: %v", snapshots)
+	}
+
+	if len(mailsSend) != 1 || mailsSend[0].to != user.Email {
+		t.Fatalf("Administrator must have been warned, but was: %v", mailsSend)
+	}
+
+	if mailsSend[0].subject != "Your organization on Emvi is running out of storage" || !strings.Contains(mailsSend[0].body, "0.8 GB") {
+		t.Fatalf("Storage limit mail not as expected: %v", mailsSend[0])
+	}
+
+	// must not be send again until the usage drops below the soft limit
+	RecordStorageUsage()
+
+	if len(mailsSend) != 1 {
+		t.Fatalf("Storage limit mail must not have been send again, but was: %v", len(mailsSend))
+	}
+}
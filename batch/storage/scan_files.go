@@ -0,0 +1,110 @@
+package storage
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+)
+
+const (
+	fileInfectedFeed = "file_infected"
+)
+
+// ScanQuarantinedFiles scans all files which could not be scanned for malware on upload.
+// The quarantine is lifted if the file is clean. Infected files are deleted and reported to the administrators.
+// Files are kept in quarantine if the scanner is still unavailable.
+func ScanQuarantinedFiles() {
+	files := model.FindFileByQuarantined()
+	logbuch.Info("Scanning quarantined files", logbuch.Fields{"count": len(files)})
+
+	for _, file := range files {
+		if err := scanQuarantinedFile(&file); err != nil {
+			logbuch.Error("Error scanning quarantined file", logbuch.Fields{"err": err, "id": file.ID})
+		}
+	}
+}
+
+func scanQuarantinedFile(file *model.File) error {
+	result, err := scanFileInStore(file.StorePath())
+
+	if err != nil {
+		return err
+	}
+
+	if result.Infected {
+		logbuch.Warn("Quarantined file is infected", logbuch.Fields{"id": file.ID, "path": file.StorePath(), "signature": result.Signature})
+
+		if err := model.DeleteFileById(nil, file.ID); err != nil {
+			return err
+		}
+
+		deleteFileInStoreIfUnused(file.StorePath())
+		return createFileInfectedFeed(file, result.Signature)
+	}
+
+	file.Quarantined = false
+	return model.SaveFile(nil, file)
+}
+
+func scanFileInStore(path string) (content.ScanResult, error) {
+	reader, err := store.Read(path)
+
+	if err != nil {
+		return content.ScanResult{}, err
+	}
+
+	defer func() {
+		if err := reader.Close(); err != nil {
+			logbuch.Error("Error closing reader on file scan", logbuch.Fields{"err": err, "path": path})
+		}
+	}()
+
+	return scanner.Scan(reader)
+}
+
+// createFileInfectedFeed notifies the administrators of the organization about the deleted file.
+// The notification mails are sent by the notification batch.
+func createFileInfectedFeed(file *model.File, signature string) error {
+	if file.OrganizationId == 0 {
+		return nil
+	}
+
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		return err
+	}
+
+	feed := &model.Feed{OrganizationId: file.OrganizationId,
+		TriggeredByUserId: file.UserId,
+		Reason:            fileInfectedFeed}
+
+	if err := model.SaveFeed(tx, feed); err != nil {
+		return err
+	}
+
+	refs := []model.FeedRef{
+		{FeedId: feed.ID, Key: null.NewString("filename", true), Value: null.NewString(file.OriginalName, true)},
+		{FeedId: feed.ID, Key: null.NewString("signature", true), Value: null.NewString(signature, true)},
+	}
+
+	for _, ref := range refs {
+		if err := model.SaveFeedRef(tx, &ref); err != nil {
+			return err
+		}
+	}
+
+	for _, admin := range model.FindOrganizationMemberByOrganizationIdAndIsAdmin(file.OrganizationId) {
+		access := &model.FeedAccess{UserId: admin.UserId,
+			FeedId:       feed.ID,
+			Notification: admin.UserId != file.UserId,
+			Read:         admin.UserId == file.UserId}
+
+		if err := model.SaveFeedAccess(tx, access); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
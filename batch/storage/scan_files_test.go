@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"errors"
+	"io"
+	"testing"
+)
+
+type testScanner struct {
+	result content.ScanResult
+	err    error
+}
+
+func (scanner testScanner) Scan(reader io.Reader) (content.ScanResult, error) {
+	return scanner.result, scanner.err
+}
+
+func TestScanQuarantinedFiles(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	store = content.NewDummyStore()
+	orga, user := testutil.CreateOrgaAndUser(t)
+	file := testutil.CreateFile(t, orga, user, nil, "")
+	file.Quarantined = true
+
+	if err := model.SaveFile(nil, file); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner = testScanner{err: errors.New("unavailable")}
+	ScanQuarantinedFiles()
+
+	if !model.GetFileByUniqueName(file.UniqueName).Quarantined {
+		t.Fatal("File must be kept in quarantine if the scanner is unavailable")
+	}
+
+	scanner = testScanner{}
+	ScanQuarantinedFiles()
+
+	if model.GetFileByUniqueName(file.UniqueName).Quarantined {
+		t.Fatal("Quarantine must have been lifted")
+	}
+
+	file = model.GetFileByUniqueName(file.UniqueName)
+	file.Quarantined = true
+
+	if err := model.SaveFile(nil, file); err != nil {
+		t.Fatal(err)
+	}
+
+	scanner = testScanner{result: content.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}}
+	ScanQuarantinedFiles()
+
+	if model.GetFileByUniqueName(file.UniqueName) != nil {
+		t.Fatal("Infected file must have been deleted")
+	}
+
+	if len(model.FindFeedByOrganizationIdAndReason(orga.ID, fileInfectedFeed)) != 1 {
+		t.Fatal("Feed must have been created for infected file")
+	}
+}
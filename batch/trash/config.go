@@ -0,0 +1,24 @@
+package trash
+
+import (
+	"emviwiki/shared/config"
+	"emviwiki/shared/content"
+)
+
+const (
+	defaultRetentionDays = 30
+)
+
+var (
+	store         content.ContentStore
+	retentionDays int
+)
+
+func LoadConfig() {
+	store = content.SelectStore()
+	retentionDays = config.Get().Trash.RetentionDays
+
+	if retentionDays <= 0 {
+		retentionDays = defaultRetentionDays
+	}
+}
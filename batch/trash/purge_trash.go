@@ -0,0 +1,32 @@
+package trash
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"github.com/emvi/logbuch"
+	"time"
+)
+
+// PurgeTrash permanently deletes all objects which have been in trash longer than the retention period.
+// Files belonging to purged articles are removed from store if they are not used anywhere else.
+func PurgeTrash() {
+	trash := model.FindTrashByDefTimeBefore(time.Now().Add(-time.Hour * 24 * time.Duration(retentionDays)))
+	logbuch.Info("Purging trash", logbuch.Fields{"count": len(trash), "retention_days": retentionDays})
+
+	for _, entry := range trash {
+		files := model.FindFileByTrashIdAndUnused(nil, entry.ID)
+
+		if err := model.DeleteTrashById(nil, entry.ID); err != nil {
+			logbuch.Error("Error purging trash", logbuch.Fields{"err": err, "id": entry.ID})
+			continue
+		}
+
+		for _, file := range files {
+			path := file.StorePath()
+
+			if err := content.DeleteWithDerivatives(store, path); err != nil {
+				logbuch.Error("Error deleting file in store when purging trash", logbuch.Fields{"err": err, "id": entry.ID, "path": path})
+			}
+		}
+	}
+}
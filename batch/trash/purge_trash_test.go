@@ -0,0 +1,68 @@
+package trash
+
+import (
+	"emviwiki/shared/content"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+	"time"
+)
+
+func TestPurgeTrash(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	store = content.NewDummyStore()
+	retentionDays = 30
+	orga, user := testutil.CreateOrgaAndUser(t)
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	testutil.CreateFile(t, orga, user, article, "")
+	old := createTrash(t, orga, user, article, time.Now().Add(-time.Hour*24*31))
+	article2 := testutil.CreateArticle(t, orga, user, lang, true, true)
+	recent := createTrash(t, orga, user, article2, time.Now().Add(-time.Hour*24*29))
+
+	if files := model.FindFileByTrashIdAndUnused(nil, old.ID); len(files) != 1 {
+		t.Fatalf("File must be unused, but was: %v", len(files))
+	}
+
+	PurgeTrash()
+
+	if model.GetTrashByOrganizationIdAndId(orga.ID, old.ID) != nil {
+		t.Fatal("Trash older than retention period must have been purged")
+	}
+
+	if model.GetTrashByOrganizationIdAndId(orga.ID, recent.ID) == nil {
+		t.Fatal("Trash within retention period must not have been purged")
+	}
+}
+
+func createTrash(t *testing.T, orga *model.Organization, user *model.User, article *model.Article, defTime time.Time) *model.Trash {
+	tx, err := model.GetConnection().Beginx()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	trash := &model.Trash{OrganizationId: orga.ID,
+		UserId:   user.ID,
+		Type:     model.TrashTypeArticle,
+		ObjectId: article.ID,
+		Name:     "title"}
+
+	if err := model.SaveTrashSnapshot(tx, trash); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := model.DeleteArticleById(tx, article.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := tx.Exec(`UPDATE "trash" SET def_time = $1 WHERE id = $2`, defTime, trash.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	return trash
+}
@@ -0,0 +1,15 @@
+package views
+
+import (
+	"emviwiki/shared/model"
+	"github.com/emvi/logbuch"
+)
+
+// MergeArticleViews merges the buffered article views into the article visits and view counters.
+func MergeArticleViews() {
+	logbuch.Info("Merging article views", logbuch.Fields{"views": model.CountArticleView()})
+
+	if err := model.MergeArticleViewTx(nil); err != nil {
+		logbuch.Fatal("Error while merging article views", logbuch.Fields{"err": err})
+	}
+}
@@ -0,0 +1,59 @@
+package views
+
+import (
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"testing"
+	"time"
+)
+
+func TestMergeArticleViews(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, user := testutil.CreateOrgaAndUser(t)
+	user2 := testutil.CreateUser(t, orga, 321, "reader@user.com")
+	lang := testutil.CreateLang(t, orga, "en", "English", true)
+	article := testutil.CreateArticle(t, orga, user, lang, true, true)
+	views := article.Views
+	createArticleView(t, article, user, lang, 1)
+	createArticleView(t, article, user, lang, 2)
+	createArticleView(t, article, user2, lang, 2)
+	MergeArticleViews()
+
+	if model.CountArticleView() != 0 {
+		t.Fatal("All article views must have been merged")
+	}
+
+	article = model.GetArticleByOrganizationIdAndId(orga.ID, article.ID)
+
+	if article.Views != views+2 {
+		t.Fatalf("Article views must have been increased by number of visits, but was: %v -> %v", views, article.Views)
+	}
+
+	visit := model.GetArticleVisitByArticleIdAndUserIdAndDefTimeAfter(article.ID, user.ID, time.Now().Add(-time.Minute))
+
+	if visit == nil || visit.Views != 2 || visit.Version != 2 || visit.LanguageId != lang.ID {
+		t.Fatalf("Visit not as expected: %v", visit)
+	}
+
+	createArticleView(t, article, user, lang, 3)
+	MergeArticleViews()
+	article = model.GetArticleByOrganizationIdAndId(orga.ID, article.ID)
+
+	if article.Views != views+2 {
+		t.Fatalf("Article views must not have been increased for another visit on the same day, but was: %v -> %v", views, article.Views)
+	}
+
+	visit = model.GetArticleVisitByArticleIdAndUserIdAndDefTimeAfter(article.ID, user.ID, time.Now().Add(-time.Minute))
+
+	if visit == nil || visit.Views != 3 || visit.Version != 3 {
+		t.Fatalf("Visit must have been updated, but was: %v", visit)
+	}
+}
+
+func createArticleView(t *testing.T, article *model.Article, user *model.User, lang *model.Language, version int) {
+	view := &model.ArticleView{ArticleId: article.ID, UserId: user.ID, LanguageId: lang.ID, Version: version}
+
+	if err := model.SaveArticleView(nil, view); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -123,7 +123,8 @@ class Article {
 			content: JSON.stringify(this.doc.toJSON()),
 			rtl: this.rtl,
 			tags: this.tags,
-			language_id: this.lang
+			language_id: this.lang,
+			source_synced: !!data.synced
 		};
 		this.saved = true;
 		logger.debug("Saving article");
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"github.com/emvi/logbuch"
+)
+
+// StorageUsage is the latest recorded storage usage of an organization.
+type StorageUsage struct {
+	Name         string
+	Size         int64
+	Files        int
+	MaxStorageGB int64 `db:"max_storage_gb"`
+}
+
+// latestStorageUsageSQL selects the latest snapshot recorded for each organization.
+const latestStorageUsageSQL = `SELECT DISTINCT ON (organization_id) * FROM "storage_usage" ORDER BY organization_id, "date" DESC`
+
+func SumStorageUsage() int64 {
+	var size int64
+
+	if err := backendDB.Get(&size, `SELECT COALESCE(SUM("size"), 0) FROM (`+latestStorageUsageSQL+`) AS usage`); err != nil {
+		logbuch.Error("Error summing storage usage", logbuch.Fields{"err": err})
+		return 0
+	}
+
+	return size
+}
+
+func FindStorageUsageLimit(n int) []StorageUsage {
+	query := `SELECT "organization".name, usage."size", usage.files, "organization".max_storage_gb
+		FROM (` + latestStorageUsageSQL + `) AS usage
+		JOIN "organization" ON usage.organization_id = "organization".id
+		ORDER BY usage."size" DESC
+		LIMIT $1`
+	var entities []StorageUsage
+
+	if err := backendDB.Select(&entities, query, n); err != nil {
+		logbuch.Error("Error reading storage usage", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entities
+}
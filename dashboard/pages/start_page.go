@@ -20,6 +20,8 @@ const (
 	loginDefaultDays        = 30
 	maxStatisticDays        = 365
 	statisticsDateFormat    = "2006-01-02"
+	maxStorageUsage         = 20
+	gbToBytes               = float64(1073741824)
 )
 
 func StartPageHandler(claims *auth.UserTokenClaims, w http.ResponseWriter, r *http.Request) {
@@ -90,6 +92,8 @@ func StartPageHandler(claims *auth.UserTokenClaims, w http.ResponseWriter, r *ht
 			RegistrationStatisticsData  template.JS
 			LoginStatisticsLabel        template.JS
 			LoginStatisticsData         template.JS
+			StorageUsage                float64
+			StorageUsagePerOrganization []storageUsage
 		}{
 			usersConnected,
 			roomsOpen,
@@ -116,12 +120,44 @@ func StartPageHandler(claims *auth.UserTokenClaims, w http.ResponseWriter, r *ht
 			registrationData,
 			loginLabel,
 			loginData,
+			toGB(model.SumStorageUsage()),
+			getStorageUsage(),
 		}
 
 		RenderPage(w, startPageTemplate, claims, &data)
 	}
 }
 
+// storageUsage is the storage usage of an organization in GB.
+type storageUsage struct {
+	Name         string
+	Size         float64
+	Files        int
+	MaxStorageGB int64
+	Percent      float64
+}
+
+func getStorageUsage() []storageUsage {
+	usage := model.FindStorageUsageLimit(maxStorageUsage)
+	result := make([]storageUsage, 0, len(usage))
+
+	for _, u := range usage {
+		percent := 0.0
+
+		if u.MaxStorageGB > 0 {
+			percent = math.Round(toGB(u.Size)/float64(u.MaxStorageGB)*1000) / 10
+		}
+
+		result = append(result, storageUsage{u.Name, toGB(u.Size), u.Files, u.MaxStorageGB, percent})
+	}
+
+	return result
+}
+
+func toGB(size int64) float64 {
+	return math.Round(float64(size)/gbToBytes*100) / 100
+}
+
 func getStatisticsLabelAndData(data []model.Statistics) (template.JS, template.JS) {
 	if len(data) == 0 {
 		return "", ""
@@ -35,6 +35,7 @@ export emviPublicReadLabel from "./labels/public_read.vue";
 export emviLimitedLabel from "./labels/limited.vue";
 export emviPrivateLabel from "./labels/private.vue";
 export emviExternalLabel from "./labels/external.vue";
+export emviBotLabel from "./labels/bot.vue";
 export emviPinnedLabel from "./labels/pinned.vue";
 export emviBookmarkedLabel from "./labels/bookmarked.vue";
 export emviWatchedLabel from "./labels/watched.vue";
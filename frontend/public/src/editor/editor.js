@@ -115,8 +115,8 @@ export class Editor {
         this.mentionsPlugin.destroy();
     }
 
-    save(message, wip) {
-        this.socket.emit("save", {message, wip});
+    save(message, wip, synced) {
+        this.socket.emit("save", {message, wip, synced});
     }
 
     closeArticle() {
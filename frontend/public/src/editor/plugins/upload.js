@@ -5,7 +5,12 @@ import axios from "axios";
 import {CancelToken} from "axios";
 import {getSizeFromBytes} from "../../util";
 
-const MAX_SIZE_BYTES = 50000000; // 50 MB
+const MAX_SIZE_BYTES = 10737418240; // 10 GB
+const CHUNK_RETRIES = 3;
+
+function toHex(buffer) {
+    return Array.from(new Uint8Array(buffer)).map(b => b.toString(16).padStart(2, "0")).join("");
+}
 
 class FileUpload {
     constructor(articleId, langId, onError) {
@@ -63,35 +68,41 @@ class FileUpload {
     }
 
     sendFile(file, id) {
-        let form = new FormData();
-        form.append("file", file);
-
         let cancelToken = CancelToken.source();
         this.pendingUploads.push(cancelToken);
-        let config = {
-            headers: {
-                "Content-Type": "multipart/form-data"
-            },
-            onUploadProgress: e => {
-                let percentCompleted = Math.round((e.loaded*100)/e.total);
-                this.updatePlaceholder(id, percentCompleted);
-            },
-            cancelToken: cancelToken.token
+        let session = null;
+        let data = {
+            article_id: this.articleId,
+            language_id: this.langId,
+            room_id: this.articleId ? "" : this.room,
+            filename: file.name,
+            mime_type: file.type,
+            size: file.size
         };
-        let articleOrRoom = this.articleId ? `?article=${this.articleId}` : `?room=${this.room}`;
 
-        axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/content${articleOrRoom}&language=${this.langId}`, form, config)
+        // files are uploaded in chunks, so that an interrupted upload doesn't need to start from the beginning
+        axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/content/upload`, data, {cancelToken: cancelToken.token})
+        .then(r => {
+            session = r.data;
+            return this.sendChunks(file, id, session, 0, cancelToken);
+        })
+        .then(() => {
+            return axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/content/upload/${session.id}`, null, {cancelToken: cancelToken.token});
+        })
         .then(r => {
             this.removePendingUpload(cancelToken);
 
+            // attachments can only be downloaded using the signed URL, the signature is removed when the article is saved
+            let url = r.data.url || this.getFileURL(r.data.unique_name);
+
             if(file.type.includes("image")) {
-                this.insertImage(id, r.data.unique_name);
+                this.insertImage(id, url);
             }
             else if(file.type.includes("pdf")) {
-                this.insertPDF(id, r.data.unique_name);
+                this.insertPDF(id, url);
             }
             else{
-                this.insertFile(id, r.data.unique_name, file.name, file.size);
+                this.insertFile(id, url, file.name, file.size);
             }
 
             this.removePlaceholder(id);
@@ -101,7 +112,14 @@ class FileUpload {
             this.removePendingUpload(cancelToken);
             this.removePlaceholder(id);
 
-            if(e.errors && e.errors.length && e.errors[0].message && e.errors[0].message === "File too large") {
+            if(session) {
+                axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/content/upload/${session.id}`)
+                .catch(e => {
+                    console.error(e);
+                });
+            }
+
+            if(e.errors && e.errors.length && e.errors[0].message && (e.errors[0].message === "File size invalid" || e.errors[0].message === "Maximum storage capacity reached")) {
                 this.onError({upload_error: "file_size"});
             }
             else {
@@ -110,6 +128,44 @@ class FileUpload {
         });
     }
 
+    sendChunks(file, id, session, number, cancelToken) {
+        if(number >= session.chunks) {
+            return Promise.resolve();
+        }
+
+        let start = number*session.chunk_size;
+        let chunk = file.slice(start, Math.min(start+session.chunk_size, file.size));
+        return this.sendChunk(session, number, chunk, cancelToken, CHUNK_RETRIES)
+        .then(() => {
+            this.updatePlaceholder(id, Math.round(((number+1)*100)/session.chunks));
+            return this.sendChunks(file, id, session, number+1, cancelToken);
+        });
+    }
+
+    sendChunk(session, number, chunk, cancelToken, retries) {
+        return chunk.arrayBuffer()
+        .then(data => {
+            return crypto.subtle.digest("SHA-256", data)
+            .then(sum => {
+                let config = {
+                    headers: {
+                        "Content-Type": "application/octet-stream",
+                        "X-Checksum-SHA256": toHex(sum)
+                    },
+                    cancelToken: cancelToken.token
+                };
+                return axios.put(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/content/upload/${session.id}?chunk=${number}`, data, config);
+            });
+        })
+        .catch(e => {
+            if(retries <= 0 || axios.isCancel(e)) {
+                throw e;
+            }
+
+            return this.sendChunk(session, number, chunk, cancelToken, retries-1);
+        });
+    }
+
     createPlaceholder(pos, id, filename, filesize, progress) {
         let tr = this.view.state.tr;
 
@@ -168,7 +224,7 @@ a 15.9155 15.9155 0 0 1 0 -31.831"></path>\
         this.createPlaceholder(pos, id, decorator.spec.filename, decorator.spec.filesize, percentCompleted);
     }
 
-    insertImage(id, uniqueName) {
+    insertImage(id, url) {
         let pos = this.findPlaceholderPos(id);
 
         if(pos === null) {
@@ -176,7 +232,7 @@ a 15.9155 15.9155 0 0 1 0 -31.831"></path>\
             return;
         }
 
-        let node = editorSchema.nodes.image.create({src: this.getFileURL(uniqueName)},
+        let node = editorSchema.nodes.image.create({src: url},
             editorSchema.nodes.paragraph.create());
         let resolvedPos = this.view.state.doc.resolve(pos);
         let tr = this.view.state.tr;
@@ -184,7 +240,7 @@ a 15.9155 15.9155 0 0 1 0 -31.831"></path>\
         this.view.dispatch(tr.replaceSelectionWith(node));
     }
 
-    insertPDF(id, uniqueName) {
+    insertPDF(id, url) {
         let pos = this.findPlaceholderPos(id);
 
         if(pos == null) {
@@ -192,11 +248,11 @@ a 15.9155 15.9155 0 0 1 0 -31.831"></path>\
             return;
         }
 
-        let node = editorSchema.nodes.pdf.create({src: this.getFileURL(uniqueName)});
+        let node = editorSchema.nodes.pdf.create({src: url});
         this.view.dispatch(this.view.state.tr.insert(pos, node));
     }
 
-    insertFile(id, uniqueName, name, size) {
+    insertFile(id, url, name, size) {
         let pos = this.findPlaceholderPos(id);
 
         if(pos == null) {
@@ -205,7 +261,7 @@ a 15.9155 15.9155 0 0 1 0 -31.831"></path>\
         }
 
         let node = editorSchema.nodes.file.create({
-            file: this.getFileURL(uniqueName),
+            file: url,
             name,
             size: getSizeFromBytes(size)
         });
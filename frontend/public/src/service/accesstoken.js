@@ -0,0 +1,39 @@
+import axios from "axios";
+
+export const AccessTokenService = new class {
+    getAccessTokens() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/accesstoken`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    saveAccessToken(name, scopes, expires) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/accesstoken`, {name, scopes, expires})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    deleteAccessToken(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/accesstoken/${id}`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+};
@@ -2,9 +2,9 @@ import axios from "axios";
 import {tagsToStringArray} from "../util";
 
 export const ArticleService = new class {
-	getArticle(id, lang, version, update_views) {
+	getArticle(id, lang, version, update_views, changes) {
 		return new Promise((resolve, reject) => {
-			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${id}`, {params: {lang, version, update_views}})
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${id}`, {params: {lang, version, update_views, changes}})
 			.then(r => {
 				let data = r.data || {};
 
@@ -59,6 +59,90 @@ export const ArticleService = new class {
 		});
 	}
 
+	getArticleStatistics(id, lang, days) {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${id}/statistics`, {params: {lang, days}})
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	createReadingCampaign(article_id, language_id, user, groups, due_date) {
+		return new Promise((resolve, reject) => {
+			axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${article_id}/campaign`, {language_id, user, groups, due_date})
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	getReadingCampaigns(article_id) {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${article_id}/campaign`)
+			.then(r => {
+				resolve(r.data || []);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	getUnconfirmedReadingCampaigns() {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/campaign`)
+			.then(r => {
+				resolve(r.data || []);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	getReadingCampaignReport(id) {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/campaign/${id}`)
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	confirmReadingCampaign(id) {
+		return new Promise((resolve, reject) => {
+			axios.put(`${EMVI_WIKI_BACKEND_HOST}/api/v1/campaign/${id}`)
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	deleteReadingCampaign(id) {
+		return new Promise((resolve, reject) => {
+			axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/campaign/${id}`)
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
 	recommendArticle(article_id, user, groups, message, receive_read_confirmation) {
 		return new Promise((resolve, reject) => {
 			axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${article_id}/recommendation`, {user, groups, message, receive_read_confirmation})
@@ -147,6 +231,23 @@ export const ArticleService = new class {
 		});
 	}
 
+	translateArticle(article_id, language_id, source_language_id) {
+		if(!source_language_id) {
+			source_language_id = 0;
+		}
+
+		return new Promise((resolve, reject) => {
+			// the translation runs in background, a notification is sent when it's done
+			axios.put(`${EMVI_WIKI_BACKEND_HOST}/api/v1/article/${article_id}/translate`, {language_id, source_language_id})
+			.then(() => {
+				resolve();
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
 	getPrivateArticles(offset, cancelToken) {
 		if(cancelToken) {
 			cancelToken = cancelToken.token;
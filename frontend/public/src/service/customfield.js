@@ -0,0 +1,39 @@
+import axios from "axios";
+
+export const CustomFieldService = new class {
+    getCustomFields() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/customfield`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    saveCustomField(id, name, type, options, required_tag_ids) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/customfield`, {id, name, type, options, required_tag_ids})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    deleteCustomField(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/customfield/${id}`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+};
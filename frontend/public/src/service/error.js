@@ -91,6 +91,7 @@ const errs = {
         "error parsing multipart form": "Error uploading file.",
         "Error parsing multipart form": "Error uploading file.",
         "File type not allowed": "File type not allowed.",
+        "File infected": "The file was refused, because malware was found. The administrators have been notified.",
         "Maximum number of lists reached": "Maximum number of lists reached. Upgrade the organization to create more or delete an existing list.",
         "Maximum number of articles reached": "Maximum number of articles reached. Upgrade the organization to create more or delete an existing article.",
         "No moderator access remaining after operation": "This operation is forbidden. No moderator access remaining after operation.",
@@ -199,6 +200,7 @@ const errs = {
         "error parsing multipart form": "Fehler beim hochladen der Datei.",
         "Error parsing multipart form": "Fehler beim hochladen der Datei.",
         "File type not allowed": "Dateityp nicht erlaubt.",
+        "File infected": "Die Datei wurde abgelehnt, weil Schadsoftware gefunden wurde. Die Administratoren wurden benachrichtigt.",
         "Maximum number of lists reached": "Maximale Anzahl von Listen erreicht. Upgrade auf eine Expert Organisation um weitere Listen anzulegen oder lösche eine Existierende.",
         "Maximum number of articles reached": "Maximale Anzahl von Artikeln erreicht. Upgrade auf eine Expert Organisation um weitere Artikel anzulegen oder lösche einen Existierenden.",
         "No moderator access remaining after operation": "Der Vorgang ist nicht erlaubt. Nach dem Vorgang bleibt kein Moderator Zugriff auf die Liste.",
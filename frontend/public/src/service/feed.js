@@ -29,6 +29,18 @@ export const FeedService = new class {
 		});
 	}
 
+	getChangedArticles() {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/feed/changes`)
+			.then(r => {
+				resolve({articles: r.data.articles || [], count: r.data.count});
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
 	toggleNotificationRead(id) {
 		return new Promise((resolve, reject) => {
 			if(!id){
@@ -0,0 +1,39 @@
+import axios from "axios";
+
+export const InboundService = new class {
+    getAddresses() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/inbound/address`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    createAddress(article_list_id) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/inbound/address`, {article_list_id})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    deleteAddress(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/inbound/address/${id}`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+};
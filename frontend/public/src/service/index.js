@@ -15,4 +15,11 @@ export {PinnedService} from "./pinned.js";
 export {ErrorService} from "./error.js";
 export {SupportService} from "./support.js";
 export {ClientService} from "./client.js";
+export {AccessTokenService} from "./accesstoken.js";
+export {ServiceAccountService} from "./serviceaccount.js";
+export {IntegrationService} from "./integration.js";
+export {InboundService} from "./inbound.js";
 export {BillingService} from "./billing.js";
+export {TrashService} from "./trash.js";
+export {LiveService} from "./live.js";
+export {CustomFieldService} from "./customfield.js";
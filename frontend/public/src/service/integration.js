@@ -0,0 +1,87 @@
+import axios from "axios";
+
+export const IntegrationService = new class {
+    getIntegrations() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    saveIntegration(id, type, name, webhook_url, api_url, access_token, signing_secret, room_id, reasons) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration`, {id, type, name, webhook_url, api_url, access_token, signing_secret, room_id, reasons})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    deleteIntegration(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration/${id}`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    getAccounts() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration/account`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    linkAccount(id, external_id) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration/${id}/account`, {external_id})
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    confirmAccount(id, code) {
+        return new Promise((resolve, reject) => {
+            axios.put(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration/${id}/account`, {code})
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    unlinkAccount(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/integration/${id}/account`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+};
@@ -48,4 +48,52 @@ export const LangService = new class {
 			});
 		});
 	}
+
+	getTranslators(id) {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/lang/${id}/translator`)
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	addTranslator(id, user_id) {
+		return new Promise((resolve, reject) => {
+			axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/lang/${id}/translator`, {user_id})
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	removeTranslator(id, user_id) {
+		return new Promise((resolve, reject) => {
+			axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/lang/${id}/translator`, {params: {user_id}})
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	getTranslationStatus(lang, pending, offset) {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/translation`, {params: {lang, pending, offset}})
+			.then(r => {
+				resolve(r.data);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
 };
@@ -0,0 +1,121 @@
+import {getCookie, getSubdomain} from "../util";
+
+const DEFAULT_RETRY_MS = 1000;
+const MAX_RETRY_MS = 60000;
+
+// The stream is read using fetch instead of EventSource, as EventSource does not allow to set the authorization headers.
+export const LiveService = new class {
+	isSupported() {
+		return !!(window.fetch && window.ReadableStream && window.TextDecoder);
+	}
+
+	// Connects to the live event stream and reconnects whenever the stream ends.
+	// onOpen is called on the first connect only, onEvent for every event received with the event name and data.
+	// Returns a function to close the connection.
+	connect(onOpen, onEvent) {
+		let closed = false;
+		let controller = null;
+		let retry = DEFAULT_RETRY_MS;
+		let failures = 0;
+		let opened = false;
+
+		let open = () => {
+			if(closed) {
+				return;
+			}
+
+			controller = new AbortController();
+			fetch(`${EMVI_WIKI_BACKEND_HOST}/api/v1/feed/live`, {
+				headers: {
+					"Authorization": `Bearer ${getCookie("access_token")}`,
+					"Organization": getSubdomain()
+				},
+				signal: controller.signal
+			})
+			.then(r => {
+				if(!r.ok) {
+					throw new Error(`Live event stream responded with status ${r.status}`);
+				}
+
+				failures = 0;
+
+				if(!opened) {
+					opened = true;
+					onOpen();
+				}
+
+				return this.read(r.body.getReader(), ms => {retry = ms;}, onEvent);
+			})
+			.catch(e => {
+				if(e.name !== "AbortError") {
+					failures++;
+					console.error(e);
+				}
+			})
+			.then(() => {
+				// back off on repeated failures
+				setTimeout(open, Math.min(retry * Math.pow(2, failures), MAX_RETRY_MS));
+			});
+		};
+
+		open();
+
+		return () => {
+			closed = true;
+
+			if(controller) {
+				controller.abort();
+			}
+		};
+	}
+
+	read(reader, onRetry, onEvent) {
+		let decoder = new TextDecoder();
+		let buffer = "";
+
+		let next = () => {
+			return reader.read().then(({done, value}) => {
+				if(done) {
+					return;
+				}
+
+				buffer += decoder.decode(value, {stream: true});
+				let end = buffer.indexOf("\n\n");
+
+				while(end > -1) {
+					this.parse(buffer.substring(0, end), onRetry, onEvent);
+					buffer = buffer.substring(end+2);
+					end = buffer.indexOf("\n\n");
+				}
+
+				return next();
+			});
+		};
+
+		return next();
+	}
+
+	parse(block, onRetry, onEvent) {
+		let event = "message";
+		let data = "";
+		let lines = block.split("\n");
+
+		for(let i = 0; i < lines.length; i++) {
+			let line = lines[i];
+
+			if(line.startsWith("event: ")) {
+				event = line.substring(7);
+			}
+			else if(line.startsWith("data: ")) {
+				data += line.substring(6);
+			}
+			else if(line.startsWith("retry: ")) {
+				onRetry(parseInt(line.substring(7)) || DEFAULT_RETRY_MS);
+			}
+		}
+
+		if(data) {
+			onEvent(event, JSON.parse(data));
+		}
+	}
+};
@@ -85,9 +85,9 @@ export const OrganizationService = new class {
 		});
 	}
 
-	getStatistics() {
+	getStatistics(days) {
 		return new Promise((resolve, reject) => {
-			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/organization/statistics`)
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/organization/statistics`, {params: {days}})
 			.then(r => {
 				resolve(r.data);
 			})
@@ -0,0 +1,63 @@
+import axios from "axios";
+
+export const ServiceAccountService = new class {
+    getServiceAccounts() {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/serviceaccount`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    saveServiceAccount(name) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/serviceaccount`, {name})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    deleteServiceAccount(id) {
+        return new Promise((resolve, reject) => {
+            axios.delete(`${EMVI_WIKI_BACKEND_HOST}/api/v1/serviceaccount/${id}`)
+            .then(r => {
+                resolve(r);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    getAccessTokens(id) {
+        return new Promise((resolve, reject) => {
+            axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/serviceaccount/${id}/accesstoken`)
+            .then(r => {
+                resolve(r.data || []);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+
+    saveAccessToken(id, name, scopes, expires) {
+        return new Promise((resolve, reject) => {
+            axios.post(`${EMVI_WIKI_BACKEND_HOST}/api/v1/serviceaccount/${id}/accesstoken`, {name, scopes, expires})
+            .then(r => {
+                resolve(r.data);
+            })
+            .catch(e => {
+                reject(e);
+            });
+        });
+    }
+};
@@ -0,0 +1,27 @@
+import axios from "axios";
+
+export const TrashService = new class {
+	getTrash() {
+		return new Promise((resolve, reject) => {
+			axios.get(`${EMVI_WIKI_BACKEND_HOST}/api/v1/trash`)
+			.then(r => {
+				resolve(r.data || []);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+
+	restore(id) {
+		return new Promise((resolve, reject) => {
+			axios.put(`${EMVI_WIKI_BACKEND_HOST}/api/v1/trash/${id}`)
+			.then(r => {
+				resolve(r);
+			})
+			.catch(e => {
+				reject(e);
+			});
+		});
+	}
+};
@@ -71,6 +71,28 @@ export const NotificationsStore = {
                 context.commit("setNotifications", {notifications: context.state.notifications, count});
             }
         },
+        handleLiveEvent(context, {event, data}) {
+            if(event === "feed") {
+                // reload if a new notification was received
+                if(data.count !== context.state.notificationCount) {
+                    context.dispatch("loadNotifications");
+                }
+            }
+            else if(event === "read") {
+                let notifications = context.state.notifications;
+
+                for(let i = 0; i < notifications.length; i++) {
+                    if(!data.feed_id || notifications[i].id === data.feed_id) {
+                        notifications[i].read = data.read;
+                    }
+                }
+
+                context.commit("setNotifications", {notifications, count: data.count});
+            }
+            else if(event === "sync") {
+                context.dispatch("loadNotifications");
+            }
+        },
         markNotificationsRead(context) {
             let notifications = context.state.notifications;
 
@@ -5,6 +5,7 @@ go test -cover -race emviwiki/auth/jwt
 go test -cover -race emviwiki/auth/pages
 go test -cover -race emviwiki/auth/user
 
+go test -cover -race emviwiki/backend/apidoc
 go test -cover -race emviwiki/backend/article
 go test -cover -race emviwiki/backend/article/history
 go test -cover -race emviwiki/backend/article/schema
@@ -15,8 +16,12 @@ go test -cover -race emviwiki/backend/bookmark
 go test -cover -race emviwiki/backend/client
 go test -cover -race emviwiki/backend/content
 go test -cover -race emviwiki/backend/context
+go test -cover -race emviwiki/backend/customfield
 go test -cover -race emviwiki/backend/feed
+go test -cover -race emviwiki/backend/inbound
+go test -cover -race emviwiki/backend/integration
 go test -cover -race emviwiki/backend/lang
+go test -cover -race emviwiki/backend/live
 go test -cover -race emviwiki/backend/member
 go test -cover -race emviwiki/backend/newsletter
 go test -cover -race emviwiki/backend/observe
@@ -27,6 +32,8 @@ go test -cover -race emviwiki/backend/prosemirror
 go test -cover -race emviwiki/backend/search
 go test -cover -race emviwiki/backend/support
 go test -cover -race emviwiki/backend/tag
+go test -cover -race emviwiki/backend/translate
+go test -cover -race emviwiki/backend/trash
 go test -cover -race emviwiki/backend/user
 go test -cover -race emviwiki/backend/usergroup
 
@@ -35,6 +42,10 @@ go test -cover -race emviwiki/batch/invitation
 go test -cover -race emviwiki/batch/newsletter
 go test -cover -race emviwiki/batch/notification
 go test -cover -race emviwiki/batch/registration
+go test -cover -race emviwiki/batch/views
+go test -cover -race emviwiki/batch/campaign
+go test -cover -race emviwiki/batch/trash
+go test -cover -race emviwiki/batch/storage
 
 go test -cover -race emviwiki/shared/auth
 go test -cover -race emviwiki/shared/config
@@ -67,13 +67,20 @@ type CORS struct {
 }
 
 type Mail struct {
-	Sender             string `yaml:"sender"`
-	SupportSender      string `yaml:"support_sender"`
-	SMTP               SMTP   `yaml:"smtp"`
-	SendGridAPIKey     string `yaml:"sendgrid_api_key"`
-	AmazonSESRegion    string `yaml:"amazon_ses_region"`
-	AmazonSESAPIKey    string `yaml:"amazon_ses_api_key"`
-	AmazonSESAPISecret string `yaml:"amazon_ses_api_secret"`
+	Sender             string      `yaml:"sender"`
+	SupportSender      string      `yaml:"support_sender"`
+	SMTP               SMTP        `yaml:"smtp"`
+	SendGridAPIKey     string      `yaml:"sendgrid_api_key"`
+	AmazonSESRegion    string      `yaml:"amazon_ses_region"`
+	AmazonSESAPIKey    string      `yaml:"amazon_ses_api_key"`
+	AmazonSESAPISecret string      `yaml:"amazon_ses_api_secret"`
+	Inbound            InboundMail `yaml:"inbound"`
+}
+
+// InboundMail configures receiving mails to create and append to articles. It is disabled if no domain is set.
+type InboundMail struct {
+	Domain string `yaml:"domain"` // addresses are <token>@<domain>
+	Secret string `yaml:"secret"` // the raw mail must be posted with this secret as bearer token
 }
 
 type SMTP struct {
@@ -84,12 +91,46 @@ type SMTP struct {
 }
 
 type Storage struct {
+	Type       string     `yaml:"type"`
+	Path       string     `yaml:"path"` // for file store
+	GCSBucket  string     `yaml:"gcs_bucket"`
+	Minio      Minio      `yaml:"minio"`
+	GCGrace    int        `yaml:"gc_grace"`   // days before unreferenced files are deleted
+	SoftLimit  int        `yaml:"soft_limit"` // percentage of the storage limit at which administrators are warned, 0 disables the warning
+	Scanner    Scanner    `yaml:"scanner"`
+	URLs       URLs       `yaml:"urls"`
+	Migration  Migration  `yaml:"migration"`
+	Encryption Encryption `yaml:"encryption"`
+}
+
+// Encryption configures the encryption of stored files. It is disabled if no master key ID is set.
+// Data keys are rotated after the configured number of days, 0 disables the rotation.
+type Encryption struct {
+	MasterKeyId  string            `yaml:"master_key_id"` // ID of the master key used to wrap new data keys
+	MasterKeys   map[string]string `yaml:"master_keys"`   // master key ID -> base64 encoded 256 bit key
+	RotationDays int               `yaml:"rotation_days"`
+}
+
+// Migration is the target store to migrate content to, using the same options as the storage.
+type Migration struct {
 	Type      string `yaml:"type"`
 	Path      string `yaml:"path"` // for file store
 	GCSBucket string `yaml:"gcs_bucket"`
 	Minio     Minio  `yaml:"minio"`
 }
 
+type URLs struct {
+	Secret  string `yaml:"secret"`  // to sign content URLs, a random secret is used if empty
+	Expiry  int    `yaml:"expiry"`  // seconds
+	Presign bool   `yaml:"presign"` // redirect to presigned store URLs if supported by the store, which is not the case if files are encrypted
+}
+
+type Scanner struct {
+	Type         string `yaml:"type"`          // "clamd" or empty to disable scanning
+	ClamdAddress string `yaml:"clamd_address"` // tcp://host:port or unix:///path/to/socket
+	Timeout      int    `yaml:"timeout"`       // seconds
+}
+
 type Minio struct {
 	Endpoint string `yaml:"endpoint"`
 	ID       string `yaml:"id"`
@@ -156,6 +197,18 @@ type Batch struct {
 	Process string `yaml:"process"`
 }
 
+type Trash struct {
+	RetentionDays int `yaml:"retention_days"`
+}
+
+// Translation configures the machine translation provider. It is disabled if no provider is set.
+type Translation struct {
+	Provider string `yaml:"provider"` // "libretranslate", "deepl" or empty to disable machine translation
+	URL      string `yaml:"url"`      // API base URL, like http://localhost:5000 for a local LibreTranslate
+	APIKey   string `yaml:"api_key"`
+	Timeout  int    `yaml:"timeout"` // seconds
+}
+
 type Registration struct {
 	ConfirmationURI      string `yaml:"confirmation_uri"`
 	CompletedNewOrgaURI  string `yaml:"completed_new_orga_uri"`
@@ -206,6 +259,8 @@ type Application struct {
 	SSO                   SSO          `yaml:"sso"`
 	Dev                   Dev          `yaml:"dev"`
 	Batch                 Batch        `yaml:"batch"`
+	Trash                 Trash        `yaml:"trash"`
+	Translation           Translation  `yaml:"translation"`
 	Registration          Registration `yaml:"registration"`
 	JWT                   JWT          `yaml:"jwt"`
 	Legal                 Legal        `yaml:"legal"`
@@ -271,6 +326,8 @@ func loadConfigFromEnv() {
 	config.Mail.AmazonSESRegion = getEnv("AMAZON_SES_REGION", "")
 	config.Mail.AmazonSESAPIKey = getEnv("AMAZON_SES_API_KEY", "")
 	config.Mail.AmazonSESAPISecret = getEnv("AMAZON_SES_API_SECRET", "")
+	config.Mail.Inbound.Domain = getEnv("MAIL_INBOUND_DOMAIN", "")
+	config.Mail.Inbound.Secret = getEnv("MAIL_INBOUND_SECRET", "")
 	config.Storage.Type = getEnv("STORE_TYPE", "")
 	config.Storage.Path = getEnv("STORE_PATH", "/files")
 	config.Storage.GCSBucket = getEnv("GCLOUD_CONTENT_STORAGE", "")
@@ -279,6 +336,25 @@ func loadConfigFromEnv() {
 	config.Storage.Minio.Secret = getEnv("MINIO_ACCESS_SECRET_KEY", "")
 	config.Storage.Minio.Secure = getEnvBool("MINIO_USE_SSL", true)
 	config.Storage.Minio.Bucket = getEnv("MINIO_CONTENT_STORAGE", "")
+	config.Storage.GCGrace = getEnvInt("STORE_GC_GRACE_DAYS", 7)
+	config.Storage.SoftLimit = getEnvInt("STORE_SOFT_LIMIT_PERCENT", 80)
+	config.Storage.Scanner.Type = getEnv("SCANNER_TYPE", "")
+	config.Storage.Scanner.ClamdAddress = getEnv("CLAMD_ADDRESS", "tcp://localhost:3310")
+	config.Storage.Scanner.Timeout = getEnvInt("SCANNER_TIMEOUT_SEC", 60)
+	config.Storage.URLs.Secret = getEnv("CONTENT_URL_SECRET", "")
+	config.Storage.URLs.Expiry = getEnvInt("CONTENT_URL_EXPIRY_SEC", 3600)
+	config.Storage.URLs.Presign = getEnvBool("STORE_PRESIGNED_URLS", false)
+	config.Storage.Migration.Type = getEnv("MIGRATION_STORE_TYPE", "")
+	config.Storage.Migration.Path = getEnv("MIGRATION_STORE_PATH", "")
+	config.Storage.Migration.GCSBucket = getEnv("MIGRATION_GCLOUD_CONTENT_STORAGE", "")
+	config.Storage.Migration.Minio.Endpoint = getEnv("MIGRATION_MINIO_ENDPOINT", "")
+	config.Storage.Migration.Minio.ID = getEnv("MIGRATION_MINIO_ACCESS_KEY", "")
+	config.Storage.Migration.Minio.Secret = getEnv("MIGRATION_MINIO_ACCESS_SECRET_KEY", "")
+	config.Storage.Migration.Minio.Secure = getEnvBool("MIGRATION_MINIO_USE_SSL", true)
+	config.Storage.Migration.Minio.Bucket = getEnv("MIGRATION_MINIO_CONTENT_STORAGE", "")
+	config.Storage.Encryption.MasterKeyId = getEnv("STORE_ENCRYPTION_KEY_ID", "")
+	config.Storage.Encryption.MasterKeys = getEnvMap("STORE_ENCRYPTION_KEYS")
+	config.Storage.Encryption.RotationDays = getEnvInt("STORE_ENCRYPTION_ROTATION_DAYS", 365)
 	config.Template.HotReload = getEnvBool("HOT_RELOAD", false)
 	config.Template.TemplateDir = getEnv("TEMPLATE_DIR", "")
 	config.Template.MailTemplateDir = getEnv("MAIL_TEMPLATE_DIR", "/template/mail/*")
@@ -308,6 +384,11 @@ func loadConfigFromEnv() {
 	config.Dev.WatchBuildJs = getEnvBool("WATCH_BUILD_JS", false)
 	config.Dev.WatchIndexHtml = getEnvBool("WATCH_INDEX_HTML", false)
 	config.Batch.Process = getEnv("BATCH_PROCESS", "")
+	config.Trash.RetentionDays = getEnvInt("TRASH_RETENTION_DAYS", 30)
+	config.Translation.Provider = getEnv("TRANSLATION_PROVIDER", "")
+	config.Translation.URL = getEnv("TRANSLATION_URL", "")
+	config.Translation.APIKey = getEnv("TRANSLATION_API_KEY", "")
+	config.Translation.Timeout = getEnvInt("TRANSLATION_TIMEOUT_SEC", 60)
 	config.Registration.ConfirmationURI = getEnv("AUTH_REGISTRATION_CONFIRMATION_URI", "")
 	config.Registration.CompletedNewOrgaURI = getEnv("AUTH_REGISTRATION_NEW_ORGA_URI", "")
 	config.Registration.CompletedJoinOrgaURI = getEnv("AUTH_REGISTRATION_JOIN_ORGA_URI", "")
@@ -393,6 +474,20 @@ func getEnvBool(name string, defaultValue bool) bool {
 	return strings.ToLower(os.Getenv(name)) == "true"
 }
 
+// getEnvMap parses a comma separated list of key:value pairs.
+func getEnvMap(name string) map[string]string {
+	name = fmt.Sprintf("%s%s", envPrefix, name)
+	m := make(map[string]string)
+
+	for _, pair := range strings.Split(os.Getenv(name), ",") {
+		if kv := strings.SplitN(strings.TrimSpace(pair), ":", 2); len(kv) == 2 {
+			m[kv[0]] = kv[1]
+		}
+	}
+
+	return m
+}
+
 // Get returns the application configuration.
 func Get() *Application {
 	return &config
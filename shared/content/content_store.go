@@ -1,7 +1,13 @@
 package content
 
 import (
+	"cloud.google.com/go/storage"
+	"emviwiki/shared/config"
+	"github.com/emvi/logbuch"
+	"github.com/minio/minio-go"
 	"io"
+	"os"
+	"time"
 )
 
 // FileInfo contains information about a file.
@@ -24,3 +30,56 @@ type ContentStore interface {
 	// Delete deletes a file for given path.
 	Delete(string) error
 }
+
+// PresignedURLStore is implemented by content stores which can create temporary URLs to download a file directly.
+type PresignedURLStore interface {
+	// PresignedURL returns an URL to download the file for given path until it expires.
+	// The filename and mime type are set as response headers for the download.
+	PresignedURL(path, filename, mimeType string, expiry time.Duration) (string, error)
+}
+
+// IsNotExist returns whether the error returned by a ContentStore is caused by a file which doesn't exist.
+func IsNotExist(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	return os.IsNotExist(err) ||
+		err == storage.ErrObjectNotExist ||
+		minio.ToErrorResponse(err).Code == "NoSuchKey"
+}
+
+// SelectStore selects the content store by configured storage type.
+// If no type or an unknown type is configured, the dummy store is used.
+func SelectStore() ContentStore {
+	c := config.Get().Storage
+	return selectStore(c.Type, c.Path, c.GCSBucket, c.Minio)
+}
+
+// SelectMigrationStore selects the content store to migrate content to by configured migration storage type.
+// It returns nil if no or an unknown type is configured.
+func SelectMigrationStore() ContentStore {
+	c := config.Get().Storage.Migration
+
+	if c.Type != "file" && c.Type != "gcs" && c.Type != "minio" {
+		return nil
+	}
+
+	return selectStore(c.Type, c.Path, c.GCSBucket, c.Minio)
+}
+
+func selectStore(storeType, path, gcsBucket string, minio config.Minio) ContentStore {
+	if storeType == "file" {
+		logbuch.Info("Using file store for content", logbuch.Fields{"path": path})
+		return encryptStore(newFileStore(path))
+	} else if storeType == "gcs" {
+		logbuch.Info("Using Google Cloud Store for content")
+		return encryptStore(newGoogleCloudStore(gcsBucket))
+	} else if storeType == "minio" {
+		logbuch.Info("Using MinIO Store for content")
+		return encryptStore(newMinioStore(minio))
+	}
+
+	logbuch.Info("Using dummy store for content")
+	return NewDummyStore()
+}
@@ -0,0 +1,435 @@
+package content
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"emviwiki/shared/config"
+	"emviwiki/shared/model"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"golang.org/x/crypto/hkdf"
+	"io"
+	"sync"
+)
+
+const (
+	encryptionMagic       = "EMVIENC1"
+	encryptionSaltSize    = 32
+	encryptionHeaderSize  = len(encryptionMagic) + 8 + encryptionSaltSize
+	encryptionNonceSize   = 12
+	encryptionKeyInfo     = "emvi file encryption"
+	encryptionSegmentSize = 65536
+	dataKeySize           = 32
+)
+
+// EncryptedStore is a ContentStore encrypting files before they are saved in another ContentStore.
+// It uses envelope encryption: files are encrypted using the data key of the organization,
+// which itself is encrypted (wrapped) by a master key and stored in the database.
+//
+// Files are encrypted in segments using AES-256-GCM, so that they can be streamed.
+// Each file is encrypted using its own key, derived from the data key and a random salt using HKDF,
+// so that the segment counter can be used as nonce without limiting the number of files per data key.
+// The header of an encrypted file contains the ID of the data key used and the salt, so it can be read without knowing the organization.
+// Files which have been saved before encryption was enabled are read as they are.
+// EncryptedStore doesn't implement PresignedURLStore, because files downloaded from the underlying store directly would still be encrypted.
+type EncryptedStore struct {
+	store  ContentStore
+	keys   KeyWrapper
+	orgaId hide.ID
+	cache  *sync.Map // data key ID -> unwrapped data key
+}
+
+// NewEncryptedStore creates a new EncryptedStore saving files in given store.
+// Files are encrypted using the data key without organization, use Organization or ForOrganization to use the organizations key.
+func NewEncryptedStore(store ContentStore, keys KeyWrapper) *EncryptedStore {
+	return &EncryptedStore{store: store, keys: keys, cache: new(sync.Map)}
+}
+
+// ForOrganization returns a ContentStore saving files for given organization.
+// The store is returned as is if it doesn't encrypt files.
+func ForOrganization(store ContentStore, orgaId hide.ID) ContentStore {
+	if encrypted, ok := store.(*EncryptedStore); ok {
+		return encrypted.Organization(orgaId)
+	}
+
+	return store
+}
+
+// Organization returns a copy of the store, encrypting new files using the data key of given organization.
+func (store *EncryptedStore) Organization(orgaId hide.ID) *EncryptedStore {
+	return &EncryptedStore{store: store.store, keys: store.keys, orgaId: orgaId, cache: store.cache}
+}
+
+// MasterKeyId returns the ID of the master key used to wrap new data keys.
+func (store *EncryptedStore) MasterKeyId() string {
+	return store.keys.MasterKeyId()
+}
+
+// Store returns the store the encrypted files are saved in.
+func (store *EncryptedStore) Store() ContentStore {
+	return store.store
+}
+
+func (store *EncryptedStore) Read(path string) (io.ReadCloser, error) {
+	reader, err := store.store.Read(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	source := bufio.NewReader(reader)
+	magic, err := source.Peek(len(encryptionMagic))
+
+	if err != nil || string(magic) != encryptionMagic {
+		return &readCloser{source, reader}, nil
+	}
+
+	header := make([]byte, encryptionHeaderSize)
+
+	if _, err := io.ReadFull(source, header); err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	key, err := store.dataKeyById(hide.ID(binary.BigEndian.Uint64(header[len(encryptionMagic):])))
+
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	aead, err := fileAEAD(key, header)
+
+	if err != nil {
+		reader.Close()
+		return nil, err
+	}
+
+	return &decryptReader{source: source,
+		closer:  reader,
+		aead:    aead,
+		header:  header,
+		segment: make([]byte, encryptionSegmentSize+aead.Overhead())}, nil
+}
+
+// Info returns the size and MD5 of the decrypted file, which requires to read the whole file.
+func (store *EncryptedStore) Info(path string) (FileInfo, error) {
+	if _, err := store.store.Info(path); err != nil {
+		return FileInfo{}, err
+	}
+
+	reader, err := store.Read(path)
+
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	defer reader.Close()
+	h := md5.New()
+	size, err := io.Copy(h, reader)
+
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	return FileInfo{
+		size,
+		hex.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+func (store *EncryptedStore) Save(path, filename string, reader io.Reader) error {
+	id, key, err := store.activeDataKey()
+
+	if err != nil {
+		return err
+	}
+
+	header := make([]byte, encryptionHeaderSize)
+	copy(header, encryptionMagic)
+	binary.BigEndian.PutUint64(header[len(encryptionMagic):], uint64(id))
+
+	if _, err := rand.Read(header[len(encryptionMagic)+8:]); err != nil {
+		return err
+	}
+
+	aead, err := fileAEAD(key, header)
+
+	if err != nil {
+		return err
+	}
+
+	return store.store.Save(path, filename, &encryptReader{source: bufio.NewReader(reader),
+		aead:    aead,
+		header:  header,
+		buffer:  header,
+		segment: make([]byte, encryptionSegmentSize)})
+}
+
+func (store *EncryptedStore) Delete(path string) error {
+	return store.store.Delete(path)
+}
+
+// DataKeyId returns the ID of the data key used to encrypt the file for given path or 0 if the file is not encrypted.
+func (store *EncryptedStore) DataKeyId(path string) (hide.ID, error) {
+	reader, err := store.store.Read(path)
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer reader.Close()
+	header := make([]byte, encryptionHeaderSize)
+
+	// files shorter than the header cannot be encrypted
+	if _, err := io.ReadFull(reader, header); err == io.EOF || err == io.ErrUnexpectedEOF {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+
+	if !bytes.HasPrefix(header, []byte(encryptionMagic)) {
+		return 0, nil
+	}
+
+	return hide.ID(binary.BigEndian.Uint64(header[len(encryptionMagic):])), nil
+}
+
+// RewrapDataKey wraps the data key using the current master key.
+func (store *EncryptedStore) RewrapDataKey(dataKey *model.DataKey) error {
+	key, err := store.keys.Unwrap(dataKey.MasterKeyId, dataKey.WrappedKey)
+
+	if err != nil {
+		return err
+	}
+
+	wrapped, err := store.keys.Wrap(key)
+
+	if err != nil {
+		return err
+	}
+
+	dataKey.MasterKeyId = store.keys.MasterKeyId()
+	dataKey.WrappedKey = wrapped
+	return model.SaveDataKey(nil, dataKey)
+}
+
+func (store *EncryptedStore) activeDataKey() (hide.ID, []byte, error) {
+	dataKey := model.GetDataKeyByOrganizationIdAndActive(store.orgaId)
+
+	if dataKey == nil {
+		key := make([]byte, dataKeySize)
+
+		if _, err := rand.Read(key); err != nil {
+			return 0, nil, err
+		}
+
+		wrapped, err := store.keys.Wrap(key)
+
+		if err != nil {
+			return 0, nil, err
+		}
+
+		dataKey = &model.DataKey{OrganizationId: store.orgaId,
+			MasterKeyId: store.keys.MasterKeyId(),
+			WrappedKey:  wrapped}
+
+		if err := model.SaveDataKey(nil, dataKey); err != nil {
+			// the key might have been created concurrently
+			if dataKey = model.GetDataKeyByOrganizationIdAndActive(store.orgaId); dataKey == nil {
+				return 0, nil, err
+			}
+		} else {
+			logbuch.Info("Created data key", logbuch.Fields{"orga_id": store.orgaId, "id": dataKey.ID})
+		}
+	}
+
+	key, err := store.dataKey(dataKey)
+	return dataKey.ID, key, err
+}
+
+func (store *EncryptedStore) dataKeyById(id hide.ID) ([]byte, error) {
+	if key, ok := store.cache.Load(id); ok {
+		return key.([]byte), nil
+	}
+
+	dataKey := model.GetDataKeyById(id)
+
+	if dataKey == nil {
+		return nil, fmt.Errorf("data key %d not found", id)
+	}
+
+	return store.dataKey(dataKey)
+}
+
+func (store *EncryptedStore) dataKey(dataKey *model.DataKey) ([]byte, error) {
+	if key, ok := store.cache.Load(dataKey.ID); ok {
+		return key.([]byte), nil
+	}
+
+	key, err := store.keys.Unwrap(dataKey.MasterKeyId, dataKey.WrappedKey)
+
+	if err != nil {
+		return nil, err
+	}
+
+	store.cache.Store(dataKey.ID, key)
+	return key, nil
+}
+
+// fileAEAD returns the cipher for a file, using the key derived from the data key and the salt from the header.
+func fileAEAD(dataKey, header []byte) (cipher.AEAD, error) {
+	key := make([]byte, dataKeySize)
+
+	if _, err := io.ReadFull(hkdf.New(sha256.New, dataKey, header[len(encryptionMagic)+8:], []byte(encryptionKeyInfo)), key); err != nil {
+		return nil, err
+	}
+
+	return newAEAD(key)
+}
+
+// segmentNonce returns the nonce for a segment, made of the segment counter and a flag for the last segment,
+// so that segments cannot be reordered or truncated. The nonce is unique, because every file uses its own key.
+func segmentNonce(counter uint32, last bool) []byte {
+	nonce := make([]byte, encryptionNonceSize)
+	binary.BigEndian.PutUint32(nonce[encryptionNonceSize-5:], counter)
+
+	if last {
+		nonce[encryptionNonceSize-1] = 1
+	}
+
+	return nonce
+}
+
+// readSegment reads a segment into the buffer and returns its length and whether it is the last one.
+func readSegment(source *bufio.Reader, segment []byte) (int, bool, error) {
+	n, err := io.ReadFull(source, segment)
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return n, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	if _, err := source.Peek(1); err == io.EOF {
+		return n, true, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return n, false, nil
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+type encryptReader struct {
+	source  *bufio.Reader
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buffer  []byte
+	segment []byte
+	done    bool
+}
+
+func (reader *encryptReader) Read(p []byte) (int, error) {
+	for len(reader.buffer) == 0 {
+		if reader.done {
+			return 0, io.EOF
+		}
+
+		n, last, err := readSegment(reader.source, reader.segment)
+
+		if err != nil {
+			return 0, err
+		}
+
+		reader.buffer = reader.aead.Seal(nil, segmentNonce(reader.counter, last), reader.segment[:n], reader.header)
+		reader.counter++
+		reader.done = last
+	}
+
+	n := copy(p, reader.buffer)
+	reader.buffer = reader.buffer[n:]
+	return n, nil
+}
+
+type decryptReader struct {
+	source  *bufio.Reader
+	closer  io.Closer
+	aead    cipher.AEAD
+	header  []byte
+	counter uint32
+	buffer  []byte
+	segment []byte
+	done    bool
+}
+
+func (reader *decryptReader) Read(p []byte) (int, error) {
+	for len(reader.buffer) == 0 {
+		if reader.done {
+			return 0, io.EOF
+		}
+
+		n, last, err := readSegment(reader.source, reader.segment)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if n < reader.aead.Overhead() {
+			return 0, errors.New("encrypted file truncated")
+		}
+
+		reader.buffer, err = reader.aead.Open(nil, segmentNonce(reader.counter, last), reader.segment[:n], reader.header)
+
+		if err != nil {
+			return 0, errors.New("encrypted file corrupt")
+		}
+
+		reader.counter++
+		reader.done = last
+	}
+
+	n := copy(p, reader.buffer)
+	reader.buffer = reader.buffer[n:]
+	return n, nil
+}
+
+func (reader *decryptReader) Close() error {
+	return reader.closer.Close()
+}
+
+// encryptStore wraps the store to encrypt files if encryption is configured.
+func encryptStore(store ContentStore) ContentStore {
+	c := config.Get().Storage.Encryption
+
+	if c.MasterKeyId == "" {
+		return store
+	}
+
+	keys, err := NewMasterKeys(c.MasterKeyId, c.MasterKeys)
+
+	if err != nil {
+		logbuch.Fatal("Error loading master keys to encrypt stored files", logbuch.Fields{"err": err})
+	}
+
+	logbuch.Info("Encrypting stored files", logbuch.Fields{"master_key_id": c.MasterKeyId})
+
+	if config.Get().Storage.URLs.Presign {
+		logbuch.Warn("Presigned URLs are not supported for encrypted files, files are downloaded through the backend instead")
+	}
+
+	return NewEncryptedStore(store, keys)
+}
@@ -0,0 +1,242 @@
+package content
+
+import (
+	"bytes"
+	"crypto/md5"
+	"emviwiki/shared/model"
+	"emviwiki/shared/testutil"
+	"encoding/base64"
+	"encoding/hex"
+	"github.com/emvi/null"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMasterKeys(t *testing.T) {
+	if _, err := NewMasterKeys("missing", map[string]string{"key": testMasterKey(1)}); err == nil {
+		t.Fatal("Current master key must be required")
+	}
+
+	if _, err := NewMasterKeys("key", map[string]string{"key": base64.StdEncoding.EncodeToString([]byte("short"))}); err == nil {
+		t.Fatal("Master key must be 256 bit")
+	}
+
+	old, _ := NewMasterKeys("old", map[string]string{"old": testMasterKey(1)})
+	wrapped, err := old.Wrap([]byte("data key"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keys, _ := NewMasterKeys("new", map[string]string{"old": testMasterKey(1), "new": testMasterKey(2)})
+
+	if key, err := keys.Unwrap("old", wrapped); err != nil || string(key) != "data key" {
+		t.Fatalf("Data key must be unwrapped using old master key, but was: %v %v", string(key), err)
+	}
+
+	if _, err := keys.Unwrap("new", wrapped); err == nil {
+		t.Fatal("Data key must not be unwrapped using other master key")
+	}
+}
+
+func TestEncryptedStore(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, _ := testutil.CreateOrgaAndUser(t)
+	dir := createTestEncryptionDir(t)
+	defer os.RemoveAll(dir)
+	store := NewEncryptedStore(&FileStore{BasePath: dir}, createTestMasterKeys(t))
+	orgaStore := ForOrganization(store, orga.ID)
+
+	for _, size := range []int{0, 1, encryptionSegmentSize - 1, encryptionSegmentSize, encryptionSegmentSize*3 + 17} {
+		data := bytes.Repeat([]byte("x"), size)
+
+		if err := orgaStore.Save("files", "file", bytes.NewReader(data)); err != nil {
+			t.Fatal(err)
+		}
+
+		raw, _ := ioutil.ReadFile(filepath.Join(dir, "files", "file"))
+
+		if size > 0 && bytes.Contains(raw, data) {
+			t.Fatal("File must have been encrypted")
+		}
+
+		reader, err := store.Read("files/file")
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		content, err := ioutil.ReadAll(reader)
+		reader.Close()
+
+		if err != nil || !bytes.Equal(content, data) {
+			t.Fatalf("File of size %v must have been decrypted, but was: %v %v", size, len(content), err)
+		}
+
+		sum := md5.Sum(data)
+		info, err := store.Info("files/file")
+
+		if err != nil || info.Size != int64(size) || info.MD5 != hex.EncodeToString(sum[:]) {
+			t.Fatalf("Info of file of size %v must be returned for decrypted file, but was: %v %v", size, info, err)
+		}
+	}
+
+	dataKey := model.GetDataKeyByOrganizationIdAndActive(orga.ID)
+
+	if dataKey == nil {
+		t.Fatal("Data key must have been created for organization")
+	}
+
+	if id, err := store.DataKeyId("files/file"); err != nil || id != dataKey.ID {
+		t.Fatalf("File must have been encrypted using the data key of the organization, but was: %v %v", id, err)
+	}
+}
+
+func TestFileAEAD(t *testing.T) {
+	dataKey := bytes.Repeat([]byte{1}, dataKeySize)
+	header := make([]byte, encryptionHeaderSize)
+	otherHeader := make([]byte, encryptionHeaderSize)
+	otherHeader[encryptionHeaderSize-1] = 1
+	aead, err := fileAEAD(dataKey, header)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := fileAEAD(dataKey, otherHeader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := segmentNonce(0, true)
+
+	if bytes.Equal(aead.Seal(nil, nonce, []byte("data"), nil), other.Seal(nil, nonce, []byte("data"), nil)) {
+		t.Fatal("Files must be encrypted using different keys for different salts")
+	}
+
+	if _, err := other.Open(nil, nonce, aead.Seal(nil, nonce, []byte("data"), nil), nil); err == nil {
+		t.Fatal("File must not be decrypted using the key of another file")
+	}
+}
+
+func TestEncryptedStoreCorrupt(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	dir := createTestEncryptionDir(t)
+	defer os.RemoveAll(dir)
+	store := NewEncryptedStore(&FileStore{BasePath: dir}, createTestMasterKeys(t))
+	data := bytes.Repeat([]byte("x"), encryptionSegmentSize*2+1)
+
+	if err := store.Save("", "file", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, _ := ioutil.ReadFile(filepath.Join(dir, "file"))
+	tampered := append([]byte{}, raw...)
+	tampered[encryptionHeaderSize+10] ^= 1
+	truncated := raw[:encryptionHeaderSize+encryptionSegmentSize+16]
+
+	for _, content := range [][]byte{tampered, truncated} {
+		if err := ioutil.WriteFile(filepath.Join(dir, "file"), content, 0666); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := store.Info("file"); err == nil {
+			t.Fatal("Corrupt file must not be decrypted")
+		}
+	}
+}
+
+func TestEncryptedStoreUnencrypted(t *testing.T) {
+	dir := createTestEncryptionDir(t)
+	defer os.RemoveAll(dir)
+	store := NewEncryptedStore(&FileStore{BasePath: dir}, createTestMasterKeys(t))
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "file"), []byte("data"), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	if info, err := store.Info("file"); err != nil || info.Size != 4 || info.MD5 != "8d777f385d3dfec8815d20f7496026dc" {
+		t.Fatalf("Unencrypted file must be read as it is, but was: %v %v", info, err)
+	}
+
+	if id, err := store.DataKeyId("file"); err != nil || id != 0 {
+		t.Fatalf("Unencrypted file must not have a data key, but was: %v %v", id, err)
+	}
+}
+
+func TestEncryptedStoreRotation(t *testing.T) {
+	testutil.CleanBackendDb(t)
+	orga, _ := testutil.CreateOrgaAndUser(t)
+	dir := createTestEncryptionDir(t)
+	defer os.RemoveAll(dir)
+	store := NewEncryptedStore(&FileStore{BasePath: dir}, createTestMasterKeys(t)).Organization(orga.ID)
+
+	if err := store.Save("", "old", bytes.NewReader([]byte("old"))); err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey := model.GetDataKeyByOrganizationIdAndActive(orga.ID)
+	dataKey.Retired = null.NewTime(time.Now(), true)
+
+	if err := model.SaveDataKey(nil, dataKey); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Save("", "new", bytes.NewReader([]byte("new"))); err != nil {
+		t.Fatal(err)
+	}
+
+	oldId, _ := store.DataKeyId("old")
+	newId, _ := store.DataKeyId("new")
+
+	if oldId != dataKey.ID || newId == dataKey.ID || newId == 0 {
+		t.Fatalf("New file must have been encrypted using a new data key, but was: %v %v", oldId, newId)
+	}
+
+	keys, _ := NewMasterKeys("new", map[string]string{"test": testMasterKey(1), "new": testMasterKey(2)})
+	rotated := NewEncryptedStore(&FileStore{BasePath: dir}, keys)
+
+	if err := rotated.RewrapDataKey(dataKey); err != nil {
+		t.Fatal(err)
+	}
+
+	dataKey = model.GetDataKeyById(dataKey.ID)
+
+	if dataKey.MasterKeyId != "new" {
+		t.Fatalf("Data key must have been wrapped using new master key, but was: %v", dataKey.MasterKeyId)
+	}
+
+	rotated = NewEncryptedStore(&FileStore{BasePath: dir}, keys)
+
+	if info, err := rotated.Info("old"); err != nil || info.Size != 3 {
+		t.Fatalf("File must be decrypted using rewrapped data key, but was: %v %v", info, err)
+	}
+}
+
+func createTestEncryptionDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "encrypted")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func createTestMasterKeys(t *testing.T) *MasterKeys {
+	keys, err := NewMasterKeys("test", map[string]string{"test": testMasterKey(1)})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return keys
+}
+
+func testMasterKey(b byte) string {
+	return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, masterKeySize))
+}
@@ -18,6 +18,10 @@ func NewFileStore() *FileStore {
 	return &FileStore{config.Get().Storage.Path}
 }
 
+func newFileStore(path string) *FileStore {
+	return &FileStore{path}
+}
+
 func (store *FileStore) Read(path string) (io.ReadCloser, error) {
 	file, err := os.Open(filepath.Join(store.BasePath, path))
 
@@ -43,20 +47,9 @@ func (store *FileStore) Info(path string) (FileInfo, error) {
 	}
 
 	h := md5.New()
-	buffer := make([]byte, 512)
 
-	for {
-		_, err := file.Read(buffer)
-
-		if err == io.EOF {
-			break
-		} else if err != nil {
-			return FileInfo{}, err
-		}
-
-		if _, err := h.Write(buffer); err != nil {
-			return FileInfo{}, err
-		}
+	if _, err := io.Copy(h, file); err != nil {
+		return FileInfo{}, err
 	}
 
 	return FileInfo{
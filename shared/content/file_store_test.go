@@ -51,7 +51,7 @@ func TestFileStoreInfo(t *testing.T) {
 		t.Fatalf("File size must be correct, but was: %v", info.Size)
 	}
 
-	if info.MD5 != "cafbbc1fc265231f1e4595d17ea5c04b" {
+	if info.MD5 != "8d777f385d3dfec8815d20f7496026dc" {
 		t.Fatalf("File MD5 must be correct, but was: %v", info.MD5)
 	}
 }
@@ -4,20 +4,39 @@ import (
 	"context"
 	"emviwiki/shared/config"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/emvi/logbuch"
 	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"cloud.google.com/go/storage"
 )
 
+const (
+	gcsCredentialsEnv = "GOOGLE_APPLICATION_CREDENTIALS"
+)
+
 type GoogleCloudStore struct {
-	client *storage.Client
-	bucket *storage.BucketHandle
+	client     *storage.Client
+	bucket     *storage.BucketHandle
+	bucketName string
+
+	// service account used to sign URLs, optional
+	accessId   string
+	privateKey []byte
 }
 
 func NewGoogleCloudStore() *GoogleCloudStore {
+	return newGoogleCloudStore(config.Get().Storage.GCSBucket)
+}
+
+func newGoogleCloudStore(bucketName string) *GoogleCloudStore {
 	ctx := context.Background()
 	client, err := storage.NewClient(ctx)
 
@@ -26,7 +45,7 @@ func NewGoogleCloudStore() *GoogleCloudStore {
 		return nil
 	}
 
-	bucketName := strings.TrimSpace(config.Get().Storage.GCSBucket)
+	bucketName = strings.TrimSpace(bucketName)
 
 	if bucketName == "" {
 		logbuch.Fatal("Bucket name must not be empty", logbuch.Fields{"name": bucketName})
@@ -34,8 +53,37 @@ func NewGoogleCloudStore() *GoogleCloudStore {
 
 	bucket := client.Bucket(bucketName)
 	logbuch.Info("Bucket name set", logbuch.Fields{"name": bucketName})
+	accessId, privateKey := readGoogleCloudServiceAccount()
+	return &GoogleCloudStore{client, bucket, bucketName, accessId, privateKey}
+}
 
-	return &GoogleCloudStore{client, bucket}
+// readGoogleCloudServiceAccount reads the email and private key from the credentials file.
+// Both are empty if no service account key file is used, in which case URLs cannot be signed.
+func readGoogleCloudServiceAccount() (string, []byte) {
+	path := os.Getenv(gcsCredentialsEnv)
+
+	if path == "" {
+		return "", nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		logbuch.Warn("Error reading Google Cloud credentials to sign URLs", logbuch.Fields{"err": err, "path": path})
+		return "", nil
+	}
+
+	credentials := struct {
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+	}{}
+
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		logbuch.Warn("Error parsing Google Cloud credentials to sign URLs", logbuch.Fields{"err": err, "path": path})
+		return "", nil
+	}
+
+	return credentials.ClientEmail, []byte(credentials.PrivateKey)
 }
 
 func (store *GoogleCloudStore) Read(path string) (io.ReadCloser, error) {
@@ -81,3 +129,25 @@ func (store *GoogleCloudStore) Delete(path string) error {
 
 	return nil
 }
+
+func (store *GoogleCloudStore) PresignedURL(path, filename, mimeType string, expiry time.Duration) (string, error) {
+	if store.accessId == "" || len(store.privateKey) == 0 {
+		return "", errors.New("no service account configured to sign URLs")
+	}
+
+	params := url.Values{}
+	params.Set("response-content-disposition", contentDisposition(filename, mimeType))
+
+	if mimeType != "" {
+		params.Set("response-content-type", mimeType)
+	}
+
+	return storage.SignedURL(store.bucketName, path, &storage.SignedURLOptions{
+		GoogleAccessID:  store.accessId,
+		PrivateKey:      store.privateKey,
+		Method:          "GET",
+		Expires:         time.Now().Add(expiry),
+		QueryParameters: params,
+		Scheme:          storage.SigningSchemeV4,
+	})
+}
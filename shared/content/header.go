@@ -4,19 +4,26 @@ import (
 	"net/http"
 )
 
-// SetContentDownloadHeader sets the relevant HTTP headers for file downloads
+// SetContentDownloadHeader sets the relevant HTTP headers for file downloads.
+// The MD5 is used as a strong ETag, so that conditional requests can be answered.
 func SetContentDownloadHeader(w http.ResponseWriter, filename, mimeType, md5 string) {
 	if mimeType != "" {
 		w.Header().Add("Content-Type", mimeType)
-		w.Header().Add("Content-Disposition", "inline; filename="+filename)
-	} else {
-		w.Header().Add("Content-Disposition", "attachment; filename="+filename)
 	}
 
+	w.Header().Add("Content-Disposition", contentDisposition(filename, mimeType))
 	w.Header().Add("X-Content-Type-Options", "nosniff")
 	w.Header().Add("X-Dns-Prefetch-Control", "off")
 	w.Header().Add("X-Download-Options", "noopen")
 	w.Header().Add("X-Xss-Protection", "1; mode=block")
 	w.Header().Add("Cache-Control", "max-age=1200")
-	w.Header().Add("ETag", md5)
+	w.Header().Add("ETag", `"`+md5+`"`)
+}
+
+func contentDisposition(filename, mimeType string) string {
+	if mimeType != "" {
+		return "inline; filename=" + filename
+	}
+
+	return "attachment; filename=" + filename
 }
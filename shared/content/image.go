@@ -0,0 +1,432 @@
+package content
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	ImageOriginal  = "original"
+	ImageMedium    = "medium"
+	ImageThumbnail = "thumbnail"
+
+	jpegMimeType = "image/jpeg"
+	pngMimeType  = "image/png"
+	webpMimeType = "image/webp"
+	jpegQuality  = 85
+
+	// images exceeding these limits are kept as they are, because decoding them requires too much memory
+	maxImageSize   = 50 * 1024 * 1024
+	maxImagePixels = 50000000
+
+	exifOrientationTag = 0x0112
+	webpExifFlag       = 0x08
+	webpXMPFlag        = 0x04
+)
+
+// ErrImageTooLarge is returned by ProcessImage for images exceeding the size or pixel limit.
+var ErrImageTooLarge = errors.New("image too large to process")
+
+// ImageDerivative is a resized version of an uploaded image.
+// The size is the maximum width and height in pixels.
+type ImageDerivative struct {
+	Name string
+	Size int
+}
+
+// ImageDerivatives are the resized versions created for uploaded images, next to the original.
+var ImageDerivatives = []ImageDerivative{
+	{ImageMedium, 1280},
+	{ImageThumbnail, 320},
+}
+
+// IsProcessableImage returns true if derivatives can be created for given mime type.
+// Animated and vector formats are kept as they are.
+func IsProcessableImage(mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	return mimeType == jpegMimeType || mimeType == pngMimeType || mimeType == webpMimeType
+}
+
+// IsImageDerivative returns true if the name is a known derivative, excluding the original.
+func IsImageDerivative(name string) bool {
+	for _, derivative := range ImageDerivatives {
+		if derivative.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ImageDerivativePath returns the path in store for the derivative of an image of given mime type.
+// The derivative name is added to the filename in front of the extension.
+// Derivatives of WebP images are saved as PNG, so they get the .png extension.
+func ImageDerivativePath(path, derivative, mimeType string) string {
+	if derivative == "" || derivative == ImageOriginal {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	derivativeExt := ext
+
+	if strings.ToLower(mimeType) == webpMimeType {
+		derivativeExt = ".png"
+	}
+
+	return strings.TrimSuffix(path, ext) + "_" + derivative + derivativeExt
+}
+
+// ImageDerivativeMimeType returns the mime type of the derivatives of an image of given mime type.
+func ImageDerivativeMimeType(mimeType string) string {
+	if strings.ToLower(mimeType) == webpMimeType {
+		return pngMimeType
+	}
+
+	return mimeType
+}
+
+// ProcessImage replaces the image in store by a version without metadata (like EXIF and GPS data)
+// and saves the resized derivatives next to it. The orientation stored in EXIF is applied to the pixels.
+// Derivatives are not created for images smaller than the derivative size.
+// WebP images cannot be encoded, so the metadata is removed from the original without decoding it and derivatives are saved as PNG
+// (see ImageDerivativePath and ImageDerivativeMimeType).
+// ErrImageTooLarge is returned for images exceeding the size or pixel limit, which are kept as they are.
+func ProcessImage(store ContentStore, dir, filename, mimeType string) error {
+	if !IsProcessableImage(mimeType) {
+		return nil
+	}
+
+	path := filepath.Join(dir, filename)
+	reader, err := store.Read(path)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(reader, maxImageSize+1))
+
+	if closeErr := reader.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(data) > maxImageSize {
+		return ErrImageTooLarge
+	}
+
+	// check the dimensions before decoding, so that small files cannot expand to huge images
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	if int64(config.Width)*int64(config.Height) > maxImagePixels {
+		return ErrImageTooLarge
+	}
+
+	img, format, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		return err
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, readExifOrientation(data))
+	}
+
+	if format == "webp" {
+		if err := store.Save(dir, filename, bytes.NewReader(stripWebPMetadata(data))); err != nil {
+			return err
+		}
+
+		format = "png"
+	} else if err := saveImage(store, dir, filename, format, img); err != nil {
+		return err
+	}
+
+	for _, derivative := range ImageDerivatives {
+		if img.Bounds().Dx() <= derivative.Size && img.Bounds().Dy() <= derivative.Size {
+			continue
+		}
+
+		_, name := filepath.Split(ImageDerivativePath(path, derivative.Name, mimeType))
+
+		if err := saveImage(store, dir, name, format, resizeImage(img, derivative.Size)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteWithDerivatives deletes the file for given path and all image derivatives stored for it.
+// The mime type of the file is not known, so the derivatives are looked up for all paths they might be saved in.
+func DeleteWithDerivatives(store ContentStore, path string) error {
+	for _, derivative := range ImageDerivatives {
+		for _, derivativePath := range []string{ImageDerivativePath(path, derivative.Name, ""), ImageDerivativePath(path, derivative.Name, webpMimeType)} {
+			if _, err := store.Info(derivativePath); err == nil {
+				if err := store.Delete(derivativePath); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return store.Delete(path)
+}
+
+func saveImage(store ContentStore, dir, filename, format string, img image.Image) error {
+	var buffer bytes.Buffer
+
+	if err := encodeImage(&buffer, format, img); err != nil {
+		return err
+	}
+
+	return store.Save(dir, filename, &buffer)
+}
+
+func encodeImage(w io.Writer, format string, img image.Image) error {
+	if format == "jpeg" {
+		return jpeg.Encode(w, img, &jpeg.Options{Quality: jpegQuality})
+	} else if format == "png" {
+		return png.Encode(w, img)
+	}
+
+	return errors.New("unsupported image format")
+}
+
+// resizeImage scales the image down to fit into a square of given size, keeping the aspect ratio.
+// Each target pixel is the average of the source pixels it covers.
+func resizeImage(img image.Image, size int) image.Image {
+	src := toRGBA(img)
+	srcWidth, srcHeight := src.Bounds().Dx(), src.Bounds().Dy()
+	width, height := size, size
+
+	if srcWidth > srcHeight {
+		height = maxInt(1, srcHeight*size/srcWidth)
+	} else {
+		width = maxInt(1, srcWidth*size/srcHeight)
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0, y1 := y*srcHeight/height, maxInt((y+1)*srcHeight/height, y*srcHeight/height+1)
+
+		for x := 0; x < width; x++ {
+			x0, x1 := x*srcWidth/width, maxInt((x+1)*srcWidth/width, x*srcWidth/width+1)
+			var r, g, b, a, n int
+
+			for sy := y0; sy < y1; sy++ {
+				offset := sy*src.Stride + x0*4
+
+				for sx := x0; sx < x1; sx++ {
+					r += int(src.Pix[offset])
+					g += int(src.Pix[offset+1])
+					b += int(src.Pix[offset+2])
+					a += int(src.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+
+			i := y*dst.Stride + x*4
+			dst.Pix[i] = uint8(r / n)
+			dst.Pix[i+1] = uint8(g / n)
+			dst.Pix[i+2] = uint8(b / n)
+			dst.Pix[i+3] = uint8(a / n)
+		}
+	}
+
+	return dst
+}
+
+// applyOrientation rotates and flips the image according to the EXIF orientation (1-8).
+func applyOrientation(img image.Image, orientation int) image.Image {
+	if orientation < 2 || orientation > 8 {
+		return img
+	}
+
+	src := toRGBA(img)
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	var dst *image.RGBA
+
+	if orientation >= 5 {
+		dst = image.NewRGBA(image.Rect(0, 0, h, w))
+	} else {
+		dst = image.NewRGBA(image.Rect(0, 0, w, h))
+	}
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+
+			switch orientation {
+			case 2:
+				dx, dy = w-1-x, y
+			case 3:
+				dx, dy = w-1-x, h-1-y
+			case 4:
+				dx, dy = x, h-1-y
+			case 5:
+				dx, dy = y, x
+			case 6:
+				dx, dy = h-1-y, x
+			case 7:
+				dx, dy = h-1-y, w-1-x
+			case 8:
+				dx, dy = y, w-1-x
+			}
+
+			copy(dst.Pix[dy*dst.Stride+dx*4:dy*dst.Stride+dx*4+4], src.Pix[y*src.Stride+x*4:y*src.Stride+x*4+4])
+		}
+	}
+
+	return dst
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, bounds.Min, draw.Src)
+	return rgba
+}
+
+// readExifOrientation returns the orientation stored in the EXIF data of a JPEG or 0 if not found.
+func readExifOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 0
+	}
+
+	offset := 2
+
+	for offset+4 <= len(data) {
+		if data[offset] != 0xFF {
+			return 0
+		}
+
+		marker := data[offset+1]
+		length := int(binary.BigEndian.Uint16(data[offset+2:]))
+
+		// start of scan, no metadata after this point
+		if marker == 0xDA || length < 2 || offset+2+length > len(data) {
+			return 0
+		}
+
+		segment := data[offset+4 : offset+2+length]
+
+		if marker == 0xE1 && len(segment) > 6 && string(segment[:6]) == "Exif\x00\x00" {
+			return readTiffOrientation(segment[6:])
+		}
+
+		offset += 2 + length
+	}
+
+	return 0
+}
+
+func readTiffOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 0
+	}
+
+	var order binary.ByteOrder
+
+	if string(tiff[:2]) == "II" {
+		order = binary.LittleEndian
+	} else if string(tiff[:2]) == "MM" {
+		order = binary.BigEndian
+	} else {
+		return 0
+	}
+
+	ifd := int(order.Uint32(tiff[4:]))
+
+	if ifd+2 > len(tiff) {
+		return 0
+	}
+
+	entries := int(order.Uint16(tiff[ifd:]))
+
+	for i := 0; i < entries; i++ {
+		entry := ifd + 2 + i*12
+
+		if entry+12 > len(tiff) {
+			return 0
+		}
+
+		if order.Uint16(tiff[entry:]) == exifOrientationTag {
+			return int(order.Uint16(tiff[entry+8:]))
+		}
+	}
+
+	return 0
+}
+
+// stripWebPMetadata removes the EXIF and XMP chunks from a WebP image and clears their flags in the extended header.
+// The image is returned as it is if it cannot be parsed.
+func stripWebPMetadata(data []byte) []byte {
+	if len(data) < 12 || string(data[:4]) != "RIFF" || string(data[8:12]) != "WEBP" {
+		return data
+	}
+
+	out := make([]byte, 12, len(data))
+	copy(out, data[:12])
+	offset := 12
+
+	for offset+8 <= len(data) {
+		fourCC := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		end := offset + 8 + size + size%2
+
+		if size < 0 || end > len(data) {
+			// the last chunk might not be padded
+			if offset+8+size != len(data) {
+				return data
+			}
+
+			end = len(data)
+		}
+
+		if fourCC != "EXIF" && fourCC != "XMP " {
+			start := len(out)
+			out = append(out, data[offset:end]...)
+
+			if fourCC == "VP8X" && size > 0 {
+				out[start+8] &^= webpExifFlag | webpXMPFlag
+			}
+		}
+
+		offset = end
+	}
+
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
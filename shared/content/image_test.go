@@ -0,0 +1,217 @@
+package content
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+// 75x100 pixel lossless WebP image
+const testWebP = "UklGRrIBAABXRUJQVlA4TKUBAAAvSsAYAA8w//M///MfeJAkbXvaSG7m8Q3GfYSBJekwQztm/IcZlgwnmWImn2BK7aFmBtnVir6q//8VOkFE/xm4baTIu8c48ArEo6+B3zFKYln3pqClSCKX0begFTAXFOLXHSyF8cCNcZEG4OywuA4KVVfJCiArU7GAgJI8+lJP/OKMT/fBAjevg1cYB7YVkFuWga2lyPi5I0HFy5YTpWIHg0RZpkniRVW9odHAKOwosWuOGdxIyn2OvaCDvhg/we6TwadPBPbqBV58MsLmMJ8yZnOWk8SRz4N+QoyPL+MnamzMvcE1rHNEr91F9GKZPVUcS9w7PhhH36suB9qPeYb/oLk6cuTiJ0wOK3m5h1cKjW6EVZCYMK7dxcKCBdgP9HkKr9gkAO2P8GKZGWVdIAatQa+1IDpt6qyorVwdy01xdW8Jkfk6xjEXmVQQ+HQdFr6OKhIN34dXWq0+0qr6EJSCeeVLH9+gvGTLyqM65PQ44ihzlTXxQKjKbAvshXgir7Lil9w4L2bvMycmjQcqXaMCO6BlY28i+FOLzbfI1vEqxAhotocAAA=="
+
+func TestProcessImage(t *testing.T) {
+	createTestImage(t, 400, 200, 6)
+	bucket := &FileStore{}
+
+	if err := ProcessImage(bucket, "bucket/images", "photo.jpg", "image/jpeg"); err != nil {
+		t.Fatalf("Image must have been processed, but was: %v", err)
+	}
+
+	data, err := ioutil.ReadFile("bucket/images/photo.jpg")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(data, []byte("Exif")) {
+		t.Fatal("EXIF data must have been removed")
+	}
+
+	if width, height := decodeTestImageSize(t, "bucket/images/photo.jpg"); width != 200 || height != 400 {
+		t.Fatalf("Orientation must have been applied, but was: %vx%v", width, height)
+	}
+
+	if width, height := decodeTestImageSize(t, "bucket/images/photo_thumbnail.jpg"); width != 160 || height != 320 {
+		t.Fatalf("Thumbnail must have been created, but was: %vx%v", width, height)
+	}
+
+	if _, err := os.Stat("bucket/images/photo_medium.jpg"); err == nil {
+		t.Fatal("Medium size must not have been created for small images")
+	}
+
+	if err := DeleteWithDerivatives(bucket, "bucket/images/photo.jpg"); err != nil {
+		t.Fatalf("Image must have been deleted, but was: %v", err)
+	}
+
+	if _, err := os.Stat("bucket/images/photo_thumbnail.jpg"); err == nil {
+		t.Fatal("Thumbnail must have been deleted")
+	}
+}
+
+func TestProcessImageIgnoreUnsupported(t *testing.T) {
+	if err := ProcessImage(NewDummyStore(), "bucket/images", "image.gif", "image/gif"); err != nil {
+		t.Fatalf("Unsupported images must be ignored, but was: %v", err)
+	}
+}
+
+func TestProcessImageTooLarge(t *testing.T) {
+	var buffer bytes.Buffer
+
+	if err := png.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	// set the size in the header to 10000x10000 pixels
+	data := buffer.Bytes()
+	binary.BigEndian.PutUint32(data[16:], 10000)
+	binary.BigEndian.PutUint32(data[20:], 10000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	if err := (&FileStore{}).Save("bucket/images", "bomb.png", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ProcessImage(&FileStore{}, "bucket/images", "bomb.png", "image/png"); err != ErrImageTooLarge {
+		t.Fatalf("Image must be too large, but was: %v", err)
+	}
+}
+
+func TestProcessImageWebP(t *testing.T) {
+	data, err := base64.StdEncoding.DecodeString(testWebP)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// add an extended header and EXIF chunk in front of the image
+	var webp bytes.Buffer
+	webp.WriteString("RIFF\x00\x00\x00\x00WEBPVP8X\x0a\x00\x00\x00")
+	webp.Write([]byte{webpExifFlag, 0, 0, 0, 74, 0, 0, 99, 0, 0})
+	webp.WriteString("EXIF\x04\x00\x00\x00GPS!")
+	webp.Write(data[12:])
+	data = webp.Bytes()
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+
+	if err := (&FileStore{}).Save("bucket/images", "image.webp", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ProcessImage(&FileStore{}, "bucket/images", "image.webp", "image/webp"); err != nil {
+		t.Fatalf("Image must have been processed, but was: %v", err)
+	}
+
+	data, err = ioutil.ReadFile("bucket/images/image.webp")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(data, []byte("GPS!")) || data[20]&webpExifFlag != 0 {
+		t.Fatal("EXIF data must have been removed")
+	}
+
+	if width, height := decodeTestImageSize(t, "bucket/images/image.webp"); width != 75 || height != 100 {
+		t.Fatalf("Image must still be readable, but was: %vx%v", width, height)
+	}
+}
+
+func TestImageDerivativePath(t *testing.T) {
+	input := []struct {
+		path       string
+		derivative string
+		mimeType   string
+	}{
+		{"some/path/file.jpg", "", "image/jpeg"},
+		{"some/path/file.jpg", ImageOriginal, "image/jpeg"},
+		{"some/path/file.jpg", ImageThumbnail, "image/jpeg"},
+		{"blob/1/md5", ImageMedium, "image/jpeg"},
+		{"some/path/file.webp", ImageOriginal, "image/webp"},
+		{"some/path/file.webp", ImageThumbnail, "image/webp"},
+		{"blob/1/md5", ImageMedium, "image/webp"},
+	}
+	expected := []string{
+		"some/path/file.jpg",
+		"some/path/file.jpg",
+		"some/path/file_thumbnail.jpg",
+		"blob/1/md5_medium",
+		"some/path/file.webp",
+		"some/path/file_thumbnail.png",
+		"blob/1/md5_medium.png",
+	}
+
+	for i, in := range input {
+		if path := ImageDerivativePath(in.path, in.derivative, in.mimeType); path != expected[i] {
+			t.Fatalf("Expected '%v', but was: %v", expected[i], path)
+		}
+	}
+}
+
+func TestImageDerivativeMimeType(t *testing.T) {
+	if mimeType := ImageDerivativeMimeType("image/jpeg"); mimeType != "image/jpeg" {
+		t.Fatalf("JPEG derivatives must be JPEG, but was: %v", mimeType)
+	}
+
+	if mimeType := ImageDerivativeMimeType("image/webp"); mimeType != "image/png" {
+		t.Fatalf("WebP derivatives must be PNG, but was: %v", mimeType)
+	}
+}
+
+func TestApplyOrientation(t *testing.T) {
+	img := image.NewRGBA(image.Rect(0, 0, 2, 1))
+	img.Set(0, 0, color.RGBA{255, 0, 0, 255})
+	rotated := applyOrientation(img, 6)
+
+	if rotated.Bounds().Dx() != 1 || rotated.Bounds().Dy() != 2 {
+		t.Fatalf("Image must have been rotated, but was: %v", rotated.Bounds())
+	}
+
+	if r, _, _, _ := rotated.At(0, 0).RGBA(); r != 0xFFFF {
+		t.Fatal("Top left pixel must have been rotated to the top right")
+	}
+}
+
+func createTestImage(t *testing.T, width, height, orientation int) {
+	if err := os.MkdirAll("bucket/images", 0777); err != nil {
+		t.Fatal(err)
+	}
+
+	var buffer bytes.Buffer
+
+	if err := jpeg.Encode(&buffer, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// APP1 segment with a little endian TIFF header and a single IFD entry for the orientation
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0, 1, 0, 0x12, 0x01, 3, 0, 1, 0, 0, 0, byte(orientation), 0, 0, 0, 0, 0, 0, 0}
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := append([]byte{0xFF, 0xE1, byte((len(segment) + 2) >> 8), byte(len(segment) + 2)}, segment...)
+	data := append(append([]byte{0xFF, 0xD8}, app1...), buffer.Bytes()[2:]...)
+
+	if err := ioutil.WriteFile("bucket/images/photo.jpg", data, 0666); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func decodeTestImageSize(t *testing.T, path string) (int, int) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return img.Bounds().Dx(), img.Bounds().Dy()
+}
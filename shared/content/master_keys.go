@@ -0,0 +1,104 @@
+package content
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+const (
+	masterKeySize = 32
+)
+
+// KeyWrapper encrypts (wraps) and decrypts (unwraps) data keys using master keys.
+// It can be implemented by a key management service, so that master keys never leave it.
+type KeyWrapper interface {
+	// MasterKeyId returns the ID of the master key used to wrap new data keys.
+	MasterKeyId() string
+
+	// Wrap encrypts the data key using the current master key.
+	Wrap(dataKey []byte) ([]byte, error)
+
+	// Unwrap decrypts the data key using the master key for given ID.
+	Unwrap(masterKeyId string, wrapped []byte) ([]byte, error)
+}
+
+// MasterKeys is a KeyWrapper using locally configured master keys and AES-256-GCM.
+// Old master keys are kept to unwrap data keys until they have been wrapped using the current master key.
+type MasterKeys struct {
+	current string
+	keys    map[string]cipher.AEAD
+}
+
+// NewMasterKeys creates a new KeyWrapper for given base64 encoded master keys and the ID of the current master key.
+func NewMasterKeys(current string, keys map[string]string) (*MasterKeys, error) {
+	masterKeys := &MasterKeys{current: current, keys: make(map[string]cipher.AEAD)}
+
+	for id, key := range keys {
+		data, err := base64.StdEncoding.DecodeString(key)
+
+		if err != nil {
+			return nil, fmt.Errorf("master key %s is not base64 encoded: %v", id, err)
+		}
+
+		if len(data) != masterKeySize {
+			return nil, fmt.Errorf("master key %s must be %d bytes long", id, masterKeySize)
+		}
+
+		aead, err := newAEAD(data)
+
+		if err != nil {
+			return nil, err
+		}
+
+		masterKeys.keys[id] = aead
+	}
+
+	if _, ok := masterKeys.keys[current]; !ok {
+		return nil, fmt.Errorf("current master key %s not found", current)
+	}
+
+	return masterKeys, nil
+}
+
+func (keys *MasterKeys) MasterKeyId() string {
+	return keys.current
+}
+
+func (keys *MasterKeys) Wrap(dataKey []byte) ([]byte, error) {
+	aead := keys.keys[keys.current]
+	nonce := make([]byte, aead.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, dataKey, []byte(keys.current)), nil
+}
+
+func (keys *MasterKeys) Unwrap(masterKeyId string, wrapped []byte) ([]byte, error) {
+	aead, ok := keys.keys[masterKeyId]
+
+	if !ok {
+		return nil, fmt.Errorf("master key %s not found", masterKeyId)
+	}
+
+	if len(wrapped) < aead.NonceSize() {
+		return nil, errors.New("wrapped data key too short")
+	}
+
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(masterKeyId))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
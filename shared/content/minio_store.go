@@ -1,13 +1,21 @@
 package content
 
 import (
+	"crypto/md5"
 	"emviwiki/shared/config"
-	"errors"
+	"encoding/hex"
 	"github.com/emvi/logbuch"
 	"github.com/minio/minio-go"
 	"io"
+	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
+)
+
+const (
+	minioMD5Metadata = "md5"
+	minioMD5Header   = "X-Amz-Meta-Md5"
 )
 
 type MinioStore struct {
@@ -16,7 +24,10 @@ type MinioStore struct {
 }
 
 func NewMinioStore() *MinioStore {
-	c := config.Get().Storage.Minio
+	return newMinioStore(config.Get().Storage.Minio)
+}
+
+func newMinioStore(c config.Minio) *MinioStore {
 	client, err := minio.New(c.Endpoint, c.ID, c.Secret, c.Secure)
 
 	if err != nil {
@@ -53,6 +64,9 @@ func (store *MinioStore) Read(path string) (io.ReadCloser, error) {
 	return store.client.GetObject(store.bucketName, path, minio.GetObjectOptions{})
 }
 
+// Info returns the size and MD5 of the object.
+// The ETag of objects uploaded in multiple parts is not the MD5 of the content,
+// so the MD5 stored as metadata when saving is used, or it is calculated from the content if not set.
 func (store *MinioStore) Info(path string) (FileInfo, error) {
 	stat, err := store.client.StatObject(store.bucketName, path, minio.StatObjectOptions{})
 
@@ -60,26 +74,49 @@ func (store *MinioStore) Info(path string) (FileInfo, error) {
 		return FileInfo{}, err
 	}
 
-	etag := strings.Split(stat.ETag, "-")
+	if sum := stat.Metadata.Get(minioMD5Header); sum != "" {
+		return FileInfo{stat.Size, sum}, nil
+	}
+
+	if !strings.Contains(stat.ETag, "-") {
+		return FileInfo{stat.Size, stat.ETag}, nil
+	}
+
+	reader, err := store.Read(path)
 
-	if len(etag) < 1 {
-		return FileInfo{}, errors.New("ETag has unexpected number of parts (splitting at '-')")
+	if err != nil {
+		return FileInfo{}, err
+	}
+
+	defer reader.Close()
+	h := md5.New()
+	size, err := io.Copy(h, reader)
+
+	if err != nil {
+		return FileInfo{}, err
 	}
 
-	return FileInfo{
-		stat.Size,
-		etag[0],
-	}, nil
+	return FileInfo{size, hex.EncodeToString(h.Sum(nil))}, nil
 }
 
+// Save uploads the object and stores the MD5 of its content as metadata afterwards.
+// The size is unknown, so the object is always uploaded in multiple parts.
 func (store *MinioStore) Save(path, filename string, reader io.Reader) error {
-	_, err := store.client.PutObject(store.bucketName, filepath.Join(path, filename), reader, -1, minio.PutObjectOptions{})
+	name := filepath.Join(path, filename)
+	h := md5.New()
+
+	if _, err := store.client.PutObject(store.bucketName, name, io.TeeReader(reader, h), -1, minio.PutObjectOptions{}); err != nil {
+		return err
+	}
+
+	// metadata can only be set by copying the object onto itself
+	dst, err := minio.NewDestinationInfo(store.bucketName, name, nil, map[string]string{minioMD5Metadata: hex.EncodeToString(h.Sum(nil))})
 
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return store.client.ComposeObject(dst, []minio.SourceInfo{minio.NewSourceInfo(store.bucketName, name, nil)})
 }
 
 func (store *MinioStore) Delete(path string) error {
@@ -89,3 +126,20 @@ func (store *MinioStore) Delete(path string) error {
 
 	return nil
 }
+
+func (store *MinioStore) PresignedURL(path, filename, mimeType string, expiry time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("response-content-disposition", contentDisposition(filename, mimeType))
+
+	if mimeType != "" {
+		params.Set("response-content-type", mimeType)
+	}
+
+	u, err := store.client.PresignedGetObject(store.bucketName, path, expiry, params)
+
+	if err != nil {
+		return "", err
+	}
+
+	return u.String(), nil
+}
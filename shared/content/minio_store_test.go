@@ -0,0 +1,69 @@
+package content
+
+import (
+	"bytes"
+	"crypto/md5"
+	"emviwiki/shared/config"
+	"emviwiki/shared/testutil"
+	"encoding/hex"
+	"github.com/minio/minio-go"
+	"io/ioutil"
+	"testing"
+)
+
+func TestMinioStoreInfo(t *testing.T) {
+	server := testutil.NewS3Server()
+	defer server.Close()
+	store := newMinioStore(config.Minio{Endpoint: server.Endpoint(), ID: "id", Secret: "secret", Bucket: "bucket"})
+	data := []byte("content")
+	sum := md5.Sum(data)
+
+	if err := store.Save("dir", "file", bytes.NewReader(data)); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := store.Info("dir/file")
+
+	if err != nil || info.Size != int64(len(data)) || info.MD5 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("Info must contain size and MD5 of content, but was: %v %v", info, err)
+	}
+
+	// objects uploaded in multiple parts without MD5 metadata
+	if _, err := store.client.PutObject("bucket", "multipart", bytes.NewReader(data), -1, minio.PutObjectOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err = store.Info("multipart")
+
+	if err != nil || info.Size != int64(len(data)) || info.MD5 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("MD5 must have been calculated from content, but was: %v %v", info, err)
+	}
+
+	reader, err := store.Read("dir/file")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadAll(reader)
+	reader.Close()
+
+	if err != nil || string(content) != "content" {
+		t.Fatalf("Content not as expected: %v %v", string(content), err)
+	}
+
+	if _, err := store.Info("missing"); !IsNotExist(err) {
+		t.Fatalf("Object must not exist, but was: %v", err)
+	}
+
+	reader, err = store.Read("missing")
+
+	if err == nil {
+		_, err = ioutil.ReadAll(reader)
+		reader.Close()
+	}
+
+	if !IsNotExist(err) {
+		t.Fatalf("Object must not exist, but was: %v", err)
+	}
+}
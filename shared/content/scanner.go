@@ -0,0 +1,163 @@
+package content
+
+import (
+	"bufio"
+	"emviwiki/shared/config"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/emvi/logbuch"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	clamdChunkSize      = 32768
+	defaultScanTimeout  = 60 // seconds
+	clamdResponseOK     = "OK"
+	clamdResponseFound  = " FOUND"
+	clamdResponseError  = " ERROR"
+	clamdResponsePrefix = "stream: "
+)
+
+// ScanResult is the result of scanning a file for malware.
+// The signature is the name of the malware found, if infected.
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+// Scanner scans file content for malware.
+type Scanner interface {
+	Scan(io.Reader) (ScanResult, error)
+}
+
+// SelectScanner selects the malware scanner by configured scanner type.
+// If no type or an unknown type is configured or the scanner cannot be created, files are not scanned.
+func SelectScanner() Scanner {
+	scannerConfig := config.Get().Storage.Scanner
+
+	if scannerConfig.Type == "clamd" {
+		scanner, err := NewClamdScanner(scannerConfig.ClamdAddress, time.Second*time.Duration(scannerConfig.Timeout))
+
+		if err != nil {
+			logbuch.Error("Error creating clamd scanner, uploaded files are not scanned for malware", logbuch.Fields{"err": err, "address": scannerConfig.ClamdAddress})
+			return NoopScanner{}
+		}
+
+		logbuch.Info("Using clamd to scan uploaded files", logbuch.Fields{"address": scannerConfig.ClamdAddress})
+		return scanner
+	}
+
+	logbuch.Info("Uploaded files are not scanned for malware")
+	return NoopScanner{}
+}
+
+// NoopScanner reports all files as clean.
+type NoopScanner struct{}
+
+func (scanner NoopScanner) Scan(reader io.Reader) (ScanResult, error) {
+	return ScanResult{}, nil
+}
+
+// ClamdScanner scans files by streaming them to a ClamAV daemon using the INSTREAM command.
+type ClamdScanner struct {
+	network string
+	address string
+	timeout time.Duration
+}
+
+// NewClamdScanner creates a new scanner for given clamd address.
+// The address must either be a TCP address (tcp://host:port) or a unix socket (unix:///path/to/socket).
+func NewClamdScanner(address string, timeout time.Duration) (*ClamdScanner, error) {
+	if timeout <= 0 {
+		timeout = time.Second * defaultScanTimeout
+	}
+
+	if strings.HasPrefix(address, "tcp://") {
+		return &ClamdScanner{"tcp", strings.TrimPrefix(address, "tcp://"), timeout}, nil
+	} else if strings.HasPrefix(address, "unix://") {
+		return &ClamdScanner{"unix", strings.TrimPrefix(address, "unix://"), timeout}, nil
+	}
+
+	return nil, fmt.Errorf("clamd address '%s' must start with tcp:// or unix://", address)
+}
+
+func (scanner *ClamdScanner) Scan(reader io.Reader) (ScanResult, error) {
+	conn, err := net.DialTimeout(scanner.network, scanner.address, scanner.timeout)
+
+	if err != nil {
+		return ScanResult{}, err
+	}
+
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(scanner.timeout)); err != nil {
+		return ScanResult{}, err
+	}
+
+	if err := clamdStream(conn, reader); err != nil {
+		return ScanResult{}, err
+	}
+
+	response, err := bufio.NewReader(conn).ReadString(0)
+
+	if err != nil && err != io.EOF {
+		return ScanResult{}, err
+	}
+
+	return parseClamdResponse(response)
+}
+
+// clamdStream sends the content in chunks, each prefixed by its length, and terminates the stream with a zero length chunk.
+func clamdStream(w io.Writer, reader io.Reader) error {
+	if _, err := w.Write([]byte("zINSTREAM\x00")); err != nil {
+		return err
+	}
+
+	buffer := make([]byte, clamdChunkSize)
+	length := make([]byte, 4)
+
+	for {
+		n, err := reader.Read(buffer)
+
+		if n > 0 {
+			binary.BigEndian.PutUint32(length, uint32(n))
+
+			if _, err := w.Write(length); err != nil {
+				return err
+			}
+
+			if _, err := w.Write(buffer[:n]); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+	}
+
+	binary.BigEndian.PutUint32(length, 0)
+	_, err := w.Write(length)
+	return err
+}
+
+func parseClamdResponse(response string) (ScanResult, error) {
+	response = strings.TrimSpace(strings.TrimRight(response, "\x00"))
+	result := strings.TrimPrefix(response, clamdResponsePrefix)
+
+	if result == clamdResponseOK {
+		return ScanResult{}, nil
+	} else if strings.HasSuffix(result, clamdResponseFound) {
+		return ScanResult{true, strings.TrimSuffix(result, clamdResponseFound)}, nil
+	} else if strings.HasSuffix(result, clamdResponseError) {
+		return ScanResult{}, errors.New(strings.TrimSuffix(result, clamdResponseError))
+	}
+
+	return ScanResult{}, fmt.Errorf("unexpected clamd response '%s'", response)
+}
@@ -0,0 +1,175 @@
+package content
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const eicar = `X5O!P%@AP[4\PZX54(P^)7CC)7}$EICAR-STANDARD-ANTIVIRUS-TEST-FILE!$H+H*`
+
+func TestNewClamdScanner(t *testing.T) {
+	input := []string{"tcp://localhost:3310", "unix:///var/run/clamd.sock", "localhost:3310", ""}
+	expected := []string{"tcp", "unix", "", ""}
+
+	for i, in := range input {
+		scanner, err := NewClamdScanner(in, 0)
+
+		if expected[i] == "" && err == nil {
+			t.Fatalf("Expected error for address '%s'", in)
+		} else if expected[i] != "" && (err != nil || scanner.network != expected[i] || scanner.timeout != time.Second*defaultScanTimeout) {
+			t.Fatalf("Expected network %s for address '%s', but was: %v %v", expected[i], in, scanner, err)
+		}
+	}
+}
+
+func TestParseClamdResponse(t *testing.T) {
+	input := []string{"stream: OK\x00", "stream: Eicar-Test-Signature FOUND\x00", "INSTREAM size limit exceeded. ERROR\x00", "unknown"}
+	expected := []ScanResult{{}, {true, "Eicar-Test-Signature"}, {}, {}}
+	expectErr := []bool{false, false, true, true}
+
+	for i, in := range input {
+		result, err := parseClamdResponse(in)
+
+		if (err != nil) != expectErr[i] {
+			t.Fatalf("Expected error %v for response '%s', but was: %v", expectErr[i], in, err)
+		}
+
+		if result != expected[i] {
+			t.Fatalf("Expected %v for response '%s', but was: %v", expected[i], in, result)
+		}
+	}
+}
+
+func TestClamdScannerTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+	go serveFakeClamd(t, listener)
+	scanner, err := NewClamdScanner("tcp://"+listener.Addr().String(), time.Second*5)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testClamdScanner(t, scanner)
+}
+
+func TestClamdScannerUnix(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clamd")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "clamd.sock")
+	listener, err := net.Listen("unix", socket)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer listener.Close()
+	go serveFakeClamd(t, listener)
+	scanner, err := NewClamdScanner("unix://"+socket, time.Second*5)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testClamdScanner(t, scanner)
+}
+
+func TestClamdScannerUnavailable(t *testing.T) {
+	scanner, err := NewClamdScanner("tcp://127.0.0.1:1", time.Second)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scanner.Scan(strings.NewReader("content")); err == nil {
+		t.Fatal("Scan must fail if clamd is not available")
+	}
+}
+
+func TestNoopScanner(t *testing.T) {
+	result, err := NoopScanner{}.Scan(strings.NewReader(eicar))
+
+	if err != nil || result.Infected {
+		t.Fatalf("Noop scanner must report file as clean, but was: %v %v", result, err)
+	}
+}
+
+func testClamdScanner(t *testing.T, scanner *ClamdScanner) {
+	result, err := scanner.Scan(strings.NewReader(strings.Repeat("clean content ", clamdChunkSize)))
+
+	if err != nil || result.Infected {
+		t.Fatalf("File must be clean, but was: %v %v", result, err)
+	}
+
+	result, err = scanner.Scan(strings.NewReader(eicar))
+
+	if err != nil || !result.Infected || result.Signature != "Eicar-Test-Signature" {
+		t.Fatalf("File must be infected, but was: %v %v", result, err)
+	}
+}
+
+// serveFakeClamd accepts connections and answers INSTREAM commands, reporting the EICAR test file as infected.
+func serveFakeClamd(t *testing.T, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+
+		if err != nil {
+			return
+		}
+
+		command := make([]byte, len("zINSTREAM\x00"))
+
+		if _, err := io.ReadFull(conn, command); err != nil || string(command) != "zINSTREAM\x00" {
+			t.Errorf("Unexpected command: %s %v", string(command), err)
+			conn.Close()
+			continue
+		}
+
+		var content bytes.Buffer
+		length := make([]byte, 4)
+
+		for {
+			if _, err := io.ReadFull(conn, length); err != nil {
+				t.Errorf("Error reading chunk length: %v", err)
+				break
+			}
+
+			n := binary.BigEndian.Uint32(length)
+
+			if n == 0 {
+				break
+			}
+
+			if _, err := io.CopyN(&content, conn, int64(n)); err != nil {
+				t.Errorf("Error reading chunk: %v", err)
+				break
+			}
+		}
+
+		if strings.Contains(content.String(), "EICAR-STANDARD-ANTIVIRUS-TEST-FILE") {
+			conn.Write([]byte("stream: Eicar-Test-Signature FOUND\x00"))
+		} else {
+			conn.Write([]byte("stream: OK\x00"))
+		}
+
+		conn.Close()
+	}
+}
@@ -0,0 +1,20 @@
+package db
+
+import (
+	"github.com/emvi/logbuch"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	listenerMinReconnectInterval = time.Second
+	listenerMaxReconnectInterval = time.Minute
+)
+
+// NewListener returns a new listener for Postgres notifications using given configuration.
+// The callback is called on connection state changes, like reconnecting after the connection was lost.
+// Channels must be added to the listener by calling Listen.
+func NewListener(data ConnectionData, callback pq.EventCallbackType) *pq.Listener {
+	logbuch.Info("Creating database listener...")
+	return pq.NewListener(postgresConnection(&data), listenerMinReconnectInterval, listenerMaxReconnectInterval, callback)
+}
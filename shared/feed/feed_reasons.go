@@ -146,6 +146,24 @@ var Reasons = map[string]map[string]Reason{
 		"recommendation_confirmation": {
 			Feed: `has read the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> you recommended.`,
 		},
+		"reading_campaign": {
+			Feed: `asked you to read and confirm the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> until {{index .Vars "due_date"}}.`,
+		},
+		"reading_campaign_reconfirm": {
+			Feed: `changed the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a>. Please read and confirm it again until {{index .Vars "due_date"}}.`,
+		},
+		"translation_outdated": {
+			Feed: `changed the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a>. The translation into {{index .Vars "language"}} is outdated now.`,
+		},
+		"file_infected": {
+			Feed: `uploaded the file "{{index .Vars "filename"}}", which was refused, because malware was found ({{index .Vars "signature"}}).`,
+		},
+		"article_translated": {
+			Feed: `translated the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> into {{index .Vars "language"}}. The draft is ready for review.`,
+		},
+		"article_translation_failed": {
+			Feed: `could not translate the article <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> into {{index .Vars "language"}}. Please try again later.`,
+		},
 	},
 	"de": {
 		"joined_organization": {
@@ -282,6 +300,24 @@ var Reasons = map[string]map[string]Reason{
 		"recommendation_confirmation": {
 			Feed: `hat den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> gelesen, den du empfohlen hast.`,
 		},
+		"reading_campaign": {
+			Feed: `bittet dich den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> bis zum {{index .Vars "due_date"}} zu lesen und zu bestätigen.`,
+		},
+		"reading_campaign_reconfirm": {
+			Feed: `hat den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> geändert. Bitte lies und bestätige ihn erneut bis zum {{index .Vars "due_date"}}.`,
+		},
+		"translation_outdated": {
+			Feed: `hat den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> geändert. Die Übersetzung in {{index .Vars "language"}} ist jetzt veraltet.`,
+		},
+		"file_infected": {
+			Feed: `hat die Datei "{{index .Vars "filename"}}" hochgeladen, die abgelehnt wurde, weil Schadsoftware gefunden wurde ({{index .Vars "signature"}}).`,
+		},
+		"article_translated": {
+			Feed: `hat den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> in {{index .Vars "language"}} übersetzt. Der Entwurf kann jetzt geprüft werden.`,
+		},
+		"article_translation_failed": {
+			Feed: `konnte den Artikel <a class="blue-100" href="{{.FrontendHost}}/read/{{SlugWithId (index .Content 0).Title (index .Articles 0).ID}}">{{(index .Content 0).Title}}</a> nicht in {{index .Vars "language"}} übersetzen. Bitte versuche es später erneut.`,
+		},
 	},
 }
 
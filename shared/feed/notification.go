@@ -0,0 +1,133 @@
+package feed
+
+import (
+	"emviwiki/shared/model"
+	"github.com/emvi/logbuch"
+	"time"
+)
+
+const (
+	CategoryMentions        = "mentions"
+	CategoryRecommendations = "recommendations"
+	CategoryLists           = "lists"
+	CategoryGroups          = "groups"
+	CategoryObserved        = "observed"
+	CategoryTranslations    = "translations"
+	CategoryOther           = "other"
+
+	// digests are sent at this hour in the member's time zone, weekly digests on the weekday
+	digestHour    = 8
+	digestWeekday = time.Monday
+)
+
+var (
+	// Categories is a list of all notification categories members can set a delivery for.
+	Categories = []string{CategoryMentions, CategoryRecommendations, CategoryLists, CategoryGroups, CategoryObserved, CategoryTranslations, CategoryOther}
+
+	// NotificationDeliveries is a list of all valid notification deliveries.
+	NotificationDeliveries = []string{model.NotificationDeliveryInstant, model.NotificationDeliveryDaily, model.NotificationDeliveryWeekly, model.NotificationDeliveryApp}
+
+	// reasons not listed here belong to CategoryOther
+	reasonCategories = map[string]string{
+		"mentioned":                           CategoryMentions,
+		"recommend_article":                   CategoryRecommendations,
+		"recommendation_confirmation":         CategoryRecommendations,
+		"invite_article":                      CategoryRecommendations,
+		"invite_new_article":                  CategoryRecommendations,
+		"reading_campaign":                    CategoryRecommendations,
+		"reading_campaign_reconfirm":          CategoryRecommendations,
+		"add_article_list_entry":              CategoryLists,
+		"add_protected_article_list_entry":    CategoryLists,
+		"add_article_list_member":             CategoryLists,
+		"remove_protected_article_list_entry": CategoryLists,
+		"remove_article_list_entry":           CategoryLists,
+		"remove_article_list_member":          CategoryLists,
+		"create_article_list":                 CategoryLists,
+		"update_article_list":                 CategoryLists,
+		"set_article_list_moderator":          CategoryLists,
+		"remove_article_list_moderator":       CategoryLists,
+		"delete_articlelist":                  CategoryLists,
+		"add_user_group_member":               CategoryGroups,
+		"remove_user_group_member":            CategoryGroups,
+		"create_user_group":                   CategoryGroups,
+		"update_user_group":                   CategoryGroups,
+		"set_user_group_moderator":            CategoryGroups,
+		"remove_user_group_moderator":         CategoryGroups,
+		"delete_usergroup":                    CategoryGroups,
+		"create_article":                      CategoryObserved,
+		"update_article":                      CategoryObserved,
+		"reset_article":                       CategoryObserved,
+		"delete_article_history_entry":        CategoryObserved,
+		"archived_article":                    CategoryObserved,
+		"restored_article":                    CategoryObserved,
+		"copy_article":                        CategoryObserved,
+		"delete_article":                      CategoryObserved,
+		"translation_outdated":                CategoryTranslations,
+	}
+)
+
+// ReasonCategory returns the notification category for given feed reason.
+func ReasonCategory(reason string) string {
+	if category, ok := reasonCategories[reason]; ok {
+		return category
+	}
+
+	return CategoryOther
+}
+
+// NotificationDelivery returns how notifications of the category are delivered to the member.
+// Categories without preference fall back to the notification interval and recommendation mail settings of the member.
+func NotificationDelivery(member *model.OrganizationMember, prefs []model.NotificationPreference, category string) string {
+	for _, pref := range prefs {
+		if pref.Category == category {
+			return pref.Delivery
+		}
+	}
+
+	if category == CategoryRecommendations && member.RecommendationMail {
+		return model.NotificationDeliveryInstant
+	}
+
+	switch member.SendNotificationsInterval {
+	case 0:
+		return model.NotificationDeliveryApp
+	case 1:
+		return model.NotificationDeliveryDaily
+	default:
+		return model.NotificationDeliveryWeekly
+	}
+}
+
+// NextDigest returns the next time after t a digest is sent in given time zone.
+func NextDigest(t time.Time, timezone string) time.Time {
+	local := t.In(loadLocation(timezone))
+	next := time.Date(local.Year(), local.Month(), local.Day(), digestHour, 0, 0, 0, local.Location())
+
+	if !next.After(local) {
+		next = next.AddDate(0, 0, 1)
+	}
+
+	return next
+}
+
+// IsWeeklyDigest returns whether the digest scheduled for t in given time zone includes the weekly notifications.
+func IsWeeklyDigest(t time.Time, timezone string) bool {
+	return t.In(loadLocation(timezone)).Weekday() == digestWeekday
+}
+
+// CheckTimezone returns whether the time zone is known.
+func CheckTimezone(timezone string) bool {
+	_, err := time.LoadLocation(timezone)
+	return timezone != "" && timezone != "Local" && err == nil
+}
+
+func loadLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+
+	if err != nil {
+		logbuch.Warn("Error loading time zone, falling back to UTC", logbuch.Fields{"err": err, "timezone": timezone})
+		return time.UTC
+	}
+
+	return loc
+}
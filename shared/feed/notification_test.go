@@ -0,0 +1,105 @@
+package feed
+
+import (
+	"emviwiki/shared/model"
+	"testing"
+	"time"
+)
+
+func TestReasonCategory(t *testing.T) {
+	input := []string{"mentioned", "recommend_article", "add_article_list_entry", "create_user_group", "update_article", "joined_organization", "unknown"}
+	expected := []string{CategoryMentions, CategoryRecommendations, CategoryLists, CategoryGroups, CategoryObserved, CategoryOther, CategoryOther}
+
+	for i, reason := range input {
+		if category := ReasonCategory(reason); category != expected[i] {
+			t.Fatalf("Expected category %v for reason %v, but was: %v", expected[i], reason, category)
+		}
+	}
+}
+
+func TestReasonCategoryReasonsExist(t *testing.T) {
+	for reason := range reasonCategories {
+		if !CheckReasonExists(reason) {
+			t.Fatalf("Reason %v must exist", reason)
+		}
+	}
+}
+
+func TestNotificationDelivery(t *testing.T) {
+	member := &model.OrganizationMember{SendNotificationsInterval: 1, RecommendationMail: true}
+	prefs := []model.NotificationPreference{{Category: CategoryMentions, Delivery: model.NotificationDeliveryInstant}}
+	input := []string{CategoryMentions, CategoryRecommendations, CategoryLists}
+	expected := []string{model.NotificationDeliveryInstant, model.NotificationDeliveryInstant, model.NotificationDeliveryDaily}
+
+	for i, category := range input {
+		if delivery := NotificationDelivery(member, prefs, category); delivery != expected[i] {
+			t.Fatalf("Expected delivery %v for category %v, but was: %v", expected[i], category, delivery)
+		}
+	}
+
+	member.RecommendationMail = false
+	member.SendNotificationsInterval = 0
+
+	if delivery := NotificationDelivery(member, nil, CategoryRecommendations); delivery != model.NotificationDeliveryApp {
+		t.Fatalf("Notifications must be in-app only, but was: %v", delivery)
+	}
+
+	member.SendNotificationsInterval = 30
+
+	if delivery := NotificationDelivery(member, nil, CategoryOther); delivery != model.NotificationDeliveryWeekly {
+		t.Fatalf("Notifications must be sent weekly, but was: %v", delivery)
+	}
+}
+
+func TestNextDigest(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := []time.Time{
+		time.Date(2020, 6, 1, 5, 0, 0, 0, time.UTC),
+		time.Date(2020, 6, 1, 6, 0, 0, 0, time.UTC),
+		time.Date(2020, 6, 1, 23, 30, 0, 0, time.UTC),
+	}
+	expected := []time.Time{
+		time.Date(2020, 6, 1, 8, 0, 0, 0, berlin),
+		time.Date(2020, 6, 2, 8, 0, 0, 0, berlin),
+		time.Date(2020, 6, 2, 8, 0, 0, 0, berlin), // already the next day in Berlin
+	}
+
+	for i, in := range input {
+		if next := NextDigest(in, "Europe/Berlin"); !next.Equal(expected[i]) {
+			t.Fatalf("Expected next digest %v for %v, but was: %v", expected[i], in, next)
+		}
+	}
+
+	if next := NextDigest(input[0], "unknown"); !next.Equal(time.Date(2020, 6, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Unknown time zone must fall back to UTC, but was: %v", next)
+	}
+}
+
+func TestIsWeeklyDigest(t *testing.T) {
+	// Monday 04:00 in Berlin, but Sunday in New York
+	monday := time.Date(2020, 6, 1, 2, 0, 0, 0, time.UTC)
+
+	if !IsWeeklyDigest(monday, "Europe/Berlin") {
+		t.Fatal("Digest must be weekly")
+	}
+
+	if IsWeeklyDigest(monday, "America/New_York") {
+		t.Fatal("Digest must not be weekly")
+	}
+}
+
+func TestCheckTimezone(t *testing.T) {
+	input := []string{"", "Local", "Europe/Nowhere", "UTC", "Europe/Berlin"}
+	expected := []bool{false, false, false, true, true}
+
+	for i, tz := range input {
+		if CheckTimezone(tz) != expected[i] {
+			t.Fatalf("Expected %v for time zone %v", expected[i], tz)
+		}
+	}
+}
@@ -20,6 +20,24 @@ var (
 	feedTemplatesMutex sync.RWMutex
 )
 
+// RenderFeedEntries renders the feed and notification texts of all entries in given language.
+// The notification text falls back to the feed text if the reason has none.
+func RenderFeedEntries(orga *model.Organization, langCode string, feed []model.Feed) {
+	for i := range feed {
+		reason, ok := Reasons[langCode][feed[i].Reason]
+
+		if ok {
+			feed[i].Feed = RenderFeed(orga, reason.Feed, FeedText, langCode, &feed[i])
+
+			if feed[i].Notification == "" {
+				feed[i].Notification = feed[i].Feed
+			} else {
+				feed[i].Notification = RenderFeed(orga, reason.Notification, NotificationText, langCode, &feed[i])
+			}
+		}
+	}
+}
+
 // RenderFeed renders given text and returns it as string.
 // The type is used to distinguish feed and notification texts.
 func RenderFeed(orga *model.Organization, text, textType, langCode string, feed *model.Feed) string {
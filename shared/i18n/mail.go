@@ -18,6 +18,8 @@ var (
 			"recommend_article":                      "You've got an article recommendation on Emvi",
 			"invite_article":                         "You've got an invitation to edit an article on Emvi",
 			"mail_notifications":                     "Your unread notifications on Emvi",
+			"mail_notification":                      "You've got a new notification on Emvi",
+			"reading_campaign":                       "Please confirm you have read an article on Emvi",
 			"newsletter_confirmation_mail":           "Your newsletter subscription at Emvi",
 			"newsletter_onpremise_confirmation_mail": "Your newsletter subscription at Emvi",
 			"subscription":                           "Your subscription at Emvi",
@@ -26,6 +28,7 @@ var (
 			"resume_subscription":                    "Your subscription has been resumed",
 			"cancel_subscription":                    "Your subscription has been cancelled",
 			"payment_action_required":                "Your subscription at Emvi requires you to take action",
+			"storage_limit":                          "Your organization on Emvi is running out of storage",
 		},
 		"de": {
 			"password_mail":                          "Dein Passwort bei Emvi wurde zurückgesetzt",
@@ -38,6 +41,8 @@ var (
 			"recommend_article":                      "Du hast einen Lesevorschlag auf Emvi erhalten",
 			"invite_article":                         "Du hast eine Einladung einen Artikel auf Emvi zu bearbeiten",
 			"mail_notifications":                     "Deine ungelesenen Benachrichtigungen auf Emvi",
+			"mail_notification":                      "Du hast eine neue Benachrichtigung auf Emvi",
+			"reading_campaign":                       "Bitte bestätige, dass du einen Artikel auf Emvi gelesen hast",
 			"newsletter_confirmation_mail":           "Dein Newsletter Abo bei Emvi",
 			"newsletter_onpremise_confirmation_mail": "Dein Newsletter Abo bei Emvi",
 			"subscription":                           "Dein Abonnement bei Emvi",
@@ -46,6 +51,7 @@ var (
 			"resume_subscription":                    "Dein Abonnement wird fortgesetzt",
 			"cancel_subscription":                    "Dein Abonnement wurde beendet",
 			"payment_action_required":                "Dein Abonnement bei Emvi erfordet deine Aufmerksamkeit",
+			"storage_limit":                          "Deiner Organisation auf Emvi geht der Speicherplatz aus",
 		},
 	}
 
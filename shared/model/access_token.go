@@ -0,0 +1,138 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// AccessToken is a personal access token of an organization member to access the API as this user.
+// Only the hash of the token is stored, the token itself is shown once when it is created.
+type AccessToken struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID   `db:"organization_id" json:"-"`
+	UserId         hide.ID   `db:"user_id" json:"user_id"`
+	Name           string    `json:"name"`
+	TokenHash      string    `db:"token_hash" json:"-"`
+	Expires        time.Time `json:"expires"`
+	LastUsed       null.Time `db:"last_used" json:"last_used"`
+
+	Scopes []AccessTokenScope `json:"scopes" db:"-"`
+}
+
+func GetAccessTokenByOrganizationIdAndId(orgaId, id hide.ID) *AccessToken {
+	entity := new(AccessToken)
+
+	if err := connection.Get(entity, `SELECT * FROM "access_token" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Access token by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func GetAccessTokenByOrganizationIdAndUserIdAndName(orgaId, userId hide.ID, name string) *AccessToken {
+	entity := new(AccessToken)
+	query := `SELECT * FROM "access_token" WHERE organization_id = $1 AND user_id = $2 AND LOWER(name) = LOWER($3)`
+
+	if err := connection.Get(entity, query, orgaId, userId, name); err != nil {
+		logbuch.Debug("Access token by organization id and user id and name not found", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "name": name})
+		return nil
+	}
+
+	return entity
+}
+
+func GetAccessTokenByTokenHash(hash string) *AccessToken {
+	entity := new(AccessToken)
+
+	if err := connection.Get(entity, `SELECT * FROM "access_token" WHERE token_hash = $1`, hash); err != nil {
+		logbuch.Debug("Access token by token hash not found", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entity
+}
+
+func FindAccessTokenByOrganizationIdAndUserId(orgaId, userId hide.ID) []AccessToken {
+	query := `SELECT * FROM "access_token" WHERE organization_id = $1 AND user_id = $2 ORDER BY def_time DESC`
+	var entities []AccessToken
+
+	if err := connection.Select(&entities, query, orgaId, userId); err != nil {
+		logbuch.Error("Error reading access tokens by organization id and user id", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entities
+}
+
+func UpdateAccessTokenLastUsedById(id hide.ID) error {
+	query := `UPDATE "access_token" SET last_used = NOW() WHERE id = $1`
+
+	if _, err := connection.DB.Exec(query, id); err != nil {
+		logbuch.Error("Error updating access token last used by id", logbuch.Fields{"err": err, "id": id})
+		return err
+	}
+
+	return nil
+}
+
+func DeleteAccessTokenById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token_scope" WHERE access_token_id = $1`, id); err != nil {
+		logbuch.Error("Error deleting access token scope by access token id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting access token by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func DeleteAccessTokenByOrganizationIdAndUserId(tx *sqlx.Tx, orgaId, userId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token_scope"
+		WHERE access_token_id IN (SELECT id FROM "access_token" WHERE organization_id = $1 AND user_id = $2)`, orgaId, userId); err != nil {
+		logbuch.Error("Error deleting access token scope by organization id and user id", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token" WHERE organization_id = $1 AND user_id = $2`, orgaId, userId); err != nil {
+		logbuch.Error("Error deleting access token by organization id and user id", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveAccessToken(tx *sqlx.Tx, entity *AccessToken) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "access_token" (organization_id, user_id, name, token_hash, expires, last_used)
+			VALUES (:organization_id, :user_id, :name, :token_hash, :expires, :last_used) RETURNING id`,
+		`UPDATE "access_token" SET organization_id = :organization_id,
+			user_id = :user_id,
+			name = :name,
+			token_hash = :token_hash,
+			expires = :expires,
+			last_used = :last_used
+			WHERE id = :id`)
+}
@@ -0,0 +1,40 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+type AccessTokenScope struct {
+	db.BaseEntity
+
+	AccessTokenId hide.ID `db:"access_token_id" json:"access_token_id"`
+	Name          string  `json:"name"`
+	Read          bool    `json:"read"`
+	Write         bool    `json:"write"`
+}
+
+func FindAccessTokenScopeByAccessTokenId(accessTokenId hide.ID) []AccessTokenScope {
+	query := `SELECT * FROM "access_token_scope" WHERE access_token_id = $1`
+	var entities []AccessTokenScope
+
+	if err := connection.Select(&entities, query, accessTokenId); err != nil {
+		logbuch.Error("Error reading access token scopes by access token id", logbuch.Fields{"err": err, "access_token_id": accessTokenId})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveAccessTokenScope(tx *sqlx.Tx, entity *AccessTokenScope) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "access_token_scope" (access_token_id, name, read, write)
+			VALUES (:access_token_id, :name, :read, :write) RETURNING id`,
+		`UPDATE "access_token_scope" SET access_token_id = :access_token_id,
+			name = :name,
+			read = :read,
+			write = :write
+			WHERE id = :id`)
+}
@@ -54,10 +54,11 @@ type Article struct {
 	Published      null.Time   `json:"published"`
 	Pinned         bool        `json:"pinned"`
 
-	LatestArticleContent *ArticleContent `db:"latest_article_content" json:"latest_article_content"`
-	Access               []ArticleAccess `db:"-" json:"access"`
-	Tags                 []Tag           `db:"-" json:"tags"`
-	PreviewImage         string          `json:"preview_image"`
+	LatestArticleContent *ArticleContent      `db:"latest_article_content" json:"latest_article_content"`
+	Access               []ArticleAccess      `db:"-" json:"access"`
+	Tags                 []Tag                `db:"-" json:"tags"`
+	CustomFields         []ArticleCustomField `db:"-" json:"custom_fields"`
+	PreviewImage         string               `json:"preview_image"`
 
 	Rank float32 `db:"rank" json:"-"`
 }
@@ -93,6 +94,18 @@ func GetArticleByOrganizationIdAndIdTx(tx *sqlx.Tx, orgaId, id hide.ID) *Article
 	return entity
 }
 
+// GetArticleByOrganizationIdAndIdForUpdateTx reads and locks the article until the transaction is finished.
+func GetArticleByOrganizationIdAndIdForUpdateTx(tx *sqlx.Tx, orgaId, id hide.ID) *Article {
+	entity := new(Article)
+
+	if err := tx.Get(entity, `SELECT * FROM "article" WHERE organization_id = $1 AND id = $2 AND archived IS NULL FOR UPDATE`, orgaId, id); err != nil {
+		logbuch.Debug("Article by organization id and id for update not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
 func GetArticleByOrganizationIdAndIdIgnoreArchived(orgaId, id hide.ID) *Article {
 	return GetArticleByOrganizationIdAndIdIgnoreArchivedTx(nil, orgaId, id)
 }
@@ -299,7 +312,7 @@ func buildArticleByOrganizationIdAndUserIdAndLanguageIdAndQueryAndFilterLimitQue
 	if count {
 		sb.WriteString(`SELECT COUNT(DISTINCT(id)) FROM (SELECT "article".id `)
 	} else {
-		sb.WriteString(`SELECT id, organization_id, views, wip, read_everyone, write_everyone, private, client_access, archived, published, def_time, mod_time,
+		sb.WriteString(`SELECT "result_set".id, organization_id, views, wip, read_everyone, write_everyone, private, client_access, archived, published, "result_set".def_time, "result_set".mod_time,
 		article_content_id "latest_article_content.id",
 		title "latest_article_content.title",
 		version "latest_article_content.version",
@@ -412,6 +425,12 @@ func buildArticleByOrganizationIdAndUserIdAndLanguageIdAndQueryAndFilterLimitQue
 		sb.WriteString(fmt.Sprintf(`AND "article_access".user_group_id %v`, userGroupFilter))
 	}
 
+	for _, customField := range filter.CustomFields {
+		var customFieldFilter string
+		customFieldFilter, index, params = buildCustomFieldFilterQuery(customField, index, params)
+		sb.WriteString(customFieldFilter)
+	}
+
 	// add date filter
 	dateFilter, index, params := filter.addDateFilter("article", index, params)
 	sb.WriteString(dateFilter)
@@ -442,13 +461,26 @@ func buildArticleByOrganizationIdAndUserIdAndLanguageIdAndQueryAndFilterLimitQue
 
 		// close distinct select
 		sb.WriteString(") AS result_set ")
+		sortFields := []SortValue{{`"result_set".title`, filter.SortTitle}, {`"result_set".published`, filter.SortPublished}, {`"result_set"."article_content_mod_time"`, filter.SortUpdated}}
+
+		// join custom field to sort by, articles without value come last
+		if filter.SortCustomFieldId != 0 && filter.SortCustomField != "" {
+			params = append(params, filter.SortCustomFieldId)
+			sb.WriteString(fmt.Sprintf(`LEFT JOIN "article_custom_field" sort_field ON sort_field.article_id = "result_set".id AND sort_field.custom_field_id = $%v `, index))
+			index++
+			sortFields = append(sortFields, SortValue{`sort_field.id IS NULL`, sortDirectionASC},
+				SortValue{`sort_field.value_number`, filter.SortCustomField},
+				SortValue{`sort_field.value_date`, filter.SortCustomField},
+				SortValue{`LOWER(sort_field.value_text)`, filter.SortCustomField})
+		}
 
 		// sorting
 		if filter.SortPublished != "" ||
 			filter.SortCreated != "" ||
 			filter.SortUpdated != "" ||
-			filter.SortTitle != "" {
-			sb.WriteString(filter.addSorting("result_set", nil, SortValue{`"result_set".title`, filter.SortTitle}, SortValue{`"result_set".published`, filter.SortPublished}, SortValue{`"result_set"."article_content_mod_time"`, filter.SortUpdated}))
+			filter.SortTitle != "" ||
+			filter.SortCustomField != "" {
+			sb.WriteString(filter.addSorting("result_set", nil, sortFields...))
 		} else {
 			rankDirection := sortDirectionDESC
 
@@ -457,7 +489,7 @@ func buildArticleByOrganizationIdAndUserIdAndLanguageIdAndQueryAndFilterLimitQue
 			}
 
 			defaultFields := []SortValue{{"rank", rankDirection}}
-			sb.WriteString(filter.addSorting("result_set", defaultFields, SortValue{`"result_set".title`, filter.SortTitle}, SortValue{`"result_set".published`, filter.SortPublished}, SortValue{`"result_set"."article_content_mod_time"`, filter.SortUpdated}))
+			sb.WriteString(filter.addSorting("result_set", defaultFields, sortFields...))
 		}
 
 		// set limit
@@ -501,6 +533,39 @@ func DeleteArticleById(tx *sqlx.Tx, id hide.ID) error {
 		return err
 	}
 
+	_, err = tx.Exec(`DELETE FROM "article_view" WHERE article_id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting article view by article id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM "article_search_query" WHERE article_id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting article search query by article id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM "reading_campaign_member"
+		WHERE reading_campaign_id IN (SELECT id FROM "reading_campaign" WHERE article_id = $1)`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting reading campaign member by article id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM "reading_campaign" WHERE article_id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting reading campaign by article id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
 	_, err = tx.Exec(`DELETE FROM "article_access" WHERE article_id = $1`, id)
 
 	if err != nil {
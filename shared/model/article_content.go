@@ -9,7 +9,7 @@ import (
 )
 
 const (
-	articleContentWithoutContentQuery = `SELECT id, article_id, language_id, user_id, title, version, commit, wip, reading_time, schema_version, def_time, mod_time FROM "article_content" `
+	articleContentWithoutContentQuery = `SELECT id, article_id, language_id, user_id, title, version, commit, wip, reading_time, schema_version, rtl, source_version, def_time, mod_time FROM "article_content" `
 )
 
 type ArticleContent struct {
@@ -24,7 +24,8 @@ type ArticleContent struct {
 	TitleTsvector   string      `db:"title_tsvector" json:"-"`
 	ReadingTime     int         `db:"reading_time" json:"reading_time"` // seconds
 	SchemaVersion   int         `db:"schema_version" json:"-"`
-	RTL             bool        `json:"rtl"` // right to left
+	RTL             bool        `json:"rtl"`                                // right to left
+	SourceVersion   null.Int64  `db:"source_version" json:"source_version"` // version of the default language this translation is based on
 
 	ArticleId  hide.ID `db:"article_id" json:"article_id"`
 	LanguageId hide.ID `db:"language_id" json:"language_id"`
@@ -215,6 +216,17 @@ func FindArticleContentByArticleIdTx(tx *sqlx.Tx, articleId hide.ID) []ArticleCo
 	return entities
 }
 
+func FindArticleContentByIdGreaterThanLimit(id hide.ID, n int) []ArticleContent {
+	var entities []ArticleContent
+
+	if err := connection.Select(&entities, `SELECT * FROM "article_content" WHERE id > $1 ORDER BY id ASC LIMIT $2`, id, n); err != nil {
+		logbuch.Error("Error finding article content by id greater than", logbuch.Fields{"err": err, "id": id})
+		return nil
+	}
+
+	return entities
+}
+
 func FindArticleContentIdByArticleIdAndWIPTx(tx *sqlx.Tx, articleId hide.ID) []hide.ID {
 	if tx == nil {
 		tx, _ = connection.Beginx()
@@ -262,6 +274,7 @@ func FindArticleContentVersionCommitByOrganizationIdAndArticleIdAndLanguageIdAnd
 		"user".language "user.language",
 		"user".info "user.info",
 		"user".picture "user.picture",
+		"user".bot "user.bot",
 		"organization_member".id "user.organization_member.id",
 		"organization_member".username "user.organization_member.username",
 		"organization_member".info "user.organization_member.info",
@@ -334,6 +347,25 @@ func DeleteArticleContentByArticleIdAndWIP(tx *sqlx.Tx, articleId hide.ID) error
 	return nil
 }
 
+// ResetArticleContentSourceVersionByOrganizationIdTx removes the source version from all article content of the organization.
+// This must be called when the default language changes, as the source versions refer to the previous one.
+func ResetArticleContentSourceVersionByOrganizationIdTx(tx *sqlx.Tx, orgaId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := connection.Exec(tx, `UPDATE "article_content" SET source_version = NULL
+		WHERE article_id IN (SELECT id FROM "article" WHERE organization_id = $1)
+		AND source_version IS NOT NULL`, orgaId); err != nil {
+		logbuch.Error("Error resetting article content source version by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
 func SaveArticleContent(tx *sqlx.Tx, entity *ArticleContent) error {
 	return connection.SaveEntity(tx, entity,
 		`INSERT INTO "article_content" (title,
@@ -348,7 +380,8 @@ func SaveArticleContent(tx *sqlx.Tx, entity *ArticleContent) error {
 			user_id,
 			reading_time,
 			schema_version,
-			rtl)
+			rtl,
+			source_version)
 			VALUES (:title,
 			:content,
 			:version,
@@ -361,7 +394,8 @@ func SaveArticleContent(tx *sqlx.Tx, entity *ArticleContent) error {
 			:user_id,
 			:reading_time,
 			:schema_version,
-			:rtl)
+			:rtl,
+			:source_version)
 			RETURNING id`,
 		`UPDATE "article_content" SET title = :title,
 			content = :content,
@@ -375,6 +409,7 @@ func SaveArticleContent(tx *sqlx.Tx, entity *ArticleContent) error {
 			user_id = :user_id,
 			reading_time = :reading_time,
 			schema_version = :schema_version,
-			rtl = :rtl
+			rtl = :rtl,
+			source_version = :source_version
 			WHERE id = :id`)
 }
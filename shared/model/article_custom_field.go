@@ -0,0 +1,137 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"strings"
+)
+
+var (
+	customFieldValueColumns = map[string]string{
+		CustomFieldText:   "value_text",
+		CustomFieldNumber: "value_number",
+		CustomFieldDate:   "value_date",
+		CustomFieldEnum:   "value_text",
+		CustomFieldUser:   "value_user_id",
+	}
+)
+
+// ArticleCustomField is the value of a custom field for an article.
+// Only the value matching the type of the field is set. Text and enum fields share the text value.
+type ArticleCustomField struct {
+	db.BaseEntity
+
+	ArticleId     hide.ID      `db:"article_id" json:"article_id"`
+	CustomFieldId hide.ID      `db:"custom_field_id" json:"custom_field_id"`
+	Text          null.String  `db:"value_text" json:"text"`
+	Number        null.Float64 `db:"value_number" json:"number"`
+	Date          null.Time    `db:"value_date" json:"date"`
+	UserId        hide.ID      `db:"value_user_id" json:"user_id"`
+
+	// joined from the custom field
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+func FindArticleCustomFieldByArticleId(articleId hide.ID) []ArticleCustomField {
+	return FindArticleCustomFieldByArticleIdTx(nil, articleId)
+}
+
+func FindArticleCustomFieldByArticleIdTx(tx *sqlx.Tx, articleId hide.ID) []ArticleCustomField {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	query := `SELECT "article_custom_field".*, "custom_field".name, "custom_field".type FROM "article_custom_field"
+		JOIN "custom_field" ON "article_custom_field".custom_field_id = "custom_field".id
+		WHERE "article_custom_field".article_id = $1
+		ORDER BY "custom_field".name`
+	var entities []ArticleCustomField
+
+	if err := tx.Select(&entities, query, articleId); err != nil {
+		logbuch.Error("Error reading article custom fields by article id", logbuch.Fields{"err": err, "article_id": articleId})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteArticleCustomFieldByArticleId(tx *sqlx.Tx, articleId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "article_custom_field" WHERE article_id = $1`, articleId); err != nil {
+		logbuch.Error("Error deleting article custom fields by article id", logbuch.Fields{"err": err, "article_id": articleId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveArticleCustomField(tx *sqlx.Tx, entity *ArticleCustomField) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "article_custom_field" (article_id, custom_field_id, value_text, value_number, value_date, value_user_id)
+			VALUES (:article_id, :custom_field_id, :value_text, :value_number, :value_date, :value_user_id) RETURNING id`,
+		`UPDATE "article_custom_field" SET article_id = :article_id,
+			custom_field_id = :custom_field_id,
+			value_text = :value_text,
+			value_number = :value_number,
+			value_date = :value_date,
+			value_user_id = :value_user_id
+			WHERE id = :id`)
+}
+
+// Returns the condition for articles having a custom field value matching the filter.
+// Text is matched fuzzy, all other values must be equal. Min and max are inclusive.
+// The values must have been validated for the type of the field.
+func buildCustomFieldFilterQuery(filter CustomFieldFilter, index int, params []interface{}) (string, int, []interface{}) {
+	column, ok := customFieldValueColumns[filter.Type]
+
+	if !ok {
+		return "", index, params
+	}
+
+	conditions := []string{fmt.Sprintf(`v.custom_field_id = $%d`, index)}
+	params = append(params, filter.CustomFieldId)
+	index++
+
+	if filter.Value != "" {
+		if filter.Type == CustomFieldText {
+			conditions = append(conditions, fmt.Sprintf(`(SIMILARITY(v.value_text, $%d) > 0.2 OR LOWER(v.value_text) LIKE LOWER('%%'||$%d||'%%'))`, index, index))
+			params = append(params, filter.Value)
+		} else if filter.Type == CustomFieldUser {
+			id, _ := hide.FromString(filter.Value)
+			conditions = append(conditions, fmt.Sprintf(`v.%s = $%d`, column, index))
+			params = append(params, id)
+		} else {
+			conditions = append(conditions, fmt.Sprintf(`v.%s = $%d`, column, index))
+			params = append(params, filter.Value)
+		}
+
+		index++
+	}
+
+	if filter.Type == CustomFieldNumber || filter.Type == CustomFieldDate {
+		if filter.Min != "" {
+			conditions = append(conditions, fmt.Sprintf(`v.%s >= $%d`, column, index))
+			params = append(params, filter.Min)
+			index++
+		}
+
+		if filter.Max != "" {
+			conditions = append(conditions, fmt.Sprintf(`v.%s <= $%d`, column, index))
+			params = append(params, filter.Max)
+			index++
+		}
+	}
+
+	return fmt.Sprintf(`AND EXISTS (SELECT 1 FROM "article_custom_field" v WHERE v.article_id = "article".id AND %s) `, strings.Join(conditions, " AND ")), index, params
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// ArticleSearchQuery is a search query that led a user to an article.
+type ArticleSearchQuery struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	ArticleId      hide.ID `db:"article_id" json:"article_id"`
+	UserId         hide.ID `db:"user_id" json:"user_id"`
+	Query          string  `json:"query"`
+}
+
+// ArticleSearchQueryStatistic is the number of times a (normalized) search query was used.
+type ArticleSearchQueryStatistic struct {
+	Query string `json:"query"`
+	Count int    `json:"count"`
+}
+
+func FindArticleSearchQueryStatisticByArticleIdAndDefTimeAfterLimit(articleId hide.ID, defTime time.Time, n int) []ArticleSearchQueryStatistic {
+	query := `SELECT LOWER(query) "query", COUNT(1) "count"
+		FROM "article_search_query"
+		WHERE article_id = $1
+		AND def_time > $2
+		GROUP BY LOWER(query)
+		ORDER BY "count" DESC, "query" ASC
+		LIMIT $3`
+	var entities []ArticleSearchQueryStatistic
+
+	if err := connection.Select(&entities, query, articleId, defTime, n); err != nil {
+		logbuch.Error("Error reading article search query statistic by article id and def time after", logbuch.Fields{"err": err, "article_id": articleId, "def_time": defTime, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+func FindArticleSearchQueryStatisticByOrganizationIdAndDefTimeAfterLimit(orgaId hide.ID, defTime time.Time, n int) []ArticleSearchQueryStatistic {
+	query := `SELECT LOWER(query) "query", COUNT(1) "count"
+		FROM "article_search_query"
+		WHERE organization_id = $1
+		AND def_time > $2
+		GROUP BY LOWER(query)
+		ORDER BY "count" DESC, "query" ASC
+		LIMIT $3`
+	var entities []ArticleSearchQueryStatistic
+
+	if err := connection.Select(&entities, query, orgaId, defTime, n); err != nil {
+		logbuch.Error("Error reading article search query statistic by organization id and def time after", logbuch.Fields{"err": err, "orga_id": orgaId, "def_time": defTime, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveArticleSearchQuery(tx *sqlx.Tx, entity *ArticleSearchQuery) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "article_search_query" (organization_id, article_id, user_id, query)
+			VALUES (:organization_id, :article_id, :user_id, :query)
+			RETURNING id`,
+		`UPDATE "article_search_query" SET organization_id = :organization_id,
+			article_id = :article_id,
+			user_id = :user_id,
+			query = :query
+			WHERE id = :id`)
+}
@@ -0,0 +1,96 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// ArticleView is a single (unmerged) view of an article by a user.
+// Views are appended to the article_view table on read and merged into article_visit and the article views periodically.
+type ArticleView struct {
+	db.BaseEntity
+
+	ArticleId  hide.ID `db:"article_id" json:"article_id"`
+	UserId     hide.ID `db:"user_id" json:"user_id"`
+	LanguageId hide.ID `db:"language_id" json:"language_id"`
+	Version    int     `json:"version"`
+}
+
+func CountArticleView() int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(1) FROM "article_view"`); err != nil {
+		logbuch.Error("Error counting article views", logbuch.Fields{"err": err})
+		return 0
+	}
+
+	return count
+}
+
+// MergeArticleViewTx merges all views into the article visits and article view counters and deletes them afterwards.
+// A visit is counted once per article, user and day. The merge is executed as a single statement,
+// so that all parts operate on the same snapshot and views saved in the meantime are kept for the next run.
+func MergeArticleViewTx(tx *sqlx.Tx) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	query := `WITH merged AS (
+			DELETE FROM "article_view" RETURNING *
+		), aggregation AS (
+			SELECT article_id,
+			user_id,
+			date(def_time) "day",
+			MIN(def_time) "def_time",
+			(array_agg(language_id ORDER BY id DESC))[1] "language_id",
+			MAX(version) "version",
+			COUNT(1) "views"
+			FROM merged
+			GROUP BY article_id, user_id, date(def_time)
+		), new_visits AS (
+			SELECT * FROM aggregation
+			WHERE NOT EXISTS (SELECT 1 FROM "article_visit"
+				WHERE "article_visit".article_id = aggregation.article_id
+				AND "article_visit".user_id = aggregation.user_id
+				AND date("article_visit".def_time) = aggregation.day)
+		), updated_articles AS (
+			UPDATE "article" SET views = "article".views + visits.count
+			FROM (SELECT article_id, COUNT(1) "count" FROM new_visits GROUP BY article_id) AS visits
+			WHERE "article".id = visits.article_id
+			RETURNING "article".id
+		), updated_visits AS (
+			UPDATE "article_visit" SET views = "article_visit".views + aggregation.views,
+			language_id = COALESCE(aggregation.language_id, "article_visit".language_id),
+			version = GREATEST("article_visit".version, aggregation.version)
+			FROM aggregation
+			WHERE "article_visit".article_id = aggregation.article_id
+			AND "article_visit".user_id = aggregation.user_id
+			AND date("article_visit".def_time) = aggregation.day
+			RETURNING "article_visit".id
+		)
+		INSERT INTO "article_visit" (article_id, user_id, language_id, version, views, def_time)
+		SELECT article_id, user_id, language_id, version, views, def_time FROM new_visits`
+
+	if _, err := tx.Exec(query); err != nil {
+		logbuch.Error("Error merging article views", logbuch.Fields{"err": err})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveArticleView(tx *sqlx.Tx, entity *ArticleView) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "article_view" (article_id, user_id, language_id, version)
+			VALUES (:article_id, :user_id, :language_id, :version)
+			RETURNING id`,
+		`UPDATE "article_view" SET article_id = :article_id,
+			user_id = :user_id,
+			language_id = :language_id,
+			version = :version
+			WHERE id = :id`)
+}
@@ -8,11 +8,75 @@ import (
 	"time"
 )
 
+const (
+	// selects the latest read version for each article the user has visited
+	// and the last published version of the article in the same language
+	articleVisitChangeQuery = `FROM (SELECT DISTINCT ON (article_id) article_id, language_id, version
+			FROM "article_visit"
+			WHERE user_id = $2
+			AND language_id IS NOT NULL
+			AND version != 0
+			ORDER BY article_id, version DESC) AS visits
+		JOIN "article" ON visits.article_id = "article".id
+		JOIN "article_content" ON "article".id = "article_content".article_id AND "article_content".language_id = visits.language_id AND "article_content".version = 0
+		JOIN LATERAL (SELECT version, user_id FROM "article_content"
+			WHERE article_id = visits.article_id
+			AND language_id = visits.language_id
+			AND version != 0
+			AND wip IS FALSE
+			ORDER BY version DESC
+			LIMIT 1) AS last_content ON TRUE
+		WHERE "article".organization_id = $1
+		AND "article".archived IS NULL
+		AND last_content.version > visits.version
+		AND last_content.user_id != $2
+		AND ("article".read_everyone IS TRUE
+			OR "article".write_everyone IS TRUE
+			OR EXISTS (SELECT 1 FROM "article_access"
+				LEFT JOIN "user_group_member" ON "article_access".user_group_id = "user_group_member".user_group_id
+				WHERE "article_access".article_id = "article".id
+				AND ("article_access".user_id = $2 OR "user_group_member".user_id = $2))) `
+)
+
 type ArticleVisit struct {
 	db.BaseEntity
 
+	ArticleId  hide.ID `db:"article_id" json:"article_id"`
+	UserId     hide.ID `db:"user_id" json:"user_id"`
+	LanguageId hide.ID `db:"language_id" json:"language_id"` // optional language of the content that was read
+	Version    int     `json:"version"`                      // version of the content that was read last
+	Views      int     `json:"views"`                        // number of times the article was opened by the user within a day
+}
+
+// ArticleVisitStatistic is the number of views and unique readers for one day.
+type ArticleVisitStatistic struct {
+	Date    time.Time `json:"date"`
+	Views   int       `json:"views"`
+	Readers int       `json:"readers"`
+}
+
+// ArticleVisitUserGroupStatistic is the number of unique readers that are member of a user group.
+type ArticleVisitUserGroupStatistic struct {
+	UserGroupId hide.ID `db:"user_group_id" json:"user_group_id"`
+	Name        string  `json:"name"`
+	Readers     int     `json:"readers"`
+}
+
+// ArticleVisitArticleStatistic is the number of views and unique readers for one article.
+type ArticleVisitArticleStatistic struct {
 	ArticleId hide.ID `db:"article_id" json:"article_id"`
-	UserId    hide.ID `db:"user_id" json:"user_id"`
+	Title     string  `json:"title"`
+	Views     int     `json:"views"`
+	Readers   int     `json:"readers"`
+}
+
+// ArticleVisitChange is an article that has been changed since the user read it last.
+type ArticleVisitChange struct {
+	ArticleId          hide.ID `db:"article_id" json:"article_id"`
+	LanguageId         hide.ID `db:"language_id" json:"language_id"`
+	Title              string  `json:"title"`
+	LastVisitedVersion int     `db:"last_visited_version" json:"last_visited_version"`
+	Version            int     `json:"version"`
 }
 
 func GetArticleVisitByArticleIdAndUserIdAndDefTimeAfter(articleId, userId hide.ID, defTime time.Time) *ArticleVisit {
@@ -26,12 +90,176 @@ func GetArticleVisitByArticleIdAndUserIdAndDefTimeAfter(articleId, userId hide.I
 	return entity
 }
 
+func FindArticleVisitStatisticByArticleIdAndDefTimeAfter(articleId hide.ID, defTime time.Time) []ArticleVisitStatistic {
+	query := `SELECT date("date"),
+		(SELECT COALESCE(SUM(views), 0) FROM "article_visit" WHERE article_id = $1 AND date(def_time) = "date") "views",
+		(SELECT COUNT(DISTINCT user_id) FROM "article_visit" WHERE article_id = $1 AND date(def_time) = "date") "readers"
+		FROM (SELECT * FROM generate_series(date($2), date(now()), interval '1 day') "date") AS date_series
+		ORDER BY "date" ASC`
+	var entities []ArticleVisitStatistic
+
+	if err := connection.Select(&entities, query, articleId, defTime); err != nil {
+		logbuch.Error("Error reading article visit statistic by article id and def time after", logbuch.Fields{"err": err, "article_id": articleId, "def_time": defTime})
+		return nil
+	}
+
+	return entities
+}
+
+func FindArticleVisitStatisticByOrganizationIdAndDefTimeAfter(orgaId hide.ID, defTime time.Time) []ArticleVisitStatistic {
+	query := `SELECT date("date"),
+		(SELECT COALESCE(SUM(views), 0) FROM "article_visit" JOIN "article" ON "article_visit".article_id = "article".id WHERE "article".organization_id = $1 AND date("article_visit".def_time) = "date") "views",
+		(SELECT COUNT(DISTINCT user_id) FROM "article_visit" JOIN "article" ON "article_visit".article_id = "article".id WHERE "article".organization_id = $1 AND date("article_visit".def_time) = "date") "readers"
+		FROM (SELECT * FROM generate_series(date($2), date(now()), interval '1 day') "date") AS date_series
+		ORDER BY "date" ASC`
+	var entities []ArticleVisitStatistic
+
+	if err := connection.Select(&entities, query, orgaId, defTime); err != nil {
+		logbuch.Error("Error reading article visit statistic by organization id and def time after", logbuch.Fields{"err": err, "orga_id": orgaId, "def_time": defTime})
+		return nil
+	}
+
+	return entities
+}
+
+func FindArticleVisitUserGroupStatisticByOrganizationIdAndArticleIdAndDefTimeAfter(orgaId, articleId hide.ID, defTime time.Time) []ArticleVisitUserGroupStatistic {
+	query := `SELECT "user_group".id "user_group_id", "user_group".name, COUNT(DISTINCT "article_visit".user_id) "readers"
+		FROM "article_visit"
+		JOIN "user_group_member" ON "article_visit".user_id = "user_group_member".user_id
+		JOIN "user_group" ON "user_group_member".user_group_id = "user_group".id
+		WHERE "user_group".organization_id = $1
+		AND "article_visit".article_id = $2
+		AND "article_visit".def_time > $3
+		GROUP BY "user_group".id, "user_group".name
+		ORDER BY "readers" DESC, "user_group".name ASC`
+	var entities []ArticleVisitUserGroupStatistic
+
+	if err := connection.Select(&entities, query, orgaId, articleId, defTime); err != nil {
+		logbuch.Error("Error reading article visit user group statistic by organization id and article id and def time after", logbuch.Fields{"err": err, "orga_id": orgaId, "article_id": articleId, "def_time": defTime})
+		return nil
+	}
+
+	return entities
+}
+
+func FindArticleVisitArticleStatisticByOrganizationIdAndLanguageIdAndDefTimeAfterLimit(orgaId, langId hide.ID, defTime time.Time, n int) []ArticleVisitArticleStatistic {
+	query := `SELECT "article_visit".article_id,
+		COALESCE((SELECT title FROM "article_content" WHERE article_id = "article_visit".article_id AND version = 0 ORDER BY language_id = $2 DESC LIMIT 1), '') "title",
+		SUM("article_visit".views) "views",
+		COUNT(DISTINCT "article_visit".user_id) "readers"
+		FROM "article_visit"
+		JOIN "article" ON "article_visit".article_id = "article".id
+		WHERE "article".organization_id = $1
+		AND "article".private IS FALSE
+		AND "article_visit".def_time > $3
+		GROUP BY "article_visit".article_id
+		ORDER BY "views" DESC, "readers" DESC
+		LIMIT $4`
+	var entities []ArticleVisitArticleStatistic
+
+	if err := connection.Select(&entities, query, orgaId, langId, defTime, n); err != nil {
+		logbuch.Error("Error reading article visit article statistic by organization id and language id and def time after", logbuch.Fields{"err": err, "orga_id": orgaId, "lang_id": langId, "def_time": defTime, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+// GetArticleVisitLastVersionByArticleIdAndUserIdAndLanguageId returns the latest version the user has read.
+// Views that have not been merged yet are taken into account. Returns 0 if the user has never read the article.
+func GetArticleVisitLastVersionByArticleIdAndUserIdAndLanguageId(articleId, userId, langId hide.ID) int {
+	query := `SELECT GREATEST(
+		(SELECT COALESCE(MAX(version), 0) FROM "article_visit" WHERE article_id = $1 AND user_id = $2 AND language_id = $3),
+		(SELECT COALESCE(MAX(version), 0) FROM "article_view" WHERE article_id = $1 AND user_id = $2 AND language_id = $3))`
+	var version int
+
+	if err := connection.Get(&version, query, articleId, userId, langId); err != nil {
+		logbuch.Error("Error reading article visit last version by article id and user id and language id", logbuch.Fields{"err": err, "article_id": articleId, "user_id": userId, "lang_id": langId})
+		return 0
+	}
+
+	return version
+}
+
+// FindArticleVisitChangeByOrganizationIdAndUserIdLimit returns the articles the user has read and which have been changed by someone else since.
+func FindArticleVisitChangeByOrganizationIdAndUserIdLimit(orgaId, userId hide.ID, n int) []ArticleVisitChange {
+	query := `SELECT "article".id "article_id",
+		visits.language_id,
+		"article_content".title,
+		visits.version "last_visited_version",
+		last_content.version
+		` + articleVisitChangeQuery + `
+		ORDER BY "article".mod_time DESC
+		LIMIT $3`
+	var entities []ArticleVisitChange
+
+	if err := connection.Select(&entities, query, orgaId, userId, n); err != nil {
+		logbuch.Error("Error reading article visit change by organization id and user id", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+func CountArticleVisitChangeByOrganizationIdAndUserId(orgaId, userId hide.ID) int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(1) `+articleVisitChangeQuery, orgaId, userId); err != nil {
+		logbuch.Error("Error counting article visit change by organization id and user id", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return 0
+	}
+
+	return count
+}
+
+func CountArticleVisitReaderByArticleId(articleId hide.ID) int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(DISTINCT user_id) FROM "article_visit" WHERE article_id = $1`, articleId); err != nil {
+		logbuch.Error("Error counting article visit reader by article id", logbuch.Fields{"err": err, "article_id": articleId})
+		return 0
+	}
+
+	return count
+}
+
+// CountArticleVisitReaderByArticleIdAndLanguageIdAndMinVersion returns the number of unique readers who have read at least given version.
+func CountArticleVisitReaderByArticleIdAndLanguageIdAndMinVersion(articleId, langId hide.ID, version int) int {
+	query := `SELECT COUNT(DISTINCT user_id) FROM "article_visit" WHERE article_id = $1 AND language_id = $2 AND version >= $3`
+	var count int
+
+	if err := connection.Get(&count, query, articleId, langId, version); err != nil {
+		logbuch.Error("Error counting article visit reader by article id and language id and min version", logbuch.Fields{"err": err, "article_id": articleId, "lang_id": langId, "version": version})
+		return 0
+	}
+
+	return count
+}
+
+func CountArticleVisitReaderByOrganizationIdAndDefTimeAfter(orgaId hide.ID, defTime time.Time) int {
+	query := `SELECT COUNT(DISTINCT user_id) FROM "article_visit"
+		JOIN "article" ON "article_visit".article_id = "article".id
+		WHERE "article".organization_id = $1
+		AND "article_visit".def_time > $2`
+	var count int
+
+	if err := connection.Get(&count, query, orgaId, defTime); err != nil {
+		logbuch.Error("Error counting article visit reader by organization id and def time after", logbuch.Fields{"err": err, "orga_id": orgaId, "def_time": defTime})
+		return 0
+	}
+
+	return count
+}
+
 func SaveArticleVisit(tx *sqlx.Tx, entity *ArticleVisit) error {
 	return connection.SaveEntity(tx, entity,
-		`INSERT INTO "article_visit" (article_id, user_id)
-			VALUES (:article_id, :user_id)
+		`INSERT INTO "article_visit" (article_id, user_id, language_id, version, views)
+			VALUES (:article_id, :user_id, :language_id, :version, :views)
 			RETURNING id`,
 		`UPDATE "article_visit" SET article_id = :article_id,
-			user_id = :user_id
+			user_id = :user_id,
+			language_id = :language_id,
+			version = :version,
+			views = :views
 			WHERE id = :id`)
 }
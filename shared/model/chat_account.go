@@ -0,0 +1,107 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+)
+
+// ChatAccount links the Slack or Matrix account of a member to a chat integration.
+// The account must be confirmed using the code sent to it before it's used.
+// The room ID is the direct message room for Matrix, which is created on the first message.
+type ChatAccount struct {
+	db.BaseEntity
+
+	ChatIntegrationId    hide.ID     `db:"chat_integration_id" json:"chat_integration_id"`
+	OrganizationMemberId hide.ID     `db:"organization_member_id" json:"-"`
+	ExternalId           string      `db:"external_id" json:"external_id"`
+	RoomId               null.String `db:"room_id" json:"-"`
+	Code                 string      `json:"-"`
+	Confirmed            bool        `json:"confirmed"`
+}
+
+func GetChatAccountByChatIntegrationIdAndOrganizationMemberId(integrationId, memberId hide.ID) *ChatAccount {
+	entity := new(ChatAccount)
+
+	if err := connection.Get(entity, `SELECT * FROM "chat_account" WHERE chat_integration_id = $1 AND organization_member_id = $2`, integrationId, memberId); err != nil {
+		logbuch.Debug("Chat account by chat integration id and organization member id not found", logbuch.Fields{"err": err, "chat_integration_id": integrationId, "member_id": memberId})
+		return nil
+	}
+
+	return entity
+}
+
+func GetChatAccountByChatIntegrationIdAndExternalIdAndConfirmed(integrationId hide.ID, externalId string) *ChatAccount {
+	entity := new(ChatAccount)
+
+	if err := connection.Get(entity, `SELECT * FROM "chat_account"
+		WHERE chat_integration_id = $1
+		AND external_id = $2
+		AND confirmed IS TRUE
+		ORDER BY def_time
+		LIMIT 1`, integrationId, externalId); err != nil {
+		logbuch.Debug("Chat account by chat integration id and external id and confirmed not found", logbuch.Fields{"err": err, "chat_integration_id": integrationId, "external_id": externalId})
+		return nil
+	}
+
+	return entity
+}
+
+func FindChatAccountByOrganizationMemberId(memberId hide.ID) []ChatAccount {
+	query := `SELECT * FROM "chat_account" WHERE organization_member_id = $1`
+	var entities []ChatAccount
+
+	if err := connection.Select(&entities, query, memberId); err != nil {
+		logbuch.Error("Error reading chat accounts by organization member id", logbuch.Fields{"err": err, "member_id": memberId})
+		return nil
+	}
+
+	return entities
+}
+
+func FindChatAccountByOrganizationIdAndUserIdAndConfirmed(orgaId, userId hide.ID) []ChatAccount {
+	query := `SELECT "chat_account".* FROM "chat_account"
+		JOIN "organization_member" ON "chat_account".organization_member_id = "organization_member".id
+		WHERE "organization_member".organization_id = $1
+		AND "organization_member".user_id = $2
+		AND "organization_member".active IS TRUE
+		AND confirmed IS TRUE`
+	var entities []ChatAccount
+
+	if err := connection.Select(&entities, query, orgaId, userId); err != nil {
+		logbuch.Error("Error reading chat accounts by organization id and user id and confirmed", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteChatAccountById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "chat_account" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting chat account by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveChatAccount(tx *sqlx.Tx, entity *ChatAccount) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "chat_account" (chat_integration_id, organization_member_id, external_id, room_id, code, confirmed)
+			VALUES (:chat_integration_id, :organization_member_id, :external_id, :room_id, :code, :confirmed) RETURNING id`,
+		`UPDATE "chat_account" SET chat_integration_id = :chat_integration_id,
+			organization_member_id = :organization_member_id,
+			external_id = :external_id,
+			room_id = :room_id,
+			code = :code,
+			confirmed = :confirmed
+			WHERE id = :id`)
+}
@@ -0,0 +1,112 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	ChatIntegrationSlack  = "slack"
+	ChatIntegrationMatrix = "matrix"
+)
+
+// ChatIntegration posts selected feed events to a Slack incoming webhook or Matrix room.
+// The access token is the bot token for Slack and the access token of the bot user for Matrix.
+// The API URL is the Slack Web API or the Matrix homeserver URL.
+type ChatIntegration struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID     `db:"organization_id" json:"organization_id"`
+	Type           string      `json:"type"`
+	Name           string      `json:"name"`
+	WebhookURL     null.String `db:"webhook_url" json:"webhook_url"`
+	APIURL         string      `db:"api_url" json:"api_url"`
+	AccessToken    null.String `db:"access_token" json:"-"`
+	SigningSecret  null.String `db:"signing_secret" json:"-"`
+	RoomId         null.String `db:"room_id" json:"room_id"`
+
+	Reasons []string `db:"-" json:"reasons"`
+}
+
+func GetChatIntegrationById(id hide.ID) *ChatIntegration {
+	entity := new(ChatIntegration)
+
+	if err := connection.Get(entity, `SELECT * FROM "chat_integration" WHERE id = $1`, id); err != nil {
+		logbuch.Debug("Chat integration by id not found", logbuch.Fields{"err": err, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func GetChatIntegrationByOrganizationIdAndId(orgaId, id hide.ID) *ChatIntegration {
+	entity := new(ChatIntegration)
+
+	if err := connection.Get(entity, `SELECT * FROM "chat_integration" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Chat integration by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func FindChatIntegrationByOrganizationId(orgaId hide.ID) []ChatIntegration {
+	query := `SELECT * FROM "chat_integration" WHERE organization_id = $1 ORDER BY name`
+	var entities []ChatIntegration
+
+	if err := connection.Select(&entities, query, orgaId); err != nil {
+		logbuch.Error("Error reading chat integrations by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
+func FindChatIntegrationByOrganizationIdAndReason(orgaId hide.ID, reason string) []ChatIntegration {
+	query := `SELECT "chat_integration".* FROM "chat_integration"
+		JOIN "chat_integration_reason" ON "chat_integration".id = "chat_integration_reason".chat_integration_id
+		WHERE organization_id = $1
+		AND reason = $2`
+	var entities []ChatIntegration
+
+	if err := connection.Select(&entities, query, orgaId, reason); err != nil {
+		logbuch.Error("Error reading chat integrations by organization id and reason", logbuch.Fields{"err": err, "orga_id": orgaId, "reason": reason})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteChatIntegrationById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	// reasons and linked accounts are deleted by cascade
+	if _, err := tx.Exec(`DELETE FROM "chat_integration" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting chat integration by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveChatIntegration(tx *sqlx.Tx, entity *ChatIntegration) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "chat_integration" (organization_id, type, name, webhook_url, api_url, access_token, signing_secret, room_id)
+			VALUES (:organization_id, :type, :name, :webhook_url, :api_url, :access_token, :signing_secret, :room_id) RETURNING id`,
+		`UPDATE "chat_integration" SET organization_id = :organization_id,
+			type = :type,
+			name = :name,
+			webhook_url = :webhook_url,
+			api_url = :api_url,
+			access_token = :access_token,
+			signing_secret = :signing_secret,
+			room_id = :room_id
+			WHERE id = :id`)
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+type ChatIntegrationReason struct {
+	db.BaseEntity
+
+	ChatIntegrationId hide.ID `db:"chat_integration_id" json:"chat_integration_id"`
+	Reason            string  `json:"reason"`
+}
+
+func FindChatIntegrationReasonByChatIntegrationId(integrationId hide.ID) []ChatIntegrationReason {
+	query := `SELECT * FROM "chat_integration_reason" WHERE chat_integration_id = $1`
+	var entities []ChatIntegrationReason
+
+	if err := connection.Select(&entities, query, integrationId); err != nil {
+		logbuch.Error("Error reading chat integration reasons by chat integration id", logbuch.Fields{"err": err, "chat_integration_id": integrationId})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteChatIntegrationReasonByChatIntegrationId(tx *sqlx.Tx, integrationId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "chat_integration_reason" WHERE chat_integration_id = $1`, integrationId); err != nil {
+		logbuch.Error("Error deleting chat integration reasons by chat integration id", logbuch.Fields{"err": err, "chat_integration_id": integrationId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveChatIntegrationReason(tx *sqlx.Tx, entity *ChatIntegrationReason) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "chat_integration_reason" (chat_integration_id, reason)
+			VALUES (:chat_integration_id, :reason) RETURNING id`,
+		`UPDATE "chat_integration_reason" SET chat_integration_id = :chat_integration_id,
+			reason = :reason
+			WHERE id = :id`)
+}
@@ -14,6 +14,7 @@ type Client struct {
 	Name           string  `json:"name"`
 	ClientId       string  `db:"client_id" json:"client_id"`
 	ClientSecret   string  `db:"client_secret" json:"client_secret"`
+	UserId         hide.ID `db:"user_id" json:"user_id"` // bot user used for write access
 
 	Scopes []ClientScope `json:"scopes" db:"-"`
 }
@@ -51,6 +52,17 @@ func GetClientByOrganizationIdAndClientId(orgaId hide.ID, clientId string) *Clie
 	return entity
 }
 
+func GetClientByOrganizationIdAndUserId(orgaId, userId hide.ID) *Client {
+	entity := new(Client)
+
+	if err := connection.Get(entity, `SELECT * FROM "client" WHERE organization_id = $1 AND user_id = $2`, orgaId, userId); err != nil {
+		logbuch.Debug("Client by organization id and user id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entity
+}
+
 func FindClientByOrganizationId(orgaId hide.ID) []Client {
 	query := `SELECT * FROM "client" WHERE organization_id = $1`
 	var entities []Client
@@ -90,11 +102,12 @@ func DeleteClientById(tx *sqlx.Tx, id hide.ID) error {
 
 func SaveClient(tx *sqlx.Tx, entity *Client) error {
 	return connection.SaveEntity(tx, entity,
-		`INSERT INTO "client" (organization_id, name, client_id, client_secret)
-			VALUES (:organization_id, :name, :client_id, :client_secret) RETURNING id`,
+		`INSERT INTO "client" (organization_id, name, client_id, client_secret, user_id)
+			VALUES (:organization_id, :name, :client_id, :client_secret, :user_id) RETURNING id`,
 		`UPDATE "client" SET organization_id = :organization_id,
 			name = :name,
 			client_id = :client_id,
-			client_secret = :client_secret
+			client_secret = :client_secret,
+			user_id = :user_id
 			WHERE id = :id`)
 }
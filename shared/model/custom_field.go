@@ -0,0 +1,100 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldDate   = "date"
+	CustomFieldEnum   = "enum"
+	CustomFieldUser   = "user"
+)
+
+// CustomField is a structured attribute of articles defined by the organization.
+// The field is required for articles having one of the required tags.
+type CustomField struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	Name           string  `json:"name"`
+	Type           string  `json:"type"`
+
+	Options      []string `db:"-" json:"options"`
+	RequiredTags []Tag    `db:"-" json:"required_tags"`
+}
+
+func GetCustomFieldByOrganizationIdAndId(orgaId, id hide.ID) *CustomField {
+	entity := new(CustomField)
+
+	if err := connection.Get(entity, `SELECT * FROM "custom_field" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Custom field by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func GetCustomFieldByOrganizationIdAndName(orgaId hide.ID, name string) *CustomField {
+	entity := new(CustomField)
+
+	if err := connection.Get(entity, `SELECT * FROM "custom_field" WHERE organization_id = $1 AND LOWER(name) = LOWER($2)`, orgaId, name); err != nil {
+		logbuch.Debug("Custom field by organization id and name not found", logbuch.Fields{"err": err, "orga_id": orgaId, "name": name})
+		return nil
+	}
+
+	return entity
+}
+
+func FindCustomFieldByOrganizationId(orgaId hide.ID) []CustomField {
+	query := `SELECT * FROM "custom_field" WHERE organization_id = $1 ORDER BY name`
+	var entities []CustomField
+
+	if err := connection.Select(&entities, query, orgaId); err != nil {
+		logbuch.Error("Error reading custom fields by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
+func CountCustomFieldByOrganizationId(orgaId hide.ID) int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(1) FROM "custom_field" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error counting custom fields by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return 0
+	}
+
+	return count
+}
+
+func DeleteCustomFieldById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	// options, required tags and article values are deleted by cascade
+	if _, err := tx.Exec(`DELETE FROM "custom_field" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting custom field by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveCustomField(tx *sqlx.Tx, entity *CustomField) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "custom_field" (organization_id, name, type)
+			VALUES (:organization_id, :name, :type) RETURNING id`,
+		`UPDATE "custom_field" SET organization_id = :organization_id,
+			name = :name,
+			type = :type
+			WHERE id = :id`)
+}
@@ -0,0 +1,52 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// CustomFieldOption is an option to choose from for enum fields.
+type CustomFieldOption struct {
+	db.BaseEntity
+
+	CustomFieldId hide.ID `db:"custom_field_id" json:"custom_field_id"`
+	Name          string  `json:"name"`
+}
+
+func FindCustomFieldOptionByCustomFieldId(fieldId hide.ID) []CustomFieldOption {
+	query := `SELECT * FROM "custom_field_option" WHERE custom_field_id = $1 ORDER BY id`
+	var entities []CustomFieldOption
+
+	if err := connection.Select(&entities, query, fieldId); err != nil {
+		logbuch.Error("Error reading custom field options by custom field id", logbuch.Fields{"err": err, "custom_field_id": fieldId})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteCustomFieldOptionByCustomFieldId(tx *sqlx.Tx, fieldId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "custom_field_option" WHERE custom_field_id = $1`, fieldId); err != nil {
+		logbuch.Error("Error deleting custom field options by custom field id", logbuch.Fields{"err": err, "custom_field_id": fieldId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveCustomFieldOption(tx *sqlx.Tx, entity *CustomFieldOption) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "custom_field_option" (custom_field_id, name)
+			VALUES (:custom_field_id, :name) RETURNING id`,
+		`UPDATE "custom_field_option" SET custom_field_id = :custom_field_id,
+			name = :name
+			WHERE id = :id`)
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// CustomFieldTag makes a custom field required for articles having the tag.
+type CustomFieldTag struct {
+	db.BaseEntity
+
+	CustomFieldId hide.ID `db:"custom_field_id" json:"custom_field_id"`
+	TagId         hide.ID `db:"tag_id" json:"tag_id"`
+}
+
+func FindTagByCustomFieldId(fieldId hide.ID) []Tag {
+	query := `SELECT "tag".* FROM "custom_field_tag"
+		JOIN "tag" ON "custom_field_tag".tag_id = "tag".id
+		WHERE "custom_field_tag".custom_field_id = $1
+		ORDER BY "tag".name`
+	var entities []Tag
+
+	if err := connection.Select(&entities, query, fieldId); err != nil {
+		logbuch.Error("Error reading tags by custom field id", logbuch.Fields{"err": err, "custom_field_id": fieldId})
+		return nil
+	}
+
+	return entities
+}
+
+func DeleteCustomFieldTagByCustomFieldId(tx *sqlx.Tx, fieldId hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "custom_field_tag" WHERE custom_field_id = $1`, fieldId); err != nil {
+		logbuch.Error("Error deleting custom field tags by custom field id", logbuch.Fields{"err": err, "custom_field_id": fieldId})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveCustomFieldTag(tx *sqlx.Tx, entity *CustomFieldTag) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "custom_field_tag" (custom_field_id, tag_id)
+			VALUES (:custom_field_id, :tag_id) RETURNING id`,
+		`UPDATE "custom_field_tag" SET custom_field_id = :custom_field_id,
+			tag_id = :tag_id
+			WHERE id = :id`)
+}
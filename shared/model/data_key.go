@@ -0,0 +1,111 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// DataKey is a key to encrypt stored files of an organization, wrapped (encrypted) by a master key.
+// Files without organization are encrypted using the data key without organization.
+// Retired keys are not used to encrypt new files, but are kept until all files have been re-encrypted.
+type DataKey struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID   `db:"organization_id" json:"-"`
+	MasterKeyId    string    `db:"master_key_id" json:"-"`
+	WrappedKey     []byte    `db:"wrapped_key" json:"-"`
+	Retired        null.Time `json:"-"`
+}
+
+func GetDataKeyById(id hide.ID) *DataKey {
+	entity := new(DataKey)
+
+	if err := connection.Get(entity, `SELECT * FROM "data_key" WHERE id = $1`, id); err != nil {
+		logbuch.Debug("Data key by id not found", logbuch.Fields{"err": err, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func GetDataKeyByOrganizationIdAndActive(orgaId hide.ID) *DataKey {
+	entity := new(DataKey)
+
+	if err := connection.Get(entity, `SELECT * FROM "data_key" WHERE organization_id IS NOT DISTINCT FROM $1 AND retired IS NULL`, orgaId); err != nil {
+		logbuch.Debug("Active data key by organization id not found", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entity
+}
+
+func FindDataKeyByMasterKeyIdNot(masterKeyId string) []DataKey {
+	var entities []DataKey
+
+	if err := connection.Select(&entities, `SELECT * FROM "data_key" WHERE master_key_id != $1`, masterKeyId); err != nil {
+		logbuch.Error("Error reading data keys by master key id not", logbuch.Fields{"err": err, "master_key_id": masterKeyId})
+		return nil
+	}
+
+	return entities
+}
+
+func FindDataKeyByActiveAndDefTimeBefore(defTime time.Time) []DataKey {
+	var entities []DataKey
+
+	if err := connection.Select(&entities, `SELECT * FROM "data_key" WHERE retired IS NULL AND def_time < $1`, defTime); err != nil {
+		logbuch.Error("Error reading active data keys by def time before", logbuch.Fields{"err": err, "def_time": defTime})
+		return nil
+	}
+
+	return entities
+}
+
+func FindDataKeyByRetiredBefore(retired time.Time) []DataKey {
+	var entities []DataKey
+
+	if err := connection.Select(&entities, `SELECT * FROM "data_key" WHERE retired < $1`, retired); err != nil {
+		logbuch.Error("Error reading data keys by retired before", logbuch.Fields{"err": err, "retired": retired})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveDataKey(tx *sqlx.Tx, entity *DataKey) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "data_key" (organization_id,
+			master_key_id,
+			wrapped_key,
+			retired)
+			VALUES (:organization_id,
+			:master_key_id,
+			:wrapped_key,
+			:retired) RETURNING id`,
+		`UPDATE "data_key" SET organization_id = :organization_id,
+			master_key_id = :master_key_id,
+			wrapped_key = :wrapped_key,
+			retired = :retired
+			WHERE id = :id`)
+}
+
+func DeleteDataKeyById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	_, err := tx.Exec(`DELETE FROM "data_key" WHERE id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting data key by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
@@ -19,6 +19,7 @@ const (
 		"triggeredby".firstname "triggered_by_user.firstname",
 		"triggeredby".lastname "triggered_by_user.lastname",
 		"triggeredby".picture "triggered_by_user.picture",
+		"triggeredby".bot "triggered_by_user.bot",
 		"triggeredbymember".username "triggered_by_user.organization_member.username"
 		FROM "feed"
 		LEFT JOIN "feed_access" ON "feed_access".feed_id = "feed".id AND "feed_access".user_id = $2
@@ -57,6 +58,34 @@ func FindFeedByOrganizationIdAndReason(orgaId hide.ID, reason string) []Feed {
 	return entities
 }
 
+func CountFeedById(id hide.ID) int {
+	query := `SELECT COUNT(1) FROM "feed" WHERE id = $1`
+	var count int
+
+	if err := connection.Get(&count, query, id); err != nil {
+		logbuch.Error("Error counting feed by id", logbuch.Fields{"err": err, "id": id})
+		return 0
+	}
+
+	return count
+}
+
+func GetFeedByOrganizationIdAndUserIdAndLanguageIdAndId(orgaId, userId, langId, id hide.ID) *Feed {
+	query := feedBaseQuery + `WHERE "feed".organization_id = $1
+		AND "feed".id = $3
+		AND ("feed".public IS TRUE OR EXISTS
+			(SELECT 1 FROM "feed_access" WHERE feed_id = "feed".id AND user_id = $2))`
+	entity := new(Feed)
+
+	if err := connection.Get(entity, query, orgaId, userId, id); err != nil {
+		logbuch.Debug("Feed by organization id and user id and language id and id not found", logbuch.Fields{"err": err, "organization_id": orgaId, "user_id": userId, "lang_id": langId, "id": id})
+		return nil
+	}
+
+	entity.FeedRefs = FindFeedRefByOrganizationIdAndLanguageIdAndFeedId(orgaId, langId, entity.ID)
+	return entity
+}
+
 func FindFeedByOrganizationIdAndUserIdAndLanguageIdAndFilterLimit(orgaId, userId, langId hide.ID, filter *SearchFeedFilter) []Feed {
 	query, params := buildFeedByOrganizationIdAndUserIdAndLanguageIdAndFilterLimitQuery(orgaId, userId, filter)
 	var entities []Feed
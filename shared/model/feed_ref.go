@@ -15,6 +15,7 @@ const (
 		CASE WHEN "user".firstname IS NULL THEN '' ELSE "user".firstname END "user.firstname",
 		CASE WHEN "user".lastname IS NULL THEN '' ELSE "user".lastname END "user.lastname",
 		CASE WHEN "user".picture IS NULL THEN '' ELSE "user".picture END "user.picture",
+		CASE WHEN "user".bot IS NULL THEN FALSE ELSE "user".bot END "user.bot",
 		CASE WHEN "organization_member".username IS NULL THEN '' ELSE "organization_member".username END "user.organization_member.username",
 		CASE WHEN "user_group".id IS NULL THEN 0 ELSE "user_group".id END "group.id",
 		CASE WHEN "user_group".info IS NULL THEN '' ELSE "user_group".info END "group.info",
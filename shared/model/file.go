@@ -2,10 +2,12 @@ package model
 
 import (
 	"emviwiki/shared/db"
+	"fmt"
 	"github.com/emvi/hide"
 	"github.com/emvi/logbuch"
 	"github.com/emvi/null"
 	"github.com/jmoiron/sqlx"
+	"path/filepath"
 	"time"
 )
 
@@ -24,11 +26,29 @@ type File struct {
 	MimeType       string      `db:"mime_type" json:"mime_type"`
 	Size           int64       `json:"size"`
 	MD5            string      `json:"md5"`
+	Blob           null.String `json:"-"` // optional content-addressed path in store shared by files with the same MD5
+	Unreferenced   null.Time   `json:"-"` // set by garbage collection when the file was found to be unused
+	Quarantined    bool        `json:"-"` // set if the file could not be scanned for malware yet, it must not be served
 }
 
+// fileStorePathSQL selects the path in store for a file row as an SQL expression.
+// This must be kept in sync with File.StorePath.
+const fileStorePathSQL = `COALESCE(%[1]s.blob, CASE WHEN %[1]s.path = '' THEN %[1]s.unique_name ELSE %[1]s.path || '/' || %[1]s.unique_name END)`
+
+// StorePath returns the path of the file content in store.
+func (file *File) StorePath() string {
+	if file.Blob.Valid {
+		return file.Blob.String
+	}
+
+	return filepath.Join(file.Path, file.UniqueName)
+}
+
+// GetFileStorageUsageByOrganizationId returns the storage used by all files of the organization, including its picture.
+// Files sharing the same content are counted once.
 func GetFileStorageUsageByOrganizationId(orgaId hide.ID) int64 {
-	query := `SELECT CASE WHEN SUM("size") IS NULL THEN 0 ELSE SUM("size") END
-		FROM (SELECT DISTINCT ON (unique_name) "size" FROM "file" WHERE organization_id = $1 AND article_id IS NOT NULL) AS files`
+	query := fmt.Sprintf(`SELECT CASE WHEN SUM("size") IS NULL THEN 0 ELSE SUM("size") END
+		FROM (SELECT DISTINCT ON (%s) "size" FROM "file" WHERE organization_id = $1) AS files`, fmt.Sprintf(fileStorePathSQL, `"file"`))
 	var size int64
 
 	if err := connection.Get(&size, query, orgaId); err != nil {
@@ -93,6 +113,18 @@ func FindFileByOrganizationId(orgaId hide.ID) []File {
 	return entities
 }
 
+func FindFileByOrganizationIdAndUniqueName(orgaId hide.ID, uniqueName string) []File {
+	query := `SELECT * FROM "file" WHERE organization_id = $1 AND unique_name = $2 ORDER BY id`
+	var entities []File
+
+	if err := connection.Select(&entities, query, orgaId, uniqueName); err != nil {
+		logbuch.Error("Error reading files by organization id and unique name", logbuch.Fields{"err": err, "orga_id": orgaId, "unique_name": uniqueName})
+		return nil
+	}
+
+	return entities
+}
+
 func FindFileByOrganizationIdAndUniqueNameAndNotId(orgaId hide.ID, uniqueName string, id hide.ID) []File {
 	query := `SELECT * FROM "file" WHERE organization_id = $1 AND unique_name = $2 AND id != $3`
 	var entities []File
@@ -205,7 +237,10 @@ func SaveFile(tx *sqlx.Tx, entity *File) error {
 			type,
 			mime_type,
 			size,
-			md5)
+			md5,
+			blob,
+			unreferenced,
+			quarantined)
 			VALUES (:organization_id,
 			:user_id,
 			:article_id,
@@ -217,7 +252,10 @@ func SaveFile(tx *sqlx.Tx, entity *File) error {
 			:type,
 			:mime_type,
 			:size,
-			:md5) RETURNING id`,
+			:md5,
+			:blob,
+			:unreferenced,
+			:quarantined) RETURNING id`,
 		`UPDATE "file" SET organization_id = :organization_id,
 			user_id = :user_id,
 			article_id = :article_id,
@@ -229,6 +267,184 @@ func SaveFile(tx *sqlx.Tx, entity *File) error {
 			type = :type,
 			mime_type = :mime_type,
 			size = :size,
-			md5 = :md5
+			md5 = :md5,
+			blob = :blob,
+			unreferenced = :unreferenced,
+			quarantined = :quarantined
 			WHERE id = :id`)
 }
+
+// FileUnreferencedReport is the number and size of unreferenced files for an organization.
+type FileUnreferencedReport struct {
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	Files          int     `db:"files" json:"files"`
+	Size           int64   `db:"size" json:"size"`
+}
+
+// FileDuplicate is a MD5 hash used by files within an organization which are stored at different paths.
+type FileDuplicate struct {
+	OrganizationId hide.ID `db:"organization_id"`
+	MD5            string  `db:"md5"`
+}
+
+// UpdateFileUnreferenced marks all files which are not referenced by any article content version, article list or profile
+// as unreferenced since given time, and clears the mark for files which are referenced (again).
+// Files already marked keep their time.
+func UpdateFileUnreferenced(tx *sqlx.Tx, now time.Time) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	query := `UPDATE "file" SET unreferenced = CASE WHEN (
+			EXISTS (
+				SELECT 1 FROM "article_content"
+				JOIN "article" ON "article_content".article_id = "article".id
+				WHERE "article".organization_id = "file".organization_id
+				AND strpos("article_content".content, "file".unique_name) > 0
+			)
+			OR EXISTS (
+				SELECT 1 FROM "article_list_name"
+				JOIN "article_list" ON "article_list_name".article_list_id = "article_list".id
+				WHERE "article_list".organization_id = "file".organization_id
+				AND strpos("article_list_name".info, "file".unique_name) > 0
+			)
+			OR EXISTS (SELECT 1 FROM "user" WHERE picture = "file".unique_name)
+			OR EXISTS (SELECT 1 FROM "organization" WHERE picture = "file".unique_name)
+		) THEN NULL ELSE COALESCE("file".unreferenced, $1) END`
+
+	if _, err := tx.Exec(query, now); err != nil {
+		logbuch.Error("Error updating unreferenced files", logbuch.Fields{"err": err})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func FindFileUnreferencedReport() []FileUnreferencedReport {
+	query := `SELECT organization_id, COUNT(1) "files", COALESCE(SUM("size"), 0) "size"
+		FROM "file"
+		WHERE unreferenced IS NOT NULL
+		GROUP BY organization_id`
+	var entities []FileUnreferencedReport
+
+	if err := connection.Select(&entities, query); err != nil {
+		logbuch.Error("Error reading unreferenced file report", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entities
+}
+
+func FindFileByUnreferencedBefore(unreferenced time.Time) []File {
+	var entities []File
+
+	if err := connection.Select(&entities, `SELECT * FROM "file" WHERE unreferenced < $1`, unreferenced); err != nil {
+		logbuch.Error("Error reading files by unreferenced before", logbuch.Fields{"err": err, "unreferenced": unreferenced})
+		return nil
+	}
+
+	return entities
+}
+
+func FindFileByQuarantined() []File {
+	var entities []File
+
+	if err := connection.Select(&entities, `SELECT * FROM "file" WHERE quarantined IS TRUE`); err != nil {
+		logbuch.Error("Error reading quarantined files", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileDuplicate returns all MD5 hashes of attachments within an organization which are stored more than once.
+func FindFileDuplicate() []FileDuplicate {
+	query := fmt.Sprintf(`SELECT organization_id, md5 FROM "file"
+		WHERE organization_id IS NOT NULL
+		AND (article_id IS NOT NULL OR room_id IS NOT NULL)
+		GROUP BY organization_id, md5
+		HAVING COUNT(DISTINCT %s) > 1`, fmt.Sprintf(fileStorePathSQL, `"file"`))
+	var entities []FileDuplicate
+
+	if err := connection.Select(&entities, query); err != nil {
+		logbuch.Error("Error reading file duplicates", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entities
+}
+
+func FindFileByOrganizationIdAndMD5(orgaId hide.ID, md5 string) []File {
+	query := `SELECT * FROM "file"
+		WHERE organization_id = $1
+		AND md5 = $2
+		AND (article_id IS NOT NULL OR room_id IS NOT NULL)`
+	var entities []File
+
+	if err := connection.Select(&entities, query, orgaId, md5); err != nil {
+		logbuch.Error("Error reading files by organization id and md5", logbuch.Fields{"err": err, "orga_id": orgaId, "md5": md5})
+		return nil
+	}
+
+	return entities
+}
+
+// CountFileByStorePath returns the number of files using given path in store, including files in trash.
+func CountFileByStorePath(path string) int {
+	query := fmt.Sprintf(`SELECT (SELECT COUNT(1) FROM "file" WHERE %s = $1) +
+		(SELECT COUNT(1) FROM "trash", jsonb_populate_recordset(NULL::"file", "trash".data->'file') f WHERE %s = $1)`,
+		fmt.Sprintf(fileStorePathSQL, `"file"`), fmt.Sprintf(fileStorePathSQL, "f"))
+	var count int
+
+	if err := connection.Get(&count, query, path); err != nil {
+		logbuch.Error("Error counting file by store path", logbuch.Fields{"err": err, "path": path})
+		return -1
+	}
+
+	return count
+}
+
+// FileStorePath is a path in store and the organization the content belongs to.
+type FileStorePath struct {
+	OrganizationId hide.ID `db:"organization_id"`
+	Path           string  `db:"path"`
+	MimeType       string  `db:"mime_type"`
+}
+
+// FindFileStorePath returns the distinct paths in store of all files, including files in trash.
+func FindFileStorePath() []FileStorePath {
+	query := fmt.Sprintf(`SELECT organization_id, %s "path", mime_type FROM "file"
+		UNION SELECT f.organization_id, %s, f.mime_type FROM "trash", jsonb_populate_recordset(NULL::"file", "trash".data->'file') f
+		ORDER BY 2, 1`,
+		fmt.Sprintf(fileStorePathSQL, `"file"`), fmt.Sprintf(fileStorePathSQL, "f"))
+	var paths []FileStorePath
+
+	if err := connection.Select(&paths, query); err != nil {
+		logbuch.Error("Error reading file store paths", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return paths
+}
+
+func UpdateFileBlobByOrganizationIdAndMD5(tx *sqlx.Tx, orgaId hide.ID, md5, blob string) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	query := `UPDATE "file" SET blob = $3
+		WHERE organization_id = $1
+		AND md5 = $2
+		AND (article_id IS NOT NULL OR room_id IS NOT NULL)`
+
+	if _, err := tx.Exec(query, orgaId, md5, blob); err != nil {
+		logbuch.Error("Error updating file blob by organization id and md5", logbuch.Fields{"err": err, "orga_id": orgaId, "md5": md5})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
@@ -27,6 +27,21 @@ type SearchArticleFilter struct {
 	SortTitle        string    `json:"sort_title"`
 	SortPublished    string    `json:"sort_published"`
 	SortRelevance    string    `json:"sort_relevance"`
+
+	// CustomFields filters by custom field values, SortCustomFieldId sorts by the value of given custom field
+	CustomFields      []CustomFieldFilter `json:"custom_fields"`
+	SortCustomFieldId hide.ID             `json:"sort_custom_field_id"`
+	SortCustomField   string              `json:"sort_custom_field"`
+}
+
+// CustomFieldFilter filters articles by the value of a custom field.
+// Min and max filter number and date fields by range. The type is set from the custom field.
+type CustomFieldFilter struct {
+	CustomFieldId hide.ID `json:"custom_field_id"`
+	Type          string  `json:"-"`
+	Value         string  `json:"value"`
+	Min           string  `json:"min"`
+	Max           string  `json:"max"`
 }
 
 type SearchArticleListFilter struct {
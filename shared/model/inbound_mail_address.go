@@ -0,0 +1,90 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// InboundMailAddress is an address mails can be sent to, in order to create articles in the organization.
+// Articles created from mails sent to an address with article list are added to the list.
+type InboundMailAddress struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	ArticleListId  hide.ID `db:"article_list_id" json:"article_list_id"`
+	Token          string  `json:"token"`
+
+	Address string `db:"-" json:"address"`
+}
+
+func GetInboundMailAddressByToken(token string) *InboundMailAddress {
+	entity := new(InboundMailAddress)
+
+	if err := connection.Get(entity, `SELECT * FROM "inbound_mail_address" WHERE LOWER(token) = LOWER($1)`, token); err != nil {
+		logbuch.Debug("Inbound mail address by token not found", logbuch.Fields{"err": err, "token": token})
+		return nil
+	}
+
+	return entity
+}
+
+func GetInboundMailAddressByOrganizationIdAndId(orgaId, id hide.ID) *InboundMailAddress {
+	entity := new(InboundMailAddress)
+
+	if err := connection.Get(entity, `SELECT * FROM "inbound_mail_address" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Inbound mail address by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func FindInboundMailAddressByOrganizationId(orgaId hide.ID) []InboundMailAddress {
+	query := `SELECT * FROM "inbound_mail_address" WHERE organization_id = $1 ORDER BY def_time`
+	var entities []InboundMailAddress
+
+	if err := connection.Select(&entities, query, orgaId); err != nil {
+		logbuch.Error("Error reading inbound mail addresses by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
+func CountInboundMailAddressByToken(token string) int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(1) FROM "inbound_mail_address" WHERE LOWER(token) = LOWER($1)`, token); err != nil {
+		logbuch.Error("Error counting inbound mail addresses by token", logbuch.Fields{"err": err, "token": token})
+		return 0
+	}
+
+	return count
+}
+
+func DeleteInboundMailAddressById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "inbound_mail_address" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting inbound mail address by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveInboundMailAddress(tx *sqlx.Tx, entity *InboundMailAddress) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "inbound_mail_address" (organization_id, article_list_id, token)
+			VALUES (:organization_id, :article_list_id, :token) RETURNING id`,
+		`UPDATE "inbound_mail_address" SET organization_id = :organization_id,
+			article_list_id = :article_list_id,
+			token = :token
+			WHERE id = :id`)
+}
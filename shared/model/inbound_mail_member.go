@@ -0,0 +1,61 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// InboundMailMember is the personal token of a member, which is part of the inbound mail addresses returned to the member.
+// Mails are only accepted if they are sent from the address of the member the token belongs to.
+type InboundMailMember struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	UserId         hide.ID `db:"user_id" json:"user_id"`
+	Token          string  `json:"token"`
+}
+
+func GetInboundMailMemberByToken(token string) *InboundMailMember {
+	entity := new(InboundMailMember)
+
+	if err := connection.Get(entity, `SELECT * FROM "inbound_mail_member" WHERE LOWER(token) = LOWER($1)`, token); err != nil {
+		logbuch.Debug("Inbound mail member by token not found", logbuch.Fields{"err": err, "token": token})
+		return nil
+	}
+
+	return entity
+}
+
+func GetInboundMailMemberByOrganizationIdAndUserId(orgaId, userId hide.ID) *InboundMailMember {
+	entity := new(InboundMailMember)
+
+	if err := connection.Get(entity, `SELECT * FROM "inbound_mail_member" WHERE organization_id = $1 AND user_id = $2`, orgaId, userId); err != nil {
+		logbuch.Debug("Inbound mail member by organization id and user id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entity
+}
+
+func CountInboundMailMemberByToken(token string) int {
+	var count int
+
+	if err := connection.Get(&count, `SELECT COUNT(1) FROM "inbound_mail_member" WHERE LOWER(token) = LOWER($1)`, token); err != nil {
+		logbuch.Error("Error counting inbound mail members by token", logbuch.Fields{"err": err, "token": token})
+		return 0
+	}
+
+	return count
+}
+
+func SaveInboundMailMember(tx *sqlx.Tx, entity *InboundMailMember) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "inbound_mail_member" (organization_id, user_id, token)
+			VALUES (:organization_id, :user_id, :token) RETURNING id`,
+		`UPDATE "inbound_mail_member" SET organization_id = :organization_id,
+			user_id = :user_id,
+			token = :token
+			WHERE id = :id`)
+}
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// LanguageTranslator is a member responsible for translating articles into a language.
+type LanguageTranslator struct {
+	db.BaseEntity
+
+	LanguageId hide.ID `db:"language_id" json:"language_id"`
+	UserId     hide.ID `db:"user_id" json:"user_id"`
+}
+
+func GetLanguageTranslatorByLanguageIdAndUserId(langId, userId hide.ID) *LanguageTranslator {
+	entity := new(LanguageTranslator)
+
+	if err := connection.Get(entity, `SELECT * FROM "language_translator" WHERE language_id = $1 AND user_id = $2`, langId, userId); err != nil {
+		logbuch.Debug("Language translator by language id and user id not found", logbuch.Fields{"err": err, "lang_id": langId, "user_id": userId})
+		return nil
+	}
+
+	return entity
+}
+
+func FindLanguageTranslatorUserByOrganizationIdAndLanguageId(orgaId, langId hide.ID) []User {
+	query := userBaseQueryHead + ` FROM "language_translator"
+		JOIN "user" ON "language_translator".user_id = "user".id
+		JOIN "organization_member" ON "user".id = "organization_member".user_id AND "organization_member".organization_id = $1
+		WHERE "language_translator".language_id = $2
+		AND "organization_member".active IS TRUE
+		ORDER BY "user".firstname, "user".lastname`
+	var entities []User
+
+	if err := connection.Select(&entities, query, orgaId, langId); err != nil {
+		logbuch.Error("Error reading language translator user by organization id and language id", logbuch.Fields{"err": err, "orga_id": orgaId, "lang_id": langId})
+		return nil
+	}
+
+	return entities
+}
+
+func FindLanguageTranslatorUserIdByOrganizationIdAndLanguageId(orgaId, langId hide.ID) []hide.ID {
+	query := `SELECT "language_translator".user_id FROM "language_translator"
+		JOIN "organization_member" ON "language_translator".user_id = "organization_member".user_id AND "organization_member".organization_id = $1
+		WHERE "language_translator".language_id = $2
+		AND "organization_member".active IS TRUE`
+	var ids []hide.ID
+
+	if err := connection.Select(&ids, query, orgaId, langId); err != nil {
+		logbuch.Error("Error reading language translator user id by organization id and language id", logbuch.Fields{"err": err, "orga_id": orgaId, "lang_id": langId})
+		return nil
+	}
+
+	return ids
+}
+
+func DeleteLanguageTranslatorById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "language_translator" WHERE id = $1`, id); err != nil {
+		logbuch.Error("Error deleting language translator by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveLanguageTranslator(tx *sqlx.Tx, entity *LanguageTranslator) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "language_translator" (language_id, user_id)
+			VALUES (:language_id, :user_id) RETURNING id`,
+		`UPDATE "language_translator" SET language_id = :language_id,
+			user_id = :user_id
+			WHERE id = :id`)
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	NotificationDeliveryInstant = "instant"
+	NotificationDeliveryDaily   = "daily"
+	NotificationDeliveryWeekly  = "weekly"
+	NotificationDeliveryApp     = "app"
+)
+
+type NotificationPreference struct {
+	db.BaseEntity
+
+	OrganizationMemberId hide.ID `db:"organization_member_id" json:"-"`
+	Category             string  `json:"category"`
+	Delivery             string  `json:"delivery"`
+}
+
+func GetNotificationPreferenceByOrganizationMemberIdAndCategoryTx(tx *sqlx.Tx, memberId hide.ID, category string) *NotificationPreference {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	entity := new(NotificationPreference)
+
+	if err := tx.Get(entity, `SELECT * FROM "notification_preference" WHERE organization_member_id = $1 AND category = $2`, memberId, category); err != nil {
+		logbuch.Debug("Notification preference by organization member id and category not found", logbuch.Fields{"err": err, "member_id": memberId, "category": category})
+		return nil
+	}
+
+	return entity
+}
+
+func FindNotificationPreferenceByOrganizationMemberId(memberId hide.ID) []NotificationPreference {
+	var entities []NotificationPreference
+
+	if err := connection.Select(&entities, `SELECT * FROM "notification_preference" WHERE organization_member_id = $1`, memberId); err != nil {
+		logbuch.Error("Error reading notification preferences by organization member id", logbuch.Fields{"err": err, "member_id": memberId})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveNotificationPreference(tx *sqlx.Tx, entity *NotificationPreference) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "notification_preference" (organization_member_id, category, delivery)
+			VALUES (:organization_member_id, :category, :delivery) RETURNING id`,
+		`UPDATE "notification_preference" SET organization_member_id = :organization_member_id,
+			category = :category,
+			delivery = :delivery
+			WHERE id = :id`)
+}
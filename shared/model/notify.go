@@ -0,0 +1,24 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+)
+
+// Notify sends a notification with given payload to all listeners of the channel.
+// When called within a transaction, the notification is delivered once the transaction has been committed.
+func Notify(tx *sqlx.Tx, channel, payload string) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	if _, err := tx.Exec(`SELECT pg_notify($1, $2)`, channel, payload); err != nil {
+		db.Rollback(tx)
+		logbuch.Error("Error sending notification", logbuch.Fields{"err": err, "channel": channel})
+		return err
+	}
+
+	return nil
+}
@@ -299,6 +299,69 @@ func DeleteOrganizationById(tx *sqlx.Tx, orgaId hide.ID) error {
 		return err
 	}
 
+	if _, err := tx.Exec(`DELETE FROM "article_view"
+		WHERE article_id IN (SELECT id FROM article WHERE organization_id = $1)`, orgaId); err != nil {
+		logbuch.Error("Error deleting article view when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "article_search_query" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting article search query when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "upload_session" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting upload session when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token_scope"
+		WHERE access_token_id IN (SELECT id FROM "access_token" WHERE organization_id = $1)`, orgaId); err != nil {
+		logbuch.Error("Error deleting access token scopes when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "access_token" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting access tokens when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "storage_usage" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting storage usage when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "data_key" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting data key when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "trash" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting trash when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "reading_campaign_member"
+		WHERE reading_campaign_id IN (SELECT id FROM reading_campaign WHERE organization_id = $1)`, orgaId); err != nil {
+		logbuch.Error("Error deleting reading campaign member when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM "reading_campaign" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error deleting reading campaign when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM "article_recommendation"
 		WHERE article_id IN (SELECT id FROM article WHERE organization_id = $1)`, orgaId); err != nil {
 		logbuch.Error("Error deleting article recommendation when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
@@ -338,6 +401,15 @@ func DeleteOrganizationById(tx *sqlx.Tx, orgaId hide.ID) error {
 		return err
 	}
 
+	// bots are only member of the organization they were created for
+	if _, err := tx.Exec(`DELETE FROM "user"
+		WHERE bot IS TRUE
+		AND NOT EXISTS (SELECT 1 FROM "organization_member" WHERE user_id = "user".id)`); err != nil {
+		logbuch.Error("Error deleting bot users when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		db.Rollback(tx)
+		return err
+	}
+
 	if _, err := tx.Exec(`DELETE FROM "language" WHERE organization_id = $1`, orgaId); err != nil {
 		logbuch.Error("Error deleting languages when deleting organization by id", logbuch.Fields{"err": err, "orga_id": orgaId})
 		db.Rollback(tx)
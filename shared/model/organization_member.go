@@ -9,6 +9,11 @@ import (
 	"time"
 )
 
+const (
+	// DefaultTimezone is used for new members and to schedule notification mails if the time zone of a member is unknown.
+	DefaultTimezone = "UTC"
+)
+
 type OrganizationMember struct {
 	db.BaseEntity
 
@@ -31,8 +36,10 @@ type OrganizationMember struct {
 	ShowCreateButton          bool        `db:"show_create_button" json:"show_create_button"`
 	ShowNavigation            bool        `db:"show_navigation" json:"show_navigation"`
 	ShowActionButtons         bool        `db:"show_action_buttons" json:"show_action_buttons"`
+	Timezone                  string      `json:"timezone"`
 
-	User *User `db:"user" json:"user"`
+	User                    *User                    `db:"user" json:"user"`
+	NotificationPreferences []NotificationPreference `db:"-" json:"notification_preferences"`
 }
 
 func GetOrganizationMemberByUsername(name string) *OrganizationMember {
@@ -92,6 +99,20 @@ func GetOrganizationMemberByOrganizationIdAndUserIdAndIsMod(orgaId, userId hide.
 	return entity
 }
 
+func GetOrganizationMemberByOrganizationIdAndId(orgaId, id hide.ID) *OrganizationMember {
+	entity := new(OrganizationMember)
+
+	if err := connection.Get(entity, `SELECT * FROM "organization_member"
+		WHERE organization_id = $1
+		AND id = $2
+		AND active IS TRUE`, orgaId, id); err != nil {
+		logbuch.Debug("Organization member by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
 func GetOrganizationMemberByOrganizationIdAndUserId(orgaId, userId hide.ID) *OrganizationMember {
 	return GetOrganizationMemberByOrganizationIdAndUserIdTx(nil, orgaId, userId)
 }
@@ -179,6 +200,21 @@ func FindOrganizationMemberByOrganizationId(orgaId hide.ID) []OrganizationMember
 	return entities
 }
 
+func FindOrganizationMemberByOrganizationIdAndIsAdmin(orgaId hide.ID) []OrganizationMember {
+	query := `SELECT * FROM "organization_member"
+		WHERE organization_id = $1
+		AND is_admin IS TRUE
+		AND active IS TRUE`
+	var entities []OrganizationMember
+
+	if err := connection.Select(&entities, query, orgaId); err != nil {
+		logbuch.Error("Error reading member by organization id and is admin", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
 func FindOrganizationMemberWithNextNotificationMailReachedCursor() (*sqlx.Rows, error) {
 	rows, err := connection.Queryx(`SELECT "organization_member".*,
 		"user".id "user.id",
@@ -192,8 +228,11 @@ func FindOrganizationMemberWithNextNotificationMailReachedCursor() (*sqlx.Rows,
 		FROM "organization_member"
 		JOIN "user" ON "organization_member".user_id = "user".id
 		WHERE active IS TRUE
-		AND send_notifications_interval > 0
-		AND next_notification_mail < NOW()`)
+		AND (send_notifications_interval > 0 OR EXISTS (SELECT 1 FROM "notification_preference"
+			WHERE organization_member_id = "organization_member".id
+			AND delivery IN ('daily', 'weekly')))
+		AND next_notification_mail < NOW()
+		AND "user".bot IS FALSE`)
 
 	if err != nil {
 		logbuch.Error("Error reading user with next notification interval reached", logbuch.Fields{"err": err})
@@ -208,8 +247,11 @@ func CountOrganizationMemberWithNextNotificationMailReached() int {
 		FROM "organization_member"
 		JOIN "user" ON "organization_member".user_id = "user".id
 		WHERE active IS TRUE
-		AND send_notifications_interval > 0
-		AND next_notification_mail < NOW()`
+		AND (send_notifications_interval > 0 OR EXISTS (SELECT 1 FROM "notification_preference"
+			WHERE organization_member_id = "organization_member".id
+			AND delivery IN ('daily', 'weekly')))
+		AND next_notification_mail < NOW()
+		AND "user".bot IS FALSE`
 	var count int
 
 	if err := connection.Get(&count, query); err != nil {
@@ -238,7 +280,9 @@ func CountOrganizationMemberByOrganizationIdAndActive(orgaId hide.ID) int {
 func CountOrganizationMemberByOrganizationIdAndActiveAndNotReadOnly(orgaId hide.ID) int {
 	query := `SELECT COUNT(1)
 		FROM "organization_member"
+		JOIN "user" ON "organization_member".user_id = "user".id
 		WHERE active IS TRUE
+		AND bot IS FALSE
 		AND read_only IS FALSE
 		AND organization_id = $1`
 	var count int
@@ -254,7 +298,9 @@ func CountOrganizationMemberByOrganizationIdAndActiveAndNotReadOnly(orgaId hide.
 func CountOrganizationMemberByOrganizationIdAndLastSeenAfter(orgaId hide.ID, lastSeen time.Time) int {
 	query := `SELECT COUNT(1)
 		FROM "organization_member"
+		JOIN "user" ON "organization_member".user_id = "user".id
 		WHERE active IS TRUE
+		AND bot IS FALSE
 		AND read_only IS FALSE
 		AND organization_id = $1
 		AND last_seen >= $2`
@@ -299,7 +345,8 @@ func SaveOrganizationMember(tx *sqlx.Tx, entity *OrganizationMember) error {
 			recommendation_mail,
 			show_create_button,
 			show_navigation,
-			show_action_buttons)
+			show_action_buttons,
+			timezone)
 			VALUES (:organization_id,
 			:user_id,
 			:language_id,
@@ -318,7 +365,8 @@ func SaveOrganizationMember(tx *sqlx.Tx, entity *OrganizationMember) error {
 			:recommendation_mail,
 			:show_create_button,
 			:show_navigation,
-			:show_action_buttons) RETURNING id`,
+			:show_action_buttons,
+			:timezone) RETURNING id`,
 		`UPDATE "organization_member" SET organization_id = :organization_id,
 			user_id = :user_id,
 			language_id = :language_id,
@@ -337,6 +385,7 @@ func SaveOrganizationMember(tx *sqlx.Tx, entity *OrganizationMember) error {
 			recommendation_mail = :recommendation_mail,
 			show_create_button = :show_create_button,
 			show_navigation = :show_navigation,
-			show_action_buttons = :show_action_buttons
+			show_action_buttons = :show_action_buttons,
+			timezone = :timezone
 			WHERE id = :id`)
 }
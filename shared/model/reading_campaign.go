@@ -0,0 +1,135 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// ReadingCampaign assigns an article to a set of members who must confirm they have read it until the due date.
+// The version is the minimum version of the article content members must have confirmed.
+type ReadingCampaign struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID   `db:"organization_id" json:"organization_id"`
+	ArticleId      hide.ID   `db:"article_id" json:"article_id"`
+	LanguageId     hide.ID   `db:"language_id" json:"language_id"`
+	UserId         hide.ID   `db:"user_id" json:"user_id"` // user who created the campaign
+	DueDate        time.Time `db:"due_date" json:"due_date"`
+	Version        int       `json:"version"`
+
+	Title            string `db:"title" json:"title"`
+	Members          int    `db:"members" json:"members"`
+	ConfirmedMembers int    `db:"confirmed_members" json:"confirmed_members"`
+}
+
+func GetReadingCampaignByOrganizationIdAndId(orgaId, id hide.ID) *ReadingCampaign {
+	entity := new(ReadingCampaign)
+
+	if err := connection.Get(entity, `SELECT * FROM "reading_campaign" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Reading campaign by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func FindReadingCampaignByOrganizationIdAndArticleId(orgaId, articleId hide.ID) []ReadingCampaign {
+	query := `SELECT "reading_campaign".*,
+		(SELECT COUNT(1) FROM "reading_campaign_member" WHERE reading_campaign_id = "reading_campaign".id) "members",
+		(SELECT COUNT(1) FROM "reading_campaign_member" WHERE reading_campaign_id = "reading_campaign".id AND confirmed_version >= "reading_campaign".version) "confirmed_members"
+		FROM "reading_campaign"
+		WHERE organization_id = $1
+		AND article_id = $2
+		ORDER BY due_date ASC`
+	var entities []ReadingCampaign
+
+	if err := connection.Select(&entities, query, orgaId, articleId); err != nil {
+		logbuch.Error("Error reading reading campaigns by organization id and article id", logbuch.Fields{"err": err, "orga_id": orgaId, "article_id": articleId})
+		return nil
+	}
+
+	return entities
+}
+
+// FindReadingCampaignByOrganizationIdAndUserIdAndUnconfirmed returns all campaigns the user is assigned to and has not confirmed (the latest version of) yet.
+func FindReadingCampaignByOrganizationIdAndUserIdAndUnconfirmed(orgaId, userId hide.ID) []ReadingCampaign {
+	query := `SELECT "reading_campaign".*,
+		COALESCE("article_content".title, '') "title"
+		FROM "reading_campaign"
+		JOIN "reading_campaign_member" ON "reading_campaign".id = "reading_campaign_member".reading_campaign_id
+		LEFT JOIN "article_content" ON "reading_campaign".article_id = "article_content".article_id
+			AND "reading_campaign".language_id = "article_content".language_id
+			AND "article_content".version = 0
+		WHERE "reading_campaign".organization_id = $1
+		AND "reading_campaign_member".user_id = $2
+		AND "reading_campaign_member".confirmed_version < "reading_campaign".version
+		ORDER BY "reading_campaign".due_date ASC`
+	var entities []ReadingCampaign
+
+	if err := connection.Select(&entities, query, orgaId, userId); err != nil {
+		logbuch.Error("Error reading reading campaigns by organization id and user id and unconfirmed", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entities
+}
+
+// UpdateReadingCampaignVersionByArticleIdAndLanguageId sets the version members must confirm for all campaigns of given article and language.
+func UpdateReadingCampaignVersionByArticleIdAndLanguageId(tx *sqlx.Tx, articleId, langId hide.ID, version int) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	_, err := tx.Exec(`UPDATE "reading_campaign" SET version = $3 WHERE article_id = $1 AND language_id = $2 AND version < $3`, articleId, langId, version)
+
+	if err != nil {
+		logbuch.Error("Error updating reading campaign version by article id and language id", logbuch.Fields{"err": err, "article_id": articleId, "lang_id": langId, "version": version})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func DeleteReadingCampaignById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	_, err := tx.Exec(`DELETE FROM "reading_campaign_member" WHERE reading_campaign_id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting reading campaign members by reading campaign id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM "reading_campaign" WHERE id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting reading campaign by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func SaveReadingCampaign(tx *sqlx.Tx, entity *ReadingCampaign) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "reading_campaign" (organization_id, article_id, language_id, user_id, due_date, version)
+			VALUES (:organization_id, :article_id, :language_id, :user_id, :due_date, :version)
+			RETURNING id`,
+		`UPDATE "reading_campaign" SET organization_id = :organization_id,
+			article_id = :article_id,
+			language_id = :language_id,
+			user_id = :user_id,
+			due_date = :due_date,
+			version = :version
+			WHERE id = :id`)
+}
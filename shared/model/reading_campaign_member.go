@@ -0,0 +1,106 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// ReadingCampaignMember is a user assigned to a reading campaign.
+// The confirmed version is the latest version of the article the user has confirmed to have read, 0 if not confirmed yet.
+type ReadingCampaignMember struct {
+	db.BaseEntity
+
+	ReadingCampaignId hide.ID   `db:"reading_campaign_id" json:"reading_campaign_id"`
+	UserId            hide.ID   `db:"user_id" json:"user_id"`
+	ConfirmedVersion  int       `db:"confirmed_version" json:"confirmed_version"`
+	Confirmed         null.Time `json:"confirmed"`
+	Reminded          null.Time `json:"-"`
+
+	User            *User            `db:"user" json:"user"`
+	ReadingCampaign *ReadingCampaign `db:"reading_campaign" json:"-"`
+}
+
+func GetReadingCampaignMemberByReadingCampaignIdAndUserId(campaignId, userId hide.ID) *ReadingCampaignMember {
+	entity := new(ReadingCampaignMember)
+
+	if err := connection.Get(entity, `SELECT * FROM "reading_campaign_member" WHERE reading_campaign_id = $1 AND user_id = $2`, campaignId, userId); err != nil {
+		logbuch.Debug("Reading campaign member by reading campaign id and user id not found", logbuch.Fields{"err": err, "campaign_id": campaignId, "user_id": userId})
+		return nil
+	}
+
+	return entity
+}
+
+func FindReadingCampaignMemberByReadingCampaignIdWithUser(campaignId hide.ID) []ReadingCampaignMember {
+	query := `SELECT "reading_campaign_member".*,
+		"user".id "user.id",
+		"user".email "user.email",
+		"user".firstname "user.firstname",
+		"user".lastname "user.lastname",
+		"user".picture "user.picture",
+		"organization_member".username "user.organization_member.username"
+		FROM "reading_campaign_member"
+		JOIN "reading_campaign" ON "reading_campaign_member".reading_campaign_id = "reading_campaign".id
+		JOIN "user" ON "reading_campaign_member".user_id = "user".id
+		JOIN "organization_member" ON "user".id = "organization_member".user_id AND "organization_member".organization_id = "reading_campaign".organization_id
+		WHERE "reading_campaign_member".reading_campaign_id = $1
+		ORDER BY "user".lastname, "user".firstname, "organization_member".username ASC`
+	var entities []ReadingCampaignMember
+
+	if err := connection.Select(&entities, query, campaignId); err != nil {
+		logbuch.Error("Error reading reading campaign members by reading campaign id with user", logbuch.Fields{"err": err, "campaign_id": campaignId})
+		return nil
+	}
+
+	return entities
+}
+
+// FindReadingCampaignMemberByDueDateBeforeAndRemindedBeforeAndUnconfirmed returns all members who must be reminded to confirm a reading campaign.
+// The campaign and user are joined.
+func FindReadingCampaignMemberByDueDateBeforeAndRemindedBeforeAndUnconfirmed(dueDate, reminded time.Time) []ReadingCampaignMember {
+	query := `SELECT "reading_campaign_member".*,
+		"reading_campaign".id "reading_campaign.id",
+		"reading_campaign".organization_id "reading_campaign.organization_id",
+		"reading_campaign".article_id "reading_campaign.article_id",
+		"reading_campaign".language_id "reading_campaign.language_id",
+		"reading_campaign".due_date "reading_campaign.due_date",
+		"reading_campaign".version "reading_campaign.version",
+		"user".id "user.id",
+		"user".email "user.email",
+		"user".firstname "user.firstname",
+		"user".lastname "user.lastname"
+		FROM "reading_campaign_member"
+		JOIN "reading_campaign" ON "reading_campaign_member".reading_campaign_id = "reading_campaign".id
+		JOIN "user" ON "reading_campaign_member".user_id = "user".id
+		JOIN "organization_member" ON "user".id = "organization_member".user_id AND "organization_member".organization_id = "reading_campaign".organization_id
+		WHERE "reading_campaign".due_date < $1
+		AND ("reading_campaign_member".reminded IS NULL OR "reading_campaign_member".reminded < $2)
+		AND "reading_campaign_member".confirmed_version < "reading_campaign".version
+		AND "user".bot IS FALSE
+		ORDER BY "reading_campaign".due_date ASC`
+	var entities []ReadingCampaignMember
+
+	if err := connection.Select(&entities, query, dueDate, reminded); err != nil {
+		logbuch.Error("Error reading reading campaign members by due date before and reminded before and unconfirmed", logbuch.Fields{"err": err, "due_date": dueDate, "reminded": reminded})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveReadingCampaignMember(tx *sqlx.Tx, entity *ReadingCampaignMember) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "reading_campaign_member" (reading_campaign_id, user_id, confirmed_version, confirmed, reminded)
+			VALUES (:reading_campaign_id, :user_id, :confirmed_version, :confirmed, :reminded)
+			RETURNING id`,
+		`UPDATE "reading_campaign_member" SET reading_campaign_id = :reading_campaign_id,
+			user_id = :user_id,
+			confirmed_version = :confirmed_version,
+			confirmed = :confirmed,
+			reminded = :reminded
+			WHERE id = :id`)
+}
@@ -0,0 +1,185 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/jmoiron/sqlx"
+	"time"
+)
+
+// StorageUsage is a daily snapshot of the storage used by an organization.
+type StorageUsage struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID   `db:"organization_id" json:"-"`
+	Date           time.Time `json:"date"`
+	Size           int64     `json:"size"`
+	Files          int       `json:"files"`
+}
+
+// StorageUsageArticle is the storage used by files attached to an article.
+type StorageUsageArticle struct {
+	ArticleId hide.ID `db:"article_id" json:"article_id"`
+	Title     string  `json:"title"`
+	Size      int64   `json:"size"`
+	Files     int     `json:"files"`
+}
+
+// StorageUsageUser is the storage used by files uploaded by a user.
+type StorageUsageUser struct {
+	UserId    hide.ID `db:"user_id" json:"user_id"`
+	Firstname string  `json:"firstname"`
+	Lastname  string  `json:"lastname"`
+	Size      int64   `json:"size"`
+	Files     int     `json:"files"`
+}
+
+// StorageUsageFile is a file and the article it is attached to, if any.
+type StorageUsageFile struct {
+	ArticleId    hide.ID `db:"article_id" json:"article_id"`
+	Title        string  `json:"title"`
+	OriginalName string  `db:"original_name" json:"original_name"`
+	UniqueName   string  `db:"unique_name" json:"unique_name"`
+	MimeType     string  `db:"mime_type" json:"mime_type"`
+	Size         int64   `json:"size"`
+}
+
+func GetStorageUsageByOrganizationIdAndDate(orgaId hide.ID, date time.Time) *StorageUsage {
+	entity := new(StorageUsage)
+
+	if err := connection.Get(entity, `SELECT * FROM "storage_usage" WHERE organization_id = $1 AND "date" = $2`, orgaId, date); err != nil {
+		logbuch.Debug("Storage usage by organization id and date not found", logbuch.Fields{"err": err, "orga_id": orgaId, "date": date})
+		return nil
+	}
+
+	return entity
+}
+
+// GetStorageUsageByOrganizationIdAndDateBefore returns the latest snapshot before given date.
+func GetStorageUsageByOrganizationIdAndDateBefore(orgaId hide.ID, date time.Time) *StorageUsage {
+	entity := new(StorageUsage)
+	query := `SELECT * FROM "storage_usage" WHERE organization_id = $1 AND "date" < $2 ORDER BY "date" DESC LIMIT 1`
+
+	if err := connection.Get(entity, query, orgaId, date); err != nil {
+		logbuch.Debug("Storage usage by organization id and date before not found", logbuch.Fields{"err": err, "orga_id": orgaId, "date": date})
+		return nil
+	}
+
+	return entity
+}
+
+func FindStorageUsageByOrganizationIdAndDateAfter(orgaId hide.ID, date time.Time) []StorageUsage {
+	var entities []StorageUsage
+
+	if err := connection.Select(&entities, `SELECT * FROM "storage_usage" WHERE organization_id = $1 AND "date" > $2 ORDER BY "date" ASC`, orgaId, date); err != nil {
+		logbuch.Error("Error reading storage usage by organization id and date after", logbuch.Fields{"err": err, "orga_id": orgaId, "date": date})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileStorageUsage returns the current storage usage for all organizations.
+// The returned entities are not saved and their date is not set.
+func FindFileStorageUsage() []StorageUsage {
+	query := fmt.Sprintf(`SELECT "organization".id "organization_id",
+		COALESCE(SUM(f."size"), 0) "size",
+		COUNT(f."size") "files"
+		FROM "organization"
+		LEFT JOIN (SELECT DISTINCT ON (organization_id, %[1]s) organization_id, "size" FROM "file" WHERE organization_id IS NOT NULL) f
+		ON f.organization_id = "organization".id
+		GROUP BY "organization".id
+		ORDER BY "organization".id`, fmt.Sprintf(fileStorePathSQL, `"file"`))
+	var entities []StorageUsage
+
+	if err := connection.Select(&entities, query); err != nil {
+		logbuch.Error("Error reading file storage usage", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileStorageUsageArticleByOrganizationIdAndLanguageIdLimit returns the articles using the most storage.
+// The title is returned in given language if available.
+func FindFileStorageUsageArticleByOrganizationIdAndLanguageIdLimit(orgaId, langId hide.ID, n int) []StorageUsageArticle {
+	query := fmt.Sprintf(`SELECT f.article_id,
+		COALESCE((SELECT title FROM "article_content" WHERE article_id = f.article_id AND version = 0 ORDER BY language_id = $2 DESC LIMIT 1), '') "title",
+		SUM(f."size") "size",
+		COUNT(*) "files"
+		FROM (SELECT DISTINCT ON (article_id, %[1]s) article_id, "size" FROM "file" WHERE organization_id = $1 AND article_id IS NOT NULL) f
+		GROUP BY f.article_id
+		ORDER BY "size" DESC
+		LIMIT $3`, fmt.Sprintf(fileStorePathSQL, `"file"`))
+	var entities []StorageUsageArticle
+
+	if err := connection.Select(&entities, query, orgaId, langId, n); err != nil {
+		logbuch.Error("Error reading file storage usage by organization id and language id grouped by article", logbuch.Fields{"err": err, "orga_id": orgaId, "lang_id": langId, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileStorageUsageUserByOrganizationIdLimit returns the users who uploaded the files using the most storage.
+func FindFileStorageUsageUserByOrganizationIdLimit(orgaId hide.ID, n int) []StorageUsageUser {
+	query := fmt.Sprintf(`SELECT f.user_id,
+		COALESCE("user".firstname, '') "firstname",
+		COALESCE("user".lastname, '') "lastname",
+		SUM(f."size") "size",
+		COUNT(*) "files"
+		FROM (SELECT DISTINCT ON (user_id, %[1]s) user_id, "size" FROM "file" WHERE organization_id = $1 AND user_id IS NOT NULL) f
+		LEFT JOIN "user" ON f.user_id = "user".id
+		GROUP BY f.user_id, "user".firstname, "user".lastname
+		ORDER BY "size" DESC
+		LIMIT $2`, fmt.Sprintf(fileStorePathSQL, `"file"`))
+	var entities []StorageUsageUser
+
+	if err := connection.Select(&entities, query, orgaId, n); err != nil {
+		logbuch.Error("Error reading file storage usage by organization id grouped by user", logbuch.Fields{"err": err, "orga_id": orgaId, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileStorageUsageFileByOrganizationIdAndLanguageIdLimit returns the largest files of the organization.
+// Files sharing the same content are returned once.
+func FindFileStorageUsageFileByOrganizationIdAndLanguageIdLimit(orgaId, langId hide.ID, n int) []StorageUsageFile {
+	query := fmt.Sprintf(`SELECT f.article_id,
+		COALESCE((SELECT title FROM "article_content" WHERE article_id = f.article_id AND version = 0 ORDER BY language_id = $2 DESC LIMIT 1), '') "title",
+		f.original_name,
+		f.unique_name,
+		f.mime_type,
+		f."size"
+		FROM (SELECT DISTINCT ON (%[1]s) * FROM "file" WHERE organization_id = $1 ORDER BY %[1]s, article_id IS NULL) f
+		ORDER BY f."size" DESC
+		LIMIT $3`, fmt.Sprintf(fileStorePathSQL, `"file"`))
+	var entities []StorageUsageFile
+
+	if err := connection.Select(&entities, query, orgaId, langId, n); err != nil {
+		logbuch.Error("Error reading largest files by organization id and language id", logbuch.Fields{"err": err, "orga_id": orgaId, "lang_id": langId, "n": n})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveStorageUsage(tx *sqlx.Tx, entity *StorageUsage) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "storage_usage" (organization_id,
+			"date",
+			"size",
+			files)
+			VALUES (:organization_id,
+			:date,
+			:size,
+			:files) RETURNING id`,
+		`UPDATE "storage_usage" SET organization_id = :organization_id,
+			"date" = :date,
+			"size" = :size,
+			files = :files
+			WHERE id = :id`)
+}
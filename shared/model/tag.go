@@ -262,7 +262,8 @@ func DeleteTagUnusedByOrganizationId(tx *sqlx.Tx, orgaId hide.ID) error {
 
 	_, err := tx.Exec(`DELETE FROM "tag"
 		WHERE organization_id = $1
-		AND NOT EXISTS (SELECT 1 FROM "article_tag" WHERE tag_id = "tag".id)`, orgaId)
+		AND NOT EXISTS (SELECT 1 FROM "article_tag" WHERE tag_id = "tag".id)
+		AND NOT EXISTS (SELECT 1 FROM "custom_field_tag" WHERE tag_id = "tag".id)`, orgaId)
 
 	if err != nil {
 		logbuch.Error("Error deleting unused tag by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"strings"
+)
+
+// TranslationStatus compares the last version of an article in the default language to its translation into another language.
+// Only published versions are taken into account.
+type TranslationStatus struct {
+	ArticleId          hide.ID    `db:"article_id" json:"article_id"`
+	LanguageId         hide.ID    `db:"language_id" json:"language_id"`
+	Title              string     `json:"title"`                                        // title in the default language
+	Version            int        `json:"version"`                                      // last version in the default language
+	TranslationVersion null.Int64 `db:"translation_version" json:"translation_version"` // last version of the translation, null if missing
+	SourceVersion      null.Int64 `db:"source_version" json:"source_version"`           // version of the default language the translation is based on
+	Status             string     `db:"-" json:"status"`
+}
+
+// SearchTranslationStatusFilter filters the translation status by language.
+// Pending returns missing and outdated translations only.
+type SearchTranslationStatusFilter struct {
+	LanguageId hide.ID
+	Pending    bool
+	Offset     int
+	Limit      int
+}
+
+func FindTranslationStatusByOrganizationIdAndUserIdAndFilterLimit(orgaId, userId hide.ID, filter *SearchTranslationStatusFilter) []TranslationStatus {
+	query, params := buildTranslationStatusByOrganizationIdAndUserIdAndFilterQuery(orgaId, userId, filter, false)
+	var entities []TranslationStatus
+
+	if err := connection.Select(&entities, query, params...); err != nil {
+		logbuch.Error("Error reading translation status by organization id and user id and filter", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "filter": filter})
+		return nil
+	}
+
+	return entities
+}
+
+func CountTranslationStatusByOrganizationIdAndUserIdAndFilter(orgaId, userId hide.ID, filter *SearchTranslationStatusFilter) int {
+	query, params := buildTranslationStatusByOrganizationIdAndUserIdAndFilterQuery(orgaId, userId, filter, true)
+	var count int
+
+	if err := connection.Get(&count, query, params...); err != nil {
+		logbuch.Error("Error counting translation status by organization id and user id and filter", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "filter": filter})
+		return 0
+	}
+
+	return count
+}
+
+func buildTranslationStatusByOrganizationIdAndUserIdAndFilterQuery(orgaId, userId hide.ID, filter *SearchTranslationStatusFilter, count bool) (string, []interface{}) {
+	params := []interface{}{orgaId, userId}
+	var sb strings.Builder
+
+	if count {
+		sb.WriteString(`SELECT COUNT(1) `)
+	} else {
+		sb.WriteString(`SELECT "article".id article_id,
+			"language".id language_id,
+			d.title,
+			d.version,
+			t.version translation_version,
+			t.source_version `)
+	}
+
+	// d is the last version in the default language, t the last version of the translation
+	sb.WriteString(`FROM "article"
+		JOIN "language" ON "language".organization_id = $1 AND "language".default IS FALSE
+		JOIN "article_content" d ON d.article_id = "article".id
+			AND d.language_id = (SELECT l.id FROM "language" l WHERE l.organization_id = $1 AND l.default IS TRUE)
+			AND d.version = (SELECT MAX(c.version) FROM "article_content" c WHERE c.article_id = "article".id AND c.language_id = d.language_id AND c.wip IS FALSE AND c.version != 0)
+		LEFT JOIN "article_content" t ON t.article_id = "article".id
+			AND t.language_id = "language".id
+			AND t.version = (SELECT MAX(c.version) FROM "article_content" c WHERE c.article_id = "article".id AND c.language_id = "language".id AND c.wip IS FALSE AND c.version != 0)
+		WHERE "article".organization_id = $1
+		AND "article".archived IS NULL
+		AND ("article".read_everyone IS TRUE OR "article".write_everyone IS TRUE OR EXISTS (
+			SELECT 1 FROM "article_access"
+			LEFT JOIN "user_group_member" ON "article_access".user_group_id = "user_group_member".user_group_id
+			WHERE "article_access".article_id = "article".id
+			AND ("article_access".user_id = $2 OR "user_group_member".user_id = $2)
+		)) `)
+
+	if filter.LanguageId != 0 {
+		params = append(params, filter.LanguageId)
+		sb.WriteString(fmt.Sprintf(`AND "language".id = $%d `, len(params)))
+	}
+
+	if filter.Pending {
+		sb.WriteString(`AND (t.id IS NULL OR t.source_version IS NULL OR t.source_version < d.version) `)
+	}
+
+	if !count {
+		params = append(params, filter.Limit, filter.Offset)
+		sb.WriteString(fmt.Sprintf(`ORDER BY d.def_time DESC, "article".id, "language".name LIMIT $%d OFFSET $%d`, len(params)-1, len(params)))
+	}
+
+	return sb.String(), params
+}
@@ -0,0 +1,339 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"encoding/json"
+	"fmt"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"strings"
+	"time"
+)
+
+const (
+	TrashTypeArticle     = "article"
+	TrashTypeArticleList = "article_list"
+	TrashTypeUserGroup   = "user_group"
+	TrashTypeTag         = "tag"
+)
+
+// Trash is a deleted object which can be restored until it gets purged.
+// The data contains a snapshot of all rows belonging to the object, grouped by table name.
+type Trash struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID `db:"organization_id" json:"organization_id"`
+	UserId         hide.ID `db:"user_id" json:"user_id"` // user who deleted the object
+	Type           string  `json:"type"`
+	ObjectId       hide.ID `db:"object_id" json:"object_id"`
+	Name           string  `json:"name"`
+	Data           string  `json:"-"`
+
+	User *User `db:"user" json:"user"`
+}
+
+// trashTable defines how rows of a table belonging to a trashed object are stored and restored.
+// The snapshot query selects the rows for the object ID ($1).
+// The restore query inserts the rows from the snapshot ($1), skipping rows referencing objects that don't exist anymore.
+// The placeholders for the column list and values of restore queries created by restoreTrashRows are filled in on restore,
+// because snapshots taken before a column was added don't contain it.
+type trashTable struct {
+	name     string
+	snapshot string
+	restore  string
+}
+
+// restore query for tags, which skips tags that have been created again in the meantime
+var trashTagTable = trashTable{"tag",
+	`SELECT * FROM "tag" WHERE id IN (SELECT tag_id FROM "article_tag" WHERE article_id = $1)`,
+	restoreTrashRows("tag", `NOT EXISTS (SELECT 1 FROM "tag" WHERE organization_id = r.organization_id AND LOWER(name) = LOWER(r.name))`)}
+
+// restore query for article tags, which are mapped to the tags by name
+var trashArticleTagRestore = `INSERT INTO "article_tag"
+	SELECT r.id, r.article_id, "tag".id, r.def_time, r.mod_time
+	FROM jsonb_populate_recordset(NULL::"article_tag", $1::jsonb->'article_tag') r
+	JOIN jsonb_populate_recordset(NULL::"tag", $1::jsonb->'tag') t ON r.tag_id = t.id
+	JOIN "tag" ON "tag".organization_id = t.organization_id AND LOWER("tag".name) = LOWER(t.name)
+	WHERE EXISTS (SELECT 1 FROM "article" WHERE id = r.article_id)
+	AND NOT EXISTS (SELECT 1 FROM "article_tag" WHERE article_id = r.article_id AND tag_id = "tag".id)
+	ON CONFLICT DO NOTHING`
+
+// restore query for required tags of custom fields, which are mapped to the tags by name
+var trashCustomFieldTagRestore = `INSERT INTO "custom_field_tag"
+	SELECT r.id, r.custom_field_id, "tag".id, r.def_time, r.mod_time
+	FROM jsonb_populate_recordset(NULL::"custom_field_tag", $1::jsonb->'custom_field_tag') r
+	JOIN jsonb_populate_recordset(NULL::"tag", $1::jsonb->'tag') t ON r.tag_id = t.id
+	JOIN "tag" ON "tag".organization_id = t.organization_id AND LOWER("tag".name) = LOWER(t.name)
+	WHERE EXISTS (SELECT 1 FROM "custom_field" WHERE id = r.custom_field_id)
+	ON CONFLICT DO NOTHING`
+
+// tables in order of insertion when restoring an object
+var trashTables = map[string][]trashTable{
+	TrashTypeArticle: {
+		{"article", `SELECT * FROM "article" WHERE id = $1`, restoreTrashRows("article", "")},
+		{"article_content", `SELECT * FROM "article_content" WHERE article_id = $1`, restoreTrashRows("article_content", "")},
+		{"article_content_author", `SELECT * FROM "article_content_author"
+			WHERE article_content_id IN (SELECT id FROM "article_content" WHERE article_id = $1)`,
+			restoreTrashRows("article_content_author", "")},
+		{"article_access", `SELECT * FROM "article_access" WHERE article_id = $1`,
+			restoreTrashRows("article_access", `(r.user_group_id IS NULL OR EXISTS (SELECT 1 FROM "user_group" WHERE id = r.user_group_id))`)},
+		trashTagTable,
+		{"article_tag", `SELECT * FROM "article_tag" WHERE article_id = $1`, trashArticleTagRestore},
+		{"custom_field_tag", `SELECT * FROM "custom_field_tag"
+			WHERE tag_id IN (SELECT tag_id FROM "article_tag" WHERE article_id = $1)`, trashCustomFieldTagRestore},
+		{"article_custom_field", `SELECT * FROM "article_custom_field" WHERE article_id = $1`,
+			restoreTrashRows("article_custom_field", `EXISTS (SELECT 1 FROM "custom_field" WHERE id = r.custom_field_id)
+				AND (r.value_user_id IS NULL OR EXISTS (SELECT 1 FROM "user" WHERE id = r.value_user_id))`)},
+		{"article_list_entry", `SELECT * FROM "article_list_entry" WHERE article_id = $1`,
+			restoreTrashRows("article_list_entry", `EXISTS (SELECT 1 FROM "article_list" WHERE id = r.article_list_id)`)},
+		{"file", `SELECT * FROM "file" WHERE article_id = $1`, restoreTrashRows("file", "")},
+	},
+	TrashTypeArticleList: {
+		{"article_list", `SELECT * FROM "article_list" WHERE id = $1`, restoreTrashRows("article_list", "")},
+		{"article_list_name", `SELECT * FROM "article_list_name" WHERE article_list_id = $1`, restoreTrashRows("article_list_name", "")},
+		{"article_list_member", `SELECT * FROM "article_list_member" WHERE article_list_id = $1`,
+			restoreTrashRows("article_list_member", `(r.user_group_id IS NULL OR EXISTS (SELECT 1 FROM "user_group" WHERE id = r.user_group_id))`)},
+		{"article_list_entry", `SELECT * FROM "article_list_entry" WHERE article_list_id = $1`,
+			restoreTrashRows("article_list_entry", `EXISTS (SELECT 1 FROM "article" WHERE id = r.article_id)`)},
+	},
+	TrashTypeUserGroup: {
+		{"user_group", `SELECT * FROM "user_group" WHERE id = $1`, restoreTrashRows("user_group", "")},
+		{"user_group_member", `SELECT * FROM "user_group_member" WHERE user_group_id = $1`, restoreTrashRows("user_group_member", "")},
+		{"article_access", `SELECT * FROM "article_access" WHERE user_group_id = $1`,
+			restoreTrashRows("article_access", `EXISTS (SELECT 1 FROM "article" WHERE id = r.article_id)`)},
+	},
+	TrashTypeTag: {
+		{"tag", `SELECT * FROM "tag" WHERE id = $1`, trashTagTable.restore},
+		{"article_tag", `SELECT * FROM "article_tag" WHERE tag_id = $1`, trashArticleTagRestore},
+		{"custom_field_tag", `SELECT * FROM "custom_field_tag" WHERE tag_id = $1`, trashCustomFieldTagRestore},
+	},
+}
+
+func restoreTrashRows(table, filter string) string {
+	if filter == "" {
+		filter = "TRUE"
+	}
+
+	return fmt.Sprintf(`INSERT INTO "%s" (%s)
+		SELECT %s FROM jsonb_populate_recordset(NULL::"%s", $1::jsonb->'%s') r
+		WHERE %s
+		ON CONFLICT DO NOTHING`, table, trashColumnsPlaceholder, trashValuesPlaceholder, table, table, filter)
+}
+
+const (
+	trashColumnsPlaceholder = "{columns}"
+	trashValuesPlaceholder  = "{values}"
+)
+
+type trashColumn struct {
+	Name       string      `db:"column_name"`
+	Default    null.String `db:"column_default"`
+	IsNullable string      `db:"is_nullable"`
+}
+
+// Fills in the column list and values of the restore query.
+// Columns missing in the snapshot are set to NULL by jsonb_populate_recordset,
+// so NOT NULL columns fall back to their default.
+func restoreTrashQuery(tx *sqlx.Tx, table trashTable) (string, error) {
+	if !strings.Contains(table.restore, trashColumnsPlaceholder) {
+		return table.restore, nil
+	}
+
+	var columns []trashColumn
+
+	if err := tx.Select(&columns, `SELECT column_name, column_default, is_nullable
+		FROM information_schema.columns
+		WHERE table_schema = current_schema()
+		AND table_name = $1
+		ORDER BY ordinal_position`, table.name); err != nil {
+		return "", err
+	}
+
+	names := make([]string, 0, len(columns))
+	values := make([]string, 0, len(columns))
+
+	for _, column := range columns {
+		names = append(names, fmt.Sprintf(`"%s"`, column.Name))
+
+		if column.IsNullable == "NO" && column.Default.Valid {
+			values = append(values, fmt.Sprintf(`COALESCE(r."%s", %s)`, column.Name, column.Default.String))
+		} else {
+			values = append(values, fmt.Sprintf(`r."%s"`, column.Name))
+		}
+	}
+
+	return strings.NewReplacer(trashColumnsPlaceholder, strings.Join(names, ", "),
+		trashValuesPlaceholder, strings.Join(values, ", ")).Replace(table.restore), nil
+}
+
+func GetTrashByOrganizationIdAndId(orgaId, id hide.ID) *Trash {
+	entity := new(Trash)
+
+	if err := connection.Get(entity, `SELECT * FROM "trash" WHERE organization_id = $1 AND id = $2`, orgaId, id); err != nil {
+		logbuch.Debug("Trash by organization id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+// FindTrashByOrganizationIdAndUserIdWithUser returns the trash for an organization without data.
+// The user ID is optional and filters for objects deleted by given user.
+func FindTrashByOrganizationIdAndUserIdWithUser(orgaId, userId hide.ID) []Trash {
+	query := `SELECT "trash".id, "trash".organization_id, "trash".user_id, "trash".type, "trash".object_id, "trash".name,
+		'{}' "data", "trash".def_time, "trash".mod_time,
+		"user".id "user.id",
+		"user".firstname "user.firstname",
+		"user".lastname "user.lastname",
+		"user".picture "user.picture",
+		"organization_member".username "user.organization_member.username"
+		FROM "trash"
+		JOIN "user" ON "trash".user_id = "user".id
+		JOIN "organization_member" ON "user".id = "organization_member".user_id AND "organization_member".organization_id = "trash".organization_id
+		WHERE "trash".organization_id = $1
+		AND ($2::bigint IS NULL OR "trash".user_id = $2)
+		ORDER BY "trash".def_time DESC`
+	var entities []Trash
+
+	if err := connection.Select(&entities, query, orgaId, userId); err != nil {
+		logbuch.Error("Error reading trash by organization id and user id with user", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId})
+		return nil
+	}
+
+	return entities
+}
+
+// FindTrashByDefTimeBefore returns all trash entries deleted before given time.
+func FindTrashByDefTimeBefore(defTime time.Time) []Trash {
+	var entities []Trash
+
+	if err := connection.Select(&entities, `SELECT * FROM "trash" WHERE def_time < $1`, defTime); err != nil {
+		logbuch.Error("Error reading trash by def time before", logbuch.Fields{"err": err, "def_time": defTime})
+		return nil
+	}
+
+	return entities
+}
+
+// FindFileByTrashIdAndUnused returns the files stored in the trash entry which are neither used by an existing file
+// nor by another trash entry (files are deduplicated by MD5 within an organization).
+func FindFileByTrashIdAndUnused(tx *sqlx.Tx, trashId hide.ID) []File {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	query := `SELECT f.* FROM "trash", jsonb_populate_recordset(NULL::"file", "trash".data->'file') f
+		WHERE "trash".id = $1
+		AND NOT EXISTS (
+			SELECT 1 FROM "file"
+			WHERE organization_id = f.organization_id
+			AND md5 = f.md5
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM "trash" t, jsonb_populate_recordset(NULL::"file", t.data->'file') tf
+			WHERE t.id != "trash".id
+			AND tf.organization_id = f.organization_id
+			AND tf.md5 = f.md5
+		)`
+	var entities []File
+
+	if err := tx.Select(&entities, query, trashId); err != nil {
+		logbuch.Error("Error reading files by trash id and unused", logbuch.Fields{"err": err, "trash_id": trashId})
+		return nil
+	}
+
+	return entities
+}
+
+// SaveTrashSnapshot takes a snapshot of all rows belonging to the object and saves the trash entry.
+// This must be called within the transaction deleting the object, before the rows are deleted.
+func SaveTrashSnapshot(tx *sqlx.Tx, entity *Trash) error {
+	tables, ok := trashTables[entity.Type]
+
+	if !ok {
+		db.Rollback(tx)
+		return fmt.Errorf("unknown trash type '%s'", entity.Type)
+	}
+
+	data := make(map[string]json.RawMessage)
+
+	for _, table := range tables {
+		var rows []byte
+		query := fmt.Sprintf(`SELECT COALESCE(jsonb_agg(to_jsonb(t)), '[]'::jsonb) FROM (%s) t`, table.snapshot)
+
+		if err := tx.Get(&rows, query, entity.ObjectId); err != nil {
+			logbuch.Error("Error taking trash snapshot", logbuch.Fields{"err": err, "type": entity.Type, "object_id": entity.ObjectId, "table": table.name})
+			db.Rollback(tx)
+			return err
+		}
+
+		data[table.name] = rows
+	}
+
+	out, err := json.Marshal(data)
+
+	if err != nil {
+		logbuch.Error("Error marshalling trash snapshot", logbuch.Fields{"err": err, "type": entity.Type, "object_id": entity.ObjectId})
+		db.Rollback(tx)
+		return err
+	}
+
+	entity.Data = string(out)
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "trash" (organization_id, user_id, type, object_id, name, data)
+			VALUES (:organization_id, :user_id, :type, :object_id, :name, :data)
+			RETURNING id`,
+		`UPDATE "trash" SET organization_id = :organization_id,
+			user_id = :user_id,
+			type = :type,
+			object_id = :object_id,
+			name = :name,
+			data = :data
+			WHERE id = :id`)
+}
+
+// RestoreTrash inserts all rows from the snapshot and deletes the trash entry.
+func RestoreTrash(tx *sqlx.Tx, entity *Trash) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	for _, table := range trashTables[entity.Type] {
+		query, err := restoreTrashQuery(tx, table)
+
+		if err != nil {
+			logbuch.Error("Error reading columns to restore trash", logbuch.Fields{"err": err, "id": entity.ID, "table": table.name})
+			db.Rollback(tx)
+			return err
+		}
+
+		if _, err := tx.Exec(query, entity.Data); err != nil {
+			logbuch.Error("Error restoring trash", logbuch.Fields{"err": err, "id": entity.ID, "table": table.name})
+			db.Rollback(tx)
+			return err
+		}
+	}
+
+	return DeleteTrashById(tx, entity.ID)
+}
+
+func DeleteTrashById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	_, err := tx.Exec(`DELETE FROM "trash" WHERE id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting trash by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,223 @@
+package model
+
+import (
+	"emviwiki/shared/db"
+	"github.com/emvi/hide"
+	"github.com/emvi/logbuch"
+	"github.com/emvi/null"
+	"github.com/jmoiron/sqlx"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	uploadChunkPath = "uploads"
+)
+
+// UploadSession is a resumable upload of a file in chunks.
+// The checksum is the optional SHA-256 of the whole file, which is verified when the upload is completed.
+type UploadSession struct {
+	db.BaseEntity
+
+	OrganizationId hide.ID     `db:"organization_id" json:"-"`
+	UserId         hide.ID     `db:"user_id" json:"-"`
+	ArticleId      hide.ID     `db:"article_id" json:"article_id"`
+	LanguageId     hide.ID     `db:"language_id" json:"language_id"`
+	RoomId         null.String `db:"room_id" json:"room_id"`
+	Path           string      `json:"-"`
+	Filename       string      `json:"filename"`
+	MimeType       string      `db:"mime_type" json:"mime_type"`
+	Size           int64       `json:"size"`
+	ChunkSize      int64       `db:"chunk_size" json:"chunk_size"`
+	Checksum       null.String `json:"checksum"`
+}
+
+// UploadChunk is a received part of an upload session.
+// The checksum is the SHA-256 of the chunk.
+type UploadChunk struct {
+	db.BaseEntity
+
+	UploadSessionId hide.ID `db:"upload_session_id" json:"-"`
+	Number          int     `json:"number"`
+	Size            int64   `json:"size"`
+	Checksum        string  `json:"checksum"`
+}
+
+// ChunkDir returns the directory in store the chunks of the upload session are saved in.
+// This must be kept in sync with FindUploadChunkStorePath.
+func (session *UploadSession) ChunkDir() string {
+	return filepath.Join(uploadChunkPath, strconv.FormatInt(int64(session.ID), 10))
+}
+
+// ChunkPath returns the path in store for the chunk with given number.
+func (session *UploadSession) ChunkPath(number int) string {
+	return filepath.Join(session.ChunkDir(), strconv.Itoa(number))
+}
+
+// Chunks returns the number of chunks required to upload the whole file.
+func (session *UploadSession) Chunks() int {
+	return int((session.Size + session.ChunkSize - 1) / session.ChunkSize)
+}
+
+func GetUploadSessionByOrganizationIdAndUserIdAndId(orgaId, userId, id hide.ID) *UploadSession {
+	entity := new(UploadSession)
+
+	if err := connection.Get(entity, `SELECT * FROM "upload_session" WHERE organization_id = $1 AND user_id = $2 AND id = $3`, orgaId, userId, id); err != nil {
+		logbuch.Debug("Upload session by organization id and user id and id not found", logbuch.Fields{"err": err, "orga_id": orgaId, "user_id": userId, "id": id})
+		return nil
+	}
+
+	return entity
+}
+
+func FindUploadSessionByOrganizationId(orgaId hide.ID) []UploadSession {
+	var entities []UploadSession
+
+	if err := connection.Select(&entities, `SELECT * FROM "upload_session" WHERE organization_id = $1`, orgaId); err != nil {
+		logbuch.Error("Error reading upload sessions by organization id", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
+// GetUploadSessionSizeByOrganizationIdAndNotId returns the size of all open upload sessions of the organization,
+// except for the session with given ID. The size is declared when the session is created and reserved until it is completed.
+func GetUploadSessionSizeByOrganizationIdAndNotId(orgaId, id hide.ID) int64 {
+	query := `SELECT COALESCE(SUM("size"), 0) FROM "upload_session" WHERE organization_id = $1 AND id != $2`
+	var size int64
+
+	if err := connection.Get(&size, query, orgaId, id); err != nil {
+		logbuch.Error("Error reading upload session size by organization id and not id", logbuch.Fields{"err": err, "orga_id": orgaId, "id": id})
+		return 0
+	}
+
+	return size
+}
+
+// FindUploadSessionByLastActivityBefore returns all upload sessions which have not been changed
+// and haven't received a chunk since given time.
+func FindUploadSessionByLastActivityBefore(lastActivity time.Time) []UploadSession {
+	query := `SELECT * FROM "upload_session"
+		WHERE mod_time < $1
+		AND NOT EXISTS (SELECT 1 FROM "upload_chunk" WHERE upload_session_id = "upload_session".id AND mod_time >= $1)`
+	var entities []UploadSession
+
+	if err := connection.Select(&entities, query, lastActivity); err != nil {
+		logbuch.Error("Error reading upload sessions by last activity before", logbuch.Fields{"err": err, "last_activity": lastActivity})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveUploadSession(tx *sqlx.Tx, entity *UploadSession) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "upload_session" (organization_id,
+			user_id,
+			article_id,
+			language_id,
+			room_id,
+			path,
+			filename,
+			mime_type,
+			size,
+			chunk_size,
+			checksum)
+			VALUES (:organization_id,
+			:user_id,
+			:article_id,
+			:language_id,
+			:room_id,
+			:path,
+			:filename,
+			:mime_type,
+			:size,
+			:chunk_size,
+			:checksum) RETURNING id`,
+		`UPDATE "upload_session" SET organization_id = :organization_id,
+			user_id = :user_id,
+			article_id = :article_id,
+			language_id = :language_id,
+			room_id = :room_id,
+			path = :path,
+			filename = :filename,
+			mime_type = :mime_type,
+			size = :size,
+			chunk_size = :chunk_size,
+			checksum = :checksum
+			WHERE id = :id`)
+}
+
+func DeleteUploadSessionById(tx *sqlx.Tx, id hide.ID) error {
+	if tx == nil {
+		tx, _ = connection.Beginx()
+		defer db.Commit(tx)
+	}
+
+	_, err := tx.Exec(`DELETE FROM "upload_session" WHERE id = $1`, id)
+
+	if err != nil {
+		logbuch.Error("Error deleting upload session by id", logbuch.Fields{"err": err, "id": id})
+		db.Rollback(tx)
+		return err
+	}
+
+	return nil
+}
+
+func GetUploadChunkByUploadSessionIdAndNumber(sessionId hide.ID, number int) *UploadChunk {
+	entity := new(UploadChunk)
+
+	if err := connection.Get(entity, `SELECT * FROM "upload_chunk" WHERE upload_session_id = $1 AND number = $2`, sessionId, number); err != nil {
+		logbuch.Debug("Upload chunk by upload session id and number not found", logbuch.Fields{"err": err, "session_id": sessionId, "number": number})
+		return nil
+	}
+
+	return entity
+}
+
+// FindUploadChunkStorePath returns the paths in store of all upload chunks.
+func FindUploadChunkStorePath() []FileStorePath {
+	query := `SELECT s.organization_id, '` + uploadChunkPath + `/' || c.upload_session_id || '/' || c.number "path"
+		FROM "upload_chunk" c
+		JOIN "upload_session" s ON c.upload_session_id = s.id
+		ORDER BY c.upload_session_id, c.number`
+	var paths []FileStorePath
+
+	if err := connection.Select(&paths, query); err != nil {
+		logbuch.Error("Error reading upload chunk store paths", logbuch.Fields{"err": err})
+		return nil
+	}
+
+	return paths
+}
+
+func FindUploadChunkByUploadSessionId(sessionId hide.ID) []UploadChunk {
+	var entities []UploadChunk
+
+	if err := connection.Select(&entities, `SELECT * FROM "upload_chunk" WHERE upload_session_id = $1 ORDER BY number`, sessionId); err != nil {
+		logbuch.Error("Error reading upload chunks by upload session id", logbuch.Fields{"err": err, "session_id": sessionId})
+		return nil
+	}
+
+	return entities
+}
+
+func SaveUploadChunk(tx *sqlx.Tx, entity *UploadChunk) error {
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "upload_chunk" (upload_session_id,
+			number,
+			size,
+			checksum)
+			VALUES (:upload_session_id,
+			:number,
+			:size,
+			:checksum) RETURNING id`,
+		`UPDATE "upload_chunk" SET upload_session_id = :upload_session_id,
+			number = :number,
+			size = :size,
+			checksum = :checksum
+			WHERE id = :id`)
+}
@@ -39,6 +39,7 @@ type User struct {
 	AcceptMarketing bool        `db:"accept_marketing" json:"accept_marketing"`
 	ColorMode       int         `db:"color_mode" json:"color_mode"`
 	Introduction    bool        `json:"introduction"`
+	Bot             bool        `json:"bot"` // bots only exist in the backend and act on behalf of clients
 
 	// IsSSOUser is obtains from auth and not stored in backend database
 	IsSSOUser bool `json:"is_sso_user"`
@@ -121,6 +122,34 @@ func GetUserWithOrganizationMemberByOrganizationIdAndUsername(orgaId hide.ID, us
 	return entity
 }
 
+func GetUserWithOrganizationMemberByOrganizationIdAndEmail(orgaId hide.ID, email string) *User {
+	query := userBaseQueryHead + userBaseQuery + `AND LOWER("user".email) = LOWER($2) AND "user".bot IS FALSE`
+	entity := new(User)
+
+	if err := connection.Get(entity, query, orgaId, email); err != nil {
+		logbuch.Debug("User with organization member by organization id and email not found", logbuch.Fields{"err": err, "orga_id": orgaId, "email": email})
+		return nil
+	}
+
+	return entity
+}
+
+// FindUserByOrganizationIdAndBotAndNotClient returns the service accounts of the organization,
+// which are bots not acting on behalf of a client.
+func FindUserByOrganizationIdAndBotAndNotClient(orgaId hide.ID) []User {
+	query := userBaseQueryHead + userBaseQuery + `AND "user".bot IS TRUE
+		AND NOT EXISTS (SELECT 1 FROM "client" WHERE "client".user_id = "user".id)
+		ORDER BY "user".firstname`
+	var entities []User
+
+	if err := connection.Select(&entities, query, orgaId); err != nil {
+		logbuch.Error("Error reading user by organization id and bot and not client", logbuch.Fields{"err": err, "orga_id": orgaId})
+		return nil
+	}
+
+	return entities
+}
+
 func FindUserByOrganizationIdAndUsernameOrFirstnameOrLastnameOrEmail(orgaId hide.ID, keywords string, filter *SearchUserFilter) []User {
 	query, params := buildUserByOrganizationIdAndUsernameOrFirstnameOrLastnameOrEmailQuery(orgaId, keywords, filter, false)
 	var entities []User
@@ -217,6 +246,19 @@ func FindNewsletterSubscriptionEmails() (*sqlx.Rows, error) {
 		) AS email`)
 }
 
+// SaveBotUser saves the given bot user.
+// Bots don't exist on auth, so the ID is taken from a sequence which won't collide with the IDs managed by auth.
+func SaveBotUser(tx *sqlx.Tx, entity *User) error {
+	entity.Bot = true
+	return connection.SaveEntity(tx, entity,
+		`INSERT INTO "user" (id, email, firstname, lastname, language, bot)
+			VALUES (nextval('bot_user_id_seq'), :email, :firstname, :lastname, :language, :bot) RETURNING id`,
+		`UPDATE "user" SET firstname = :firstname,
+			lastname = :lastname,
+			language = :language
+			WHERE id = :id`)
+}
+
 // SaveUser saves the given user. If create is set to true, a new user will be created.
 // User is a special case because the ID is managed by auth and in a different schema,
 // so that the generic save cannot be used here.
@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"emviwiki/shared/config"
 	"github.com/emvi/logbuch"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -14,21 +16,36 @@ const (
 	shutdownTimeout = time.Second * 30
 )
 
+type connContextKey struct{}
+
 // Start starts a new HTTP REST server.
 // It allows configuring read/write timeouts and TLS through environment variables.
 // The optional shutdown function is called right before shutdown and can be used to clean up.
-func Start(handler http.Handler, shutdown func()) {
+// The write timeout doesn't apply to the optional stream paths, which are used for long running responses (like server-sent events).
+func Start(handler http.Handler, shutdown func(), streamPaths ...string) {
 	c := config.Get()
 	logbuch.Info("Starting server...")
 	logbuch.Info("Using HTTP read/write timeouts", logbuch.Fields{"write_timeout": c.Server.HTTP.Timeout.Write, "read_timeout": c.Server.HTTP.Timeout.Read})
+	writeTimeout := time.Duration(c.Server.HTTP.Timeout.Write) * time.Second
 
 	server := &http.Server{
 		Handler:      handler,
 		Addr:         c.Server.Host,
-		WriteTimeout: time.Duration(c.Server.HTTP.Timeout.Write) * time.Second,
+		WriteTimeout: writeTimeout,
 		ReadTimeout:  time.Duration(c.Server.HTTP.Timeout.Read) * time.Second,
 	}
 
+	if len(streamPaths) != 0 {
+		server.Handler = writeTimeoutHandler(handler, writeTimeout, streamPaths)
+		server.WriteTimeout = 0
+		server.ConnContext = func(ctx context.Context, conn net.Conn) context.Context {
+			return context.WithValue(ctx, connContextKey{}, conn)
+		}
+
+		// the write deadline is set on the connection, which is shared by all requests with HTTP/2
+		server.TLSNextProto = make(map[string]func(*http.Server, *tls.Conn, http.Handler))
+	}
+
 	go func() {
 		sigint := make(chan os.Signal)
 		signal.Notify(sigint, os.Interrupt)
@@ -58,3 +75,29 @@ func Start(handler http.Handler, shutdown func()) {
 		}
 	}
 }
+
+// writeTimeoutHandler sets the write deadline of the connection for each request instead of the server,
+// so that it can be disabled for given paths.
+func writeTimeoutHandler(next http.Handler, timeout time.Duration, streamPaths []string) http.Handler {
+	paths := make(map[string]bool)
+
+	for _, path := range streamPaths {
+		paths[path] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if conn, ok := r.Context().Value(connContextKey{}).(net.Conn); ok {
+			var deadline time.Time
+
+			if timeout > 0 && !paths[r.URL.Path] {
+				deadline = time.Now().Add(timeout)
+			}
+
+			if err := conn.SetWriteDeadline(deadline); err != nil {
+				logbuch.Debug("Error setting write deadline", logbuch.Fields{"err": err, "path": r.URL.Path})
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -16,10 +16,94 @@ func CleanBackendDb(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "article_search_query"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "article_view"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "upload_session"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "storage_usage"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "data_key"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "trash"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "article_custom_field"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "custom_field_tag"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "custom_field_option"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "custom_field"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "reading_campaign_member"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "reading_campaign"`); err != nil {
+		t.Fatal(err)
+	}
+
 	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "newsletter_subscription"`); err != nil {
 		t.Fatal(err)
 	}
 
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "notification_preference"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "chat_account"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "chat_integration_reason"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "chat_integration"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "inbound_mail_address"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "inbound_mail_member"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "language_translator"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "access_token_scope"`); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "access_token"`); err != nil {
+		t.Fatal(err)
+	}
+
 	if _, err := model.GetConnection().Exec(nil, `DELETE FROM "client_scope"`); err != nil {
 		t.Fatal(err)
 	}
@@ -131,6 +131,29 @@ func CreateUser(t *testing.T, orga *model.Organization, id hide.ID, email string
 	return user
 }
 
+func CreateBot(t *testing.T, orga *model.Organization, name string) *model.User {
+	user := &model.User{Firstname: name, Language: null.NewString("en", true)}
+
+	if err := model.SaveBotUser(nil, user); err != nil {
+		t.Fatal(err)
+	}
+
+	lang := model.GetDefaultLanguageByOrganizationId(orga.ID)
+	member := &model.OrganizationMember{OrganizationId: orga.ID,
+		UserId:     user.ID,
+		LanguageId: lang.ID,
+		Username:   "bot-" + strconv.Itoa(int(user.ID)),
+		Active:     true,
+		User:       user}
+
+	if err := model.SaveOrganizationMember(nil, member); err != nil {
+		t.Fatal(err)
+	}
+
+	user.OrganizationMember = member
+	return user
+}
+
 func CreateUserGroup(t *testing.T, orga *model.Organization, name string) *model.UserGroup {
 	group := &model.UserGroup{OrganizationId: orga.ID, Name: name}
 
@@ -664,7 +687,7 @@ func CreateInvitation(t *testing.T, orga *model.Organization, email, code string
 }
 
 func CreateArticleVisit(t *testing.T, article *model.Article, user *model.User) *model.ArticleVisit {
-	visit := &model.ArticleVisit{ArticleId: article.ID, UserId: user.ID}
+	visit := &model.ArticleVisit{ArticleId: article.ID, UserId: user.ID, Views: 1}
 
 	if err := model.SaveArticleVisit(nil, visit); err != nil {
 		t.Fatal(err)
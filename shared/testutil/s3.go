@@ -0,0 +1,282 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// S3Server is an in-memory stand-in for MinIO/S3, implementing the object operations used by the content stores.
+// Requests are not authenticated. Uploads without known size are saved as multipart uploads,
+// which results in an ETag which is not the MD5 of the content, like S3 does.
+type S3Server struct {
+	*httptest.Server
+	m       sync.Mutex
+	objects map[string]*s3Object
+	uploads map[string]map[int][]byte
+}
+
+type s3Object struct {
+	data     []byte
+	etag     string
+	metadata http.Header
+	modTime  time.Time
+}
+
+// NewS3Server starts a new S3Server, which must be closed by the caller.
+func NewS3Server() *S3Server {
+	server := &S3Server{objects: make(map[string]*s3Object), uploads: make(map[string]map[int][]byte)}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+// Endpoint returns the host and port of the server.
+func (server *S3Server) Endpoint() string {
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func (server *S3Server) handle(w http.ResponseWriter, r *http.Request) {
+	server.m.Lock()
+	defer server.m.Unlock()
+	query := r.URL.Query()
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+
+	// bucket operations
+	if len(parts) < 2 || parts[1] == "" {
+		if hasQuery(query, "location") {
+			writeS3XML(w, http.StatusOK, struct {
+				XMLName xml.Name `xml:"LocationConstraint"`
+			}{})
+		}
+
+		return
+	}
+
+	key := parts[1]
+
+	switch {
+	case r.Method == http.MethodPost && hasQuery(query, "uploads"):
+		uploadId := strconv.Itoa(len(server.uploads) + 1)
+		server.uploads[uploadId] = make(map[int][]byte)
+		writeS3XML(w, http.StatusOK, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: parts[0], Key: key, UploadId: uploadId})
+	case r.Method == http.MethodPut && query.Get("uploadId") != "":
+		data, err := readS3Body(r)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		partNumber, _ := strconv.Atoi(query.Get("partNumber"))
+		server.uploads[query.Get("uploadId")][partNumber] = data
+		w.Header().Set("ETag", fmt.Sprintf(`"%s"`, md5Hex(data)))
+	case r.Method == http.MethodPost && query.Get("uploadId") != "":
+		server.completeUpload(w, parts[0], key, query.Get("uploadId"), r.Header)
+	case r.Method == http.MethodDelete && query.Get("uploadId") != "":
+		delete(server.uploads, query.Get("uploadId"))
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		server.copyObject(w, key, r.Header)
+	case r.Method == http.MethodPut:
+		data, err := readS3Body(r)
+
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		server.objects[key] = &s3Object{data, md5Hex(data), s3Metadata(r.Header), time.Now()}
+		w.Header().Set("ETag", fmt.Sprintf(`"%s"`, md5Hex(data)))
+	case r.Method == http.MethodHead || r.Method == http.MethodGet:
+		server.getObject(w, r, key)
+	case r.Method == http.MethodDelete:
+		delete(server.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (server *S3Server) completeUpload(w http.ResponseWriter, bucket, key, uploadId string, header http.Header) {
+	parts := server.uploads[uploadId]
+	var data bytes.Buffer
+	var sums []byte
+
+	for i := 1; i <= len(parts); i++ {
+		data.Write(parts[i])
+		sum := md5.Sum(parts[i])
+		sums = append(sums, sum[:]...)
+	}
+
+	etag := fmt.Sprintf("%s-%d", md5Hex(sums), len(parts))
+	server.objects[key] = &s3Object{data.Bytes(), etag, s3Metadata(header), time.Now()}
+	delete(server.uploads, uploadId)
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName xml.Name `xml:"CompleteMultipartUploadResult"`
+		Bucket  string
+		Key     string
+		ETag    string
+	}{Bucket: bucket, Key: key, ETag: fmt.Sprintf(`"%s"`, etag)})
+}
+
+func (server *S3Server) copyObject(w http.ResponseWriter, key string, header http.Header) {
+	source := strings.SplitN(strings.TrimPrefix(header.Get("X-Amz-Copy-Source"), "/"), "/", 2)
+	object, ok := server.objects[source[len(source)-1]]
+
+	if !ok {
+		writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+
+	metadata := object.metadata
+
+	if header.Get("X-Amz-Metadata-Directive") == "REPLACE" {
+		metadata = s3Metadata(header)
+	}
+
+	server.objects[key] = &s3Object{object.data, object.etag, metadata, time.Now()}
+	writeS3XML(w, http.StatusOK, struct {
+		XMLName      xml.Name `xml:"CopyObjectResult"`
+		ETag         string
+		LastModified string
+	}{ETag: fmt.Sprintf(`"%s"`, object.etag), LastModified: time.Now().UTC().Format(time.RFC3339)})
+}
+
+func (server *S3Server) getObject(w http.ResponseWriter, r *http.Request, key string) {
+	object, ok := server.objects[key]
+
+	if !ok {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+		} else {
+			writeS3Error(w, http.StatusNotFound, "NoSuchKey")
+		}
+
+		return
+	}
+
+	for k, v := range object.metadata {
+		w.Header()[k] = v
+	}
+
+	w.Header().Set("ETag", fmt.Sprintf(`"%s"`, object.etag))
+	w.Header().Set("Last-Modified", object.modTime.UTC().Format(http.TimeFormat))
+	w.Header().Set("Content-Type", "application/octet-stream")
+	data := object.data
+
+	if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+		var start, end int
+		n, _ := fmt.Sscanf(rangeHeader, "bytes=%d-%d", &start, &end)
+
+		if n < 2 || end >= len(data) {
+			end = len(data) - 1
+		}
+
+		if start > end {
+			w.WriteHeader(http.StatusRequestedRangeNotSatisfiable)
+			return
+		}
+
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, end, len(data)))
+		w.Header().Set("Content-Length", strconv.Itoa(end-start+1))
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(data[start : end+1])
+		return
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+
+	if r.Method == http.MethodGet {
+		w.Write(data)
+	}
+}
+
+// Reads the request body, decoding it if it was sent using the streaming signature (aws-chunked).
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return ioutil.ReadAll(r.Body)
+	}
+
+	reader := bufio.NewReader(r.Body)
+	var data bytes.Buffer
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			return nil, err
+		}
+
+		size, err := strconv.ParseInt(strings.SplitN(strings.TrimSpace(line), ";", 2)[0], 16, 64)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			return data.Bytes(), nil
+		}
+
+		if _, err := io.CopyN(&data, reader, size); err != nil {
+			return nil, err
+		}
+
+		if _, err := reader.Discard(2); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func s3Metadata(header http.Header) http.Header {
+	metadata := make(http.Header)
+
+	for k, v := range header {
+		if strings.HasPrefix(strings.ToLower(k), "x-amz-meta-") {
+			metadata[k] = v
+		}
+	}
+
+	return metadata
+}
+
+func hasQuery(query map[string][]string, key string) bool {
+	_, ok := query[key]
+	return ok
+}
+
+func md5Hex(data []byte) string {
+	sum := md5.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeS3Error(w http.ResponseWriter, status int, code string) {
+	writeS3XML(w, status, struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+	}{Code: code})
+}
+
+func writeS3XML(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+
+	if err := xml.NewEncoder(w).Encode(v); err != nil {
+		panic(err)
+	}
+}
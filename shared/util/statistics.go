@@ -0,0 +1,23 @@
+package util
+
+import (
+	"time"
+)
+
+const (
+	DefaultStatisticsDays = 30
+	MaxStatisticsDays     = 365
+)
+
+// GetStatisticsStartTime returns the beginning of the day statistics for given number of days start at, including today.
+// The number of days defaults to DefaultStatisticsDays and is limited to MaxStatisticsDays.
+func GetStatisticsStartTime(days int) time.Time {
+	if days <= 0 {
+		days = DefaultStatisticsDays
+	} else if days > MaxStatisticsDays {
+		days = MaxStatisticsDays
+	}
+
+	start := time.Now().AddDate(0, 0, -(days - 1))
+	return time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+}
@@ -0,0 +1,21 @@
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGetStatisticsStartTime(t *testing.T) {
+	now := time.Now()
+	start := GetStatisticsStartTime(0)
+
+	if start.Hour() != 0 || start.Minute() != 0 || now.Sub(start) > time.Hour*24*DefaultStatisticsDays {
+		t.Fatalf("Start time not as expected: %v", start)
+	}
+
+	start = GetStatisticsStartTime(MaxStatisticsDays + 1)
+
+	if now.Sub(start) > time.Hour*24*MaxStatisticsDays {
+		t.Fatalf("Start time must be limited, but was: %v", start)
+	}
+}
@@ -0,0 +1,22 @@
+package util
+
+import (
+	"emviwiki/shared/config"
+	"emviwiki/shared/model"
+)
+
+const (
+	gbToBytes = int64(1073741824)
+)
+
+// GetStorageLimit returns the maximum storage the organization can use in bytes.
+func GetStorageLimit(orga *model.Organization) int64 {
+	return orga.MaxStorageGB * gbToBytes
+}
+
+// IsStorageSoftLimitReached returns whether given storage usage in bytes reaches the configured percentage of the storage limit,
+// at which administrators of the organization are warned. A soft limit of 0 disables the warning.
+func IsStorageSoftLimitReached(orga *model.Organization, usage int64) bool {
+	softLimit := int64(config.Get().Storage.SoftLimit)
+	return softLimit > 0 && usage >= GetStorageLimit(orga)*softLimit/100
+}
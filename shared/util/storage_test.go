@@ -0,0 +1,35 @@
+package util
+
+import (
+	"emviwiki/shared/config"
+	"emviwiki/shared/model"
+	"testing"
+)
+
+func TestIsStorageSoftLimitReached(t *testing.T) {
+	softLimit := config.Get().Storage.SoftLimit
+	defer func() {
+		config.Get().Storage.SoftLimit = softLimit
+	}()
+	orga := &model.Organization{MaxStorageGB: 10}
+
+	if limit := GetStorageLimit(orga); limit != 10737418240 {
+		t.Fatalf("Storage limit must be 10 GB, but was: %v", limit)
+	}
+
+	config.Get().Storage.SoftLimit = 80
+
+	if IsStorageSoftLimitReached(orga, 8589934591) {
+		t.Fatal("Soft limit must not be reached")
+	}
+
+	if !IsStorageSoftLimitReached(orga, 8589934592) {
+		t.Fatal("Soft limit must be reached")
+	}
+
+	config.Get().Storage.SoftLimit = 0
+
+	if IsStorageSoftLimitReached(orga, 10737418240) {
+		t.Fatal("Soft limit must be disabled")
+	}
+}